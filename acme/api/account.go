@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -44,6 +45,51 @@ func (a *AccountService) NewEAB(accMsg acme.Account, kid, hmacEncoded string) (a
 	return a.New(accMsg)
 }
 
+// KeyChange Replaces the key of an account (key rollover).
+// After the rollover, the requests are signed with the new key.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.5
+func (a *AccountService) KeyChange(accountURL string, newKey crypto.PrivateKey) error {
+	if accountURL == "" {
+		return errors.New("account[keyChange]: empty URL")
+	}
+
+	keyChangeURL := a.core.GetDirectory().KeyChangeURL
+	if keyChangeURL == "" {
+		return errors.New("account[keyChange]: the server does not support the key rollover")
+	}
+
+	keyChangeJWS, err := a.core.signKeyChangeContent(keyChangeURL, accountURL, newKey)
+	if err != nil {
+		return fmt.Errorf("account[keyChange]: error signing key change content: %w", err)
+	}
+
+	_, err = a.core.retrievablePost(keyChangeURL, keyChangeJWS, nil)
+	if err != nil {
+		return err
+	}
+
+	a.core.jws.SetKey(newKey)
+
+	return nil
+}
+
+// BindEAB Binds the key of an existing account to External Account Binding credentials.
+// The newAccount request is signed with the account key (JWK), the server returns the existing account.
+func (a *AccountService) BindEAB(accountURL, kid, hmacEncoded string) (acme.ExtendedAccount, error) {
+	if accountURL == "" {
+		return acme.ExtendedAccount{}, errors.New("account[bindEAB]: empty URL")
+	}
+
+	a.core.jws.SetKid("")
+
+	account, err := a.NewEAB(acme.Account{}, kid, hmacEncoded)
+	if account.Location == "" {
+		a.core.jws.SetKid(accountURL)
+	}
+
+	return account, err
+}
+
 // Get Retrieves an account.
 func (a *AccountService) Get(accountURL string) (acme.Account, error) {
 	if accountURL == "" {
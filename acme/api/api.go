@@ -23,6 +23,7 @@ type Core struct {
 	nonceManager *nonces.Manager
 	jws          *secure.JWS
 	directory    acme.Directory
+	clockSkew    time.Duration
 	HTTPClient   *http.Client
 
 	common         service // Reuse a single struct instead of allocating one for each service on the heap.
@@ -37,7 +38,7 @@ type Core struct {
 func New(httpClient *http.Client, userAgent, caDirURL, kid string, privateKey crypto.PrivateKey) (*Core, error) {
 	doer := sender.NewDoer(httpClient, userAgent)
 
-	dir, err := getDirectory(doer, caDirURL)
+	dir, clockSkew, err := getDirectory(doer, caDirURL)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +47,7 @@ func New(httpClient *http.Client, userAgent, caDirURL, kid string, privateKey cr
 
 	jws := secure.NewJWS(privateKey, kid, nonceManager)
 
-	c := &Core{doer: doer, nonceManager: nonceManager, jws: jws, directory: dir, HTTPClient: httpClient}
+	c := &Core{doer: doer, nonceManager: nonceManager, jws: jws, directory: dir, clockSkew: clockSkew, HTTPClient: httpClient}
 
 	c.common.core = c
 	c.Accounts = (*AccountService)(&c.common)
@@ -69,6 +70,17 @@ func (a *Core) post(uri string, reqBody, response interface{}) (*http.Response,
 	return a.retrievablePost(uri, content, response)
 }
 
+// postWithJWS performs an HTTP POST request signed with a specific JWS (ex: signed with the private key of a certificate),
+// and parses the response body as JSON, into the provided respBody object.
+func (a *Core) postWithJWS(jws *secure.JWS, uri string, reqBody, response interface{}) (*http.Response, error) {
+	content, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, errors.New("failed to marshal message")
+	}
+
+	return a.retrievablePostWithJWS(jws, uri, content, response)
+}
+
 // postAsGet performs an HTTP POST ("POST-as-GET") request.
 // https://www.rfc-editor.org/rfc/rfc8555.html#section-6.3
 func (a *Core) postAsGet(uri string, response interface{}) (*http.Response, error) {
@@ -76,6 +88,10 @@ func (a *Core) postAsGet(uri string, response interface{}) (*http.Response, erro
 }
 
 func (a *Core) retrievablePost(uri string, content []byte, response interface{}) (*http.Response, error) {
+	return a.retrievablePostWithJWS(a.jws, uri, content, response)
+}
+
+func (a *Core) retrievablePostWithJWS(jws *secure.JWS, uri string, content []byte, response interface{}) (*http.Response, error) {
 	// during tests, allow to support ~90% of bad nonce with a minimum of attempts.
 	bo := backoff.NewExponentialBackOff()
 	bo.InitialInterval = 200 * time.Millisecond
@@ -85,7 +101,7 @@ func (a *Core) retrievablePost(uri string, content []byte, response interface{})
 	var resp *http.Response
 	operation := func() error {
 		var err error
-		resp, err = a.signedPost(uri, content, response)
+		resp, err = a.signedPost(jws, uri, content, response)
 		if err != nil {
 			// Retry if the nonce was invalidated
 			var e *acme.NonceError
@@ -111,8 +127,8 @@ func (a *Core) retrievablePost(uri string, content []byte, response interface{})
 	return resp, nil
 }
 
-func (a *Core) signedPost(uri string, content []byte, response interface{}) (*http.Response, error) {
-	signedContent, err := a.jws.SignContent(uri, content)
+func (a *Core) signedPost(jws *secure.JWS, uri string, content []byte, response interface{}) (*http.Response, error) {
+	signedContent, err := jws.SignContent(uri, content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to post JWS message: failed to sign content: %w", err)
 	}
@@ -139,6 +155,26 @@ func (a *Core) signEABContent(newAccountURL, kid string, hmac []byte) ([]byte, e
 	return []byte(eabJWS.FullSerialize()), nil
 }
 
+func (a *Core) signKeyChangeContent(keyChangeURL, accountURL string, newKey crypto.PrivateKey) ([]byte, error) {
+	keyChangeJWS, err := a.jws.SignKeyChangeContent(keyChangeURL, accountURL, newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(keyChangeJWS.FullSerialize()), nil
+}
+
+// SignContent signs a content with the account key, and returns the JWS (flattened JSON serialization).
+// The protected header contains the URL, a fresh nonce, and the account URL (kid) if the account is registered.
+func (a *Core) SignContent(uri string, content []byte) ([]byte, error) {
+	signed, err := a.jws.SignContent(uri, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(signed.FullSerialize()), nil
+}
+
 // GetKeyAuthorization Gets the key authorization.
 func (a *Core) GetKeyAuthorization(token string) (string, error) {
 	return a.jws.GetKeyAuthorization(token)
@@ -148,18 +184,46 @@ func (a *Core) GetDirectory() acme.Directory {
 	return a.directory
 }
 
-func getDirectory(do *sender.Doer, caDirURL string) (acme.Directory, error) {
+// GetClockSkew returns the difference between the clock of the CA and the local clock,
+// measured with the Date header of the directory response.
+// A positive value means that the local clock is behind the clock of the CA.
+// Returns 0 if the CA does not provide a Date header.
+func (a *Core) GetClockSkew() time.Duration {
+	return a.clockSkew
+}
+
+func getDirectory(do *sender.Doer, caDirURL string) (acme.Directory, time.Duration, error) {
+	start := time.Now()
+
 	var dir acme.Directory
-	if _, err := do.Get(caDirURL, &dir); err != nil {
-		return dir, fmt.Errorf("get directory at '%s': %w", caDirURL, err)
+	resp, err := do.Get(caDirURL, &dir)
+	if err != nil {
+		return dir, 0, fmt.Errorf("get directory at '%s': %w", caDirURL, err)
 	}
 
 	if dir.NewAccountURL == "" {
-		return dir, errors.New("directory missing new registration URL")
+		return dir, 0, errors.New("directory missing new registration URL")
 	}
 	if dir.NewOrderURL == "" {
-		return dir, errors.New("directory missing new order URL")
+		return dir, 0, errors.New("directory missing new order URL")
 	}
 
-	return dir, nil
+	return dir, measureClockSkew(resp, start, time.Now()), nil
+}
+
+// measureClockSkew compares the Date header of the response with the local time at the middle of the request.
+// The resolution of the Date header is one second.
+func measureClockSkew(resp *http.Response, start, end time.Time) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0
+	}
+
+	local := start.Add(end.Sub(start) / 2)
+
+	return date.Sub(local).Round(time.Second)
 }
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_measureClockSkew(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(200 * time.Millisecond)
+
+	testCases := []struct {
+		desc     string
+		date     string
+		expected time.Duration
+	}{
+		{
+			desc:     "no skew",
+			date:     start.Format(http.TimeFormat),
+			expected: 0,
+		},
+		{
+			desc:     "local clock behind",
+			date:     start.Add(10 * time.Minute).Format(http.TimeFormat),
+			expected: 10 * time.Minute,
+		},
+		{
+			desc:     "local clock ahead",
+			date:     start.Add(-2 * time.Hour).Format(http.TimeFormat),
+			expected: -2 * time.Hour,
+		},
+		{
+			desc:     "missing Date header",
+			expected: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			resp := &http.Response{Header: http.Header{}}
+			if test.date != "" {
+				resp.Header.Set("Date", test.date)
+			}
+
+			assert.Equal(t, test.expected, measureClockSkew(resp, start, end))
+		})
+	}
+}
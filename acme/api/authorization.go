@@ -8,6 +8,27 @@ import (
 
 type AuthorizationService service
 
+// New Creates a new authorization (pre-authorization).
+// The CA must support pre-authorization (the newAuthz URL of the directory).
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1
+func (c *AuthorizationService) New(identifier acme.Identifier) (acme.ExtendedAuthorization, error) {
+	newAuthzURL := c.core.GetDirectory().NewAuthzURL
+	if newAuthzURL == "" {
+		return acme.ExtendedAuthorization{}, errors.New("authorization[new]: the CA doesn't support the pre-authorization")
+	}
+
+	var authz acme.Authorization
+	resp, err := c.core.post(newAuthzURL, acme.NewAuthzMessage{Identifier: identifier}, &authz)
+	if err != nil {
+		return acme.ExtendedAuthorization{}, err
+	}
+
+	return acme.ExtendedAuthorization{
+		Authorization: authz,
+		Location:      resp.Header.Get("Location"),
+	}, nil
+}
+
 // Get Gets an authorization.
 func (c *AuthorizationService) Get(authzURL string) (acme.Authorization, error) {
 	if authzURL == "" {
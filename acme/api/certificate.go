@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 
 	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/api/internal/secure"
 	"github.com/pya789/lego/v4/certcrypto"
 	"github.com/pya789/lego/v4/log"
 )
@@ -61,6 +63,20 @@ func (c *CertificateService) Revoke(req acme.RevokeCertMessage) error {
 	return err
 }
 
+// RevokeWithKey Revokes a certificate, the request is signed with the private key of the certificate instead of the account key.
+// The JWS contains the public key of the certificate (jwk) instead of the account URL (kid): an account is not required.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.6
+func (c *CertificateService) RevokeWithKey(req acme.RevokeCertMessage, privateKey crypto.PrivateKey) error {
+	if privateKey == nil {
+		return errors.New("certificate[revoke]: the private key of the certificate is required")
+	}
+
+	jws := secure.NewJWS(privateKey, "", c.core.nonceManager)
+
+	_, err := c.core.postWithJWS(jws, c.core.GetDirectory().RevokeCertURL, req, nil)
+	return err
+}
+
 // get Returns the certificate and the "up" link.
 func (c *CertificateService) get(certURL string, bundle bool) (*acme.RawCertificate, http.Header, error) {
 	if certURL == "" {
@@ -6,6 +6,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rsa"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 
 	"github.com/pya789/lego/v4/acme/api/internal/nonces"
@@ -33,22 +34,15 @@ func (j *JWS) SetKid(kid string) {
 	j.kid = kid
 }
 
+// SetKey Sets the private key (ex: after a key rollover).
+func (j *JWS) SetKey(privateKey crypto.PrivateKey) {
+	j.privKey = privateKey
+}
+
 // SignContent Signs a content with the JWS.
 func (j *JWS) SignContent(url string, content []byte) (*jose.JSONWebSignature, error) {
-	var alg jose.SignatureAlgorithm
-	switch k := j.privKey.(type) {
-	case *rsa.PrivateKey:
-		alg = jose.RS256
-	case *ecdsa.PrivateKey:
-		if k.Curve == elliptic.P256() {
-			alg = jose.ES256
-		} else if k.Curve == elliptic.P384() {
-			alg = jose.ES384
-		}
-	}
-
 	signKey := jose.SigningKey{
-		Algorithm: alg,
+		Algorithm: signatureAlgorithm(j.privKey),
 		Key:       jose.JSONWebKey{Key: j.privKey, KeyID: j.kid},
 	}
 
@@ -105,6 +99,45 @@ func (j *JWS) SignEABContent(url, kid string, hmac []byte) (*jose.JSONWebSignatu
 	return signed, nil
 }
 
+// SignKeyChangeContent Signs the inner JWS of a key rollover with the new key.
+// The payload contains the account URL and the current (old) public key,
+// the protected header contains the URL and the new public key, without nonce.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.5
+func (j *JWS) SignKeyChangeContent(url, accountURL string, newKey crypto.PrivateKey) (*jose.JSONWebSignature, error) {
+	oldKey := jose.JSONWebKey{Key: j.privKey}
+
+	content, err := json.Marshal(struct {
+		Account string          `json:"account"`
+		OldKey  jose.JSONWebKey `json:"oldKey"`
+	}{
+		Account: accountURL,
+		OldKey:  oldKey.Public(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("acme: error encoding key change content: %w", err)
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: signatureAlgorithm(newKey), Key: newKey},
+		&jose.SignerOptions{
+			EmbedJWK: true,
+			ExtraHeaders: map[jose.HeaderKey]interface{}{
+				"url": url,
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key change jose signer: %w", err)
+	}
+
+	signed, err := signer.Sign(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign key change content: %w", err)
+	}
+
+	return signed, nil
+}
+
 // GetKeyAuthorization Gets the key authorization for a token.
 func (j *JWS) GetKeyAuthorization(token string) (string, error) {
 	var publicKey crypto.PublicKey
@@ -128,3 +161,18 @@ func (j *JWS) GetKeyAuthorization(token string) (string, error) {
 
 	return token + "." + keyThumb, nil
 }
+
+func signatureAlgorithm(privateKey crypto.PrivateKey) jose.SignatureAlgorithm {
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return jose.RS256
+	case *ecdsa.PrivateKey:
+		if k.Curve == elliptic.P256() {
+			return jose.ES256
+		} else if k.Curve == elliptic.P384() {
+			return jose.ES384
+		}
+	}
+
+	return ""
+}
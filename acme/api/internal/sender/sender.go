@@ -6,7 +6,9 @@ import (
 	"io"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pya789/lego/v4/acme"
 )
@@ -117,6 +119,24 @@ func (d *Doer) formatUserAgent() string {
 	return strings.TrimSpace(ua)
 }
 
+// parseRetryAfter parses the value of the header Retry-After: a number of seconds, or an HTTP date.
+// - https://www.rfc-editor.org/rfc/rfc9110.html#section-10.2.3
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
+
 func checkError(req *http.Request, resp *http.Response) error {
 	if resp.StatusCode >= http.StatusBadRequest {
 		body, err := io.ReadAll(resp.Body)
@@ -142,6 +162,10 @@ func checkError(req *http.Request, resp *http.Response) error {
 			return &acme.NonceError{ProblemDetails: errorDetails}
 		}
 
+		if errorDetails.Type == acme.RateLimitedErr {
+			return &acme.RateLimitedError{ProblemDetails: errorDetails, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		}
+
 		return errorDetails
 	}
 	return nil
@@ -5,7 +5,9 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pya789/lego/v4/acme"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -65,3 +67,57 @@ func TestDo_CustomUserAgent(t *testing.T) {
 	}
 	assert.Len(t, strings.Split(ua, " "), 5)
 }
+
+func TestDo_rateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"urn:ietf:params:acme:error:rateLimited","detail":"too many certificates"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	doer := NewDoer(http.DefaultClient, "")
+
+	_, err := doer.Get(server.URL, nil)
+	require.Error(t, err)
+
+	var rateLimited *acme.RateLimitedError
+	require.ErrorAs(t, err, &rateLimited)
+	assert.Equal(t, 2*time.Minute, rateLimited.RetryAfter)
+
+	var problem *acme.ProblemDetails
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusTooManyRequests, problem.HTTPStatus)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+	}{
+		{desc: "empty", value: ""},
+		{desc: "seconds", value: "30", expected: 30 * time.Second},
+		{desc: "negative seconds", value: "-30"},
+		{desc: "past date", value: "Wed, 21 Oct 2015 07:28:00 GMT"},
+		{desc: "invalid", value: "soon"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, parseRetryAfter(test.value))
+		})
+	}
+}
+
+func Test_parseRetryAfter_date(t *testing.T) {
+	value := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+
+	retryAfter := parseRetryAfter(value)
+
+	assert.Greater(t, retryAfter, 59*time.Minute)
+	assert.LessOrEqual(t, retryAfter, time.Hour)
+}
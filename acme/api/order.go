@@ -3,7 +3,10 @@ package api
 import (
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"net"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pya789/lego/v4/acme"
@@ -17,6 +20,9 @@ type OrderOptions struct {
 	// order is intended to replace.
 	// - https://datatracker.ietf.org/doc/html/draft-ietf-acme-ari-03#section-5
 	ReplacesCertID string
+	// The name of a profile advertised by the server (see acme.Meta.Profiles).
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profile string
 }
 
 type OrderService service
@@ -32,13 +38,20 @@ func (o *OrderService) NewWithOptions(domains []string, opts *OrderOptions) (acm
 	for _, domain := range domains {
 		ident := acme.Identifier{Value: domain, Type: "dns"}
 
-		if net.ParseIP(domain) != nil {
+		if ip := net.ParseIP(domain); ip != nil {
+			// RFC 8738: the value is the textual representation of the IP address (RFC 5952 for IPv6).
 			ident.Type = "ip"
+			ident.Value = ip.String()
 		}
 
 		identifiers = append(identifiers, ident)
 	}
 
+	return o.NewWithIdentifiers(identifiers, opts)
+}
+
+// NewWithIdentifiers Creates a new order for the given identifiers (ex: permanent-identifier).
+func (o *OrderService) NewWithIdentifiers(identifiers []acme.Identifier, opts *OrderOptions) (acme.ExtendedOrder, error) {
 	orderReq := acme.Order{Identifiers: identifiers}
 
 	if opts != nil {
@@ -53,6 +66,14 @@ func (o *OrderService) NewWithOptions(domains []string, opts *OrderOptions) (acm
 		if o.core.GetDirectory().RenewalInfo != "" {
 			orderReq.Replaces = opts.ReplacesCertID
 		}
+
+		if opts.Profile != "" {
+			if err := checkProfile(o.core.GetDirectory().Meta.Profiles, opts.Profile); err != nil {
+				return acme.ExtendedOrder{}, err
+			}
+
+			orderReq.Profile = opts.Profile
+		}
 	}
 
 	var order acme.Order
@@ -67,6 +88,26 @@ func (o *OrderService) NewWithOptions(domains []string, opts *OrderOptions) (acm
 	}, nil
 }
 
+// checkProfile checks that the profile is advertised by the server.
+func checkProfile(profiles map[string]string, profile string) error {
+	if _, ok := profiles[profile]; ok {
+		return nil
+	}
+
+	if len(profiles) == 0 {
+		return fmt.Errorf("order[new]: the server does not support the profiles: unable to use the profile %q", profile)
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return fmt.Errorf("order[new]: unknown profile %q (available: %s)", profile, strings.Join(names, ", "))
+}
+
 // Get Gets an order.
 func (o *OrderService) Get(orderURL string) (acme.ExtendedOrder, error) {
 	if orderURL == "" {
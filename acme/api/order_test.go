@@ -52,6 +52,7 @@ func TestOrderService_NewWithOptions(t *testing.T) {
 			Authorizations: order.Authorizations,
 			Finalize:       order.Finalize,
 			Certificate:    order.Certificate,
+			Profile:        order.Profile,
 		})
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -63,9 +64,10 @@ func TestOrderService_NewWithOptions(t *testing.T) {
 	require.NoError(t, err)
 
 	testCases := []struct {
-		desc     string
-		opts     *OrderOptions
-		expected acme.ExtendedOrder
+		desc          string
+		opts          *OrderOptions
+		expected      acme.ExtendedOrder
+		expectedError string
 	}{
 		{
 			desc: "simple",
@@ -91,6 +93,22 @@ func TestOrderService_NewWithOptions(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "with profile",
+			opts: &OrderOptions{Profile: "shortlived"},
+			expected: acme.ExtendedOrder{
+				Order: acme.Order{
+					Status:      "valid",
+					Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+					Profile:     "shortlived",
+				},
+			},
+		},
+		{
+			desc:          "unknown profile",
+			opts:          &OrderOptions{Profile: "tlsserver"},
+			expectedError: `order[new]: unknown profile "tlsserver" (available: classic, shortlived)`,
+		},
 	}
 
 	for _, test := range testCases {
@@ -98,6 +116,11 @@ func TestOrderService_NewWithOptions(t *testing.T) {
 			t.Parallel()
 
 			order, err := core.Orders.NewWithOptions([]string{"example.com"}, test.opts)
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+				return
+			}
+
 			require.NoError(t, err)
 
 			assert.Equal(t, test.expected, order)
@@ -74,6 +74,11 @@ type Meta struct {
 	// then the CA requires that all new-account requests include an "externalAccountBinding" field
 	// associating the new account with an external account.
 	ExternalAccountRequired bool `json:"externalAccountRequired"`
+
+	// profiles (optional, object):
+	// The certificate profiles supported by the ACME server, by name, with a description of the profile.
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profiles map[string]string `json:"profiles,omitempty"`
 }
 
 // ExtendedAccount an extended Account.
@@ -187,6 +192,11 @@ type Order struct {
 	// previously-issued certificate which this order is intended to replace.
 	// - https://datatracker.ietf.org/doc/html/draft-ietf-acme-ari-03#section-5
 	Replaces string `json:"replaces,omitempty"`
+
+	// profile (optional, string):
+	// The name of the profile (advertised by the server in the directory meta) used to issue the certificate.
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profile string `json:"profile,omitempty"`
 }
 
 // Authorization the ACME authorization object.
@@ -222,6 +232,22 @@ type Authorization struct {
 	Wildcard bool `json:"wildcard,omitempty"`
 }
 
+// ExtendedAuthorization a extended Authorization.
+type ExtendedAuthorization struct {
+	Authorization
+
+	// The authorization URL, contains the value of the response header `Location`
+	Location string `json:"-"`
+}
+
+// NewAuthzMessage a pre-authorization request.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1
+type NewAuthzMessage struct {
+	// identifier (required, object):
+	// The identifier that the account wishes to be authorized to represent (the value must not contain a wildcard).
+	Identifier Identifier `json:"identifier"`
+}
+
 // ExtendedChallenge a extended Challenge.
 type ExtendedChallenge struct {
 	Challenge
@@ -2,12 +2,21 @@ package acme
 
 import (
 	"fmt"
+	"time"
 )
 
 // Errors types.
 const (
-	errNS       = "urn:ietf:params:acme:error:"
-	BadNonceErr = errNS + "badNonce"
+	errNS                = "urn:ietf:params:acme:error:"
+	BadNonceErr          = errNS + "badNonce"
+	RateLimitedErr       = errNS + "rateLimited"
+	CAAErr               = errNS + "caa"
+	ConnectionErr        = errNS + "connection"
+	DNSErr               = errNS + "dns"
+	IncorrectResponseErr = errNS + "incorrectResponse"
+	InvalidProfileErr    = errNS + "invalidProfile"
+	TLSErr               = errNS + "tls"
+	UnauthorizedErr      = errNS + "unauthorized"
 )
 
 // ProblemDetails the problem details object.
@@ -56,3 +65,16 @@ func (p ProblemDetails) Error() string {
 type NonceError struct {
 	*ProblemDetails
 }
+
+// RateLimitedError represents the error which is returned
+// if the request was rejected by a rate limit of the server.
+type RateLimitedError struct {
+	*ProblemDetails
+
+	// RetryAfter the duration to wait before retrying the request (header Retry-After), 0 if not defined.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Unwrap() error {
+	return e.ProblemDetails
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+)
+
+// Details of the rate limit errors of Let's Encrypt:
+//   - too many certificates (50) already issued for "example.com" in the last 168h0m0s, retry after 2024-11-26 03:47:15 UTC: see https://...
+//   - too many certificates (5) already issued for this exact set of identifiers in the last 168h0m0s, retry after ...
+//   - too many new orders (300) from this account in the last 3h0m0s, retry after ...
+var (
+	certificatesPerDomainRegexp = regexp.MustCompile(`too many certificates \((\d+)\) already issued for "([^"]+)" in the last (\S+?),`)
+	duplicateCertificatesRegexp = regexp.MustCompile(`too many certificates \((\d+)\) already issued for this exact set of (?:identifiers|domains) in the last (\S+?),`)
+	ordersPerAccountRegexp      = regexp.MustCompile(`too many new orders \((\d+)\) from this account in the last (\S+?),`)
+	retryAfterRegexp            = regexp.MustCompile(`retry after (\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}) UTC`)
+)
+
+// Parse parses a rate limit error of the CA (*acme.RateLimitedError).
+// The Name of the ExceededError is the registered domain for the limit of the certificates per domain, and is empty for the other limits.
+// RetryAt is the time of the detail of the error, or the time of the Retry-After header.
+// Returns false if the error is not a rate limit error of a known limit.
+func Parse(err error, now time.Time) (*ExceededError, bool) {
+	var rateLimited *acme.RateLimitedError
+	if !errors.As(err, &rateLimited) || rateLimited.ProblemDetails == nil {
+		return nil, false
+	}
+
+	detail := rateLimited.Detail
+
+	var exceeded *ExceededError
+
+	if m := certificatesPerDomainRegexp.FindStringSubmatch(detail); m != nil {
+		exceeded = &ExceededError{Limit: CertificatesPerDomain, Name: strings.ToLower(m[2]), Max: atoi(m[1]), Window: parseWindow(m[3])}
+	} else if m := duplicateCertificatesRegexp.FindStringSubmatch(detail); m != nil {
+		exceeded = &ExceededError{Limit: DuplicateCertificates, Max: atoi(m[1]), Window: parseWindow(m[2])}
+	} else if m := ordersPerAccountRegexp.FindStringSubmatch(detail); m != nil {
+		exceeded = &ExceededError{Limit: OrdersPerAccount, Max: atoi(m[1]), Window: parseWindow(m[2])}
+	} else {
+		return nil, false
+	}
+
+	if m := retryAfterRegexp.FindStringSubmatch(detail); m != nil {
+		retryAt, errP := time.Parse("2006-01-02 15:04:05", strings.Replace(m[1], "T", " ", 1))
+		if errP == nil {
+			exceeded.RetryAt = retryAt
+		}
+	}
+
+	if exceeded.RetryAt.IsZero() {
+		exceeded.RetryAt = now.Add(rateLimited.RetryAfter).UTC()
+	}
+
+	return exceeded, true
+}
+
+func atoi(s string) int {
+	v, _ := strconv.Atoi(s)
+	return v
+}
+
+func parseWindow(s string) time.Duration {
+	d, _ := time.ParseDuration(s)
+	return d
+}
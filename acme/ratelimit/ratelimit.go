@@ -0,0 +1,366 @@
+// Package ratelimit tracks the certificates issued per account and per registered domain,
+// to check the rate limits of the CA (ex: Let's Encrypt) before creating an order.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pya789/lego/v4/storage"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Names of the limits.
+const (
+	// CertificatesPerDomain the new certificates per registered domain (eTLD+1).
+	CertificatesPerDomain = "certificates-per-domain"
+	// DuplicateCertificates the certificates for the same set of identifiers.
+	DuplicateCertificates = "duplicate-certificates"
+	// OrdersPerAccount the new orders per account.
+	OrdersPerAccount = "orders-per-account"
+)
+
+// retention the duration of the history of the issuances: a request for the same set of identifiers as a tracked issuance is a renewal.
+const retention = 90 * 24 * time.Hour
+
+// Limit a rate limit of the CA: Max requests in the Window.
+type Limit struct {
+	Max    int
+	Window time.Duration
+}
+
+// Limits the rate limits of a CA. A zero limit is not checked.
+type Limits struct {
+	CertificatesPerDomain Limit
+	DuplicateCertificates Limit
+	OrdersPerAccount      Limit
+}
+
+// LetsEncrypt the rate limits of Let's Encrypt.
+// https://letsencrypt.org/docs/rate-limits/
+var LetsEncrypt = Limits{
+	CertificatesPerDomain: Limit{Max: 50, Window: 7 * 24 * time.Hour},
+	DuplicateCertificates: Limit{Max: 5, Window: 7 * 24 * time.Hour},
+	OrdersPerAccount:      Limit{Max: 300, Window: 3 * time.Hour},
+}
+
+// LetsEncryptStaging the rate limits of the staging environment of Let's Encrypt.
+// https://letsencrypt.org/docs/staging-environment/
+var LetsEncryptStaging = Limits{
+	CertificatesPerDomain: Limit{Max: 30000, Window: 7 * 24 * time.Hour},
+	DuplicateCertificates: Limit{Max: 30000, Window: 7 * 24 * time.Hour},
+	OrdersPerAccount:      Limit{Max: 1500, Window: 3 * time.Hour},
+}
+
+// ForServer returns the known rate limits of the CA server (directory URL).
+func ForServer(serverURL string) (Limits, bool) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return Limits{}, false
+	}
+
+	switch u.Hostname() {
+	case "acme-v02.api.letsencrypt.org":
+		return LetsEncrypt, true
+	case "acme-staging-v02.api.letsencrypt.org":
+		return LetsEncryptStaging, true
+	default:
+		return Limits{}, false
+	}
+}
+
+// Issuance a certificate issued by the CA.
+type Issuance struct {
+	Time    time.Time `json:"time"`
+	Account string    `json:"account"`
+	Domains []string  `json:"domains"`
+	// Renewal the certificate has been issued for the same set of identifiers as a previous certificate:
+	// the renewals are exempt from the limits of the new certificates per domain and of the new orders per account.
+	Renewal bool `json:"renewal,omitempty"`
+}
+
+// Block a limit exceeded according to the CA (rate limit error): the requests are refused until RetryAt.
+type Block struct {
+	Limit   string    `json:"limit"`
+	Name    string    `json:"name"`
+	RetryAt time.Time `json:"retryAt"`
+}
+
+type state struct {
+	Issuances []Issuance `json:"issuances"`
+	Blocks    []Block    `json:"blocks,omitempty"`
+}
+
+// ExceededError a request exceeding a rate limit of the CA.
+type ExceededError struct {
+	Limit string
+	// Name the registered domain, the account, or the identifiers (comma separated), according to the limit.
+	Name    string
+	Max     int
+	Window  time.Duration
+	RetryAt time.Time
+}
+
+func (e *ExceededError) Error() string {
+	msg := fmt.Sprintf("ratelimit: %s limit exceeded for %s", e.Limit, e.Name)
+	if e.Max > 0 {
+		msg += fmt.Sprintf(" (%d in %s)", e.Max, e.Window)
+	}
+
+	return msg + ", retry after " + e.RetryAt.UTC().Format(time.RFC3339)
+}
+
+// Tracker tracks the issuances of a CA in the storage, and checks the requests against the limits of the CA.
+type Tracker struct {
+	store  storage.Store
+	key    string
+	limits Limits
+
+	mu sync.Mutex
+}
+
+// NewTracker creates a Tracker storing the issuances under the key of the storage.
+func NewTracker(store storage.Store, key string, limits Limits) *Tracker {
+	return &Tracker{store: store, key: key, limits: limits}
+}
+
+// Check checks if a new certificate for the domains would exceed a limit.
+// Returns an *ExceededError with the latest RetryAt if several limits are exceeded.
+func (t *Tracker) Check(ctx context.Context, account string, domains []string, now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, err := t.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	domains = normalize(domains)
+	identifiers := strings.Join(domains, ",")
+	registered := registeredDomains(domains)
+	renewal := st.isRenewal(domains)
+
+	var exceeded *ExceededError
+
+	keep := func(e *ExceededError) {
+		if exceeded == nil || e.RetryAt.After(exceeded.RetryAt) {
+			exceeded = e
+		}
+	}
+
+	for _, block := range st.Blocks {
+		if !now.Before(block.RetryAt) {
+			continue
+		}
+
+		switch {
+		case block.Limit == DuplicateCertificates && block.Name == identifiers,
+			block.Limit == CertificatesPerDomain && !renewal && slices.Contains(registered, block.Name),
+			block.Limit == OrdersPerAccount && !renewal && block.Name == account:
+			keep(&ExceededError{Limit: block.Limit, Name: block.Name, RetryAt: block.RetryAt})
+		}
+	}
+
+	keepCount := func(limit string, name string, l Limit, match func(Issuance) bool) {
+		if e := l.check(limit, name, st.Issuances, now, match); e != nil {
+			keep(e)
+		}
+	}
+
+	keepCount(DuplicateCertificates, identifiers, t.limits.DuplicateCertificates, func(i Issuance) bool {
+		return slices.Equal(i.Domains, domains)
+	})
+
+	if !renewal {
+		for _, name := range registered {
+			keepCount(CertificatesPerDomain, name, t.limits.CertificatesPerDomain, func(i Issuance) bool {
+				return !i.Renewal && slices.Contains(registeredDomains(i.Domains), name)
+			})
+		}
+
+		keepCount(OrdersPerAccount, account, t.limits.OrdersPerAccount, func(i Issuance) bool {
+			return !i.Renewal && i.Account == account
+		})
+	}
+
+	if exceeded != nil {
+		return exceeded
+	}
+
+	return nil
+}
+
+// Record records a certificate issued for the domains.
+func (t *Tracker) Record(ctx context.Context, account string, domains []string, now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, err := t.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	domains = normalize(domains)
+
+	st.Issuances = append(st.Issuances, Issuance{
+		Time:    now.UTC(),
+		Account: account,
+		Domains: domains,
+		Renewal: st.isRenewal(domains),
+	})
+
+	return t.save(ctx, st, now)
+}
+
+// RecordError records the limit exceeded according to a rate limit error of the CA:
+// the next requests are refused by Check until the end of the Retry-After window.
+// Returns false if the error is not a rate limit error of a known limit.
+func (t *Tracker) RecordError(ctx context.Context, account string, domains []string, err error, now time.Time) (*ExceededError, bool) {
+	exceeded, ok := Parse(err, now)
+	if !ok {
+		return nil, false
+	}
+
+	switch exceeded.Limit {
+	case DuplicateCertificates:
+		exceeded.Name = strings.Join(normalize(domains), ",")
+	case OrdersPerAccount:
+		exceeded.Name = account
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, errL := t.load(ctx)
+	if errL != nil {
+		return exceeded, true
+	}
+
+	st.Blocks = append(st.Blocks, Block{Limit: exceeded.Limit, Name: exceeded.Name, RetryAt: exceeded.RetryAt.UTC()})
+
+	_ = t.save(ctx, st, now)
+
+	return exceeded, true
+}
+
+func (t *Tracker) load(ctx context.Context) (*state, error) {
+	raw, err := t.store.Get(ctx, t.key)
+	if errors.Is(err, storage.ErrNotExist) {
+		return &state{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: %w", err)
+	}
+
+	var st state
+
+	err = json.Unmarshal(raw, &st)
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: invalid issuances file %s: %w", t.key, err)
+	}
+
+	return &st, nil
+}
+
+// save saves the state, without the issuances older than the retention and the blocks ended.
+func (t *Tracker) save(ctx context.Context, st *state, now time.Time) error {
+	st.Issuances = slices.DeleteFunc(st.Issuances, func(i Issuance) bool {
+		return now.Sub(i.Time) > retention
+	})
+
+	st.Blocks = slices.DeleteFunc(st.Blocks, func(b Block) bool {
+		return !now.Before(b.RetryAt)
+	})
+
+	raw, err := json.MarshalIndent(st, "", "\t")
+	if err != nil {
+		return fmt.Errorf("ratelimit: %w", err)
+	}
+
+	err = t.store.Put(ctx, t.key, raw)
+	if err != nil {
+		return fmt.Errorf("ratelimit: %w", err)
+	}
+
+	return nil
+}
+
+func (st *state) isRenewal(domains []string) bool {
+	return slices.ContainsFunc(st.Issuances, func(i Issuance) bool {
+		return slices.Equal(i.Domains, domains)
+	})
+}
+
+// check counts the issuances matching in the window.
+// Returns an error if a new issuance would exceed the limit, RetryAt is the time when the oldest counted issuance leaves the window.
+func (l Limit) check(limit, name string, issuances []Issuance, now time.Time, match func(Issuance) bool) *ExceededError {
+	if l.Max <= 0 || l.Window <= 0 {
+		return nil
+	}
+
+	var times []time.Time
+
+	for _, issuance := range issuances {
+		if now.Sub(issuance.Time) < l.Window && match(issuance) {
+			times = append(times, issuance.Time)
+		}
+	}
+
+	if len(times) < l.Max {
+		return nil
+	}
+
+	slices.SortFunc(times, func(a, b time.Time) int { return a.Compare(b) })
+
+	return &ExceededError{
+		Limit:   limit,
+		Name:    name,
+		Max:     l.Max,
+		Window:  l.Window,
+		RetryAt: times[len(times)-l.Max].Add(l.Window),
+	}
+}
+
+// normalize returns the sorted identifiers, in lower case, without duplicates.
+func normalize(domains []string) []string {
+	var normalized []string
+
+	for _, domain := range domains {
+		normalized = append(normalized, strings.ToLower(strings.TrimSuffix(domain, ".")))
+	}
+
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
+
+// registeredDomains returns the registered domains (eTLD+1) of the domains. The IP addresses are ignored.
+func registeredDomains(domains []string) []string {
+	var registered []string
+
+	for _, domain := range domains {
+		if net.ParseIP(domain) != nil {
+			continue
+		}
+
+		name, err := publicsuffix.EffectiveTLDPlusOne(strings.TrimPrefix(domain, "*."))
+		if err != nil {
+			name = strings.TrimPrefix(domain, "*.")
+		}
+
+		if !slices.Contains(registered, name) {
+			registered = append(registered, name)
+		}
+	}
+
+	return registered
+}
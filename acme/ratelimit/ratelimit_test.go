@@ -0,0 +1,200 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracker(t *testing.T, limits Limits) *Tracker {
+	t.Helper()
+
+	return NewTracker(storage.NewFileSystem(t.TempDir()), "ratelimits/example.json", limits)
+}
+
+func TestForServer(t *testing.T) {
+	limits, ok := ForServer("https://acme-v02.api.letsencrypt.org/directory")
+	require.True(t, ok)
+	assert.Equal(t, LetsEncrypt, limits)
+
+	limits, ok = ForServer("https://acme-staging-v02.api.letsencrypt.org/directory")
+	require.True(t, ok)
+	assert.Equal(t, LetsEncryptStaging, limits)
+
+	_, ok = ForServer("https://localhost:14000/dir")
+	assert.False(t, ok)
+}
+
+func TestTracker_Check_certificatesPerDomain(t *testing.T) {
+	now := time.Date(2024, 11, 20, 10, 0, 0, 0, time.UTC)
+
+	tracker := newTestTracker(t, Limits{CertificatesPerDomain: Limit{Max: 3, Window: 7 * 24 * time.Hour}})
+
+	for i, domain := range []string{"a.example.com", "b.example.com", "*.c.example.com"} {
+		err := tracker.Record(context.Background(), "bob", []string{domain}, now.Add(time.Duration(i-3)*time.Hour))
+		require.NoError(t, err)
+	}
+
+	err := tracker.Check(context.Background(), "bob", []string{"example.org"}, now)
+	require.NoError(t, err)
+
+	err = tracker.Check(context.Background(), "bob", []string{"d.example.com"}, now)
+
+	var exceeded *ExceededError
+	require.ErrorAs(t, err, &exceeded)
+
+	assert.Equal(t, CertificatesPerDomain, exceeded.Limit)
+	assert.Equal(t, "example.com", exceeded.Name)
+	assert.Equal(t, now.Add(-3*time.Hour).Add(7*24*time.Hour), exceeded.RetryAt)
+	assert.EqualError(t, err, "ratelimit: certificates-per-domain limit exceeded for example.com (3 in 168h0m0s), retry after 2024-11-27T07:00:00Z")
+
+	// a renewal is not counted, and is not refused.
+	err = tracker.Check(context.Background(), "bob", []string{"A.example.com"}, now)
+	require.NoError(t, err)
+
+	// the oldest issuance has left the window.
+	err = tracker.Check(context.Background(), "bob", []string{"d.example.com"}, now.Add(7*24*time.Hour-2*time.Hour))
+	require.NoError(t, err)
+}
+
+func TestTracker_Check_duplicateCertificates(t *testing.T) {
+	now := time.Date(2024, 11, 20, 10, 0, 0, 0, time.UTC)
+
+	tracker := newTestTracker(t, Limits{DuplicateCertificates: Limit{Max: 2, Window: 7 * 24 * time.Hour}})
+
+	for i := range 2 {
+		err := tracker.Record(context.Background(), "bob", []string{"b.example.com", "a.example.com"}, now.Add(time.Duration(i-2)*time.Hour))
+		require.NoError(t, err)
+	}
+
+	err := tracker.Check(context.Background(), "bob", []string{"a.example.com"}, now)
+	require.NoError(t, err)
+
+	err = tracker.Check(context.Background(), "bob", []string{"a.example.com", "b.example.com"}, now)
+	require.EqualError(t, err, "ratelimit: duplicate-certificates limit exceeded for a.example.com,b.example.com (2 in 168h0m0s), retry after 2024-11-27T08:00:00Z")
+}
+
+func TestTracker_Check_ordersPerAccount(t *testing.T) {
+	now := time.Date(2024, 11, 20, 10, 0, 0, 0, time.UTC)
+
+	tracker := newTestTracker(t, Limits{OrdersPerAccount: Limit{Max: 2, Window: 3 * time.Hour}})
+
+	for i := range 2 {
+		err := tracker.Record(context.Background(), "bob", []string{fmt.Sprintf("%d.example.com", i)}, now.Add(-time.Hour))
+		require.NoError(t, err)
+	}
+
+	err := tracker.Check(context.Background(), "alice", []string{"example.org"}, now)
+	require.NoError(t, err)
+
+	err = tracker.Check(context.Background(), "bob", []string{"example.org"}, now)
+	require.EqualError(t, err, "ratelimit: orders-per-account limit exceeded for bob (2 in 3h0m0s), retry after 2024-11-20T12:00:00Z")
+}
+
+func TestTracker_RecordError(t *testing.T) {
+	now := time.Date(2024, 11, 20, 10, 0, 0, 0, time.UTC)
+
+	tracker := newTestTracker(t, LetsEncrypt)
+
+	rateLimited := &acme.RateLimitedError{ProblemDetails: &acme.ProblemDetails{
+		Type:   acme.RateLimitedErr,
+		Detail: `too many certificates (50) already issued for "example.com" in the last 168h0m0s, retry after 2024-11-21 03:47:15 UTC: see https://letsencrypt.org/docs/rate-limits/`,
+	}}
+
+	exceeded, ok := tracker.RecordError(context.Background(), "bob", []string{"a.example.com"}, fmt.Errorf("obtain: %w", rateLimited), now)
+	require.True(t, ok)
+
+	assert.Equal(t, "example.com", exceeded.Name)
+
+	// the limit is blocked until the time of the CA, without tracked issuances.
+	err := tracker.Check(context.Background(), "bob", []string{"b.example.com"}, now.Add(time.Hour))
+	require.EqualError(t, err, "ratelimit: certificates-per-domain limit exceeded for example.com, retry after 2024-11-21T03:47:15Z")
+
+	err = tracker.Check(context.Background(), "bob", []string{"example.org"}, now.Add(time.Hour))
+	require.NoError(t, err)
+
+	err = tracker.Check(context.Background(), "bob", []string{"b.example.com"}, now.Add(24*time.Hour))
+	require.NoError(t, err)
+
+	_, ok = tracker.RecordError(context.Background(), "bob", []string{"a.example.com"}, errors.New("boom"), now)
+	assert.False(t, ok)
+}
+
+func TestTracker_Record_retention(t *testing.T) {
+	now := time.Date(2024, 11, 20, 10, 0, 0, 0, time.UTC)
+
+	tracker := newTestTracker(t, LetsEncrypt)
+
+	err := tracker.Record(context.Background(), "bob", []string{"example.com"}, now.Add(-100*24*time.Hour))
+	require.NoError(t, err)
+
+	err = tracker.Record(context.Background(), "bob", []string{"example.org"}, now)
+	require.NoError(t, err)
+
+	st, err := tracker.load(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, st.Issuances, 1)
+	assert.Equal(t, []string{"example.org"}, st.Issuances[0].Domains)
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 11, 20, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc       string
+		detail     string
+		retryAfter time.Duration
+		expected   *ExceededError
+	}{
+		{
+			desc:     "certificates per domain",
+			detail:   `too many certificates (50) already issued for "Example.com" in the last 168h0m0s, retry after 2024-11-26 03:47:15 UTC: see https://letsencrypt.org/docs/rate-limits/#new-certificates-per-registered-domain`,
+			expected: &ExceededError{Limit: CertificatesPerDomain, Name: "example.com", Max: 50, Window: 168 * time.Hour, RetryAt: time.Date(2024, 11, 26, 3, 47, 15, 0, time.UTC)},
+		},
+		{
+			desc:     "duplicate certificates",
+			detail:   `too many certificates (5) already issued for this exact set of identifiers in the last 168h0m0s, retry after 2024-11-22 10:00:00 UTC: see https://letsencrypt.org/docs/rate-limits/#new-certificates-per-exact-set-of-hostnames`,
+			expected: &ExceededError{Limit: DuplicateCertificates, Max: 5, Window: 168 * time.Hour, RetryAt: time.Date(2024, 11, 22, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			desc:       "orders per account, Retry-After header",
+			detail:     `too many new orders (300) from this account in the last 3h0m0s, see https://letsencrypt.org/docs/rate-limits/#new-orders-per-account`,
+			retryAfter: 30 * time.Minute,
+			expected:   &ExceededError{Limit: OrdersPerAccount, Max: 300, Window: 3 * time.Hour, RetryAt: now.Add(30 * time.Minute)},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := &acme.RateLimitedError{
+				ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr, Detail: test.detail},
+				RetryAfter:     test.retryAfter,
+			}
+
+			exceeded, ok := Parse(err, now)
+			require.True(t, ok)
+
+			assert.Equal(t, test.expected, exceeded)
+		})
+	}
+}
+
+func TestParse_unknown(t *testing.T) {
+	err := &acme.RateLimitedError{ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr, Detail: "too many failed authorizations recently"}}
+
+	_, ok := Parse(err, time.Now())
+	assert.False(t, ok)
+
+	_, ok = Parse(errors.New("boom"), time.Now())
+	assert.False(t, ok)
+}
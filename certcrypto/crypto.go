@@ -19,6 +19,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pya789/lego/v4/platform/random"
 	"golang.org/x/crypto/ocsp"
 )
 
@@ -135,19 +136,34 @@ func GeneratePrivateKey(keyType KeyType) (crypto.PrivateKey, error) {
 	return nil, fmt.Errorf("invalid KeyType: %s", keyType)
 }
 
+// GenerateCSR generates a CSR for the domain (common name) and the SANs.
+// The IP addresses (RFC 8738) are added as IP address SANs, an IP address is never used as common name.
 func GenerateCSR(privateKey crypto.PrivateKey, domain string, san []string, mustStaple bool) ([]byte, error) {
 	var dnsNames []string
 	var ipAddresses []net.IP
 	for _, altname := range san {
 		if ip := net.ParseIP(altname); ip != nil {
-			ipAddresses = append(ipAddresses, ip)
+			// the same IP address can have several textual representations (ex: IPv6).
+			if !slices.ContainsFunc(ipAddresses, ip.Equal) {
+				ipAddresses = append(ipAddresses, ip)
+			}
 		} else {
 			dnsNames = append(dnsNames, altname)
 		}
 	}
 
+	commonName := domain
+
+	if ip := net.ParseIP(domain); ip != nil {
+		commonName = ""
+
+		if !slices.ContainsFunc(ipAddresses, ip.Equal) {
+			ipAddresses = append([]net.IP{ip}, ipAddresses...)
+		}
+	}
+
 	template := x509.CertificateRequest{
-		Subject:     pkix.Name{CommonName: domain},
+		Subject:     pkix.Name{CommonName: commonName},
 		DNSNames:    dnsNames,
 		IPAddresses: ipAddresses,
 	}
@@ -299,7 +315,7 @@ func GeneratePemCert(privateKey *rsa.PrivateKey, domain string, extensions []pki
 
 func generateDerCert(privateKey *rsa.PrivateKey, expiration time.Time, domain string, extensions []pkix.Extension) ([]byte, error) {
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
-	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	serialNumber, err := rand.Int(random.Reader(), serialNumberLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -328,5 +344,5 @@ func generateDerCert(privateKey *rsa.PrivateKey, expiration time.Time, domain st
 		template.DNSNames = []string{domain}
 	}
 
-	return x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	return x509.CreateCertificate(random.Reader(), &template, &template, &privateKey.PublicKey, privateKey)
 }
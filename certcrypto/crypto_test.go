@@ -5,7 +5,9 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/pem"
+	"net"
 	"regexp"
 	"testing"
 	"time"
@@ -103,6 +105,21 @@ func TestGenerateCSR(t *testing.T) {
 	}
 }
 
+func TestGenerateCSR_ipAddress(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err, "Error generating private key")
+
+	raw, err := GenerateCSR(privateKey, "2001:db8::1", []string{"192.0.2.1", "lego.acme", "2001:DB8:0::1"}, false)
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(raw)
+	require.NoError(t, err)
+
+	assert.Empty(t, csr.Subject.CommonName)
+	assert.Equal(t, []string{"lego.acme"}, csr.DNSNames)
+	assert.Equal(t, []net.IP{net.ParseIP("192.0.2.1").To4(), net.ParseIP("2001:db8::1")}, csr.IPAddresses)
+}
+
 func TestPEMEncode(t *testing.T) {
 	buf := bytes.NewBufferString("TestingRSAIsSoMuchFun")
 
@@ -0,0 +1,74 @@
+package certcrypto
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TLSA selectors (RFC 6698, section 2.1.2).
+const (
+	TLSASelectorCert = 0 // Full certificate.
+	TLSASelectorSPKI = 1 // SubjectPublicKeyInfo.
+)
+
+// TLSA matching types (RFC 6698, section 2.1.3).
+const (
+	TLSAMatchingFull   = 0 // Exact match on selected content.
+	TLSAMatchingSHA256 = 1 // SHA-256 hash of selected content.
+	TLSAMatchingSHA512 = 2 // SHA-512 hash of selected content.
+)
+
+// Fingerprint returns the SHA-256 fingerprint of the certificate,
+// formatted as uppercase hexadecimal bytes separated by colons (ex: "AB:CD:...").
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// SPKIPin returns the base64 encoded SHA-256 hash of the SubjectPublicKeyInfo of the certificate
+// (the "pin-sha256" of HPKP, RFC 7469).
+// The pin depends only on the key: it is the same for all the certificates using the same private key.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// TLSAData returns the hexadecimal "certificate association data" of a TLSA record (RFC 6698)
+// for the given selector and matching type.
+func TLSAData(cert *x509.Certificate, selector, matchingType uint8) (string, error) {
+	var content []byte
+
+	switch selector {
+	case TLSASelectorCert:
+		content = cert.Raw
+	case TLSASelectorSPKI:
+		content = cert.RawSubjectPublicKeyInfo
+	default:
+		return "", fmt.Errorf("unsupported TLSA selector: %d", selector)
+	}
+
+	switch matchingType {
+	case TLSAMatchingFull:
+		return hex.EncodeToString(content), nil
+	case TLSAMatchingSHA256:
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:]), nil
+	case TLSAMatchingSHA512:
+		sum := sha512.Sum512(content)
+		return hex.EncodeToString(sum[:]), nil
+	default:
+		return "", fmt.Errorf("unsupported TLSA matching type: %d", matchingType)
+	}
+}
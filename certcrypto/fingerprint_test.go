@@ -0,0 +1,117 @@
+package certcrypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	certBytes, err := generateDerCert(privateKey, time.Now().Add(time.Hour), "test.com", nil)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func TestFingerprint(t *testing.T) {
+	cert := generateTestCertificate(t)
+
+	sum := sha256.Sum256(cert.Raw)
+
+	fingerprint := Fingerprint(cert)
+
+	assert.Len(t, fingerprint, 32*3-1)
+	assert.Equal(t, strings.ToUpper(hex.EncodeToString(sum[:])), strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+func TestSPKIPin(t *testing.T) {
+	cert := generateTestCertificate(t)
+
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), SPKIPin(cert))
+}
+
+func TestTLSAData(t *testing.T) {
+	cert := generateTestCertificate(t)
+
+	certSHA256 := sha256.Sum256(cert.Raw)
+	spkiSHA256 := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	spkiSHA512 := sha512.Sum512(cert.RawSubjectPublicKeyInfo)
+
+	testCases := []struct {
+		desc         string
+		selector     uint8
+		matchingType uint8
+		expected     string
+		expectedErr  string
+	}{
+		{
+			desc:         "full certificate",
+			selector:     TLSASelectorCert,
+			matchingType: TLSAMatchingFull,
+			expected:     hex.EncodeToString(cert.Raw),
+		},
+		{
+			desc:         "certificate SHA-256",
+			selector:     TLSASelectorCert,
+			matchingType: TLSAMatchingSHA256,
+			expected:     hex.EncodeToString(certSHA256[:]),
+		},
+		{
+			desc:         "SPKI SHA-256",
+			selector:     TLSASelectorSPKI,
+			matchingType: TLSAMatchingSHA256,
+			expected:     hex.EncodeToString(spkiSHA256[:]),
+		},
+		{
+			desc:         "SPKI SHA-512",
+			selector:     TLSASelectorSPKI,
+			matchingType: TLSAMatchingSHA512,
+			expected:     hex.EncodeToString(spkiSHA512[:]),
+		},
+		{
+			desc:         "unsupported selector",
+			selector:     2,
+			matchingType: TLSAMatchingSHA256,
+			expectedErr:  "unsupported TLSA selector: 2",
+		},
+		{
+			desc:         "unsupported matching type",
+			selector:     TLSASelectorSPKI,
+			matchingType: 3,
+			expectedErr:  "unsupported TLSA matching type: 3",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			data, err := TLSAData(cert, test.selector, test.matchingType)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, data)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package certificate
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/x509"
 	"encoding/base64"
@@ -49,6 +50,13 @@ type Resource struct {
 	Certificate       []byte `json:"-"`
 	IssuerCertificate []byte `json:"-"`
 	CSR               []byte `json:"-"`
+
+	// OrderURL the URL of the order of the certificate.
+	OrderURL string `json:"orderUrl,omitempty"`
+	// AuthorizationURLs the URLs of the authorizations of the order.
+	AuthorizationURLs []string `json:"authorizationUrls,omitempty"`
+	// ARICertID the identifier of the certificate for the renewalInfo endpoint (draft-ietf-acme-ari).
+	ARICertID string `json:"ariCertId,omitempty"`
 }
 
 // ObtainRequest The request to obtain certificate.
@@ -78,6 +86,11 @@ type ObtainRequest struct {
 	// order is intended to replace.
 	// - https://datatracker.ietf.org/doc/html/draft-ietf-acme-ari-03#section-5
 	ReplacesCertID string
+	// The name of a certificate profile advertised by the server (ex: "tlsserver", "shortlived").
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profile string
+	// Progress receives the phase transitions and the status updates of the domains, if defined.
+	Progress ProgressFunc
 }
 
 // ObtainForCSRRequest The request to obtain a certificate matching the CSR passed into it.
@@ -88,6 +101,9 @@ type ObtainRequest struct {
 // See https://datatracker.ietf.org/doc/html/rfc8555#section-7.5.2.
 type ObtainForCSRRequest struct {
 	CSR *x509.CertificateRequest
+	// Identifiers overrides the identifiers inferred from the CSR.
+	// Used for the non-DNS identifiers (ex: "permanent-identifier" with the device-attest-01 challenge).
+	Identifiers []acme.Identifier
 
 	NotBefore                      time.Time
 	NotAfter                       time.Time
@@ -98,6 +114,11 @@ type ObtainForCSRRequest struct {
 	// order is intended to replace.
 	// - https://datatracker.ietf.org/doc/html/draft-ietf-acme-ari-03#section-5
 	ReplacesCertID string
+	// The name of a certificate profile advertised by the server (ex: "tlsserver", "shortlived").
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profile string
+	// Progress receives the phase transitions and the status updates of the domains, if defined.
+	Progress ProgressFunc
 }
 
 type resolver interface {
@@ -108,6 +129,10 @@ type CertifierOptions struct {
 	KeyType             certcrypto.KeyType
 	Timeout             time.Duration
 	OverallRequestLimit int
+	// PreferredChain is used when a request doesn't define its own preferred chain.
+	PreferredChain string
+	// StrictIDNA validates the domains with the IDNA2008/UTS-46 strict rules before ordering.
+	StrictIDNA bool
 }
 
 // Certifier A service to obtain/renew/revoke certificates.
@@ -138,6 +163,10 @@ func (c *Certifier) GenerateOrder(request ObtainRequest) (*acme.ExtendedOrder, [
 		return nil, nil, errors.New("no domains to obtain a certificate for")
 	}
 
+	if err := validateDomains(request.Domains, c.options.StrictIDNA); err != nil {
+		return nil, nil, err
+	}
+
 	domains := sanitizeDomain(request.Domains)
 
 	if request.Bundle {
@@ -150,6 +179,7 @@ func (c *Certifier) GenerateOrder(request ObtainRequest) (*acme.ExtendedOrder, [
 		NotBefore:      request.NotBefore,
 		NotAfter:       request.NotAfter,
 		ReplacesCertID: request.ReplacesCertID,
+		Profile:        request.Profile,
 	}
 
 	order, err := c.core.Orders.NewWithOptions(domains, orderOpts)
@@ -194,10 +224,27 @@ func (c *Certifier) Finalize(order *acme.ExtendedOrder, authz []acme.Authorizati
 // This function will never return a partial certificate.
 // If one domain in the list fails, the whole certificate will fail.
 func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
+	return c.ObtainContext(context.Background(), request)
+}
+
+// ObtainContext is like Obtain,
+// the context is passed to the challenge providers implementing challenge.ProviderContext.
+func (c *Certifier) ObtainContext(ctx context.Context, request ObtainRequest) (*Resource, error) {
+	cert, err := c.obtain(ctx, request)
+	request.Progress.done(err)
+
+	return cert, err
+}
+
+func (c *Certifier) obtain(ctx context.Context, request ObtainRequest) (*Resource, error) {
 	if len(request.Domains) == 0 {
 		return nil, errors.New("no domains to obtain a certificate for")
 	}
 
+	if err := validateDomains(request.Domains, c.options.StrictIDNA); err != nil {
+		return nil, err
+	}
+
 	domains := sanitizeDomain(request.Domains)
 
 	if request.Bundle {
@@ -210,13 +257,18 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 		NotBefore:      request.NotBefore,
 		NotAfter:       request.NotAfter,
 		ReplacesCertID: request.ReplacesCertID,
+		Profile:        request.Profile,
 	}
 
+	request.Progress.phase(PhaseOrder)
+
 	order, err := c.core.Orders.NewWithOptions(domains, orderOpts)
 	if err != nil {
 		return nil, err
 	}
 
+	request.Progress.phase(PhaseAuthorizations)
+
 	authz, err := c.getAuthorizations(order)
 	if err != nil {
 		// If any challenge fails, return. Do not generate partial SAN certificates.
@@ -224,7 +276,7 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 		return nil, err
 	}
 
-	err = c.resolver.Solve(authz)
+	err = c.solve(ctx, authz, request.Progress)
 	if err != nil {
 		// If any challenge fails, return. Do not generate partial SAN certificates.
 		c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
@@ -233,6 +285,8 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 
 	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
 
+	request.Progress.phase(PhaseFinalize)
+
 	failures := newObtainError()
 	cert, err := c.getForOrder(domains, order, request.Bundle, request.PrivateKey, request.MustStaple, request.PreferredChain)
 	if err != nil {
@@ -258,6 +312,19 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 // This function will never return a partial certificate.
 // If one domain in the list fails, the whole certificate will fail.
 func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error) {
+	return c.ObtainForCSRContext(context.Background(), request)
+}
+
+// ObtainForCSRContext is like ObtainForCSR,
+// the context is passed to the challenge providers implementing challenge.ProviderContext.
+func (c *Certifier) ObtainForCSRContext(ctx context.Context, request ObtainForCSRRequest) (*Resource, error) {
+	cert, err := c.obtainForCSR(ctx, request)
+	request.Progress.done(err)
+
+	return cert, err
+}
+
+func (c *Certifier) obtainForCSR(ctx context.Context, request ObtainForCSRRequest) (*Resource, error) {
 	if request.CSR == nil {
 		return nil, errors.New("cannot obtain resource for CSR: CSR is missing")
 	}
@@ -266,6 +333,15 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 	// start with the common name
 	domains := certcrypto.ExtractDomainsCSR(request.CSR)
 
+	if len(request.Identifiers) > 0 {
+		domains = nil
+		for _, ident := range request.Identifiers {
+			domains = append(domains, ident.Value)
+		}
+	} else if err := validateDomains(domains, c.options.StrictIDNA); err != nil {
+		return nil, err
+	}
+
 	if request.Bundle {
 		log.Infof("[%s] acme: Obtaining bundled SAN certificate given a CSR", strings.Join(domains, ", "))
 	} else {
@@ -276,13 +352,24 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 		NotBefore:      request.NotBefore,
 		NotAfter:       request.NotAfter,
 		ReplacesCertID: request.ReplacesCertID,
+		Profile:        request.Profile,
 	}
 
-	order, err := c.core.Orders.NewWithOptions(domains, orderOpts)
+	request.Progress.phase(PhaseOrder)
+
+	var order acme.ExtendedOrder
+	var err error
+	if len(request.Identifiers) > 0 {
+		order, err = c.core.Orders.NewWithIdentifiers(request.Identifiers, orderOpts)
+	} else {
+		order, err = c.core.Orders.NewWithOptions(domains, orderOpts)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	request.Progress.phase(PhaseAuthorizations)
+
 	authz, err := c.getAuthorizations(order)
 	if err != nil {
 		// If any challenge fails, return. Do not generate partial SAN certificates.
@@ -290,7 +377,7 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 		return nil, err
 	}
 
-	err = c.resolver.Solve(authz)
+	err = c.solve(ctx, authz, request.Progress)
 	if err != nil {
 		// If any challenge fails, return. Do not generate partial SAN certificates.
 		c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
@@ -299,6 +386,8 @@ func (c *Certifier) ObtainForCSR(request ObtainForCSRRequest) (*Resource, error)
 
 	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
 
+	request.Progress.phase(PhaseFinalize)
+
 	failures := newObtainError()
 	cert, err := c.getForCSR(domains, order, request.Bundle, request.CSR.Raw, nil, request.PreferredChain)
 	if err != nil {
@@ -361,15 +450,23 @@ func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, bund
 }
 
 func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle bool, csr, privateKeyPem []byte, preferredChain string) (*Resource, error) {
+	// The identifiers are checked before the finalization: no certificate is issued for an unexpected order.
+	err := checkOrderIdentifiers(order, domains)
+	if err != nil {
+		return nil, err
+	}
+
 	respOrder, err := c.core.Orders.UpdateForCSR(order.Finalize, csr)
 	if err != nil {
 		return nil, err
 	}
 
 	certRes := &Resource{
-		Domain:     domains[0],
-		CertURL:    respOrder.Certificate,
-		PrivateKey: privateKeyPem,
+		Domain:            domains[0],
+		CertURL:           respOrder.Certificate,
+		PrivateKey:        privateKeyPem,
+		OrderURL:          order.Location,
+		AuthorizationURLs: order.Authorizations,
 	}
 
 	if respOrder.Status == acme.StatusValid {
@@ -380,6 +477,9 @@ func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle
 		}
 
 		if ok {
+			certRes.setARICertID()
+			checkCertificateIdentifiers(order, domains, certRes)
+
 			return certRes, nil
 		}
 	}
@@ -402,8 +502,34 @@ func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle
 
 		return done, nil
 	})
+	if err != nil {
+		return certRes, err
+	}
 
-	return certRes, err
+	certRes.setARICertID()
+	checkCertificateIdentifiers(order, domains, certRes)
+
+	return certRes, nil
+}
+
+// setARICertID sets the ARI identifier of the certificate.
+// The identifier is only informative: an error is logged, the certificate is usable without it.
+func (r *Resource) setARICertID() {
+	certificates, err := certcrypto.ParsePEMBundle(r.Certificate)
+	if err != nil {
+		log.Warnf("[%s] Unable to parse the certificate: %v", r.Domain, err)
+		return
+	}
+
+	// The certificates without an authority key identifier cannot be identified.
+	if len(certificates[0].AuthorityKeyId) == 0 {
+		return
+	}
+
+	r.ARICertID, err = MakeARICertID(certificates[0])
+	if err != nil {
+		log.Warnf("[%s] Unable to make the ARI CertID: %v", r.Domain, err)
+	}
 }
 
 // checkResponse checks to see if the certificate is ready and a link is contained in the response.
@@ -420,6 +546,10 @@ func (c *Certifier) checkResponse(order acme.ExtendedOrder, certRes *Resource, b
 		return valid, err
 	}
 
+	if preferredChain == "" {
+		preferredChain = c.options.PreferredChain
+	}
+
 	certs, err := c.core.Certificates.GetAll(order.Certificate, bundle)
 	if err != nil {
 		return false, err
@@ -467,14 +597,37 @@ func (c *Certifier) Revoke(cert []byte) error {
 
 // RevokeWithReason takes a PEM encoded certificate or bundle and tries to revoke it at the CA.
 func (c *Certifier) RevokeWithReason(cert []byte, reason *uint) error {
-	certificates, err := certcrypto.ParsePEMBundle(cert)
+	x509Cert, err := parseRevokedCertificate(cert)
 	if err != nil {
 		return err
 	}
 
-	x509Cert := certificates[0]
-	if x509Cert.IsCA {
-		return errors.New("certificate bundle starts with a CA certificate")
+	revokeMsg := acme.RevokeCertMessage{
+		Certificate: base64.RawURLEncoding.EncodeToString(x509Cert.Raw),
+		Reason:      reason,
+	}
+
+	return c.core.Certificates.Revoke(revokeMsg)
+}
+
+// RevokeWithKey takes a PEM encoded certificate or bundle and tries to revoke it at the CA.
+// The request is signed with the private key of the certificate instead of the account key:
+// the certificate can be revoked without the account that has issued it (ex: lost account, key compromise).
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.6
+func (c *Certifier) RevokeWithKey(cert []byte, privateKey crypto.PrivateKey, reason *uint) error {
+	x509Cert, err := parseRevokedCertificate(cert)
+	if err != nil {
+		return err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+
+	publicKey, ok := signer.Public().(interface{ Equal(x crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(x509Cert.PublicKey) {
+		return errors.New("the private key does not match the public key of the certificate")
 	}
 
 	revokeMsg := acme.RevokeCertMessage{
@@ -482,7 +635,21 @@ func (c *Certifier) RevokeWithReason(cert []byte, reason *uint) error {
 		Reason:      reason,
 	}
 
-	return c.core.Certificates.Revoke(revokeMsg)
+	return c.core.Certificates.RevokeWithKey(revokeMsg, privateKey)
+}
+
+func parseRevokedCertificate(cert []byte) (*x509.Certificate, error) {
+	certificates, err := certcrypto.ParsePEMBundle(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	x509Cert := certificates[0]
+	if x509Cert.IsCA {
+		return nil, errors.New("certificate bundle starts with a CA certificate")
+	}
+
+	return x509Cert, nil
 }
 
 // RenewOptions options used by Certifier.RenewWithOptions.
@@ -495,6 +662,16 @@ type RenewOptions struct {
 	AlwaysDeactivateAuthorizations bool
 	// Not supported for CSR request.
 	MustStaple bool
+	// A string uniquely identifying the certificate replaced by the new order (ARI CertID).
+	// The CertID of the renewed certificate is used if empty.
+	// The replaced certificate is only sent if the server advertises a renewal info endpoint.
+	// - https://datatracker.ietf.org/doc/html/draft-ietf-acme-ari-03#section-5
+	ReplacesCertID string
+	// The name of a certificate profile advertised by the server (ex: "tlsserver", "shortlived").
+	// - https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profile string
+	// Progress receives the phase transitions and the status updates of the domains, if defined.
+	Progress ProgressFunc
 }
 
 // Renew takes a Resource and tries to renew the certificate.
@@ -544,6 +721,11 @@ func (c *Certifier) RenewWithOptions(certRes Resource, options *RenewOptions) (*
 	timeLeft := x509Cert.NotAfter.Sub(time.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", certRes.Domain, int(timeLeft.Hours()))
 
+	replacesCertID, err := getReplacesCertID(x509Cert, certRes.ARICertID, options)
+	if err != nil {
+		return nil, err
+	}
+
 	// We always need to request a new certificate to renew.
 	// Start by checking to see if the certificate was based off a CSR,
 	// and use that if it's defined.
@@ -553,7 +735,7 @@ func (c *Certifier) RenewWithOptions(certRes Resource, options *RenewOptions) (*
 			return nil, errP
 		}
 
-		request := ObtainForCSRRequest{CSR: csr}
+		request := ObtainForCSRRequest{CSR: csr, ReplacesCertID: replacesCertID}
 
 		if options != nil {
 			request.NotBefore = options.NotBefore
@@ -561,6 +743,8 @@ func (c *Certifier) RenewWithOptions(certRes Resource, options *RenewOptions) (*
 			request.Bundle = options.Bundle
 			request.PreferredChain = options.PreferredChain
 			request.AlwaysDeactivateAuthorizations = options.AlwaysDeactivateAuthorizations
+			request.Profile = options.Profile
+			request.Progress = options.Progress
 		}
 
 		return c.ObtainForCSR(request)
@@ -575,8 +759,9 @@ func (c *Certifier) RenewWithOptions(certRes Resource, options *RenewOptions) (*
 	}
 
 	request := ObtainRequest{
-		Domains:    certcrypto.ExtractDomains(x509Cert),
-		PrivateKey: privateKey,
+		Domains:        certcrypto.ExtractDomains(x509Cert),
+		PrivateKey:     privateKey,
+		ReplacesCertID: replacesCertID,
 	}
 
 	if options != nil {
@@ -586,11 +771,32 @@ func (c *Certifier) RenewWithOptions(certRes Resource, options *RenewOptions) (*
 		request.Bundle = options.Bundle
 		request.PreferredChain = options.PreferredChain
 		request.AlwaysDeactivateAuthorizations = options.AlwaysDeactivateAuthorizations
+		request.Profile = options.Profile
+		request.Progress = options.Progress
 	}
 
 	return c.Obtain(request)
 }
 
+// getReplacesCertID returns the ARI CertID of the certificate replaced by the renewal.
+// The stored CertID (Resource.ARICertID) is used if the options don't define it.
+func getReplacesCertID(cert *x509.Certificate, storedCertID string, options *RenewOptions) (string, error) {
+	if options != nil && options.ReplacesCertID != "" {
+		return options.ReplacesCertID, nil
+	}
+
+	if storedCertID != "" {
+		return storedCertID, nil
+	}
+
+	// The certificates without an authority key identifier cannot be identified.
+	if len(cert.AuthorityKeyId) == 0 {
+		return "", nil
+	}
+
+	return MakeARICertID(cert)
+}
+
 // GetOCSP takes a PEM encoded cert or cert bundle returning the raw OCSP response,
 // the parsed response, and an error, if any.
 //
@@ -694,13 +900,17 @@ func (c *Certifier) Get(url string, bundle bool) (*Resource, error) {
 		return nil, err
 	}
 
-	return &Resource{
+	certRes := &Resource{
 		Domain:            domain,
 		Certificate:       cert,
 		IssuerCertificate: issuer,
 		CertURL:           url,
 		CertStableURL:     url,
-	}, nil
+	}
+
+	certRes.setARICertID()
+
+	return certRes, nil
 }
 
 func hasPreferredChain(issuer []byte, preferredChain string) (bool, error) {
@@ -3,11 +3,15 @@ package certificate
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
 
+	jose "github.com/go-jose/go-jose/v4"
 	"github.com/pya789/lego/v4/acme"
 	"github.com/pya789/lego/v4/acme/api"
 	"github.com/pya789/lego/v4/certcrypto"
@@ -389,6 +393,160 @@ func Test_Get(t *testing.T) {
 	assert.Equal(t, issuerMock, string(certRes.IssuerCertificate), "IssuerCertificate")
 }
 
+func Test_getForCSR_orderMetadata(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/order/1/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Order{Status: acme.StatusValid, Certificate: apiURL + "/certificate"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(certResponseMock))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	order := acme.ExtendedOrder{
+		Order: acme.Order{
+			Status:         acme.StatusReady,
+			Identifiers:    []acme.Identifier{{Type: "dns", Value: "acme.wtf"}},
+			Authorizations: []string{apiURL + "/authz/1"},
+			Finalize:       apiURL + "/order/1/finalize",
+		},
+		Location: apiURL + "/order/1",
+	}
+
+	certRes, err := certifier.getForCSR([]string{"acme.wtf"}, order, true, []byte("csr"), nil, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/order/1", certRes.OrderURL)
+	assert.Equal(t, []string{apiURL + "/authz/1"}, certRes.AuthorizationURLs)
+	// the certificate has no authority key identifier.
+	assert.Empty(t, certRes.ARICertID)
+}
+
+func Test_getForCSR_orderMismatch(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/order/1/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("the order must not be finalized")
+		http.Error(w, "unexpected finalization", http.StatusInternalServerError)
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	order := acme.ExtendedOrder{
+		Order: acme.Order{
+			Status:      acme.StatusReady,
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "acme.wtf"}, {Type: "dns", Value: "other.wtf"}},
+			Finalize:    apiURL + "/order/1/finalize",
+		},
+		Location: apiURL + "/order/1",
+	}
+
+	_, err = certifier.getForCSR([]string{"acme.wtf"}, order, true, []byte("csr"), nil, "")
+	require.EqualError(t, err, "the order doesn't match the requested identifiers (-missing, +unexpected):\n+ other.wtf")
+}
+
+func TestResource_setARICertID(t *testing.T) {
+	certRes := &Resource{Domain: "example.com", Certificate: []byte(ariLeafPEM + "\n" + issuerMock)}
+
+	certRes.setARICertID()
+
+	assert.Equal(t, ariLeafCertID, certRes.ARICertID)
+}
+
+func Test_RevokeWithKey(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	certKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cert, err := certcrypto.GeneratePemCert(certKey, "example.com", nil)
+	require.NoError(t, err)
+
+	var revoked []acme.RevokeCertMessage
+
+	mux.HandleFunc("/revokeCert", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		jws, err := jose.ParseSigned(string(body), []jose.SignatureAlgorithm{jose.RS256})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The request is signed with the key of the certificate, embedded in the JWS (no account URL).
+		header := jws.Signatures[0].Protected
+		if header.KeyID != "" || header.JSONWebKey == nil {
+			http.Error(w, "the JWS must contain the jwk of the certificate", http.StatusBadRequest)
+			return
+		}
+
+		payload, err := jws.Verify(&jose.JSONWebKey{Key: certKey.Public()})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var msg acme.RevokeCertMessage
+
+		err = json.Unmarshal(payload, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		revoked = append(revoked, msg)
+	})
+
+	accountKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", apiURL+"/account/1", accountKey)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	// the account key is not the key of the certificate.
+	err = certifier.RevokeWithKey(cert, accountKey, nil)
+	require.EqualError(t, err, "the private key does not match the public key of the certificate")
+
+	reason := acme.CRLReasonKeyCompromise
+
+	err = certifier.RevokeWithKey(cert, certKey, &reason)
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(cert)
+
+	require.Len(t, revoked, 1)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(block.Bytes), revoked[0].Certificate)
+	require.NotNil(t, revoked[0].Reason)
+	assert.Equal(t, acme.CRLReasonKeyCompromise, *revoked[0].Reason)
+}
+
 type resolverMock struct {
 	error error
 }
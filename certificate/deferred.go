@@ -0,0 +1,238 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/challenge/http01"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/platform/wait"
+)
+
+// PendingOrder an order whose challenges are solved out-of-band (ex: the DNS records are created later by another team).
+// The order is created by Certifier.Prepare, then validated and finalized by Certifier.Continue.
+type PendingOrder struct {
+	OrderURL   string             `json:"orderUrl"`
+	Domains    []string           `json:"domains"`
+	Expires    string             `json:"expires,omitempty"`
+	Challenges []PendingChallenge `json:"challenges"`
+}
+
+// PendingChallenge the values of a challenge to place out-of-band.
+//
+// For dns-01, a TXT record named FQDN with the content Value.
+// For http-01, a file served at http://<Domain><Path> with the content Value.
+type PendingChallenge struct {
+	Domain           string         `json:"domain"`
+	Type             challenge.Type `json:"type"`
+	AuthorizationURL string         `json:"authorizationUrl"`
+	ChallengeURL     string         `json:"challengeUrl"`
+	FQDN             string         `json:"fqdn,omitempty"`
+	Path             string         `json:"path,omitempty"`
+	Value            string         `json:"value"`
+}
+
+// Prepare creates an order and returns the values of the challenges to place out-of-band, without solving them.
+// Only the dns-01 and http-01 challenges are supported.
+func (c *Certifier) Prepare(request ObtainRequest, chlgType challenge.Type) (*PendingOrder, error) {
+	if chlgType != challenge.DNS01 && chlgType != challenge.HTTP01 {
+		return nil, fmt.Errorf("unsupported challenge for a deferred order: %s", chlgType)
+	}
+
+	if len(request.Domains) == 0 {
+		return nil, errors.New("no domains to obtain a certificate for")
+	}
+
+	if err := validateDomains(request.Domains, c.options.StrictIDNA); err != nil {
+		return nil, err
+	}
+
+	domains := sanitizeDomain(request.Domains)
+
+	log.Infof("[%s] acme: Creating a deferred order", strings.Join(domains, ", "))
+
+	orderOpts := &api.OrderOptions{
+		NotBefore:      request.NotBefore,
+		NotAfter:       request.NotAfter,
+		ReplacesCertID: request.ReplacesCertID,
+		Profile:        request.Profile,
+	}
+
+	order, err := c.core.Orders.NewWithOptions(domains, orderOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := &PendingOrder{
+		OrderURL: order.Location,
+		Domains:  domains,
+		Expires:  order.Expires,
+	}
+
+	// The authorization URLs are required to follow the validation later,
+	// so the authorizations are fetched one by one instead of using getAuthorizations.
+	for _, authzURL := range order.Authorizations {
+		authz, err := c.core.Authorizations.Get(authzURL)
+		if err != nil {
+			c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
+			return nil, err
+		}
+
+		// The authorizations already valid (ex: reused) don't need a challenge.
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+
+		domain := challenge.GetTargetedDomain(authz)
+
+		chlng, err := challenge.FindChallenge(chlgType, authz)
+		if err != nil {
+			c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
+			return nil, err
+		}
+
+		keyAuth, err := c.core.GetKeyAuthorization(chlng.Token)
+		if err != nil {
+			c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
+			return nil, err
+		}
+
+		pc := PendingChallenge{
+			Domain:           domain,
+			Type:             chlgType,
+			AuthorizationURL: authzURL,
+			ChallengeURL:     chlng.URL,
+		}
+
+		switch chlgType {
+		case challenge.DNS01:
+			info := dns01.GetChallengeInfo(authz.Identifier.Value, keyAuth)
+			pc.FQDN = info.EffectiveFQDN
+			pc.Value = info.Value
+		case challenge.HTTP01:
+			pc.Path = http01.ChallengePath(chlng.Token)
+			pc.Value = keyAuth
+		}
+
+		pending.Challenges = append(pending.Challenges, pc)
+	}
+
+	log.Infof("[%s] acme: Order created, the challenges must be placed before continuing: %s",
+		strings.Join(pending.Domains, ", "), pending.OrderURL)
+
+	return pending, nil
+}
+
+// Continue triggers the validation of the challenges of a pending order, and finalizes the order.
+// The domains, the private key, and the options of the certificate are read from the request.
+func (c *Certifier) Continue(pending *PendingOrder, request ObtainRequest) (*Resource, error) {
+	if pending == nil || pending.OrderURL == "" {
+		return nil, errors.New("the pending order is missing")
+	}
+
+	order, err := c.core.Orders.Get(pending.OrderURL)
+	if err != nil {
+		return nil, fmt.Errorf("get the pending order: %w", err)
+	}
+
+	// The order URL is not returned when fetching an order.
+	order.Location = pending.OrderURL
+
+	if order.Status == acme.StatusInvalid {
+		if order.Error != nil {
+			return nil, fmt.Errorf("the pending order is invalid: %w", order.Error)
+		}
+
+		return nil, errors.New("the pending order is invalid")
+	}
+
+	failures := newObtainError()
+
+	for _, pc := range pending.Challenges {
+		err = c.validatePending(pc)
+		if err != nil {
+			failures.Add(pc.Domain, err)
+		}
+	}
+
+	if err = failures.Join(); err != nil {
+		c.deactivateAuthorizations(order, request.AlwaysDeactivateAuthorizations)
+		return nil, err
+	}
+
+	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(pending.Domains, ", "))
+
+	if len(request.Domains) == 0 {
+		request.Domains = pending.Domains
+	}
+
+	authz, err := c.getAuthorizations(order)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Finalize(&order, authz, request)
+}
+
+// validatePending triggers the validation of a challenge, and waits for the validation of its authorization.
+func (c *Certifier) validatePending(pc PendingChallenge) error {
+	authz, err := c.core.Authorizations.Get(pc.AuthorizationURL)
+	if err != nil {
+		return err
+	}
+
+	if authz.Status == acme.StatusValid {
+		log.Infof("[%s] acme: authorization already valid; skipping challenge", pc.Domain)
+		return nil
+	}
+
+	log.Infof("[%s] acme: Triggering the validation of the %s challenge", pc.Domain, pc.Type)
+
+	_, err = c.core.Challenges.New(pc.ChallengeURL)
+	if err != nil {
+		return fmt.Errorf("failed to initiate challenge: %w", err)
+	}
+
+	timeout := c.options.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	var errAuthz error
+
+	err = wait.For("authorization", timeout, 2*time.Second, func() (bool, error) {
+		authz, errA := c.core.Authorizations.Get(pc.AuthorizationURL)
+		if errA != nil {
+			return false, errA
+		}
+
+		switch authz.Status {
+		case acme.StatusValid:
+			log.Infof("[%s] The server validated our request", pc.Domain)
+			return true, nil
+		case acme.StatusPending, acme.StatusProcessing:
+			return false, nil
+		default:
+			errAuthz = fmt.Errorf("the authorization state %s", authz.Status)
+
+			for _, chlg := range authz.Challenges {
+				if chlg.Status == acme.StatusInvalid && chlg.Error != nil {
+					errAuthz = chlg.Error
+				}
+			}
+
+			return true, nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return errAuthz
+}
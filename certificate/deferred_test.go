@@ -0,0 +1,103 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertifier_Prepare(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/order/1")
+		w.WriteHeader(http.StatusCreated)
+
+		err := tester.WriteJSONResponse(w, acme.Order{
+			Status:      acme.StatusPending,
+			Expires:     "2024-01-08T00:00:00Z",
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}, {Type: "dns", Value: "*.example.com"}},
+			Authorizations: []string{
+				apiURL + "/authz/1",
+				apiURL + "/authz/2",
+			},
+			Finalize: apiURL + "/order/1/finalize",
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+			Challenges: []acme.Challenge{
+				{Type: "http-01", URL: apiURL + "/chlg/1", Token: "token-http"},
+				{Type: "dns-01", URL: apiURL + "/chlg/2", Token: "token-dns"},
+			},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("/authz/2", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusValid,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+			Wildcard:   true,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	pending, err := certifier.Prepare(ObtainRequest{Domains: []string{"example.com", "*.example.com"}}, challenge.DNS01)
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/order/1", pending.OrderURL)
+	assert.Equal(t, []string{"example.com", "*.example.com"}, pending.Domains)
+	assert.Equal(t, "2024-01-08T00:00:00Z", pending.Expires)
+
+	require.Len(t, pending.Challenges, 1)
+
+	pc := pending.Challenges[0]
+	assert.Equal(t, "example.com", pc.Domain)
+	assert.Equal(t, challenge.DNS01, pc.Type)
+	assert.Equal(t, apiURL+"/authz/1", pc.AuthorizationURL)
+	assert.Equal(t, apiURL+"/chlg/2", pc.ChallengeURL)
+	assert.Equal(t, "_acme-challenge.example.com.", pc.FQDN)
+	assert.NotEmpty(t, pc.Value)
+}
+
+func TestCertifier_Prepare_unsupportedChallenge(t *testing.T) {
+	certifier := NewCertifier(nil, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	_, err := certifier.Prepare(ObtainRequest{Domains: []string{"example.com"}}, challenge.TLSALPN01)
+	require.EqualError(t, err, "unsupported challenge for a deferred order: tls-alpn-01")
+}
+
+func TestCertifier_Continue_missingOrder(t *testing.T) {
+	certifier := NewCertifier(nil, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	_, err := certifier.Continue(&PendingOrder{}, ObtainRequest{})
+	require.EqualError(t, err, "the pending order is missing")
+}
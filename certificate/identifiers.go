@@ -0,0 +1,157 @@
+package certificate
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/log"
+	"golang.org/x/net/idna"
+)
+
+// identifiersError is returned when the identifiers of the order, or of the issued certificate, don't match the requested identifiers.
+type identifiersError struct {
+	// Subject the order, or the issued certificate.
+	Subject    string
+	Missing    []string
+	Unexpected []string
+}
+
+func (e *identifiersError) Error() string {
+	var lines []string
+	for _, v := range e.Missing {
+		lines = append(lines, "- "+v)
+	}
+
+	for _, v := range e.Unexpected {
+		lines = append(lines, "+ "+v)
+	}
+
+	return fmt.Sprintf("%s doesn't match the requested identifiers (-missing, +unexpected):\n%s", e.Subject, strings.Join(lines, "\n"))
+}
+
+// checkOrderIdentifiers verifies, before the finalization, that the order covers exactly the requested identifiers.
+// Only the orders with DNS and IP identifiers are checked.
+func checkOrderIdentifiers(order acme.ExtendedOrder, domains []string) error {
+	if !isDNSOrIPOrder(order) {
+		return nil
+	}
+
+	var identifiers []string
+	for _, ident := range order.Identifiers {
+		identifiers = append(identifiers, ident.Value)
+	}
+
+	return compareIdentifiers("the order", domains, identifiers)
+}
+
+// checkCertificateIdentifiers verifies that the issued certificate covers exactly the requested identifiers.
+// The certificate is already issued: a mismatch is only logged.
+func checkCertificateIdentifiers(order acme.ExtendedOrder, domains []string, certRes *Resource) {
+	if !isDNSOrIPOrder(order) {
+		return
+	}
+
+	err := checkIdentifiers(domains, certRes.Certificate)
+	if err != nil {
+		log.Warnf("[%s] %v", certRes.Domain, err)
+	}
+}
+
+// checkIdentifiers verifies that the issued certificate covers exactly the requested identifiers.
+func checkIdentifiers(domains []string, cert []byte) error {
+	certificates, err := certcrypto.ParsePEMBundle(cert)
+	if err != nil {
+		return err
+	}
+
+	leaf := certificates[0]
+
+	issued := slices.Clone(leaf.DNSNames)
+
+	for _, ip := range leaf.IPAddresses {
+		issued = append(issued, ip.String())
+	}
+
+	// The Common Name must be one of the requested identifiers.
+	if cn := leaf.Subject.CommonName; cn != "" {
+		issued = append(issued, cn)
+	}
+
+	return compareIdentifiers("the issued certificate", domains, issued)
+}
+
+func isDNSOrIPOrder(order acme.ExtendedOrder) bool {
+	for _, ident := range order.Identifiers {
+		if ident.Type != "dns" && ident.Type != "ip" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareIdentifiers compares the identifiers (normalized) with the requested identifiers.
+func compareIdentifiers(subject string, domains, identifiers []string) error {
+	actual := map[string]struct{}{}
+
+	for _, ident := range identifiers {
+		actual[normalizeIdentifier(ident)] = struct{}{}
+	}
+
+	requested := map[string]struct{}{}
+
+	for _, domain := range domains {
+		if domain == "" {
+			continue
+		}
+
+		requested[normalizeIdentifier(domain)] = struct{}{}
+	}
+
+	e := &identifiersError{
+		Subject:    subject,
+		Missing:    difference(requested, actual),
+		Unexpected: difference(actual, requested),
+	}
+
+	if len(e.Missing) > 0 || len(e.Unexpected) > 0 {
+		return e
+	}
+
+	return nil
+}
+
+// normalizeIdentifier returns the form of the identifier as it appears in a certificate.
+func normalizeIdentifier(value string) string {
+	if ip := net.ParseIP(value); ip != nil {
+		return ip.String()
+	}
+
+	value = strings.TrimSuffix(strings.ToLower(value), ".")
+
+	ascii, err := idna.ToASCII(value)
+	if err != nil {
+		return value
+	}
+
+	return ascii
+}
+
+func difference(a, b map[string]struct{}) []string {
+	var diff []string
+
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			diff = append(diff, k)
+		}
+	}
+
+	sort.Strings(diff)
+
+	return diff
+}
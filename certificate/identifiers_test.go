@@ -0,0 +1,140 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkIdentifiers(t *testing.T) {
+	cert := generateTestCertificate(t, "example.com",
+		[]string{"example.com", "*.example.com", "xn--bcher-kva.example"},
+		[]net.IP{net.ParseIP("2001:db8::1")})
+
+	testCases := []struct {
+		desc     string
+		domains  []string
+		expected *identifiersError
+	}{
+		{
+			desc:    "match",
+			domains: []string{"Example.com.", "*.example.com", "bücher.example", "2001:0db8:0000:0000:0000:0000:0000:0001", ""},
+		},
+		{
+			desc:    "missing",
+			domains: []string{"example.com", "*.example.com", "bücher.example", "2001:db8::1", "www.example.org", "192.0.2.1"},
+			expected: &identifiersError{
+				Subject: "the issued certificate",
+				Missing: []string{"192.0.2.1", "www.example.org"},
+			},
+		},
+		{
+			desc:    "unexpected",
+			domains: []string{"example.com", "*.example.com"},
+			expected: &identifiersError{
+				Subject:    "the issued certificate",
+				Unexpected: []string{"2001:db8::1", "xn--bcher-kva.example"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkIdentifiers(test.domains, cert)
+			if test.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, test.expected, err)
+		})
+	}
+}
+
+func Test_checkOrderIdentifiers(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		identifiers []acme.Identifier
+		expected    *identifiersError
+	}{
+		{
+			desc: "match",
+			identifiers: []acme.Identifier{
+				{Type: "dns", Value: "example.com"},
+				{Type: "dns", Value: "xn--bcher-kva.example"},
+				{Type: "ip", Value: "2001:db8::1"},
+			},
+		},
+		{
+			desc: "mismatch",
+			identifiers: []acme.Identifier{
+				{Type: "dns", Value: "example.com"},
+				{Type: "dns", Value: "www.example.org"},
+			},
+			expected: &identifiersError{
+				Subject:    "the order",
+				Missing:    []string{"2001:db8::1", "xn--bcher-kva.example"},
+				Unexpected: []string{"www.example.org"},
+			},
+		},
+		{
+			desc: "other identifier types",
+			identifiers: []acme.Identifier{
+				{Type: "dns", Value: "example.com"},
+				{Type: "permanent-identifier", Value: "device-1"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			order := acme.ExtendedOrder{Order: acme.Order{Identifiers: test.identifiers}}
+
+			err := checkOrderIdentifiers(order, []string{"Example.com", "bücher.example", "2001:db8::1"})
+			if test.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, test.expected, err)
+		})
+	}
+}
+
+func generateTestCertificate(t *testing.T, commonName string, dnsNames []string, ips []net.IP) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
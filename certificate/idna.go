@@ -0,0 +1,122 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pya789/lego/v4/log"
+	"golang.org/x/net/idna"
+)
+
+// strictIDNA the IDNA2008/UTS-46 profile used to validate the domains in strict mode.
+var strictIDNA = idna.New(
+	idna.ValidateForRegistration(),
+	idna.BidiRule(),
+	idna.CheckJoiners(true),
+	idna.StrictDomainName(true),
+	idna.Transitional(false),
+)
+
+// scripts the scripts checked to detect mixed-script labels.
+var scripts = map[string]*unicode.RangeTable{
+	"Latin":    unicode.Latin,
+	"Cyrillic": unicode.Cyrillic,
+	"Greek":    unicode.Greek,
+	"Armenian": unicode.Armenian,
+	"Hebrew":   unicode.Hebrew,
+	"Arabic":   unicode.Arabic,
+	"Georgian": unicode.Georgian,
+	"Cherokee": unicode.Cherokee,
+	"Han":      unicode.Han,
+	"Hiragana": unicode.Hiragana,
+	"Katakana": unicode.Katakana,
+	"Bopomofo": unicode.Bopomofo,
+	"Hangul":   unicode.Hangul,
+	"Thai":     unicode.Thai,
+}
+
+// allowedScriptSets the combinations of scripts allowed in a label (UTS-39 "Highly Restrictive" level).
+var allowedScriptSets = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// validateDomains validates the domains before the creation of an order.
+// The domains are validated with the IDNA2008/UTS-46 strict rules if strict is true,
+// and a warning is logged for each label mixing scripts (potential homograph).
+func validateDomains(domains []string, strict bool) error {
+	var errs []error
+
+	for _, domain := range domains {
+		if net.ParseIP(domain) != nil {
+			continue
+		}
+
+		name := strings.TrimPrefix(domain, "*.")
+
+		if strict {
+			_, err := strictIDNA.ToASCII(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid domain %q (IDNA2008): %w", domain, err))
+				continue
+			}
+		}
+
+		unicodeName, err := idna.ToUnicode(name)
+		if err != nil {
+			continue
+		}
+
+		for _, label := range strings.Split(unicodeName, ".") {
+			if labelScripts := mixedScripts(label); len(labelScripts) > 0 {
+				log.Warnf("[%s] the label %q mixes scripts (%s): it can be a homograph of another domain", domain, label, strings.Join(labelScripts, ", "))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// mixedScripts returns the scripts of the label if the combination of scripts is not allowed.
+func mixedScripts(label string) []string {
+	var found []string
+
+	for _, r := range label {
+		for name, table := range scripts {
+			if unicode.Is(table, r) && !slices.Contains(found, name) {
+				found = append(found, name)
+				break
+			}
+		}
+	}
+
+	if len(found) < 2 {
+		return nil
+	}
+
+	for _, set := range allowedScriptSets {
+		if containsAll(set, found) {
+			return nil
+		}
+	}
+
+	sort.Strings(found)
+
+	return found
+}
+
+func containsAll(set, values []string) bool {
+	for _, v := range values {
+		if !slices.Contains(set, v) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,85 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validateDomains(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		domains    []string
+		strict     bool
+		requireErr require.ErrorAssertionFunc
+	}{
+		{
+			desc:       "valid domains",
+			domains:    []string{"example.com", "*.example.com", "bücher.example", "192.0.2.1"},
+			strict:     true,
+			requireErr: require.NoError,
+		},
+		{
+			desc:       "invalid domain (strict)",
+			domains:    []string{"foo_bar.example.com"},
+			strict:     true,
+			requireErr: require.Error,
+		},
+		{
+			desc:       "invalid domain (not strict)",
+			domains:    []string{"foo_bar.example.com"},
+			requireErr: require.NoError,
+		},
+		{
+			desc:       "invalid punycode (strict)",
+			domains:    []string{"xn--a.example.com"},
+			strict:     true,
+			requireErr: require.Error,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateDomains(test.domains, test.strict)
+			test.requireErr(t, err)
+		})
+	}
+}
+
+func Test_mixedScripts(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		label    string
+		expected []string
+	}{
+		{
+			desc:  "latin",
+			label: "paypal",
+		},
+		{
+			desc:     "latin and cyrillic",
+			label:    "pаypal",
+			expected: []string{"Cyrillic", "Latin"},
+		},
+		{
+			desc:  "latin, han and hiragana",
+			label: "abc漢字ひらがな",
+		},
+		{
+			desc:     "greek and cyrillic",
+			label:    "αа",
+			expected: []string{"Cyrillic", "Greek"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, mixedScripts(test.label))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/log"
+)
+
+// PreAuthorization the authorization of an identifier created ahead of time (pre-authorization).
+type PreAuthorization struct {
+	Domain  string
+	URL     string
+	Status  string
+	Expires time.Time
+}
+
+// PreAuthorize validates the domains ahead of time with the newAuthz endpoint of the CA (pre-authorization).
+// The valid authorizations are reused by the CA for the next orders of the account:
+// the challenges are not solved when the certificate is obtained.
+// The wildcard domains cannot be pre-authorized.
+// - https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1
+func (c *Certifier) PreAuthorize(domains []string) ([]PreAuthorization, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("no domains to pre-authorize")
+	}
+
+	if err := validateDomains(domains, c.options.StrictIDNA); err != nil {
+		return nil, err
+	}
+
+	domains = sanitizeDomain(domains)
+
+	for _, domain := range domains {
+		if strings.HasPrefix(domain, "*.") {
+			return nil, fmt.Errorf("[%s] acme: a wildcard domain cannot be pre-authorized", domain)
+		}
+	}
+
+	log.Infof("[%s] acme: Pre-authorizing", strings.Join(domains, ", "))
+
+	var results []PreAuthorization
+	var authorizations []acme.Authorization
+
+	for _, domain := range domains {
+		identifier := acme.Identifier{Type: "dns", Value: domain}
+		if ip := net.ParseIP(domain); ip != nil {
+			identifier.Type = "ip"
+			identifier.Value = ip.String()
+		}
+
+		authz, err := c.core.Authorizations.New(identifier)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] acme: could not create the authorization: %w", domain, err)
+		}
+
+		log.Infof("[%s] AuthURL: %s", domain, authz.Location)
+
+		results = append(results, PreAuthorization{Domain: domain, URL: authz.Location, Status: authz.Status, Expires: authz.Expires})
+		authorizations = append(authorizations, authz.Authorization)
+	}
+
+	// The already valid authorizations are skipped by the resolver.
+	errSolve := c.resolver.Solve(authorizations)
+
+	for i, result := range results {
+		authz, err := c.core.Authorizations.Get(result.URL)
+		if err != nil {
+			log.Warnf("[%s] acme: could not get the authorization: %v", result.Domain, err)
+			continue
+		}
+
+		results[i].Status = authz.Status
+		results[i].Expires = authz.Expires
+	}
+
+	return results, errSolve
+}
@@ -0,0 +1,70 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertifier_PreAuthorize(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	expires := time.Date(2024, time.January, 8, 0, 0, 0, 0, time.UTC)
+
+	mux.HandleFunc("/newAuthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", apiURL+"/authz/1")
+		w.WriteHeader(http.StatusCreated)
+
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+			Challenges: []acme.Challenge{{Type: "dns-01", URL: apiURL + "/chlg/1", Token: "token"}},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusValid,
+			Expires:    expires,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	results, err := certifier.PreAuthorize([]string{"example.com"})
+	require.NoError(t, err)
+
+	expected := []PreAuthorization{{
+		Domain:  "example.com",
+		URL:     apiURL + "/authz/1",
+		Status:  acme.StatusValid,
+		Expires: expires,
+	}}
+
+	assert.Equal(t, expected, results)
+
+	_, err = certifier.PreAuthorize([]string{"*.example.com"})
+	require.EqualError(t, err, "[*.example.com] acme: a wildcard domain cannot be pre-authorized")
+}
@@ -0,0 +1,84 @@
+package certificate
+
+import (
+	"context"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/challenge"
+)
+
+// Phase a phase of a certificate request.
+type Phase string
+
+const (
+	// PhaseOrder the creation of the order.
+	PhaseOrder Phase = "order"
+	// PhaseAuthorizations the retrieval of the authorizations of the order.
+	PhaseAuthorizations Phase = "authorizations"
+	// PhaseChallenges the resolution of the challenges: the status of each domain is reported by the events of this phase.
+	PhaseChallenges Phase = "challenges"
+	// PhaseFinalize the finalization of the order and the download of the certificate.
+	PhaseFinalize Phase = "finalize"
+	// PhaseDone the end of the request: the event contains the error of the request, if any.
+	PhaseDone Phase = "done"
+)
+
+// ProgressEvent a progress update of a certificate request.
+// An event without Domain is a phase transition,
+// an event with a Domain is a status update of the challenge of this domain.
+type ProgressEvent struct {
+	Phase  Phase
+	Domain string
+	Status challenge.Status
+	Err    error
+}
+
+// ProgressFunc receives the progress updates of a certificate request (Obtain, ObtainForCSR, RenewWithOptions).
+// The function is called synchronously, from the goroutine of the request:
+// to display the progress in another goroutine (ex: a UI), the function can send the events to a channel.
+type ProgressFunc func(event ProgressEvent)
+
+func (f ProgressFunc) phase(phase Phase) {
+	if f != nil {
+		f(ProgressEvent{Phase: phase})
+	}
+}
+
+func (f ProgressFunc) done(err error) {
+	if f != nil {
+		f(ProgressEvent{Phase: PhaseDone, Err: err})
+	}
+}
+
+// statusResolver a resolver able to report the status of the challenge of each domain.
+type statusResolver interface {
+	SolveWithStatus(authorizations []acme.Authorization, notify challenge.StatusFunc) error
+}
+
+// contextResolver a resolver able to pass a context to the challenge providers.
+type contextResolver interface {
+	SolveContext(ctx context.Context, authorizations []acme.Authorization, notify challenge.StatusFunc) error
+}
+
+// solve solves the challenges, and reports the status of each domain to the progress function.
+func (c *Certifier) solve(ctx context.Context, authorizations []acme.Authorization, progress ProgressFunc) error {
+	progress.phase(PhaseChallenges)
+
+	var notify challenge.StatusFunc
+	if progress != nil {
+		notify = func(domain string, status challenge.Status, err error) {
+			progress(ProgressEvent{Phase: PhaseChallenges, Domain: domain, Status: status, Err: err})
+		}
+	}
+
+	if r, ok := c.resolver.(contextResolver); ok {
+		return r.SolveContext(ctx, authorizations, notify)
+	}
+
+	r, ok := c.resolver.(statusResolver)
+	if !ok || notify == nil {
+		return c.resolver.Solve(authorizations)
+	}
+
+	return r.SolveWithStatus(authorizations, notify)
+}
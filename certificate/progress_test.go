@@ -0,0 +1,56 @@
+package certificate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statusResolverMock struct {
+	resolverMock
+}
+
+func (r *statusResolverMock) SolveWithStatus(authorizations []acme.Authorization, notify challenge.StatusFunc) error {
+	for _, authz := range authorizations {
+		notify(authz.Identifier.Value, challenge.StatusValid, nil)
+	}
+
+	return r.error
+}
+
+func TestCertifier_solve_progress(t *testing.T) {
+	certifier := NewCertifier(nil, &statusResolverMock{resolverMock{error: errors.New("oops")}}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	var events []ProgressEvent
+
+	authz := []acme.Authorization{
+		{Identifier: acme.Identifier{Type: "dns", Value: "example.com"}},
+		{Identifier: acme.Identifier{Type: "dns", Value: "example.org"}},
+	}
+
+	err := certifier.solve(context.Background(), authz, func(event ProgressEvent) {
+		events = append(events, event)
+	})
+	require.EqualError(t, err, "oops")
+
+	expected := []ProgressEvent{
+		{Phase: PhaseChallenges},
+		{Phase: PhaseChallenges, Domain: "example.com", Status: challenge.StatusValid},
+		{Phase: PhaseChallenges, Domain: "example.org", Status: challenge.StatusValid},
+	}
+
+	assert.Equal(t, expected, events)
+}
+
+func TestCertifier_solve_noProgress(t *testing.T) {
+	certifier := NewCertifier(nil, &statusResolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	err := certifier.solve(context.Background(), []acme.Authorization{{Identifier: acme.Identifier{Type: "dns", Value: "example.com"}}}, nil)
+	require.NoError(t, err)
+}
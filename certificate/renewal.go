@@ -7,10 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/platform/random"
 )
 
 // RenewalInfoRequest contains the necessary renewal information.
@@ -42,7 +43,7 @@ func (r *RenewalInfoResponse) ShouldRenewAt(now time.Time, willingToSleep time.D
 
 	// Select a uniform random time within the suggested window.
 	window := end.Sub(start)
-	randomDuration := time.Duration(rand.Int63n(int64(window)))
+	randomDuration := random.Duration(window)
 	rt := start.Add(randomDuration)
 
 	// If the selected time is in the past, attempt renewal immediately.
@@ -76,6 +77,11 @@ func (c *Certifier) GetRenewalInfo(req RenewalInfoRequest) (*RenewalInfoResponse
 		return nil, fmt.Errorf("error making certID: %w", err)
 	}
 
+	return c.getRenewalInfo(certID)
+}
+
+// getRenewalInfo sends a request to the renewalInfo endpoint for the ARI CertID.
+func (c *Certifier) getRenewalInfo(certID string) (*RenewalInfoResponse, error) {
 	resp, err := c.core.Certificates.GetRenewalInfo(certID)
 	if err != nil {
 		return nil, err
@@ -98,6 +104,29 @@ func (c *Certifier) GetRenewalInfo(req RenewalInfoRequest) (*RenewalInfoResponse
 	return &info, nil
 }
 
+// RenewalInfo is like GetRenewalInfo, for the certificate of a Resource (the first certificate of the bundle).
+// The suggested renewal window is honored with the ShouldRenewAt method of the returned RenewalInfoResponse object,
+// and the certificate is replaced with RenewWithOptions (the new order references the certificate with the `replaces` field).
+//
+// This method will return api.ErrNoARI if the server does not advertise a renewal info endpoint.
+func (c *Certifier) RenewalInfo(certRes Resource) (*RenewalInfoResponse, error) {
+	certificates, err := certcrypto.ParsePEMBundle(certRes.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	if certificates[0].IsCA {
+		return nil, fmt.Errorf("[%s] Certificate bundle starts with a CA certificate", certRes.Domain)
+	}
+
+	// The stored CertID avoids to re-derive it from the certificate.
+	if certRes.ARICertID != "" {
+		return c.getRenewalInfo(certRes.ARICertID)
+	}
+
+	return c.GetRenewalInfo(RenewalInfoRequest{Cert: certificates[0]})
+}
+
 // MakeARICertID constructs a certificate identifier as described in draft-ietf-acme-ari-03, section 4.1.
 func MakeARICertID(leaf *x509.Certificate) (string, error) {
 	if leaf == nil {
@@ -80,6 +80,50 @@ func TestCertifier_GetRenewalInfo(t *testing.T) {
 	assert.Equal(t, time.Duration(21600000000000), ri.RetryAfter)
 }
 
+func TestCertifier_RenewalInfo(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+	mux.HandleFunc("/renewalInfo/"+ariLeafCertID, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, wErr := w.Write([]byte(`{"suggestedWindow": {"start": "2020-03-17T17:51:09Z", "end": "2020-03-17T18:21:09Z"}}`))
+		require.NoError(t, wErr)
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	ri, err := certifier.RenewalInfo(Resource{Domain: "example.com", Certificate: []byte(ariLeafPEM)})
+	require.NoError(t, err)
+
+	assert.Equal(t, "2020-03-17T17:51:09Z", ri.SuggestedWindow.Start.Format(time.RFC3339))
+	assert.Equal(t, "2020-03-17T18:21:09Z", ri.SuggestedWindow.End.Format(time.RFC3339))
+}
+
+func Test_getReplacesCertID(t *testing.T) {
+	leaf, err := certcrypto.ParsePEMCertificate([]byte(ariLeafPEM))
+	require.NoError(t, err)
+
+	certID, err := getReplacesCertID(leaf, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, ariLeafCertID, certID)
+
+	certID, err = getReplacesCertID(leaf, "", &RenewOptions{ReplacesCertID: "foo.bar"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo.bar", certID)
+
+	certID, err = getReplacesCertID(leaf, "stored.id", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "stored.id", certID)
+
+	certID, err = getReplacesCertID(leaf, "stored.id", &RenewOptions{ReplacesCertID: "foo.bar"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo.bar", certID)
+}
+
 func TestCertifier_GetRenewalInfo_errors(t *testing.T) {
 	leaf, err := certcrypto.ParsePEMCertificate([]byte(ariLeafPEM))
 	require.NoError(t, err)
@@ -0,0 +1,58 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/log"
+)
+
+// ResumeOrder fetches an existing order (ex: the order of an interrupted request) and its authorizations,
+// to continue the request with Challenge and Finalize without creating a new order (rate limits).
+// The authorizations already valid are not solved again.
+//
+// Only the orders not yet finalized ("pending" or "ready") can be resumed:
+// the private key of a finalized order is not known.
+func (c *Certifier) ResumeOrder(orderURL string) (*acme.ExtendedOrder, []acme.Authorization, error) {
+	if orderURL == "" {
+		return nil, nil, errors.New("the order URL is missing")
+	}
+
+	order, err := c.core.Orders.Get(orderURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get the order: %w", err)
+	}
+
+	// The order URL is not returned when fetching an order.
+	order.Location = orderURL
+
+	switch order.Status {
+	case acme.StatusPending, acme.StatusReady:
+	case acme.StatusInvalid:
+		if order.Error != nil {
+			return nil, nil, fmt.Errorf("the order is invalid: %w", order.Error)
+		}
+
+		return nil, nil, errors.New("the order is invalid")
+	default:
+		return nil, nil, fmt.Errorf("the order cannot be resumed: the order is %s", order.Status)
+	}
+
+	if order.Expires != "" {
+		expires, errP := time.Parse(time.RFC3339, order.Expires)
+		if errP == nil && time.Now().After(expires) {
+			return nil, nil, fmt.Errorf("the order has expired at %s", order.Expires)
+		}
+	}
+
+	log.Infof("acme: Resuming the order %s", orderURL)
+
+	authz, err := c.getAuthorizations(order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &order, authz, nil
+}
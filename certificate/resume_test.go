@@ -0,0 +1,89 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertifier_ResumeOrder(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	expires := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	orders := map[string]acme.Order{
+		"/order/pending": {Status: acme.StatusPending, Expires: expires},
+		"/order/expired": {Status: acme.StatusPending, Expires: "2024-01-08T00:00:00Z"},
+		"/order/valid":   {Status: acme.StatusValid, Expires: expires},
+		"/order/invalid": {Status: acme.StatusInvalid, Expires: expires},
+	}
+
+	for path, order := range orders {
+		order.Identifiers = []acme.Identifier{{Type: "dns", Value: "example.com"}}
+		order.Authorizations = []string{apiURL + "/authz/1"}
+		order.Finalize = apiURL + path + "/finalize"
+
+		mux.HandleFunc(path, func(w http.ResponseWriter, _ *http.Request) {
+			err := tester.WriteJSONResponse(w, order)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		})
+	}
+
+	mux.HandleFunc("/authz/1", func(w http.ResponseWriter, _ *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusValid,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &resolverMock{}, CertifierOptions{KeyType: certcrypto.RSA2048})
+
+	order, authz, err := certifier.ResumeOrder(apiURL + "/order/pending")
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/order/pending", order.Location)
+	assert.Equal(t, apiURL+"/order/pending/finalize", order.Finalize)
+
+	require.Len(t, authz, 1)
+	assert.Equal(t, acme.StatusValid, authz[0].Status)
+
+	testCases := []struct {
+		desc     string
+		orderURL string
+		expected string
+	}{
+		{desc: "missing URL", expected: "the order URL is missing"},
+		{desc: "expired", orderURL: apiURL + "/order/expired", expected: "the order has expired at 2024-01-08T00:00:00Z"},
+		{desc: "finalized", orderURL: apiURL + "/order/valid", expected: "the order cannot be resumed: the order is valid"},
+		{desc: "invalid", orderURL: apiURL + "/order/invalid", expected: "the order is invalid"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := certifier.ResumeOrder(test.orderURL)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"golang.org/x/crypto/ocsp"
+)
+
+// maxCRLSize is the maximum size of a CRL that we will read.
+const maxCRLSize = 50 * 1024 * 1024
+
+// RevocationStatus the revocation status of a certificate.
+type RevocationStatus struct {
+	Revoked bool
+	// Reason the revocation reason code (RFC 5280).
+	Reason int
+	// Source the URL of the CRL or of the OCSP responder used to check the status.
+	Source string
+}
+
+// CheckRevocation takes a PEM encoded cert or cert bundle and checks its revocation status.
+//
+// The CRL distribution points of the certificate are used first,
+// if there is no CRL distribution point, the OCSP responder is used.
+//
+// The CRLs are only used if the bundle contains the issuer certificate, to verify their signatures:
+// an unverifiable or outdated CRL is an error, and never reports the certificate as revoked.
+func (c *Certifier) CheckRevocation(bundle []byte) (*RevocationStatus, error) {
+	certificates, err := certcrypto.ParsePEMBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := certificates[0]
+	if leaf.IsCA {
+		return nil, errors.New("certificate bundle starts with a CA certificate")
+	}
+
+	var issuer *x509.Certificate
+	if len(certificates) > 1 {
+		issuer = certificates[1]
+	}
+
+	var errAll error
+
+	for _, uri := range leaf.CRLDistributionPoints {
+		status, errC := c.checkCRL(leaf, issuer, uri)
+		if errC != nil {
+			errAll = errors.Join(errAll, errC)
+			continue
+		}
+
+		return status, nil
+	}
+
+	if len(leaf.OCSPServer) == 0 {
+		if errAll != nil {
+			return nil, errAll
+		}
+
+		return nil, errors.New("no CRL distribution point and no OCSP server specified in cert")
+	}
+
+	_, ocspRes, err := c.GetOCSP(bundle)
+	if err != nil {
+		return nil, errors.Join(errAll, err)
+	}
+
+	return &RevocationStatus{
+		Revoked: ocspRes.Status == ocsp.Revoked,
+		Reason:  ocspRes.RevocationReason,
+		Source:  leaf.OCSPServer[0],
+	}, nil
+}
+
+func (c *Certifier) checkCRL(leaf, issuer *x509.Certificate, uri string) (*RevocationStatus, error) {
+	if issuer == nil {
+		return nil, fmt.Errorf("CRL %s: unable to verify the CRL without the issuer certificate", uri)
+	}
+
+	err := leaf.CheckSignatureFrom(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("CRL %s: the certificate is not signed by the issuer certificate of the bundle: %w", uri, err)
+	}
+
+	resp, err := c.core.HTTPClient.Get(uri)
+	if err != nil {
+		return nil, fmt.Errorf("CRL %s: %w", uri, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CRL %s: unexpected status code: %d", uri, resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(http.MaxBytesReader(nil, resp.Body, maxCRLSize))
+	if err != nil {
+		return nil, fmt.Errorf("CRL %s: %w", uri, err)
+	}
+
+	crl, err := x509.ParseRevocationList(raw)
+	if err != nil {
+		return nil, fmt.Errorf("CRL %s: %w", uri, err)
+	}
+
+	err = crl.CheckSignatureFrom(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("CRL %s: %w", uri, err)
+	}
+
+	if !crl.NextUpdate.IsZero() && time.Now().After(crl.NextUpdate) {
+		return nil, fmt.Errorf("CRL %s: outdated CRL, the next update was expected at %s", uri, crl.NextUpdate.Format(time.RFC3339))
+	}
+
+	status := &RevocationStatus{Source: uri}
+
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+			status.Revoked = true
+			status.Reason = entry.ReasonCode
+
+			break
+		}
+	}
+
+	return status, nil
+}
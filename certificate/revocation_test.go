@@ -0,0 +1,192 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertifier_CheckRevocation(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		revoked  []x509.RevocationListEntry
+		expected *RevocationStatus
+	}{
+		{
+			desc: "not revoked",
+			revoked: []x509.RevocationListEntry{
+				{SerialNumber: big.NewInt(3), RevocationTime: time.Now()},
+			},
+			expected: &RevocationStatus{Revoked: false},
+		},
+		{
+			desc: "revoked",
+			revoked: []x509.RevocationListEntry{
+				{SerialNumber: big.NewInt(2), RevocationTime: time.Now(), ReasonCode: 1},
+			},
+			expected: &RevocationStatus{Revoked: true, Reason: 1},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			fixture := setupRevocationFixture(t, time.Now().Add(time.Hour), test.revoked)
+
+			status, err := fixture.certifier.CheckRevocation(append(fixture.leafPEM, fixture.issuerPEM...))
+			require.NoError(t, err)
+
+			test.expected.Source = fixture.crlURL
+
+			assert.Equal(t, test.expected, status)
+		})
+	}
+}
+
+func TestCertifier_CheckRevocation_unverifiable(t *testing.T) {
+	revoked := []x509.RevocationListEntry{
+		{SerialNumber: big.NewInt(2), RevocationTime: time.Now(), ReasonCode: 1},
+	}
+
+	testCases := []struct {
+		desc       string
+		nextUpdate time.Time
+		issuer     func(t *testing.T, fixture *revocationFixture) []byte
+		expected   string
+	}{
+		{
+			desc:       "without issuer certificate",
+			nextUpdate: time.Now().Add(time.Hour),
+			issuer:     func(*testing.T, *revocationFixture) []byte { return nil },
+			expected:   "unable to verify the CRL without the issuer certificate",
+		},
+		{
+			desc:       "another issuer certificate",
+			nextUpdate: time.Now().Add(time.Hour),
+			issuer: func(t *testing.T, _ *revocationFixture) []byte {
+				t.Helper()
+
+				_, issuerPEM := createTestCA(t)
+
+				return issuerPEM
+			},
+			expected: "the certificate is not signed by the issuer certificate of the bundle",
+		},
+		{
+			desc:       "outdated CRL",
+			nextUpdate: time.Now().Add(-time.Minute),
+			issuer:     func(_ *testing.T, fixture *revocationFixture) []byte { return fixture.issuerPEM },
+			expected:   "outdated CRL",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			fixture := setupRevocationFixture(t, test.nextUpdate, revoked)
+
+			status, err := fixture.certifier.CheckRevocation(append(fixture.leafPEM, test.issuer(t, fixture)...))
+			require.ErrorContains(t, err, test.expected)
+
+			assert.Nil(t, status)
+		})
+	}
+}
+
+type revocationFixture struct {
+	certifier *Certifier
+	leafPEM   []byte
+	issuerPEM []byte
+	crlURL    string
+}
+
+// setupRevocationFixture creates a CA, a certificate (serial number 2) with a CRL distribution point, and serves the CRL of the CA.
+func setupRevocationFixture(t *testing.T, nextUpdate time.Time, revoked []x509.RevocationListEntry) *revocationFixture {
+	t.Helper()
+
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	caKey, caCert := createTestCA(t)
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "example.com"},
+		DNSNames:              []string{"example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		CRLDistributionPoints: []string{apiURL + "/crl"},
+	}
+
+	issuer, err := x509.ParseCertificate(pemDecode(t, caCert))
+	require.NoError(t, err)
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, issuer, caKey.Public(), caKey)
+	require.NoError(t, err)
+
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-2 * time.Hour),
+		NextUpdate:                nextUpdate,
+		RevokedCertificateEntries: revoked,
+	}, issuer, caKey)
+	require.NoError(t, err)
+
+	mux.HandleFunc("/crl", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(crlDER)
+	})
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	return &revocationFixture{
+		certifier: NewCertifier(core, &resolverMock{}, CertifierOptions{}),
+		leafPEM:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}),
+		issuerPEM: caCert,
+		crlURL:    apiURL + "/crl",
+	}
+}
+
+// createTestCA creates a self-signed CA, returns its key and its PEM encoded certificate.
+func createTestCA(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	require.NoError(t, err)
+
+	return caKey, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+}
+
+func pemDecode(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+
+	return block.Bytes
+}
@@ -20,6 +20,9 @@ const (
 
 	// TLSALPN01 is the "tls-alpn-01" ACME challenge https://www.rfc-editor.org/rfc/rfc8737.html
 	TLSALPN01 = Type("tls-alpn-01")
+
+	// DEVICEATTEST01 is the "device-attest-01" ACME challenge https://datatracker.ietf.org/doc/draft-acme-device-attest/
+	DEVICEATTEST01 = Type("device-attest-01")
 )
 
 func (t Type) String() string {
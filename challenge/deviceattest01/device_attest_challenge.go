@@ -0,0 +1,84 @@
+package deviceattest01
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/log"
+)
+
+// Attester provides the attestation of a device.
+type Attester interface {
+	// Attest returns the WebAuthn attestation object (CBOR encoded) of the device (ex: TPM, Apple Secure Enclave).
+	// The challenge of the attestation (nonce) is the digest (SHA-256) of the key authorization.
+	Attest(identifier acme.Identifier, keyAuthDigest []byte) ([]byte, error)
+}
+
+type ValidateFunc func(core *api.Core, domain string, chlng acme.Challenge, payload interface{}) error
+
+// Payload the payload sent to the CA to respond to the challenge.
+// - https://datatracker.ietf.org/doc/html/draft-acme-device-attest-03#section-5
+type Payload struct {
+	// AttObj the base64url encoded attestation object.
+	AttObj string `json:"attObj"`
+}
+
+// Challenge implements the device-attest-01 challenge.
+type Challenge struct {
+	core     *api.Core
+	validate ValidateFunc
+	attester Attester
+}
+
+func NewChallenge(core *api.Core, validate ValidateFunc, attester Attester) *Challenge {
+	return &Challenge{
+		core:     core,
+		validate: validate,
+		attester: attester,
+	}
+}
+
+func (c *Challenge) SetAttester(attester Attester) {
+	c.attester = attester
+}
+
+// Solve produces the attestation of the device and sends it to the CA.
+func (c *Challenge) Solve(authz acme.Authorization) error {
+	identifier := authz.Identifier.Value
+	log.Infof("[%s] acme: Trying to solve DEVICE-ATTEST-01", identifier)
+
+	chlng, err := challenge.FindChallenge(challenge.DEVICEATTEST01, authz)
+	if err != nil {
+		return err
+	}
+
+	if c.attester == nil {
+		return fmt.Errorf("[%s] acme: no attester configured", identifier)
+	}
+
+	// Generate the Key Authorization for the challenge
+	keyAuth, err := c.core.GetKeyAuthorization(chlng.Token)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256([]byte(keyAuth))
+
+	attObj, err := c.attester.Attest(authz.Identifier, digest[:])
+	if err != nil {
+		return fmt.Errorf("[%s] acme: error during the attestation: %w", identifier, err)
+	}
+
+	if len(attObj) == 0 {
+		return errors.New("acme: empty attestation object")
+	}
+
+	chlng.KeyAuthorization = keyAuth
+
+	return c.validate(c.core, identifier, chlng, Payload{AttObj: base64.RawURLEncoding.EncodeToString(attObj)})
+}
@@ -0,0 +1,93 @@
+package deviceattest01
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallenge_Solve(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	attester := &fakeAttester{}
+
+	mockValidate := func(_ *api.Core, identifier string, chlng acme.Challenge, payload interface{}) error {
+		assert.Equal(t, "serial-1234", identifier)
+
+		digest := sha256.Sum256([]byte(chlng.KeyAuthorization))
+		assert.Equal(t, digest[:], attester.digest)
+
+		assert.Equal(t, Payload{AttObj: base64.RawURLEncoding.EncodeToString([]byte("attestation"))}, payload)
+
+		return nil
+	}
+
+	solver := NewChallenge(core, mockValidate, attester)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Type: "permanent-identifier", Value: "serial-1234"},
+		Challenges: []acme.Challenge{
+			{Type: "device-attest-01", Token: "token"},
+		},
+	}
+
+	err = solver.Solve(authz)
+	require.NoError(t, err)
+}
+
+func TestChallenge_Solve_error(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	mockValidate := func(_ *api.Core, _ string, _ acme.Challenge, _ interface{}) error {
+		return errors.New("should not be called")
+	}
+
+	solver := NewChallenge(core, mockValidate, &fakeAttester{err: errors.New("no TPM")})
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Type: "permanent-identifier", Value: "serial-1234"},
+		Challenges: []acme.Challenge{
+			{Type: "device-attest-01", Token: "token"},
+		},
+	}
+
+	err = solver.Solve(authz)
+	require.EqualError(t, err, "[serial-1234] acme: error during the attestation: no TPM")
+}
+
+type fakeAttester struct {
+	digest []byte
+	err    error
+}
+
+func (a *fakeAttester) Attest(_ acme.Identifier, keyAuthDigest []byte) ([]byte, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	a.digest = keyAuthDigest
+
+	return []byte("attestation"), nil
+}
@@ -0,0 +1,107 @@
+package dns01
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+var (
+	// challengeAliases the alias domain of each domain (without trailing dot, in lower case).
+	challengeAliases   = map[string]string{}
+	muChallengeAliases sync.RWMutex
+)
+
+// WithChallengeAlias delegates the TXT records of the domains to alias domains (challenge alias mode):
+// the TXT record of a domain is created at _acme-challenge.<alias>,
+// where the record _acme-challenge.<domain> must be a CNAME to _acme-challenge.<alias>
+// (ex: example.com: _acme-challenge.example.com. CNAME _acme-challenge.example.net.).
+//
+// The DNS provider only manages the zones of the aliases, the zones of the domains are not modified.
+// The alias is used without resolving the CNAME records (GetChallengeInfo),
+// and the propagation check also waits for the CNAME record of the domain to resolve to the alias.
+// The alias of a wildcard domain is the alias of its base domain.
+func WithChallengeAlias(domainToAlias map[string]string) ChallengeOption {
+	return func(_ *Challenge) error {
+		aliases := make(map[string]string, len(domainToAlias))
+
+		for domain, alias := range domainToAlias {
+			d, a := normalizeAliasDomain(domain), normalizeAliasDomain(alias)
+
+			if d == "" || a == "" {
+				return fmt.Errorf("invalid challenge alias: %q=%q", domain, alias)
+			}
+
+			if _, ok := dns.IsDomainName(a); !ok {
+				return fmt.Errorf("invalid challenge alias of %s: %q", d, alias)
+			}
+
+			aliases[d] = a
+		}
+
+		muChallengeAliases.Lock()
+		challengeAliases = aliases
+		muChallengeAliases.Unlock()
+
+		return nil
+	}
+}
+
+// getChallengeAlias returns the FQDN of the TXT record of the alias of the domain.
+func getChallengeAlias(domain string) (string, bool) {
+	muChallengeAliases.RLock()
+	defer muChallengeAliases.RUnlock()
+
+	alias, ok := challengeAliases[normalizeAliasDomain(domain)]
+	if !ok {
+		return "", false
+	}
+
+	return getChallengeFQDN(alias, false), true
+}
+
+// checkChallengeAlias checks that the CNAME records of the challenge record of the domain lead to its alias,
+// otherwise the CA cannot find the TXT record.
+func checkChallengeAlias(domain string) error {
+	alias, ok := getChallengeAlias(domain)
+	if !ok {
+		return nil
+	}
+
+	fqdn := getChallengeFQDN(domain, false)
+
+	var target string
+
+	// recursion counter so it doesn't spin out of control
+	for range 50 {
+		r, err := dnsQuery(fqdn, dns.TypeCNAME, recursiveNameservers, true)
+		if err != nil || r.Rcode != dns.RcodeSuccess {
+			break
+		}
+
+		cname := updateDomainWithCName(r, fqdn)
+		if cname == fqdn {
+			break
+		}
+
+		if strings.EqualFold(cname, alias) {
+			return nil
+		}
+
+		target, fqdn = cname, cname
+	}
+
+	if target == "" {
+		return fmt.Errorf("the CNAME record %s to %s is missing", getChallengeFQDN(domain, false), alias)
+	}
+
+	return fmt.Errorf("the CNAME record %s resolves to %s instead of %s", getChallengeFQDN(domain, false), target, alias)
+}
+
+func normalizeAliasDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	return strings.TrimSuffix(strings.TrimPrefix(domain, "*."), ".")
+}
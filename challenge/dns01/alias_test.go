@@ -0,0 +1,142 @@
+package dns01
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setChallengeAliases(t *testing.T, domainToAlias map[string]string) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		muChallengeAliases.Lock()
+		challengeAliases = map[string]string{}
+		muChallengeAliases.Unlock()
+	})
+
+	require.NoError(t, WithChallengeAlias(domainToAlias)(&Challenge{}))
+}
+
+func TestWithChallengeAlias_invalid(t *testing.T) {
+	err := WithChallengeAlias(map[string]string{"example.com": ""})(&Challenge{})
+	require.EqualError(t, err, `invalid challenge alias: "example.com"=""`)
+
+	err = WithChallengeAlias(map[string]string{"example.com": "example..net"})(&Challenge{})
+	require.EqualError(t, err, `invalid challenge alias of example.com: "example..net"`)
+}
+
+func TestGetChallengeInfo_alias(t *testing.T) {
+	setChallengeAliases(t, map[string]string{
+		"*.Example.com.": "alias.example.net",
+	})
+
+	info := GetChallengeInfo("example.com", "123d==")
+
+	expected := ChallengeInfo{
+		FQDN:          "_acme-challenge.example.com.",
+		EffectiveFQDN: "_acme-challenge.alias.example.net.",
+		Value:         "ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY",
+	}
+
+	assert.Equal(t, expected, info)
+
+	// the other domains are not delegated.
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	info = GetChallengeInfo("www.example.com", "123d==")
+	assert.Equal(t, "_acme-challenge.www.example.com.", info.EffectiveFQDN)
+}
+
+func Test_checkChallengeAlias(t *testing.T) {
+	records := map[string]string{
+		"_acme-challenge.delegated.example.":    "_acme-challenge.alias.example.net.",
+		"_acme-challenge.chain.example.":        "intermediate.example.org.",
+		"intermediate.example.org.":             "_acme-challenge.alias.example.net.",
+		"_acme-challenge.other.example.":        "_acme-challenge.other.example.net.",
+		"_acme-challenge.alias.example.net.":    "",
+		"_acme-challenge.other.example.net.":    "",
+		"_acme-challenge.notdelegated.example.": "",
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(req)
+
+			q := req.Question[0]
+
+			if target := records[q.Name]; target != "" && q.Qtype == dns.TypeCNAME {
+				m.Answer = append(m.Answer, &dns.CNAME{
+					Hdr:    dns.RR_Header{Name: q.Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+					Target: target,
+				})
+			}
+
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	nameservers := recursiveNameservers
+	t.Cleanup(func() { recursiveNameservers = nameservers })
+
+	recursiveNameservers = []string{conn.LocalAddr().String()}
+
+	setChallengeAliases(t, map[string]string{
+		"delegated.example":    "alias.example.net",
+		"chain.example":        "alias.example.net",
+		"other.example":        "alias.example.net",
+		"notdelegated.example": "alias.example.net",
+	})
+
+	testCases := []struct {
+		desc        string
+		domain      string
+		expectedErr string
+	}{
+		{
+			desc:   "CNAME to the alias",
+			domain: "delegated.example",
+		},
+		{
+			desc:   "chain of CNAME to the alias",
+			domain: "chain.example",
+		},
+		{
+			desc:   "without alias",
+			domain: "example.org",
+		},
+		{
+			desc:        "CNAME to another domain",
+			domain:      "other.example",
+			expectedErr: "the CNAME record _acme-challenge.other.example. resolves to _acme-challenge.other.example.net. instead of _acme-challenge.alias.example.net.",
+		},
+		{
+			desc:        "missing CNAME",
+			domain:      "notdelegated.example",
+			expectedErr: "the CNAME record _acme-challenge.notdelegated.example. to _acme-challenge.alias.example.net. is missing",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := checkChallengeAlias(test.domain)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
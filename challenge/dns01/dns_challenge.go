@@ -1,8 +1,10 @@
 package dns01
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -28,10 +30,46 @@ const (
 	DefaultTTL = 120
 )
 
+// ErrPropagationTimeout is returned when the TXT record is not propagated before the propagation timeout.
+var ErrPropagationTimeout = errors.New("propagation timeout")
+
+// presentRetries the number of retries of Present when the error of the provider is temporary (throttled or transient),
+// and the provider is idempotent (challenge.Idempotent).
+const presentRetries = 2
+
+// presentRetryDelay the delay before the first retry of Present, increased at each retry.
+var presentRetryDelay = 5 * time.Second
+
 type ValidateFunc func(core *api.Core, domain string, chlng acme.Challenge) error
 
 type ChallengeOption func(*Challenge) error
 
+// PropagationWaiter is implemented by the DNS providers able to confirm by themselves the propagation of the TXT record
+// (ex: by polling their own authoritative nameservers or the status of the record in their API).
+// When a provider implements this interface, it replaces the propagation check on the recursive nameservers,
+// unless WaitForPropagation returns an error wrapping errors.ErrUnsupported.
+// The optional interfaces (PropagationWaiter, challenge.ProviderTimeout, challenge.Transactional,
+// challenge.ProviderExclusiveRecord, and the Sequential method)
+// are also found on the providers wrapped by a provider implementing Unwrap() challenge.Provider.
+type PropagationWaiter interface {
+	WaitForPropagation(domain, token, keyAuth string) error
+}
+
+// PropagationWaiterContext is like PropagationWaiter, the wait is cancelled with the context of the obtain request.
+type PropagationWaiterContext interface {
+	PropagationWaiter
+	WaitForPropagationContext(ctx context.Context, domain, token, keyAuth string) error
+}
+
+// AuthoritativePropagationWaiter is implemented by the PropagationWaiter querying the authoritative nameservers (DNS queries),
+// instead of an API of the provider.
+// Like the propagation check on the authoritative nameservers, the waiter is not called
+// when this check is disabled, replaced by a custom pre-check, or by a fixed propagation wait.
+type AuthoritativePropagationWaiter interface {
+	PropagationWaiter
+	ChecksAuthoritativeNameservers() bool
+}
+
 // CondOption Conditional challenge option.
 func CondOption(condition bool, opt ChallengeOption) ChallengeOption {
 	if !condition {
@@ -74,6 +112,11 @@ func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Prov
 // PreSolve just submits the txt record to the dns provider.
 // It does not validate record propagation, or do anything at all with the acme server.
 func (c *Challenge) PreSolve(authz acme.Authorization) error {
+	return c.PreSolveContext(context.Background(), authz)
+}
+
+// PreSolveContext is like PreSolve, the context is passed to the provider if it implements challenge.ProviderContext.
+func (c *Challenge) PreSolveContext(ctx context.Context, authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 	log.Infof("[%s] acme: Preparing to solve DNS-01", domain)
 
@@ -92,7 +135,7 @@ func (c *Challenge) PreSolve(authz acme.Authorization) error {
 		return err
 	}
 
-	err = c.provider.Present(authz.Identifier.Value, chlng.Token, keyAuth)
+	err = c.present(ctx, domain, authz.Identifier.Value, chlng.Token, keyAuth)
 	if err != nil {
 		return fmt.Errorf("[%s] acme: error presenting token: %w", domain, err)
 	}
@@ -100,7 +143,45 @@ func (c *Challenge) PreSolve(authz acme.Authorization) error {
 	return nil
 }
 
+// present calls the provider, and retries the temporary errors (throttled, transient) if the provider is idempotent.
+// The other errors fail fast, the credential errors are reported explicitly.
+func (c *Challenge) present(ctx context.Context, domain, value, token, keyAuth string) error {
+	idempotent, ok := findProvider[challenge.Idempotent](c.provider)
+	retryable := ok && idempotent.IdempotentPresent()
+
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		err = challenge.Present(ctx, c.provider, value, token, keyAuth)
+		if err == nil || !retryable || !challenge.IsRetryable(err) || attempt > presentRetries {
+			break
+		}
+
+		delay := presentRetryDelay * time.Duration(attempt)
+
+		log.Warnf("[%s] acme: %s error presenting token, retrying in %s: %v", domain, challenge.GetErrorCategory(err), delay, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+	}
+
+	if challenge.GetErrorCategory(err) == challenge.ErrorCategoryAuth {
+		return fmt.Errorf("invalid credentials or missing permissions of the DNS provider: %w", err)
+	}
+
+	return err
+}
+
+// Solve waits for the propagation of the TXT record, and validates the challenge.
 func (c *Challenge) Solve(authz acme.Authorization) error {
+	return c.SolveContext(context.Background(), authz)
+}
+
+// SolveContext is like Solve, the propagation wait is cancelled with the context.
+func (c *Challenge) SolveContext(ctx context.Context, authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 	log.Infof("[%s] acme: Trying to solve DNS-01", domain)
 
@@ -117,35 +198,126 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 
 	info := GetChallengeInfo(authz.Identifier.Value, keyAuth)
 
-	var timeout, interval time.Duration
-	switch provider := c.provider.(type) {
-	case challenge.ProviderTimeout:
+	timeout, interval := DefaultPropagationTimeout, DefaultPollingInterval
+	if provider, ok := findProvider[challenge.ProviderTimeout](c.provider); ok {
 		timeout, interval = provider.Timeout()
-	default:
-		timeout, interval = DefaultPropagationTimeout, DefaultPollingInterval
+	}
+
+	if waiter, ok := c.propagationWaiter(); ok {
+		log.Infof("[%s] acme: Waiting for the DNS provider to confirm the record propagation.", domain)
+
+		err = waitForPropagation(ctx, waiter, authz.Identifier.Value, chlng.Token, keyAuth)
+		switch {
+		case err == nil:
+			chlng.KeyAuthorization = keyAuth
+			return c.validate(c.core, domain, chlng)
+
+		case !errors.Is(err, errors.ErrUnsupported):
+			return fmt.Errorf("[%s] acme: error waiting for the record propagation: %w", domain, wrapPropagationTimeout(err))
+		}
+	}
+
+	// The provider is trusted to have published the record:
+	// the propagation waiter of the provider (ex: plan mode, change status) is still called before.
+	if c.preCheck.wait > 0 {
+		log.Infof("[%s] acme: Waiting %s for the DNS record propagation (the propagation is not checked).", domain, c.preCheck.wait)
+
+		err = sleep(ctx, c.preCheck.wait)
+		if err != nil {
+			return fmt.Errorf("[%s] acme: %w", domain, err)
+		}
+
+		chlng.KeyAuthorization = keyAuth
+		return c.validate(c.core, domain, chlng)
 	}
 
 	log.Infof("[%s] acme: Checking DNS record propagation. [nameservers=%s]", domain, strings.Join(recursiveNameservers, ","))
 
-	time.Sleep(interval)
+	err = sleep(ctx, interval)
+	if err != nil {
+		return fmt.Errorf("[%s] acme: %w", domain, err)
+	}
+
+	_, alias := getChallengeAlias(authz.Identifier.Value)
+	if alias {
+		log.Infof("[%s] acme: The TXT record is delegated to the challenge alias %s.", domain, info.EffectiveFQDN)
+	}
 
-	err = wait.For("propagation", timeout, interval, func() (bool, error) {
+	err = wait.ForContext(ctx, "propagation", timeout, interval, func() (bool, error) {
 		stop, errP := c.preCheck.call(domain, info.EffectiveFQDN, info.Value)
+		if stop && errP == nil && alias && c.preCheck.requireRecursiveNssPropagation {
+			// the CA follows the CNAME record of the domain to find the TXT record of the alias.
+			errA := checkChallengeAlias(authz.Identifier.Value)
+			if errA != nil {
+				log.Infof("[%s] acme: Waiting for the challenge alias: %v.", domain, errA)
+				return false, errA
+			}
+		}
+
 		if !stop || errP != nil {
 			log.Infof("[%s] acme: Waiting for DNS record propagation.", domain)
 		}
 		return stop, errP
 	})
 	if err != nil {
-		return err
+		return wrapPropagationTimeout(err)
 	}
 
 	chlng.KeyAuthorization = keyAuth
 	return c.validate(c.core, domain, chlng)
 }
 
+// waitForPropagation calls the waiter, with the context if it implements PropagationWaiterContext.
+func waitForPropagation(ctx context.Context, waiter PropagationWaiter, domain, token, keyAuth string) error {
+	if w, ok := waiter.(PropagationWaiterContext); ok {
+		return w.WaitForPropagationContext(ctx, domain, token, keyAuth)
+	}
+
+	return waiter.WaitForPropagation(domain, token, keyAuth)
+}
+
+// sleep waits for the duration, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// propagationWaiter returns the propagation waiter of the provider, unless it checks the authoritative nameservers and this check is not used.
+func (c *Challenge) propagationWaiter() (PropagationWaiter, bool) {
+	waiter, ok := findProvider[PropagationWaiter](c.provider)
+	if !ok {
+		return nil, false
+	}
+
+	if w, ok := waiter.(AuthoritativePropagationWaiter); ok && w.ChecksAuthoritativeNameservers() {
+		if c.preCheck.checkFunc != nil || !c.preCheck.requireAuthoritativeNssPropagation || c.preCheck.wait > 0 {
+			return nil, false
+		}
+	}
+
+	return waiter, true
+}
+
+// wrapPropagationTimeout marks the time limit errors as propagation timeouts.
+func wrapPropagationTimeout(err error) error {
+	if errors.Is(err, wait.ErrTimeout) {
+		return fmt.Errorf("%w: %w", ErrPropagationTimeout, err)
+	}
+
+	return err
+}
+
 // CleanUp cleans the challenge.
 func (c *Challenge) CleanUp(authz acme.Authorization) error {
+	return c.CleanUpContext(context.Background(), authz)
+}
+
+// CleanUpContext is like CleanUp, the context is passed to the provider if it implements challenge.ProviderContext.
+func (c *Challenge) CleanUpContext(ctx context.Context, authz acme.Authorization) error {
 	log.Infof("[%s] acme: Cleaning DNS-01 challenge", challenge.GetTargetedDomain(authz))
 
 	chlng, err := challenge.FindChallenge(challenge.DNS01, authz)
@@ -158,12 +330,14 @@ func (c *Challenge) CleanUp(authz acme.Authorization) error {
 		return err
 	}
 
-	return c.provider.CleanUp(authz.Identifier.Value, chlng.Token, keyAuth)
+	return challenge.CleanUp(ctx, c.provider, authz.Identifier.Value, chlng.Token, keyAuth)
 }
 
 func (c *Challenge) Sequential() (bool, time.Duration) {
-	if p, ok := c.provider.(sequential); ok {
-		return ok, p.Sequential()
+	if p, ok := findProvider[sequential](c.provider); ok {
+		// an interval of 0 means that the provider doesn't need the sequential resolution.
+		interval := p.Sequential()
+		return interval > 0, interval
 	}
 	return false, 0
 }
@@ -172,6 +346,46 @@ type sequential interface {
 	Sequential() time.Duration
 }
 
+// Transaction returns the provider if it supports the transactions (challenge.Transactional).
+// The wrappers of providers implementing Unwrap() challenge.Provider are traversed.
+func (c *Challenge) Transaction() (challenge.Transactional, bool) {
+	return findProvider[challenge.Transactional](c.provider)
+}
+
+// ExclusiveRecord returns the FQDN of the TXT record of the authorization,
+// and true if the provider cannot keep several TXT records on the same FQDN (challenge.ProviderExclusiveRecord).
+func (c *Challenge) ExclusiveRecord(authz acme.Authorization) (string, bool) {
+	p, ok := findProvider[challenge.ProviderExclusiveRecord](c.provider)
+	if !ok || !p.ExclusiveRecord() {
+		return "", false
+	}
+
+	return GetChallengeInfo(authz.Identifier.Value, "").EffectiveFQDN, true
+}
+
+// Zone returns the zone of the TXT record of the authorization.
+func (c *Challenge) Zone(authz acme.Authorization) (string, error) {
+	return FindZoneByFqdn(GetChallengeInfo(authz.Identifier.Value, "").EffectiveFQDN)
+}
+
+// findProvider returns the provider implementing T: the provider itself,
+// or a provider wrapped by a provider implementing Unwrap() challenge.Provider.
+func findProvider[T any](provider challenge.Provider) (T, bool) {
+	for {
+		if p, ok := provider.(T); ok {
+			return p, true
+		}
+
+		wrapper, ok := provider.(interface{ Unwrap() challenge.Provider })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+
+		provider = wrapper.Unwrap()
+	}
+}
+
 // GetRecord returns a DNS record which will fulfill the `dns-01` challenge.
 // Deprecated: use GetChallengeInfo instead.
 func GetRecord(domain, keyAuth string) (fqdn, value string) {
@@ -193,11 +407,20 @@ type ChallengeInfo struct {
 }
 
 // GetChallengeInfo returns information used to create a DNS record which will fulfill the `dns-01` challenge.
+// The EffectiveFQDN of a domain with a challenge alias (WithChallengeAlias) is the FQDN of the alias.
 func GetChallengeInfo(domain, keyAuth string) ChallengeInfo {
 	keyAuthShaBytes := sha256.Sum256([]byte(keyAuth))
 	// base64URL encoding without padding
 	value := base64.RawURLEncoding.EncodeToString(keyAuthShaBytes[:sha256.Size])
 
+	if alias, ok := getChallengeAlias(domain); ok {
+		return ChallengeInfo{
+			Value:         value,
+			FQDN:          getChallengeFQDN(domain, false),
+			EffectiveFQDN: alias,
+		}
+	}
+
 	ok, _ := strconv.ParseBool(os.Getenv("LEGO_DISABLE_CNAME_SUPPORT"))
 
 	return ChallengeInfo{
@@ -2,8 +2,16 @@ package dns01
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 )
 
@@ -11,16 +19,72 @@ const (
 	dnsTemplate = `%s %d IN TXT %q`
 )
 
+// Environment variables passed to the manual hooks.
+const (
+	ManualEnvDomain = "LEGO_MANUAL_DOMAIN"
+	ManualEnvFQDN   = "LEGO_MANUAL_FQDN"
+	ManualEnvZone   = "LEGO_MANUAL_ZONE"
+	ManualEnvValue  = "LEGO_MANUAL_VALUE"
+)
+
+const manualHookTimeout = 120 * time.Second
+
+// ManualConfig is used to configure the manual DNS provider.
+type ManualConfig struct {
+	// ExportDir if set, the TXT records of the order are written to importable files
+	// (BIND zone snippet, PowerShell script, Terraform configuration) in this directory,
+	// and the confirmation is asked once for all the records.
+	ExportDir string
+
+	// AuthHook if set, the command is executed for each TXT record to create, instead of asking for a confirmation.
+	// The record is described by the LEGO_MANUAL_* environment variables.
+	AuthHook string
+
+	// CleanupHook if set, the command is executed for each TXT record to remove.
+	CleanupHook string
+}
+
+// manualRecord a TXT record required by the order.
+type manualRecord struct {
+	Domain string
+	FQDN   string
+	Zone   string
+	Value  string
+}
+
 // DNSProviderManual is an implementation of the ChallengeProvider interface.
-type DNSProviderManual struct{}
+type DNSProviderManual struct {
+	config *ManualConfig
+
+	mu       sync.Mutex
+	records  map[string]manualRecord
+	exported bool
+}
 
 // NewDNSProviderManual returns a DNSProviderManual instance.
 func NewDNSProviderManual() (*DNSProviderManual, error) {
-	return &DNSProviderManual{}, nil
+	return NewDNSProviderManualConfig(&ManualConfig{})
 }
 
-// Present prints instructions for manually creating the TXT record.
-func (*DNSProviderManual) Present(domain, token, keyAuth string) error {
+// NewDNSProviderManualConfig return a DNSProviderManual instance configured for the manual DNS provider.
+func NewDNSProviderManualConfig(config *ManualConfig) (*DNSProviderManual, error) {
+	if config == nil {
+		return nil, errors.New("manual: the configuration of the DNS provider is nil")
+	}
+
+	if config.ExportDir != "" {
+		err := os.MkdirAll(config.ExportDir, 0o700)
+		if err != nil {
+			return nil, fmt.Errorf("manual: %w", err)
+		}
+	}
+
+	return &DNSProviderManual{config: config, records: map[string]manualRecord{}}, nil
+}
+
+// Present prints instructions for manually creating the TXT record,
+// or executes the authentication hook.
+func (d *DNSProviderManual) Present(domain, token, keyAuth string) error {
 	info := GetChallengeInfo(domain, keyAuth)
 
 	authZone, err := FindZoneByFqdn(info.EffectiveFQDN)
@@ -28,20 +92,36 @@ func (*DNSProviderManual) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("manual: could not find zone: %w", err)
 	}
 
-	fmt.Printf("lego: Please create the following TXT record in your %s zone:\n", authZone)
-	fmt.Printf(dnsTemplate+"\n", info.EffectiveFQDN, DefaultTTL, info.Value)
-	fmt.Printf("lego: Press 'Enter' when you are done\n")
+	record := manualRecord{Domain: domain, FQDN: info.EffectiveFQDN, Zone: authZone, Value: info.Value}
 
-	_, err = bufio.NewReader(os.Stdin).ReadBytes('\n')
-	if err != nil {
-		return fmt.Errorf("manual: %w", err)
+	d.mu.Lock()
+	d.records[record.FQDN+record.Value] = record
+	d.exported = false
+	d.mu.Unlock()
+
+	if d.config.AuthHook != "" {
+		err = runManualHook(d.config.AuthHook, record)
+		if err != nil {
+			return fmt.Errorf("manual: auth hook: %w", err)
+		}
+
+		return nil
 	}
 
-	return nil
+	// The records are exported and confirmed all together by WaitForPropagation.
+	if d.config.ExportDir != "" {
+		return nil
+	}
+
+	fmt.Printf("lego: Please create the following TXT record in your %s zone:\n", authZone)
+	fmt.Printf(dnsTemplate+"\n", info.EffectiveFQDN, DefaultTTL, info.Value)
+
+	return waitForEnter()
 }
 
-// CleanUp prints instructions for manually removing the TXT record.
-func (*DNSProviderManual) CleanUp(domain, token, keyAuth string) error {
+// CleanUp prints instructions for manually removing the TXT record,
+// or executes the cleanup hook.
+func (d *DNSProviderManual) CleanUp(domain, token, keyAuth string) error {
 	info := GetChallengeInfo(domain, keyAuth)
 
 	authZone, err := FindZoneByFqdn(info.EffectiveFQDN)
@@ -49,14 +129,204 @@ func (*DNSProviderManual) CleanUp(domain, token, keyAuth string) error {
 		return fmt.Errorf("manual: could not find zone: %w", err)
 	}
 
+	record := manualRecord{Domain: domain, FQDN: info.EffectiveFQDN, Zone: authZone, Value: info.Value}
+
+	d.mu.Lock()
+	delete(d.records, record.FQDN+record.Value)
+	d.mu.Unlock()
+
+	if d.config.CleanupHook != "" {
+		err = runManualHook(d.config.CleanupHook, record)
+		if err != nil {
+			return fmt.Errorf("manual: cleanup hook: %w", err)
+		}
+
+		return nil
+	}
+
 	fmt.Printf("lego: You can now remove this TXT record from your %s zone:\n", authZone)
 	fmt.Printf(dnsTemplate+"\n", info.EffectiveFQDN, DefaultTTL, "...")
 
 	return nil
 }
 
+// WaitForPropagation writes the export files of all the TXT records of the order,
+// and asks for a confirmation (once for all the records).
+// The propagation is always checked on the nameservers afterward.
+func (d *DNSProviderManual) WaitForPropagation(_, _, _ string) error {
+	if d.config.ExportDir == "" {
+		return errors.ErrUnsupported
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.exported {
+		return errors.ErrUnsupported
+	}
+
+	records := d.sortedRecords()
+
+	files, err := exportManualRecords(d.config.ExportDir, records)
+	if err != nil {
+		return fmt.Errorf("manual: %w", err)
+	}
+
+	d.exported = true
+
+	fmt.Printf("lego: The %d TXT record(s) of the order have been exported to:\n", len(records))
+	for _, file := range files {
+		fmt.Printf("\t%s\n", file)
+	}
+
+	if d.config.AuthHook == "" {
+		if err = waitForEnter(); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("manual: %w", errors.ErrUnsupported)
+}
+
 // Sequential All DNS challenges for this provider will be resolved sequentially.
 // Returns the interval between each iteration.
+// With an export directory or an authentication hook, the challenges are not sequential (returns 0):
+// all the records of the order must be presented before the export and the confirmation.
 func (d *DNSProviderManual) Sequential() time.Duration {
+	if d.config.ExportDir != "" || d.config.AuthHook != "" {
+		return 0
+	}
+
 	return DefaultPropagationTimeout
 }
+
+func (d *DNSProviderManual) sortedRecords() []manualRecord {
+	var records []manualRecord
+	for _, record := range d.records {
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].FQDN == records[j].FQDN {
+			return records[i].Value < records[j].Value
+		}
+
+		return records[i].FQDN < records[j].FQDN
+	})
+
+	return records
+}
+
+func waitForEnter() error {
+	fmt.Printf("lego: Press 'Enter' when you are done\n")
+
+	_, err := bufio.NewReader(os.Stdin).ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("manual: %w", err)
+	}
+
+	return nil
+}
+
+func runManualHook(hook string, record manualRecord) error {
+	ctx, cancel := context.WithTimeout(context.Background(), manualHookTimeout)
+	defer cancel()
+
+	parts := strings.Fields(hook)
+
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	cmd.Env = append(os.Environ(),
+		ManualEnvDomain+"="+record.Domain,
+		ManualEnvFQDN+"="+record.FQDN,
+		ManualEnvZone+"="+record.Zone,
+		ManualEnvValue+"="+record.Value,
+	)
+
+	output, err := cmd.CombinedOutput()
+
+	if len(output) > 0 {
+		fmt.Println(string(output))
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.New("hook timed out")
+	}
+
+	return err
+}
+
+// Export files of the manual records.
+const (
+	manualExportBIND       = "lego-challenges.zone"
+	manualExportPowerShell = "lego-challenges.ps1"
+	manualExportTerraform  = "lego-challenges.tf"
+)
+
+var manualExportFuncs = template.FuncMap{
+	"ttl":      func() int { return DefaultTTL },
+	"unfqdn":   UnFqdn,
+	"relative": relativeName,
+}
+
+var manualExportTemplates = map[string]*template.Template{
+	manualExportBIND: template.Must(template.New(manualExportBIND).Funcs(manualExportFuncs).Parse(
+		`; TXT records of the ACME order, to include in the zone files.
+{{ range . }}{{ .FQDN }}	{{ ttl }}	IN	TXT	"{{ .Value }}"
+{{ end }}`)),
+
+	manualExportPowerShell: template.Must(template.New(manualExportPowerShell).Funcs(manualExportFuncs).Parse(
+		`# TXT records of the ACME order, for the Windows DNS Server.
+{{ range . }}Add-DnsServerResourceRecord -ZoneName "{{ unfqdn .Zone }}" -Name "{{ relative .FQDN .Zone }}" -Txt -DescriptiveText "{{ .Value }}" -TimeToLive (New-TimeSpan -Seconds {{ ttl }})
+{{ end }}`)),
+
+	manualExportTerraform: template.Must(template.New(manualExportTerraform).Funcs(manualExportFuncs).Parse(
+		`# TXT records of the ACME order (generic "dns" provider, RFC 2136).
+{{ range $i, $r := . }}
+resource "dns_txt_record_set" "lego_challenge_{{ $i }}" {
+  zone = "{{ $r.Zone }}"
+  name = "{{ relative $r.FQDN $r.Zone }}"
+  txt  = ["{{ $r.Value }}"]
+  ttl  = {{ ttl }}
+}
+{{ end }}`)),
+}
+
+// exportManualRecords writes the records to all the export formats, and returns the paths of the files.
+func exportManualRecords(dir string, records []manualRecord) ([]string, error) {
+	names := []string{manualExportBIND, manualExportPowerShell, manualExportTerraform}
+
+	var files []string
+
+	for _, name := range names {
+		filename := filepath.Join(dir, name)
+
+		err := writeManualExport(filename, manualExportTemplates[name], records)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, filename)
+	}
+
+	return files, nil
+}
+
+func writeManualExport(filename string, tmpl *template.Template, records []manualRecord) error {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	return tmpl.Execute(file, records)
+}
+
+// relativeName returns the name of the record relative to the zone ("@" for the apex of the zone).
+func relativeName(fqdn, zone string) string {
+	if fqdn == zone {
+		return "@"
+	}
+
+	return UnFqdn(strings.TrimSuffix(fqdn, "."+zone))
+}
@@ -3,8 +3,11 @@ package dns01
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,3 +60,101 @@ func TestDNSProviderManual(t *testing.T) {
 		})
 	}
 }
+
+func Test_exportManualRecords(t *testing.T) {
+	dir := t.TempDir()
+
+	records := []manualRecord{
+		{Domain: "example.com", FQDN: "_acme-challenge.example.com.", Zone: "example.com.", Value: "value1"},
+		{Domain: "*.example.com", FQDN: "_acme-challenge.example.com.", Zone: "example.com.", Value: "value2"},
+		{Domain: "example.org", FQDN: "_acme-challenge.example.org.", Zone: "_acme-challenge.example.org.", Value: "value3"},
+	}
+
+	files, err := exportManualRecords(dir, records)
+	require.NoError(t, err)
+
+	require.Len(t, files, 3)
+
+	testCases := []struct {
+		filename string
+		expected string
+	}{
+		{
+			filename: manualExportBIND,
+			expected: `; TXT records of the ACME order, to include in the zone files.
+_acme-challenge.example.com.	120	IN	TXT	"value1"
+_acme-challenge.example.com.	120	IN	TXT	"value2"
+_acme-challenge.example.org.	120	IN	TXT	"value3"
+`,
+		},
+		{
+			filename: manualExportPowerShell,
+			expected: `# TXT records of the ACME order, for the Windows DNS Server.
+Add-DnsServerResourceRecord -ZoneName "example.com" -Name "_acme-challenge" -Txt -DescriptiveText "value1" -TimeToLive (New-TimeSpan -Seconds 120)
+Add-DnsServerResourceRecord -ZoneName "example.com" -Name "_acme-challenge" -Txt -DescriptiveText "value2" -TimeToLive (New-TimeSpan -Seconds 120)
+Add-DnsServerResourceRecord -ZoneName "_acme-challenge.example.org" -Name "@" -Txt -DescriptiveText "value3" -TimeToLive (New-TimeSpan -Seconds 120)
+`,
+		},
+		{
+			filename: manualExportTerraform,
+			expected: `# TXT records of the ACME order (generic "dns" provider, RFC 2136).
+
+resource "dns_txt_record_set" "lego_challenge_0" {
+  zone = "example.com."
+  name = "_acme-challenge"
+  txt  = ["value1"]
+  ttl  = 120
+}
+
+resource "dns_txt_record_set" "lego_challenge_1" {
+  zone = "example.com."
+  name = "_acme-challenge"
+  txt  = ["value2"]
+  ttl  = 120
+}
+
+resource "dns_txt_record_set" "lego_challenge_2" {
+  zone = "_acme-challenge.example.org."
+  name = "@"
+  txt  = ["value3"]
+  ttl  = 120
+}
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.filename, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join(dir, test.filename))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(content))
+		})
+	}
+}
+
+func Test_runManualHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on Windows")
+	}
+
+	dir := t.TempDir()
+
+	output := filepath.Join(dir, "output.txt")
+	script := filepath.Join(dir, "hook.sh")
+
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+echo "$LEGO_MANUAL_DOMAIN $LEGO_MANUAL_FQDN $LEGO_MANUAL_ZONE $LEGO_MANUAL_VALUE" > "$1"
+`), 0o700)
+	require.NoError(t, err)
+
+	record := manualRecord{Domain: "example.com", FQDN: "_acme-challenge.example.com.", Zone: "example.com.", Value: "value"}
+
+	err = runManualHook(script+" "+output, record)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com _acme-challenge.example.com. example.com. value\n", string(content))
+}
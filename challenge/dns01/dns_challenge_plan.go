@@ -0,0 +1,75 @@
+package dns01
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/log"
+)
+
+// ErrPlanMode is returned by the plan provider instead of validating the challenges.
+var ErrPlanMode = errors.New("plan mode: no DNS record created")
+
+// PlanProvider wraps a DNS provider and logs the records it would create or delete, without calling the API of the provider.
+type PlanProvider struct {
+	provider challenge.Provider
+	ttl      int
+}
+
+// NewPlanProvider returns a PlanProvider for the DNS provider.
+func NewPlanProvider(provider challenge.Provider) *PlanProvider {
+	return &PlanProvider{provider: provider, ttl: providerTTL(provider)}
+}
+
+// Present logs the TXT record that the provider would create.
+func (p *PlanProvider) Present(domain, _, keyAuth string) error {
+	p.log("create", domain, keyAuth)
+
+	return nil
+}
+
+// CleanUp logs the TXT record that the provider would delete.
+func (p *PlanProvider) CleanUp(domain, _, keyAuth string) error {
+	p.log("delete", domain, keyAuth)
+
+	return nil
+}
+
+// WaitForPropagation stops the resolution before the validation of the challenge:
+// the record doesn't exist, so the CA cannot validate the challenge.
+func (p *PlanProvider) WaitForPropagation(_, _, _ string) error {
+	return ErrPlanMode
+}
+
+func (p *PlanProvider) log(action, domain, keyAuth string) {
+	info := GetChallengeInfo(domain, keyAuth)
+
+	zone, err := FindZoneByFqdn(info.EffectiveFQDN)
+	if err != nil {
+		zone = "(unknown: " + err.Error() + ")"
+	}
+
+	log.Infof("[%s] plan: %s TXT record: fqdn=%s value=%q ttl=%d zone=%s", domain, action, info.EffectiveFQDN, info.Value, p.ttl, zone)
+}
+
+// providerTTL returns the TTL of the configuration of the provider (the TTL field of the config field),
+// or DefaultTTL if the provider doesn't have such a configuration.
+func providerTTL(provider challenge.Provider) int {
+	v := reflect.ValueOf(provider)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return DefaultTTL
+	}
+
+	config := v.Elem().FieldByName("config")
+	if config.Kind() != reflect.Pointer || config.IsNil() || config.Elem().Kind() != reflect.Struct {
+		return DefaultTTL
+	}
+
+	ttl := config.Elem().FieldByName("TTL")
+	if !ttl.IsValid() || !ttl.CanInt() || ttl.Int() <= 0 {
+		return DefaultTTL
+	}
+
+	return int(ttl.Int())
+}
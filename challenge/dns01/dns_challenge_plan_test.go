@@ -0,0 +1,34 @@
+package dns01
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProviderConfig struct {
+	TTL int
+}
+
+type fakeConfiguredProvider struct {
+	config *fakeProviderConfig
+}
+
+func (f *fakeConfiguredProvider) Present(_, _, _ string) error { return nil }
+
+func (f *fakeConfiguredProvider) CleanUp(_, _, _ string) error { return nil }
+
+func Test_providerTTL(t *testing.T) {
+	assert.Equal(t, 300, providerTTL(&fakeConfiguredProvider{config: &fakeProviderConfig{TTL: 300}}))
+	assert.Equal(t, DefaultTTL, providerTTL(&fakeConfiguredProvider{config: &fakeProviderConfig{}}))
+	assert.Equal(t, DefaultTTL, providerTTL(&fakeConfiguredProvider{}))
+	assert.Equal(t, DefaultTTL, providerTTL(&DNSProviderManual{}))
+}
+
+func TestPlanProvider_WaitForPropagation(t *testing.T) {
+	provider := NewPlanProvider(&fakeConfiguredProvider{config: &fakeProviderConfig{TTL: 300}})
+
+	err := provider.WaitForPropagation("example.com", "token", "keyAuth")
+	require.ErrorIs(t, err, ErrPlanMode)
+}
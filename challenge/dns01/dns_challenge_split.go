@@ -0,0 +1,55 @@
+package dns01
+
+import (
+	"context"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+)
+
+// SplitProvider uses a DNS provider to create the records, and another one to remove them
+// (ex: the same provider with other credentials).
+// The optional interfaces of the present provider (propagation waiter, transactions, sequential resolution) are used through Unwrap.
+type SplitProvider struct {
+	present challenge.Provider
+	cleanUp challenge.Provider
+}
+
+// NewSplitProvider returns a SplitProvider: the records are created by present, and removed by cleanUp.
+func NewSplitProvider(present, cleanUp challenge.Provider) *SplitProvider {
+	return &SplitProvider{present: present, cleanUp: cleanUp}
+}
+
+// Present creates the TXT record with the present provider.
+func (p *SplitProvider) Present(domain, token, keyAuth string) error {
+	return p.present.Present(domain, token, keyAuth)
+}
+
+// CleanUp removes the TXT record with the clean-up provider.
+func (p *SplitProvider) CleanUp(domain, token, keyAuth string) error {
+	return p.cleanUp.CleanUp(domain, token, keyAuth)
+}
+
+// PresentContext creates the TXT record with the present provider.
+func (p *SplitProvider) PresentContext(ctx context.Context, domain, token, keyAuth string) error {
+	return challenge.Present(ctx, p.present, domain, token, keyAuth)
+}
+
+// CleanUpContext removes the TXT record with the clean-up provider.
+func (p *SplitProvider) CleanUpContext(ctx context.Context, domain, token, keyAuth string) error {
+	return challenge.CleanUp(ctx, p.cleanUp, domain, token, keyAuth)
+}
+
+// Timeout returns the timeout and interval of the present provider.
+func (p *SplitProvider) Timeout() (timeout, interval time.Duration) {
+	if provider, ok := p.present.(challenge.ProviderTimeout); ok {
+		return provider.Timeout()
+	}
+
+	return DefaultPropagationTimeout, DefaultPollingInterval
+}
+
+// Unwrap returns the present provider.
+func (p *SplitProvider) Unwrap() challenge.Provider {
+	return p.present
+}
@@ -0,0 +1,52 @@
+package dns01
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerSequentialMock struct {
+	providerWaiterMock
+}
+
+func (p *providerSequentialMock) Sequential() time.Duration { return time.Minute }
+
+type providerTransactionalMock struct {
+	providerMock
+}
+
+func (p *providerTransactionalMock) Begin() error  { return nil }
+func (p *providerTransactionalMock) Commit() error { return nil }
+func (p *providerTransactionalMock) Rollback()     {}
+
+func TestSplitProvider_optionalInterfaces(t *testing.T) {
+	present := &providerSequentialMock{}
+
+	chlg := NewChallenge(nil, nil, NewSplitProvider(present, &providerMock{}))
+
+	sequential, interval := chlg.Sequential()
+	assert.True(t, sequential)
+	assert.Equal(t, time.Minute, interval)
+
+	waiter, ok := findProvider[PropagationWaiter](chlg.provider)
+	require.True(t, ok)
+	assert.Same(t, present, waiter)
+
+	_, ok = chlg.Transaction()
+	assert.False(t, ok)
+
+	tx := &providerTransactionalMock{}
+
+	chlg = NewChallenge(nil, nil, NewSplitProvider(tx, &providerMock{}))
+
+	provider, ok := chlg.Transaction()
+	require.True(t, ok)
+	assert.Same(t, challenge.Transactional(tx), provider)
+
+	sequential, _ = chlg.Sequential()
+	assert.False(t, sequential)
+}
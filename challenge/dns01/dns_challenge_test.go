@@ -1,9 +1,11 @@
 package dns01
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 	"time"
@@ -113,6 +115,22 @@ func TestChallenge_PreSolve(t *testing.T) {
 	}
 }
 
+type providerWaiterMock struct {
+	present, cleanUp, wait error
+}
+
+func (p *providerWaiterMock) Present(domain, token, keyAuth string) error { return p.present }
+func (p *providerWaiterMock) CleanUp(domain, token, keyAuth string) error { return p.cleanUp }
+func (p *providerWaiterMock) WaitForPropagation(domain, token, keyAuth string) error {
+	return p.wait
+}
+
+type providerAuthoritativeWaiterMock struct {
+	providerWaiterMock
+}
+
+func (p *providerAuthoritativeWaiterMock) ChecksAuthoritativeNameservers() bool { return true }
+
 func TestChallenge_Solve(t *testing.T) {
 	_, apiURL := tester.SetupFakeAPI(t)
 
@@ -126,6 +144,7 @@ func TestChallenge_Solve(t *testing.T) {
 		desc        string
 		validate    ValidateFunc
 		preCheck    WrapPreCheckFunc
+		options     []ChallengeOption
 		provider    challenge.Provider
 		expectError bool
 	}{
@@ -155,6 +174,50 @@ func TestChallenge_Solve(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			desc:     "propagation waiter",
+			validate: func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			provider: &providerWaiterMock{},
+		},
+		{
+			desc:        "propagation waiter fail",
+			validate:    func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			provider:    &providerWaiterMock{wait: errors.New("OOPS")},
+			expectError: true,
+		},
+		{
+			desc:        "authoritative propagation waiter fail",
+			validate:    func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			provider:    &providerAuthoritativeWaiterMock{providerWaiterMock{wait: errors.New("OOPS")}},
+			expectError: true,
+		},
+		{
+			desc:     "authoritative propagation waiter: custom pre-check",
+			validate: func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			preCheck: func(_, _, _ string, _ PreCheckFunc) (bool, error) { return true, nil },
+			provider: &providerAuthoritativeWaiterMock{providerWaiterMock{wait: errors.New("OOPS")}},
+		},
+		{
+			desc:     "authoritative propagation waiter: authoritative check disabled",
+			validate: func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			options:  []ChallengeOption{DisableAuthoritativeNssPropagationRequirement(), DisableRecursiveNssPropagationRequirement()},
+			provider: &providerAuthoritativeWaiterMock{providerWaiterMock{wait: errors.New("OOPS")}},
+		},
+		{
+			desc:     "propagation wait",
+			validate: func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			options:  []ChallengeOption{PropagationWait(10 * time.Millisecond)},
+			provider: &providerWaiterMock{wait: fmt.Errorf("OOPS: %w", errors.ErrUnsupported)},
+		},
+		{
+			desc: "propagation wait: plan mode",
+			validate: func(_ *api.Core, _ string, _ acme.Challenge) error {
+				return errors.New("the challenge must not be validated")
+			},
+			options:     []ChallengeOption{PropagationWait(10 * time.Millisecond)},
+			provider:    NewPlanProvider(&providerMock{}),
+			expectError: true,
+		},
 		{
 			desc:     "present fail",
 			validate: func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
@@ -175,7 +238,7 @@ func TestChallenge_Solve(t *testing.T) {
 
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
-			var options []ChallengeOption
+			options := test.options
 			if test.preCheck != nil {
 				options = append(options, WrapPreCheck(test.preCheck))
 			}
@@ -281,3 +344,138 @@ func TestChallenge_CleanUp(t *testing.T) {
 		})
 	}
 }
+
+type providerCountMock struct {
+	errs       []error
+	calls      int
+	idempotent bool
+}
+
+func (p *providerCountMock) Present(_, _, _ string) error {
+	p.calls++
+
+	if len(p.errs) == 0 {
+		return nil
+	}
+
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+
+	return err
+}
+
+func (p *providerCountMock) CleanUp(_, _, _ string) error { return nil }
+
+func (p *providerCountMock) IdempotentPresent() bool { return p.idempotent }
+
+func TestChallenge_present(t *testing.T) {
+	presentRetryDelay = time.Millisecond
+	t.Cleanup(func() { presentRetryDelay = 5 * time.Second })
+
+	testCases := []struct {
+		desc          string
+		errs          []error
+		idempotent    bool
+		expectedCalls int
+		expectedError string
+	}{
+		{
+			desc:          "transient error retried",
+			errs:          []error{challenge.Transient(errors.New("timeout"))},
+			idempotent:    true,
+			expectedCalls: 2,
+		},
+		{
+			desc:          "throttled error retried until the limit",
+			errs:          []error{challenge.Throttled(errors.New("429")), challenge.Throttled(errors.New("429")), challenge.Throttled(errors.New("429"))},
+			idempotent:    true,
+			expectedCalls: 3,
+			expectedError: "429",
+		},
+		{
+			desc:          "transient error not retried: not idempotent",
+			errs:          []error{challenge.Transient(errors.New("timeout"))},
+			expectedCalls: 1,
+			expectedError: "timeout",
+		},
+		{
+			desc:          "throttled error not retried: not idempotent",
+			errs:          []error{challenge.Throttled(errors.New("429"))},
+			expectedCalls: 1,
+			expectedError: "429",
+		},
+		{
+			desc:          "zone not found fails fast",
+			errs:          []error{challenge.NotFoundZone(errors.New("zone not found"))},
+			expectedCalls: 1,
+			expectedError: "zone not found",
+		},
+		{
+			desc:          "uncategorized error fails fast",
+			errs:          []error{errors.New("oops")},
+			expectedCalls: 1,
+			expectedError: "oops",
+		},
+		{
+			desc:          "auth error reported",
+			errs:          []error{challenge.AuthError(errors.New("invalid token"))},
+			expectedCalls: 1,
+			expectedError: "invalid credentials or missing permissions of the DNS provider: invalid token",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			provider := &providerCountMock{errs: test.errs, idempotent: test.idempotent}
+
+			chlg := &Challenge{provider: provider}
+
+			err := chlg.present(context.Background(), "example.com", "example.com", "token", "keyAuth")
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, test.expectedCalls, provider.calls)
+		})
+	}
+}
+
+func TestChallenge_present_canceled(t *testing.T) {
+	provider := &providerCountMock{errs: []error{challenge.Transient(errors.New("timeout"))}, idempotent: true}
+
+	chlg := &Challenge{provider: provider}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := chlg.present(ctx, "example.com", "example.com", "token", "keyAuth")
+	require.EqualError(t, err, "context canceled: timeout")
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.Equal(t, 1, provider.calls)
+}
+
+func TestChallenge_SolveContext_canceled(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	chlg := NewChallenge(core, nil, &providerMock{}, PropagationWait(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: "abc"}},
+	}
+
+	err = chlg.SolveContext(ctx, authz)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,89 @@
+package dns01
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// dohContentType the media type of the DNS messages sent over HTTPS (RFC 8484).
+const dohContentType = "application/dns-message"
+
+// isDoH checks if the nameserver is a DNS-over-HTTPS URL (ex: https://1.1.1.1/dns-query).
+func isDoH(ns string) bool {
+	return strings.HasPrefix(ns, "https://")
+}
+
+// dohTransport the transport of the DNS-over-HTTPS queries, the connections use the IP family of the DNS queries.
+var dohTransport http.RoundTripper = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: func(ctx context.Context, _, address string) (net.Conn, error) {
+		dialer := &net.Dialer{Timeout: dnsTimeout}
+
+		return dialer.DialContext(ctx, dnsIPFamily.Network("tcp"), address)
+	},
+	ForceAttemptHTTP2:   true,
+	TLSHandshakeTimeout: dnsTimeout,
+}
+
+// exchangeDoH sends the query to the DNS-over-HTTPS endpoint (RFC 8484, POST method).
+func exchangeDoH(ctx context.Context, m *dns.Msg, endpoint string) (*dns.Msg, error) {
+	// The ID must be 0 to be cacheable by the HTTP caches (RFC 8484 section 4.1).
+	query := m.Copy()
+	query.Id = 0
+
+	raw, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("pack DNS message: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dnsTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", dohContentType)
+	req.Header.Set("Accept", dohContentType)
+
+	client := &http.Client{Transport: dohTransport}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH endpoint %s: unexpected status code: %d", endpoint, resp.StatusCode)
+	}
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, dohContentType) {
+		return nil, fmt.Errorf("DoH endpoint %s: unexpected content type: %q", endpoint, ct)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(dns.Msg)
+
+	err = r.Unpack(body)
+	if err != nil {
+		return nil, fmt.Errorf("unpack DNS message: %w", err)
+	}
+
+	r.Id = m.Id
+
+	return r, nil
+}
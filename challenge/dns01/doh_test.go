@@ -0,0 +1,74 @@
+package dns01
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sendDNSQuery_doh(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/dns-query" {
+			http.NotFound(rw, req)
+			return
+		}
+
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != dohContentType {
+			http.Error(rw, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		raw, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := new(dns.Msg)
+		if err = query.Unpack(raw); err != nil || query.Id != 0 {
+			http.Error(rw, "invalid DNS message", http.StatusBadRequest)
+			return
+		}
+
+		m := new(dns.Msg)
+		m.SetReply(query)
+		m.Answer = append(m.Answer, &dns.TXT{
+			Hdr: dns.RR_Header{Name: query.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60},
+			Txt: []string{"value"},
+		})
+
+		data, err := m.Pack()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rw.Header().Set("Content-Type", dohContentType)
+		_, _ = rw.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	transport := dohTransport
+	t.Cleanup(func() { dohTransport = transport })
+
+	dohTransport = server.Client().Transport
+
+	nameservers := ParseNameservers([]string{server.URL + "/dns-query"})
+	assert.Equal(t, []string{server.URL + "/dns-query"}, nameservers)
+
+	m := createDNSMsg("_acme-challenge.example.com.", dns.TypeTXT, true)
+
+	r, err := sendDNSQuery(m, nameservers[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, m.Id, r.Id)
+	assert.True(t, containsTXT(r, "value"))
+
+	_, err = sendDNSQuery(m, server.URL+"/not-found")
+	require.ErrorContains(t, err, "unexpected status code: 404")
+}
@@ -1,6 +1,7 @@
 package dns01
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/miekg/dns"
+	"github.com/pya789/lego/v4/platform/ipfamily"
 )
 
 const defaultResolvConf = "/etc/resolv.conf"
@@ -63,6 +65,74 @@ func AddDNSTimeout(timeout time.Duration) ChallengeOption {
 	}
 }
 
+// DNSTransport the transport used by the DNS queries.
+type DNSTransport string
+
+const (
+	// DNSTransportUDP uses UDP, and TCP only when the response is truncated (default).
+	DNSTransportUDP DNSTransport = "udp"
+	// DNSTransportTCP uses only TCP.
+	DNSTransportTCP DNSTransport = "tcp"
+	// DNSTransportUDPWithTCPFallback uses UDP, and TCP when the response is truncated or when the UDP query fails (ex: timeout).
+	DNSTransportUDPWithTCPFallback DNSTransport = "udp+tcp"
+)
+
+const defaultEDNS0BufferSize = 4096
+
+var (
+	// dnsTransport the transport used by the DNS queries.
+	dnsTransport = DNSTransportUDP
+	// dnsRetries the number of retries of a DNS query on the same nameserver when the query fails (ex: timeout).
+	dnsRetries = 0
+	// dnsEDNS0BufferSize the EDNS0 UDP buffer size advertised by the DNS queries (0 disables EDNS0).
+	dnsEDNS0BufferSize uint16 = defaultEDNS0BufferSize
+	// dnsIPFamily the IP family used to contact the nameservers.
+	dnsIPFamily = ipfamily.Any
+)
+
+// SetDNSTransport sets the transport used by the DNS queries (propagation checks, zone lookups).
+func SetDNSTransport(transport DNSTransport) ChallengeOption {
+	return func(_ *Challenge) error {
+		switch transport {
+		case DNSTransportUDP, DNSTransportTCP, DNSTransportUDPWithTCPFallback:
+			dnsTransport = transport
+			return nil
+		default:
+			return fmt.Errorf("unsupported DNS transport: %s", transport)
+		}
+	}
+}
+
+// AddDNSRetries sets the number of retries of a DNS query on the same nameserver when the query fails (ex: timeout, lossy network).
+func AddDNSRetries(retries int) ChallengeOption {
+	return func(_ *Challenge) error {
+		if retries < 0 {
+			return fmt.Errorf("invalid number of DNS retries: %d", retries)
+		}
+
+		dnsRetries = retries
+		return nil
+	}
+}
+
+// SetIPFamily sets the IP family (IPv4/IPv6) used to contact the nameservers.
+// The nameservers defined by name are resolved, and contacted with the addresses of the family.
+func SetIPFamily(family ipfamily.Family) ChallengeOption {
+	return func(_ *Challenge) error {
+		dnsIPFamily = family
+		return nil
+	}
+}
+
+// AddEDNS0BufferSize sets the EDNS0 UDP buffer size advertised by the DNS queries.
+// A small size (ex: 1232) avoids the IP fragmentation, 0 disables EDNS0.
+func AddEDNS0BufferSize(size uint16) ChallengeOption {
+	return func(_ *Challenge) error {
+		dnsEDNS0BufferSize = size
+		return nil
+	}
+}
+
 func AddRecursiveNameservers(nameservers []string) ChallengeOption {
 	return func(_ *Challenge) error {
 		recursiveNameservers = ParseNameservers(nameservers)
@@ -83,8 +153,10 @@ func getNameservers(path string, defaults []string) []string {
 func ParseNameservers(servers []string) []string {
 	var resolvers []string
 	for _, resolver := range servers {
-		// ensure all servers have a port number
-		if _, _, err := net.SplitHostPort(resolver); err != nil {
+		// ensure all servers have a port number (except the DNS-over-HTTPS URLs)
+		if isDoH(resolver) {
+			resolvers = append(resolvers, resolver)
+		} else if _, _, err := net.SplitHostPort(resolver); err != nil {
 			resolvers = append(resolvers, net.JoinHostPort(resolver, "53"))
 		} else {
 			resolvers = append(resolvers, resolver)
@@ -225,6 +297,11 @@ func dnsMsgContainsCNAME(msg *dns.Msg) bool {
 }
 
 func dnsQuery(fqdn string, rtype uint16, nameservers []string, recursive bool) (*dns.Msg, error) {
+	return dnsQueryContext(context.Background(), fqdn, rtype, nameservers, recursive)
+}
+
+// dnsQueryContext is like dnsQuery, the queries are interrupted when the context is done.
+func dnsQueryContext(ctx context.Context, fqdn string, rtype uint16, nameservers []string, recursive bool) (*dns.Msg, error) {
 	m := createDNSMsg(fqdn, rtype, recursive)
 
 	if len(nameservers) == 0 {
@@ -236,7 +313,7 @@ func dnsQuery(fqdn string, rtype uint16, nameservers []string, recursive bool) (
 	var errAll error
 
 	for _, ns := range nameservers {
-		r, err = sendDNSQuery(m, ns)
+		r, err = sendDNSQueryContext(ctx, m, ns)
 		if err == nil && len(r.Answer) > 0 {
 			break
 		}
@@ -254,7 +331,10 @@ func dnsQuery(fqdn string, rtype uint16, nameservers []string, recursive bool) (
 func createDNSMsg(fqdn string, rtype uint16, recursive bool) *dns.Msg {
 	m := new(dns.Msg)
 	m.SetQuestion(fqdn, rtype)
-	m.SetEdns0(4096, false)
+
+	if dnsEDNS0BufferSize > 0 {
+		m.SetEdns0(dnsEDNS0BufferSize, false)
+	}
 
 	if !recursive {
 		m.RecursionDesired = false
@@ -264,30 +344,77 @@ func createDNSMsg(fqdn string, rtype uint16, recursive bool) *dns.Msg {
 }
 
 func sendDNSQuery(m *dns.Msg, ns string) (*dns.Msg, error) {
-	if ok, _ := strconv.ParseBool(os.Getenv("LEGO_EXPERIMENTAL_DNS_TCP_ONLY")); ok {
-		tcp := &dns.Client{Net: "tcp", Timeout: dnsTimeout}
-		r, _, err := tcp.Exchange(m, ns)
-		if err != nil {
-			return r, &DNSError{Message: "DNS call error", MsgIn: m, NS: ns, Err: err}
+	return sendDNSQueryContext(context.Background(), m, ns)
+}
+
+func sendDNSQueryContext(ctx context.Context, m *dns.Msg, ns string) (*dns.Msg, error) {
+	var r *dns.Msg
+	var err error
+
+	for attempt := 0; attempt <= dnsRetries; attempt++ {
+		r, err = exchangeDNSQuery(ctx, m, ns)
+		if err == nil || ctx.Err() != nil {
+			break
 		}
+	}
 
+	if err == nil {
 		return r, nil
 	}
 
-	udp := &dns.Client{Net: "udp", Timeout: dnsTimeout}
-	r, _, err := udp.Exchange(m, ns)
+	return r, &DNSError{Message: "DNS call error", MsgIn: m, NS: ns, Err: err}
+}
+
+func exchangeDNSQuery(ctx context.Context, m *dns.Msg, ns string) (*dns.Msg, error) {
+	if isDoH(ns) {
+		return exchangeDoH(ctx, m, ns)
+	}
+
+	transport := dnsTransport
+	if ok, _ := strconv.ParseBool(os.Getenv("LEGO_EXPERIMENTAL_DNS_TCP_ONLY")); ok {
+		transport = DNSTransportTCP
+	}
 
-	if r != nil && r.Truncated {
-		tcp := &dns.Client{Net: "tcp", Timeout: dnsTimeout}
+	if transport == DNSTransportTCP {
+		return exchange(ctx, m, "tcp", ns)
+	}
+
+	r, err := exchange(ctx, m, "udp", ns)
+
+	if (r != nil && r.Truncated) || (err != nil && transport == DNSTransportUDPWithTCPFallback) {
 		// If the TCP request succeeds, the "err" will reset to nil
-		r, _, err = tcp.Exchange(m, ns)
+		r, err = exchange(ctx, m, "tcp", ns)
+	}
+
+	return r, err
+}
+
+// exchange sends the query to the nameserver, using the addresses of the IP family.
+func exchange(ctx context.Context, m *dns.Msg, network, ns string) (*dns.Msg, error) {
+	client := &dns.Client{Net: dnsIPFamily.Network(network), Timeout: dnsTimeout}
+
+	if dnsIPFamily == ipfamily.Any {
+		r, _, err := client.ExchangeContext(ctx, m, ns)
+		return r, err
 	}
 
+	addresses, err := dnsIPFamily.Resolve(ctx, ns)
 	if err != nil {
-		return r, &DNSError{Message: "DNS call error", MsgIn: m, NS: ns, Err: err}
+		return nil, err
 	}
 
-	return r, nil
+	var errAll error
+
+	for _, address := range addresses {
+		r, _, errE := client.ExchangeContext(ctx, m, address)
+		if errE == nil {
+			return r, nil
+		}
+
+		errAll = errors.Join(errAll, errE)
+	}
+
+	return nil, errAll
 }
 
 // DNSError error related to DNS calls.
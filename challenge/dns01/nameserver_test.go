@@ -1,11 +1,15 @@
 package dns01
 
 import (
+	"context"
 	"errors"
+	"net"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/miekg/dns"
+	"github.com/pya789/lego/v4/platform/ipfamily"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -264,3 +268,94 @@ func TestDNSError_Error(t *testing.T) {
 		})
 	}
 }
+
+func Test_sendDNSQuery_transport(t *testing.T) {
+	// Only a TCP server: the UDP queries fail.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var edns0 []uint16
+
+	server := &dns.Server{
+		Listener: listener,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			if opt := req.IsEdns0(); opt != nil {
+				edns0 = append(edns0, opt.UDPSize())
+			}
+
+			m := new(dns.Msg)
+			m.SetReply(req)
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	timeout, transport, retries, bufferSize, family := dnsTimeout, dnsTransport, dnsRetries, dnsEDNS0BufferSize, dnsIPFamily
+	t.Cleanup(func() {
+		dnsTimeout, dnsTransport, dnsRetries, dnsEDNS0BufferSize, dnsIPFamily = timeout, transport, retries, bufferSize, family
+	})
+
+	chlg := &Challenge{}
+
+	require.NoError(t, AddDNSTimeout(200*time.Millisecond)(chlg))
+	require.NoError(t, AddDNSRetries(1)(chlg))
+
+	_, err = sendDNSQuery(createDNSMsg("example.com.", dns.TypeTXT, true), listener.Addr().String())
+	require.Error(t, err)
+
+	require.NoError(t, SetDNSTransport(DNSTransportUDPWithTCPFallback)(chlg))
+	require.NoError(t, AddEDNS0BufferSize(1232)(chlg))
+
+	r, err := sendDNSQuery(createDNSMsg("example.com.", dns.TypeTXT, true), listener.Addr().String())
+	require.NoError(t, err)
+	assert.Equal(t, dns.RcodeSuccess, r.Rcode)
+
+	require.NoError(t, SetDNSTransport(DNSTransportTCP)(chlg))
+	require.NoError(t, AddEDNS0BufferSize(0)(chlg))
+
+	_, err = sendDNSQuery(createDNSMsg("example.com.", dns.TypeTXT, true), listener.Addr().String())
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint16{1232}, edns0)
+
+	require.NoError(t, SetIPFamily(ipfamily.IPv6)(chlg))
+
+	_, err = sendDNSQuery(createDNSMsg("example.com.", dns.TypeTXT, true), listener.Addr().String())
+	require.ErrorContains(t, err, "no ipv6 address for 127.0.0.1")
+
+	require.NoError(t, SetIPFamily(ipfamily.PreferIPv6)(chlg))
+
+	_, err = sendDNSQuery(createDNSMsg("example.com.", dns.TypeTXT, true), listener.Addr().String())
+	require.NoError(t, err)
+}
+
+func TestSetDNSTransport_invalid(t *testing.T) {
+	require.EqualError(t, SetDNSTransport("quic")(&Challenge{}), "unsupported DNS transport: quic")
+	require.EqualError(t, AddDNSRetries(-1)(&Challenge{}), "invalid number of DNS retries: -1")
+}
+
+func Test_sendDNSQueryContext_deadline(t *testing.T) {
+	// A nameserver that never answers.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	timeout, retries := dnsTimeout, dnsRetries
+	t.Cleanup(func() { dnsTimeout, dnsRetries = timeout, retries })
+
+	dnsTimeout = 10 * time.Second
+	dnsRetries = 3
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err = sendDNSQueryContext(ctx, createDNSMsg("example.com.", dns.TypeTXT, true), conn.LocalAddr().String())
+	require.Error(t, err)
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
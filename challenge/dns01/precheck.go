@@ -1,11 +1,17 @@
 package dns01
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"net"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/miekg/dns"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/platform/random"
 )
 
 // PreCheckFunc checks DNS propagation before notifying ACME that the DNS challenge is ready.
@@ -23,9 +29,94 @@ func WrapPreCheck(wrap WrapPreCheckFunc) ChallengeOption {
 	}
 }
 
+// DisableCompletePropagationRequirement disables the check of the propagation of the TXT record to all authoritative nameservers.
+//
+// Deprecated: use DisableAuthoritativeNssPropagationRequirement instead.
 func DisableCompletePropagationRequirement() ChallengeOption {
+	return DisableAuthoritativeNssPropagationRequirement()
+}
+
+// DisableAuthoritativeNssPropagationRequirement disables the check of the propagation of the TXT record to all authoritative nameservers.
+func DisableAuthoritativeNssPropagationRequirement() ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.preCheck.requireAuthoritativeNssPropagation = false
+		return nil
+	}
+}
+
+// DisableRecursiveNssPropagationRequirement disables the queries to the recursive nameservers:
+// the CNAME of the TXT record are not followed, and the perspective nameservers are not checked.
+func DisableRecursiveNssPropagationRequirement() ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.preCheck.requireRecursiveNssPropagation = false
+		return nil
+	}
+}
+
+// PropagationWait waits for a fixed duration instead of checking the propagation of the TXT record:
+// the DNS provider is trusted to have published the record when the duration is over.
+// The propagation waiter of the provider (PropagationWaiter), if any, is called before the wait.
+func PropagationWait(wait time.Duration) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if wait <= 0 {
+			return fmt.Errorf("invalid propagation wait: %s", wait)
+		}
+
+		chlg.preCheck.wait = wait
+		return nil
+	}
+}
+
+// AddPerspectiveNameservers defines recursive nameservers (ex: public resolvers in different regions)
+// that must also return the TXT record before notifying ACME that the DNS challenge is ready.
+func AddPerspectiveNameservers(nameservers []string) ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.preCheck.perspectiveNameservers = ParseNameservers(nameservers)
+		return nil
+	}
+}
+
+// PropagationPolicy the number of authoritative nameservers that must return the TXT record.
+type PropagationPolicy string
+
+const (
+	// PropagationAll requires all the authoritative nameservers (default).
+	PropagationAll PropagationPolicy = "all"
+	// PropagationAny requires at least one authoritative nameserver.
+	PropagationAny PropagationPolicy = "any"
+	// PropagationQuorum requires a majority of the authoritative nameservers.
+	PropagationQuorum PropagationPolicy = "quorum"
+)
+
+// WithPropagationPolicy sets the number of authoritative nameservers that must return the TXT record,
+// and the timeout of the query to each nameserver (0 uses only the DNS timeout).
+// The authoritative nameservers are queried concurrently.
+func WithPropagationPolicy(policy PropagationPolicy, nameserverTimeout time.Duration) ChallengeOption {
+	return func(chlg *Challenge) error {
+		switch policy {
+		case PropagationAll, PropagationAny, PropagationQuorum:
+		default:
+			return fmt.Errorf("unsupported propagation policy: %s", policy)
+		}
+
+		if nameserverTimeout < 0 {
+			return fmt.Errorf("invalid nameserver timeout: %s", nameserverTimeout)
+		}
+
+		chlg.preCheck.policy = policy
+		chlg.preCheck.nameserverTimeout = nameserverTimeout
+
+		return nil
+	}
+}
+
+// EnableNegativeCacheBusting enables the negative-cache busting strategy:
+// when the recursive nameservers return NXDOMAIN for the TXT record,
+// the query is retried with a randomized case (DNS 0x20) and by rotating the recursive nameservers,
+// to avoid the negative answer cached by a resolver hiding the propagation of the record.
+func EnableNegativeCacheBusting() ChallengeOption {
 	return func(chlg *Challenge) error {
-		chlg.preCheck.requireCompletePropagation = false
+		chlg.preCheck.negativeCacheBusting = true
 		return nil
 	}
 }
@@ -34,12 +125,26 @@ type preCheck struct {
 	// checks DNS propagation before notifying ACME that the DNS challenge is ready.
 	checkFunc WrapPreCheckFunc
 	// require the TXT record to be propagated to all authoritative name servers
-	requireCompletePropagation bool
+	requireAuthoritativeNssPropagation bool
+	// query the recursive nameservers (CNAME, perspective nameservers).
+	requireRecursiveNssPropagation bool
+	// fixed duration to wait instead of checking the propagation.
+	wait time.Duration
+	// recursive nameservers used as additional vantage points.
+	perspectiveNameservers []string
+	// retry NXDOMAIN answers with a randomized case and rotated recursive nameservers.
+	negativeCacheBusting bool
+	// number of authoritative nameservers that must return the TXT record.
+	policy PropagationPolicy
+	// timeout of the query to each authoritative nameserver.
+	nameserverTimeout time.Duration
 }
 
 func newPreCheck() preCheck {
 	return preCheck{
-		requireCompletePropagation: true,
+		requireAuthoritativeNssPropagation: true,
+		requireRecursiveNssPropagation:     true,
+		policy:                             PropagationAll,
 	}
 }
 
@@ -53,58 +158,226 @@ func (p preCheck) call(domain, fqdn, value string) (bool, error) {
 
 // checkDNSPropagation checks if the expected TXT record has been propagated to all authoritative nameservers.
 func (p preCheck) checkDNSPropagation(fqdn, value string) (bool, error) {
-	// Initial attempt to resolve at the recursive NS
-	r, err := dnsQuery(fqdn, dns.TypeTXT, recursiveNameservers, true)
-	if err != nil {
-		return false, err
-	}
+	if p.requireRecursiveNssPropagation {
+		// Initial attempt to resolve at the recursive NS
+		r, err := dnsQuery(fqdn, dns.TypeTXT, recursiveNameservers, true)
+		if err != nil {
+			return false, err
+		}
 
-	if !p.requireCompletePropagation {
-		return true, nil
+		if r.Rcode == dns.RcodeNameError && p.negativeCacheBusting {
+			if ttl := negativeCacheTTL(r); ttl > 0 {
+				log.Infof("[%s] acme: NXDOMAIN from the recursive nameservers, the negative answer can be cached up to %s (SOA minimum TTL).", fqdn, ttl)
+			}
+
+			r, err = dnsQueryCacheBusting(fqdn, dns.TypeTXT, recursiveNameservers)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		if r.Rcode == dns.RcodeSuccess {
+			fqdn = updateDomainWithCName(r, fqdn)
+		}
 	}
 
-	if r.Rcode == dns.RcodeSuccess {
-		fqdn = updateDomainWithCName(r, fqdn)
+	if p.requireAuthoritativeNssPropagation {
+		authoritativeNss, err := lookupNameservers(fqdn)
+		if err != nil {
+			return false, err
+		}
+
+		found, err := checkAuthoritativeNssWithPolicy(fqdn, value, authoritativeNss, p.policy, p.nameserverTimeout)
+		if !found || err != nil {
+			return found, err
+		}
 	}
 
-	authoritativeNss, err := lookupNameservers(fqdn)
-	if err != nil {
-		return false, err
+	if !p.requireRecursiveNssPropagation {
+		return true, nil
 	}
 
-	return checkAuthoritativeNss(fqdn, value, authoritativeNss)
+	return checkPerspectiveNss(fqdn, value, p.perspectiveNameservers)
 }
 
-// checkAuthoritativeNss queries each of the given nameservers for the expected TXT record.
-func checkAuthoritativeNss(fqdn, value string, nameservers []string) (bool, error) {
+// checkPerspectiveNss queries each of the given recursive nameservers for the expected TXT record.
+func checkPerspectiveNss(fqdn, value string, nameservers []string) (bool, error) {
 	for _, ns := range nameservers {
-		r, err := dnsQuery(fqdn, dns.TypeTXT, []string{net.JoinHostPort(ns, "53")}, false)
+		r, err := dnsQuery(fqdn, dns.TypeTXT, []string{ns}, true)
 		if err != nil {
 			return false, err
 		}
 
-		if r.Rcode != dns.RcodeSuccess {
-			return false, fmt.Errorf("NS %s returned %s for %s", ns, dns.RcodeToString[r.Rcode], fqdn)
+		if !containsTXT(r, value) {
+			return false, fmt.Errorf("resolver %s did not return the expected TXT record [fqdn: %s, value: %s]", ns, fqdn, value)
+		}
+	}
+
+	return true, nil
+}
+
+func containsTXT(r *dns.Msg, value string) bool {
+	for _, rr := range r.Answer {
+		if txt, ok := rr.(*dns.TXT); ok && JoinTXTValue(txt.Txt) == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckAuthoritativeNameservers checks that each of the given nameservers (host, or host:port) returns the expected TXT record.
+// It can be used by the providers to confirm the propagation on their own nameservers.
+func CheckAuthoritativeNameservers(fqdn, value string, nameservers []string) (bool, error) {
+	return checkAuthoritativeNss(fqdn, value, nameservers)
+}
+
+// checkAuthoritativeNss queries each of the given nameservers for the expected TXT record.
+func checkAuthoritativeNss(fqdn, value string, nameservers []string) (bool, error) {
+	return checkAuthoritativeNssWithPolicy(fqdn, value, nameservers, PropagationAll, 0)
+}
+
+// checkAuthoritativeNssWithPolicy queries concurrently the given nameservers for the expected TXT record,
+// and checks that the number of nameservers returning the record satisfies the policy.
+func checkAuthoritativeNssWithPolicy(fqdn, value string, nameservers []string, policy PropagationPolicy, timeout time.Duration) (bool, error) {
+	if len(nameservers) == 0 {
+		return true, nil
+	}
+
+	errs := make([]error, len(nameservers))
+
+	var wg sync.WaitGroup
+
+	for i, ns := range nameservers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs[i] = checkAuthoritativeNsWithTimeout(fqdn, value, ns, timeout)
+		}()
+	}
+
+	wg.Wait()
+
+	return checkPropagationPolicy(errs, policy)
+}
+
+// checkPropagationPolicy checks that the number of nameservers returning the record (nil errors) satisfies the policy.
+func checkPropagationPolicy(errs []error, policy PropagationPolicy) (bool, error) {
+	var found int
+
+	var firstErr error
+
+	for _, err := range errs {
+		if err == nil {
+			found++
+		} else if firstErr == nil {
+			firstErr = err
 		}
+	}
+
+	var required int
+
+	switch policy {
+	case PropagationAny:
+		required = 1
+	case PropagationQuorum:
+		required = len(errs)/2 + 1
+	default:
+		required = len(errs)
+	}
+
+	if found >= required {
+		return true, nil
+	}
 
-		var records []string
-
-		var found bool
-		for _, rr := range r.Answer {
-			if txt, ok := rr.(*dns.TXT); ok {
-				record := strings.Join(txt.Txt, "")
-				records = append(records, record)
-				if record == value {
-					found = true
-					break
-				}
+	if policy == PropagationAll || policy == "" {
+		return false, firstErr
+	}
+
+	return false, fmt.Errorf("%d/%d authoritative nameservers returned the TXT record (policy: %s): %w", found, len(errs), policy, firstErr)
+}
+
+// checkAuthoritativeNsWithTimeout queries the nameserver for the expected TXT record,
+// and gives up after the timeout (if not zero).
+func checkAuthoritativeNsWithTimeout(fqdn, value, ns string, timeout time.Duration) error {
+	if timeout <= 0 {
+		return checkAuthoritativeNs(context.Background(), fqdn, value, ns)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := checkAuthoritativeNs(ctx, fqdn, value, ns)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("NS %s did not answer within %s for %s: %w", ns, timeout, fqdn, err)
+	}
+
+	return err
+}
+
+// checkAuthoritativeNs queries the nameserver for the expected TXT record.
+func checkAuthoritativeNs(ctx context.Context, fqdn, value, ns string) error {
+	r, err := dnsQueryContext(ctx, fqdn, dns.TypeTXT, ParseNameservers([]string{ns}), false)
+	if err != nil {
+		return err
+	}
+
+	if r.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("NS %s returned %s for %s", ns, dns.RcodeToString[r.Rcode], fqdn)
+	}
+
+	var records []string
+
+	for _, rr := range r.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			record := JoinTXTValue(txt.Txt)
+			records = append(records, record)
+			if record == value {
+				return nil
 			}
 		}
+	}
+
+	return fmt.Errorf("NS %s did not return the expected TXT record [fqdn: %s, value: %s]: %s", ns, fqdn, value, strings.Join(records, " ,"))
+}
+
+// dnsQueryCacheBusting queries the nameservers, starting from a random one, with a randomized case of the fqdn.
+func dnsQueryCacheBusting(fqdn string, rtype uint16, nameservers []string) (*dns.Msg, error) {
+	if len(nameservers) == 0 {
+		return nil, &DNSError{Message: "empty list of nameservers"}
+	}
+
+	offset := random.Intn(len(nameservers))
+	rotated := append(append([]string{}, nameservers[offset:]...), nameservers[:offset]...)
+
+	return dnsQuery(randomizeCase(fqdn), rtype, rotated, true)
+}
+
+// randomizeCase randomizes the case of the letters of the fqdn (DNS 0x20 encoding).
+func randomizeCase(fqdn string) string {
+	runes := []rune(fqdn)
+
+	for i, c := range runes {
+		if random.Intn(2) == 0 {
+			runes[i] = unicode.ToUpper(c)
+		} else {
+			runes[i] = unicode.ToLower(c)
+		}
+	}
+
+	return string(runes)
+}
 
-		if !found {
-			return false, fmt.Errorf("NS %s did not return the expected TXT record [fqdn: %s, value: %s]: %s", ns, fqdn, value, strings.Join(records, " ,"))
+// negativeCacheTTL returns the duration during which a negative answer can be cached (RFC 2308):
+// the minimum of the TTL of the SOA record and of its MINIMUM field.
+func negativeCacheTTL(r *dns.Msg) time.Duration {
+	for _, rr := range r.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return time.Duration(min(soa.Hdr.Ttl, soa.Minttl)) * time.Second
 		}
 	}
 
-	return true, nil
+	return 0
 }
@@ -1,8 +1,12 @@
 package dns01
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,6 +50,22 @@ func TestCheckDNSPropagation(t *testing.T) {
 	}
 }
 
+func TestCheckDNSPropagation_disabled(t *testing.T) {
+	check := newPreCheck()
+
+	for _, opt := range []ChallengeOption{DisableAuthoritativeNssPropagationRequirement(), DisableRecursiveNssPropagationRequirement()} {
+		chlg := &Challenge{preCheck: check}
+		require.NoError(t, opt(chlg))
+		check = chlg.preCheck
+	}
+
+	// No DNS queries: the DNS provider is trusted.
+	ok, err := check.checkDNSPropagation("_acme-challenge.example.com.", "value")
+	require.NoError(t, err)
+
+	assert.True(t, ok)
+}
+
 func TestCheckAuthoritativeNss(t *testing.T) {
 	testCases := []struct {
 		desc        string
@@ -112,3 +132,97 @@ func TestCheckAuthoritativeNssErr(t *testing.T) {
 		})
 	}
 }
+
+func Test_randomizeCase(t *testing.T) {
+	fqdn := "_acme-challenge.example.com."
+
+	assert.True(t, strings.EqualFold(fqdn, randomizeCase(fqdn)))
+}
+
+func Test_negativeCacheTTL(t *testing.T) {
+	r := &dns.Msg{
+		Ns: []dns.RR{&dns.SOA{
+			Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Ttl: 3600},
+			Minttl: 300,
+		}},
+	}
+
+	assert.Equal(t, 300*time.Second, negativeCacheTTL(r))
+
+	assert.Zero(t, negativeCacheTTL(&dns.Msg{}))
+}
+
+func Test_checkPropagationPolicy(t *testing.T) {
+	errNS := errors.New("NS ns2.example.com. did not return the expected TXT record")
+
+	testCases := []struct {
+		desc     string
+		errs     []error
+		policy   PropagationPolicy
+		found    bool
+		expected string
+	}{
+		{
+			desc:   "all: found",
+			errs:   []error{nil, nil, nil},
+			policy: PropagationAll,
+			found:  true,
+		},
+		{
+			desc:     "all: missing",
+			errs:     []error{nil, errNS, nil},
+			policy:   PropagationAll,
+			expected: errNS.Error(),
+		},
+		{
+			desc:   "any: found",
+			errs:   []error{errNS, nil, errNS},
+			policy: PropagationAny,
+			found:  true,
+		},
+		{
+			desc:     "any: missing",
+			errs:     []error{errNS, errNS},
+			policy:   PropagationAny,
+			expected: "0/2 authoritative nameservers returned the TXT record (policy: any): " + errNS.Error(),
+		},
+		{
+			desc:   "quorum: found",
+			errs:   []error{nil, errNS, nil, nil, errNS, nil},
+			policy: PropagationQuorum,
+			found:  true,
+		},
+		{
+			desc:     "quorum: missing",
+			errs:     []error{nil, errNS, nil, errNS, errNS, nil},
+			policy:   PropagationQuorum,
+			expected: "3/6 authoritative nameservers returned the TXT record (policy: quorum): " + errNS.Error(),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			found, err := checkPropagationPolicy(test.errs, test.policy)
+			if test.expected != "" {
+				require.EqualError(t, err, test.expected)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.found, found)
+		})
+	}
+}
+
+func TestWithPropagationPolicy(t *testing.T) {
+	chlg := &Challenge{preCheck: newPreCheck()}
+
+	require.NoError(t, WithPropagationPolicy(PropagationQuorum, 2*time.Second)(chlg))
+	assert.Equal(t, PropagationQuorum, chlg.preCheck.policy)
+	assert.Equal(t, 2*time.Second, chlg.preCheck.nameserverTimeout)
+
+	require.EqualError(t, WithPropagationPolicy("most", 0)(chlg), "unsupported propagation policy: most")
+	require.EqualError(t, WithPropagationPolicy(PropagationAny, -time.Second)(chlg), "invalid nameserver timeout: -1s")
+}
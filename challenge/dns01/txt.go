@@ -0,0 +1,50 @@
+package dns01
+
+import "strings"
+
+// MaxTXTStringLength is the maximum length (in bytes) of a character-string in a TXT record (RFC 1035).
+const MaxTXTStringLength = 255
+
+// SplitTXTValue splits a TXT value into character-strings of at most 255 bytes.
+// The resolvers (and the CA) join the character-strings of a TXT record without separator.
+func SplitTXTValue(value string) []string {
+	if len(value) <= MaxTXTStringLength {
+		return []string{value}
+	}
+
+	var chunks []string
+	for len(value) > MaxTXTStringLength {
+		chunks = append(chunks, value[:MaxTXTStringLength])
+		value = value[MaxTXTStringLength:]
+	}
+
+	return append(chunks, value)
+}
+
+// QuoteTXTValue formats a TXT value in the zone file presentation format,
+// i.e. one or more quoted character-strings of at most 255 bytes: `"chunk1" "chunk2"`.
+func QuoteTXTValue(value string) string {
+	chunks := SplitTXTValue(value)
+
+	for i, chunk := range chunks {
+		chunks[i] = `"` + chunk + `"`
+	}
+
+	return strings.Join(chunks, " ")
+}
+
+// UnquoteTXTValue parses a TXT value in the zone file presentation format (see QuoteTXTValue).
+// Escaped characters are not supported.
+func UnquoteTXTValue(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, `"`) {
+		return value
+	}
+
+	return strings.ReplaceAll(strings.Trim(value, `"`), `" "`, "")
+}
+
+// JoinTXTValue joins the character-strings of a TXT record.
+func JoinTXTValue(chunks []string) string {
+	return strings.Join(chunks, "")
+}
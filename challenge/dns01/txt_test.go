@@ -0,0 +1,58 @@
+package dns01
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTXTValue(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected []string
+	}{
+		{
+			desc:     "short value",
+			value:    "foo",
+			expected: []string{"foo"},
+		},
+		{
+			desc:     "255 bytes",
+			value:    strings.Repeat("a", 255),
+			expected: []string{strings.Repeat("a", 255)},
+		},
+		{
+			desc:     "long value",
+			value:    strings.Repeat("a", 255) + strings.Repeat("b", 255) + "c",
+			expected: []string{strings.Repeat("a", 255), strings.Repeat("b", 255), "c"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			chunks := SplitTXTValue(test.value)
+			assert.Equal(t, test.expected, chunks)
+
+			assert.Equal(t, test.value, JoinTXTValue(chunks))
+		})
+	}
+}
+
+func TestQuoteTXTValue(t *testing.T) {
+	assert.Equal(t, `"foo"`, QuoteTXTValue("foo"))
+
+	value := strings.Repeat("a", 255) + "b"
+	assert.Equal(t, `"`+strings.Repeat("a", 255)+`" "b"`, QuoteTXTValue(value))
+}
+
+func TestUnquoteTXTValue(t *testing.T) {
+	assert.Equal(t, "foo", UnquoteTXTValue("foo"))
+	assert.Equal(t, "foo", UnquoteTXTValue(`"foo"`))
+
+	value := strings.Repeat("a", 255) + "b"
+	assert.Equal(t, value, UnquoteTXTValue(QuoteTXTValue(value)))
+}
@@ -0,0 +1,101 @@
+package challenge
+
+import "errors"
+
+// ErrorCategory the category of an error of a provider.
+// The category is used to decide to retry, to fail fast, or to report a credential error.
+type ErrorCategory string
+
+const (
+	// ErrorCategoryAuth the credentials are invalid, or don't have the required permissions: fail fast.
+	ErrorCategoryAuth ErrorCategory = "auth"
+	// ErrorCategoryZoneNotFound the zone of the domain is not managed by the account: fail fast.
+	ErrorCategoryZoneNotFound ErrorCategory = "zone-not-found"
+	// ErrorCategoryThrottled the requests are throttled by the API (rate limit): retry later.
+	ErrorCategoryThrottled ErrorCategory = "throttled"
+	// ErrorCategoryTransient a temporary failure (network, server error): retry.
+	ErrorCategoryTransient ErrorCategory = "transient"
+	// ErrorCategoryPermanent the request cannot succeed (invalid request): fail fast.
+	ErrorCategoryPermanent ErrorCategory = "permanent"
+)
+
+// CategorizedError an error with a category.
+// The errors of the providers can implement this interface,
+// or be wrapped with AuthError, NotFoundZone, Throttled, Transient, or Permanent.
+type CategorizedError interface {
+	error
+	ErrorCategory() ErrorCategory
+}
+
+// ProviderError an error of a provider, with its category.
+type ProviderError struct {
+	Category ErrorCategory
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCategory returns the category of the error.
+func (e *ProviderError) ErrorCategory() ErrorCategory {
+	return e.Category
+}
+
+// AuthError wraps an error caused by invalid credentials, or by missing permissions.
+func AuthError(err error) error {
+	return newProviderError(ErrorCategoryAuth, err)
+}
+
+// NotFoundZone wraps an error caused by a zone not managed by the account.
+func NotFoundZone(err error) error {
+	return newProviderError(ErrorCategoryZoneNotFound, err)
+}
+
+// Throttled wraps an error caused by the rate limit of the API.
+func Throttled(err error) error {
+	return newProviderError(ErrorCategoryThrottled, err)
+}
+
+// Transient wraps an error caused by a temporary failure.
+func Transient(err error) error {
+	return newProviderError(ErrorCategoryTransient, err)
+}
+
+// Permanent wraps an error caused by a request that cannot succeed.
+func Permanent(err error) error {
+	return newProviderError(ErrorCategoryPermanent, err)
+}
+
+func newProviderError(category ErrorCategory, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ProviderError{Category: category, Err: err}
+}
+
+// GetErrorCategory returns the category of the error (the first category found in the chain of errors),
+// or an empty category if the error is not categorized.
+func GetErrorCategory(err error) ErrorCategory {
+	var categorized CategorizedError
+	if errors.As(err, &categorized) {
+		return categorized.ErrorCategory()
+	}
+
+	return ""
+}
+
+// IsRetryable returns true if the error is temporary (throttled or transient).
+func IsRetryable(err error) bool {
+	switch GetErrorCategory(err) {
+	case ErrorCategoryThrottled, ErrorCategoryTransient:
+		return true
+	default:
+		return false
+	}
+}
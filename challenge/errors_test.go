@@ -0,0 +1,40 @@
+package challenge
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetErrorCategory(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		err       error
+		expected  ErrorCategory
+		retryable bool
+	}{
+		{desc: "nil", err: nil},
+		{desc: "uncategorized", err: errors.New("oops")},
+		{desc: "auth", err: AuthError(errors.New("oops")), expected: ErrorCategoryAuth},
+		{desc: "zone not found", err: NotFoundZone(errors.New("oops")), expected: ErrorCategoryZoneNotFound},
+		{desc: "throttled", err: Throttled(errors.New("oops")), expected: ErrorCategoryThrottled, retryable: true},
+		{desc: "transient", err: Transient(errors.New("oops")), expected: ErrorCategoryTransient, retryable: true},
+		{desc: "permanent", err: Permanent(errors.New("oops")), expected: ErrorCategoryPermanent},
+		{desc: "wrapped", err: fmt.Errorf("provider: %w", Throttled(errors.New("oops"))), expected: ErrorCategoryThrottled, retryable: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, GetErrorCategory(test.err))
+			assert.Equal(t, test.retryable, IsRetryable(test.err))
+		})
+	}
+}
+
+func TestAuthError_nil(t *testing.T) {
+	assert.NoError(t, AuthError(nil))
+}
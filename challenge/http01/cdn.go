@@ -0,0 +1,129 @@
+package http01
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge/dns01"
+)
+
+// cdnSignatures the response headers used to identify a CDN.
+// An empty value means that only the presence of the header is checked.
+var cdnSignatures = []struct {
+	name   string
+	header string
+	value  string
+}{
+	{name: "Cloudflare", header: "Cf-Ray"},
+	{name: "Cloudflare", header: "Server", value: "cloudflare"},
+	{name: "Amazon CloudFront", header: "X-Amz-Cf-Id"},
+	{name: "Fastly", header: "X-Fastly-Request-Id"},
+	{name: "Akamai", header: "Server", value: "akamaighost"},
+	{name: "Azure Front Door", header: "X-Azure-Ref"},
+	{name: "Netlify", header: "X-Nf-Request-Id"},
+	{name: "Vercel", header: "X-Vercel-Id"},
+	{name: "Bunny CDN", header: "Server", value: "bunnycdn"},
+}
+
+// DetectCDN enables the detection of the CDNs serving the apex domains.
+// An apex domain served by a CDN is usually CNAME-flattened by its DNS provider,
+// so the HTTP-01 challenge is answered by the CDN instead of lego.
+func DetectCDN(client *http.Client) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if client == nil {
+			client = &http.Client{
+				Timeout: 10 * time.Second,
+				CheckRedirect: func(*http.Request, []*http.Request) error {
+					return http.ErrUseLastResponse
+				},
+			}
+		}
+
+		chlg.cdn = &cdnDetector{
+			client:         client,
+			findZoneByFqdn: dns01.FindZoneByFqdn,
+			results:        map[string]string{},
+		}
+
+		return nil
+	}
+}
+
+// DetectedCDN returns the name of the CDN serving the apex domain,
+// or an empty string if the domain is not an apex domain, if no CDN is detected, or if the detection is disabled.
+func (c *Challenge) DetectedCDN(domain string) string {
+	if c.cdn == nil {
+		return ""
+	}
+
+	return c.cdn.detect(domain)
+}
+
+type cdnDetector struct {
+	client *http.Client
+
+	// only for testing purpose.
+	findZoneByFqdn func(fqdn string) (string, error)
+
+	mu      sync.Mutex
+	results map[string]string
+}
+
+func (d *cdnDetector) detect(domain string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if name, ok := d.results[domain]; ok {
+		return name
+	}
+
+	name, err := d.lookup(domain)
+	if err != nil {
+		// The detection is a best effort: the challenge is solved as usual.
+		name = ""
+	}
+
+	d.results[domain] = name
+
+	return name
+}
+
+func (d *cdnDetector) lookup(domain string) (string, error) {
+	fqdn := dns01.ToFqdn(domain)
+
+	zone, err := d.findZoneByFqdn(fqdn)
+	if err != nil {
+		return "", fmt.Errorf("could not find zone: %w", err)
+	}
+
+	if zone != fqdn {
+		return "", nil
+	}
+
+	resp, err := d.client.Head("http://" + domain + "/")
+	if err != nil {
+		return "", err
+	}
+
+	_ = resp.Body.Close()
+
+	return identifyCDN(resp.Header), nil
+}
+
+func identifyCDN(header http.Header) string {
+	for _, sig := range cdnSignatures {
+		value := header.Get(sig.header)
+		if value == "" {
+			continue
+		}
+
+		if sig.value == "" || strings.Contains(strings.ToLower(value), sig.value) {
+			return sig.name
+		}
+	}
+
+	return ""
+}
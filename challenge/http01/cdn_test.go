@@ -0,0 +1,85 @@
+package http01
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_identifyCDN(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		header   http.Header
+		expected string
+	}{
+		{
+			desc:     "Cloudflare ray",
+			header:   http.Header{"Cf-Ray": []string{"8a1b2c3d4e5f-CDG"}},
+			expected: "Cloudflare",
+		},
+		{
+			desc:     "Cloudflare server",
+			header:   http.Header{"Server": []string{"cloudflare"}},
+			expected: "Cloudflare",
+		},
+		{
+			desc:     "CloudFront",
+			header:   http.Header{"X-Amz-Cf-Id": []string{"abc"}},
+			expected: "Amazon CloudFront",
+		},
+		{
+			desc:     "Akamai",
+			header:   http.Header{"Server": []string{"AkamaiGHost"}},
+			expected: "Akamai",
+		},
+		{
+			desc:   "no CDN",
+			header: http.Header{"Server": []string{"nginx"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, identifyCDN(test.header))
+		})
+	}
+}
+
+func TestChallenge_DetectedCDN(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("X-Vercel-Id", "cdg1::abc")
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	domain := serverURL.Host
+
+	chlg := &Challenge{}
+
+	assert.Empty(t, chlg.DetectedCDN(domain))
+
+	err = DetectCDN(server.Client())(chlg)
+	require.NoError(t, err)
+
+	chlg.cdn.findZoneByFqdn = func(fqdn string) (string, error) {
+		return fqdn, nil
+	}
+
+	assert.Equal(t, "Vercel", chlg.DetectedCDN(domain))
+
+	// not an apex domain.
+	chlg.cdn.results = map[string]string{}
+	chlg.cdn.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	assert.Empty(t, chlg.DetectedCDN(domain))
+}
@@ -1,6 +1,7 @@
 package http01
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pya789/lego/v4/acme"
@@ -17,17 +18,29 @@ func ChallengePath(token string) string {
 }
 
 type Challenge struct {
-	core     *api.Core
-	validate ValidateFunc
-	provider challenge.Provider
+	core         *api.Core
+	validate     ValidateFunc
+	provider     challenge.Provider
+	perspectives *perspectiveChecker
+	selfCheck    *SelfChecker
+	cdn          *cdnDetector
 }
 
-func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider) *Challenge {
-	return &Challenge{
+func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider, opts ...ChallengeOption) *Challenge {
+	chlg := &Challenge{
 		core:     core,
 		validate: validate,
 		provider: provider,
 	}
+
+	for _, opt := range opts {
+		err := opt(chlg)
+		if err != nil {
+			log.Infof("challenge option error: %v", err)
+		}
+	}
+
+	return chlg
 }
 
 func (c *Challenge) SetProvider(provider challenge.Provider) {
@@ -35,6 +48,11 @@ func (c *Challenge) SetProvider(provider challenge.Provider) {
 }
 
 func (c *Challenge) Solve(authz acme.Authorization) error {
+	return c.SolveContext(context.Background(), authz)
+}
+
+// SolveContext is like Solve, the context is passed to the provider if it implements challenge.ProviderContext.
+func (c *Challenge) SolveContext(ctx context.Context, authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 	log.Infof("[%s] acme: Trying to solve HTTP-01", domain)
 
@@ -49,17 +67,38 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 		return err
 	}
 
-	err = c.provider.Present(authz.Identifier.Value, chlng.Token, keyAuth)
+	if name := c.DetectedCDN(authz.Identifier.Value); name != "" {
+		log.Warnf("[%s] acme: the apex domain seems to be served by %s (CNAME flattening):"+
+			" the HTTP-01 challenge will fail unless the CDN forwards %s to this server."+
+			" Use the DNS-01 or TLS-ALPN-01 challenge, or define a fallback challenge.",
+			domain, name, ChallengePath(chlng.Token))
+	}
+
+	err = challenge.Present(ctx, c.provider, authz.Identifier.Value, chlng.Token, keyAuth)
 	if err != nil {
 		return fmt.Errorf("[%s] acme: error presenting token: %w", domain, err)
 	}
 	defer func() {
-		err := c.provider.CleanUp(authz.Identifier.Value, chlng.Token, keyAuth)
+		err := challenge.CleanUp(context.WithoutCancel(ctx), c.provider, authz.Identifier.Value, chlng.Token, keyAuth)
 		if err != nil {
 			log.Warnf("[%s] acme: cleaning up failed: %v", domain, err)
 		}
 	}()
 
+	if c.selfCheck != nil {
+		err = c.selfCheck.Check(authz.Identifier.Value, chlng.Token, keyAuth)
+		if err != nil {
+			return fmt.Errorf("[%s] acme: %w", domain, err)
+		}
+	}
+
+	if c.perspectives != nil {
+		err = c.perspectives.check(authz.Identifier.Value, chlng.Token, keyAuth)
+		if err != nil {
+			return fmt.Errorf("[%s] acme: %w", domain, err)
+		}
+	}
+
 	chlng.KeyAuthorization = keyAuth
 	return c.validate(c.core, domain, chlng)
 }
@@ -0,0 +1,97 @@
+package http01
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/platform/wait"
+)
+
+// PerspectivePlaceholder is replaced by the (escaped) URL of the challenge in the URL of a perspective checker.
+const PerspectivePlaceholder = "{url}"
+
+const (
+	defaultPerspectiveTimeout  = 60 * time.Second
+	defaultPerspectiveInterval = 5 * time.Second
+)
+
+// maxPerspectiveBodySize is the maximum size of body that we will read from a perspective checker.
+const maxPerspectiveBodySize = 1024 * 1024
+
+// ChallengeOption configures the HTTP-01 challenge.
+type ChallengeOption func(*Challenge) error
+
+// AddPerspectiveCheckers defines HTTP checkers used to verify, from external vantage points,
+// that the challenge is reachable before notifying the CA.
+//
+// A checker is a URL containing the `{url}` placeholder, replaced by the escaped URL of the challenge.
+// The checker must respond with a 200 status code and a body containing the key authorization.
+func AddPerspectiveCheckers(client *http.Client, checkers []string) ChallengeOption {
+	return func(chlg *Challenge) error {
+		for _, checker := range checkers {
+			if !strings.Contains(checker, PerspectivePlaceholder) {
+				return fmt.Errorf("the perspective checker %q must contain the %s placeholder", checker, PerspectivePlaceholder)
+			}
+		}
+
+		if client == nil {
+			client = &http.Client{Timeout: 10 * time.Second}
+		}
+
+		chlg.perspectives = &perspectiveChecker{client: client, checkers: checkers}
+
+		return nil
+	}
+}
+
+type perspectiveChecker struct {
+	client   *http.Client
+	checkers []string
+}
+
+// check polls all the checkers until they all see the key authorization.
+func (p *perspectiveChecker) check(domain, token, keyAuth string) error {
+	challengeURL := "http://" + domain + ChallengePath(token)
+
+	return wait.For("perspective checks", defaultPerspectiveTimeout, defaultPerspectiveInterval, func() (bool, error) {
+		var errAll error
+
+		for _, checker := range p.checkers {
+			err := p.checkOne(strings.ReplaceAll(checker, PerspectivePlaceholder, url.QueryEscape(challengeURL)), keyAuth)
+			if err != nil {
+				errAll = errors.Join(errAll, err)
+			}
+		}
+
+		return errAll == nil, errAll
+	})
+}
+
+func (p *perspectiveChecker) checkOne(checkerURL, keyAuth string) error {
+	resp, err := p.client.Get(checkerURL)
+	if err != nil {
+		return fmt.Errorf("perspective checker: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("perspective checker %s: unexpected status code: %d", resp.Request.URL.Host, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPerspectiveBodySize))
+	if err != nil {
+		return fmt.Errorf("perspective checker %s: %w", resp.Request.URL.Host, err)
+	}
+
+	if !strings.Contains(string(body), keyAuth) {
+		return fmt.Errorf("perspective checker %s: the key authorization was not found", resp.Request.URL.Host)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package http01
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_perspectiveChecker_checkOne(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/check", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("url") != "http://example.com/.well-known/acme-challenge/token" {
+			http.Error(w, "invalid URL", http.StatusBadRequest)
+			return
+		}
+
+		_, _ = fmt.Fprint(w, "token.keyAuth")
+	})
+
+	chlg := &Challenge{}
+
+	err := AddPerspectiveCheckers(server.Client(), []string{server.URL + "/check?url={url}"})(chlg)
+	require.NoError(t, err)
+
+	err = chlg.perspectives.check("example.com", "token", "token.keyAuth")
+	require.NoError(t, err)
+
+	err = chlg.perspectives.checkOne(server.URL+"/check?url=foo", "token.keyAuth")
+	require.Error(t, err)
+}
+
+func TestAddPerspectiveCheckers_missingPlaceholder(t *testing.T) {
+	chlg := &Challenge{}
+
+	err := AddPerspectiveCheckers(nil, []string{"https://checker.example.com"})(chlg)
+	require.Error(t, err)
+
+	assert.Nil(t, chlg.perspectives)
+}
@@ -0,0 +1,112 @@
+package http01
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/platform/wait"
+)
+
+const (
+	defaultSelfCheckTimeout  = 30 * time.Second
+	defaultSelfCheckInterval = 2 * time.Second
+)
+
+// EnableSelfCheck verifies, before notifying the CA, that the challenge is served on the domain (http://<domain>/.well-known/acme-challenge/<token>).
+// The client allows to select the address family (IPv4/IPv6) of the check, to match the address family used by the CA.
+func EnableSelfCheck(client *http.Client) ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.selfCheck = NewSelfChecker(client, "", 0, 0)
+
+		return nil
+	}
+}
+
+// SelfCheckError the response to a self-check doesn't match the key authorization.
+type SelfCheckError struct {
+	// StatusCode the status code of the response (200 if the body doesn't match the key authorization).
+	StatusCode int
+}
+
+func (e *SelfCheckError) Error() string {
+	if e.StatusCode == http.StatusOK {
+		return "self-check: the response doesn't match the key authorization"
+	}
+
+	return fmt.Sprintf("self-check: unexpected status code: %d", e.StatusCode)
+}
+
+// SelfChecker verifies that a challenge is served on a domain.
+type SelfChecker struct {
+	client   *http.Client
+	address  string
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// NewSelfChecker creates a SelfChecker.
+// The requests are sent to the address (ex: 127.0.0.1:80) with the domain as Host header, or to the domain if the address is empty.
+// The challenge is requested every interval (2 seconds if 0), up to the timeout (30 seconds if 0).
+func NewSelfChecker(client *http.Client, address string, timeout, interval time.Duration) *SelfChecker {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if timeout <= 0 {
+		timeout = defaultSelfCheckTimeout
+	}
+
+	if interval <= 0 {
+		interval = defaultSelfCheckInterval
+	}
+
+	return &SelfChecker{client: client, address: address, timeout: timeout, interval: interval}
+}
+
+// Check polls the challenge URL until it responds with the key authorization.
+// The last error is a *SelfCheckError if the server responded.
+func (s *SelfChecker) Check(domain, token, keyAuth string) error {
+	return wait.For("self-check", s.timeout, s.interval, func() (bool, error) {
+		err := s.checkOnce(domain, token, keyAuth)
+		return err == nil, err
+	})
+}
+
+func (s *SelfChecker) checkOnce(domain, token, keyAuth string) error {
+	host := s.address
+	if host == "" {
+		host = domain
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+host+ChallengePath(token), http.NoBody)
+	if err != nil {
+		return fmt.Errorf("self-check: %w", err)
+	}
+
+	req.Host = domain
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("self-check: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return &SelfCheckError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPerspectiveBodySize))
+	if err != nil {
+		return fmt.Errorf("self-check: %w", err)
+	}
+
+	if strings.TrimSpace(string(body)) != keyAuth {
+		return &SelfCheckError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package http01
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selfChecker_checkOnce(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc(ChallengePath("token"), func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "token.keyAuth")
+	})
+
+	chlg := &Challenge{}
+
+	err := EnableSelfCheck(server.Client())(chlg)
+	require.NoError(t, err)
+
+	// the address of the test server is used as domain.
+	domain := server.Listener.Addr().String()
+
+	err = chlg.selfCheck.checkOnce(domain, "token", "token.keyAuth")
+	require.NoError(t, err)
+
+	err = chlg.selfCheck.checkOnce(domain, "token", "token.other")
+	require.EqualError(t, err, "self-check: the response doesn't match the key authorization")
+
+	err = chlg.selfCheck.checkOnce(domain, "unknown", "token.keyAuth")
+	require.EqualError(t, err, "self-check: unexpected status code: 404")
+}
+
+func TestSelfChecker_Check_address(t *testing.T) {
+	var host string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		host = req.Host
+		http.NotFound(rw, req)
+	}))
+	t.Cleanup(server.Close)
+
+	checker := NewSelfChecker(server.Client(), server.Listener.Addr().String(), 10*time.Millisecond, 10*time.Millisecond)
+
+	err := checker.Check("example.com", "token", "token.keyAuth")
+	require.ErrorContains(t, err, "self-check: unexpected status code: 404")
+
+	var selfCheckErr *SelfCheckError
+	require.ErrorAs(t, err, &selfCheckErr)
+	assert.Equal(t, http.StatusNotFound, selfCheckErr.StatusCode)
+
+	assert.Equal(t, "example.com", host)
+}
@@ -1,6 +1,9 @@
 package challenge
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Provider enables implementing a custom challenge
 // provider. Present presents the solution to a challenge available to
@@ -11,6 +14,37 @@ type Provider interface {
 	CleanUp(domain, token, keyAuth string) error
 }
 
+// ProviderContext allows for implementing a Provider whose operations
+// can be cancelled or bounded by a deadline (ex: the calls to the API of a DNS provider).
+// If a Provider provides the PresentContext and CleanUpContext methods,
+// the solvers call them instead of Present and CleanUp, with the context of the obtain request.
+// CleanUpContext is not cancelled with the obtain request (but keeps its values), so the solutions are always removed.
+type ProviderContext interface {
+	Provider
+	PresentContext(ctx context.Context, domain, token, keyAuth string) error
+	CleanUpContext(ctx context.Context, domain, token, keyAuth string) error
+}
+
+// Present presents the solution to a challenge with the provider,
+// the context is used if the provider implements ProviderContext.
+func Present(ctx context.Context, provider Provider, domain, token, keyAuth string) error {
+	if p, ok := provider.(ProviderContext); ok {
+		return p.PresentContext(ctx, domain, token, keyAuth)
+	}
+
+	return provider.Present(domain, token, keyAuth)
+}
+
+// CleanUp cleans up the solution to a challenge with the provider,
+// the context is used if the provider implements ProviderContext.
+func CleanUp(ctx context.Context, provider Provider, domain, token, keyAuth string) error {
+	if p, ok := provider.(ProviderContext); ok {
+		return p.CleanUpContext(ctx, domain, token, keyAuth)
+	}
+
+	return provider.CleanUp(domain, token, keyAuth)
+}
+
 // ProviderTimeout allows for implementing a
 // Provider where an unusually long timeout is required when
 // waiting for an ACME challenge to be satisfied, such as when
@@ -26,3 +60,65 @@ type ProviderTimeout interface {
 	Provider
 	Timeout() (timeout, interval time.Duration)
 }
+
+// Idempotent allows for implementing a Provider whose Present
+// can be called again with the same parameters without side effects
+// (ex: the record is not created twice if it already exists).
+// The temporary errors (throttled, transient) of Present are only retried if the provider is idempotent:
+// a failed call can have partially applied the changes (ex: a timeout after the creation of the record).
+type Idempotent interface {
+	Provider
+	IdempotentPresent() bool
+}
+
+// ProviderExclusiveRecord allows for implementing a Provider which cannot keep
+// several TXT records on the same FQDN: Present replaces the existing TXT records,
+// or CleanUp removes all the TXT records of the FQDN.
+// The challenges of an order sharing the same FQDN (ex: example.com and *.example.com)
+// are then solved one after the other: the record of a challenge is presented, validated and cleaned up
+// before the record of the next challenge is presented.
+type ProviderExclusiveRecord interface {
+	Provider
+	ExclusiveRecord() bool
+}
+
+// Transactional allows for implementing a Provider able to apply
+// all the record changes of an order in a single atomic change set
+// (ex: a change batch of a DNS zone).
+// Between Begin and Commit, Present and CleanUp only stage the changes.
+// Commit applies all the staged changes, or none of them:
+// if only a part of the changes can be applied (ex: several zones),
+// the applied changes are reverted before returning the error.
+// Rollback discards the staged changes.
+type Transactional interface {
+	Provider
+	Begin() error
+	Commit() error
+	Rollback()
+}
+
+// CredentialsVerifier allows for implementing a Provider able to check its credentials
+// with a cheap call to its API (ex: list the zones), without changing the records.
+// The callers can then detect invalid or revoked credentials before solving the challenges (ex: at the start of a daemon).
+type CredentialsVerifier interface {
+	Provider
+	VerifyCredentials(ctx context.Context) error
+}
+
+// VerifyCredentials checks the credentials of the provider if it implements CredentialsVerifier,
+// or of the provider wrapped by it (a provider implementing Unwrap() Provider).
+// Returns false if the provider cannot check its credentials.
+func VerifyCredentials(ctx context.Context, provider Provider) (bool, error) {
+	for {
+		if p, ok := provider.(CredentialsVerifier); ok {
+			return true, p.VerifyCredentials(ctx)
+		}
+
+		wrapper, ok := provider.(interface{ Unwrap() Provider })
+		if !ok {
+			return false, nil
+		}
+
+		provider = wrapper.Unwrap()
+	}
+}
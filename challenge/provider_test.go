@@ -0,0 +1,130 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type contextKey struct{}
+
+type providerMock struct {
+	calls []string
+}
+
+func (p *providerMock) Present(_, _, _ string) error {
+	p.calls = append(p.calls, "Present")
+	return nil
+}
+
+func (p *providerMock) CleanUp(_, _, _ string) error {
+	p.calls = append(p.calls, "CleanUp")
+	return nil
+}
+
+type providerContextMock struct {
+	providerMock
+}
+
+func (p *providerContextMock) PresentContext(ctx context.Context, _, _, _ string) error {
+	p.calls = append(p.calls, "PresentContext:"+ctx.Value(contextKey{}).(string))
+	return nil
+}
+
+func (p *providerContextMock) CleanUpContext(ctx context.Context, _, _, _ string) error {
+	p.calls = append(p.calls, "CleanUpContext:"+ctx.Value(contextKey{}).(string))
+	return nil
+}
+
+func TestPresent_CleanUp(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+	provider := &providerMock{}
+
+	require.NoError(t, Present(ctx, provider, "example.com", "token", "keyAuth"))
+	require.NoError(t, CleanUp(ctx, provider, "example.com", "token", "keyAuth"))
+
+	assert.Equal(t, []string{"Present", "CleanUp"}, provider.calls)
+}
+
+func TestPresent_CleanUp_providerContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+	provider := &providerContextMock{}
+
+	require.NoError(t, Present(ctx, provider, "example.com", "token", "keyAuth"))
+	require.NoError(t, CleanUp(ctx, provider, "example.com", "token", "keyAuth"))
+
+	assert.Equal(t, []string{"PresentContext:value", "CleanUpContext:value"}, provider.calls)
+}
+
+type providerVerifierMock struct {
+	providerMock
+
+	err error
+}
+
+func (p *providerVerifierMock) VerifyCredentials(_ context.Context) error {
+	return p.err
+}
+
+type providerWrapperMock struct {
+	providerMock
+
+	provider Provider
+}
+
+func (p *providerWrapperMock) Unwrap() Provider {
+	return p.provider
+}
+
+func TestVerifyCredentials(t *testing.T) {
+	errInvalid := AuthError(errors.New("invalid token"))
+
+	testCases := []struct {
+		desc          string
+		provider      Provider
+		expectedOK    bool
+		expectedError error
+	}{
+		{
+			desc:     "not supported",
+			provider: &providerMock{},
+		},
+		{
+			desc:       "valid",
+			provider:   &providerVerifierMock{},
+			expectedOK: true,
+		},
+		{
+			desc:          "invalid",
+			provider:      &providerVerifierMock{err: errInvalid},
+			expectedOK:    true,
+			expectedError: errInvalid,
+		},
+		{
+			desc:          "wrapped",
+			provider:      &providerWrapperMock{provider: &providerVerifierMock{err: errInvalid}},
+			expectedOK:    true,
+			expectedError: errInvalid,
+		},
+		{
+			desc:     "wrapped not supported",
+			provider: &providerWrapperMock{provider: &providerMock{}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := VerifyCredentials(context.Background(), test.provider)
+
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
@@ -12,14 +12,28 @@ type obtainError map[string]error
 func (e obtainError) Error() string {
 	buffer := bytes.NewBufferString("error: one or more domains had a problem:\n")
 
+	for _, domain := range e.domains() {
+		_, _ = fmt.Fprintf(buffer, "[%s] %s\n", domain, e[domain])
+	}
+	return buffer.String()
+}
+
+// Unwrap returns the errors of the domains.
+func (e obtainError) Unwrap() []error {
+	var errs []error
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// domains returns the sorted domains.
+func (e obtainError) domains() []string {
 	var domains []string
 	for domain := range e {
 		domains = append(domains, domain)
 	}
 	sort.Strings(domains)
 
-	for _, domain := range domains {
-		_, _ = fmt.Fprintf(buffer, "[%s] %s\n", domain, e[domain])
-	}
-	return buffer.String()
+	return domains
 }
@@ -1,7 +1,9 @@
 package resolver
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pya789/lego/v4/acme"
@@ -25,10 +27,34 @@ type cleanup interface {
 	CleanUp(authorization acme.Authorization) error
 }
 
+// Interfaces for the solvers able to pass a context to their provider (see challenge.ProviderContext).
+type solverContext interface {
+	SolveContext(ctx context.Context, authorization acme.Authorization) error
+}
+
+type preSolverContext interface {
+	PreSolveContext(ctx context.Context, authorization acme.Authorization) error
+}
+
+type cleanupContext interface {
+	CleanUpContext(ctx context.Context, authorization acme.Authorization) error
+}
+
 type sequential interface {
 	Sequential() (bool, time.Duration)
 }
 
+// Interface for challenges like dns, where the authorizations of an order can share the same record (ex: example.com and *.example.com),
+// and where the provider cannot keep several records at the same time (see challenge.ProviderExclusiveRecord).
+type exclusiveRecord interface {
+	ExclusiveRecord(authz acme.Authorization) (string, bool)
+}
+
+// Interface for challenges like dns, where the records of the authorizations are grouped by zone (see SolverManager.SetZoneConcurrency).
+type zoneFinder interface {
+	Zone(authz acme.Authorization) (string, error)
+}
+
 // an authz with the solver we have chosen and the index of the challenge associated with it.
 type selectedAuthSolver struct {
 	authz  acme.Authorization
@@ -48,6 +74,16 @@ func NewProber(solverManager *SolverManager) *Prober {
 // Solve Looks through the challenge combinations to find a solvable match.
 // Then solves the challenges in series and returns.
 func (p *Prober) Solve(authorizations []acme.Authorization) error {
+	return p.SolveWithStatus(authorizations, nil)
+}
+
+// SolveWithStatus is like Solve, and reports the status updates of the challenge of each domain to notify.
+func (p *Prober) SolveWithStatus(authorizations []acme.Authorization, notify challenge.StatusFunc) error {
+	return p.SolveContext(context.Background(), authorizations, notify)
+}
+
+// SolveContext is like SolveWithStatus, the context is passed to the providers implementing challenge.ProviderContext.
+func (p *Prober) SolveContext(ctx context.Context, authorizations []acme.Authorization, notify challenge.StatusFunc) error {
 	failures := make(obtainError)
 
 	var authSolvers []*selectedAuthSolver
@@ -60,6 +96,7 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 		if authz.Status == acme.StatusValid {
 			// Boulder might recycle recent validated authz (see issue #267)
 			log.Infof("[%s] acme: authorization already valid; skipping challenge", domain)
+			notify.Notify(domain, challenge.StatusSkipped, nil)
 			continue
 		}
 
@@ -81,9 +118,43 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 		}
 	}
 
-	parallelSolve(authSolvers, failures)
+	batches := splitBatches(authSolvers, p.solverManager.batchSize)
+
+	for i, batch := range batches {
+		if i > 0 && !pause(ctx, p.solverManager.batchInterval, i, len(batches)) {
+			for _, authSolver := range batch {
+				failures[challenge.GetTargetedDomain(authSolver.authz)] = ctx.Err()
+			}
+
+			continue
+		}
+
+		var rounds [][]*selectedAuthSolver
+		for _, round := range splitExclusiveRecords(batch) {
+			rounds = append(rounds, splitZoneConcurrency(round, p.solverManager.zoneConcurrency)...)
+		}
+
+		for j, round := range rounds {
+			if j > 0 && ctx.Err() != nil {
+				// the deadline of the request is over: the next rounds are not presented.
+				for _, authSolver := range round {
+					failures[challenge.GetTargetedDomain(authSolver.authz)] = ctx.Err()
+				}
+
+				continue
+			}
+
+			parallelSolve(ctx, round, failures, notify)
+		}
+	}
+
+	sequentialSolve(ctx, authSolversSequential, failures, notify)
 
-	sequentialSolve(authSolversSequential, failures)
+	if notify != nil {
+		for _, domain := range failures.domains() {
+			notify(domain, challenge.StatusInvalid, failures[domain])
+		}
+	}
 
 	// Be careful not to return an empty failures map,
 	// for even an empty obtainError is a non-nil error value
@@ -93,30 +164,176 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 	return nil
 }
 
-func sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
+// splitBatches splits the authorizations into batches of size authorizations (a single batch if size is lower than 1).
+func splitBatches(authSolvers []*selectedAuthSolver, size int) [][]*selectedAuthSolver {
+	if size < 1 || len(authSolvers) <= size {
+		return [][]*selectedAuthSolver{authSolvers}
+	}
+
+	var batches [][]*selectedAuthSolver
+
+	for start := 0; start < len(authSolvers); start += size {
+		batches = append(batches, authSolvers[start:min(start+size, len(authSolvers))])
+	}
+
+	return batches
+}
+
+// pause waits for the interval before the batch i, returns false if the context is done.
+func pause(ctx context.Context, interval time.Duration, i, count int) bool {
+	log.Infof("acme: batch %d/%d of authorizations, wait for %s", i+1, count, interval)
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(interval):
+		return true
+	}
+}
+
+// splitExclusiveRecords splits the authorizations into rounds solved one after the other:
+// the authorizations sharing the same exclusive record are solved in different rounds,
+// so the record of an authorization is not replaced (or removed) by the record of another authorization.
+// The other authorizations are solved in the first round.
+func splitExclusiveRecords(authSolvers []*selectedAuthSolver) [][]*selectedAuthSolver {
+	rounds := [][]*selectedAuthSolver{nil}
+
+	// the records presented in each round.
+	var records []map[string]bool
+
+	for _, authSolver := range authSolvers {
+		solvr, ok := authSolver.solver.(exclusiveRecord)
+		if !ok {
+			rounds[0] = append(rounds[0], authSolver)
+			continue
+		}
+
+		fqdn, exclusive := solvr.ExclusiveRecord(authSolver.authz)
+		if !exclusive {
+			rounds[0] = append(rounds[0], authSolver)
+			continue
+		}
+
+		i := 0
+		for i < len(records) && records[i][fqdn] {
+			i++
+		}
+
+		if i == len(records) {
+			records = append(records, make(map[string]bool))
+		}
+
+		if i == len(rounds) {
+			rounds = append(rounds, nil)
+		}
+
+		if i > 0 {
+			log.Infof("[%s] acme: the record %s is shared with another authorization: the challenge is solved after it",
+				challenge.GetTargetedDomain(authSolver.authz), fqdn)
+		}
+
+		records[i][fqdn] = true
+		rounds[i] = append(rounds[i], authSolver)
+	}
+
+	return rounds
+}
+
+// splitZoneConcurrency splits the authorizations into rounds solved one after the other:
+// a round contains at most the limit of authorizations of each zone (see SolverManager.SetZoneConcurrency),
+// the authorizations of the other zones, and the authorizations without zone, are solved in the first round.
+func splitZoneConcurrency(authSolvers []*selectedAuthSolver, limits map[string]int) [][]*selectedAuthSolver {
+	if len(limits) == 0 || len(authSolvers) == 0 {
+		return [][]*selectedAuthSolver{authSolvers}
+	}
+
+	rounds := [][]*selectedAuthSolver{nil}
+
+	// the number of authorizations of each zone in each round.
+	counts := []map[string]int{{}}
+
+	for _, authSolver := range authSolvers {
+		zone, limit := zoneLimit(authSolver, limits)
+		if limit < 1 {
+			rounds[0] = append(rounds[0], authSolver)
+			continue
+		}
+
+		i := 0
+		for i < len(counts) && counts[i][zone] >= limit {
+			i++
+		}
+
+		if i == len(counts) {
+			counts = append(counts, make(map[string]int))
+			rounds = append(rounds, nil)
+		}
+
+		if i > 0 {
+			log.Infof("[%s] acme: the zone %s is limited to %d concurrent challenges: the challenge is solved in the round %d",
+				challenge.GetTargetedDomain(authSolver.authz), zone, limit, i+1)
+		}
+
+		counts[i][zone]++
+		rounds[i] = append(rounds[i], authSolver)
+	}
+
+	return rounds
+}
+
+// zoneLimit returns the zone of the authorization, and its concurrency limit (0 if unlimited).
+func zoneLimit(authSolver *selectedAuthSolver, limits map[string]int) (string, int) {
+	solvr, ok := authSolver.solver.(zoneFinder)
+	if !ok {
+		return "", 0
+	}
+
+	zone, err := solvr.Zone(authSolver.authz)
+	if err != nil {
+		log.Warnf("[%s] acme: could not find the zone, the concurrency of the challenge is not limited: %v",
+			challenge.GetTargetedDomain(authSolver.authz), err)
+		return "", 0
+	}
+
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+
+	if limit, ok := limits[zone]; ok {
+		return zone, limit
+	}
+
+	return zone, limits[AllZones]
+}
+
+func sequentialSolve(ctx context.Context, authSolvers []*selectedAuthSolver, failures obtainError, notify challenge.StatusFunc) {
 	for i, authSolver := range authSolvers {
 		// Submit the challenge
 		domain := challenge.GetTargetedDomain(authSolver.authz)
 
 		if solvr, ok := authSolver.solver.(preSolver); ok {
-			err := solvr.PreSolve(authSolver.authz)
+			notify.Notify(domain, challenge.StatusPresenting, nil)
+
+			err := preSolve(ctx, solvr, authSolver.authz)
 			if err != nil {
 				failures[domain] = err
-				cleanUp(authSolver.solver, authSolver.authz)
+				cleanUp(ctx, authSolver.solver, authSolver.authz, notify)
 				continue
 			}
 		}
 
 		// Solve challenge
-		err := authSolver.solver.Solve(authSolver.authz)
+		notify.Notify(domain, challenge.StatusValidating, nil)
+
+		err := solve(ctx, authSolver.solver, authSolver.authz)
 		if err != nil {
 			failures[domain] = err
-			cleanUp(authSolver.solver, authSolver.authz)
+			cleanUp(ctx, authSolver.solver, authSolver.authz, notify)
 			continue
 		}
 
+		notify.Notify(domain, challenge.StatusValid, nil)
+
 		// Clean challenge
-		cleanUp(authSolver.solver, authSolver.authz)
+		cleanUp(ctx, authSolver.solver, authSolver.authz, notify)
 
 		if len(authSolvers)-1 > i {
 			solvr := authSolver.solver.(sequential)
@@ -127,23 +344,45 @@ func sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 	}
 }
 
-func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
+func parallelSolve(ctx context.Context, authSolvers []*selectedAuthSolver, failures obtainError, notify challenge.StatusFunc) {
+	// The changes of the providers supporting the transactions are applied in a single change set.
+	txs := newTransactions(authSolvers)
+
+	txs.begin(failures)
+
 	// For all valid preSolvers, first submit the challenges, so they have max time to propagate
 	for _, authSolver := range authSolvers {
 		authz := authSolver.authz
+		if failures[challenge.GetTargetedDomain(authz)] != nil {
+			continue
+		}
+
 		if solvr, ok := authSolver.solver.(preSolver); ok {
-			err := solvr.PreSolve(authz)
+			notify.Notify(challenge.GetTargetedDomain(authz), challenge.StatusPresenting, nil)
+
+			err := preSolve(ctx, solvr, authz)
 			if err != nil {
 				failures[challenge.GetTargetedDomain(authz)] = err
 			}
 		}
 	}
 
+	txs.commit(failures)
+
 	defer func() {
+		txs.beginCleanUp()
+
 		// Clean all created TXT records
 		for _, authSolver := range authSolvers {
-			cleanUp(authSolver.solver, authSolver.authz)
+			if txs.isDiscarded(challenge.GetTargetedDomain(authSolver.authz)) {
+				// the records were not created.
+				continue
+			}
+
+			cleanUp(ctx, authSolver.solver, authSolver.authz, notify)
 		}
+
+		txs.commitCleanUp()
 	}()
 
 	// Finally solve all challenges for real
@@ -155,17 +394,46 @@ func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 			continue
 		}
 
-		err := authSolver.solver.Solve(authz)
+		notify.Notify(domain, challenge.StatusValidating, nil)
+
+		err := solve(ctx, authSolver.solver, authz)
 		if err != nil {
 			failures[domain] = err
+			continue
 		}
+
+		notify.Notify(domain, challenge.StatusValid, nil)
 	}
 }
 
-func cleanUp(solvr solver, authz acme.Authorization) {
+func preSolve(ctx context.Context, solvr preSolver, authz acme.Authorization) error {
+	if s, ok := solvr.(preSolverContext); ok {
+		return s.PreSolveContext(ctx, authz)
+	}
+
+	return solvr.PreSolve(authz)
+}
+
+func solve(ctx context.Context, solvr solver, authz acme.Authorization) error {
+	if s, ok := solvr.(solverContext); ok {
+		return s.SolveContext(ctx, authz)
+	}
+
+	return solvr.Solve(authz)
+}
+
+func cleanUp(ctx context.Context, solvr solver, authz acme.Authorization, notify challenge.StatusFunc) {
 	if solvr, ok := solvr.(cleanup); ok {
 		domain := challenge.GetTargetedDomain(authz)
-		err := solvr.CleanUp(authz)
+		notify.Notify(domain, challenge.StatusCleaningUp, nil)
+
+		var err error
+		if s, ok := solvr.(cleanupContext); ok {
+			// the clean-up is not cancelled with the request: the records must be removed.
+			err = s.CleanUpContext(context.WithoutCancel(ctx), authz)
+		} else {
+			err = solvr.CleanUp(authz)
+		}
 		if err != nil {
 			log.Warnf("[%s] acme: cleaning up failed: %v ", domain, err)
 		}
@@ -1,6 +1,8 @@
 package resolver
 
 import (
+	"context"
+	"strings"
 	"time"
 
 	"github.com/pya789/lego/v4/acme"
@@ -42,3 +44,117 @@ func createStubAuthorizationHTTP01(domain, status string) acme.Authorization {
 		},
 	}
 }
+
+type transactionalSolverMock struct {
+	preSolverMock
+	provider *transactionalProviderMock
+}
+
+func (s *transactionalSolverMock) PreSolve(authorization acme.Authorization) error {
+	s.provider.calls = append(s.provider.calls, "present "+authorization.Identifier.Value)
+	return s.preSolverMock.PreSolve(authorization)
+}
+
+func (s *transactionalSolverMock) CleanUp(authorization acme.Authorization) error {
+	s.provider.calls = append(s.provider.calls, "cleanup "+authorization.Identifier.Value)
+	return s.preSolverMock.CleanUp(authorization)
+}
+
+func (s *transactionalSolverMock) Transaction() (challenge.Transactional, bool) {
+	return s.provider, true
+}
+
+type transactionalProviderMock struct {
+	commitErr error
+	calls     []string
+}
+
+func (p *transactionalProviderMock) Present(_, _, _ string) error { return nil }
+
+func (p *transactionalProviderMock) CleanUp(_, _, _ string) error { return nil }
+
+func (p *transactionalProviderMock) Begin() error {
+	p.calls = append(p.calls, "begin")
+	return nil
+}
+
+func (p *transactionalProviderMock) Commit() error {
+	p.calls = append(p.calls, "commit")
+	return p.commitErr
+}
+
+func (p *transactionalProviderMock) Rollback() {
+	p.calls = append(p.calls, "rollback")
+}
+
+type contextKey struct{}
+
+// contextSolverMock records the context values received by the solver.
+type contextSolverMock struct {
+	preSolverMock
+	calls []string
+}
+
+func (s *contextSolverMock) PreSolveContext(ctx context.Context, authorization acme.Authorization) error {
+	s.calls = append(s.calls, "presolve "+authorization.Identifier.Value+" "+ctx.Value(contextKey{}).(string))
+	return s.PreSolve(authorization)
+}
+
+func (s *contextSolverMock) SolveContext(ctx context.Context, authorization acme.Authorization) error {
+	s.calls = append(s.calls, "solve "+authorization.Identifier.Value+" "+ctx.Value(contextKey{}).(string))
+	return s.Solve(authorization)
+}
+
+func (s *contextSolverMock) CleanUpContext(ctx context.Context, authorization acme.Authorization) error {
+	s.calls = append(s.calls, "cleanup "+authorization.Identifier.Value+" "+ctx.Value(contextKey{}).(string))
+	return s.CleanUp(authorization)
+}
+
+// exclusiveSolverMock records the calls of the solver, the records of the authorizations are exclusive.
+type exclusiveSolverMock struct {
+	preSolverMock
+	calls []string
+}
+
+func (s *exclusiveSolverMock) PreSolve(authorization acme.Authorization) error {
+	s.calls = append(s.calls, "present "+challenge.GetTargetedDomain(authorization))
+	return s.preSolverMock.PreSolve(authorization)
+}
+
+func (s *exclusiveSolverMock) Solve(authorization acme.Authorization) error {
+	s.calls = append(s.calls, "solve "+challenge.GetTargetedDomain(authorization))
+	return s.preSolverMock.Solve(authorization)
+}
+
+func (s *exclusiveSolverMock) CleanUp(authorization acme.Authorization) error {
+	s.calls = append(s.calls, "cleanup "+challenge.GetTargetedDomain(authorization))
+	return s.preSolverMock.CleanUp(authorization)
+}
+
+func (s *exclusiveSolverMock) ExclusiveRecord(authorization acme.Authorization) (string, bool) {
+	return "_acme-challenge." + authorization.Identifier.Value + ".", true
+}
+
+// zoneSolverMock records the calls of the solver, the zone of an authorization is its parent domain.
+type zoneSolverMock struct {
+	exclusiveSolverMock
+
+	onSolve func()
+}
+
+func (s *zoneSolverMock) Solve(authorization acme.Authorization) error {
+	if s.onSolve != nil {
+		s.onSolve()
+	}
+
+	return s.exclusiveSolverMock.Solve(authorization)
+}
+
+func (s *zoneSolverMock) ExclusiveRecord(_ acme.Authorization) (string, bool) {
+	return "", false
+}
+
+func (s *zoneSolverMock) Zone(authorization acme.Authorization) (string, error) {
+	_, zone, _ := strings.Cut(authorization.Identifier.Value, ".")
+	return zone + ".", nil
+}
@@ -1,11 +1,25 @@
 package resolver
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/miekg/dns"
 	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/api"
 	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -115,3 +129,389 @@ func TestProber_Solve(t *testing.T) {
 		})
 	}
 }
+
+func TestProber_Solve_transaction(t *testing.T) {
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("acme.wtf", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("lego.wtf", acme.StatusProcessing),
+	}
+
+	testCases := []struct {
+		desc          string
+		solver        *transactionalSolverMock
+		expectedCalls []string
+		expectedError string
+	}{
+		{
+			desc: "success",
+			solver: &transactionalSolverMock{
+				provider: &transactionalProviderMock{},
+			},
+			expectedCalls: []string{
+				"begin", "present acme.wtf", "present lego.wtf", "commit",
+				"begin", "cleanup acme.wtf", "cleanup lego.wtf", "commit",
+			},
+		},
+		{
+			desc: "rollback on partial failure",
+			solver: &transactionalSolverMock{
+				preSolverMock: preSolverMock{
+					preSolve: map[string]error{"lego.wtf": errors.New("preSolve error lego.wtf")},
+				},
+				provider: &transactionalProviderMock{},
+			},
+			expectedCalls: []string{"begin", "present acme.wtf", "present lego.wtf", "rollback"},
+			expectedError: `error: one or more domains had a problem:
+[acme.wtf] [acme.wtf] acme: the change set was rolled back: failed changes for lego.wtf
+[lego.wtf] preSolve error lego.wtf
+`,
+		},
+		{
+			desc: "commit error",
+			solver: &transactionalSolverMock{
+				provider: &transactionalProviderMock{commitErr: errors.New("oops")},
+			},
+			expectedCalls: []string{"begin", "present acme.wtf", "present lego.wtf", "commit"},
+			expectedError: `error: one or more domains had a problem:
+[acme.wtf] [acme.wtf] acme: could not apply the change set: oops
+[lego.wtf] [lego.wtf] acme: could not apply the change set: oops
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			prober := &Prober{
+				solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.HTTP01: test.solver}},
+			}
+
+			err := prober.Solve(authz)
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedCalls, test.solver.provider.calls)
+		})
+	}
+}
+
+func TestProber_Solve_exclusiveRecord(t *testing.T) {
+	wildcard := createStubAuthorizationHTTP01("example.com", acme.StatusProcessing)
+	wildcard.Wildcard = true
+
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("example.com", acme.StatusProcessing),
+		wildcard,
+		createStubAuthorizationHTTP01("example.org", acme.StatusProcessing),
+	}
+
+	solvr := &exclusiveSolverMock{}
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.HTTP01: solvr}},
+	}
+
+	err := prober.Solve(authz)
+	require.NoError(t, err)
+
+	// the records of example.com and *.example.com are not presented at the same time.
+	expected := []string{
+		"present example.com", "present example.org",
+		"solve example.com", "solve example.org",
+		"cleanup example.com", "cleanup example.org",
+		"present *.example.com", "solve *.example.com", "cleanup *.example.com",
+	}
+
+	assert.Equal(t, expected, solvr.calls)
+}
+
+func TestProber_Solve_batches(t *testing.T) {
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("a.example.com", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("b.example.com", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("c.example.com", acme.StatusProcessing),
+	}
+
+	solvr := &exclusiveSolverMock{}
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.HTTP01: solvr}},
+	}
+
+	prober.solverManager.SetBatches(2, time.Millisecond)
+
+	err := prober.Solve(authz)
+	require.NoError(t, err)
+
+	// the records of a batch are cleaned up before the next batch is presented.
+	expected := []string{
+		"present a.example.com", "present b.example.com",
+		"solve a.example.com", "solve b.example.com",
+		"cleanup a.example.com", "cleanup b.example.com",
+		"present c.example.com", "solve c.example.com", "cleanup c.example.com",
+	}
+
+	assert.Equal(t, expected, solvr.calls)
+}
+
+func TestProber_Solve_batches_canceled(t *testing.T) {
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("a.example.com", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("b.example.com", acme.StatusProcessing),
+	}
+
+	solvr := &exclusiveSolverMock{}
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.HTTP01: solvr}},
+	}
+
+	prober.solverManager.SetBatches(1, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	t.Cleanup(cancel)
+
+	err := prober.SolveContext(ctx, authz, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the next batch is not presented.
+	expected := []string{"present a.example.com", "solve a.example.com", "cleanup a.example.com"}
+
+	assert.Equal(t, expected, solvr.calls)
+}
+
+func TestProber_Solve_zoneConcurrency(t *testing.T) {
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("a.example.com", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("b.example.com", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("c.example.com", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("a.example.org", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("b.example.org", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("a.example.net", acme.StatusProcessing),
+	}
+
+	solvr := &zoneSolverMock{}
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.HTTP01: solvr}},
+	}
+
+	prober.solverManager.SetZoneConcurrency(map[string]int{
+		"Example.com.": 2,
+		"example.net":  0,
+		AllZones:       1,
+	})
+
+	err := prober.Solve(authz)
+	require.NoError(t, err)
+
+	// the zones are still solved in parallel, within their limit.
+	expected := []string{
+		"present a.example.com", "present b.example.com", "present a.example.org", "present a.example.net",
+		"solve a.example.com", "solve b.example.com", "solve a.example.org", "solve a.example.net",
+		"cleanup a.example.com", "cleanup b.example.com", "cleanup a.example.org", "cleanup a.example.net",
+		"present c.example.com", "present b.example.org",
+		"solve c.example.com", "solve b.example.org",
+		"cleanup c.example.com", "cleanup b.example.org",
+	}
+
+	assert.Equal(t, expected, solvr.calls)
+}
+
+func TestProber_Solve_zoneConcurrency_canceled(t *testing.T) {
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("a.example.com", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("b.example.com", acme.StatusProcessing),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	solvr := &zoneSolverMock{}
+	// the deadline is over during the first round.
+	solvr.onSolve = cancel
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.HTTP01: solvr}},
+	}
+
+	prober.solverManager.SetZoneConcurrency(map[string]int{AllZones: 1})
+
+	err := prober.SolveContext(ctx, authz, nil)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the next round is not presented.
+	expected := []string{"present a.example.com", "solve a.example.com", "cleanup a.example.com"}
+
+	assert.Equal(t, expected, solvr.calls)
+}
+
+func TestProber_SolveWithStatus(t *testing.T) {
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("acme.wtf", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("lego.wtf", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("mydomain.wtf", acme.StatusValid),
+	}
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{
+			challenge.HTTP01: &preSolverMock{
+				solve: map[string]error{"lego.wtf": errors.New("solve error lego.wtf")},
+			},
+		}},
+	}
+
+	var statuses []string
+
+	err := prober.SolveWithStatus(authz, func(domain string, status challenge.Status, _ error) {
+		statuses = append(statuses, domain+" "+string(status))
+	})
+	require.Error(t, err)
+
+	expected := []string{
+		"mydomain.wtf skipped",
+		"acme.wtf presenting",
+		"lego.wtf presenting",
+		"acme.wtf validating",
+		"acme.wtf valid",
+		"lego.wtf validating",
+		"acme.wtf cleaning-up",
+		"lego.wtf cleaning-up",
+		"lego.wtf invalid",
+	}
+
+	assert.Equal(t, expected, statuses)
+}
+
+func TestProber_SolveContext(t *testing.T) {
+	authz := []acme.Authorization{
+		createStubAuthorizationHTTP01("acme.wtf", acme.StatusProcessing),
+	}
+
+	solvr := &contextSolverMock{}
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{
+			challenge.HTTP01: solvr,
+		}},
+	}
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+	err := prober.SolveContext(ctx, authz, nil)
+	require.NoError(t, err)
+
+	expected := []string{
+		"presolve acme.wtf value",
+		"solve acme.wtf value",
+		"cleanup acme.wtf value",
+	}
+
+	assert.Equal(t, expected, solvr.calls)
+}
+
+func TestProber_Solve_manualExport(t *testing.T) {
+	setupDNSServer(t)
+
+	backupStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = backupStdin })
+
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	require.NoError(t, err)
+
+	// only one confirmation for all the records of the order.
+	_, err = stdin.WriteString("\n")
+	require.NoError(t, err)
+
+	_, err = stdin.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	os.Stdin = stdin
+
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	provider, err := dns01.NewDNSProviderManualConfig(&dns01.ManualConfig{ExportDir: dir})
+	require.NoError(t, err)
+
+	var validated []string
+
+	validate := func(_ *api.Core, domain string, _ acme.Challenge) error {
+		validated = append(validated, domain)
+		return nil
+	}
+
+	chlg := dns01.NewChallenge(core, validate, provider, dns01.PropagationWait(time.Millisecond))
+
+	prober := &Prober{
+		solverManager: &SolverManager{solvers: map[challenge.Type]solver{challenge.DNS01: chlg}},
+	}
+
+	authz := []acme.Authorization{
+		createStubAuthorizationDNS01("a.example.com", "tokenA"),
+		createStubAuthorizationDNS01("b.example.com", "tokenB"),
+	}
+
+	err = prober.Solve(authz)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, validated)
+
+	export, err := os.ReadFile(filepath.Join(dir, "lego-challenges.zone"))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(export), "_acme-challenge.a.example.com.")
+	assert.Contains(t, string(export), "_acme-challenge.b.example.com.")
+}
+
+func createStubAuthorizationDNS01(domain, token string) acme.Authorization {
+	return acme.Authorization{
+		Status:     acme.StatusPending,
+		Identifier: acme.Identifier{Type: "dns", Value: domain},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: token}},
+	}
+}
+
+// setupDNSServer starts a DNS server resolving the zone example.com, used as recursive nameserver.
+func setupDNSServer(t *testing.T) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(req)
+
+			if req.Question[0].Qtype == dns.TypeSOA {
+				m.Answer = append(m.Answer, &dns.SOA{
+					Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 60},
+					Ns:  "ns.example.com.", Mbox: "admin.example.com.", Minttl: 60,
+				})
+			}
+
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	require.NoError(t, dns01.AddRecursiveNameservers([]string{conn.LocalAddr().String()})(&dns01.Challenge{}))
+
+	dns01.ClearFqdnCache()
+	t.Cleanup(dns01.ClearFqdnCache)
+}
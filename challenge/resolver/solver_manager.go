@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/pya789/lego/v4/acme"
 	"github.com/pya789/lego/v4/acme/api"
 	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/deviceattest01"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/challenge/http01"
 	"github.com/pya789/lego/v4/challenge/tlsalpn01"
@@ -23,9 +25,22 @@ func (a byType) Len() int           { return len(a) }
 func (a byType) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byType) Less(i, j int) bool { return a[i].Type > a[j].Type }
 
+// Interface for solvers able to detect a CDN serving a domain (like http-01).
+type cdnDetector interface {
+	DetectedCDN(domain string) string
+}
+
 type SolverManager struct {
-	core    *api.Core
-	solvers map[challenge.Type]solver
+	core        *api.Core
+	solvers     map[challenge.Type]solver
+	cdnFallback challenge.Type
+
+	// the authorizations solved in parallel are split in batches (see SetBatches).
+	batchSize     int
+	batchInterval time.Duration
+
+	// the maximum number of authorizations of a zone solved at the same time (see SetZoneConcurrency).
+	zoneConcurrency map[string]int
 }
 
 func NewSolversManager(core *api.Core) *SolverManager {
@@ -36,8 +51,8 @@ func NewSolversManager(core *api.Core) *SolverManager {
 }
 
 // SetHTTP01Provider specifies a custom provider p that can solve the given HTTP-01 challenge.
-func (c *SolverManager) SetHTTP01Provider(p challenge.Provider) error {
-	c.solvers[challenge.HTTP01] = http01.NewChallenge(c.core, validate, p)
+func (c *SolverManager) SetHTTP01Provider(p challenge.Provider, opts ...http01.ChallengeOption) error {
+	c.solvers[challenge.HTTP01] = http01.NewChallenge(c.core, validate, p, opts...)
 	return nil
 }
 
@@ -53,6 +68,47 @@ func (c *SolverManager) SetDNS01Provider(p challenge.Provider, opts ...dns01.Cha
 	return nil
 }
 
+// SetDeviceAttest01Attester specifies an attester that can solve the given DEVICE-ATTEST-01 challenge.
+func (c *SolverManager) SetDeviceAttest01Attester(attester deviceattest01.Attester) error {
+	c.solvers[challenge.DEVICEATTEST01] = deviceattest01.NewChallenge(c.core, validateWithPayload, attester)
+	return nil
+}
+
+// SetCDNFallback defines the challenge type used instead of HTTP-01
+// when the domain is detected as served by a CDN (see http01.DetectCDN).
+func (c *SolverManager) SetCDNFallback(chlgType challenge.Type) {
+	c.cdnFallback = chlgType
+}
+
+// SetBatches splits the authorizations solved in parallel in batches of size authorizations,
+// solved one after the other with a pause of interval between them (ex: orders with hundreds of domains).
+// The records of a batch are cleaned up before the next batch is presented:
+// the number of records at the same time, and the rate of the calls to the providers, are bounded.
+// The authorizations validated by the previous batches remain valid when a batch fails,
+// a new order for the same domains only solves the remaining authorizations (if the CA reuses the valid authorizations).
+// A size lower than 1 disables the batches.
+func (c *SolverManager) SetBatches(size int, interval time.Duration) {
+	c.batchSize = size
+	c.batchInterval = interval
+}
+
+// AllZones the key of the concurrency limit of the zones without their own limit (see SetZoneConcurrency).
+const AllZones = "*"
+
+// SetZoneConcurrency limits the number of authorizations of a zone solved at the same time, by zone (ex: example.com: 2),
+// so the challenges don't overwhelm the rate-limited DNS APIs: the other authorizations of the zone are solved in the next rounds,
+// while the authorizations of the other zones are still solved in parallel.
+// The limit of the key AllZones applies to the zones without their own limit, a limit lower than 1 disables the limit of a zone.
+// The zones are determined by the solvers able to find them (dns-01).
+// When the deadline of the request is over, the next rounds are not presented.
+func (c *SolverManager) SetZoneConcurrency(limits map[string]int) {
+	c.zoneConcurrency = make(map[string]int, len(limits))
+
+	for zone, limit := range limits {
+		c.zoneConcurrency[strings.ToLower(strings.TrimSuffix(zone, "."))] = limit
+	}
+}
+
 // Remove removes a challenge type from the available solvers.
 func (c *SolverManager) Remove(chlgType challenge.Type) {
 	delete(c.solvers, chlgType)
@@ -66,6 +122,10 @@ func (c *SolverManager) chooseSolver(authz acme.Authorization) solver {
 	domain := challenge.GetTargetedDomain(authz)
 	for _, chlg := range authz.Challenges {
 		if solvr, ok := c.solvers[challenge.Type(chlg.Type)]; ok {
+			if fallback := c.chooseCDNFallback(authz, solvr); fallback != nil {
+				return fallback
+			}
+
 			log.Infof("[%s] acme: use %s solver", domain, chlg.Type)
 			return solvr
 		}
@@ -75,8 +135,39 @@ func (c *SolverManager) chooseSolver(authz acme.Authorization) solver {
 	return nil
 }
 
+// chooseCDNFallback returns the fallback solver if the solver has detected a CDN serving the domain.
+func (c *SolverManager) chooseCDNFallback(authz acme.Authorization, solvr solver) solver {
+	detector, ok := solvr.(cdnDetector)
+	if !ok || c.cdnFallback == "" {
+		return nil
+	}
+
+	fallback, ok := c.solvers[c.cdnFallback]
+	if !ok {
+		return nil
+	}
+
+	if _, err := challenge.FindChallenge(c.cdnFallback, authz); err != nil {
+		return nil
+	}
+
+	name := detector.DetectedCDN(authz.Identifier.Value)
+	if name == "" {
+		return nil
+	}
+
+	log.Infof("[%s] acme: %s detected, use %s solver", challenge.GetTargetedDomain(authz), name, c.cdnFallback)
+
+	return fallback
+}
+
 func validate(core *api.Core, domain string, chlg acme.Challenge) error {
-	chlng, err := core.Challenges.New(chlg.URL)
+	// Challenge initiation is done by sending a JWS payload containing the trivial JSON object `{}`.
+	return validateWithPayload(core, domain, chlg, struct{}{})
+}
+
+func validateWithPayload(core *api.Core, domain string, chlg acme.Challenge, payload interface{}) error {
+	chlng, err := core.Challenges.NewWithPayload(chlg.URL, payload)
 	if err != nil {
 		return fmt.Errorf("failed to initiate challenge: %w", err)
 	}
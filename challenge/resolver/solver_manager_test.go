@@ -11,6 +11,7 @@ import (
 
 	"github.com/pya789/lego/v4/acme"
 	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/platform/tester"
 	"github.com/go-jose/go-jose/v4"
 	"github.com/stretchr/testify/assert"
@@ -31,6 +32,65 @@ func TestByType(t *testing.T) {
 	assert.Equal(t, expected, challenges)
 }
 
+type cdnSolverMock struct {
+	cdn string
+}
+
+func (s cdnSolverMock) Solve(_ acme.Authorization) error { return nil }
+
+func (s cdnSolverMock) DetectedCDN(_ string) string { return s.cdn }
+
+func TestSolverManager_chooseSolver_cdnFallback(t *testing.T) {
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: "http-01"}, {Type: "dns-01"}},
+	}
+
+	testCases := []struct {
+		desc     string
+		cdn      string
+		fallback challenge.Type
+		expected solver
+	}{
+		{
+			desc:     "CDN detected",
+			cdn:      "Cloudflare",
+			fallback: challenge.DNS01,
+			expected: cdnSolverMock{cdn: "dns"},
+		},
+		{
+			desc:     "no CDN",
+			fallback: challenge.DNS01,
+			expected: cdnSolverMock{},
+		},
+		{
+			desc:     "no fallback",
+			cdn:      "Cloudflare",
+			expected: cdnSolverMock{cdn: "Cloudflare"},
+		},
+		{
+			desc:     "fallback not offered",
+			cdn:      "Cloudflare",
+			fallback: challenge.TLSALPN01,
+			expected: cdnSolverMock{cdn: "Cloudflare"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewSolversManager(nil)
+			manager.solvers[challenge.HTTP01] = cdnSolverMock{cdn: test.cdn}
+			manager.solvers[challenge.DNS01] = cdnSolverMock{cdn: "dns"}
+			manager.solvers[challenge.TLSALPN01] = cdnSolverMock{cdn: "tls"}
+			manager.SetCDNFallback(test.fallback)
+
+			assert.Equal(t, test.expected, manager.chooseSolver(authz))
+		})
+	}
+}
+
 func TestValidate(t *testing.T) {
 	mux, apiURL := tester.SetupFakeAPI(t)
 
@@ -0,0 +1,162 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/log"
+)
+
+// Interface for challenges like dns, where the provider can apply all the changes of an order in a single change set.
+type transactional interface {
+	Transaction() (challenge.Transactional, bool)
+}
+
+// transaction the change set of a provider, and the domains staged in the change set.
+type transaction struct {
+	provider challenge.Transactional
+	domains  []string
+
+	// discarded is true if the changes were not applied (rollback or failure of the commit).
+	discarded bool
+	// cleaning is true if the clean-up changes are staged in the change set.
+	cleaning bool
+}
+
+// transactions the transactions of the providers supporting them, for one order.
+type transactions struct {
+	list     []*transaction
+	byDomain map[string]*transaction
+}
+
+func newTransactions(authSolvers []*selectedAuthSolver) *transactions {
+	txs := &transactions{byDomain: make(map[string]*transaction)}
+
+	for _, authSolver := range authSolvers {
+		solvr, ok := authSolver.solver.(transactional)
+		if !ok {
+			continue
+		}
+
+		provider, ok := solvr.Transaction()
+		if !ok {
+			continue
+		}
+
+		var tx *transaction
+		for _, t := range txs.list {
+			if t.provider == provider {
+				tx = t
+				break
+			}
+		}
+
+		if tx == nil {
+			tx = &transaction{provider: provider}
+			txs.list = append(txs.list, tx)
+		}
+
+		domain := challenge.GetTargetedDomain(authSolver.authz)
+
+		tx.domains = append(tx.domains, domain)
+		txs.byDomain[domain] = tx
+	}
+
+	return txs
+}
+
+// begin starts the transactions. If a transaction cannot be started, all its domains fail.
+func (t *transactions) begin(failures obtainError) {
+	for _, tx := range t.list {
+		err := tx.provider.Begin()
+		if err != nil {
+			tx.discarded = true
+
+			for _, domain := range tx.domains {
+				failures[domain] = fmt.Errorf("[%s] acme: could not start the change set: %w", domain, err)
+			}
+		}
+	}
+}
+
+// commit applies the transactions.
+// If a change of a transaction failed, the transaction is rolled back, and all its domains fail.
+func (t *transactions) commit(failures obtainError) {
+	for _, tx := range t.list {
+		if tx.discarded {
+			continue
+		}
+
+		var failed []string
+		for _, domain := range tx.domains {
+			if failures[domain] != nil {
+				failed = append(failed, domain)
+			}
+		}
+
+		if len(failed) > 0 {
+			tx.provider.Rollback()
+			tx.discarded = true
+
+			for _, domain := range tx.domains {
+				if failures[domain] == nil {
+					failures[domain] = fmt.Errorf("[%s] acme: the change set was rolled back: failed changes for %s",
+						domain, strings.Join(failed, ", "))
+				}
+			}
+
+			continue
+		}
+
+		err := tx.provider.Commit()
+		if err != nil {
+			tx.discarded = true
+
+			for _, domain := range tx.domains {
+				failures[domain] = fmt.Errorf("[%s] acme: could not apply the change set: %w", domain, err)
+			}
+		}
+	}
+}
+
+// isDiscarded returns true if the changes of the domain were not applied by its transaction.
+func (t *transactions) isDiscarded(domain string) bool {
+	tx, ok := t.byDomain[domain]
+
+	return ok && tx.discarded
+}
+
+// beginCleanUp starts the transactions of the clean-up.
+// If a transaction cannot be started, the records are removed one by one.
+func (t *transactions) beginCleanUp() {
+	for _, tx := range t.list {
+		if tx.discarded {
+			continue
+		}
+
+		err := tx.provider.Begin()
+		if err != nil {
+			log.Warnf("[%s] acme: could not start the clean-up change set: %v", strings.Join(tx.domains, ", "), err)
+			continue
+		}
+
+		tx.cleaning = true
+	}
+}
+
+// commitCleanUp applies the transactions of the clean-up.
+func (t *transactions) commitCleanUp() {
+	for _, tx := range t.list {
+		if !tx.cleaning {
+			continue
+		}
+
+		tx.cleaning = false
+
+		err := tx.provider.Commit()
+		if err != nil {
+			log.Warnf("[%s] acme: cleaning up failed: %v", strings.Join(tx.domains, ", "), err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package challenge
+
+// Status the status of the challenge of a domain, reported to follow the progress of a certificate request.
+type Status string
+
+const (
+	// StatusSkipped the authorization is already valid: the challenge is not solved.
+	StatusSkipped Status = "skipped"
+	// StatusPresenting the solution of the challenge is presented (ex: creation of the TXT record).
+	StatusPresenting Status = "presenting"
+	// StatusValidating the CA is asked to validate the challenge.
+	StatusValidating Status = "validating"
+	// StatusValid the challenge is validated by the CA.
+	StatusValid Status = "valid"
+	// StatusInvalid the challenge cannot be solved.
+	StatusInvalid Status = "invalid"
+	// StatusCleaningUp the solution of the challenge is removed (ex: removal of the TXT record).
+	StatusCleaningUp Status = "cleaning-up"
+)
+
+// StatusFunc receives the status updates of the challenges of the domains.
+// The error is only defined for StatusInvalid.
+type StatusFunc func(domain string, status Status, err error)
+
+// Notify calls the function, if defined.
+func (f StatusFunc) Notify(domain string, status Status, err error) {
+	if f != nil {
+		f(domain, status, err)
+	}
+}
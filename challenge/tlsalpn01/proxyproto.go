@@ -0,0 +1,181 @@
+package tlsalpn01
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt
+const (
+	proxyV1Prefix = "PROXY "
+	// proxyV1MaxLength the maximum length of a v1 header, including the CRLF.
+	proxyV1MaxLength = 107
+
+	proxyV2HeaderLength = 16
+	// proxyV2MaxLength the maximum length of the addresses and the TLVs of a v2 header.
+	proxyV2MaxLength = 4096
+)
+
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyConn a connection received through a proxy using the PROXY protocol.
+type proxyConn struct {
+	net.Conn
+
+	reader *bufio.Reader
+	// remote the address of the client, nil if the proxy did not send it (ex: health checks).
+	remote net.Addr
+	// header the raw PROXY protocol header, replayed to the passthrough address.
+	header []byte
+}
+
+func (c *proxyConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// RemoteAddr returns the address of the client, or the address of the proxy if the client is unknown.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// readProxyHeader reads the PROXY protocol header (v1 or v2) at the start of the connection.
+// The header is required: a connection without header is rejected.
+func readProxyHeader(conn net.Conn) (*proxyConn, error) {
+	reader := bufio.NewReaderSize(conn, proxyV1MaxLength)
+
+	// the shortest v1 header (PROXY UNKNOWN\r\n) is longer than the v2 signature.
+	prefix, err := reader.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	pc := &proxyConn{Conn: conn, reader: reader}
+
+	switch {
+	case bytes.Equal(prefix, proxyV2Signature):
+		pc.remote, pc.header, err = readProxyHeaderV2(reader)
+	case bytes.HasPrefix(prefix, []byte(proxyV1Prefix)):
+		pc.remote, pc.header, err = readProxyHeaderV1(reader)
+	default:
+		return nil, errors.New("missing header")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pc, nil
+}
+
+// readProxyHeaderV1 reads a human-readable header (ex: PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n).
+func readProxyHeaderV1(reader *bufio.Reader) (net.Addr, []byte, error) {
+	line, err := reader.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return nil, nil, errors.New("v1: header too long")
+		}
+
+		return nil, nil, fmt.Errorf("v1: read header: %w", err)
+	}
+
+	header := bytes.Clone(line)
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, errors.New("v1: invalid line ending")
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, header, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("v1: invalid header: %q", strings.TrimSpace(string(line)))
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil || (ip.To4() != nil) != (fields[1] == "TCP4") {
+		return nil, nil, fmt.Errorf("v1: invalid source address: %q", fields[2])
+	}
+
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, nil, fmt.Errorf("v1: invalid source port: %q", fields[4])
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, header, nil
+}
+
+// readProxyHeaderV2 reads a binary header.
+func readProxyHeaderV2(reader *bufio.Reader) (net.Addr, []byte, error) {
+	fixed := make([]byte, proxyV2HeaderLength)
+
+	_, err := io.ReadFull(reader, fixed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("v2: read header: %w", err)
+	}
+
+	version, command := fixed[12]>>4, fixed[12]&0x0F
+	if version != 2 {
+		return nil, nil, fmt.Errorf("v2: unsupported version: %d", version)
+	}
+
+	length := int(binary.BigEndian.Uint16(fixed[14:16]))
+	if length > proxyV2MaxLength {
+		return nil, nil, fmt.Errorf("v2: header too long: %d", length)
+	}
+
+	payload := make([]byte, length)
+
+	_, err = io.ReadFull(reader, payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("v2: read addresses: %w", err)
+	}
+
+	header := append(fixed, payload...)
+
+	switch command {
+	case 0x0:
+		// LOCAL: the connection was established by the proxy itself (ex: health checks).
+		return nil, header, nil
+	case 0x1:
+		// PROXY
+	default:
+		return nil, nil, fmt.Errorf("v2: unsupported command: %d", command)
+	}
+
+	// the high nibble is the address family, the low nibble is the transport protocol.
+	switch family := fixed[13] >> 4; family {
+	case 0x1: // AF_INET
+		if length < 12 {
+			return nil, nil, errors.New("v2: invalid IPv4 addresses")
+		}
+
+		port := binary.BigEndian.Uint16(payload[8:10])
+
+		return &net.TCPAddr{IP: net.IP(bytes.Clone(payload[0:4])), Port: int(port)}, header, nil
+	case 0x2: // AF_INET6
+		if length < 36 {
+			return nil, nil, errors.New("v2: invalid IPv6 addresses")
+		}
+
+		port := binary.BigEndian.Uint16(payload[32:34])
+
+		return &net.TCPAddr{IP: net.IP(bytes.Clone(payload[0:16])), Port: int(port)}, header, nil
+	default:
+		// AF_UNSPEC, AF_UNIX: the address of the proxy is used.
+		return nil, header, nil
+	}
+}
@@ -0,0 +1,138 @@
+package tlsalpn01
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func proxyV2Header(command, family byte, addresses []byte) []byte {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x20|command, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(addresses)))
+
+	return append(header, addresses...)
+}
+
+func Test_readProxyHeader(t *testing.T) {
+	ipv4 := []byte{192, 0, 2, 1, 192, 0, 2, 2, 0xDC, 0x04, 0x01, 0xBB}
+
+	ipv6 := make([]byte, 36)
+	copy(ipv6, net.ParseIP("2001:db8::1"))
+	copy(ipv6[16:], net.ParseIP("2001:db8::2"))
+	binary.BigEndian.PutUint16(ipv6[32:], 56324)
+	binary.BigEndian.PutUint16(ipv6[34:], 443)
+
+	testCases := []struct {
+		desc         string
+		data         []byte
+		expectedAddr string
+		expectedErr  string
+	}{
+		{
+			desc:         "v1 TCP4",
+			data:         []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n"),
+			expectedAddr: "192.0.2.1:56324",
+		},
+		{
+			desc:         "v1 TCP6",
+			data:         []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n"),
+			expectedAddr: "[2001:db8::1]:56324",
+		},
+		{
+			desc:         "v1 UNKNOWN",
+			data:         []byte("PROXY UNKNOWN\r\n"),
+			expectedAddr: "pipe",
+		},
+		{
+			desc:        "v1 invalid protocol",
+			data:        []byte("PROXY UDP4 192.0.2.1 192.0.2.2 56324 443\r\n"),
+			expectedErr: `v1: invalid header: "PROXY UDP4 192.0.2.1 192.0.2.2 56324 443"`,
+		},
+		{
+			desc:        "v1 family mismatch",
+			data:        []byte("PROXY TCP4 2001:db8::1 2001:db8::2 56324 443\r\n"),
+			expectedErr: `v1: invalid source address: "2001:db8::1"`,
+		},
+		{
+			desc:        "v1 invalid port",
+			data:        []byte("PROXY TCP4 192.0.2.1 192.0.2.2 99999 443\r\n"),
+			expectedErr: `v1: invalid source port: "99999"`,
+		},
+		{
+			desc:        "v1 invalid line ending",
+			data:        []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\n"),
+			expectedErr: "v1: invalid line ending",
+		},
+		{
+			desc:         "v2 IPv4",
+			data:         proxyV2Header(0x1, 0x11, ipv4),
+			expectedAddr: "192.0.2.1:56324",
+		},
+		{
+			desc:         "v2 IPv6",
+			data:         proxyV2Header(0x1, 0x21, ipv6),
+			expectedAddr: "[2001:db8::1]:56324",
+		},
+		{
+			desc:         "v2 IPv4 with TLVs",
+			data:         proxyV2Header(0x1, 0x11, append(ipv4, 0x04, 0x00, 0x01, 0x00)),
+			expectedAddr: "192.0.2.1:56324",
+		},
+		{
+			desc:         "v2 LOCAL",
+			data:         proxyV2Header(0x0, 0x00, nil),
+			expectedAddr: "pipe",
+		},
+		{
+			desc:        "v2 truncated addresses",
+			data:        proxyV2Header(0x1, 0x11, ipv4[:6]),
+			expectedErr: "v2: invalid IPv4 addresses",
+		},
+		{
+			desc:        "v2 unsupported command",
+			data:        proxyV2Header(0x2, 0x11, ipv4),
+			expectedErr: "v2: unsupported command: 2",
+		},
+		{
+			desc:        "missing header",
+			data:        []byte("\x16\x03\x01\x02\x00\x01\x00\x01\xfc\x03\x03\x00"),
+			expectedErr: "missing header",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, server := net.Pipe()
+			t.Cleanup(func() { _ = client.Close(); _ = server.Close() })
+
+			go func() {
+				_, _ = client.Write(append(test.data, "hello"...))
+			}()
+
+			conn, err := readProxyHeader(server)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedAddr, conn.RemoteAddr().String())
+			assert.Equal(t, test.data, conn.header)
+
+			// the data following the header is readable.
+			data := make([]byte, 5)
+			_, err = io.ReadFull(conn, data)
+			require.NoError(t, err)
+
+			assert.Equal(t, "hello", string(data))
+		})
+	}
+}
@@ -0,0 +1,24 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package tlsalpn01
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reusePort sets SO_REUSEPORT on the socket,
+// to bind the port while another service (also using SO_REUSEPORT) is listening on it.
+func reusePort(_, _ string, c syscall.RawConn) error {
+	var errOpt error
+
+	err := c.Control(func(fd uintptr) {
+		errOpt = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if err != nil {
+		return err
+	}
+
+	return errOpt
+}
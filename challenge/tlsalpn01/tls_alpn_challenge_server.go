@@ -1,12 +1,17 @@
 package tlsalpn01
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net"
-	"net/http"
-	"strings"
+	"os"
+	"slices"
+	"time"
 
 	"github.com/pya789/lego/v4/log"
 )
@@ -18,6 +23,8 @@ const (
 	// defaultTLSPort is the port that the ProviderServer will default to
 	// when no other port is provided.
 	defaultTLSPort = "443"
+
+	handshakeTimeout = 10 * time.Second
 )
 
 // ProviderServer implements ChallengeProvider for `TLS-ALPN-01` challenge.
@@ -27,6 +34,19 @@ type ProviderServer struct {
 	iface    string
 	port     string
 	listener net.Listener
+
+	socketPath string
+	socketMode fs.FileMode
+
+	reusePort     bool
+	inherited     net.Listener
+	passthrough   string
+	proxyProtocol bool
+	done          chan struct{}
+}
+
+type deadliner interface {
+	SetDeadline(t time.Time) error
 }
 
 // NewProviderServer creates a new ProviderServer on the selected interface and port.
@@ -36,10 +56,56 @@ func NewProviderServer(iface, port string) *ProviderServer {
 	return &ProviderServer{iface: iface, port: port}
 }
 
+// NewUnixProviderServer creates a new ProviderServer listening on a unix socket,
+// ex: behind a load balancer or a reverse proxy on the same host.
+// The socket is created with the file mode, and removed by CleanUp.
+func NewUnixProviderServer(socketPath string, mode fs.FileMode) *ProviderServer {
+	return &ProviderServer{socketPath: socketPath, socketMode: mode}
+}
+
 func (s *ProviderServer) GetAddress() string {
+	if s.socketPath != "" {
+		return s.socketPath
+	}
+
 	return net.JoinHostPort(s.iface, s.port)
 }
 
+// SetReusePort binds the port with the SO_REUSEPORT option,
+// so the server can coexist with a running service on the same port (the service must also use SO_REUSEPORT).
+// The kernel distributes the connections between the sockets:
+// use it with SetPassthrough to forward the connections of the service.
+func (s *ProviderServer) SetReusePort(enabled bool) {
+	s.reusePort = enabled
+}
+
+// SetListener uses an existing listener instead of binding the port,
+// ex: a socket handed off by the running service (file descriptor passing, see net.FileListener).
+// The listener is not closed by CleanUp, and must support deadlines (ex: *net.TCPListener).
+func (s *ProviderServer) SetListener(listener net.Listener) error {
+	if _, ok := listener.(deadliner); !ok {
+		return fmt.Errorf("the listener %T does not support deadlines", listener)
+	}
+
+	s.inherited = listener
+
+	return nil
+}
+
+// SetPassthrough forwards the connections not negotiating the `acme-tls/1` protocol to the address,
+// without terminating TLS, so the running service (ex: moved to another port) is still reachable during the validation.
+func (s *ProviderServer) SetPassthrough(address string) {
+	s.passthrough = address
+}
+
+// SetProxyProtocol requires a PROXY protocol header (v1 or v2) at the start of the connections,
+// so the server can run behind a L4 load balancer preserving the addresses of the clients (ex: HAProxy with send-proxy).
+// The connections without header are rejected.
+// The header is replayed to the passthrough address: the running service must also accept the PROXY protocol.
+func (s *ProviderServer) SetProxyProtocol(enabled bool) {
+	s.proxyProtocol = enabled
+}
+
 // Present generates a certificate with an SHA-256 digest of the keyAuth provided
 // as the acmeValidation-v1 extension value to conform to the ACME-TLS-ALPN spec.
 func (s *ProviderServer) Present(domain, token, keyAuth string) error {
@@ -64,19 +130,14 @@ func (s *ProviderServer) Present(domain, token, keyAuth string) error {
 	// https://www.rfc-editor.org/rfc/rfc8737.html#section-6.2
 	tlsConf.NextProtos = []string{ACMETLS1Protocol}
 
-	// Create the listener with the created tls.Config.
-	s.listener, err = tls.Listen("tcp", s.GetAddress(), tlsConf)
+	s.listener, err = s.listen()
 	if err != nil {
 		return fmt.Errorf("could not start HTTPS server for challenge: %w", err)
 	}
 
-	// Shut the server down when we're finished.
-	go func() {
-		err := http.Serve(s.listener, nil)
-		if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
-			log.Println(err)
-		}
-	}()
+	s.done = make(chan struct{})
+
+	go s.serve(tlsConf)
 
 	return nil
 }
@@ -87,10 +148,185 @@ func (s *ProviderServer) CleanUp(domain, token, keyAuth string) error {
 		return nil
 	}
 
-	// Server was created, close it.
-	if err := s.listener.Close(); err != nil && errors.Is(err, http.ErrServerClosed) {
+	if s.inherited != nil {
+		// The inherited listener is kept open for the next challenges, the deadline stops the server.
+		if err := s.inherited.(deadliner).SetDeadline(time.Now()); err != nil {
+			return err
+		}
+	} else if err := s.listener.Close(); err != nil {
+		return err
+	}
+
+	<-s.done
+
+	s.listener = nil
+
+	if s.inherited != nil {
+		return s.inherited.(deadliner).SetDeadline(time.Time{})
+	}
+
+	return nil
+}
+
+func (s *ProviderServer) listen() (net.Listener, error) {
+	if s.inherited != nil {
+		return s.inherited, nil
+	}
+
+	if s.socketPath != "" {
+		listener, err := net.Listen("unix", s.socketPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = os.Chmod(s.socketPath, s.socketMode); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("chmod %s: %w", s.socketPath, err)
+		}
+
+		return listener, nil
+	}
+
+	lc := net.ListenConfig{}
+	if s.reusePort {
+		lc.Control = reusePort
+	}
+
+	return lc.Listen(context.Background(), "tcp", s.GetAddress())
+}
+
+// serve answers the handshakes of the `acme-tls/1` connections,
+// and forwards the other connections to the passthrough address (if defined).
+func (s *ProviderServer) serve(tlsConf *tls.Config) {
+	defer close(s.done)
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) && !errors.Is(err, os.ErrDeadlineExceeded) {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		go func() {
+			defer func() { _ = conn.Close() }()
+
+			err := s.handle(conn, tlsConf)
+			if err != nil {
+				log.Warnf("tls-alpn-01: %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (s *ProviderServer) handle(conn net.Conn, tlsConf *tls.Config) error {
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+
+	var header []byte
+
+	if s.proxyProtocol {
+		pc, err := readProxyHeader(conn)
+		if err != nil {
+			return fmt.Errorf("proxy protocol: %w", err)
+		}
+
+		log.Debugf("tls-alpn-01: connection of %s proxied by %s", pc.RemoteAddr(), conn.RemoteAddr())
+
+		conn, header = pc, pc.header
+	}
+
+	if s.passthrough == "" {
+		// The validation only needs the handshake.
+		return tls.Server(conn, tlsConf).Handshake()
+	}
+
+	hello, peeked, err := peekClientHello(conn)
+	if err != nil {
 		return err
 	}
 
+	replay := &replayConn{Conn: conn, reader: io.MultiReader(bytes.NewReader(peeked), conn)}
+
+	if slices.Contains(hello.SupportedProtos, ACMETLS1Protocol) {
+		return tls.Server(replay, tlsConf).Handshake()
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	backend, err := net.DialTimeout("tcp", s.passthrough, handshakeTimeout)
+	if err != nil {
+		return fmt.Errorf("passthrough: %w", err)
+	}
+
+	defer func() { _ = backend.Close() }()
+
+	if len(header) > 0 {
+		_, err = backend.Write(header)
+		if err != nil {
+			return fmt.Errorf("passthrough: %w", err)
+		}
+	}
+
+	go func() {
+		_, _ = io.Copy(conn, backend)
+		_ = conn.Close()
+	}()
+
+	_, err = io.Copy(backend, replay)
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("passthrough: %w", err)
+	}
+
 	return nil
 }
+
+var errHelloRead = errors.New("client hello read")
+
+// peekClientHello reads the TLS ClientHello of the connection without answering it,
+// and returns the bytes read, to replay them to the handler of the connection.
+func peekClientHello(conn net.Conn) (*tls.ClientHelloInfo, []byte, error) {
+	var (
+		hello  *tls.ClientHelloInfo
+		peeked bytes.Buffer
+	)
+
+	err := tls.Server(readOnlyConn{Conn: conn, reader: io.TeeReader(conn, &peeked)}, &tls.Config{
+		GetConfigForClient: func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+			hello = info
+			return nil, errHelloRead
+		},
+	}).Handshake()
+
+	if hello == nil {
+		return nil, nil, fmt.Errorf("read client hello: %w", err)
+	}
+
+	return hello, peeked.Bytes(), nil
+}
+
+// readOnlyConn a connection discarding the writes (the TLS alerts), used to read the ClientHello.
+type readOnlyConn struct {
+	net.Conn
+
+	reader io.Reader
+}
+
+func (c readOnlyConn) Read(p []byte) (int, error)         { return c.reader.Read(p) }
+func (c readOnlyConn) Write(_ []byte) (int, error)        { return 0, io.ErrClosedPipe }
+func (c readOnlyConn) Close() error                       { return nil }
+func (c readOnlyConn) SetDeadline(_ time.Time) error      { return nil }
+func (c readOnlyConn) SetReadDeadline(_ time.Time) error  { return nil }
+func (c readOnlyConn) SetWriteDeadline(_ time.Time) error { return nil }
+
+// replayConn a connection replaying the bytes already read.
+type replayConn struct {
+	net.Conn
+
+	reader io.Reader
+}
+
+func (c *replayConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
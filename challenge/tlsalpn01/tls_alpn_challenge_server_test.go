@@ -0,0 +1,142 @@
+package tlsalpn01
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderServer_passthrough(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte("service"))
+	}))
+	t.Cleanup(backend.Close)
+
+	srv := NewProviderServer("127.0.0.1", "0")
+	srv.SetPassthrough(backend.Listener.Addr().String())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	require.NoError(t, srv.SetListener(listener))
+
+	require.NoError(t, srv.Present("localhost", "token", "keyAuth"))
+
+	// acme-tls/1 connection: answered by the challenge server.
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+		ServerName:         "localhost",
+		NextProtos:         []string{ACMETLS1Protocol},
+		InsecureSkipVerify: true,
+	})
+	require.NoError(t, err)
+
+	state := conn.ConnectionState()
+	assert.Equal(t, ACMETLS1Protocol, state.NegotiatedProtocol)
+	require.Len(t, state.PeerCertificates, 1)
+	assert.Equal(t, []string{"localhost"}, state.PeerCertificates[0].DNSNames)
+
+	_ = conn.Close()
+
+	// other connections: forwarded to the service.
+	client := backend.Client()
+	resp, err := client.Get("https://" + listener.Addr().String())
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, srv.CleanUp("localhost", "token", "keyAuth"))
+
+	// the inherited listener is kept for the next challenge.
+	require.NoError(t, srv.Present("localhost", "token", "keyAuth"))
+	require.NoError(t, srv.CleanUp("localhost", "token", "keyAuth"))
+}
+
+func TestProviderServer_SetReusePort(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_REUSEPORT behavior is tested on Linux only")
+	}
+
+	lc := net.ListenConfig{Control: reusePort}
+
+	service, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = service.Close() })
+
+	_, port, err := net.SplitHostPort(service.Addr().String())
+	require.NoError(t, err)
+
+	srv := NewProviderServer("127.0.0.1", port)
+	require.Error(t, srv.Present("localhost", "token", "keyAuth"))
+
+	srv.SetReusePort(true)
+	require.NoError(t, srv.Present("localhost", "token", "keyAuth"))
+	require.NoError(t, srv.CleanUp("localhost", "token", "keyAuth"))
+}
+
+func TestProviderServer_SetListener_unsupported(t *testing.T) {
+	srv := NewProviderServer("", "")
+
+	err := srv.SetListener(struct{ net.Listener }{})
+	require.Error(t, err)
+}
+
+func TestProviderServer_SetProxyProtocol(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "tls-alpn.sock")
+
+	srv := NewUnixProviderServer(socketPath, 0o600)
+	srv.SetProxyProtocol(true)
+
+	require.NoError(t, srv.Present("localhost", "token", "keyAuth"))
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	dial := func(header string) (*tls.Conn, error) {
+		raw, err := net.Dial("unix", socketPath)
+		if err != nil {
+			return nil, err
+		}
+
+		t.Cleanup(func() { _ = raw.Close() })
+
+		_, err = raw.Write([]byte(header))
+		if err != nil {
+			return nil, err
+		}
+
+		conn := tls.Client(raw, &tls.Config{
+			ServerName:         "localhost",
+			NextProtos:         []string{ACMETLS1Protocol},
+			InsecureSkipVerify: true,
+		})
+
+		return conn, conn.Handshake()
+	}
+
+	conn, err := dial("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n")
+	require.NoError(t, err)
+
+	assert.Equal(t, ACMETLS1Protocol, conn.ConnectionState().NegotiatedProtocol)
+
+	// the connections without header are rejected.
+	_, err = dial("")
+	require.Error(t, err)
+
+	require.NoError(t, srv.CleanUp("localhost", "token", "keyAuth"))
+
+	_, err = os.Stat(socketPath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
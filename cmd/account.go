@@ -10,6 +10,7 @@ import (
 type Account struct {
 	Email        string                 `json:"email"`
 	Registration *registration.Resource `json:"registration"`
+	Defaults     *registration.Defaults `json:"defaults,omitempty"`
 	key          crypto.PrivateKey
 }
 
@@ -29,3 +30,8 @@ func (a *Account) GetPrivateKey() crypto.PrivateKey {
 func (a *Account) GetRegistration() *registration.Resource {
 	return a.Registration
 }
+
+// GetDefaults returns the default settings of the account.
+func (a *Account) GetDefaults() *registration.Defaults {
+	return a.Defaults
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/storage"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Account bundle format.
+const (
+	accountBundleFormat  = "lego-account-bundle"
+	accountBundleVersion = 1
+)
+
+// accountBundle an encrypted bundle of accounts (account files and private keys).
+// The payload is encrypted with AES-256-GCM, the key is derived from a passphrase with scrypt.
+// The header (format, version, KDF, cipher) is authenticated as additional data:
+// a modification of the header or of the ciphertext is detected at the decryption.
+type accountBundle struct {
+	accountBundleHeader
+
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type accountBundleHeader struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	KDF     bundleKDF `json:"kdf"`
+	Cipher  string    `json:"cipher"`
+}
+
+type bundleKDF struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// accountBundlePayload the content of a bundle.
+type accountBundlePayload struct {
+	Created  time.Time            `json:"created"`
+	Accounts []accountBundleEntry `json:"accounts"`
+}
+
+// accountBundleEntry an account of a bundle.
+type accountBundleEntry struct {
+	// Server the directory of the CA server in the storage (ex: acme-v02.api.letsencrypt.org).
+	Server string `json:"server"`
+	// UserID the name of the account ("account" option, or "email" option).
+	UserID string `json:"userID"`
+	// Account the account file (account.json).
+	Account json.RawMessage `json:"account"`
+	// PrivateKey the private key of the account (PEM).
+	PrivateKey string `json:"privateKey"`
+}
+
+func (e accountBundleEntry) rootUserKey() string {
+	return path.Join(baseAccountsRootFolderName, e.Server, e.UserID)
+}
+
+func (e accountBundleEntry) privateKeyKey() string {
+	return path.Join(e.rootUserKey(), baseKeysFolderName, e.UserID+".key")
+}
+
+// validate checks the entry: a corrupted or malicious bundle must not write outside the accounts directory.
+func (e accountBundleEntry) validate() error {
+	if e.Server == "" || strings.ContainsAny(e.Server, `/\`) || strings.Contains(e.Server, "..") {
+		return fmt.Errorf("invalid server directory %q", e.Server)
+	}
+
+	err := validateAccountName(e.UserID)
+	if err != nil {
+		return err
+	}
+
+	var account Account
+
+	err = json.Unmarshal(e.Account, &account)
+	if err != nil {
+		return fmt.Errorf("%s: invalid account file: %w", e.UserID, err)
+	}
+
+	_, err = parsePrivateKey([]byte(e.PrivateKey))
+	if err != nil {
+		return fmt.Errorf("%s: invalid private key: %w", e.UserID, err)
+	}
+
+	return nil
+}
+
+// sealAccountBundle encrypts the payload with the passphrase.
+func sealAccountBundle(payload *accountBundlePayload, passphrase string) ([]byte, error) {
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &accountBundle{
+		accountBundleHeader: accountBundleHeader{
+			Format:  accountBundleFormat,
+			Version: accountBundleVersion,
+			KDF:     bundleKDF{Name: "scrypt", Salt: make([]byte, 16), N: 1 << 15, R: 8, P: 1},
+			Cipher:  "AES-256-GCM",
+		},
+	}
+
+	_, err = rand.Read(bundle.KDF.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := bundle.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle.Nonce = make([]byte, aead.NonceSize())
+
+	_, err = rand.Read(bundle.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	additionalData, err := json.Marshal(bundle.accountBundleHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle.Ciphertext = aead.Seal(nil, bundle.Nonce, plaintext, additionalData)
+
+	return json.MarshalIndent(bundle, "", "\t")
+}
+
+// openAccountBundle decrypts a bundle with the passphrase, and validates the accounts.
+func openAccountBundle(raw []byte, passphrase string) (*accountBundlePayload, error) {
+	var bundle accountBundle
+
+	err := json.Unmarshal(raw, &bundle)
+	if err != nil || bundle.Format != accountBundleFormat {
+		return nil, errors.New("not an account bundle")
+	}
+
+	if bundle.Version != accountBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d (supported: %d)", bundle.Version, accountBundleVersion)
+	}
+
+	if bundle.KDF.Name != "scrypt" || bundle.Cipher != "AES-256-GCM" {
+		return nil, fmt.Errorf("unsupported bundle encryption: %s, %s", bundle.KDF.Name, bundle.Cipher)
+	}
+
+	aead, err := bundle.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bundle.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid bundle nonce")
+	}
+
+	additionalData, err := json.Marshal(bundle.accountBundleHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, bundle.Nonce, bundle.Ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.New("invalid passphrase, or the bundle has been modified")
+	}
+
+	var payload accountBundlePayload
+
+	err = json.Unmarshal(plaintext, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle content: %w", err)
+	}
+
+	for _, entry := range payload.Accounts {
+		err = entry.validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle content: %w", err)
+		}
+	}
+
+	return &payload, nil
+}
+
+func (b *accountBundle) aead(passphrase string) (cipher.AEAD, error) {
+	// The parameters are bounded: they are read from the bundle.
+	if b.KDF.N < 1<<14 || b.KDF.N > 1<<20 || b.KDF.R < 1 || b.KDF.R > 32 || b.KDF.P < 1 || b.KDF.P > 16 || len(b.KDF.Salt) < 16 {
+		return nil, errors.New("invalid bundle KDF parameters")
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), b.KDF.Salt, b.KDF.N, b.KDF.R, b.KDF.P, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// collectAccounts reads the accounts of the account files (keys of the storage) for a bundle.
+func collectAccounts(ctx context.Context, store storage.Store, accountFiles []string) (*accountBundlePayload, error) {
+	payload := &accountBundlePayload{Created: time.Now().UTC()}
+
+	for _, accountFile := range accountFiles {
+		// accounts/<server>/<userID>/account.json
+		parts := strings.Split(accountFile, "/")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("unexpected account file: %s", accountFile)
+		}
+
+		entry := accountBundleEntry{Server: parts[1], UserID: parts[2]}
+
+		account, err := store.Get(ctx, accountFile)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Account = account
+
+		privateKey, err := store.Get(ctx, entry.privateKeyKey())
+		if err != nil {
+			return nil, fmt.Errorf("%s: private key: %w", entry.UserID, err)
+		}
+
+		entry.PrivateKey = string(privateKey)
+
+		err = entry.validate()
+		if err != nil {
+			return nil, err
+		}
+
+		payload.Accounts = append(payload.Accounts, entry)
+	}
+
+	return payload, nil
+}
+
+// restoreAccounts writes the accounts of a bundle in the storage.
+// The existing accounts are not replaced, unless force: all the accounts are checked before writing the first account.
+func restoreAccounts(ctx context.Context, store storage.Store, payload *accountBundlePayload, force bool) error {
+	if !force {
+		var existing []string
+
+		for _, entry := range payload.Accounts {
+			_, err := store.Get(ctx, path.Join(entry.rootUserKey(), accountFileName))
+			if err == nil {
+				existing = append(existing, path.Join(entry.Server, entry.UserID))
+			} else if !errors.Is(err, storage.ErrNotExist) {
+				return err
+			}
+		}
+
+		if len(existing) > 0 {
+			return fmt.Errorf("the accounts already exist (use --force to replace them): %s", strings.Join(existing, ", "))
+		}
+	}
+
+	for _, entry := range payload.Accounts {
+		// The key first: an account file without key is not usable.
+		err := store.Put(ctx, entry.privateKeyKey(), []byte(entry.PrivateKey))
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.UserID, err)
+		}
+
+		err = store.Put(ctx, path.Join(entry.rootUserKey(), accountFileName), entry.Account)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.UserID, err)
+		}
+	}
+
+	return nil
+}
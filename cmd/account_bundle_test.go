@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"path"
+	"testing"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func putTestAccount(t *testing.T, store storage.Store, server, userID string) {
+	t.Helper()
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	rootUserKey := path.Join(baseAccountsRootFolderName, server, userID)
+
+	err = store.Put(context.Background(), path.Join(rootUserKey, baseKeysFolderName, userID+".key"), pem.EncodeToMemory(certcrypto.PEMBlock(privateKey)))
+	require.NoError(t, err)
+
+	err = store.Put(context.Background(), path.Join(rootUserKey, accountFileName), []byte(`{"email":"`+userID+`"}`))
+	require.NoError(t, err)
+}
+
+func TestAccountBundle(t *testing.T) {
+	source := storage.NewFileSystem(t.TempDir())
+
+	putTestAccount(t, source, "acme-v02.api.letsencrypt.org", "alice@example.com")
+	putTestAccount(t, source, "localhost_14000", "bob")
+
+	accountFiles, err := listAccountFiles(source)
+	require.NoError(t, err)
+	require.Len(t, accountFiles, 2)
+
+	payload, err := collectAccounts(context.Background(), source, accountFiles)
+	require.NoError(t, err)
+
+	bundle, err := sealAccountBundle(payload, "secret")
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(bundle), "PRIVATE KEY")
+
+	opened, err := openAccountBundle(bundle, "secret")
+	require.NoError(t, err)
+
+	target := storage.NewFileSystem(t.TempDir())
+
+	err = restoreAccounts(context.Background(), target, opened, false)
+	require.NoError(t, err)
+
+	for _, accountFile := range accountFiles {
+		for _, key := range []string{accountFile, path.Join(path.Dir(accountFile), baseKeysFolderName, path.Base(path.Dir(accountFile))+".key")} {
+			expected, errG := source.Get(context.Background(), key)
+			require.NoError(t, errG)
+
+			actual, errG := target.Get(context.Background(), key)
+			require.NoError(t, errG)
+
+			assert.Equal(t, expected, actual)
+		}
+	}
+
+	// the existing accounts are not replaced.
+	err = restoreAccounts(context.Background(), target, opened, false)
+	require.EqualError(t, err, "the accounts already exist (use --force to replace them): acme-v02.api.letsencrypt.org/alice@example.com, localhost_14000/bob")
+
+	err = restoreAccounts(context.Background(), target, opened, true)
+	require.NoError(t, err)
+}
+
+func Test_openAccountBundle_errors(t *testing.T) {
+	store := storage.NewFileSystem(t.TempDir())
+
+	putTestAccount(t, store, "localhost_14000", "bob")
+
+	payload, err := collectAccounts(context.Background(), store, []string{"accounts/localhost_14000/bob/account.json"})
+	require.NoError(t, err)
+
+	bundle, err := sealAccountBundle(payload, "secret")
+	require.NoError(t, err)
+
+	modify := func(fn func(b map[string]any)) []byte {
+		var b map[string]any
+		require.NoError(t, json.Unmarshal(bundle, &b))
+
+		fn(b)
+
+		raw, errM := json.Marshal(b)
+		require.NoError(t, errM)
+
+		return raw
+	}
+
+	malicious := &accountBundlePayload{Accounts: []accountBundleEntry{payload.Accounts[0]}}
+	malicious.Accounts[0].Server = ".."
+
+	maliciousBundle, err := sealAccountBundle(malicious, "secret")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc       string
+		bundle     []byte
+		passphrase string
+		expected   string
+	}{
+		{
+			desc:       "invalid passphrase",
+			bundle:     bundle,
+			passphrase: "invalid",
+			expected:   "invalid passphrase, or the bundle has been modified",
+		},
+		{
+			desc:       "modified ciphertext",
+			bundle:     modify(func(b map[string]any) { b["ciphertext"] = "AAAA" + b["ciphertext"].(string)[4:] }),
+			passphrase: "secret",
+			expected:   "invalid passphrase, or the bundle has been modified",
+		},
+		{
+			desc:       "modified header",
+			bundle:     modify(func(b map[string]any) { b["kdf"].(map[string]any)["p"] = 2 }),
+			passphrase: "secret",
+			expected:   "invalid passphrase, or the bundle has been modified",
+		},
+		{
+			desc:       "unsupported version",
+			bundle:     modify(func(b map[string]any) { b["version"] = 2 }),
+			passphrase: "secret",
+			expected:   "unsupported bundle version 2 (supported: 1)",
+		},
+		{
+			desc:       "not a bundle",
+			bundle:     []byte(`{"email":"bob"}`),
+			passphrase: "secret",
+			expected:   "not an account bundle",
+		},
+		{
+			desc:       "invalid server directory",
+			bundle:     maliciousBundle,
+			passphrase: "secret",
+			expected:   `invalid bundle content: invalid server directory ".."`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := openAccountBundle(test.bundle, test.passphrase)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
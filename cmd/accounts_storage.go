@@ -1,20 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"crypto"
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"net/url"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
 
 	"github.com/pya789/lego/v4/certcrypto"
 	"github.com/pya789/lego/v4/lego"
 	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/registration"
+	"github.com/pya789/lego/v4/storage"
 	"github.com/urfave/cli/v2"
 )
 
@@ -25,6 +27,7 @@ const (
 )
 
 // AccountsStorage A storage for account data.
+// The paths are the keys of the storage if the storage is not the filesystem ("storage" option).
 //
 // rootPath:
 //
@@ -35,7 +38,7 @@ const (
 // rootUserPath:
 //
 //	./.lego/accounts/localhost_14000/hubert@hubert.com/
-//	     │      │             │             └── userID ("email" option)
+//	     │      │             │             └── userID ("account" option, or "email" option)
 //	     │      │             └── CA server ("server" option)
 //	     │      └── root accounts directory
 //	     └── "path" option
@@ -44,7 +47,7 @@ const (
 //
 //	./.lego/accounts/localhost_14000/hubert@hubert.com/keys/
 //	     │      │             │             │           └── root keys directory
-//	     │      │             │             └── userID ("email" option)
+//	     │      │             │             └── userID ("account" option, or "email" option)
 //	     │      │             └── CA server ("server" option)
 //	     │      └── root accounts directory
 //	     └── "path" option
@@ -53,47 +56,64 @@ const (
 //
 //	./.lego/accounts/localhost_14000/hubert@hubert.com/account.json
 //	     │      │             │             │             └── account file
-//	     │      │             │             └── userID ("email" option)
+//	     │      │             │             └── userID ("account" option, or "email" option)
 //	     │      │             └── CA server ("server" option)
 //	     │      └── root accounts directory
 //	     └── "path" option
 type AccountsStorage struct {
-	userID          string
-	rootPath        string
-	rootUserPath    string
-	keysPath        string
-	accountFilePath string
-	ctx             *cli.Context
+	userID         string
+	email          string
+	store          storage.Store
+	rootUserKey    string
+	keysKey        string
+	accountFileKey string
+	ctx            *cli.Context
 }
 
 // NewAccountsStorage Creates a new AccountsStorage.
 func NewAccountsStorage(ctx *cli.Context) *AccountsStorage {
-	// TODO: move to account struct? Currently MUST pass email.
-	email := getEmail(ctx)
+	email := ctx.String("email")
+
+	// The account name allows to have several accounts with the same email on the same CA.
+	userID := ctx.String("account")
+	if userID == "" {
+		userID = getEmail(ctx)
+	} else if err := validateAccountName(userID); err != nil {
+		fatalConfig(err)
+	}
 
 	serverURL, err := url.Parse(ctx.String("server"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	rootPath := filepath.Join(ctx.String("path"), baseAccountsRootFolderName)
-	serverPath := strings.NewReplacer(":", "_", "/", string(os.PathSeparator)).Replace(serverURL.Host)
-	accountsPath := filepath.Join(rootPath, serverPath)
-	rootUserPath := filepath.Join(accountsPath, email)
+	serverPath := strings.NewReplacer(":", "_").Replace(serverURL.Host)
+	rootUserKey := path.Join(baseAccountsRootFolderName, serverPath, userID)
 
 	return &AccountsStorage{
-		userID:          email,
-		rootPath:        rootPath,
-		rootUserPath:    rootUserPath,
-		keysPath:        filepath.Join(rootUserPath, baseKeysFolderName),
-		accountFilePath: filepath.Join(rootUserPath, accountFileName),
-		ctx:             ctx,
+		userID:         userID,
+		email:          email,
+		store:          getStore(ctx),
+		rootUserKey:    rootUserKey,
+		keysKey:        path.Join(rootUserKey, baseKeysFolderName),
+		accountFileKey: path.Join(rootUserKey, accountFileName),
+		ctx:            ctx,
 	}
 }
 
+// validateAccountName checks that the account name is usable as the name of a directory of the storage:
+// the name must not contain path separators, or be a relative reference ("." or "..").
+func validateAccountName(name string) error {
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") || name == "." {
+		return fmt.Errorf("invalid account name %q: the name must not contain path separators or '..'", name)
+	}
+
+	return nil
+}
+
 func (s *AccountsStorage) ExistsAccountFilePath() bool {
-	accountFile := filepath.Join(s.rootUserPath, accountFileName)
-	if _, err := os.Stat(accountFile); os.IsNotExist(err) {
+	_, err := s.store.Get(context.Background(), s.accountFileKey)
+	if errors.Is(err, storage.ErrNotExist) {
 		return false
 	} else if err != nil {
 		log.Fatal(err)
@@ -102,28 +122,56 @@ func (s *AccountsStorage) ExistsAccountFilePath() bool {
 }
 
 func (s *AccountsStorage) GetRootPath() string {
-	return s.rootPath
+	return storagePath(s.store, baseAccountsRootFolderName)
 }
 
 func (s *AccountsStorage) GetRootUserPath() string {
-	return s.rootUserPath
+	return storagePath(s.store, s.rootUserKey)
+}
+
+// ListAccountFiles returns the keys of the account files of all the accounts.
+func (s *AccountsStorage) ListAccountFiles() ([]string, error) {
+	return listAccountFiles(s.store)
+}
+
+// listAccountFiles returns the keys of the account files of all the accounts of the storage.
+func listAccountFiles(store storage.Store) ([]string, error) {
+	keys, err := store.List(context.Background(), baseAccountsRootFolderName+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+
+	for _, key := range keys {
+		// accounts/<server>/<userID>/account.json
+		if strings.Count(key, "/") == 3 && path.Base(key) == accountFileName {
+			files = append(files, key)
+		}
+	}
+
+	return files, nil
 }
 
 func (s *AccountsStorage) GetUserID() string {
 	return s.userID
 }
 
+func (s *AccountsStorage) GetEmail() string {
+	return s.email
+}
+
 func (s *AccountsStorage) Save(account *Account) error {
 	jsonBytes, err := json.MarshalIndent(account, "", "\t")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(s.accountFilePath, jsonBytes, filePerm)
+	return s.store.Put(context.Background(), s.accountFileKey, jsonBytes)
 }
 
 func (s *AccountsStorage) LoadAccount(privateKey crypto.PrivateKey) *Account {
-	fileBytes, err := os.ReadFile(s.accountFilePath)
+	fileBytes, err := s.store.Get(context.Background(), s.accountFileKey)
 	if err != nil {
 		log.Fatalf("Could not load file for account %s: %v", s.userID, err)
 	}
@@ -153,22 +201,25 @@ func (s *AccountsStorage) LoadAccount(privateKey crypto.PrivateKey) *Account {
 }
 
 func (s *AccountsStorage) GetPrivateKey(keyType certcrypto.KeyType) crypto.PrivateKey {
-	accKeyPath := filepath.Join(s.keysPath, s.userID+".key")
+	accKeyKey := s.privateKeyKey()
+	accKeyPath := storagePath(s.store, accKeyKey)
 
-	if _, err := os.Stat(accKeyPath); os.IsNotExist(err) {
+	keyBytes, err := s.store.Get(context.Background(), accKeyKey)
+	if errors.Is(err, storage.ErrNotExist) {
 		log.Printf("No key found for account %s. Generating a %s key.", s.userID, keyType)
-		s.createKeysFolder()
 
-		privateKey, err := generatePrivateKey(accKeyPath, keyType)
+		privateKey, err := s.generatePrivateKey(accKeyKey, keyType)
 		if err != nil {
 			log.Fatalf("Could not generate RSA private account key for account %s: %v", s.userID, err)
 		}
 
 		log.Printf("Saved key to %s", accKeyPath)
 		return privateKey
+	} else if err != nil {
+		log.Fatalf("Could not load RSA private key from file %s: %v", accKeyPath, err)
 	}
 
-	privateKey, err := loadPrivateKey(accKeyPath)
+	privateKey, err := parsePrivateKey(keyBytes)
 	if err != nil {
 		log.Fatalf("Could not load RSA private key from file %s: %v", accKeyPath, err)
 	}
@@ -176,26 +227,22 @@ func (s *AccountsStorage) GetPrivateKey(keyType certcrypto.KeyType) crypto.Priva
 	return privateKey
 }
 
-func (s *AccountsStorage) createKeysFolder() {
-	if err := createNonExistingFolder(s.keysPath); err != nil {
-		log.Fatalf("Could not check/create directory for account %s: %v", s.userID, err)
-	}
+// SavePrivateKey replaces the private key of the account (ex: after a key rollover).
+func (s *AccountsStorage) SavePrivateKey(privateKey crypto.PrivateKey) error {
+	return s.store.Put(context.Background(), s.privateKeyKey(), pem.EncodeToMemory(certcrypto.PEMBlock(privateKey)))
 }
 
-func generatePrivateKey(file string, keyType certcrypto.KeyType) (crypto.PrivateKey, error) {
-	privateKey, err := certcrypto.GeneratePrivateKey(keyType)
-	if err != nil {
-		return nil, err
-	}
+func (s *AccountsStorage) privateKeyKey() string {
+	return path.Join(s.keysKey, s.userID+".key")
+}
 
-	certOut, err := os.Create(file)
+func (s *AccountsStorage) generatePrivateKey(key string, keyType certcrypto.KeyType) (crypto.PrivateKey, error) {
+	privateKey, err := certcrypto.GeneratePrivateKey(keyType)
 	if err != nil {
 		return nil, err
 	}
-	defer certOut.Close()
 
-	pemKey := certcrypto.PEMBlock(privateKey)
-	err = pem.Encode(certOut, pemKey)
+	err = s.store.Put(context.Background(), key, pem.EncodeToMemory(certcrypto.PEMBlock(privateKey)))
 	if err != nil {
 		return nil, err
 	}
@@ -203,13 +250,11 @@ func generatePrivateKey(file string, keyType certcrypto.KeyType) (crypto.Private
 	return privateKey, nil
 }
 
-func loadPrivateKey(file string) (crypto.PrivateKey, error) {
-	keyBytes, err := os.ReadFile(file)
-	if err != nil {
-		return nil, err
-	}
-
+func parsePrivateKey(keyBytes []byte) (crypto.PrivateKey, error) {
 	keyBlock, _ := pem.Decode(keyBytes)
+	if keyBlock == nil {
+		return nil, errors.New("invalid PEM private key")
+	}
 
 	switch keyBlock.Type {
 	case "RSA PRIVATE KEY":
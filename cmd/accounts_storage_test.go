@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountsStorage(t *testing.T) {
+	root := t.TempDir()
+
+	accountsStorage := &AccountsStorage{
+		userID:         "foo@example.com",
+		store:          storage.NewFileSystem(root),
+		rootUserKey:    "accounts/acme.example.com/foo@example.com",
+		keysKey:        "accounts/acme.example.com/foo@example.com/keys",
+		accountFileKey: "accounts/acme.example.com/foo@example.com/account.json",
+	}
+
+	assert.False(t, accountsStorage.ExistsAccountFilePath())
+
+	privateKey := accountsStorage.GetPrivateKey(certcrypto.EC256)
+	require.NotNil(t, privateKey)
+
+	assert.FileExists(t, filepath.Join(root, "accounts", "acme.example.com", "foo@example.com", "keys", "foo@example.com.key"))
+
+	// the existing key is loaded.
+	assert.Equal(t, privateKey, accountsStorage.GetPrivateKey(certcrypto.EC256))
+
+	err := accountsStorage.Save(&Account{Email: "foo@example.com"})
+	require.NoError(t, err)
+
+	assert.True(t, accountsStorage.ExistsAccountFilePath())
+
+	files, err := accountsStorage.ListAccountFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"accounts/acme.example.com/foo@example.com/account.json"}, files)
+
+	assert.Equal(t, filepath.Join(root, "accounts", "acme.example.com", "foo@example.com"), accountsStorage.GetRootUserPath())
+}
+
+func Test_validateAccountName(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		name        string
+		expectError bool
+	}{
+		{desc: "email", name: "foo@example.com"},
+		{desc: "name", name: "production-2"},
+		{desc: "slash", name: "foo/bar", expectError: true},
+		{desc: "backslash", name: `foo\bar`, expectError: true},
+		{desc: "parent", name: "..", expectError: true},
+		{desc: "parent prefix", name: "../foo", expectError: true},
+		{desc: "current", name: ".", expectError: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := validateAccountName(test.name)
+			if test.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
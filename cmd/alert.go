@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	pushoverMessageURL = "https://api.pushover.net/1/messages.json"
+)
+
+// renewalFailures the consecutive renewal failures of a certificate.
+// It is stored in the resource file (.json) of the certificate, and cleared by a successful renewal.
+type renewalFailures struct {
+	Count       int       `json:"count"`
+	LastError   string    `json:"lastError,omitempty"`
+	LastAttempt time.Time `json:"lastAttempt"`
+	Alerted     bool      `json:"alerted,omitempty"`
+}
+
+// renewalAlert the description of a renewal failure sent to the alert sinks.
+type renewalAlert struct {
+	Domain   string
+	Failures int
+	NotAfter time.Time
+	Err      error
+	// Critical true if the remaining validity of the certificate is shorter than the threshold.
+	Critical bool
+}
+
+func (a renewalAlert) summary() string {
+	return fmt.Sprintf("lego: renewal of the certificate %s failed %d time(s), the certificate expires on %s",
+		a.Domain, a.Failures, a.NotAfter.UTC().Format(time.RFC3339))
+}
+
+// alertSink sends the alerts to an external system.
+type alertSink interface {
+	trigger(ctx context.Context, alert renewalAlert) error
+	resolve(ctx context.Context, alert renewalAlert) error
+}
+
+// alertManager sends alerts when the renewal of a certificate fails repeatedly (escalation policy):
+// only after a number of consecutive failures, or when the remaining validity of the certificate is too short.
+type alertManager struct {
+	failures int
+	validity time.Duration
+	sinks    []alertSink
+}
+
+// newAlertManager creates the alert manager from the "alert.*" options.
+// Returns nil if no alert sink is defined.
+func newAlertManager(ctx *cli.Context) *alertManager {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var sinks []alertSink
+
+	if key := ctx.String("alert.pagerduty.routing-key"); key != "" {
+		sinks = append(sinks, &pagerDutySink{client: client, endpoint: pagerDutyEventsURL, routingKey: key})
+	}
+
+	token, user := ctx.String("alert.pushover.token"), ctx.String("alert.pushover.user")
+
+	switch {
+	case token != "" && user != "":
+		sinks = append(sinks, &pushoverSink{client: client, endpoint: pushoverMessageURL, token: token, user: user})
+	case token != "" || user != "":
+		fatalConfig("Both --alert.pushover.token and --alert.pushover.user are required to send alerts to Pushover")
+	}
+
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	if ctx.Int("alert.failures") < 1 {
+		fatalConfig("--alert.failures must be greater than 0")
+	}
+
+	return &alertManager{
+		failures: ctx.Int("alert.failures"),
+		validity: time.Duration(ctx.Int("alert.days")) * 24 * time.Hour,
+		sinks:    sinks,
+	}
+}
+
+// onFailure records the renewal failure in the certificate metadata, and triggers an alert if the escalation policy matches.
+func (m *alertManager) onFailure(ctx context.Context, certsStorage *CertificatesStorage, domain string, notAfter time.Time, err error) {
+	if m == nil || !certsStorage.ExistsFile(domain, resourceExt) {
+		return
+	}
+
+	resource := certsStorage.ReadResource(domain)
+
+	if resource.Failures == nil {
+		resource.Failures = &renewalFailures{}
+	}
+
+	resource.Failures.Count++
+	resource.Failures.LastError = err.Error()
+	resource.Failures.LastAttempt = time.Now().UTC()
+
+	alert := renewalAlert{
+		Domain:   domain,
+		Failures: resource.Failures.Count,
+		NotAfter: notAfter,
+		Err:      err,
+		Critical: time.Until(notAfter) < m.validity,
+	}
+
+	if alert.Failures >= m.failures || alert.Critical {
+		if m.send(ctx, alert, alertSink.trigger) {
+			resource.Failures.Alerted = true
+		}
+	} else {
+		log.Infof("[%s] alert: renewal failure %d/%d: no alert sent.", domain, alert.Failures, m.failures)
+	}
+
+	certsStorage.SaveResourceMetadata(&resource)
+}
+
+// onSuccess resolves the alert of the previous renewal failures, if an alert has been sent.
+// It must be called before saving the renewed certificate.
+func (m *alertManager) onSuccess(ctx context.Context, certsStorage *CertificatesStorage, domain string, notAfter time.Time) {
+	if m == nil || !certsStorage.ExistsFile(domain, resourceExt) {
+		return
+	}
+
+	failures := certsStorage.ReadResource(domain).Failures
+	if failures == nil || !failures.Alerted {
+		return
+	}
+
+	m.send(ctx, renewalAlert{Domain: domain, Failures: failures.Count, NotAfter: notAfter}, alertSink.resolve)
+}
+
+// send calls all the sinks, and returns true if at least one sink succeeded.
+func (m *alertManager) send(ctx context.Context, alert renewalAlert, fn func(alertSink, context.Context, renewalAlert) error) bool {
+	var sent bool
+
+	for _, sink := range m.sinks {
+		err := fn(sink, ctx, alert)
+		if err != nil {
+			log.Warnf("[%s] alert: %v", alert.Domain, err)
+			continue
+		}
+
+		sent = true
+	}
+
+	return sent
+}
+
+// pagerDutySink sends the alerts to the PagerDuty Events API (v2).
+// The domain is used as deduplication key, so a successful renewal resolves the incident.
+type pagerDutySink struct {
+	client     *http.Client
+	endpoint   string
+	routingKey string
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func (s *pagerDutySink) trigger(ctx context.Context, alert renewalAlert) error {
+	severity := "error"
+	if alert.Critical {
+		severity = "critical"
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey:  s.routingKey,
+		EventAction: "trigger",
+		DedupKey:    pagerDutyDedupKey(alert.Domain),
+		Payload: &pagerDutyPayload{
+			Summary:   alert.summary(),
+			Source:    alert.Domain,
+			Severity:  severity,
+			Component: "lego",
+			CustomDetails: map[string]string{
+				"failures": fmt.Sprint(alert.Failures),
+				"notAfter": alert.NotAfter.UTC().Format(time.RFC3339),
+				"error":    errorString(alert.Err),
+			},
+		},
+	}
+
+	return s.send(ctx, event)
+}
+
+func (s *pagerDutySink) resolve(ctx context.Context, alert renewalAlert) error {
+	return s.send(ctx, pagerDutyEvent{
+		RoutingKey:  s.routingKey,
+		EventAction: "resolve",
+		DedupKey:    pagerDutyDedupKey(alert.Domain),
+	})
+}
+
+func (s *pagerDutySink) send(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return doAlertRequest(s.client, req, "pagerduty")
+}
+
+func pagerDutyDedupKey(domain string) string {
+	return "lego-renewal-" + domain
+}
+
+// pushoverSink sends the alerts to Pushover.
+type pushoverSink struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	user     string
+}
+
+func (s *pushoverSink) trigger(ctx context.Context, alert renewalAlert) error {
+	message := alert.summary()
+	if alert.Err != nil {
+		message += ": " + alert.Err.Error()
+	}
+
+	// The high priority bypasses the quiet hours of the user.
+	priority := "0"
+	if alert.Critical {
+		priority = "1"
+	}
+
+	return s.send(ctx, "Certificate renewal failure: "+alert.Domain, message, priority)
+}
+
+func (s *pushoverSink) resolve(ctx context.Context, alert renewalAlert) error {
+	return s.send(ctx, "Certificate renewed: "+alert.Domain,
+		fmt.Sprintf("lego: the certificate %s has been renewed after %d failure(s)", alert.Domain, alert.Failures), "0")
+}
+
+func (s *pushoverSink) send(ctx context.Context, title, message, priority string) error {
+	form := url.Values{}
+	form.Set("token", s.token)
+	form.Set("user", s.user)
+	form.Set("title", title)
+	form.Set("message", message)
+	form.Set("priority", priority)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return doAlertRequest(s.client, req, "pushover")
+}
+
+func doAlertRequest(client *http.Client, req *http.Request, name string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		raw, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: unexpected status code: %d: %s", name, resp.StatusCode, string(raw))
+	}
+
+	return nil
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
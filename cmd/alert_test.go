@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type alertSinkMock struct {
+	triggered []renewalAlert
+	resolved  []renewalAlert
+}
+
+func (s *alertSinkMock) trigger(_ context.Context, alert renewalAlert) error {
+	s.triggered = append(s.triggered, alert)
+	return nil
+}
+
+func (s *alertSinkMock) resolve(_ context.Context, alert renewalAlert) error {
+	s.resolved = append(s.resolved, alert)
+	return nil
+}
+
+func TestAlertManager(t *testing.T) {
+	domain := "example.com"
+
+	storage := newTestCertificatesStorage(t)
+	storage.SaveResourceMetadata(&CertificateResource{Resource: certificate.Resource{Domain: domain}})
+
+	sink := &alertSinkMock{}
+	manager := &alertManager{failures: 2, validity: 7 * 24 * time.Hour, sinks: []alertSink{sink}}
+
+	notAfter := time.Now().Add(20 * 24 * time.Hour)
+
+	manager.onFailure(context.Background(), storage, domain, notAfter, errors.New("boom"))
+	assert.Empty(t, sink.triggered)
+	assert.Equal(t, 1, storage.ReadResource(domain).Failures.Count)
+
+	manager.onFailure(context.Background(), storage, domain, notAfter, errors.New("boom"))
+	require.Len(t, sink.triggered, 1)
+	assert.Equal(t, 2, sink.triggered[0].Failures)
+	assert.False(t, sink.triggered[0].Critical)
+
+	failures := storage.ReadResource(domain).Failures
+	assert.Equal(t, 2, failures.Count)
+	assert.Equal(t, "boom", failures.LastError)
+	assert.True(t, failures.Alerted)
+
+	manager.onSuccess(context.Background(), storage, domain, notAfter)
+	require.Len(t, sink.resolved, 1)
+	assert.Equal(t, 2, sink.resolved[0].Failures)
+}
+
+func TestAlertManager_onFailure_validity(t *testing.T) {
+	domain := "example.com"
+
+	storage := newTestCertificatesStorage(t)
+	storage.SaveResourceMetadata(&CertificateResource{Resource: certificate.Resource{Domain: domain}})
+
+	sink := &alertSinkMock{}
+	manager := &alertManager{failures: 3, validity: 7 * 24 * time.Hour, sinks: []alertSink{sink}}
+
+	manager.onFailure(context.Background(), storage, domain, time.Now().Add(2*24*time.Hour), errors.New("boom"))
+
+	require.Len(t, sink.triggered, 1)
+	assert.True(t, sink.triggered[0].Critical)
+
+	manager.onSuccess(context.Background(), storage, "example.org", time.Now())
+	assert.Empty(t, sink.resolved)
+}
+
+func TestPagerDutySink(t *testing.T) {
+	var events []pagerDutyEvent
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var event pagerDutyEvent
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&event))
+
+		events = append(events, event)
+
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	sink := &pagerDutySink{client: server.Client(), endpoint: server.URL, routingKey: "key"}
+
+	alert := renewalAlert{Domain: "example.com", Failures: 3, NotAfter: time.Now(), Err: errors.New("boom"), Critical: true}
+
+	require.NoError(t, sink.trigger(context.Background(), alert))
+	require.NoError(t, sink.resolve(context.Background(), alert))
+
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "trigger", events[0].EventAction)
+	assert.Equal(t, "key", events[0].RoutingKey)
+	assert.Equal(t, "lego-renewal-example.com", events[0].DedupKey)
+	assert.Equal(t, "critical", events[0].Payload.Severity)
+	assert.Equal(t, "boom", events[0].Payload.CustomDetails["error"])
+
+	assert.Equal(t, "resolve", events[1].EventAction)
+	assert.Equal(t, "lego-renewal-example.com", events[1].DedupKey)
+	assert.Nil(t, events[1].Payload)
+}
+
+func TestPushoverSink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "token", req.FormValue("token"))
+		assert.Equal(t, "user", req.FormValue("user"))
+		assert.Equal(t, "Certificate renewal failure: example.com", req.FormValue("title"))
+		assert.Equal(t, "0", req.FormValue("priority"))
+
+		rw.WriteHeader(http.StatusBadRequest)
+		_, _ = rw.Write([]byte(`{"status":0}`))
+	}))
+	t.Cleanup(server.Close)
+
+	sink := &pushoverSink{client: server.Client(), endpoint: server.URL, token: "token", user: "user"}
+
+	err := sink.trigger(context.Background(), renewalAlert{Domain: "example.com", Failures: 3, NotAfter: time.Now()})
+	require.EqualError(t, err, `pushover: unexpected status code: 400: {"status":0}`)
+}
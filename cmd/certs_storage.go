@@ -2,14 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +17,7 @@ import (
 	"github.com/pya789/lego/v4/certcrypto"
 	"github.com/pya789/lego/v4/certificate"
 	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/storage"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/net/idna"
 	"software.sslmate.com/src/go-pkcs12"
@@ -36,26 +37,47 @@ const (
 	resourceExt = ".json"
 )
 
+// CertificateResource a certificate resource and its metadata.
+// It is stored in the resource file (.json) of the certificate.
+type CertificateResource struct {
+	certificate.Resource
+
+	// Account the name of the account used to obtain the certificate.
+	Account string `json:"account,omitempty"`
+
+	// Labels user-defined labels (ex: team, service, ticket).
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Schedule the windows during which the certificate is allowed to be renewed (ex: "Sun 02:00-04:00").
+	Schedule []string `json:"schedule,omitempty"`
+
+	// Deployments the IDs of the remote objects created by the deployment targets (ex: ARN), indexed by target name.
+	Deployments map[string]string `json:"deployments,omitempty"`
+
+	// Failures the consecutive renewal failures (only tracked when alerts are enabled).
+	Failures *renewalFailures `json:"failures,omitempty"`
+}
+
 // CertificatesStorage a certificates' storage.
 //
-// rootPath:
+// root certificates directory:
 //
 //	./.lego/certificates/
 //	     │      └── root certificates directory
-//	     └── "path" option
+//	     └── "path" option (or "storage" option)
 //
-// archivePath:
+// archived certificates directory:
 //
 //	./.lego/archives/
 //	     │      └── archived certificates directory
-//	     └── "path" option
+//	     └── "path" option (or "storage" option)
 type CertificatesStorage struct {
-	rootPath    string
-	archivePath string
+	store       storage.Store
 	pem         bool
 	pfx         bool
 	pfxPassword string
 	pfxFormat   string
+	ocsp        bool
 	filename    string // Deprecated
 }
 
@@ -66,39 +88,57 @@ func NewCertificatesStorage(ctx *cli.Context) *CertificatesStorage {
 	switch pfxFormat {
 	case "DES", "RC2", "SHA256":
 	default:
-		log.Fatalf("Invalid PFX format: %s", pfxFormat)
+		fatalConfigf("Invalid PFX format: %s", pfxFormat)
 	}
 
 	return &CertificatesStorage{
-		rootPath:    filepath.Join(ctx.String("path"), baseCertificatesFolderName),
-		archivePath: filepath.Join(ctx.String("path"), baseArchivesFolderName),
+		store:       getStore(ctx),
 		pem:         ctx.Bool("pem"),
 		pfx:         ctx.Bool("pfx"),
 		pfxPassword: ctx.String("pfx.pass"),
 		pfxFormat:   pfxFormat,
+		ocsp:        ctx.Bool("ocsp"),
 		filename:    ctx.String("filename"),
 	}
 }
 
+// CreateRootFolder creates the root certificates directory (only for the filesystem storage).
 func (s *CertificatesStorage) CreateRootFolder() {
-	err := createNonExistingFolder(s.rootPath)
+	if _, ok := s.store.(*storage.FileSystem); !ok {
+		return
+	}
+
+	err := createNonExistingFolder(s.GetRootPath())
 	if err != nil {
 		log.Fatalf("Could not check/create path: %v", err)
 	}
 }
 
+// CreateArchiveFolder creates the archived certificates directory (only for the filesystem storage).
 func (s *CertificatesStorage) CreateArchiveFolder() {
-	err := createNonExistingFolder(s.archivePath)
+	if _, ok := s.store.(*storage.FileSystem); !ok {
+		return
+	}
+
+	err := createNonExistingFolder(s.GetArchivePath())
 	if err != nil {
 		log.Fatalf("Could not check/create path: %v", err)
 	}
 }
 
+// GetRootPath returns the path of the root certificates directory,
+// or the prefix of the keys of the certificates if the storage is not the filesystem.
 func (s *CertificatesStorage) GetRootPath() string {
-	return s.rootPath
+	return storagePath(s.store, baseCertificatesFolderName)
+}
+
+// GetArchivePath returns the path of the archived certificates directory,
+// or the prefix of the keys of the archived certificates if the storage is not the filesystem.
+func (s *CertificatesStorage) GetArchivePath() string {
+	return storagePath(s.store, baseArchivesFolderName)
 }
 
-func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
+func (s *CertificatesStorage) SaveResource(certRes *CertificateResource) {
 	domain := certRes.Domain
 
 	// We store the certificate, private key and metadata in different files
@@ -117,7 +157,7 @@ func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
 
 	// if we were given a CSR, we don't know the private key
 	if certRes.PrivateKey != nil {
-		err = s.WriteCertificateFiles(domain, certRes)
+		err = s.WriteCertificateFiles(domain, &certRes.Resource)
 		if err != nil {
 			log.Fatalf("Unable to save PrivateKey for domain %s\n\t%v", domain, err)
 		}
@@ -126,6 +166,13 @@ func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
 		log.Fatalf("Unable to save PEM or PFX without private key for domain %s. Are you using a CSR?", domain)
 	}
 
+	s.SaveResourceMetadata(certRes)
+}
+
+// SaveResourceMetadata saves only the resource file (.json) of the certificate.
+func (s *CertificatesStorage) SaveResourceMetadata(certRes *CertificateResource) {
+	domain := certRes.Domain
+
 	jsonBytes, err := json.MarshalIndent(certRes, "", "\t")
 	if err != nil {
 		log.Fatalf("Unable to marshal CertResource for domain %s\n\t%v", domain, err)
@@ -137,13 +184,13 @@ func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
 	}
 }
 
-func (s *CertificatesStorage) ReadResource(domain string) certificate.Resource {
+func (s *CertificatesStorage) ReadResource(domain string) CertificateResource {
 	raw, err := s.ReadFile(domain, resourceExt)
 	if err != nil {
 		log.Fatalf("Error while loading the meta data for domain %s\n\t%v", domain, err)
 	}
 
-	var resource certificate.Resource
+	var resource CertificateResource
 	if err = json.Unmarshal(raw, &resource); err != nil {
 		log.Fatalf("Error while marshaling the meta data for domain %s\n\t%v", domain, err)
 	}
@@ -152,9 +199,8 @@ func (s *CertificatesStorage) ReadResource(domain string) certificate.Resource {
 }
 
 func (s *CertificatesStorage) ExistsFile(domain, extension string) bool {
-	filePath := s.GetFileName(domain, extension)
-
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	_, err := s.ReadFile(domain, extension)
+	if errors.Is(err, storage.ErrNotExist) {
 		return false
 	} else if err != nil {
 		log.Fatal(err)
@@ -163,12 +209,44 @@ func (s *CertificatesStorage) ExistsFile(domain, extension string) bool {
 }
 
 func (s *CertificatesStorage) ReadFile(domain, extension string) ([]byte, error) {
-	return os.ReadFile(s.GetFileName(domain, extension))
+	return s.store.Get(context.Background(), certificateKey(sanitizedDomain(domain)+extension))
 }
 
+// RemoveFile removes the file of the domain, it doesn't fail if the file doesn't exist.
+func (s *CertificatesStorage) RemoveFile(domain, extension string) error {
+	return s.store.Delete(context.Background(), certificateKey(sanitizedDomain(domain)+extension))
+}
+
+// ListFiles returns the names (without extension) of the files with the extension.
+func (s *CertificatesStorage) ListFiles(extension string) ([]string, error) {
+	keys, err := s.store.List(context.Background(), baseCertificatesFolderName+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, key := range keys {
+		filename := path.Base(key)
+		if key != certificateKey(filename) || !strings.HasSuffix(filename, extension) {
+			continue
+		}
+
+		name := strings.TrimSuffix(filename, extension)
+		if extension == certExt && strings.HasSuffix(name, ".issuer") {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// GetFileName returns the path of the file of the domain,
+// or the key of the file if the storage is not the filesystem.
 func (s *CertificatesStorage) GetFileName(domain, extension string) string {
-	filename := sanitizedDomain(domain) + extension
-	return filepath.Join(s.rootPath, filename)
+	return storagePath(s.store, certificateKey(sanitizedDomain(domain)+extension))
 }
 
 func (s *CertificatesStorage) ReadCertificate(domain, extension string) ([]*x509.Certificate, error) {
@@ -189,9 +267,12 @@ func (s *CertificatesStorage) WriteFile(domain, extension string, data []byte) e
 		baseFileName = sanitizedDomain(domain)
 	}
 
-	filePath := filepath.Join(s.rootPath, baseFileName+extension)
+	return s.store.Put(context.Background(), certificateKey(baseFileName+extension), data)
+}
 
-	return os.WriteFile(filePath, data, filePerm)
+// writeDomainFile writes the file of the domain, regardless of the deprecated "filename" option.
+func (s *CertificatesStorage) writeDomainFile(domain, extension string, data []byte) error {
+	return s.store.Put(context.Background(), certificateKey(sanitizedDomain(domain)+extension), data)
 }
 
 func (s *CertificatesStorage) WriteCertificateFiles(domain string, certRes *certificate.Resource) error {
@@ -270,23 +351,33 @@ func (s *CertificatesStorage) WritePFXFile(domain string, certRes *certificate.R
 }
 
 func (s *CertificatesStorage) MoveToArchive(domain string) error {
-	baseFilename := filepath.Join(s.rootPath, sanitizedDomain(domain))
+	ctx := context.Background()
 
-	matches, err := filepath.Glob(baseFilename + ".*")
+	baseKey := certificateKey(sanitizedDomain(domain))
+
+	keys, err := s.store.List(ctx, baseKey+".")
 	if err != nil {
 		return err
 	}
 
-	for _, oldFile := range matches {
-		if strings.TrimSuffix(oldFile, filepath.Ext(oldFile)) != baseFilename && oldFile != baseFilename+issuerExt {
+	for _, oldKey := range keys {
+		if strings.TrimSuffix(oldKey, path.Ext(oldKey)) != baseKey && oldKey != baseKey+issuerExt {
 			continue
 		}
 
+		data, err := s.store.Get(ctx, oldKey)
+		if err != nil {
+			return err
+		}
+
 		date := strconv.FormatInt(time.Now().Unix(), 10)
-		filename := date + "." + filepath.Base(oldFile)
-		newFile := filepath.Join(s.archivePath, filename)
 
-		err = os.Rename(oldFile, newFile)
+		err = s.store.Put(ctx, baseArchivesFolderName+"/"+date+"."+path.Base(oldKey), data)
+		if err != nil {
+			return err
+		}
+
+		err = s.store.Delete(ctx, oldKey)
 		if err != nil {
 			return err
 		}
@@ -331,6 +422,10 @@ func getPFXEncoder(pfxFormat string) (*pkcs12.Encoder, error) {
 	return encoder, nil
 }
 
+func certificateKey(filename string) string {
+	return baseCertificatesFolderName + "/" + filename
+}
+
 // sanitizedDomain Make sure no funny chars are in the cert names (like wildcards ;)).
 func sanitizedDomain(domain string) string {
 	safe, err := idna.ToASCII(strings.NewReplacer(":", "-", "*", "_").Replace(domain))
@@ -6,6 +6,8 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -13,12 +15,9 @@ import (
 func TestCertificatesStorage_MoveToArchive(t *testing.T) {
 	domain := "example.com"
 
-	storage := CertificatesStorage{
-		rootPath:    t.TempDir(),
-		archivePath: t.TempDir(),
-	}
+	storage := newTestCertificatesStorage(t)
 
-	domainFiles := generateTestFiles(t, storage.rootPath, domain)
+	domainFiles := generateTestFiles(t, storage.GetRootPath(), domain)
 
 	err := storage.MoveToArchive(domain)
 	require.NoError(t, err)
@@ -27,11 +26,11 @@ func TestCertificatesStorage_MoveToArchive(t *testing.T) {
 		assert.NoFileExists(t, file)
 	}
 
-	root, err := os.ReadDir(storage.rootPath)
+	root, err := os.ReadDir(storage.GetRootPath())
 	require.NoError(t, err)
 	require.Empty(t, root)
 
-	archive, err := os.ReadDir(storage.archivePath)
+	archive, err := os.ReadDir(storage.GetArchivePath())
 	require.NoError(t, err)
 
 	require.Len(t, archive, len(domainFiles))
@@ -41,12 +40,9 @@ func TestCertificatesStorage_MoveToArchive(t *testing.T) {
 func TestCertificatesStorage_MoveToArchive_noFileRelatedToDomain(t *testing.T) {
 	domain := "example.com"
 
-	storage := CertificatesStorage{
-		rootPath:    t.TempDir(),
-		archivePath: t.TempDir(),
-	}
+	storage := newTestCertificatesStorage(t)
 
-	domainFiles := generateTestFiles(t, storage.rootPath, "example.org")
+	domainFiles := generateTestFiles(t, storage.GetRootPath(), "example.org")
 
 	err := storage.MoveToArchive(domain)
 	require.NoError(t, err)
@@ -55,11 +51,11 @@ func TestCertificatesStorage_MoveToArchive_noFileRelatedToDomain(t *testing.T) {
 		assert.FileExists(t, file)
 	}
 
-	root, err := os.ReadDir(storage.rootPath)
+	root, err := os.ReadDir(storage.GetRootPath())
 	require.NoError(t, err)
 	assert.Len(t, root, len(domainFiles))
 
-	archive, err := os.ReadDir(storage.archivePath)
+	archive, err := os.ReadDir(storage.GetArchivePath())
 	require.NoError(t, err)
 
 	assert.Empty(t, archive)
@@ -68,13 +64,10 @@ func TestCertificatesStorage_MoveToArchive_noFileRelatedToDomain(t *testing.T) {
 func TestCertificatesStorage_MoveToArchive_ambiguousDomain(t *testing.T) {
 	domain := "example.com"
 
-	storage := CertificatesStorage{
-		rootPath:    t.TempDir(),
-		archivePath: t.TempDir(),
-	}
+	storage := newTestCertificatesStorage(t)
 
-	domainFiles := generateTestFiles(t, storage.rootPath, domain)
-	otherDomainFiles := generateTestFiles(t, storage.rootPath, domain+".example.org")
+	domainFiles := generateTestFiles(t, storage.GetRootPath(), domain)
+	otherDomainFiles := generateTestFiles(t, storage.GetRootPath(), domain+".example.org")
 
 	err := storage.MoveToArchive(domain)
 	require.NoError(t, err)
@@ -87,17 +80,55 @@ func TestCertificatesStorage_MoveToArchive_ambiguousDomain(t *testing.T) {
 		assert.FileExists(t, file)
 	}
 
-	root, err := os.ReadDir(storage.rootPath)
+	root, err := os.ReadDir(storage.GetRootPath())
 	require.NoError(t, err)
 	require.Len(t, root, len(otherDomainFiles))
 
-	archive, err := os.ReadDir(storage.archivePath)
+	archive, err := os.ReadDir(storage.GetArchivePath())
 	require.NoError(t, err)
 
 	require.Len(t, archive, len(domainFiles))
 	assert.Regexp(t, `\d+\.`+regexp.QuoteMeta(domain), archive[0].Name())
 }
 
+func TestCertificatesStorage_SaveResource(t *testing.T) {
+	storage := newTestCertificatesStorage(t)
+
+	resource := &CertificateResource{
+		Resource: certificate.Resource{
+			Domain:      "example.com",
+			CertURL:     "https://example.com/cert",
+			Certificate: []byte("cert"),
+		},
+		Account: "foo",
+	}
+
+	storage.SaveResource(resource)
+
+	actual := storage.ReadResource("example.com")
+
+	expected := CertificateResource{
+		Resource: certificate.Resource{
+			Domain:  "example.com",
+			CertURL: "https://example.com/cert",
+		},
+		Account: "foo",
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+// newTestCertificatesStorage creates a CertificatesStorage in a temporary directory.
+func newTestCertificatesStorage(t *testing.T) *CertificatesStorage {
+	t.Helper()
+
+	certsStorage := &CertificatesStorage{store: storage.NewFileSystem(t.TempDir())}
+	certsStorage.CreateRootFolder()
+	certsStorage.CreateArchiveFolder()
+
+	return certsStorage
+}
+
 func generateTestFiles(t *testing.T, dir, domain string) []string {
 	t.Helper()
 
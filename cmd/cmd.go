@@ -4,11 +4,30 @@ import "github.com/urfave/cli/v2"
 
 // CreateCommands Creates all CLI commands.
 func CreateCommands() []*cli.Command {
-	return []*cli.Command{
+	commands := []*cli.Command{
 		createRun(),
+		createContinue(),
+		createPreAuth(),
 		createRevoke(),
 		createRenew(),
 		createDNSHelp(),
 		createList(),
+		createCert(),
+		createStorage(),
+		createDaemon(),
+		createInventory(),
+		createHealth(),
+		createDashboard(),
+		createConfig(),
+		createGC(),
+		createStats(),
+		createAccount(),
+		createSelfUpdate(),
 	}
+
+	for _, command := range commands {
+		command.OnUsageError = OnUsageError
+	}
+
+	return commands
 }
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+func createAccount() *cli.Command {
+	return &cli.Command{
+		Name:  "account",
+		Usage: "Manage the ACME account.",
+		Subcommands: []*cli.Command{
+			{
+				Name: "eab-rotate",
+				Usage: "Rotate the External Account Binding credentials of the account:" +
+					" the account key is replaced by a new key (key rollover), and the new key is bound to the new credentials (--kid and --hmac).",
+				Before: func(ctx *cli.Context) error {
+					if ctx.String("kid") == "" || ctx.String("hmac") == "" {
+						fatalConfigf("Requires arguments --kid and --hmac.")
+					}
+
+					return nil
+				},
+				Action: accountRotateEAB,
+			},
+			{
+				Name: "export",
+				Usage: "Export the account (or all the accounts with --all) to an encrypted bundle containing the account files and the private keys," +
+					" ex: to migrate the accounts to another host, or to back them up.",
+				Before: requireBundlePassphrase,
+				Action: accountExport,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "output",
+						Aliases:  []string{"o"},
+						Usage:    "The path of the bundle file.",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "Export all the accounts of the storage (all the CA servers), instead of the account defined by --account or --email, and --server.",
+					},
+					&cli.StringFlag{
+						Name:    "passphrase",
+						Usage:   "The passphrase used to encrypt the bundle.",
+						EnvVars: []string{"LEGO_ACCOUNT_BUNDLE_PASSPHRASE"},
+					},
+				},
+			},
+			{
+				Name:   "import",
+				Usage:  "Import the accounts of an encrypted bundle created by 'account export' in the storage.",
+				Before: requireBundlePassphrase,
+				Action: accountImport,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "input",
+						Aliases:  []string{"i"},
+						Usage:    "The path of the bundle file.",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Replace the existing accounts.",
+					},
+					&cli.StringFlag{
+						Name:    "passphrase",
+						Usage:   "The passphrase used to decrypt the bundle.",
+						EnvVars: []string{"LEGO_ACCOUNT_BUNDLE_PASSPHRASE"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func accountRotateEAB(ctx *cli.Context) error {
+	accountsStorage := NewAccountsStorage(ctx)
+
+	if !accountsStorage.ExistsAccountFilePath() {
+		fatalConfigf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
+	}
+
+	account, client := setup(ctx, accountsStorage)
+
+	if account.Registration == nil {
+		fatalConfigf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
+	}
+
+	newKey, err := certcrypto.GeneratePrivateKey(getKeyType(ctx))
+	if err != nil {
+		log.Fatalf("Could not generate the new account key for account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	err = client.Registration.KeyRollover(newKey)
+	if err != nil {
+		log.Fatalf("Could not change the key of account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	// The old key is no longer associated with the account: the new key must be saved before the binding.
+	err = accountsStorage.SavePrivateKey(newKey)
+	if err != nil {
+		log.Fatalf("Could not save the new key of account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	account.key = newKey
+
+	reg, err := client.Registration.BindExternalAccount(ctx.String("kid"), ctx.String("hmac"))
+	if err != nil {
+		log.Fatalf("Could not bind account %s to the new External Account Binding credentials: %v", accountsStorage.GetUserID(), err)
+	}
+
+	account.Registration = reg
+
+	err = accountsStorage.Save(account)
+	if err != nil {
+		log.Fatalf("Could not save account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	log.Printf("The External Account Binding of account %s has been rotated (key identifier: %s).", accountsStorage.GetUserID(), ctx.String("kid"))
+
+	return nil
+}
+
+func requireBundlePassphrase(ctx *cli.Context) error {
+	if ctx.String("passphrase") == "" {
+		fatalConfigf("Requires the passphrase of the bundle: --passphrase, or LEGO_ACCOUNT_BUNDLE_PASSPHRASE.")
+	}
+
+	return nil
+}
+
+func accountExport(ctx *cli.Context) error {
+	store := getStore(ctx)
+
+	var accountFiles []string
+
+	if ctx.Bool("all") {
+		var err error
+
+		accountFiles, err = listAccountFiles(store)
+		if err != nil {
+			return fmt.Errorf("could not list the accounts: %w", err)
+		}
+	} else {
+		accountsStorage := NewAccountsStorage(ctx)
+
+		if !accountsStorage.ExistsAccountFilePath() {
+			fatalConfigf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
+		}
+
+		accountFiles = append(accountFiles, accountsStorage.accountFileKey)
+	}
+
+	if len(accountFiles) == 0 {
+		return errors.New("no account to export")
+	}
+
+	payload, err := collectAccounts(ctx.Context, store, accountFiles)
+	if err != nil {
+		return fmt.Errorf("could not read the accounts: %w", err)
+	}
+
+	bundle, err := sealAccountBundle(payload, ctx.String("passphrase"))
+	if err != nil {
+		return fmt.Errorf("could not create the bundle: %w", err)
+	}
+
+	err = os.WriteFile(ctx.String("output"), bundle, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not write the bundle: %w", err)
+	}
+
+	for _, entry := range payload.Accounts {
+		log.Printf("Account %s (%s) exported.", entry.UserID, entry.Server)
+	}
+
+	log.Printf("%d account(s) exported to %s.", len(payload.Accounts), ctx.String("output"))
+
+	return nil
+}
+
+func accountImport(ctx *cli.Context) error {
+	raw, err := os.ReadFile(ctx.String("input"))
+	if err != nil {
+		return fmt.Errorf("could not read the bundle: %w", err)
+	}
+
+	payload, err := openAccountBundle(raw, ctx.String("passphrase"))
+	if err != nil {
+		return fmt.Errorf("could not open the bundle %s: %w", ctx.String("input"), err)
+	}
+
+	err = restoreAccounts(ctx.Context, getStore(ctx), payload, ctx.Bool("force"))
+	if err != nil {
+		return fmt.Errorf("could not import the accounts: %w", err)
+	}
+
+	for _, entry := range payload.Accounts {
+		log.Printf("Account %s (%s) imported.", entry.UserID, entry.Server)
+	}
+
+	log.Printf("%d account(s) imported from %s (exported at %s).", len(payload.Accounts), ctx.String("input"), payload.Created.Format(time.RFC3339))
+
+	return nil
+}
@@ -1,22 +1,78 @@
 package cmd
 
 import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+
 	"github.com/pya789/lego/v4/log"
 	"github.com/urfave/cli/v2"
 )
 
 func Before(ctx *cli.Context) error {
+	err := setupLogger(ctx)
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	err = mergeIPAddresses(ctx)
+	if err != nil {
+		fatalConfig(err)
+	}
+
 	if ctx.String("path") == "" {
-		log.Fatal("Could not determine current working directory. Please pass --path.")
+		fatalConfig("Could not determine current working directory. Please pass --path.")
 	}
 
-	err := createNonExistingFolder(ctx.String("path"))
+	err = createNonExistingFolder(ctx.String("path"))
 	if err != nil {
 		log.Fatalf("Could not check/create path: %v", err)
 	}
 
 	if ctx.String("server") == "" {
-		log.Fatal("Could not determine current working server. Please pass --server.")
+		fatalConfig("Could not determine current working server. Please pass --server.")
+	}
+
+	return nil
+}
+
+// mergeIPAddresses adds the IP addresses (--ip) to the domains (--domains):
+// the IP addresses are ordered as identifiers of type "ip" (RFC 8738).
+func mergeIPAddresses(ctx *cli.Context) error {
+	for _, value := range ctx.StringSlice("ip") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address: %q", value)
+		}
+
+		err := ctx.Set("domains", ip.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setupLogger configures the level and the format of the logs (--log.level, --log.format).
+func setupLogger(ctx *cli.Context) error {
+	level, err := log.ParseLevel(ctx.String("log.level"))
+	if err != nil {
+		return err
+	}
+
+	switch ctx.String("log.format") {
+	case "", "text":
+		log.SetLevel(level)
+
+	case "json":
+		handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level.SlogLevel()})
+
+		log.SetLeveledLogger(log.NewSlogLogger(slog.New(handler)))
+
+	default:
+		return fmt.Errorf("unknown log format: %q", ctx.String("log.format"))
 	}
 
 	return nil
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+func createCert() *cli.Command {
+	return &cli.Command{
+		Name:  "cert",
+		Usage: "Manage the stored certificates.",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "fingerprint",
+				Usage:  "Display the fingerprints, SPKI pins, and TLSA records of the stored certificates (all certificates if no domains are defined).",
+				Action: certFingerprint,
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:  "tlsa.usage",
+						Usage: "The certificate usage of the TLSA record: 0 (PKIX-TA), 1 (PKIX-EE), 2 (DANE-TA), or 3 (DANE-EE).",
+						Value: 3,
+					},
+					&cli.UintFlag{
+						Name:  "tlsa.selector",
+						Usage: "The selector of the TLSA record: 0 (full certificate), or 1 (SubjectPublicKeyInfo).",
+						Value: certcrypto.TLSASelectorSPKI,
+					},
+					&cli.UintFlag{
+						Name:  "tlsa.matching-type",
+						Usage: "The matching type of the TLSA record: 0 (exact match), 1 (SHA-256), or 2 (SHA-512).",
+						Value: certcrypto.TLSAMatchingSHA256,
+					},
+				},
+			},
+			{
+				Name:   "export",
+				Usage:  "Export the stored certificates (all certificates if no domains are defined) to the storage of a reverse proxy.",
+				Action: certExport,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "format",
+						Usage:    "The format of the export: 'traefik' (acme.json file), 'caddy' (data directory), or 'spiffe' (X.509 SVID files).",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "output",
+						Aliases:  []string{"o"},
+						Usage:    "The path of the Traefik acme.json file, the path of the Caddy data directory, or the directory of the SVID files.",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "traefik.resolver",
+						Usage: "The name of the Traefik certificates resolver.",
+						Value: "lego",
+					},
+					&cli.StringFlag{
+						Name:  "caddy.issuer",
+						Usage: "The name of the Caddy issuer directory. Defaults to the name derived from the CA URL ('--server').",
+					},
+					&cli.StringFlag{
+						Name:  "spiffe.bundle",
+						Usage: "The PEM file of the trust bundle written with the SVID files (ex: the root certificates of the CA). Defaults to the issuer certificates.",
+					},
+				},
+			},
+		},
+	}
+}
+
+func certFingerprint(ctx *cli.Context) error {
+	certsStorage := NewCertificatesStorage(ctx)
+
+	usage := ctx.Uint("tlsa.usage")
+	if usage > 3 {
+		log.Fatalf("Unsupported TLSA certificate usage: %d", usage)
+	}
+
+	selector := uint8(ctx.Uint("tlsa.selector"))
+	matchingType := uint8(ctx.Uint("tlsa.matching-type"))
+
+	domains, err := getCertificateNames(ctx, certsStorage)
+	if err != nil {
+		return err
+	}
+
+	if len(domains) == 0 {
+		fmt.Println("No certificates found.")
+		return nil
+	}
+
+	for _, domain := range domains {
+		certificates, err := certsStorage.ReadCertificate(domain, ".crt")
+		if err != nil {
+			log.Fatalf("Error while reading the certificate for domain %s\n\t%v", domain, err)
+		}
+
+		err = printFingerprints(domain, certificates[0], usage, selector, matchingType)
+		if err != nil {
+			log.Fatalf("Error while computing the fingerprints of the certificate for domain %s\n\t%v", domain, err)
+		}
+	}
+
+	return nil
+}
+
+func printFingerprints(domain string, cert *x509.Certificate, usage uint, selector, matchingType uint8) error {
+	tlsa, err := certcrypto.TLSAData(cert, selector, matchingType)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Certificate Name:", domain)
+	fmt.Println("  SHA-256 Fingerprint:", certcrypto.Fingerprint(cert))
+	fmt.Println("  SPKI Pin (pin-sha256):", certcrypto.SPKIPin(cert))
+	fmt.Printf("  TLSA Record: %d %d %d %s\n", usage, selector, matchingType, tlsa)
+	fmt.Println()
+
+	return nil
+}
+
+func certExport(ctx *cli.Context) error {
+	certsStorage := NewCertificatesStorage(ctx)
+
+	domains, err := getCertificateNames(ctx, certsStorage)
+	if err != nil {
+		return err
+	}
+
+	var certificates []*exportedCertificate
+
+	for _, domain := range domains {
+		exported, err := readExportedCertificate(certsStorage, domain)
+		if err != nil {
+			log.Fatalf("Error while reading the certificate for domain %s\n\t%v", domain, err)
+		}
+
+		certificates = append(certificates, exported)
+	}
+
+	output := ctx.String("output")
+
+	switch format := ctx.String("format"); format {
+	case "traefik":
+		err = exportTraefik(output, ctx.String("traefik.resolver"), certificates)
+	case "caddy":
+		err = exportCaddy(output, ctx.String("server"), ctx.String("caddy.issuer"), certificates)
+	case "spiffe":
+		err = exportSPIFFE(output, ctx.String("spiffe.bundle"), certificates)
+	default:
+		log.Fatalf("Unsupported export format: %s", format)
+	}
+
+	if err != nil {
+		log.Fatalf("Could not export the certificates: %v", err)
+	}
+
+	log.Infof("%d certificate(s) exported to %s", len(certificates), output)
+
+	return nil
+}
+
+// getCertificateNames returns the domains defined by the "domains" flag, or the names of all the stored certificates.
+func getCertificateNames(ctx *cli.Context, certsStorage *CertificatesStorage) ([]string, error) {
+	domains := ctx.StringSlice("domains")
+	if len(domains) > 0 {
+		return domains, nil
+	}
+
+	return certsStorage.ListFiles(certExt)
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+const redacted = "[REDACTED]"
+
+// Sources of the configuration values.
+const (
+	configSourceFlag    = "flag"
+	configSourceEnv     = "env"
+	configSourceDefault = "default"
+)
+
+// secretName matches the names of the options and environment variables containing secrets.
+var secretName = regexp.MustCompile(`(?i)(hmac|password|passphrase|secret|token|routing-key|api[-_]?key|private[-_]?key|credentials?)`)
+
+func createConfig() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Display the configuration",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "show",
+				Usage:  "Display the effective configuration (flags, environment variables, deployment configuration, defaults). The secrets are redacted.",
+				Action: showConfig,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Display the configuration as JSON.",
+					},
+				},
+			},
+		},
+	}
+}
+
+type effectiveConfig struct {
+	Options     []configOption    `json:"options"`
+	Environment map[string]string `json:"environment,omitempty"`
+	Deploy      []deployTarget    `json:"deploy,omitempty"`
+}
+
+type configOption struct {
+	Name   string      `json:"name"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+	EnvVar string      `json:"envVar,omitempty"`
+}
+
+type deployTarget struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Domains []string `json:"domains,omitempty"`
+}
+
+func showConfig(ctx *cli.Context) error {
+	cfg := effectiveConfig{
+		Options:     getConfigOptions(ctx, os.Args),
+		Environment: getLegoEnvironment(ctx.App.Flags),
+	}
+
+	if deployConfig := loadDeployConfig(ctx); deployConfig != nil {
+		for _, target := range deployConfig.Targets {
+			cfg.Deploy = append(cfg.Deploy, deployTarget{Name: target.Name, Type: target.Type, Domains: target.Domains})
+		}
+	}
+
+	if ctx.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(cfg)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "OPTION\tVALUE\tSOURCE")
+
+	for _, option := range cfg.Options {
+		source := option.Source
+		if option.Source == configSourceEnv {
+			source += " (" + option.EnvVar + ")"
+		}
+
+		_, _ = fmt.Fprintf(w, "--%s\t%v\t%s\n", option.Name, formatConfigValue(option.Value), source)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(cfg.Environment) > 0 {
+		fmt.Println("\nEnvironment:")
+
+		var keys []string
+		for k := range cfg.Environment {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fmt.Printf("  %s=%s\n", k, cfg.Environment[k])
+		}
+	}
+
+	if len(cfg.Deploy) > 0 {
+		fmt.Printf("\nDeployment targets (%s):\n", ctx.String("deploy-config"))
+
+		for _, target := range cfg.Deploy {
+			fmt.Printf("  %s (%s): %s\n", target.Name, target.Type, strings.Join(target.Domains, ", "))
+		}
+	}
+
+	return nil
+}
+
+// getConfigOptions returns the global options with their effective values and their sources.
+func getConfigOptions(ctx *cli.Context, args []string) []configOption {
+	var options []configOption
+
+	for _, flag := range ctx.App.Flags {
+		names := flag.Names()
+		name := names[0]
+
+		if name == "help" || name == "version" {
+			continue
+		}
+
+		option := configOption{
+			Name:   name,
+			Value:  configValue(ctx.Value(name)),
+			Source: configSourceDefault,
+		}
+
+		switch {
+		case isFlagInArgs(names, args):
+			option.Source = configSourceFlag
+
+		case ctx.IsSet(name):
+			option.Source = configSourceEnv
+
+			if envFlag, ok := flag.(interface{ GetEnvVars() []string }); ok {
+				for _, envVar := range envFlag.GetEnvVars() {
+					if _, found := os.LookupEnv(envVar); found {
+						option.EnvVar = envVar
+						break
+					}
+				}
+			}
+		}
+
+		if secretName.MatchString(name) && !isEmptyConfigValue(option.Value) {
+			option.Value = redacted
+		}
+
+		// The credentials of the proxies are redacted, not the address of the proxies.
+		if value, ok := option.Value.(string); ok && strings.HasPrefix(name, "proxy.") && value != "" {
+			if u, err := url.Parse(value); err == nil {
+				option.Value = u.Redacted()
+			}
+		}
+
+		options = append(options, option)
+	}
+
+	return options
+}
+
+// getLegoEnvironment returns the LEGO_* environment variables not bound to an option (ex: LEGO_CA_CERTIFICATES).
+func getLegoEnvironment(flags []cli.Flag) map[string]string {
+	var bound []string
+
+	for _, flag := range flags {
+		if envFlag, ok := flag.(interface{ GetEnvVars() []string }); ok {
+			bound = append(bound, envFlag.GetEnvVars()...)
+		}
+	}
+
+	env := map[string]string{}
+
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, "LEGO_") || slices.Contains(bound, key) {
+			continue
+		}
+
+		if secretName.MatchString(key) && value != "" {
+			value = redacted
+		}
+
+		env[key] = value
+	}
+
+	return env
+}
+
+// isFlagInArgs returns true if one of the names of the flag is used in the command line arguments.
+func isFlagInArgs(names, args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		key, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if slices.Contains(names, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// configValue converts the value of an option to a displayable (and JSON encodable) value.
+func configValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case cli.StringSlice:
+		return v.Value()
+	case *cli.StringSlice:
+		return v.Value()
+	case *cli.IntSlice:
+		return v.Value()
+	case *cli.Timestamp:
+		if t := v.Value(); t != nil {
+			return t.Format(time.RFC3339)
+		}
+
+		return ""
+	case time.Duration:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+func isEmptyConfigValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func formatConfigValue(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case string:
+		if v == "" {
+			return `""`
+		}
+
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
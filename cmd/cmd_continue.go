@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/lego"
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+func createContinue() *cli.Command {
+	return &cli.Command{
+		Name:  "continue",
+		Usage: "Validate the challenges of a deferred order (run --deferred), then create and install the certificate",
+		Before: func(ctx *cli.Context) error {
+			if len(ctx.StringSlice("domains")) == 0 {
+				fatalConfig("Please specify --domains/-d")
+			}
+
+			return nil
+		},
+		Action: continueOrder,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "no-bundle",
+				Usage: "Do not create a certificate bundle by adding the issuers certificate to the new certificate.",
+			},
+			&cli.BoolFlag{
+				Name:  "must-staple",
+				Usage: "Include the OCSP must staple TLS extension in the CSR and generated certificate.",
+			},
+			&cli.StringFlag{
+				Name: "preferred-chain",
+				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name." +
+					" If no match, the default offered chain will be used.",
+			},
+			&cli.BoolFlag{
+				Name:  "always-deactivate-authorizations",
+				Usage: "Force the authorizations to be relinquished even if the certificate request was successful.",
+			},
+			&cli.StringFlag{
+				Name:  "run-hook",
+				Usage: "Define a hook. The hook is executed when the certificates are effectively created.",
+			},
+			&cli.StringSliceFlag{
+				Name:  "label",
+				Usage: "Add a label (key=value) to the certificate metadata. Can be specified multiple times.",
+			},
+		},
+	}
+}
+
+// prepareDeferred creates an order without solving the challenges (run --deferred),
+// prints the values of the challenges, and saves the pending order for the continue command.
+func prepareDeferred(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage) error {
+	chlgType := challenge.Type(ctx.String("deferred.challenge"))
+	if chlgType != challenge.DNS01 && chlgType != challenge.HTTP01 {
+		fatalConfigf("Invalid --deferred.challenge: %s (supported: %s, %s)", chlgType, challenge.DNS01, challenge.HTTP01)
+	}
+
+	domains := ctx.StringSlice("domains")
+
+	request := certificate.ObtainRequest{
+		Domains:                        domains,
+		AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
+		NotBefore:                      getTime(ctx, "not-before"),
+		NotAfter:                       getTime(ctx, "not-after"),
+		Profile:                        ctx.String("profile"),
+	}
+
+	pending, err := client.Certificate.Prepare(request, chlgType)
+	if err != nil {
+		fatal(fmt.Errorf("could not create the deferred order:\n\t%w", err))
+	}
+
+	err = certsStorage.SavePendingOrder(domains[0], pending)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printPendingOrder(os.Stdout, pending, certsStorage.GetFileName(domains[0], pendingExt))
+
+	return nil
+}
+
+func continueOrder(ctx *cli.Context) error {
+	summary := newRunSummary(ctx, "continue")
+
+	accountsStorage := NewAccountsStorage(ctx)
+
+	account, client := setup(ctx, accountsStorage)
+
+	if account.Registration == nil {
+		fatalConfigf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
+	}
+
+	labels, err := parseLabels(ctx.StringSlice("label"))
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	deployConfig := loadDeployConfig(ctx)
+
+	certsStorage := NewCertificatesStorage(ctx)
+	certsStorage.CreateRootFolder()
+
+	domains := ctx.StringSlice("domains")
+
+	pending, err := certsStorage.ReadPendingOrder(domains[0])
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	request := certificate.ObtainRequest{
+		Domains:                        domains,
+		Bundle:                         !ctx.Bool("no-bundle"),
+		MustStaple:                     ctx.Bool("must-staple"),
+		PreferredChain:                 ctx.String("preferred-chain"),
+		AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
+	}
+
+	start := time.Now()
+
+	cert, err := client.Certificate.Continue(pending, request)
+	summary.phase("obtain", start)
+	if err != nil {
+		summary.addCertificate(domains[0], summaryFailed, "", err)
+		summary.write()
+
+		fatal(fmt.Errorf("could not obtain certificates:\n\t%w", err))
+	}
+
+	start = time.Now()
+
+	certResource := &CertificateResource{Resource: *cert, Account: accountsStorage.GetUserID(), Labels: labels}
+
+	certsStorage.SaveResource(certResource)
+
+	stapleOCSP(client.Certificate, certsStorage, cert.Domain)
+
+	if err = certsStorage.RemovePendingOrder(domains[0]); err != nil {
+		log.Warnf("[%s] Unable to remove the pending order: %v", domains[0], err)
+	}
+
+	summary.phase("save", start)
+	summary.addCertificate(cert.Domain, summaryObtained, cert.CertURL, nil)
+
+	deployCertificate(ctx, deployConfig, certsStorage, certResource, summary)
+
+	meta := map[string]string{
+		renewEnvAccountEmail: account.Email,
+		renewEnvAccountName:  accountsStorage.GetUserID(),
+		renewEnvCertDomain:   cert.Domain,
+		renewEnvCertPath:     certsStorage.GetFileName(cert.Domain, ".crt"),
+		renewEnvCertKeyPath:  certsStorage.GetFileName(cert.Domain, ".key"),
+		renewEnvCertPEMPath:  certsStorage.GetFileName(cert.Domain, ".pem"),
+		renewEnvCertPFXPath:  certsStorage.GetFileName(cert.Domain, ".pfx"),
+		renewEnvCertOCSPPath: certsStorage.GetFileName(cert.Domain, ocspExt),
+	}
+
+	start = time.Now()
+
+	err = launchHook(ctx.String("run-hook"), meta)
+
+	summary.phase("hook", start)
+	summary.write()
+
+	// The certificate has been obtained: a hook failure is a partial success.
+	return withExitCode(exitCodePartial, err)
+}
@@ -0,0 +1,653 @@
+package cmd
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pya789/lego/v4/acme/api"
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/lego"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/metrics"
+	"github.com/pya789/lego/v4/providers/deploy"
+	"github.com/urfave/cli/v2"
+)
+
+func createDaemon() *cli.Command {
+	return &cli.Command{
+		Name: "daemon",
+		Usage: "Keep running and renew the certificates of the data directory when needed" +
+			" (expiration or ARI window), with jitter, backoff on failures, hooks, and deployments.",
+		Action: daemon,
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "interval",
+				Value: 12 * time.Hour,
+				Usage: "The time between two checks of the certificates.",
+			},
+			&cli.DurationFlag{
+				Name:  "jitter",
+				Value: time.Hour,
+				Usage: "The maximum random delay added to the interval between two checks.",
+			},
+			&cli.IntFlag{
+				Name:  "days",
+				Value: 30,
+				Usage: "The number of days left on a certificate to renew it.",
+			},
+			&cli.BoolFlag{
+				Name:  "ari-enable",
+				Usage: "Use the renewalInfo endpoint (draft-ietf-acme-ari) to check if a certificate should be renewed.",
+			},
+			&cli.DurationFlag{
+				Name:  "backoff.initial",
+				Value: 10 * time.Minute,
+				Usage: "The delay before retrying the renewal of a certificate after a failure, doubled after each consecutive failure.",
+			},
+			&cli.DurationFlag{
+				Name:  "backoff.max",
+				Value: 12 * time.Hour,
+				Usage: "The maximum delay before retrying the renewal of a certificate after a failure.",
+			},
+			&cli.StringSliceFlag{
+				Name:    "selector",
+				Aliases: []string{"l"},
+				Usage:   "Only renew the certificates with the given labels (ex: team=infra,service=api).",
+			},
+			&cli.BoolFlag{
+				Name:  "reuse-key",
+				Usage: "Used to indicate you want to reuse your current private key for the renewed certificates.",
+			},
+			&cli.BoolFlag{
+				Name:  "no-bundle",
+				Usage: "Do not create a certificate bundle by adding the issuers certificate to the new certificate.",
+			},
+			&cli.StringFlag{
+				Name:  "renew-hook",
+				Usage: "Define a hook. The hook is executed only when the certificates are effectively renewed.",
+			},
+			&cli.BoolFlag{
+				Name: "check-revocation",
+				Usage: "Check the revocation status of the certificates (CRL, or OCSP) at each check." +
+					" A revoked certificate is renewed whatever the number of days left.",
+			},
+			&cli.BoolFlag{
+				Name: "ratelimit.enforce",
+				Usage: "Postpone the renewals which would exceed a rate limit of the CA (certificates per domain, duplicate certificates, orders per account)," +
+					" according to the certificates issued by lego (tracked in the storage) and the rate limit errors of the CA.",
+			},
+			&cli.StringSliceFlag{
+				Name: "maintenance-window",
+				Usage: "Define a CA maintenance window (start/end in RFC3339 format) during which renewals are postponed." +
+					" Can be specified multiple times.",
+			},
+			&cli.BoolFlag{
+				Name: "credentials.verify",
+				Usage: "Verify the credentials of the DNS provider at startup (the daemon stops if they are invalid), and at each check after the TTL." +
+					" The successful checks are cached in the storage.",
+			},
+			&cli.DurationFlag{
+				Name:  "credentials.ttl",
+				Value: 24 * time.Hour,
+				Usage: "The duration during which a successful check of the credentials of the DNS provider is not repeated.",
+			},
+			&cli.BoolFlag{
+				Name: "ct.monitor",
+				Usage: "Watch the CT logs for the certificates of the managed domains issued without lego (other accounts, other CAs)" +
+					" after the start of the monitoring. The unexpected certificates are logged, and reported to the CT hook.",
+			},
+			&cli.DurationFlag{
+				Name:  "ct.interval",
+				Value: 6 * time.Hour,
+				Usage: "The time between two searches in the CT logs.",
+			},
+			&cli.StringFlag{
+				Name:  "ct.url",
+				Value: defaultCTSearchURL,
+				Usage: "The URL of the service used to search the CT logs (crt.sh compatible JSON API).",
+			},
+			&cli.StringFlag{
+				Name:  "ct.hook",
+				Usage: "Define a hook executed for each unexpected certificate found in the CT logs.",
+			},
+			&cli.StringFlag{
+				Name:    "health.address",
+				EnvVars: []string{"LEGO_HEALTH_ADDRESS"},
+				Usage:   fmt.Sprintf("Serve the health endpoints (/healthz, /readyz) on this address (ex: %s).", defaultHealthAddress),
+			},
+			&cli.StringFlag{
+				Name:    "metrics.listen",
+				EnvVars: []string{"LEGO_METRICS_LISTEN"},
+				Usage:   "Serve the metrics in the Prometheus format (/metrics) on this address (ex: localhost:9798).",
+			},
+		},
+	}
+}
+
+// renewalDaemon renews the certificates of the data directory when needed.
+type renewalDaemon struct {
+	ctx          *cli.Context
+	certsStorage *CertificatesStorage
+	deployConfig *deploy.Config
+	selector     map[string]string
+	windows      []maintenanceWindow
+	health       *healthMonitor
+
+	// challenges the recording of the calls to the challenge providers, used to know the challenge type of the domains.
+	challenges *runSummary
+
+	client *lego.Client
+	meta   map[string]string
+
+	// limiter checks the renewals against the rate limits of the CA, and tracks the issued certificates.
+	limiter *issuanceLimiter
+
+	// failures the consecutive renewal failures (and the postponed renewals) of the certificates, by name.
+	failures map[string]*daemonFailure
+
+	// causes the number of failures by cause during the current check.
+	causes map[string]int
+}
+
+// daemonFailure the consecutive renewal failures of a certificate.
+// A renewal postponed by the CA (maintenance, rate limit) has a next attempt without failure.
+type daemonFailure struct {
+	count int
+	next  time.Time
+}
+
+func daemon(ctx *cli.Context) error {
+	selector, err := parseLabels(ctx.StringSlice("selector"))
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	if ctx.Duration("interval") <= 0 {
+		fatalConfigf("daemon: the interval must be greater than 0")
+	}
+
+	windows, err := parseMaintenanceWindows(ctx.StringSlice("maintenance-window"))
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	if ctx.Bool("credentials.verify") && !ctx.IsSet("dns") {
+		fatalConfigf("daemon: --credentials.verify requires a DNS provider (--dns)")
+	}
+
+	accountsStorage := NewAccountsStorage(ctx)
+
+	account, client := setup(ctx, accountsStorage)
+	challenges := newRunSummary(ctx, "daemon")
+	setupChallenges(ctx, client, challenges)
+
+	if account.Registration == nil {
+		fatalConfigf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
+	}
+
+	d := &renewalDaemon{
+		ctx:          ctx,
+		certsStorage: NewCertificatesStorage(ctx),
+		deployConfig: loadDeployConfig(ctx),
+		selector:     selector,
+		windows:      windows,
+		challenges:   challenges,
+		client:       client,
+		meta: map[string]string{
+			renewEnvAccountEmail: account.Email,
+			renewEnvAccountName:  accountsStorage.GetUserID(),
+		},
+		limiter:  newIssuanceLimiter(ctx, accountsStorage.GetUserID()),
+		failures: map[string]*daemonFailure{},
+	}
+
+	d.health = newHealthMonitor(func() error { return checkDirectory(ctx.String("server")) })
+
+	runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Invalid credentials stop the daemon at startup, instead of failing all the renewals.
+	if err = d.verifyCredentials(runCtx); err != nil {
+		log.Fatalf("daemon: invalid credentials of the DNS provider %s: %v", ctx.String("dns"), err)
+	}
+
+	if ctx.Bool("ct.monitor") {
+		if ctx.Duration("ct.interval") <= 0 {
+			fatalConfigf("daemon: the CT monitor interval must be greater than 0")
+		}
+
+		monitor := &ctMonitor{
+			certsStorage: d.certsStorage,
+			client:       &http.Client{Timeout: 2 * time.Minute},
+			searchURL:    ctx.String("ct.url"),
+			hook:         ctx.String("ct.hook"),
+		}
+
+		go monitor.run(runCtx, ctx.Duration("ct.interval"))
+	}
+
+	if address := ctx.String("health.address"); address != "" {
+		server := serveDaemonEndpoints("health endpoints", address, d.health.handler())
+		defer func() { _ = server.Close() }()
+	}
+
+	if address := ctx.String("metrics.listen"); address != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Default.Handler())
+
+		server := serveDaemonEndpoints("metrics", address, mux)
+		defer func() { _ = server.Close() }()
+	}
+
+	return d.run(runCtx)
+}
+
+// serveDaemonEndpoints serves the endpoints in the background: the errors are logged.
+func serveDaemonEndpoints(name, address string, handler http.Handler) *http.Server {
+	server := &http.Server{Addr: address, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warnf("daemon: %s: %v", name, err)
+		}
+	}()
+
+	return server
+}
+
+// run checks the certificates until the context is canceled.
+func (d *renewalDaemon) run(ctx context.Context) error {
+	log.Infof("daemon: started, checking the certificates every %s", d.ctx.Duration("interval"))
+
+	for {
+		d.check(ctx)
+
+		wait := nextCheckDelay(d.ctx.Duration("interval"), d.ctx.Duration("jitter"), d.nextRetry(time.Now()))
+
+		log.Infof("daemon: next check in %s", wait.Round(time.Second))
+
+		select {
+		case <-ctx.Done():
+			log.Infof("daemon: stopped")
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// check renews the certificates needing a renewal.
+// The errors are logged: the certificates are checked again at the next check.
+func (d *renewalDaemon) check(ctx context.Context) {
+	if window, ok := findMaintenanceWindow(d.windows, time.Now()); ok {
+		log.Infof("daemon: CA maintenance window in progress until %s: the renewals are postponed.", window.End.Format(time.RFC3339))
+		return
+	}
+
+	if err := d.verifyCredentials(ctx); err != nil {
+		log.Warnf("daemon: invalid credentials of the DNS provider %s: the renewals are postponed until the next check: %v", d.ctx.String("dns"), err)
+		return
+	}
+
+	entries, err := loadDashboardEntries(d.certsStorage, d.selector)
+	if err != nil {
+		log.Warnf("daemon: unable to load the certificates, retrying at the next check: %v", err)
+		return
+	}
+
+	d.causes = nil
+
+	defer func() {
+		if len(d.causes) > 0 {
+			log.Warnf("daemon: failure causes of the check: %s", formatFailureCauses(d.causes))
+		}
+	}()
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if d.challenges.metrics {
+			metrics.SetCertificateExpiry(entry.Name, entry.NotAfter)
+		}
+
+		now := time.Now()
+
+		if failure, ok := d.failures[entry.Name]; ok && now.Before(failure.next) {
+			log.Infof("[%s] daemon: renewal postponed (%d consecutive failure(s)), next attempt at %s", entry.Name, failure.count, failure.next.Format(time.RFC3339))
+			continue
+		}
+
+		// The renewals postponed by the rate limit of the CA are shared with the renew command (--ratelimit.resume).
+		resumed, postponed := d.checkRateLimited(entry.Name, now)
+		if postponed {
+			continue
+		}
+
+		revoked := d.ctx.Bool("check-revocation") && isRevoked(d.client, d.certsStorage, entry.Name)
+
+		if !d.shouldRenew(entry, now, resumed || revoked) {
+			stapleOCSP(d.client.Certificate, d.certsStorage, entry.Name)
+			continue
+		}
+
+		err = d.renew(ctx, entry)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			d.onFailure(entry.Name, now, err)
+			continue
+		}
+
+		d.health.recordRenewal(entry.Name, nil)
+
+		delete(d.failures, entry.Name)
+
+		clearRateLimited(d.certsStorage, entry.Name)
+	}
+}
+
+// verifyCredentials checks the credentials of the DNS provider (see the "credentials.verify" option):
+// a successful check is not repeated before the end of its TTL, even after a restart.
+func (d *renewalDaemon) verifyCredentials(ctx context.Context) error {
+	if !d.ctx.Bool("credentials.verify") || d.challenges.dnsProvider == nil {
+		return nil
+	}
+
+	return verifyProviderCredentials(ctx, d.certsStorage, d.ctx.String("dns"), d.challenges.dnsProvider, d.ctx.Duration("credentials.ttl"), time.Now())
+}
+
+// checkRateLimited checks if the renewal of the certificate has been postponed by the rate limit of the CA.
+// It returns postponed=true if the Retry-After window is not over,
+// and resumed=true if the window is over: the renewal must be done whatever the number of days left.
+func (d *renewalDaemon) checkRateLimited(name string, now time.Time) (resumed, postponed bool) {
+	renewal, err := d.certsStorage.ReadRateLimited(name)
+	if err != nil {
+		log.Warnf("[%s] daemon: %v", name, err)
+		return false, false
+	}
+
+	if renewal == nil {
+		return false, false
+	}
+
+	if now.Before(renewal.RetryAt) {
+		log.Infof("[%s] daemon: rate limited by the CA: the renewal is postponed until %s.", name, renewal.RetryAt.Format(time.RFC3339))
+
+		d.postpone(name, renewal.RetryAt)
+
+		return false, true
+	}
+
+	return true, false
+}
+
+// onFailure records the failure of a renewal.
+// The renewals refused by the CA (maintenance, rate limit) are postponed without being marked as failed.
+func (d *renewalDaemon) onFailure(name string, now time.Time, err error) {
+	if isMaintenanceError(err) {
+		next := now.Add(d.ctx.Duration("backoff.initial"))
+
+		log.Warnf("[%s] daemon: the CA is unavailable (maintenance?): the renewal is postponed until %s: %v", name, next.Format(time.RFC3339), err)
+
+		d.postpone(name, next)
+
+		return
+	}
+
+	if retryAfter, ok := getRateLimitRetryAfter(err); ok {
+		// The postponed renewal is saved to be also honored by the renew command, and after a restart.
+		postponeRateLimited(d.certsStorage, name, now, err)
+
+		d.postpone(name, now.Add(retryAfter))
+
+		return
+	}
+
+	d.health.recordRenewal(name, err)
+
+	causes := getFailureCauses(err)
+
+	d.causes = countFailureCauses(d.causes, causes)
+
+	// The causes are aggregated over time, to be displayed by the stats command.
+	recordFailureStats(d.certsStorage, name, causes, now)
+
+	failure := d.recordFailure(name, now)
+
+	log.Warnf("[%s] daemon: renewal failed, next attempt at %s: %v", name, failure.next.Format(time.RFC3339), err)
+
+	recordDashboardFailure(d.certsStorage, name, err)
+}
+
+// shouldRenew checks the expiration (or the ARI window) and the renewal windows of the certificate.
+// A forced renewal (revoked certificate, resumed renewal) ignores the expiration.
+func (d *renewalDaemon) shouldRenew(entry dashboardEntry, now time.Time, force bool) bool {
+	deadline := entry.NotAfter
+
+	renew := force || entry.NotAfter.Sub(now) < time.Duration(d.ctx.Int("days"))*24*time.Hour
+
+	if d.ctx.Bool("ari-enable") {
+		info, err := d.getRenewalInfo(entry.Name)
+
+		switch {
+		case errors.Is(err, api.ErrNoARI):
+			// The server does not advertise a renewal info endpoint: the expiration is used.
+
+		case err != nil:
+			log.Warnf("[%s] daemon: calling renewal info endpoint: %v", entry.Name, err)
+
+		default:
+			renew = renew || info.ShouldRenewAt(now, 0) != nil
+
+			if end := info.SuggestedWindow.End; !end.IsZero() && end.Before(deadline) {
+				deadline = end
+			}
+		}
+	}
+
+	if !renew {
+		return false
+	}
+
+	return checkRenewalSchedule(getRenewalSchedule(d.ctx, d.certsStorage, entry.Name), entry.Name, now, deadline)
+}
+
+func (d *renewalDaemon) getRenewalInfo(name string) (*certificate.RenewalInfoResponse, error) {
+	certBytes, err := d.certsStorage.ReadFile(name, certExt)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.client.Certificate.RenewalInfo(certificate.Resource{Domain: name, Certificate: certBytes, ARICertID: getStoredARICertID(d.certsStorage, name)})
+}
+
+// renew renews the certificate, saves it, deploys it, and runs the hook.
+func (d *renewalDaemon) renew(ctx context.Context, entry dashboardEntry) error {
+	certificates, err := d.certsStorage.ReadCertificate(entry.Name, certExt)
+	if err != nil {
+		return err
+	}
+
+	var privateKey crypto.PrivateKey
+	if d.ctx.Bool("reuse-key") {
+		keyBytes, errR := d.certsStorage.ReadFile(entry.Name, keyExt)
+		if errR != nil {
+			return errR
+		}
+
+		privateKey, errR = certcrypto.ParsePEMPrivateKey(keyBytes)
+		if errR != nil {
+			return errR
+		}
+	}
+
+	log.Infof("[%s] daemon: renewing the certificate (expires at %s)", entry.Name, entry.NotAfter.Format(time.RFC3339))
+
+	request := certificate.ObtainRequest{
+		Domains:    certcrypto.ExtractDomains(certificates[0]),
+		PrivateKey: privateKey,
+		Bundle:     !d.ctx.Bool("no-bundle"),
+	}
+
+	if d.ctx.Bool("ari-enable") {
+		request.ReplacesCertID, err = getARICertID(d.certsStorage, entry.Name, certificates[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.challenges.metrics {
+		request.Progress = metrics.Progress(d.challenges.challengeType, nil)
+	}
+
+	certRes, err := d.limiter.obtain(ctx, entry.Name, request.Domains, func() (*certificate.Resource, error) {
+		return d.client.Certificate.ObtainContext(ctx, request)
+	})
+	if err != nil {
+		return err
+	}
+
+	if d.challenges.metrics {
+		if cert, errP := certcrypto.ParsePEMCertificate(certRes.Certificate); errP == nil {
+			metrics.SetCertificateExpiry(entry.Name, cert.NotAfter)
+		}
+	}
+
+	summary := newRunSummary(d.ctx, "daemon")
+
+	certResource := &CertificateResource{
+		Resource:    *certRes,
+		Account:     d.meta[renewEnvAccountName],
+		Labels:      getLabels(d.ctx, d.certsStorage, entry.Name),
+		Deployments: getDeployments(d.certsStorage, entry.Name),
+		Schedule:    getScheduleWindows(d.ctx, d.certsStorage, entry.Name),
+	}
+
+	d.certsStorage.SaveResource(certResource)
+
+	stapleOCSP(d.client.Certificate, d.certsStorage, entry.Name)
+
+	summary.addCertificate(entry.Name, summaryRenewed, certRes.CertURL, nil)
+
+	deployCertificate(d.ctx, d.deployConfig, d.certsStorage, certResource, summary)
+
+	summary.write()
+
+	meta := map[string]string{}
+	for k, v := range d.meta {
+		meta[k] = v
+	}
+
+	addPathToMetadata(meta, entry.Name, certRes, d.certsStorage)
+
+	err = launchHook(d.ctx.String("renew-hook"), meta)
+	if err != nil {
+		// The certificate has been renewed: the hook is not retried.
+		log.Warnf("[%s] daemon: %v", entry.Name, err)
+	}
+
+	return nil
+}
+
+// recordFailure records a renewal failure, and computes the time of the next attempt.
+func (d *renewalDaemon) recordFailure(name string, now time.Time) *daemonFailure {
+	failure, ok := d.failures[name]
+	if !ok {
+		failure = &daemonFailure{}
+		d.failures[name] = failure
+	}
+
+	failure.count++
+	failure.next = now.Add(backoffDelay(failure.count, d.ctx.Duration("backoff.initial"), d.ctx.Duration("backoff.max")))
+
+	return failure
+}
+
+// postpone sets the time of the next attempt of a renewal, without counting a failure.
+func (d *renewalDaemon) postpone(name string, next time.Time) {
+	failure, ok := d.failures[name]
+	if !ok {
+		failure = &daemonFailure{}
+		d.failures[name] = failure
+	}
+
+	failure.next = next
+}
+
+// nextRetry returns the time of the next retry of a failed or postponed renewal,
+// or the end of the current maintenance window (zero if there is none).
+func (d *renewalDaemon) nextRetry(now time.Time) time.Time {
+	var next time.Time
+
+	if window, ok := findMaintenanceWindow(d.windows, now); ok {
+		next = window.End
+	}
+
+	for _, failure := range d.failures {
+		if failure.next.After(now) && (next.IsZero() || failure.next.Before(next)) {
+			next = failure.next
+		}
+	}
+
+	return next
+}
+
+// backoffDelay returns the delay before the next attempt after consecutive failures:
+// the initial delay doubled after each failure, up to the maximum delay.
+func backoffDelay(failures int, initial, maximum time.Duration) time.Duration {
+	delay := initial
+
+	for i := 1; i < failures && delay < maximum; i++ {
+		delay *= 2
+	}
+
+	if maximum > 0 && delay > maximum {
+		return maximum
+	}
+
+	return delay
+}
+
+// nextCheckDelay returns the delay before the next check: the interval with a random jitter,
+// or the delay before the next retry of a failed renewal if it's earlier.
+func nextCheckDelay(interval, jitter time.Duration, nextRetry time.Time) time.Duration {
+	delay := interval + renewalJitter("", jitter, false)
+
+	if !nextRetry.IsZero() {
+		if untilRetry := time.Until(nextRetry); untilRetry < delay {
+			return max(untilRetry, time.Second)
+		}
+	}
+
+	return delay
+}
+
+// checkDirectory checks that the directory of the CA is reachable.
+func checkDirectory(server string) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	resp, err := client.Get(server)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_backoffDelay(t *testing.T) {
+	testCases := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 1, expected: 10 * time.Minute},
+		{failures: 2, expected: 20 * time.Minute},
+		{failures: 3, expected: 40 * time.Minute},
+		{failures: 5, expected: 2 * time.Hour},
+		{failures: 50, expected: 2 * time.Hour},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, backoffDelay(test.failures, 10*time.Minute, 2*time.Hour), "failures: %d", test.failures)
+	}
+}
+
+func Test_nextCheckDelay(t *testing.T) {
+	delay := nextCheckDelay(time.Hour, 10*time.Minute, time.Time{})
+	assert.GreaterOrEqual(t, delay, time.Hour)
+	assert.Less(t, delay, time.Hour+10*time.Minute)
+
+	// a failed renewal is retried before the next check.
+	delay = nextCheckDelay(time.Hour, 0, time.Now().Add(10*time.Minute))
+	assert.LessOrEqual(t, delay, 10*time.Minute)
+	assert.Greater(t, delay, 9*time.Minute)
+
+	assert.Equal(t, time.Hour, nextCheckDelay(time.Hour, 0, time.Now().Add(2*time.Hour)))
+}
+
+func Test_renewalDaemon_onFailure_rateLimited(t *testing.T) {
+	d := &renewalDaemon{
+		certsStorage: newTestCertificatesStorage(t),
+		failures:     map[string]*daemonFailure{},
+	}
+
+	now := time.Now().UTC()
+
+	err := &acme.RateLimitedError{
+		ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr, HTTPStatus: 429},
+		RetryAfter:     2 * time.Hour,
+	}
+
+	d.onFailure("example.com", now, err)
+
+	// the Retry-After window of the CA is honored, without counting a failure.
+	require.Contains(t, d.failures, "example.com")
+	assert.Equal(t, 0, d.failures["example.com"].count)
+	assert.Equal(t, now.Add(2*time.Hour), d.failures["example.com"].next)
+
+	// the postponed renewal is saved.
+	resumed, postponed := d.checkRateLimited("example.com", now.Add(time.Hour))
+	assert.False(t, resumed)
+	assert.True(t, postponed)
+
+	resumed, postponed = d.checkRateLimited("example.com", now.Add(3*time.Hour))
+	assert.True(t, resumed)
+	assert.False(t, postponed)
+}
+
+func Test_renewalDaemon_nextRetry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d := &renewalDaemon{
+		windows: []maintenanceWindow{
+			{Start: now.Add(-time.Hour), End: now.Add(3 * time.Hour)},
+		},
+		failures: map[string]*daemonFailure{},
+	}
+
+	// the end of the maintenance window.
+	assert.Equal(t, now.Add(3*time.Hour), d.nextRetry(now))
+
+	d.postpone("example.com", now.Add(2*time.Hour))
+	assert.Equal(t, now.Add(2*time.Hour), d.nextRetry(now))
+
+	// no maintenance window in progress.
+	assert.Equal(t, now.Add(2*time.Hour), d.nextRetry(now.Add(-2*time.Hour)))
+	assert.True(t, d.nextRetry(now.Add(4*time.Hour)).IsZero())
+}
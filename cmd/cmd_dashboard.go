@@ -0,0 +1,434 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/lego"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	dashboardStatusOK      = "ok"
+	dashboardStatusRenew   = "renew"
+	dashboardStatusFailing = "failing"
+	dashboardStatusExpired = "expired"
+)
+
+func createDashboard() *cli.Command {
+	return &cli.Command{
+		Name: "dashboard",
+		Usage: "Interactive terminal dashboard listing the certificates, their expiry countdown and their last renewal error." +
+			" The certificates can be renewed from the dashboard.",
+		Action: dashboard,
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "days",
+				Value: 30,
+				Usage: "The number of days left on a certificate to flag it for renewal.",
+			},
+			&cli.StringSliceFlag{
+				Name:    "selector",
+				Aliases: []string{"l"},
+				Usage:   "Display only the certificates with the given labels (ex: team=infra,service=api).",
+			},
+			&cli.BoolFlag{
+				Name:  "reuse-key",
+				Usage: "Used to indicate you want to reuse your current private key for the renewed certificates.",
+			},
+			&cli.BoolFlag{
+				Name:  "no-bundle",
+				Usage: "Do not create a certificate bundle by adding the issuers certificate to the new certificate.",
+			},
+		},
+	}
+}
+
+// dashboardEntry a certificate displayed by the dashboard.
+type dashboardEntry struct {
+	Name      string
+	Domains   []string
+	NotAfter  time.Time
+	Failures  int
+	LastError string
+}
+
+func (e dashboardEntry) status(now time.Time, days int) string {
+	switch {
+	case !e.NotAfter.After(now):
+		return dashboardStatusExpired
+	case e.Failures > 0:
+		return dashboardStatusFailing
+	case e.NotAfter.Sub(now) < time.Duration(days)*24*time.Hour:
+		return dashboardStatusRenew
+	default:
+		return dashboardStatusOK
+	}
+}
+
+// dashboardUI the state of the dashboard.
+type dashboardUI struct {
+	ctx          *cli.Context
+	certsStorage *CertificatesStorage
+	selector     map[string]string
+	days         int
+
+	in  *bufio.Scanner
+	out io.Writer
+	// clear true if the screen is cleared before each rendering (terminal).
+	clear bool
+	// message the result of the last action.
+	message string
+
+	// client created on the first renewal.
+	client      *lego.Client
+	accountName string
+}
+
+func dashboard(ctx *cli.Context) error {
+	selector, err := parseLabels(ctx.StringSlice("selector"))
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	ui := &dashboardUI{
+		ctx:          ctx,
+		certsStorage: NewCertificatesStorage(ctx),
+		selector:     selector,
+		days:         ctx.Int("days"),
+		in:           bufio.NewScanner(os.Stdin),
+		out:          os.Stdout,
+		clear:        isTerminal(os.Stdout),
+	}
+
+	return ui.run()
+}
+
+func (d *dashboardUI) run() error {
+	for {
+		entries, err := loadDashboardEntries(d.certsStorage, d.selector)
+		if err != nil {
+			return err
+		}
+
+		if d.clear {
+			_, _ = fmt.Fprint(d.out, "\033[H\033[2J")
+		}
+
+		renderDashboard(d.out, entries, time.Now(), d.days)
+
+		if d.message != "" {
+			_, _ = fmt.Fprintf(d.out, "\n%s\n", d.message)
+			d.message = ""
+		}
+
+		_, _ = fmt.Fprint(d.out, "\n[r <#|name>] renew  [a] renew the flagged certificates  [enter] refresh  [q] quit\n> ")
+
+		if !d.in.Scan() {
+			return d.in.Err()
+		}
+
+		fields := strings.Fields(d.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "q", "quit":
+			return nil
+
+		case "r", "renew":
+			if len(fields) != 2 {
+				d.message = "Usage: r <#|name>"
+				continue
+			}
+
+			entry, ok := findDashboardEntry(entries, fields[1])
+			if !ok {
+				d.message = fmt.Sprintf("Unknown certificate: %s", fields[1])
+				continue
+			}
+
+			d.renew(entry)
+
+		case "a", "all":
+			now := time.Now()
+
+			var results []string
+			for _, entry := range entries {
+				if entry.status(now, d.days) == dashboardStatusOK {
+					continue
+				}
+
+				d.renew(entry)
+				results = append(results, d.message)
+			}
+
+			d.message = strings.Join(results, "\n")
+			if d.message == "" {
+				d.message = "No certificate to renew."
+			}
+
+		default:
+			d.message = fmt.Sprintf("Unknown command: %s", fields[0])
+		}
+	}
+}
+
+// renew renews the certificate and displays the progress of the renewal.
+// The result is stored in the message of the dashboard, and the failures in the certificate metadata.
+func (d *dashboardUI) renew(entry dashboardEntry) {
+	_, _ = fmt.Fprintf(d.out, "\nRenewing %s...\n", entry.Name)
+
+	certRes, err := d.obtain(entry)
+	if err != nil {
+		recordDashboardFailure(d.certsStorage, entry.Name, err)
+
+		d.message = fmt.Sprintf("[%s] renewal failed: %v", entry.Name, err)
+
+		return
+	}
+
+	summary := newRunSummary(d.ctx, "dashboard")
+
+	certResource := &CertificateResource{
+		Resource:    *certRes,
+		Account:     d.accountName,
+		Labels:      getLabels(d.ctx, d.certsStorage, entry.Name),
+		Deployments: getDeployments(d.certsStorage, entry.Name),
+		Schedule:    getScheduleWindows(d.ctx, d.certsStorage, entry.Name),
+	}
+
+	d.certsStorage.SaveResource(certResource)
+
+	stapleOCSP(d.getClient().Certificate, d.certsStorage, entry.Name)
+
+	deployCertificate(d.ctx, loadDeployConfig(d.ctx), d.certsStorage, certResource, summary)
+
+	summary.addCertificate(entry.Name, summaryRenewed, certRes.CertURL, nil)
+	summary.write()
+
+	d.message = fmt.Sprintf("[%s] renewed.", entry.Name)
+}
+
+func (d *dashboardUI) obtain(entry dashboardEntry) (*certificate.Resource, error) {
+	certificates, err := d.certsStorage.ReadCertificate(entry.Name, certExt)
+	if err != nil {
+		return nil, err
+	}
+
+	var privateKey crypto.PrivateKey
+	if d.ctx.Bool("reuse-key") {
+		keyBytes, errR := d.certsStorage.ReadFile(entry.Name, keyExt)
+		if errR != nil {
+			return nil, errR
+		}
+
+		privateKey, errR = certcrypto.ParsePEMPrivateKey(keyBytes)
+		if errR != nil {
+			return nil, errR
+		}
+	}
+
+	request := certificate.ObtainRequest{
+		Domains:    certcrypto.ExtractDomains(certificates[0]),
+		PrivateKey: privateKey,
+		Bundle:     !d.ctx.Bool("no-bundle"),
+		Progress: func(event certificate.ProgressEvent) {
+			printProgress(d.out, entry.Name, event)
+		},
+	}
+
+	return d.getClient().Certificate.Obtain(request)
+}
+
+func (d *dashboardUI) getClient() *lego.Client {
+	if d.client != nil {
+		return d.client
+	}
+
+	accountsStorage := NewAccountsStorage(d.ctx)
+
+	account, client := setup(d.ctx, accountsStorage)
+	setupChallenges(d.ctx, client, newRunSummary(d.ctx, "dashboard"))
+
+	if account.Registration == nil {
+		fatalConfigf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
+	}
+
+	d.client = client
+	d.accountName = accountsStorage.GetUserID()
+
+	return d.client
+}
+
+// loadDashboardEntries reads the certificates and their metadata from the storage.
+func loadDashboardEntries(certsStorage *CertificatesStorage, selector map[string]string) ([]dashboardEntry, error) {
+	files, err := certsStorage.ListFiles(certExt)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []dashboardEntry
+
+	for _, file := range files {
+		data, err := certsStorage.ReadFile(file, certExt)
+		if err != nil {
+			return nil, err
+		}
+
+		cert, err := certcrypto.ParsePEMCertificate(data)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := certcrypto.GetCertificateMainDomain(cert)
+		if err != nil {
+			return nil, err
+		}
+
+		var resource CertificateResource
+		if certsStorage.ExistsFile(name, resourceExt) {
+			resource = certsStorage.ReadResource(name)
+		}
+
+		if !matchLabels(resource.Labels, selector) {
+			continue
+		}
+
+		entry := dashboardEntry{
+			Name:     name,
+			Domains:  certcrypto.ExtractDomains(cert),
+			NotAfter: cert.NotAfter,
+		}
+
+		if resource.Failures != nil {
+			entry.Failures = resource.Failures.Count
+			entry.LastError = resource.Failures.LastError
+		}
+
+		entries = append(entries, entry)
+	}
+
+	// The certificates expiring first are displayed first.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].NotAfter.Before(entries[j].NotAfter)
+	})
+
+	return entries, nil
+}
+
+func renderDashboard(w io.Writer, entries []dashboardEntry, now time.Time, days int) {
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(w, "No certificates found.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "#\tNAME\tDOMAINS\tEXPIRES\tREMAINING\tSTATUS\tLAST ERROR")
+
+	for i, entry := range entries {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			i+1, entry.Name, strings.Join(entry.Domains, ","), entry.NotAfter.UTC().Format(time.RFC3339),
+			formatCountdown(entry.NotAfter.Sub(now)), entry.status(now, days), truncate(entry.LastError, 60))
+	}
+
+	_ = tw.Flush()
+}
+
+// findDashboardEntry finds an entry by its number (starting at 1) or its name.
+func findDashboardEntry(entries []dashboardEntry, ref string) (dashboardEntry, bool) {
+	if i, err := strconv.Atoi(ref); err == nil {
+		if i < 1 || i > len(entries) {
+			return dashboardEntry{}, false
+		}
+
+		return entries[i-1], true
+	}
+
+	for _, entry := range entries {
+		if entry.Name == ref {
+			return entry, true
+		}
+	}
+
+	return dashboardEntry{}, false
+}
+
+// recordDashboardFailure records the renewal failure in the certificate metadata, to display it as the last error.
+func recordDashboardFailure(certsStorage *CertificatesStorage, name string, err error) {
+	if !certsStorage.ExistsFile(name, resourceExt) {
+		return
+	}
+
+	resource := certsStorage.ReadResource(name)
+
+	if resource.Failures == nil {
+		resource.Failures = &renewalFailures{}
+	}
+
+	resource.Failures.Count++
+	resource.Failures.LastError = err.Error()
+	resource.Failures.LastAttempt = time.Now().UTC()
+
+	certsStorage.SaveResourceMetadata(&resource)
+}
+
+func printProgress(w io.Writer, name string, event certificate.ProgressEvent) {
+	switch {
+	case event.Phase == certificate.PhaseDone:
+		return
+	case event.Domain == "":
+		_, _ = fmt.Fprintf(w, "[%s] %s\n", name, event.Phase)
+	case event.Err != nil:
+		_, _ = fmt.Fprintf(w, "[%s]   %s: %s: %v\n", name, event.Domain, event.Status, event.Err)
+	default:
+		_, _ = fmt.Fprintf(w, "[%s]   %s: %s\n", name, event.Domain, event.Status)
+	}
+}
+
+// formatCountdown formats the remaining validity of a certificate (ex: 12d 4h).
+func formatCountdown(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+
+	if days == 0 {
+		return fmt.Sprintf("%dh %dm", hours, int(d.Minutes())%60)
+	}
+
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
+
+func truncate(s string, size int) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	if len(s) <= size {
+		return s
+	}
+
+	return s[:size-3] + "..."
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
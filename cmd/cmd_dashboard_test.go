@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_renderDashboard(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entries := []dashboardEntry{
+		{Name: "expired.com", Domains: []string{"expired.com"}, NotAfter: now.Add(-time.Hour)},
+		{Name: "failing.com", Domains: []string{"failing.com"}, NotAfter: now.Add(10 * time.Hour), Failures: 2, LastError: "acme: error: 429"},
+		{Name: "soon.com", Domains: []string{"soon.com", "www.soon.com"}, NotAfter: now.Add(10*24*time.Hour + 4*time.Hour)},
+		{Name: "ok.com", Domains: []string{"ok.com"}, NotAfter: now.Add(60 * 24 * time.Hour)},
+	}
+
+	buf := new(bytes.Buffer)
+
+	renderDashboard(buf, entries, now, 30)
+
+	expected := `#  NAME         DOMAINS                EXPIRES               REMAINING  STATUS   LAST ERROR
+1  expired.com  expired.com            2023-12-31T23:00:00Z  -          expired  
+2  failing.com  failing.com            2024-01-01T10:00:00Z  10h 0m     failing  acme: error: 429
+3  soon.com     soon.com,www.soon.com  2024-01-11T04:00:00Z  10d 4h     renew    
+4  ok.com       ok.com                 2024-03-01T00:00:00Z  60d 0h     ok       
+`
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func Test_findDashboardEntry(t *testing.T) {
+	entries := []dashboardEntry{{Name: "a.com"}, {Name: "b.com"}}
+
+	testCases := []struct {
+		desc     string
+		ref      string
+		expected string
+		found    bool
+	}{
+		{desc: "by number", ref: "2", expected: "b.com", found: true},
+		{desc: "by name", ref: "a.com", expected: "a.com", found: true},
+		{desc: "number out of range", ref: "3"},
+		{desc: "zero", ref: "0"},
+		{desc: "unknown name", ref: "c.com"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			entry, ok := findDashboardEntry(entries, test.ref)
+
+			assert.Equal(t, test.found, ok)
+			assert.Equal(t, test.expected, entry.Name)
+		})
+	}
+}
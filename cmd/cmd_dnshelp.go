@@ -6,6 +6,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/pya789/lego/v4/providers/dns"
 	"github.com/urfave/cli/v2"
 )
 
@@ -39,6 +40,13 @@ func dnsHelp(ctx *cli.Context) error {
 		ew.writeln("Supported DNS providers:")
 		ew.writef("\t%s\n", allDNSCodes())
 		ew.writeln()
+
+		if registered := dns.RegisteredProviders(); len(registered) > 0 {
+			ew.writeln("Registered DNS providers:")
+			ew.writef("\t%s\n", strings.Join(registered, ", "))
+			ew.writeln()
+		}
+
 		ew.writeln("More information: https://go-acme.github.io/lego/dns")
 
 		if ew.err != nil {
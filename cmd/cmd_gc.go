@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+func createGC() *cli.Command {
+	return &cli.Command{
+		Name: "gc",
+		Usage: "Remove the DNS records left by the deferred clean-up (--dns.deferred-cleanup)." +
+			" The DNS provider is created with the clean-up credentials (--dns.cleanup-env-prefix).",
+		Before: func(ctx *cli.Context) error {
+			if ctx.String("dns") == "" || ctx.String("dns") == "manual" {
+				fatalConfig("Please specify the DNS provider with --dns")
+			}
+
+			return nil
+		},
+		Action: collectDNSRecords,
+	}
+}
+
+func collectDNSRecords(ctx *cli.Context) error {
+	provider, err := newDNSCleanupProvider(ctx)
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	journal := newDNSCleanupJournal(ctx)
+
+	removed, err := journal.collect(provider)
+
+	log.Infof("gc: %d DNS record(s) removed.", removed)
+
+	if err != nil {
+		fatal(fmt.Errorf("gc: some DNS records cannot be removed, they are kept in %s:\n%w", journal.filename, err))
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+const defaultHealthAddress = "localhost:9797"
+
+func createHealth() *cli.Command {
+	return &cli.Command{
+		Name:   "health",
+		Usage:  "Query the health endpoints of a running lego daemon. Exits with a non-zero code if the daemon is not healthy.",
+		Action: health,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "address",
+				Usage:   "The address of the health endpoints of the daemon.",
+				EnvVars: []string{"LEGO_HEALTH_ADDRESS"},
+				Value:   defaultHealthAddress,
+			},
+			&cli.BoolFlag{
+				Name:  "liveness",
+				Usage: "Query the liveness endpoint (/healthz) instead of the readiness endpoint (/readyz).",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "The timeout of the request.",
+				Value: 10 * time.Second,
+			},
+		},
+	}
+}
+
+func health(ctx *cli.Context) error {
+	endpoint := "/readyz"
+	if ctx.Bool("liveness") {
+		endpoint = "/healthz"
+	}
+
+	client := &http.Client{Timeout: ctx.Duration("timeout")}
+
+	resp, err := client.Get("http://" + ctx.String("address") + endpoint)
+	if err != nil {
+		log.Fatalf("Unable to query the daemon: %v", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("Unable to read the response of the daemon: %v", err)
+	}
+
+	var status healthStatus
+	if err = json.Unmarshal(raw, &status); err != nil {
+		log.Fatalf("Unable to parse the response of the daemon: %v", err)
+	}
+
+	fmt.Println("Status:", status.Status)
+
+	if status.LastRenewal != nil {
+		fmt.Printf("Last renewal: %s (%s)\n", status.LastRenewal.Format(time.RFC3339), status.LastRenewalStatus)
+	}
+
+	if status.ACME != "" {
+		fmt.Println("ACME:", status.ACME)
+	}
+
+	for domain, msg := range status.PendingFailures {
+		fmt.Printf("Pending failure: %s: %s\n", domain, msg)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatal("The daemon is not healthy.")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pya789/lego/v4/inventory"
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+func createInventory() *cli.Command {
+	return &cli.Command{
+		Name:   "inventory",
+		Usage:  "Display the domains read from external inventory sources.",
+		Action: listInventory,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "source",
+				Aliases:  []string{"s"},
+				Usage:    "Inventory source: 'file:<glob>', 'kubernetes[:<namespace>]', 'traefik:<glob>', 'caddy:<path>', 'consul:<address>'.",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:    "names",
+				Aliases: []string{"n"},
+				Usage:   "Display the domains only (one certificate by line, the domains are separated by commas).",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Display the entries as JSON.",
+			},
+		},
+	}
+}
+
+func listInventory(ctx *cli.Context) error {
+	var sources []inventory.Source
+
+	for _, spec := range ctx.StringSlice("source") {
+		src, err := inventory.NewSource(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		sources = append(sources, src)
+	}
+
+	entries, err := inventory.Collect(ctx.Context, sources...)
+	if err != nil {
+		// The entries of the other sources are still displayed.
+		log.Warnf("inventory: %v", err)
+	}
+
+	switch {
+	case ctx.Bool("json"):
+		return json.NewEncoder(os.Stdout).Encode(entries)
+
+	case ctx.Bool("names"):
+		for _, entry := range entries {
+			fmt.Println(strings.Join(entry.Domains, ","))
+		}
+
+	default:
+		if len(entries) == 0 {
+			fmt.Println("No domains found.")
+			return nil
+		}
+
+		fmt.Println("Found the following domains:")
+
+		for _, entry := range entries {
+			fmt.Printf("  Domains: %s\n", strings.Join(entry.Domains, ", "))
+			fmt.Printf("    Source: %s (%s)\n", entry.Source, entry.Name)
+		}
+	}
+
+	return nil
+}
@@ -4,8 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
 
 	"github.com/pya789/lego/v4/certcrypto"
@@ -28,6 +27,11 @@ func createList() *cli.Command {
 				Aliases: []string{"n"},
 				Usage:   "Display certificate common names only.",
 			},
+			&cli.StringSliceFlag{
+				Name:    "selector",
+				Aliases: []string{"l"},
+				Usage:   "Display only the certificates with the given labels (ex: team=infra,service=api).",
+			},
 			// fake email, needed by NewAccountsStorage
 			&cli.StringFlag{
 				Name:   "email",
@@ -51,14 +55,19 @@ func list(ctx *cli.Context) error {
 func listCertificates(ctx *cli.Context) error {
 	certsStorage := NewCertificatesStorage(ctx)
 
-	matches, err := filepath.Glob(filepath.Join(certsStorage.GetRootPath(), "*.crt"))
+	files, err := certsStorage.ListFiles(certExt)
 	if err != nil {
 		return err
 	}
 
 	names := ctx.Bool("names")
 
-	if len(matches) == 0 {
+	selector, err := parseLabels(ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
 		if !names {
 			fmt.Println("No certificates found.")
 		}
@@ -69,12 +78,8 @@ func listCertificates(ctx *cli.Context) error {
 		fmt.Println("Found the following certs:")
 	}
 
-	for _, filename := range matches {
-		if strings.HasSuffix(filename, ".issuer.crt") {
-			continue
-		}
-
-		data, err := os.ReadFile(filename)
+	for _, file := range files {
+		data, err := certsStorage.ReadFile(file, certExt)
 		if err != nil {
 			return err
 		}
@@ -89,13 +94,43 @@ func listCertificates(ctx *cli.Context) error {
 			return err
 		}
 
+		var resource CertificateResource
+		if certsStorage.ExistsFile(name, resourceExt) {
+			resource = certsStorage.ReadResource(name)
+		}
+
+		if !matchLabels(resource.Labels, selector) {
+			continue
+		}
+
 		if names {
 			fmt.Println(name)
 		} else {
 			fmt.Println("  Certificate Name:", name)
 			fmt.Println("    Domains:", strings.Join(pCert.DNSNames, ", "))
 			fmt.Println("    Expiry Date:", pCert.NotAfter)
-			fmt.Println("    Certificate Path:", filename)
+			fmt.Println("    Certificate Path:", certsStorage.GetFileName(file, certExt))
+
+			if resource.Account != "" {
+				fmt.Println("    Account:", resource.Account)
+			}
+
+			if len(resource.Labels) > 0 {
+				fmt.Println("    Labels:", formatLabels(resource.Labels))
+			}
+
+			if len(resource.Schedule) > 0 {
+				fmt.Println("    Renewal Windows:", strings.Join(resource.Schedule, ", "))
+			}
+
+			if resource.OrderURL != "" {
+				fmt.Println("    Order URL:", resource.OrderURL)
+			}
+
+			if resource.ARICertID != "" {
+				fmt.Println("    ARI CertID:", resource.ARICertID)
+			}
+
 			fmt.Println()
 		}
 	}
@@ -106,19 +141,19 @@ func listCertificates(ctx *cli.Context) error {
 func listAccount(ctx *cli.Context) error {
 	accountsStorage := NewAccountsStorage(ctx)
 
-	matches, err := filepath.Glob(filepath.Join(accountsStorage.GetRootPath(), "*", "*", "*.json"))
+	files, err := accountsStorage.ListAccountFiles()
 	if err != nil {
 		return err
 	}
 
-	if len(matches) == 0 {
+	if len(files) == 0 {
 		fmt.Println("No accounts found.")
 		return nil
 	}
 
 	fmt.Println("Found the following accounts:")
-	for _, filename := range matches {
-		data, err := os.ReadFile(filename)
+	for _, file := range files {
+		data, err := accountsStorage.store.Get(ctx.Context, file)
 		if err != nil {
 			return err
 		}
@@ -134,9 +169,10 @@ func listAccount(ctx *cli.Context) error {
 			return err
 		}
 
+		fmt.Println("  Name:", path.Base(path.Dir(file)))
 		fmt.Println("  Email:", account.Email)
 		fmt.Println("  Server:", uri.Host)
-		fmt.Println("  Path:", filepath.Dir(filename))
+		fmt.Println("  Path:", storagePath(accountsStorage.store, path.Dir(file)))
 		fmt.Println()
 	}
 
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func createPreAuth() *cli.Command {
+	return &cli.Command{
+		Name: "preauth",
+		Usage: "Validate the domains ahead of time (pre-authorization), if supported by the CA." +
+			" The next certificates for these domains are obtained without solving the challenges, until the authorizations expire.",
+		Before: func(ctx *cli.Context) error {
+			if len(ctx.StringSlice("domains")) == 0 {
+				fatalConfig("Please specify --domains/-d")
+			}
+
+			return nil
+		},
+		Action: preAuthorize,
+	}
+}
+
+func preAuthorize(ctx *cli.Context) error {
+	summary := newRunSummary(ctx, "preauth")
+
+	accountsStorage := NewAccountsStorage(ctx)
+
+	account, client := setup(ctx, accountsStorage)
+	setupChallenges(ctx, client, summary)
+
+	if account.Registration == nil {
+		fatalConfigf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
+	}
+
+	if !client.SupportsPreAuthorization() {
+		fatalConfigf("The CA (%s) doesn't support the pre-authorization.", ctx.String("server"))
+	}
+
+	results, err := client.Certificate.PreAuthorize(ctx.StringSlice("domains"))
+
+	if len(results) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(w, "DOMAIN\tSTATUS\tEXPIRES")
+
+		for _, result := range results {
+			var expires string
+			if !result.Expires.IsZero() {
+				expires = result.Expires.Format(time.RFC3339)
+			}
+
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", result.Domain, result.Status, expires)
+		}
+
+		_ = w.Flush()
+	}
+
+	if err != nil {
+		fatal(fmt.Errorf("could not pre-authorize the domains:\n\t%w", err))
+	}
+
+	return nil
+}
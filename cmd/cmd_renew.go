@@ -4,27 +4,32 @@ import (
 	"crypto"
 	"crypto/x509"
 	"errors"
-	"math/rand"
+	"hash/fnv"
 	"os"
 	"time"
 
 	"github.com/pya789/lego/v4/acme/api"
 	"github.com/pya789/lego/v4/certcrypto"
 	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/lego"
 	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/platform/random"
+	"github.com/pya789/lego/v4/providers/deploy"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
 )
 
 const (
 	renewEnvAccountEmail      = "LEGO_ACCOUNT_EMAIL"
+	renewEnvAccountName       = "LEGO_ACCOUNT_NAME"
 	renewEnvCertDomain        = "LEGO_CERT_DOMAIN"
 	renewEnvCertPath          = "LEGO_CERT_PATH"
 	renewEnvCertKeyPath       = "LEGO_CERT_KEY_PATH"
 	renewEnvIssuerCertKeyPath = "LEGO_ISSUER_CERT_PATH"
 	renewEnvCertPEMPath       = "LEGO_CERT_PEM_PATH"
 	renewEnvCertPFXPath       = "LEGO_CERT_PFX_PATH"
+	renewEnvCertOCSPPath      = "LEGO_CERT_OCSP_PATH"
 )
 
 func createRenew() *cli.Command {
@@ -37,10 +42,10 @@ func createRenew() *cli.Command {
 			hasDomains := len(ctx.StringSlice("domains")) > 0
 			hasCsr := ctx.String("csr") != ""
 			if hasDomains && hasCsr {
-				log.Fatal("Please specify either --domains/-d or --csr/-c, but not both")
+				fatalConfig("Please specify either --domains/-d or --csr/-c, but not both")
 			}
 			if !hasDomains && !hasCsr {
-				log.Fatal("Please specify --domains/-d (or --csr/-c if you already have a CSR)")
+				fatalConfig("Please specify --domains/-d (or --csr/-c if you already have a CSR)")
 			}
 			return nil
 		},
@@ -86,10 +91,19 @@ func createRenew() *cli.Command {
 				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name." +
 					" If no match, the default offered chain will be used.",
 			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "The name of the certificate profile (ex: tlsserver, shortlived) advertised by the CA (ACME profiles extension).",
+			},
 			&cli.StringFlag{
 				Name:  "always-deactivate-authorizations",
 				Usage: "Force the authorizations to be relinquished even if the certificate request was successful.",
 			},
+			&cli.BoolFlag{
+				Name: "check-revocation",
+				Usage: "Check the revocation status of the certificate (CRL, or OCSP)." +
+					" A revoked certificate is renewed whatever the number of days left.",
+			},
 			&cli.StringFlag{
 				Name:  "renew-hook",
 				Usage: "Define a hook. The hook is executed only when the certificates are effectively renewed.",
@@ -99,34 +113,149 @@ func createRenew() *cli.Command {
 				Usage: "Do not add a random sleep before the renewal." +
 					" We do not recommend using this flag if you are doing your renewals in an automated way.",
 			},
+			&cli.DurationFlag{
+				Name:  "jitter",
+				Value: 8 * time.Minute,
+				Usage: "The maximum random sleep added before the renewal (only when not running in a terminal).",
+			},
+			&cli.IntFlag{
+				Name: "jitter.window",
+				Usage: "Spread the renewals over this number of days before the --days threshold." +
+					" Each certificate is renewed at a point of the window derived from its domain (stable across the runs), to avoid synchronized renewals across a fleet.",
+			},
+			&cli.BoolFlag{
+				Name: "jitter.hash",
+				Usage: "Derive the random sleep from the certificate domain," +
+					" so a given certificate is always renewed at the same time after the start of the run.",
+			},
+			&cli.StringSliceFlag{
+				Name:  "label",
+				Usage: "Add a label (key=value) to the certificate metadata, the existing labels are kept. Can be specified multiple times.",
+			},
+			&cli.StringFlag{
+				Name:  "summary-file",
+				Usage: "Write a machine-readable (JSON) summary of the renewal (certificates, timings, provider calls, CA errors) to this file.",
+			},
+			&cli.StringSliceFlag{
+				Name: "maintenance-window",
+				Usage: "Define a CA maintenance window (start/end in RFC3339 format) during which renewals are postponed." +
+					" Can be specified multiple times.",
+			},
+			&cli.StringSliceFlag{
+				Name: "renewal-window",
+				Usage: "Only renew the certificate during a window ('<days> <HH:MM>-<HH:MM>' in local time, ex: 'Sun 02:00-04:00', 'Mon-Fri 22:00-06:00')," +
+					" unless the certificate would expire (or the ARI suggested window would end) before the next window." +
+					" The windows are stored with the certificate. Can be specified multiple times.",
+			},
+			&cli.BoolFlag{
+				Name: "ratelimit.resume",
+				Usage: "When the CA rejects the renewal because of a rate limit, postpone the renewal instead of failing:" +
+					" the renewal is resumed by the next run after the Retry-After window.",
+			},
+			&cli.DurationFlag{
+				Name:  "ratelimit.max-wait",
+				Usage: "When the CA rejects the renewal because of a rate limit, wait and retry if the Retry-After window is shorter than this duration.",
+			},
+			&cli.BoolFlag{
+				Name: "ratelimit.enforce",
+				Usage: "Refuse the renewals which would exceed a rate limit of the CA (certificates per domain, duplicate certificates, orders per account)," +
+					" according to the certificates issued by lego (tracked in the storage) and the rate limit errors of the CA." +
+					" The renewals are delayed if the wait is shorter than --ratelimit.max-wait, and postponed with --ratelimit.resume.",
+			},
+			&cli.IntFlag{
+				Name:  "alert.failures",
+				Value: 3,
+				Usage: "The number of consecutive renewal failures of a certificate before sending an alert.",
+			},
+			&cli.IntFlag{
+				Name:  "alert.days",
+				Value: 7,
+				Usage: "Send an alert on the first renewal failure if the certificate expires in less than this number of days.",
+			},
+			&cli.StringFlag{
+				Name:    "alert.pagerduty.routing-key",
+				EnvVars: []string{"LEGO_ALERT_PAGERDUTY_ROUTING_KEY"},
+				Usage:   "Send the renewal failure alerts to PagerDuty (Events API v2) with this integration routing key.",
+			},
+			&cli.StringFlag{
+				Name:    "alert.pushover.token",
+				EnvVars: []string{"LEGO_ALERT_PUSHOVER_TOKEN"},
+				Usage:   "Send the renewal failure alerts to Pushover with this application token.",
+			},
+			&cli.StringFlag{
+				Name:    "alert.pushover.user",
+				EnvVars: []string{"LEGO_ALERT_PUSHOVER_USER"},
+				Usage:   "The Pushover user (or group) key receiving the renewal failure alerts.",
+			},
 		},
 	}
 }
 
 func renew(ctx *cli.Context) error {
-	account, client := setup(ctx, NewAccountsStorage(ctx))
-	setupChallenges(ctx, client)
+	windows, err := parseMaintenanceWindows(ctx.StringSlice("maintenance-window"))
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	// Validates the labels and the renewal windows before the renewal.
+	if _, err = parseLabels(ctx.StringSlice("label")); err != nil {
+		fatalConfig(err)
+	}
+
+	if _, err = parseRenewalSchedule(ctx.StringSlice("renewal-window")); err != nil {
+		fatalConfig(err)
+	}
+
+	summary := newRunSummary(ctx, "renew")
+
+	if window, ok := findMaintenanceWindow(windows, time.Now()); ok {
+		log.Infof("renewal: CA maintenance window in progress until %s: the renewal is postponed.", window.End)
+
+		var domain string
+		if domains := ctx.StringSlice("domains"); len(domains) > 0 {
+			domain = domains[0]
+		}
+
+		summary.addCertificate(domain, summaryPostponed, "", nil)
+		summary.write()
+
+		return nil
+	}
+
+	accountsStorage := NewAccountsStorage(ctx)
+
+	account, client := setup(ctx, accountsStorage)
+	setupChallenges(ctx, client, summary)
 
 	if account.Registration == nil {
-		log.Fatalf("Account %s is not registered. Use 'run' to register a new account.\n", account.Email)
+		fatalConfigf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
 	}
 
+	updateContacts(ctx, client, account, accountsStorage)
+
 	certsStorage := NewCertificatesStorage(ctx)
 
+	deployConfig := loadDeployConfig(ctx)
+
+	alerts := newAlertManager(ctx)
+
 	bundle := !ctx.Bool("no-bundle")
 
-	meta := map[string]string{renewEnvAccountEmail: account.Email}
+	meta := map[string]string{
+		renewEnvAccountEmail: account.Email,
+		renewEnvAccountName:  accountsStorage.GetUserID(),
+	}
 
 	// CSR
 	if ctx.IsSet("csr") {
-		return renewForCSR(ctx, client, certsStorage, bundle, meta)
+		return renewForCSR(ctx, client, certsStorage, deployConfig, alerts, bundle, meta, summary)
 	}
 
 	// Domains
-	return renewForDomains(ctx, client, certsStorage, bundle, meta)
+	return renewForDomains(ctx, client, certsStorage, deployConfig, alerts, bundle, meta, summary)
 }
 
-func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage, bundle bool, meta map[string]string) error {
+func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage, deployConfig *deploy.Config, alerts *alertManager, bundle bool, meta map[string]string, summary *runSummary) error {
 	domains := ctx.StringSlice("domains")
 	domain := domains[0]
 
@@ -140,11 +269,28 @@ func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *Certif
 
 	cert := certificates[0]
 
+	checkAccountAssociation(certsStorage, domain, meta[renewEnvAccountName])
+
+	var resumed bool
+	if ctx.Bool("ratelimit.resume") {
+		var postponed bool
+		resumed, postponed = checkRateLimited(certsStorage, domain, client.Now())
+		if postponed {
+			summary.addCertificate(domain, summaryPostponed, "", nil)
+			summary.write()
+
+			return nil
+		}
+	}
+
+	start := time.Now()
+
 	var ariRenewalTime *time.Time
+	deadline := cert.NotAfter
 	if ctx.Bool("ari-enable") {
-		ariRenewalTime = getARIRenewalTime(ctx, cert, domain, client)
+		ariRenewalTime, deadline = getARIRenewalTime(ctx, certsStorage, cert, domain, client)
 		if ariRenewalTime != nil {
-			now := time.Now().UTC()
+			now := client.Now().UTC()
 			// Figure out if we need to sleep before renewing.
 			if ariRenewalTime.After(now) {
 				log.Infof("[%s] Sleeping %s until renewal time %s", domain, ariRenewalTime.Sub(now), ariRenewalTime)
@@ -153,12 +299,31 @@ func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *Certif
 		}
 	}
 
-	if ariRenewalTime == nil && !needRenewal(cert, domain, ctx.Int("days")) {
+	revoked := ctx.Bool("check-revocation") && isRevoked(client, certsStorage, domain)
+
+	if ariRenewalTime == nil && !revoked && !resumed && !needRenewal(cert, domain, getRenewalDays(ctx, domain), client.Now()) {
+		stapleOCSP(client.Certificate, certsStorage, domain)
+
+		summary.phase("check", start)
+		summary.addCertificate(domain, summarySkipped, "", nil)
+		summary.write()
+
+		return nil
+	}
+
+	summary.phase("check", start)
+
+	if !checkRenewalSchedule(getRenewalSchedule(ctx, certsStorage, domain), domain, time.Now(), deadline) {
+		stapleOCSP(client.Certificate, certsStorage, domain)
+
+		summary.addCertificate(domain, summaryPostponed, "", nil)
+		summary.write()
+
 		return nil
 	}
 
 	// This is just meant to be informal for the user.
-	timeLeft := cert.NotAfter.Sub(time.Now().UTC())
+	timeLeft := cert.NotAfter.Sub(client.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", domain, int(timeLeft.Hours()))
 
 	certDomains := certcrypto.ExtractDomains(cert)
@@ -176,17 +341,7 @@ func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *Certif
 		}
 	}
 
-	// https://github.com/go-acme/lego/issues/1656
-	// https://github.com/certbot/certbot/blob/284023a1b7672be2bd4018dd7623b3b92197d4b0/certbot/certbot/_internal/renewal.py#L435-L440
-	if !isatty.IsTerminal(os.Stdout.Fd()) && !ctx.Bool("no-random-sleep") {
-		// https://github.com/certbot/certbot/blob/284023a1b7672be2bd4018dd7623b3b92197d4b0/certbot/certbot/_internal/renewal.py#L472
-		const jitter = 8 * time.Minute
-		rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-		sleepTime := time.Duration(rnd.Int63n(int64(jitter)))
-
-		log.Infof("renewal: random delay of %s", sleepTime)
-		time.Sleep(sleepTime)
-	}
+	randomSleep(ctx, domain)
 
 	request := certificate.ObtainRequest{
 		Domains:                        merge(certDomains, domains),
@@ -196,29 +351,94 @@ func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *Certif
 		NotAfter:                       getTime(ctx, "not-after"),
 		Bundle:                         bundle,
 		PreferredChain:                 ctx.String("preferred-chain"),
+		Profile:                        ctx.String("profile"),
 		AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
 	}
 
 	if ctx.Bool("ari-enable") {
-		request.ReplacesCertID, err = certificate.MakeARICertID(cert)
+		request.ReplacesCertID, err = getARICertID(certsStorage, domain, cert)
 		if err != nil {
 			log.Fatalf("Error while construction the ARI CertID for domain %s\n\t%v", domain, err)
 		}
 	}
 
-	certRes, err := client.Certificate.Obtain(request)
+	start = time.Now()
+
+	limiter := newIssuanceLimiter(ctx, meta[renewEnvAccountName])
+
+	certRes, err := obtainWithRateLimit(domain, ctx.Duration("ratelimit.max-wait"), func() (*certificate.Resource, error) {
+		return limiter.obtain(ctx.Context, domain, request.Domains, func() (*certificate.Resource, error) {
+			return client.Certificate.Obtain(request)
+		})
+	})
+	summary.phase("obtain", start)
+	if errors.Is(err, dns01.ErrPlanMode) {
+		log.Printf("[%s] Plan mode: the DNS records were not created, the certificate was not renewed.", domain)
+		return nil
+	}
+
 	if err != nil {
-		log.Fatal(err)
+		if isMaintenanceError(err) {
+			log.Warnf("[%s] renewal: the CA is unavailable (maintenance?): the renewal is postponed: %v", domain, err)
+
+			summary.addCertificate(domain, summaryPostponed, "", err)
+			summary.write()
+
+			return nil
+		}
+
+		if ctx.Bool("ratelimit.resume") && postponeRateLimited(certsStorage, domain, client.Now(), err) {
+			summary.addCertificate(domain, summaryPostponed, "", err)
+			summary.write()
+
+			return nil
+		}
+
+		alerts.onFailure(ctx.Context, certsStorage, domain, cert.NotAfter, err)
+
+		summary.addCertificate(domain, summaryFailed, "", err)
+		summary.write()
+
+		fatal(err)
+	}
+
+	alerts.onSuccess(ctx.Context, certsStorage, domain, cert.NotAfter)
+
+	clearRateLimited(certsStorage, domain)
+
+	start = time.Now()
+
+	certResource := &CertificateResource{
+		Resource:    *certRes,
+		Account:     meta[renewEnvAccountName],
+		Labels:      getLabels(ctx, certsStorage, domain),
+		Deployments: getDeployments(certsStorage, domain),
+		Schedule:    getScheduleWindows(ctx, certsStorage, domain),
 	}
 
-	certsStorage.SaveResource(certRes)
+	certsStorage.SaveResource(certResource)
+
+	stapleOCSP(client.Certificate, certsStorage, domain)
+
+	summary.phase("save", start)
+	summary.addCertificate(domain, summaryRenewed, certRes.CertURL, nil)
+
+	deployCertificate(ctx, deployConfig, certsStorage, certResource, summary)
 
 	addPathToMetadata(meta, domain, certRes, certsStorage)
 
-	return launchHook(ctx.String("renew-hook"), meta)
+	start = time.Now()
+
+	err = launchHook(ctx.String("renew-hook"), meta)
+
+	summary.phase("hook", start)
+	summary.write()
+
+	// The certificate has been obtained: a hook failure is a partial success.
+	return withExitCode(exitCodePartial, err)
 }
 
-func renewForCSR(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage, bundle bool, meta map[string]string) error {
+func renewForCSR(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage, deployConfig *deploy.Config, alerts *alertManager, bundle bool, meta map[string]string, summary *runSummary) error {
 	csr, err := readCSRFile(ctx.String("csr"))
 	if err != nil {
 		log.Fatal(err)
@@ -239,11 +459,28 @@ func renewForCSR(ctx *cli.Context, client *lego.Client, certsStorage *Certificat
 
 	cert := certificates[0]
 
+	checkAccountAssociation(certsStorage, domain, meta[renewEnvAccountName])
+
+	var resumed bool
+	if ctx.Bool("ratelimit.resume") {
+		var postponed bool
+		resumed, postponed = checkRateLimited(certsStorage, domain, client.Now())
+		if postponed {
+			summary.addCertificate(domain, summaryPostponed, "", nil)
+			summary.write()
+
+			return nil
+		}
+	}
+
+	start := time.Now()
+
 	var ariRenewalTime *time.Time
+	deadline := cert.NotAfter
 	if ctx.Bool("ari-enable") {
-		ariRenewalTime = getARIRenewalTime(ctx, cert, domain, client)
+		ariRenewalTime, deadline = getARIRenewalTime(ctx, certsStorage, cert, domain, client)
 		if ariRenewalTime != nil {
-			now := time.Now().UTC()
+			now := client.Now().UTC()
 			// Figure out if we need to sleep before renewing.
 			if ariRenewalTime.After(now) {
 				log.Infof("[%s] Sleeping %s until renewal time %s", domain, ariRenewalTime.Sub(now), ariRenewalTime)
@@ -252,49 +489,222 @@ func renewForCSR(ctx *cli.Context, client *lego.Client, certsStorage *Certificat
 		}
 	}
 
-	if ariRenewalTime == nil && !needRenewal(cert, domain, ctx.Int("days")) {
+	revoked := ctx.Bool("check-revocation") && isRevoked(client, certsStorage, domain)
+
+	if ariRenewalTime == nil && !revoked && !resumed && !needRenewal(cert, domain, getRenewalDays(ctx, domain), client.Now()) {
+		stapleOCSP(client.Certificate, certsStorage, domain)
+
+		summary.phase("check", start)
+		summary.addCertificate(domain, summarySkipped, "", nil)
+		summary.write()
+
+		return nil
+	}
+
+	summary.phase("check", start)
+
+	if !checkRenewalSchedule(getRenewalSchedule(ctx, certsStorage, domain), domain, time.Now(), deadline) {
+		stapleOCSP(client.Certificate, certsStorage, domain)
+
+		summary.addCertificate(domain, summaryPostponed, "", nil)
+		summary.write()
+
 		return nil
 	}
 
 	// This is just meant to be informal for the user.
-	timeLeft := cert.NotAfter.Sub(time.Now().UTC())
+	timeLeft := cert.NotAfter.Sub(client.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", domain, int(timeLeft.Hours()))
 
+	randomSleep(ctx, domain)
+
 	request := certificate.ObtainForCSRRequest{
 		CSR:                            csr,
 		NotBefore:                      getTime(ctx, "not-before"),
 		NotAfter:                       getTime(ctx, "not-after"),
 		Bundle:                         bundle,
 		PreferredChain:                 ctx.String("preferred-chain"),
+		Profile:                        ctx.String("profile"),
 		AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
 	}
 
 	if ctx.Bool("ari-enable") {
-		request.ReplacesCertID, err = certificate.MakeARICertID(cert)
+		request.ReplacesCertID, err = getARICertID(certsStorage, domain, cert)
 		if err != nil {
 			log.Fatalf("Error while construction the ARI CertID for domain %s\n\t%v", domain, err)
 		}
 	}
 
-	certRes, err := client.Certificate.ObtainForCSR(request)
+	start = time.Now()
+
+	limiter := newIssuanceLimiter(ctx, meta[renewEnvAccountName])
+
+	certRes, err := obtainWithRateLimit(domain, ctx.Duration("ratelimit.max-wait"), func() (*certificate.Resource, error) {
+		return limiter.obtain(ctx.Context, domain, certcrypto.ExtractDomainsCSR(csr), func() (*certificate.Resource, error) {
+			return client.Certificate.ObtainForCSR(request)
+		})
+	})
+	summary.phase("obtain", start)
 	if err != nil {
-		log.Fatal(err)
+		if isMaintenanceError(err) {
+			log.Warnf("[%s] renewal: the CA is unavailable (maintenance?): the renewal is postponed: %v", domain, err)
+
+			summary.addCertificate(domain, summaryPostponed, "", err)
+			summary.write()
+
+			return nil
+		}
+
+		if ctx.Bool("ratelimit.resume") && postponeRateLimited(certsStorage, domain, client.Now(), err) {
+			summary.addCertificate(domain, summaryPostponed, "", err)
+			summary.write()
+
+			return nil
+		}
+
+		alerts.onFailure(ctx.Context, certsStorage, domain, cert.NotAfter, err)
+
+		summary.addCertificate(domain, summaryFailed, "", err)
+		summary.write()
+
+		fatal(err)
+	}
+
+	alerts.onSuccess(ctx.Context, certsStorage, domain, cert.NotAfter)
+
+	clearRateLimited(certsStorage, domain)
+
+	start = time.Now()
+
+	certResource := &CertificateResource{
+		Resource:    *certRes,
+		Account:     meta[renewEnvAccountName],
+		Labels:      getLabels(ctx, certsStorage, domain),
+		Deployments: getDeployments(certsStorage, domain),
+		Schedule:    getScheduleWindows(ctx, certsStorage, domain),
 	}
 
-	certsStorage.SaveResource(certRes)
+	certsStorage.SaveResource(certResource)
+
+	stapleOCSP(client.Certificate, certsStorage, domain)
+
+	summary.phase("save", start)
+	summary.addCertificate(domain, summaryRenewed, certRes.CertURL, nil)
+
+	deployCertificate(ctx, deployConfig, certsStorage, certResource, summary)
 
 	addPathToMetadata(meta, domain, certRes, certsStorage)
 
-	return launchHook(ctx.String("renew-hook"), meta)
+	start = time.Now()
+
+	err = launchHook(ctx.String("renew-hook"), meta)
+
+	summary.phase("hook", start)
+	summary.write()
+
+	// The certificate has been obtained: a hook failure is a partial success.
+	return withExitCode(exitCodePartial, err)
 }
 
-func needRenewal(x509Cert *x509.Certificate, domain string, days int) bool {
+// isRevoked checks if the stored certificate has been revoked.
+func isRevoked(client *lego.Client, certsStorage *CertificatesStorage, domain string) bool {
+	bundle, err := certsStorage.ReadFile(domain, certExt)
+	if err != nil {
+		log.Fatalf("Error while loading the certificate for domain %s\n\t%v", domain, err)
+	}
+
+	// The issuer certificate is required to verify the CRL signature: the CRLs are not used without it.
+	if issuer, errR := certsStorage.ReadFile(domain, issuerExt); errR == nil {
+		bundle = append(bundle, issuer...)
+	}
+
+	status, err := client.Certificate.CheckRevocation(bundle)
+	if err != nil {
+		log.Warnf("[%s] Unable to check the revocation status of the certificate: %v", domain, err)
+		return false
+	}
+
+	if status.Revoked {
+		log.Warnf("[%s] The certificate has been revoked (reason: %d, source: %s): the certificate will be renewed.", domain, status.Reason, status.Source)
+	}
+
+	return status.Revoked
+}
+
+// checkAccountAssociation warns if the certificate was obtained with another account.
+func checkAccountAssociation(certsStorage *CertificatesStorage, domain, accountName string) {
+	if !certsStorage.ExistsFile(domain, resourceExt) {
+		return
+	}
+
+	resource := certsStorage.ReadResource(domain)
+
+	if resource.Account != "" && resource.Account != accountName {
+		log.Warnf("[%s] The certificate was obtained with the account %q but the account %q is used.", domain, resource.Account, accountName)
+	}
+}
+
+// getLabels returns the labels of the existing certificate merged with the labels from the flags.
+func getLabels(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) map[string]string {
+	labels, err := parseLabels(ctx.StringSlice("label"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !certsStorage.ExistsFile(domain, resourceExt) {
+		return labels
+	}
+
+	resource := certsStorage.ReadResource(domain)
+
+	for k, v := range resource.Labels {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+
+	return labels
+}
+
+// getScheduleWindows returns the renewal windows of the certificate: the windows of the flags, or the windows stored with the certificate.
+func getScheduleWindows(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) []string {
+	if ctx.IsSet("renewal-window") {
+		return ctx.StringSlice("renewal-window")
+	}
+
+	if !certsStorage.ExistsFile(domain, resourceExt) {
+		return nil
+	}
+
+	return certsStorage.ReadResource(domain).Schedule
+}
+
+// getRenewalSchedule returns the renewal schedule of the certificate.
+func getRenewalSchedule(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) renewalSchedule {
+	schedule, err := parseRenewalSchedule(getScheduleWindows(ctx, certsStorage, domain))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return schedule
+}
+
+// getDeployments returns the IDs of the remote objects created by the previous deployments of the certificate.
+func getDeployments(certsStorage *CertificatesStorage, domain string) map[string]string {
+	if !certsStorage.ExistsFile(domain, resourceExt) {
+		return nil
+	}
+
+	return certsStorage.ReadResource(domain).Deployments
+}
+
+func needRenewal(x509Cert *x509.Certificate, domain string, days int, now time.Time) bool {
 	if x509Cert.IsCA {
 		log.Fatalf("[%s] Certificate bundle starts with a CA certificate", domain)
 	}
 
 	if days >= 0 {
-		notAfter := int(time.Until(x509Cert.NotAfter).Hours() / 24.0)
+		notAfter := int(x509Cert.NotAfter.Sub(now).Hours() / 24.0)
 		if notAfter > days {
 			log.Printf("[%s] The certificate expires in %d days, the number of days defined to perform the renewal is %d: no renewal.",
 				domain, notAfter, days)
@@ -305,28 +715,85 @@ func needRenewal(x509Cert *x509.Certificate, domain string, days int) bool {
 	return true
 }
 
+// getRenewalDays returns the number of days left on a certificate to renew it,
+// shifted by a number of days within the jitter window.
+// The shift is derived from the domain: a random shift at each run would renew most of the certificates
+// at the start of the window (the first run drawing a shift beyond the days left).
+func getRenewalDays(ctx *cli.Context, domain string) int {
+	days := ctx.Int("days")
+	window := ctx.Int("jitter.window")
+
+	if days < 0 || window <= 0 {
+		return days
+	}
+
+	offset := int(renewalJitter(domain, time.Duration(window)*24*time.Hour, true) / (24 * time.Hour))
+
+	return days + offset
+}
+
+// randomSleep adds a random delay before the renewal, to avoid synchronized load on the CA.
+func randomSleep(ctx *cli.Context, domain string) {
+	// https://github.com/go-acme/lego/issues/1656
+	// https://github.com/certbot/certbot/blob/284023a1b7672be2bd4018dd7623b3b92197d4b0/certbot/certbot/_internal/renewal.py#L435-L440
+	if isatty.IsTerminal(os.Stdout.Fd()) || ctx.Bool("no-random-sleep") {
+		return
+	}
+
+	// https://github.com/certbot/certbot/blob/284023a1b7672be2bd4018dd7623b3b92197d4b0/certbot/certbot/_internal/renewal.py#L472
+	sleepTime := renewalJitter(domain, ctx.Duration("jitter"), ctx.Bool("jitter.hash"))
+	if sleepTime <= 0 {
+		return
+	}
+
+	log.Infof("renewal: random delay of %s", sleepTime)
+	time.Sleep(sleepTime)
+}
+
+// renewalJitter returns a duration in [0, maxJitter).
+// If hashed is true, the duration is derived from the domain instead of a random source.
+func renewalJitter(domain string, maxJitter time.Duration, hashed bool) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+
+	if hashed {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(domain))
+
+		return time.Duration(h.Sum64() % uint64(maxJitter))
+	}
+
+	return random.Duration(maxJitter)
+}
+
 // getARIRenewalTime checks if the certificate needs to be renewed using the renewalInfo endpoint.
-func getARIRenewalTime(ctx *cli.Context, cert *x509.Certificate, domain string, client *lego.Client) *time.Time {
+// It also returns the deadline of the renewal: the end of the suggested window, or the expiration of the certificate.
+func getARIRenewalTime(ctx *cli.Context, certsStorage *CertificatesStorage, cert *x509.Certificate, domain string, client *lego.Client) (*time.Time, time.Time) {
 	if cert.IsCA {
 		log.Fatalf("[%s] Certificate bundle starts with a CA certificate", domain)
 	}
 
-	renewalInfo, err := client.Certificate.GetRenewalInfo(certificate.RenewalInfoRequest{Cert: cert})
+	renewalInfo, err := client.Certificate.RenewalInfo(certificate.Resource{
+		Domain:      domain,
+		Certificate: certcrypto.PEMEncode(certcrypto.DERCertificateBytes(cert.Raw)),
+		ARICertID:   getStoredARICertID(certsStorage, domain),
+	})
 	if err != nil {
 		if errors.Is(err, api.ErrNoARI) {
 			// The server does not advertise a renewal info endpoint.
 			log.Warnf("[%s] acme: %v", domain, err)
-			return nil
+			return nil, cert.NotAfter
 		}
 		log.Warnf("[%s] acme: calling renewal info endpoint: %v", domain, err)
-		return nil
+		return nil, cert.NotAfter
 	}
 
-	now := time.Now().UTC()
+	now := client.Now().UTC()
 	renewalTime := renewalInfo.ShouldRenewAt(now, ctx.Duration("ari-wait-to-renew-duration"))
 	if renewalTime == nil {
 		log.Infof("[%s] acme: renewalInfo endpoint indicates that renewal is not needed", domain)
-		return nil
+		return nil, cert.NotAfter
 	}
 	log.Infof("[%s] acme: renewalInfo endpoint indicates that renewal is needed", domain)
 
@@ -334,7 +801,30 @@ func getARIRenewalTime(ctx *cli.Context, cert *x509.Certificate, domain string,
 		log.Infof("[%s] acme: renewalInfo endpoint provided an explanation: %s", domain, renewalInfo.ExplanationURL)
 	}
 
-	return renewalTime
+	deadline := cert.NotAfter
+	if end := renewalInfo.SuggestedWindow.End; !end.IsZero() && end.Before(deadline) {
+		deadline = end
+	}
+
+	return renewalTime, deadline
+}
+
+// getARICertID returns the ARI CertID of the certificate: the CertID stored in the metadata, or the CertID computed from the certificate.
+func getARICertID(certsStorage *CertificatesStorage, domain string, cert *x509.Certificate) (string, error) {
+	if certID := getStoredARICertID(certsStorage, domain); certID != "" {
+		return certID, nil
+	}
+
+	return certificate.MakeARICertID(cert)
+}
+
+// getStoredARICertID returns the ARI CertID stored in the metadata of the certificate, empty if the metadata don't define it.
+func getStoredARICertID(certsStorage *CertificatesStorage, domain string) string {
+	if !certsStorage.ExistsFile(domain, resourceExt) {
+		return ""
+	}
+
+	return certsStorage.ReadResource(domain).ARICertID
 }
 
 func addPathToMetadata(meta map[string]string, domain string, certRes *certificate.Resource, certsStorage *CertificatesStorage) {
@@ -353,6 +843,10 @@ func addPathToMetadata(meta map[string]string, domain string, certRes *certifica
 	if certsStorage.pfx {
 		meta[renewEnvCertPFXPath] = certsStorage.GetFileName(domain, pfxExt)
 	}
+
+	if certsStorage.ocsp {
+		meta[renewEnvCertOCSPPath] = certsStorage.GetFileName(domain, ocspExt)
+	}
 }
 
 func merge(prevDomains, nextDomains []string) []string {
@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"crypto/x509"
+	"flag"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
 )
 
 func Test_merge(t *testing.T) {
@@ -108,9 +111,100 @@ func Test_needRenewal(t *testing.T) {
 
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
-			actual := needRenewal(test.x509Cert, "foo.com", test.days)
+			actual := needRenewal(test.x509Cert, "foo.com", test.days, time.Now())
 
 			assert.Equal(t, test.expected, actual)
 		})
 	}
 }
+
+func Test_renewalJitter(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		domain    string
+		maxJitter time.Duration
+		hashed    bool
+	}{
+		{
+			desc:      "random",
+			domain:    "example.com",
+			maxJitter: 8 * time.Minute,
+		},
+		{
+			desc:      "hashed",
+			domain:    "example.com",
+			maxJitter: 8 * time.Minute,
+			hashed:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			actual := renewalJitter(test.domain, test.maxJitter, test.hashed)
+
+			assert.GreaterOrEqual(t, actual, time.Duration(0))
+			assert.Less(t, actual, test.maxJitter)
+
+			if test.hashed {
+				assert.Equal(t, actual, renewalJitter(test.domain, test.maxJitter, test.hashed))
+			}
+		})
+	}
+}
+
+func Test_renewalJitter_noJitter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), renewalJitter("example.com", 0, false))
+	assert.Equal(t, time.Duration(0), renewalJitter("example.com", 0, true))
+}
+
+func Test_getRenewalDays(t *testing.T) {
+	testCases := []struct {
+		desc string
+		args []string
+		min  int
+		max  int
+	}{
+		{
+			desc: "no window",
+			args: []string{"--days", "30"},
+			min:  30,
+			max:  30,
+		},
+		{
+			desc: "window",
+			args: []string{"--days", "30", "--jitter.window", "10"},
+			min:  30,
+			max:  39,
+		},
+		{
+			desc: "no days",
+			args: []string{"--days", "-1", "--jitter.window", "10"},
+			min:  -1,
+			max:  -1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Int("days", 30, "")
+			set.Int("jitter.window", 0, "")
+
+			require.NoError(t, set.Parse(test.args))
+
+			ctx := cli.NewContext(cli.NewApp(), set, nil)
+
+			days := getRenewalDays(ctx, "example.com")
+
+			assert.GreaterOrEqual(t, days, test.min)
+			assert.LessOrEqual(t, days, test.max)
+
+			// the renewal point is stable across the runs.
+			for range 10 {
+				assert.Equal(t, days, getRenewalDays(ctx, "example.com"))
+			}
+		})
+	}
+}
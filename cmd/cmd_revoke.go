@@ -1,11 +1,31 @@
 package cmd
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/lego"
 	"github.com/pya789/lego/v4/log"
 	"github.com/urfave/cli/v2"
 )
 
+// revocationReasons the names of the revocation reasons (RFC 5280).
+var revocationReasons = map[string]uint{
+	"unspecified":          acme.CRLReasonUnspecified,
+	"keyCompromise":        acme.CRLReasonKeyCompromise,
+	"cACompromise":         acme.CRLReasonCACompromise,
+	"affiliationChanged":   acme.CRLReasonAffiliationChanged,
+	"superseded":           acme.CRLReasonSuperseded,
+	"cessationOfOperation": acme.CRLReasonCessationOfOperation,
+	"certificateHold":      acme.CRLReasonCertificateHold,
+	"removeFromCRL":        acme.CRLReasonRemoveFromCRL,
+	"privilegeWithdrawn":   acme.CRLReasonPrivilegeWithdrawn,
+	"aACompromise":         acme.CRLReasonAACompromise,
+}
+
 func createRevoke() *cli.Command {
 	return &cli.Command{
 		Name:   "revoke",
@@ -17,25 +37,48 @@ func createRevoke() *cli.Command {
 				Aliases: []string{"k"},
 				Usage:   "Keep the certificates after the revocation instead of archiving them.",
 			},
-			&cli.UintFlag{
+			&cli.StringFlag{
 				Name: "reason",
 				Usage: "Identifies the reason for the certificate revocation." +
 					" See https://www.rfc-editor.org/rfc/rfc5280.html#section-5.3.1." +
-					" Valid values are:" +
+					" Valid values are (name or code):" +
 					" 0 (unspecified), 1 (keyCompromise), 2 (cACompromise), 3 (affiliationChanged)," +
 					" 4 (superseded), 5 (cessationOfOperation), 6 (certificateHold), 8 (removeFromCRL)," +
 					" 9 (privilegeWithdrawn), or 10 (aACompromise).",
-				Value: acme.CRLReasonUnspecified,
+				Value: "unspecified",
+			},
+			&cli.BoolFlag{
+				Name: "cert-key",
+				Usage: "Sign the revocation request with the private key of the certificate instead of the account key." +
+					" The account is not required: allows to revoke the certificates of a lost account, or with a compromised key.",
 			},
 		},
 	}
 }
 
 func revoke(ctx *cli.Context) error {
-	acc, client := setup(ctx, NewAccountsStorage(ctx))
+	reason, err := parseRevocationReason(ctx.String("reason"))
+	if err != nil {
+		fatalConfigf("Invalid --reason: %v", err)
+	}
+
+	var client *lego.Client
+
+	if ctx.Bool("cert-key") {
+		client = newCertKeyClient(ctx)
+	} else {
+		accountsStorage := NewAccountsStorage(ctx)
 
-	if acc.Registration == nil {
-		log.Fatalf("Account %s is not registered. Use 'run' to register a new account.\n", acc.Email)
+		var acc *Account
+		acc, client = setup(ctx, accountsStorage)
+
+		if acc.Registration == nil {
+			log.Fatalf("Account %s is not registered. Use 'run' to register a new account.\n", accountsStorage.GetUserID())
+		}
+
+		if reason == acme.CRLReasonKeyCompromise {
+			log.Warnf("Some CAs only accept the keyCompromise reason if the request is signed with the private key of the certificate: use --cert-key.")
+		}
 	}
 
 	certsStorage := NewCertificatesStorage(ctx)
@@ -44,20 +87,30 @@ func revoke(ctx *cli.Context) error {
 	for _, domain := range ctx.StringSlice("domains") {
 		log.Printf("Trying to revoke certificate for domain %s", domain)
 
-		certBytes, err := certsStorage.ReadFile(domain, ".crt")
+		certBytes, err := certsStorage.ReadFile(domain, certExt)
 		if err != nil {
 			log.Fatalf("Error while revoking the certificate for domain %s\n\t%v", domain, err)
 		}
 
-		reason := ctx.Uint("reason")
+		if ctx.Bool("cert-key") {
+			err = revokeWithCertKey(client, certsStorage, domain, certBytes, reason)
+		} else {
+			err = client.Certificate.RevokeWithReason(certBytes, &reason)
+		}
 
-		err = client.Certificate.RevokeWithReason(certBytes, &reason)
 		if err != nil {
 			log.Fatalf("Error while revoking the certificate for domain %s\n\t%v", domain, err)
 		}
 
 		log.Println("Certificate was revoked.")
 
+		// The identifiers of the certificate are logged, for the requests to the CA.
+		if certsStorage.ExistsFile(domain, resourceExt) {
+			if resource := certsStorage.ReadResource(domain); resource.OrderURL != "" {
+				log.Printf("[%s] Order URL: %s, ARI CertID: %s", domain, resource.OrderURL, resource.ARICertID)
+			}
+		}
+
 		if ctx.Bool("keep") {
 			return nil
 		}
@@ -74,3 +127,55 @@ func revoke(ctx *cli.Context) error {
 
 	return nil
 }
+
+// newCertKeyClient creates a client without account:
+// the revocation requests are signed with the private keys of the certificates,
+// the key of the client is an ephemeral key, never used to sign the requests.
+func newCertKeyClient(ctx *cli.Context) *lego.Client {
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	if err != nil {
+		log.Fatalf("Could not generate the ephemeral key: %v", err)
+	}
+
+	return newClient(ctx, &Account{key: privateKey}, getKeyType(ctx))
+}
+
+func revokeWithCertKey(client *lego.Client, certsStorage *CertificatesStorage, domain string, certBytes []byte, reason uint) error {
+	keyBytes, err := certsStorage.ReadFile(domain, keyExt)
+	if err != nil {
+		return fmt.Errorf("unable to read the private key of the certificate: %w", err)
+	}
+
+	privateKey, err := certcrypto.ParsePEMPrivateKey(keyBytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse the private key of the certificate: %w", err)
+	}
+
+	return client.Certificate.RevokeWithKey(certBytes, privateKey, &reason)
+}
+
+// parseRevocationReason parses a revocation reason: a name (ex: keyCompromise) or a code (ex: 1).
+func parseRevocationReason(value string) (uint, error) {
+	if code, ok := revocationReasons[value]; ok {
+		return code, nil
+	}
+
+	for name, code := range revocationReasons {
+		if strings.EqualFold(name, value) {
+			return code, nil
+		}
+	}
+
+	code, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unknown reason %q", value)
+	}
+
+	for _, c := range revocationReasons {
+		if uint(code) == c {
+			return c, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown reason code %d", code)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseRevocationReason(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected uint
+	}{
+		{desc: "name", value: "keyCompromise", expected: acme.CRLReasonKeyCompromise},
+		{desc: "name case insensitive", value: "KEYCOMPROMISE", expected: acme.CRLReasonKeyCompromise},
+		{desc: "code", value: "4", expected: acme.CRLReasonSuperseded},
+		{desc: "default", value: "unspecified", expected: acme.CRLReasonUnspecified},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			reason, err := parseRevocationReason(test.value)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, reason)
+		})
+	}
+}
+
+func Test_parseRevocationReason_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected string
+	}{
+		{desc: "unknown name", value: "stolen", expected: `unknown reason "stolen"`},
+		{desc: "unused code", value: "7", expected: "unknown reason code 7"},
+		{desc: "negative code", value: "-1", expected: `unknown reason "-1"`},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseRevocationReason(test.value)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/pya789/lego/v4/certcrypto"
 	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/lego"
 	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/registration"
@@ -23,10 +26,19 @@ func createRun() *cli.Command {
 			hasDomains := len(ctx.StringSlice("domains")) > 0
 			hasCsr := ctx.String("csr") != ""
 			if hasDomains && hasCsr {
-				log.Fatal("Please specify either --domains/-d or --csr/-c, but not both")
+				fatalConfig("Please specify either --domains/-d or --csr/-c, but not both")
 			}
 			if !hasDomains && !hasCsr {
-				log.Fatal("Please specify --domains/-d (or --csr/-c if you already have a CSR)")
+				fatalConfig("Please specify --domains/-d (or --csr/-c if you already have a CSR)")
+			}
+			if hasCsr && ctx.Bool("deferred") {
+				fatalConfig("The deferred mode (--deferred) doesn't support --csr/-c")
+			}
+			if hasCsr && ctx.Bool("resume") {
+				fatalConfig("The resumable mode (--resume) doesn't support --csr/-c")
+			}
+			if ctx.Bool("deferred") && ctx.Bool("resume") {
+				fatalConfig("Please specify either --deferred or --resume, but not both")
 			}
 			return nil
 		},
@@ -56,6 +68,10 @@ func createRun() *cli.Command {
 				Usage: "If the CA offers multiple certificate chains, prefer the chain with an issuer matching this Subject Common Name." +
 					" If no match, the default offered chain will be used.",
 			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "The name of the certificate profile (ex: tlsserver, shortlived) advertised by the CA (ACME profiles extension).",
+			},
 			&cli.StringFlag{
 				Name:  "always-deactivate-authorizations",
 				Usage: "Force the authorizations to be relinquished even if the certificate request was successful.",
@@ -64,6 +80,39 @@ func createRun() *cli.Command {
 				Name:  "run-hook",
 				Usage: "Define a hook. The hook is executed when the certificates are effectively created.",
 			},
+			&cli.StringSliceFlag{
+				Name:  "label",
+				Usage: "Add a label (key=value) to the certificate metadata. Can be specified multiple times.",
+			},
+			&cli.StringFlag{
+				Name:  "summary-file",
+				Usage: "Write a machine-readable (JSON) summary of the run (certificates, timings, provider calls, CA errors) to this file.",
+			},
+			&cli.BoolFlag{
+				Name: "save-defaults",
+				Usage: "Save the key type (--key-type), the preferred chain (--preferred-chain), and the contacts (--contact) as the defaults of the account." +
+					" Only the flags explicitly set are saved, the other defaults are kept. The defaults are used by the next runs when these flags are not set.",
+			},
+			&cli.BoolFlag{
+				Name: "deferred",
+				Usage: "Create the order without solving the challenges: the challenge values are printed and saved to a pending file," +
+					" to be placed out-of-band. The 'continue' command validates the challenges and creates the certificate.",
+			},
+			&cli.StringFlag{
+				Name:  "deferred.challenge",
+				Usage: "The challenge type of the deferred mode (dns-01 or http-01).",
+				Value: "dns-01",
+			},
+			&cli.BoolFlag{
+				Name: "resume",
+				Usage: "Save the order when it's created, and resume the saved order of an interrupted run (ex: while waiting for the DNS propagation)" +
+					" instead of creating a new order. The order is removed when the certificate is obtained.",
+			},
+			&cli.BoolFlag{
+				Name: "ratelimit.enforce",
+				Usage: "Refuse the certificates which would exceed a rate limit of the CA (certificates per domain, duplicate certificates, orders per account)," +
+					" according to the certificates issued by lego (tracked in the storage) and the rate limit errors of the CA.",
+			},
 		},
 	}
 }
@@ -80,15 +129,21 @@ backups of this folder is ideal.
 `
 
 func run(ctx *cli.Context) error {
+	summary := newRunSummary(ctx, "run")
+
 	accountsStorage := NewAccountsStorage(ctx)
 
 	account, client := setup(ctx, accountsStorage)
-	setupChallenges(ctx, client)
+
+	// In deferred mode, the challenges are placed out-of-band.
+	if !ctx.Bool("deferred") {
+		setupChallenges(ctx, client, summary)
+	}
 
 	if account.Registration == nil {
 		reg, err := register(ctx, client)
 		if err != nil {
-			log.Fatalf("Could not complete registration\n\t%v", err)
+			fatal(fmt.Errorf("could not complete registration\n\t%w", err))
 		}
 
 		account.Registration = reg
@@ -97,30 +152,90 @@ func run(ctx *cli.Context) error {
 		}
 
 		fmt.Printf(rootPathWarningMessage, accountsStorage.GetRootPath())
+	} else {
+		updateContacts(ctx, client, account, accountsStorage)
+	}
+
+	if ctx.Bool("save-defaults") {
+		account.Defaults = updateDefaults(ctx, account.Defaults)
+
+		if err := accountsStorage.Save(account); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	labels, err := parseLabels(ctx.StringSlice("label"))
+	if err != nil {
+		fatalConfig(err)
 	}
 
+	deployConfig := loadDeployConfig(ctx)
+
 	certsStorage := NewCertificatesStorage(ctx)
 	certsStorage.CreateRootFolder()
 
-	cert, err := obtainCertificate(ctx, client)
+	if ctx.Bool("deferred") {
+		return prepareDeferred(ctx, client, certsStorage)
+	}
+
+	start := time.Now()
+
+	limiter := newIssuanceLimiter(ctx, accountsStorage.GetUserID())
+
+	cert, err := obtainCertificate(ctx, client, certsStorage, limiter)
+	summary.phase("obtain", start)
+	if errors.Is(err, dns01.ErrPlanMode) {
+		log.Println("Plan mode: the DNS records were not created, no certificate was obtained.")
+		return nil
+	}
+
 	if err != nil {
+		var domain string
+		if domains := ctx.StringSlice("domains"); len(domains) > 0 {
+			domain = domains[0]
+		}
+
+		summary.addCertificate(domain, summaryFailed, "", err)
+		summary.write()
+
 		// Make sure to return a non-zero exit code if ObtainSANCertificate returned at least one error.
 		// Due to us not returning partial certificate we can just exit here instead of at the end.
-		log.Fatalf("Could not obtain certificates:\n\t%v", err)
+		fatal(fmt.Errorf("could not obtain certificates:\n\t%w", err))
 	}
 
-	certsStorage.SaveResource(cert)
+	start = time.Now()
+
+	certResource := &CertificateResource{Resource: *cert, Account: accountsStorage.GetUserID(), Labels: labels}
+
+	certsStorage.SaveResource(certResource)
+
+	stapleOCSP(client.Certificate, certsStorage, cert.Domain)
+
+	summary.phase("save", start)
+	summary.addCertificate(cert.Domain, summaryObtained, cert.CertURL, nil)
+
+	deployCertificate(ctx, deployConfig, certsStorage, certResource, summary)
 
 	meta := map[string]string{
 		renewEnvAccountEmail: account.Email,
+		renewEnvAccountName:  accountsStorage.GetUserID(),
 		renewEnvCertDomain:   cert.Domain,
 		renewEnvCertPath:     certsStorage.GetFileName(cert.Domain, ".crt"),
 		renewEnvCertKeyPath:  certsStorage.GetFileName(cert.Domain, ".key"),
 		renewEnvCertPEMPath:  certsStorage.GetFileName(cert.Domain, ".pem"),
 		renewEnvCertPFXPath:  certsStorage.GetFileName(cert.Domain, ".pfx"),
+		renewEnvCertOCSPPath: certsStorage.GetFileName(cert.Domain, ocspExt),
 	}
 
-	return launchHook(ctx.String("run-hook"), meta)
+	start = time.Now()
+
+	err = launchHook(ctx.String("run-hook"), meta)
+
+	summary.phase("hook", start)
+	summary.write()
+
+	// The certificate has been obtained: a hook failure is a partial success.
+	return withExitCode(exitCodePartial, err)
 }
 
 func handleTOS(ctx *cli.Context, client *lego.Client) bool {
@@ -162,20 +277,67 @@ func register(ctx *cli.Context, client *lego.Client) (*registration.Resource, er
 		hmacEncoded := ctx.String("hmac")
 
 		if kid == "" || hmacEncoded == "" {
-			log.Fatalf("Requires arguments --kid and --hmac.")
+			fatalConfigf("Requires arguments --kid and --hmac.")
 		}
 
 		return client.Registration.RegisterWithExternalAccountBinding(registration.RegisterEABOptions{
 			TermsOfServiceAgreed: accepted,
 			Kid:                  kid,
 			HmacEncoded:          hmacEncoded,
+			Contact:              ctx.StringSlice("contact"),
 		})
 	}
 
-	return client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	return client.Registration.Register(registration.RegisterOptions{
+		TermsOfServiceAgreed: true,
+		Contact:              ctx.StringSlice("contact"),
+	})
 }
 
-func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Resource, error) {
+// updateDefaults returns the defaults of the account updated with the flags explicitly set.
+func updateDefaults(ctx *cli.Context, defaults *registration.Defaults) *registration.Defaults {
+	updated := &registration.Defaults{}
+	if defaults != nil {
+		*updated = *defaults
+	}
+
+	if ctx.IsSet("key-type") {
+		updated.KeyType = getKeyType(ctx)
+	}
+
+	if ctx.IsSet("preferred-chain") {
+		updated.PreferredChain = ctx.String("preferred-chain")
+	}
+
+	if ctx.IsSet("contact") {
+		updated.Contact = ctx.StringSlice("contact")
+	}
+
+	return updated
+}
+
+// updateContacts updates the contacts of a registered account when the "contact" option is set, and the contacts changed.
+func updateContacts(ctx *cli.Context, client *lego.Client, account *Account, accountsStorage *AccountsStorage) {
+	if !ctx.IsSet("contact") {
+		return
+	}
+
+	reg, err := client.Registration.UpdateContacts(ctx.StringSlice("contact"))
+	if err != nil {
+		fatal(fmt.Errorf("could not update the contacts of the account\n\t%w", err))
+	}
+
+	if reg == nil {
+		return
+	}
+
+	account.Registration = reg
+	if err = accountsStorage.Save(account); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func obtainCertificate(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage, limiter *issuanceLimiter) (*certificate.Resource, error) {
 	bundle := !ctx.Bool("no-bundle")
 
 	domains := ctx.StringSlice("domains")
@@ -186,6 +348,7 @@ func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Reso
 			Bundle:                         bundle,
 			MustStaple:                     ctx.Bool("must-staple"),
 			PreferredChain:                 ctx.String("preferred-chain"),
+			Profile:                        ctx.String("profile"),
 			AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
 		}
 
@@ -199,7 +362,13 @@ func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Reso
 			request.NotAfter = *notAfter
 		}
 
-		return client.Certificate.Obtain(request)
+		return limiter.obtain(ctx.Context, domains[0], domains, func() (*certificate.Resource, error) {
+			if ctx.Bool("resume") {
+				return obtainResumable(client.Certificate, certsStorage, request)
+			}
+
+			return client.Certificate.Obtain(request)
+		})
 	}
 
 	// read the CSR
@@ -215,8 +384,16 @@ func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Reso
 		NotAfter:                       getTime(ctx, "not-after"),
 		Bundle:                         bundle,
 		PreferredChain:                 ctx.String("preferred-chain"),
+		Profile:                        ctx.String("profile"),
 		AlwaysDeactivateAuthorizations: ctx.Bool("always-deactivate-authorizations"),
 	}
 
-	return client.Certificate.ObtainForCSR(request)
+	domain, err := certcrypto.GetCSRMainDomain(csr)
+	if err != nil {
+		return nil, err
+	}
+
+	return limiter.obtain(ctx.Context, domain, certcrypto.ExtractDomainsCSR(csr), func() (*certificate.Resource, error) {
+		return client.Certificate.ObtainForCSR(request)
+	})
 }
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/registration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func Test_updateDefaults(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		args     []string
+		defaults *registration.Defaults
+		expected *registration.Defaults
+	}{
+		{
+			desc:     "no flags",
+			expected: &registration.Defaults{},
+		},
+		{
+			desc: "no flags: the existing defaults are kept",
+			defaults: &registration.Defaults{
+				KeyType:        certcrypto.RSA4096,
+				PreferredChain: "ISRG Root X1",
+				Contact:        []string{"mailto:ops@example.com"},
+			},
+			expected: &registration.Defaults{
+				KeyType:        certcrypto.RSA4096,
+				PreferredChain: "ISRG Root X1",
+				Contact:        []string{"mailto:ops@example.com"},
+			},
+		},
+		{
+			desc: "only the flags explicitly set",
+			args: []string{"--preferred-chain", "ISRG Root X2", "--contact", "mailto:admin@example.com", "--contact", "tel:+1-201-555-0123"},
+			defaults: &registration.Defaults{
+				KeyType:        certcrypto.RSA4096,
+				PreferredChain: "ISRG Root X1",
+			},
+			expected: &registration.Defaults{
+				KeyType:        certcrypto.RSA4096,
+				PreferredChain: "ISRG Root X2",
+				Contact:        []string{"mailto:admin@example.com", "tel:+1-201-555-0123"},
+			},
+		},
+		{
+			desc:     "key type",
+			args:     []string{"--key-type", "ec384"},
+			expected: &registration.Defaults{KeyType: certcrypto.EC384},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("key-type", "ec256", "")
+			set.String("preferred-chain", "", "")
+			set.Var(cli.NewStringSlice(), "contact", "")
+
+			require.NoError(t, set.Parse(test.args))
+
+			ctx := cli.NewContext(cli.NewApp(), set, nil)
+
+			assert.Equal(t, test.expected, updateDefaults(ctx, test.defaults))
+		})
+	}
+}
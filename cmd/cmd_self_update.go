@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+func createSelfUpdate() *cli.Command {
+	return &cli.Command{
+		Name: "self-update",
+		Usage: "Update the lego binary with the binary of the latest release." +
+			" The checksums of the release are verified with the minisign public keys of the releases.",
+		Action: selfUpdate,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "channel",
+				EnvVars: []string{"LEGO_SELF_UPDATE_CHANNEL"},
+				Usage:   "The release channel: 'stable' (the releases), or 'edge' (the releases and the pre-releases).",
+				Value:   channelStable,
+			},
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "Only check if an update is available.",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Install the latest release of the channel, even if it's not newer than the running binary (ex: downgrade from edge to stable).",
+			},
+			&cli.BoolFlag{
+				Name:  "rollback",
+				Usage: "Restore the previous binary (kept by the last update).",
+			},
+			&cli.StringSliceFlag{
+				Name:    "public-key",
+				EnvVars: []string{"LEGO_SELF_UPDATE_PUBLIC_KEY"},
+				Usage:   "A minisign public key trusted to sign the releases (base64), in addition to the built-in keys.",
+			},
+			&cli.StringFlag{
+				Name:   "api-url",
+				Usage:  "The URL of the GitHub API.",
+				Value:  "https://api.github.com",
+				Hidden: true,
+			},
+		},
+	}
+}
+
+func selfUpdate(ctx *cli.Context) error {
+	executable, err := currentExecutable()
+	if err != nil {
+		return fmt.Errorf("self-update: %w", err)
+	}
+
+	if ctx.Bool("rollback") {
+		err = rollbackBinary(executable)
+		if err != nil {
+			return fmt.Errorf("self-update: rollback: %w", err)
+		}
+
+		log.Infof("self-update: the previous binary has been restored.")
+
+		err = checkBinary(executable)
+		if err != nil {
+			return fmt.Errorf("self-update: rollback: %w", err)
+		}
+
+		return nil
+	}
+
+	channel := ctx.String("channel")
+	if channel != channelStable && channel != channelEdge {
+		fatalConfigf("Invalid --channel: %q: the channel must be %q or %q.", channel, channelStable, channelEdge)
+	}
+
+	keys, err := selfUpdateKeys(ctx.StringSlice("public-key"))
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	updater := &selfUpdater{
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		apiURL:   ctx.String("api-url"),
+		channel:  channel,
+		platform: currentPlatform(),
+		keys:     keys,
+	}
+
+	release, err := updater.latestRelease(ctx.Context)
+	if err != nil {
+		return fmt.Errorf("self-update: %w", err)
+	}
+
+	current := ctx.App.Version
+
+	if compareVersions(release.TagName, current) <= 0 && !ctx.Bool("force") {
+		log.Infof("self-update: lego %s is up to date (latest %s release: %s).", current, channel, release.TagName)
+		return nil
+	}
+
+	if ctx.Bool("check") {
+		log.Infof("self-update: lego %s is available (current version: %s).", release.TagName, current)
+		return nil
+	}
+
+	binary, err := updater.fetchBinary(ctx.Context, release)
+	if err != nil {
+		return fmt.Errorf("self-update: %w", err)
+	}
+
+	err = installBinary(executable, binary)
+	if err != nil {
+		return fmt.Errorf("self-update: %w", err)
+	}
+
+	log.Infof("self-update: lego has been updated from %s to %s (%s), the previous binary can be restored with --rollback.",
+		current, release.TagName, executable)
+
+	return nil
+}
+
+// selfUpdateKeys returns the trusted public keys: the built-in keys, and the keys of the option.
+func selfUpdateKeys(values []string) ([]minisignPublicKey, error) {
+	var keys []minisignPublicKey
+
+	for _, value := range append(strings.Split(releaseKeys, ","), values...) {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		key, err := parseMinisignPublicKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("self-update: %w", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("self-update: no public key to verify the releases, use --public-key")
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func createStats() *cli.Command {
+	return &cli.Command{
+		Name:   "stats",
+		Usage:  "Display the top causes of the renewal failures of the daemon, aggregated from the problems returned by the CA.",
+		Action: stats,
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "top",
+				Usage: "The number of causes to display (0 to display all the causes).",
+				Value: 10,
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Display the statistics as JSON.",
+			},
+		},
+	}
+}
+
+func stats(ctx *cli.Context) error {
+	certsStorage := NewCertificatesStorage(ctx)
+
+	failures, err := certsStorage.ReadFailureStats()
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("json") {
+		return json.NewEncoder(os.Stdout).Encode(failures)
+	}
+
+	if len(failures.Causes) == 0 {
+		fmt.Println("No failures found.")
+		return nil
+	}
+
+	fmt.Printf("Failure causes since %s:\n", failures.Since.Format(time.RFC3339))
+
+	for _, name := range failures.top(ctx.Int("top")) {
+		cause := failures.Causes[name]
+
+		fmt.Printf("  %s: %d failure(s)\n", name, cause.Count)
+		fmt.Printf("    Last seen: %s (%s)\n", cause.LastSeen.Format(time.RFC3339), cause.LastDomain)
+
+		if cause.LastDetail != "" {
+			fmt.Printf("    Last detail: %s\n", cause.LastDetail)
+		}
+	}
+
+	return nil
+}
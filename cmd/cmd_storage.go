@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/storage"
+	"github.com/urfave/cli/v2"
+)
+
+func createStorage() *cli.Command {
+	return &cli.Command{
+		Name:  "storage",
+		Usage: "Manage the data directory.",
+		Subcommands: []*cli.Command{
+			{
+				Name: "verify",
+				Usage: "Verify the integrity of the data directory: private key and certificate pairing, chain validity, metadata consistency, and file permissions." +
+					" Exits with an error if some problems are not resolved.",
+				Action: storageVerify,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "fix",
+						Usage: "Fix the recoverable problems (file permissions, missing or inconsistent metadata, issuer certificate).",
+					},
+				},
+			},
+		},
+	}
+}
+
+func storageVerify(ctx *cli.Context) error {
+	fsStore, ok := getStore(ctx).(*storage.FileSystem)
+	if !ok {
+		return withExitCode(exitCodeConfig, errors.New("the storage verification only supports the filesystem storage"))
+	}
+
+	verifier := &storageVerifier{
+		certsPath:    fsStore.Path(baseCertificatesFolderName),
+		accountsPath: fsStore.Path(baseAccountsRootFolderName),
+	}
+
+	err := verifier.verify()
+	if err != nil {
+		return err
+	}
+
+	var fixed, unresolved int
+
+	for _, problem := range verifier.problems {
+		if ctx.Bool("fix") && problem.fix != nil {
+			if errF := problem.fix(); errF != nil {
+				problem.Message += fmt.Sprintf(" (fix failed: %v)", errF)
+			} else {
+				problem.fixed = true
+			}
+		}
+
+		switch {
+		case problem.fixed:
+			fixed++
+			fmt.Printf("%s: %s (fixed)\n", problem.Path, problem.Message)
+		case problem.fix != nil && !ctx.Bool("fix"):
+			unresolved++
+			fmt.Printf("%s: %s (fixable with --fix)\n", problem.Path, problem.Message)
+		default:
+			unresolved++
+			fmt.Printf("%s: %s\n", problem.Path, problem.Message)
+		}
+	}
+
+	fmt.Printf("%d problem(s) found, %d fixed.\n", len(verifier.problems), fixed)
+
+	if unresolved > 0 {
+		fatal(fmt.Errorf("storage: %d problem(s) not resolved", unresolved))
+	}
+
+	return nil
+}
+
+// storageProblem a problem found in the data directory.
+type storageProblem struct {
+	Path    string
+	Message string
+
+	// fix repairs the problem, nil if the problem is not recoverable.
+	fix   func() error
+	fixed bool
+}
+
+// storageVerifier verifies the integrity of the certificates and the accounts of the data directory.
+type storageVerifier struct {
+	certsPath    string
+	accountsPath string
+
+	problems []*storageProblem
+}
+
+func (v *storageVerifier) report(path string, fix func() error, format string, a ...any) {
+	v.problems = append(v.problems, &storageProblem{Path: path, Message: fmt.Sprintf(format, a...), fix: fix})
+}
+
+func (v *storageVerifier) verify() error {
+	matches, err := filepath.Glob(filepath.Join(v.certsPath, "*"+certExt))
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range matches {
+		if strings.HasSuffix(filename, issuerExt) {
+			continue
+		}
+
+		v.verifyCertificate(strings.TrimSuffix(filename, certExt))
+	}
+
+	keys, err := filepath.Glob(filepath.Join(v.certsPath, "*"+keyExt))
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range keys {
+		if !fileExists(strings.TrimSuffix(filename, keyExt) + certExt) {
+			v.report(filename, nil, "orphan private key: no certificate")
+		}
+	}
+
+	return v.verifyAccounts()
+}
+
+// verifyCertificate verifies the files of a certificate.
+// The prefix is the path of the files without the extension.
+func (v *storageVerifier) verifyCertificate(prefix string) {
+	certFile := prefix + certExt
+
+	raw, err := os.ReadFile(certFile)
+	if err != nil {
+		v.report(certFile, nil, "unable to read the certificate: %v", err)
+		return
+	}
+
+	certs, err := certcrypto.ParsePEMBundle(raw)
+	if err != nil {
+		v.report(certFile, nil, "unable to parse the certificate: %v", err)
+		return
+	}
+
+	// The private key is not stored when the certificate is obtained with a CSR.
+	if fileExists(prefix + keyExt) {
+		v.verifyPrivateKey(prefix+keyExt, certs[0])
+	}
+
+	for i := 0; i < len(certs)-1; i++ {
+		if err = certs[i].CheckSignatureFrom(certs[i+1]); err != nil {
+			v.report(certFile, nil, "invalid chain: %q is not signed by %q: %v", certs[i].Subject.CommonName, certs[i+1].Subject.CommonName, err)
+		}
+	}
+
+	if fileExists(prefix + issuerExt) {
+		v.verifyIssuer(prefix+issuerExt, certs)
+	}
+
+	v.verifyMetadata(prefix+resourceExt, certs[0])
+
+	for _, ext := range []string{keyExt, pemExt, pfxExt} {
+		v.verifyPermissions(prefix + ext)
+	}
+}
+
+func (v *storageVerifier) verifyPrivateKey(filename string, cert *x509.Certificate) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		v.report(filename, nil, "unable to read the private key: %v", err)
+		return
+	}
+
+	privateKey, err := certcrypto.ParsePEMPrivateKey(raw)
+	if err != nil {
+		v.report(filename, nil, "unable to parse the private key: %v", err)
+		return
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		v.report(filename, nil, "unsupported private key type: %T", privateKey)
+		return
+	}
+
+	publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(cert.PublicKey) {
+		v.report(filename, nil, "the private key does not match the certificate")
+	}
+}
+
+// verifyIssuer verifies that the certificate is signed by the issuer certificate.
+// The issuer file can be rewritten from the bundle if the bundle contains the issuer.
+func (v *storageVerifier) verifyIssuer(filename string, certs []*x509.Certificate) {
+	var fix func() error
+	if len(certs) > 1 && certs[0].CheckSignatureFrom(certs[1]) == nil {
+		fix = func() error {
+			return os.WriteFile(filename, encodeCertificates(certs[1:]), filePerm)
+		}
+	}
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		v.report(filename, fix, "unable to read the issuer certificate: %v", err)
+		return
+	}
+
+	issuers, err := certcrypto.ParsePEMBundle(raw)
+	if err != nil {
+		v.report(filename, fix, "unable to parse the issuer certificate: %v", err)
+		return
+	}
+
+	if err = certs[0].CheckSignatureFrom(issuers[0]); err != nil {
+		v.report(filename, fix, "the certificate is not signed by the issuer certificate: %v", err)
+	}
+}
+
+// verifyMetadata verifies the resource file of the certificate.
+// A missing resource file, or a resource file with another domain, is rewritten from the certificate.
+func (v *storageVerifier) verifyMetadata(filename string, cert *x509.Certificate) {
+	domain, err := certcrypto.GetCertificateMainDomain(cert)
+	if err != nil {
+		v.report(filename, nil, "unable to get the main domain of the certificate: %v", err)
+		return
+	}
+
+	var resource CertificateResource
+
+	save := func() error {
+		resource.Domain = domain
+
+		raw, errM := json.MarshalIndent(resource, "", "\t")
+		if errM != nil {
+			return errM
+		}
+
+		return os.WriteFile(filename, raw, filePerm)
+	}
+
+	raw, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		resource = CertificateResource{Resource: certificate.Resource{Domain: domain}}
+		v.report(filename, save, "missing metadata")
+		return
+	}
+
+	if err != nil {
+		v.report(filename, nil, "unable to read the metadata: %v", err)
+		return
+	}
+
+	if err = json.Unmarshal(raw, &resource); err != nil {
+		v.report(filename, nil, "unable to parse the metadata: %v", err)
+		return
+	}
+
+	if resource.Domain != domain {
+		v.report(filename, save, "the domain of the metadata (%s) does not match the certificate (%s)", resource.Domain, domain)
+	}
+
+	// The stored ARI CertID is used by the renewals (renewalInfo endpoint, replaced certificate),
+	// the certificates without an authority key identifier cannot be identified.
+	if resource.ARICertID != "" {
+		var certID string
+		var errC error
+
+		if len(cert.AuthorityKeyId) > 0 {
+			certID, errC = certificate.MakeARICertID(cert)
+		}
+
+		if errC == nil && certID != resource.ARICertID {
+			v.report(filename, func() error {
+				resource.ARICertID = certID
+				return save()
+			}, "the ARI CertID of the metadata (%s) does not match the certificate (%s)", resource.ARICertID, certID)
+		}
+	}
+}
+
+// verifyPermissions verifies that a file containing a private key is only readable by its owner.
+func (v *storageVerifier) verifyPermissions(filename string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return
+	}
+
+	if fi.Mode().Perm()&0o077 != 0 {
+		v.report(filename, func() error { return os.Chmod(filename, filePerm) },
+			"the file is accessible by other users (%s), expected %s", fi.Mode().Perm(), filePerm)
+	}
+}
+
+// verifyAccounts verifies the account files and the account keys.
+func (v *storageVerifier) verifyAccounts() error {
+	if !fileExists(v.accountsPath) {
+		return nil
+	}
+
+	return filepath.WalkDir(v.accountsPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return nil
+
+		case d.Name() == accountFileName:
+			raw, errR := os.ReadFile(path)
+			if errR != nil {
+				v.report(path, nil, "unable to read the account: %v", errR)
+				return nil
+			}
+
+			var account Account
+			if errR = json.Unmarshal(raw, &account); errR != nil {
+				v.report(path, nil, "unable to parse the account: %v", errR)
+			}
+
+			v.verifyPermissions(path)
+
+		case filepath.Base(filepath.Dir(path)) == baseKeysFolderName && strings.HasSuffix(path, keyExt):
+			raw, errR := os.ReadFile(path)
+			if errR != nil {
+				v.report(path, nil, "unable to read the account key: %v", errR)
+				return nil
+			}
+
+			if _, errR = certcrypto.ParsePEMPrivateKey(raw); errR != nil {
+				v.report(path, nil, "unable to parse the account key: %v", errR)
+			}
+
+			v.verifyPermissions(path)
+		}
+
+		return nil
+	})
+}
+
+func fileExists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
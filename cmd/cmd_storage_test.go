@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestCertificate(t *testing.T, dir, domain string) *rsa.PrivateKey {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+
+	cert := certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, domain+certExt), cert, filePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, domain+keyExt), certcrypto.PEMEncode(privateKey), filePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, domain+resourceExt), []byte(`{"domain":"`+domain+`"}`), filePerm))
+
+	return privateKey
+}
+
+func Test_storageVerifier_verify(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTestCertificate(t, dir, "valid.com")
+
+	// mismatched private key
+	writeTestCertificate(t, dir, "mismatch.com")
+	otherKey := writeTestCertificate(t, dir, "other.com")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mismatch.com"+keyExt), certcrypto.PEMEncode(otherKey), filePerm))
+
+	// missing and inconsistent metadata
+	writeTestCertificate(t, dir, "nometa.com")
+	require.NoError(t, os.Remove(filepath.Join(dir, "nometa.com"+resourceExt)))
+
+	writeTestCertificate(t, dir, "badmeta.com")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "badmeta.com"+resourceExt), []byte(`{"domain":"example.org"}`), filePerm))
+
+	writeTestCertificate(t, dir, "badari.com")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "badari.com"+resourceExt), []byte(`{"domain":"badari.com","ariCertId":"foo.bar"}`), filePerm))
+
+	// orphan private key
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orphan.com"+keyExt), certcrypto.PEMEncode(otherKey), filePerm))
+
+	verifier := &storageVerifier{certsPath: dir, accountsPath: filepath.Join(dir, "accounts")}
+
+	require.NoError(t, verifier.verify())
+
+	problems := map[string]bool{}
+	for _, problem := range verifier.problems {
+		problems[filepath.Base(problem.Path)] = problem.fix != nil
+	}
+
+	expected := map[string]bool{
+		"mismatch.com.key": false,
+		"nometa.com.json":  true,
+		"badmeta.com.json": true,
+		"badari.com.json":  true,
+		"orphan.com.key":   false,
+	}
+
+	assert.Equal(t, expected, problems)
+
+	for _, problem := range verifier.problems {
+		if problem.fix != nil {
+			require.NoError(t, problem.fix())
+		}
+	}
+
+	verifier = &storageVerifier{certsPath: dir, accountsPath: filepath.Join(dir, "accounts")}
+
+	require.NoError(t, verifier.verify())
+
+	assert.Len(t, verifier.problems, 2)
+}
+
+func Test_storageVerifier_verifyPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the file permissions are not checked on Windows")
+	}
+
+	dir := t.TempDir()
+
+	writeTestCertificate(t, dir, "example.com")
+	require.NoError(t, os.Chmod(filepath.Join(dir, "example.com"+keyExt), 0o644))
+
+	verifier := &storageVerifier{certsPath: dir}
+
+	require.NoError(t, verifier.verify())
+	require.Len(t, verifier.problems, 1)
+	require.NotNil(t, verifier.problems[0].fix)
+
+	require.NoError(t, verifier.problems[0].fix())
+
+	fi, err := os.Stat(filepath.Join(dir, "example.com"+keyExt))
+	require.NoError(t, err)
+
+	assert.Equal(t, filePerm, fi.Mode().Perm())
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/storage"
+)
+
+// credentialsCacheKey the key of the successful checks of the credentials of the DNS providers, relative to the root of the storage.
+const credentialsCacheKey = "cache/credentials.json"
+
+// credentialsCache the successful checks of the credentials, by DNS provider.
+type credentialsCache struct {
+	Providers map[string]*credentialsCheck `json:"providers"`
+}
+
+// credentialsCheck a successful check of the credentials of a DNS provider.
+type credentialsCheck struct {
+	// Fingerprint the hash of the environment variables of the provider: a change of the credentials invalidates the check.
+	Fingerprint string    `json:"fingerprint"`
+	VerifiedAt  time.Time `json:"verifiedAt"`
+}
+
+// ReadCredentialsCache reads the successful checks of the credentials. Returns an empty cache if there is none.
+func (s *CertificatesStorage) ReadCredentialsCache() (*credentialsCache, error) {
+	raw, err := s.store.Get(context.Background(), credentialsCacheKey)
+	if errors.Is(err, storage.ErrNotExist) {
+		return &credentialsCache{Providers: make(map[string]*credentialsCheck)}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the credentials cache: %w", err)
+	}
+
+	cache := credentialsCache{Providers: make(map[string]*credentialsCheck)}
+	if err = json.Unmarshal(raw, &cache); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the credentials cache: %w", err)
+	}
+
+	return &cache, nil
+}
+
+// SaveCredentialsCache saves the successful checks of the credentials.
+func (s *CertificatesStorage) SaveCredentialsCache(cache *credentialsCache) error {
+	raw, err := json.MarshalIndent(cache, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the credentials cache: %w", err)
+	}
+
+	return s.store.Put(context.Background(), credentialsCacheKey, raw)
+}
+
+// verifyProviderCredentials checks the credentials of the DNS provider (challenge.CredentialsVerifier),
+// unless a successful check of the same credentials is younger than the TTL.
+// Only the successful checks are cached: revoked credentials are detected at the first check after the TTL.
+func verifyProviderCredentials(ctx context.Context, certsStorage *CertificatesStorage, name string, provider challenge.Provider, ttl time.Duration, now time.Time) error {
+	fingerprint := credentialsFingerprint(name, os.Environ())
+
+	cache, err := certsStorage.ReadCredentialsCache()
+	if err != nil {
+		log.Warnf("DNS provider %s: %v", name, err)
+
+		cache = &credentialsCache{Providers: make(map[string]*credentialsCheck)}
+	}
+
+	if check, ok := cache.Providers[name]; ok && check.Fingerprint == fingerprint && now.Sub(check.VerifiedAt) < ttl {
+		log.Infof("DNS provider %s: the credentials were verified at %s, the check is skipped", name, check.VerifiedAt.Format(time.RFC3339))
+		return nil
+	}
+
+	ok, err := challenge.VerifyCredentials(ctx, provider)
+	if !ok {
+		log.Infof("DNS provider %s: the provider cannot verify its credentials", name)
+		return nil
+	}
+
+	if err != nil {
+		if _, cached := cache.Providers[name]; cached {
+			delete(cache.Providers, name)
+
+			saveCredentialsCache(certsStorage, name, cache)
+		}
+
+		return err
+	}
+
+	cache.Providers[name] = &credentialsCheck{Fingerprint: fingerprint, VerifiedAt: now}
+
+	saveCredentialsCache(certsStorage, name, cache)
+
+	return nil
+}
+
+func saveCredentialsCache(certsStorage *CertificatesStorage, name string, cache *credentialsCache) {
+	if err := certsStorage.SaveCredentialsCache(cache); err != nil {
+		log.Warnf("DNS provider %s: unable to save the credentials cache: %v", name, err)
+	}
+}
+
+// credentialsFingerprint returns the hash of the environment variables containing the name of the provider
+// (ex: CLOUDFLARE_DNS_API_TOKEN, CF1_CLOUDFLARE_DNS_API_TOKEN).
+// The values are not stored, only their hash.
+func credentialsFingerprint(name string, environ []string) string {
+	key := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+
+	var vars []string
+
+	for _, item := range environ {
+		envName, _, _ := strings.Cut(item, "=")
+		if strings.Contains(envName, key) {
+			vars = append(vars, item)
+		}
+	}
+
+	slices.Sort(vars)
+
+	hash := sha256.New()
+
+	for _, item := range vars {
+		_, _ = hash.Write([]byte(item))
+		_, _ = hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type verifierMock struct {
+	calls int
+	err   error
+}
+
+func (m *verifierMock) Present(_, _, _ string) error { return nil }
+
+func (m *verifierMock) CleanUp(_, _, _ string) error { return nil }
+
+func (m *verifierMock) VerifyCredentials(_ context.Context) error {
+	m.calls++
+	return m.err
+}
+
+func Test_verifyProviderCredentials(t *testing.T) {
+	certsStorage := newTestCertificatesStorage(t)
+
+	provider := &verifierMock{}
+
+	now := time.Now().UTC()
+
+	err := verifyProviderCredentials(context.Background(), certsStorage, "mock", provider, time.Hour, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.calls)
+
+	// the successful check is cached.
+	err = verifyProviderCredentials(context.Background(), certsStorage, "mock", provider, time.Hour, now.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.calls)
+
+	// the check is repeated after the TTL, and the revoked credentials are detected.
+	provider.err = errors.New("invalid token")
+
+	err = verifyProviderCredentials(context.Background(), certsStorage, "mock", provider, time.Hour, now.Add(2*time.Hour))
+	require.EqualError(t, err, "invalid token")
+	assert.Equal(t, 2, provider.calls)
+
+	// the failures are not cached.
+	err = verifyProviderCredentials(context.Background(), certsStorage, "mock", provider, time.Hour, now.Add(2*time.Hour))
+	require.Error(t, err)
+	assert.Equal(t, 3, provider.calls)
+
+	cache, err := certsStorage.ReadCredentialsCache()
+	require.NoError(t, err)
+	assert.NotContains(t, cache.Providers, "mock")
+}
+
+func Test_verifyProviderCredentials_fingerprint(t *testing.T) {
+	certsStorage := newTestCertificatesStorage(t)
+
+	provider := &verifierMock{}
+
+	now := time.Now().UTC()
+
+	t.Setenv("MOCK_API_TOKEN", "a")
+
+	err := verifyProviderCredentials(context.Background(), certsStorage, "mock", provider, time.Hour, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.calls)
+
+	// new credentials invalidate the cached check.
+	t.Setenv("MOCK_API_TOKEN", "b")
+
+	err = verifyProviderCredentials(context.Background(), certsStorage, "mock", provider, time.Hour, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, provider.calls)
+}
+
+func Test_credentialsFingerprint(t *testing.T) {
+	fingerprint := credentialsFingerprint("cloudflare", []string{"CLOUDFLARE_DNS_API_TOKEN=a", "HOME=/root"})
+
+	// the other variables are ignored.
+	assert.Equal(t, fingerprint, credentialsFingerprint("cloudflare", []string{"HOME=/home/lego", "CLOUDFLARE_DNS_API_TOKEN=a"}))
+
+	assert.NotEqual(t, fingerprint, credentialsFingerprint("cloudflare", []string{"CLOUDFLARE_DNS_API_TOKEN=b"}))
+	assert.NotEqual(t, fingerprint, credentialsFingerprint("cloudflare", []string{"CLOUDFLARE_DNS_API_TOKEN=a", "CF1_CLOUDFLARE_DNS_API_TOKEN=a"}))
+}
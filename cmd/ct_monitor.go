@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/storage"
+)
+
+// ctStateKey the key of the state of the CT monitor, relative to the root of the storage.
+const ctStateKey = "ct/state.json"
+
+const defaultCTSearchURL = "https://crt.sh/"
+
+// Environment variables of the CT hook.
+const (
+	ctEnvDomain    = "LEGO_CT_DOMAIN"
+	ctEnvNames     = "LEGO_CT_NAMES"
+	ctEnvSerial    = "LEGO_CT_SERIAL"
+	ctEnvIssuer    = "LEGO_CT_ISSUER"
+	ctEnvNotBefore = "LEGO_CT_NOT_BEFORE"
+	ctEnvNotAfter  = "LEGO_CT_NOT_AFTER"
+)
+
+// ctState the state of the CT monitor.
+type ctState struct {
+	// Since the start of the monitoring: the certificates issued before are ignored.
+	Since time.Time `json:"since"`
+	// Known the serial numbers of the certificates issued by lego (hexadecimal), with their main domain.
+	Known map[string]string `json:"known"`
+	// Reported the serial numbers of the unexpected certificates already reported, with the date of the report.
+	Reported map[string]time.Time `json:"reported"`
+}
+
+// ReadCTState reads the state of the CT monitor. Returns an empty state if there is none.
+func (s *CertificatesStorage) ReadCTState() (*ctState, error) {
+	state := &ctState{Known: make(map[string]string), Reported: make(map[string]time.Time)}
+
+	raw, err := s.store.Get(context.Background(), ctStateKey)
+	if errors.Is(err, storage.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the CT monitor state: %w", err)
+	}
+
+	if err = json.Unmarshal(raw, state); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the CT monitor state: %w", err)
+	}
+
+	return state, nil
+}
+
+// SaveCTState saves the state of the CT monitor.
+func (s *CertificatesStorage) SaveCTState(state *ctState) error {
+	raw, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the CT monitor state: %w", err)
+	}
+
+	return s.store.Put(context.Background(), ctStateKey, raw)
+}
+
+// ctLogEntry a certificate found in the CT logs (crt.sh JSON format).
+type ctLogEntry struct {
+	ID           int64  `json:"id"`
+	IssuerName   string `json:"issuer_name"`
+	CommonName   string `json:"common_name"`
+	NameValue    string `json:"name_value"`
+	SerialNumber string `json:"serial_number"`
+	NotBefore    string `json:"not_before"`
+	NotAfter     string `json:"not_after"`
+}
+
+// names returns the domains of the certificate (name_value contains a domain per line).
+func (e ctLogEntry) names() []string {
+	var names []string
+
+	for _, name := range strings.Split(e.NameValue, "\n") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// notBefore parses the date of issuance (UTC, without timezone).
+func (e ctLogEntry) notBefore() (time.Time, error) {
+	return time.Parse("2006-01-02T15:04:05", e.NotBefore)
+}
+
+// ctMonitor watches the CT logs for the certificates issued for the managed domains, without lego:
+// the certificates issued after the start of the monitoring, and not found in the data directory.
+// The CT logs are searched with a crt.sh compatible service.
+type ctMonitor struct {
+	certsStorage *CertificatesStorage
+	client       *http.Client
+	searchURL    string
+	hook         string
+}
+
+// managedCertificate a certificate of the data directory.
+type managedCertificate struct {
+	Name    string
+	Domains []string
+	Serial  string
+}
+
+// run polls the CT logs until the context is canceled.
+func (m *ctMonitor) run(ctx context.Context, interval time.Duration) {
+	log.Infof("ct: monitoring the certificates of the managed domains every %s", interval)
+
+	for {
+		err := m.poll(ctx, time.Now().UTC())
+		if err != nil && ctx.Err() == nil {
+			log.Warnf("ct: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// poll searches the certificates of the managed domains in the CT logs, and reports the unexpected certificates.
+func (m *ctMonitor) poll(ctx context.Context, now time.Time) error {
+	state, err := m.certsStorage.ReadCTState()
+	if err != nil {
+		return err
+	}
+
+	if state.Since.IsZero() {
+		state.Since = now
+	}
+
+	managed, err := loadManagedCertificates(m.certsStorage)
+	if err != nil {
+		return fmt.Errorf("unable to load the certificates: %w", err)
+	}
+
+	// the certificates renewed since the last poll are added to the known certificates.
+	for _, cert := range managed {
+		state.Known[cert.Serial] = cert.Name
+	}
+
+	defer func() {
+		if errS := m.certsStorage.SaveCTState(state); errS != nil {
+			log.Warnf("ct: %v", errS)
+		}
+	}()
+
+	var errs []error
+
+	for _, domain := range searchDomains(managed) {
+		entries, err := m.search(ctx, domain)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			errs = append(errs, err)
+
+			continue
+		}
+
+		for _, entry := range entries {
+			m.check(state, domain, entry, now)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// check reports the certificate if it has been issued without lego after the start of the monitoring.
+func (m *ctMonitor) check(state *ctState, domain string, entry ctLogEntry, now time.Time) {
+	serial := normalizeSerial(entry.SerialNumber)
+
+	if _, ok := state.Known[serial]; ok {
+		return
+	}
+
+	if _, ok := state.Reported[serial]; ok {
+		return
+	}
+
+	if !slices.Contains(entry.names(), domain) {
+		return
+	}
+
+	notBefore, err := entry.notBefore()
+	if err != nil {
+		log.Warnf("[%s] ct: certificate %s: invalid notBefore: %q", domain, serial, entry.NotBefore)
+		return
+	}
+
+	// the backdating of the certificates by the CAs (ex: 1 hour) is tolerated.
+	if notBefore.Before(state.Since.Add(-time.Hour)) {
+		return
+	}
+
+	state.Reported[serial] = now
+
+	log.Warnf("[%s] ct: unexpected certificate issued by %q (serial: %s, names: %s, not before: %s): it has not been issued by lego for this data directory",
+		domain, entry.IssuerName, serial, strings.Join(entry.names(), ","), entry.NotBefore)
+
+	meta := map[string]string{
+		ctEnvDomain:    domain,
+		ctEnvNames:     strings.Join(entry.names(), ","),
+		ctEnvSerial:    serial,
+		ctEnvIssuer:    entry.IssuerName,
+		ctEnvNotBefore: entry.NotBefore,
+		ctEnvNotAfter:  entry.NotAfter,
+	}
+
+	err = launchHook(m.hook, meta)
+	if err != nil {
+		log.Warnf("[%s] ct: %v", domain, err)
+	}
+}
+
+// search returns the certificates (and precertificates) of a domain found in the CT logs.
+func (m *ctMonitor) search(ctx context.Context, domain string) ([]ctLogEntry, error) {
+	endpoint, err := url.Parse(m.searchURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CT search URL: %w", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("q", domain)
+	query.Set("output", "json")
+	query.Set("exclude", "expired")
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] unable to create the CT search request: %w", domain, err)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] unable to search the CT logs: %w", domain, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] unable to read the CT search response: %w", domain, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("[%s] CT search: unexpected status code: %d: %s", domain, resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	var entries []ctLogEntry
+
+	err = json.Unmarshal(raw, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] unable to unmarshal the CT search response: %w", domain, err)
+	}
+
+	return entries, nil
+}
+
+// loadManagedCertificates returns the certificates of the data directory.
+func loadManagedCertificates(certsStorage *CertificatesStorage) ([]managedCertificate, error) {
+	files, err := certsStorage.ListFiles(certExt)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []managedCertificate
+
+	for _, file := range files {
+		data, err := certsStorage.ReadFile(file, certExt)
+		if err != nil {
+			return nil, err
+		}
+
+		cert, err := certcrypto.ParsePEMCertificate(data)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := certcrypto.GetCertificateMainDomain(cert)
+		if err != nil {
+			return nil, err
+		}
+
+		certs = append(certs, managedCertificate{
+			Name:    name,
+			Domains: certcrypto.ExtractDomains(cert),
+			Serial:  normalizeSerial(cert.SerialNumber.Text(16)),
+		})
+	}
+
+	return certs, nil
+}
+
+// searchDomains returns the domains of the certificates (in lower case, without duplicates).
+func searchDomains(certs []managedCertificate) []string {
+	var domains []string
+
+	for _, cert := range certs {
+		for _, domain := range cert.Domains {
+			domain = strings.ToLower(domain)
+
+			// the IP addresses are not searched.
+			if net.ParseIP(domain) != nil {
+				continue
+			}
+
+			if !slices.Contains(domains, domain) {
+				domains = append(domains, domain)
+			}
+		}
+	}
+
+	return domains
+}
+
+// normalizeSerial returns the serial number in lower case hexadecimal, without the leading zeros and the separators.
+func normalizeSerial(serial string) string {
+	serial = strings.ToLower(strings.ReplaceAll(serial, ":", ""))
+
+	serial = strings.TrimLeft(serial, "0")
+	if serial == "" {
+		return "0"
+	}
+
+	return serial
+}
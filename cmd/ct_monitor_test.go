@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ctMonitor_poll(t *testing.T) {
+	certsStorage := newTestCertificatesStorage(t)
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(0x0a1b),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com", "*.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+
+	require.NoError(t, certsStorage.WriteFile("example.com", certExt, certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der))))
+
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	var queries []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		queries = append(queries, req.URL.Query().Get("q"))
+
+		entries := []ctLogEntry{
+			// issued by lego.
+			{ID: 1, IssuerName: "C=US, O=Let's Encrypt, CN=R3", NameValue: "example.com\n*.example.com", SerialNumber: "0a1b", NotBefore: "2024-03-01T11:30:00"},
+			// issued before the start of the monitoring.
+			{ID: 2, IssuerName: "C=US, O=Let's Encrypt, CN=R3", NameValue: "example.com", SerialNumber: "0c", NotBefore: "2024-01-01T00:00:00"},
+			// unexpected.
+			{ID: 3, IssuerName: "C=US, O=Other CA, CN=Other", NameValue: "example.com\nwww.example.com", SerialNumber: "00ff01", NotBefore: "2024-03-01T11:45:00"},
+		}
+
+		_ = json.NewEncoder(rw).Encode(entries)
+	}))
+	t.Cleanup(server.Close)
+
+	monitor := &ctMonitor{
+		certsStorage: certsStorage,
+		client:       server.Client(),
+		searchURL:    server.URL,
+	}
+
+	err = monitor.poll(context.Background(), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com", "*.example.com"}, queries)
+
+	state, err := certsStorage.ReadCTState()
+	require.NoError(t, err)
+
+	assert.Equal(t, now, state.Since)
+	assert.Equal(t, map[string]string{"a1b": "example.com"}, state.Known)
+	assert.Equal(t, map[string]time.Time{"ff01": now}, state.Reported)
+
+	// the unexpected certificate is reported once.
+	err = monitor.poll(context.Background(), now.Add(time.Hour))
+	require.NoError(t, err)
+
+	state, err = certsStorage.ReadCTState()
+	require.NoError(t, err)
+
+	assert.Equal(t, now, state.Since)
+	assert.Equal(t, map[string]time.Time{"ff01": now}, state.Reported)
+}
+
+func Test_normalizeSerial(t *testing.T) {
+	testCases := []struct {
+		serial   string
+		expected string
+	}{
+		{serial: "0A1B", expected: "a1b"},
+		{serial: "0a:1b", expected: "a1b"},
+		{serial: "00", expected: "0"},
+		{serial: "ff01", expected: "ff01"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, normalizeSerial(test.serial), test.serial)
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/providers/deploy"
+	"github.com/urfave/cli/v2"
+)
+
+// loadDeployConfig loads the deployment configuration, if defined:
+// the targets of the configuration file, then the targets of the "deploy" options.
+// The configuration is loaded before obtaining the certificates to detect the errors early.
+func loadDeployConfig(ctx *cli.Context) *deploy.Config {
+	filename := ctx.String("deploy-config")
+	targets := ctx.StringSlice("deploy")
+
+	if filename == "" && len(targets) == 0 {
+		return nil
+	}
+
+	cfg := &deploy.Config{}
+
+	if filename != "" {
+		var err error
+
+		cfg, err = deploy.LoadConfig(filename)
+		if err != nil {
+			fatalConfig(err)
+		}
+	}
+
+	for _, value := range groupDeployTargets(targets) {
+		target, err := deploy.ParseTarget(value)
+		if err != nil {
+			fatalConfigf("Invalid --deploy: %q: %v", value, err)
+		}
+
+		cfg.Targets = append(cfg.Targets, target)
+	}
+
+	return cfg
+}
+
+// groupDeployTargets rebuilds the definitions of the targets split by the comma separator of the slice flags:
+// a value with an equal sign, and no colon before it, is an option of the previous target,
+// a value without equal sign is a new target without options (ex: `haproxy`).
+func groupDeployTargets(values []string) []string {
+	var targets []string
+
+	for _, value := range values {
+		key, _, option := strings.Cut(value, "=")
+
+		if len(targets) > 0 && option && !strings.Contains(key, ":") {
+			targets[len(targets)-1] += "," + value
+			continue
+		}
+
+		targets = append(targets, value)
+	}
+
+	return targets
+}
+
+// deployCertificate deploys the certificate to the targets of the deployment configuration.
+// The IDs of the remote objects are stored in the resource file, even if a deployment fails,
+// to update the remote objects during the next renewals.
+func deployCertificate(ctx *cli.Context, cfg *deploy.Config, certsStorage *CertificatesStorage, certRes *CertificateResource, summary *runSummary) {
+	if cfg == nil {
+		return
+	}
+
+	start := time.Now()
+
+	if certRes.Deployments == nil {
+		certRes.Deployments = make(map[string]string)
+	}
+
+	err := cfg.Deploy(ctx.Context, &certRes.Resource, certRes.Deployments)
+
+	if len(certRes.Deployments) > 0 {
+		certsStorage.SaveResourceMetadata(certRes)
+	}
+
+	summary.phase("deploy", start)
+
+	if err != nil {
+		summary.write()
+
+		fatal(withExitCode(exitCodePartial, fmt.Errorf("could not deploy the certificate for domain %s\n\t%w", certRes.Domain, err)))
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_groupDeployTargets(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		values   []string
+		expected []string
+	}{
+		{
+			desc: "options split by the comma separator",
+			values: []string{
+				"file:certificate=/etc/ssl/{domain}.crt",
+				"key=/etc/ssl/{domain}.key",
+				"certificate_mode=0640",
+				"haproxy:socket=unix:/run/haproxy/admin.sock",
+				"certificate=/etc/haproxy/certs/{domain}.pem",
+				"vault:path=certificates/{domain}",
+			},
+			expected: []string{
+				"file:certificate=/etc/ssl/{domain}.crt,key=/etc/ssl/{domain}.key,certificate_mode=0640",
+				"haproxy:socket=unix:/run/haproxy/admin.sock,certificate=/etc/haproxy/certs/{domain}.pem",
+				"vault:path=certificates/{domain}",
+			},
+		},
+		{
+			desc: "type without options",
+			values: []string{
+				"file:certificate=/etc/ssl/{domain}.crt",
+				"haproxy",
+				"nginx",
+			},
+			expected: []string{
+				"file:certificate=/etc/ssl/{domain}.crt",
+				"haproxy",
+				"nginx",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, groupDeployTargets(test.values))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns"
+	"github.com/urfave/cli/v2"
+)
+
+// dnsCleanupJournalName the name of the file containing the records to remove by the gc command (--dns.deferred-cleanup).
+const dnsCleanupJournalName = "dns-cleanup.json"
+
+// dnsCleanupEntry a TXT record to remove by the gc command.
+type dnsCleanupEntry struct {
+	Provider string    `json:"provider"`
+	Domain   string    `json:"domain"`
+	Token    string    `json:"token"`
+	KeyAuth  string    `json:"keyAuth"`
+	Created  time.Time `json:"created"`
+}
+
+// dnsCleanupJournal records the TXT records to remove, instead of removing them (--dns.deferred-cleanup).
+// The records are removed later by the gc command, with the clean-up credentials.
+type dnsCleanupJournal struct {
+	filename string
+	provider string
+
+	mu sync.Mutex
+}
+
+func newDNSCleanupJournal(ctx *cli.Context) *dnsCleanupJournal {
+	return &dnsCleanupJournal{
+		filename: filepath.Join(ctx.String("path"), dnsCleanupJournalName),
+		provider: ctx.String("dns"),
+	}
+}
+
+// Present is never called: the journal is only used to remove the records.
+func (j *dnsCleanupJournal) Present(_, _, _ string) error {
+	return errors.New("the clean-up journal cannot create records")
+}
+
+// CleanUp records the TXT record in the journal.
+func (j *dnsCleanupJournal) CleanUp(domain, token, keyAuth string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		return err
+	}
+
+	entries = append(entries, dnsCleanupEntry{
+		Provider: j.provider,
+		Domain:   domain,
+		Token:    token,
+		KeyAuth:  keyAuth,
+		Created:  time.Now().UTC(),
+	})
+
+	return j.write(entries)
+}
+
+func (j *dnsCleanupJournal) read() ([]dnsCleanupEntry, error) {
+	raw, err := os.ReadFile(j.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the clean-up journal: %w", err)
+	}
+
+	var entries []dnsCleanupEntry
+
+	err = json.Unmarshal(raw, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the clean-up journal %s: %w", j.filename, err)
+	}
+
+	return entries, nil
+}
+
+func (j *dnsCleanupJournal) write(entries []dnsCleanupEntry) error {
+	if len(entries) == 0 {
+		err := os.Remove(j.filename)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the clean-up journal: %w", err)
+	}
+
+	return os.WriteFile(j.filename, raw, filePerm)
+}
+
+// collect removes the records of the journal created by the provider of the journal, with the clean-up provider.
+// The records that cannot be removed are kept in the journal.
+func (j *dnsCleanupJournal) collect(provider challenge.Provider) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		return 0, err
+	}
+
+	var remaining []dnsCleanupEntry
+	var removed int
+	var errAll error
+
+	for _, entry := range entries {
+		if entry.Provider != j.provider {
+			remaining = append(remaining, entry)
+			continue
+		}
+
+		err = provider.CleanUp(entry.Domain, entry.Token, entry.KeyAuth)
+		if err != nil {
+			errAll = errors.Join(errAll, fmt.Errorf("[%s] %w", entry.Domain, err))
+			remaining = append(remaining, entry)
+
+			continue
+		}
+
+		removed++
+	}
+
+	err = j.write(remaining)
+	if err != nil {
+		return removed, errors.Join(errAll, err)
+	}
+
+	return removed, errAll
+}
+
+// newDNSCleanupProvider creates the DNS provider used to remove the records:
+// the environment variables prefixed by --dns.cleanup-env-prefix have priority over the non-prefixed ones.
+func newDNSCleanupProvider(ctx *cli.Context) (challenge.Provider, error) {
+	return dns.NewDNSChallengeProviderByNameFromEnv(ctx.String("dns"), env.WithPrefix(ctx.String("dns.cleanup-env-prefix")))
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCleanUpProvider struct {
+	removed []string
+	fail    map[string]bool
+}
+
+func (f *fakeCleanUpProvider) Present(_, _, _ string) error { return nil }
+
+func (f *fakeCleanUpProvider) CleanUp(domain, _, _ string) error {
+	if f.fail[domain] {
+		return errors.New("oops")
+	}
+
+	f.removed = append(f.removed, domain)
+
+	return nil
+}
+
+func Test_dnsCleanupJournal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), dnsCleanupJournalName)
+
+	journal := &dnsCleanupJournal{filename: filename, provider: "cloudflare"}
+
+	require.NoError(t, journal.CleanUp("a.example.com", "token-a", "keyAuth-a"))
+	require.NoError(t, journal.CleanUp("b.example.com", "token-b", "keyAuth-b"))
+
+	other := &dnsCleanupJournal{filename: filename, provider: "route53"}
+	require.NoError(t, other.CleanUp("c.example.com", "token-c", "keyAuth-c"))
+
+	provider := &fakeCleanUpProvider{fail: map[string]bool{"b.example.com": true}}
+
+	removed, err := journal.collect(provider)
+	require.EqualError(t, err, "[b.example.com] oops")
+
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []string{"a.example.com"}, provider.removed)
+
+	entries, err := journal.read()
+	require.NoError(t, err)
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "b.example.com", entries[0].Domain)
+	assert.Equal(t, "keyAuth-b", entries[0].KeyAuth)
+	assert.Equal(t, "c.example.com", entries[1].Domain)
+	assert.Equal(t, "route53", entries[1].Provider)
+
+	provider.fail = nil
+
+	removed, err = journal.collect(provider)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	removed, err = other.collect(provider)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	assert.NoFileExists(t, filename)
+}
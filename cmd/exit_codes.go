@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes.
+// They allow the wrapper scripts and the monitoring to react to the class of a failure.
+const (
+	exitCodeError        = 1 // unclassified error.
+	exitCodeConfig       = 2 // invalid configuration (flags, environment variables, files).
+	exitCodeProviderAuth = 3 // authentication failure of the challenge provider.
+	exitCodePropagation  = 4 // DNS propagation timeout.
+	exitCodeRateLimited  = 5 // rate limit of the CA.
+	exitCodePartial      = 6 // partial success: the certificate has been obtained, but the deployment or the hook failed.
+)
+
+// exitError an error associated with an exit code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode associates an exit code with an error.
+// Returns nil if the error is nil.
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &exitError{code: code, err: err}
+}
+
+// ExitCode returns the exit code matching the class of the error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+
+	var problem *acme.ProblemDetails
+	if errors.As(err, &problem) && problem.Type == acme.RateLimitedErr {
+		return exitCodeRateLimited
+	}
+
+	if errors.Is(err, dns01.ErrPropagationTimeout) {
+		return exitCodePropagation
+	}
+
+	// Only the categorized errors of the providers are authentication failures:
+	// the ACME errors (ex: urn:ietf:params:acme:error:unauthorized) are not related to the providers.
+	if challenge.GetErrorCategory(err) == challenge.ErrorCategoryAuth {
+		return exitCodeProviderAuth
+	}
+
+	return exitCodeError
+}
+
+// OnUsageError handles the errors related to the flags (invalid value, unknown flag) as configuration errors.
+func OnUsageError(_ *cli.Context, err error, _ bool) error {
+	return withExitCode(exitCodeConfig, fmt.Errorf("incorrect usage: %w", err))
+}
+
+// fatal logs the error and exits with the exit code matching the class of the error.
+func fatal(err error) {
+	log.Print(err)
+	os.Exit(ExitCode(err))
+}
+
+// fatalConfig logs a configuration error and exits.
+func fatalConfig(args ...interface{}) {
+	fatal(withExitCode(exitCodeConfig, errors.New(fmt.Sprint(args...))))
+}
+
+// fatalConfigf logs a configuration error and exits.
+func fatalConfigf(format string, args ...interface{}) {
+	fatal(withExitCode(exitCodeConfig, fmt.Errorf(format, args...)))
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected int
+	}{
+		{
+			desc:     "no error",
+			expected: 0,
+		},
+		{
+			desc:     "unclassified",
+			err:      errors.New("boom"),
+			expected: exitCodeError,
+		},
+		{
+			desc:     "explicit exit code",
+			err:      fmt.Errorf("wrapped: %w", withExitCode(exitCodePartial, errors.New("hook failed"))),
+			expected: exitCodePartial,
+		},
+		{
+			desc:     "configuration",
+			err:      OnUsageError(nil, errors.New("flag provided but not defined: -foo"), false),
+			expected: exitCodeConfig,
+		},
+		{
+			desc: "rate limit",
+			err: fmt.Errorf("could not obtain certificates: %w", &acme.ProblemDetails{
+				Type:   acme.RateLimitedErr,
+				Detail: "too many certificates already issued",
+			}),
+			expected: exitCodeRateLimited,
+		},
+		{
+			desc:     "propagation timeout",
+			err:      fmt.Errorf("example.com: %w: propagation: time limit exceeded", dns01.ErrPropagationTimeout),
+			expected: exitCodePropagation,
+		},
+		{
+			desc:     "provider authentication",
+			err:      fmt.Errorf("[example.com] acme: error presenting token: %w", challenge.AuthError(errors.New("bad secret"))),
+			expected: exitCodeProviderAuth,
+		},
+		{
+			desc:     "uncategorized error with authentication words",
+			err:      errors.New("[example.com] acme: error presenting token: foo: zone 403-unauthorized-key not found"),
+			expected: exitCodeError,
+		},
+		{
+			desc: "ACME unauthorized",
+			err: &acme.ProblemDetails{
+				Type:   "urn:ietf:params:acme:error:unauthorized",
+				Detail: "Incorrect TXT record",
+			},
+			expected: exitCodeError,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, ExitCode(test.err))
+		})
+	}
+}
+
+func Test_withExitCode(t *testing.T) {
+	assert.NoError(t, withExitCode(exitCodePartial, nil))
+
+	err := withExitCode(exitCodePartial, errors.New("boom"))
+	assert.EqualError(t, err, "boom")
+}
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/log"
+)
+
+// exportedCertificate a stored certificate to export.
+type exportedCertificate struct {
+	Domain      string
+	SANs        []string
+	Certificate []byte
+	Issuer      []byte
+	PrivateKey  []byte
+	CertURL     string
+}
+
+// readExportedCertificate reads a stored certificate and its private key.
+func readExportedCertificate(certsStorage *CertificatesStorage, domain string) (*exportedCertificate, error) {
+	certPEM, err := certsStorage.ReadFile(domain, certExt)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := certsStorage.ReadFile(domain, keyExt)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates, err := certcrypto.ParsePEMBundle(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	// The name of the certificate in the storage is sanitized (ex: wildcard).
+	mainDomain, err := certcrypto.GetCertificateMainDomain(certificates[0])
+	if err != nil {
+		return nil, err
+	}
+
+	exported := &exportedCertificate{
+		Domain:      mainDomain,
+		Certificate: certPEM,
+		PrivateKey:  keyPEM,
+	}
+
+	for _, san := range certcrypto.ExtractDomains(certificates[0]) {
+		if san != mainDomain {
+			exported.SANs = append(exported.SANs, san)
+		}
+	}
+
+	if certsStorage.ExistsFile(domain, issuerExt) {
+		exported.Issuer, err = certsStorage.ReadFile(domain, issuerExt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if certsStorage.ExistsFile(domain, resourceExt) {
+		exported.CertURL = certsStorage.ReadResource(domain).CertURL
+	}
+
+	return exported, nil
+}
+
+// traefikStore the content of the Traefik ACME storage file (acme.json): the key is the name of the certificates resolver.
+type traefikStore map[string]*traefikResolver
+
+type traefikResolver struct {
+	Account      json.RawMessage       `json:"Account"`
+	Certificates []*traefikCertificate `json:"Certificates"`
+}
+
+type traefikCertificate struct {
+	Domain      traefikDomain `json:"domain"`
+	Certificate []byte        `json:"certificate"`
+	Key         []byte        `json:"key"`
+	Store       string        `json:"Store"`
+}
+
+type traefikDomain struct {
+	Main string   `json:"main"`
+	SANs []string `json:"sans,omitempty"`
+}
+
+// exportTraefik writes the certificates into a Traefik ACME storage file.
+// The existing content of the file (account, certificates of other domains or resolvers) is preserved.
+func exportTraefik(filename, resolver string, certificates []*exportedCertificate) error {
+	store := traefikStore{}
+
+	data, err := os.ReadFile(filename)
+	switch {
+	case err == nil:
+		if len(data) > 0 {
+			err = json.Unmarshal(data, &store)
+			if err != nil {
+				return fmt.Errorf("invalid Traefik storage file %s: %w", filename, err)
+			}
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	if store[resolver] == nil {
+		store[resolver] = &traefikResolver{Account: json.RawMessage("null")}
+	}
+
+	for _, cert := range certificates {
+		tc := &traefikCertificate{
+			Domain:      traefikDomain{Main: cert.Domain, SANs: cert.SANs},
+			Certificate: cert.Certificate,
+			Key:         cert.PrivateKey,
+			Store:       "default",
+		}
+
+		var found bool
+		for i, existing := range store[resolver].Certificates {
+			if existing.Domain.Main == cert.Domain {
+				store[resolver].Certificates[i] = tc
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			store[resolver].Certificates = append(store[resolver].Certificates, tc)
+		}
+	}
+
+	data, err = json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Traefik requires the permissions 600 on the storage file.
+	return os.WriteFile(filename, data, filePerm)
+}
+
+// caddyResource the metadata file of a certificate in the Caddy storage.
+type caddyResource struct {
+	SANs       []string        `json:"sans,omitempty"`
+	IssuerData caddyIssuerData `json:"issuer_data"`
+}
+
+type caddyIssuerData struct {
+	URL string `json:"url,omitempty"`
+	CA  string `json:"ca,omitempty"`
+}
+
+// exportCaddy writes the certificates into the Caddy storage (data directory):
+//
+//	<root>/certificates/<issuer>/<name>/<name>.crt
+//	<root>/certificates/<issuer>/<name>/<name>.key
+//	<root>/certificates/<issuer>/<name>/<name>.json
+func exportCaddy(root, caURL, issuer string, certificates []*exportedCertificate) error {
+	if issuer == "" {
+		issuer = caddyIssuerKey(caURL)
+	}
+
+	for _, cert := range certificates {
+		name := caddySafeName(cert.Domain)
+
+		dir := filepath.Join(root, "certificates", issuer, name)
+
+		err := os.MkdirAll(dir, 0o700)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(dir, name+".crt"), cert.Certificate, filePerm)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(dir, name+".key"), cert.PrivateKey, filePerm)
+		if err != nil {
+			return err
+		}
+
+		resource := caddyResource{
+			SANs:       append([]string{cert.Domain}, cert.SANs...),
+			IssuerData: caddyIssuerData{URL: cert.CertURL, CA: caURL},
+		}
+
+		data, err := json.MarshalIndent(resource, "", "\t")
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(dir, name+".json"), data, filePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// caddyIssuerKey the name of the issuer directory used by Caddy for an ACME CA
+// (ex: acme-v02.api.letsencrypt.org-directory).
+func caddyIssuerKey(caURL string) string {
+	key := caURL
+
+	if u, err := url.Parse(caURL); err == nil && u.Host != "" {
+		key = u.Host + u.Path
+	}
+
+	return caddySafeName(strings.ReplaceAll(strings.Trim(key, "/"), "/", "-"))
+}
+
+// caddySafeName the name used by Caddy for the files and directories of the storage.
+func caddySafeName(name string) string {
+	return strings.NewReplacer(
+		" ", "_",
+		"+", "_plus_",
+		"*", "wildcard_",
+		":", "-",
+		"..", "",
+	).Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// SPIFFE file names, compatible with the spiffe-helper.
+const (
+	spiffeSVIDFile   = "svid.pem"
+	spiffeKeyFile    = "svid_key.pem"
+	spiffeBundleFile = "svid_bundle.pem"
+)
+
+// exportSPIFFE writes the certificates as X.509 SVID files, the layout used by the spiffe-helper:
+//
+//	<root>/<name>/svid.pem         (certificate chain: leaf and intermediates)
+//	<root>/<name>/svid_key.pem     (private key, PKCS #8)
+//	<root>/<name>/svid_bundle.pem  (trust bundle)
+//
+// The trust bundle is the content of the bundle file, or the issuer certificates if the bundle file is not defined.
+func exportSPIFFE(root, bundleFile string, certificates []*exportedCertificate) error {
+	var trustBundle []byte
+	if bundleFile != "" {
+		var err error
+		trustBundle, err = os.ReadFile(bundleFile)
+		if err != nil {
+			return fmt.Errorf("unable to read the trust bundle: %w", err)
+		}
+	}
+
+	for _, cert := range certificates {
+		chain, err := certcrypto.ParsePEMBundle(cert.Certificate)
+		if err != nil {
+			return fmt.Errorf("%s: %w", cert.Domain, err)
+		}
+
+		if !hasSPIFFEID(chain[0]) {
+			log.Warnf("[%s] spiffe: the certificate has no SPIFFE ID (spiffe:// URI SAN), the workloads must identify it by its DNS names.", cert.Domain)
+		}
+
+		var issuers []*x509.Certificate
+		if len(cert.Issuer) > 0 {
+			issuers, err = certcrypto.ParsePEMBundle(cert.Issuer)
+			if err != nil {
+				return fmt.Errorf("%s: invalid issuer certificate: %w", cert.Domain, err)
+			}
+		}
+
+		// The stored certificate doesn't contain the issuer with --no-bundle.
+		if len(chain) == 1 {
+			chain = append(chain, issuers...)
+		}
+
+		key, err := certcrypto.ParsePEMPrivateKey(cert.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("%s: %w", cert.Domain, err)
+		}
+
+		keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", cert.Domain, err)
+		}
+
+		bundle := trustBundle
+		if bundleFile == "" {
+			bundle = encodeCertificates(issuers)
+		}
+
+		dir := filepath.Join(root, sanitizedDomain(cert.Domain))
+
+		err = os.MkdirAll(dir, 0o700)
+		if err != nil {
+			return err
+		}
+
+		files := map[string][]byte{
+			spiffeSVIDFile:   encodeCertificates(chain),
+			spiffeKeyFile:    pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+			spiffeBundleFile: bundle,
+		}
+
+		for name, data := range files {
+			err = os.WriteFile(filepath.Join(dir, name), data, filePerm)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasSPIFFEID checks if the certificate contains a SPIFFE ID (URI SAN with the spiffe scheme).
+func hasSPIFFEID(cert *x509.Certificate) bool {
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func encodeCertificates(certs []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		buf.Write(certcrypto.PEMEncode(certcrypto.DERCertificateBytes(cert.Raw)))
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_exportTraefik(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "acme.json")
+
+	existing := `{
+  "letsencrypt": {"Account": {"Email": "test@example.com"}, "Certificates": []},
+  "lego": {
+    "Account": null,
+    "Certificates": [
+      {"domain": {"main": "example.com"}, "certificate": "b2xk", "key": "b2xk", "Store": "default"},
+      {"domain": {"main": "example.org"}, "certificate": "b2xk", "key": "b2xk", "Store": "default"}
+    ]
+  }
+}`
+
+	err := os.WriteFile(filename, []byte(existing), 0o600)
+	require.NoError(t, err)
+
+	certificates := []*exportedCertificate{
+		{Domain: "example.com", SANs: []string{"www.example.com"}, Certificate: []byte("cert"), PrivateKey: []byte("key")},
+		{Domain: "*.example.net", Certificate: []byte("cert2"), PrivateKey: []byte("key2")},
+	}
+
+	err = exportTraefik(filename, "lego", certificates)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	store := traefikStore{}
+	err = json.Unmarshal(data, &store)
+	require.NoError(t, err)
+
+	require.Contains(t, store, "letsencrypt")
+	assert.JSONEq(t, `{"Email": "test@example.com"}`, string(store["letsencrypt"].Account))
+
+	expected := []*traefikCertificate{
+		{Domain: traefikDomain{Main: "example.com", SANs: []string{"www.example.com"}}, Certificate: []byte("cert"), Key: []byte("key"), Store: "default"},
+		{Domain: traefikDomain{Main: "example.org"}, Certificate: []byte("old"), Key: []byte("old"), Store: "default"},
+		{Domain: traefikDomain{Main: "*.example.net"}, Certificate: []byte("cert2"), Key: []byte("key2"), Store: "default"},
+	}
+
+	assert.Equal(t, expected, store["lego"].Certificates)
+
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, filePerm, info.Mode().Perm())
+}
+
+func Test_exportCaddy(t *testing.T) {
+	root := t.TempDir()
+
+	certificates := []*exportedCertificate{
+		{Domain: "*.example.com", SANs: []string{"example.com"}, Certificate: []byte("cert"), PrivateKey: []byte("key"), CertURL: "https://ca.example/cert/1"},
+	}
+
+	err := exportCaddy(root, "https://acme-v02.api.letsencrypt.org/directory", "", certificates)
+	require.NoError(t, err)
+
+	dir := filepath.Join(root, "certificates", "acme-v02.api.letsencrypt.org-directory", "wildcard_.example.com")
+
+	assert.FileExists(t, filepath.Join(dir, "wildcard_.example.com.crt"))
+	assert.FileExists(t, filepath.Join(dir, "wildcard_.example.com.key"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "wildcard_.example.com.json"))
+	require.NoError(t, err)
+
+	expected := `{
+	"sans": ["*.example.com", "example.com"],
+	"issuer_data": {"url": "https://ca.example/cert/1", "ca": "https://acme-v02.api.letsencrypt.org/directory"}
+}`
+
+	assert.JSONEq(t, expected, string(data))
+}
+
+func Test_caddyIssuerKey(t *testing.T) {
+	testCases := []struct {
+		caURL    string
+		expected string
+	}{
+		{caURL: "https://acme-v02.api.letsencrypt.org/directory", expected: "acme-v02.api.letsencrypt.org-directory"},
+		{caURL: "https://acme-staging-v02.api.letsencrypt.org/directory", expected: "acme-staging-v02.api.letsencrypt.org-directory"},
+		{caURL: "https://localhost:14000/dir", expected: "localhost-14000-dir"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.caURL, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, caddyIssuerKey(test.caURL))
+		})
+	}
+}
+
+func Test_exportSPIFFE(t *testing.T) {
+	root := t.TempDir()
+
+	caKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		URIs:         []*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/api"}},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	certificates := []*exportedCertificate{
+		{
+			Domain:      "example.com",
+			Certificate: certcrypto.PEMEncode(certcrypto.DERCertificateBytes(leafDER)),
+			Issuer:      certcrypto.PEMEncode(certcrypto.DERCertificateBytes(caDER)),
+			PrivateKey:  certcrypto.PEMEncode(key),
+		},
+	}
+
+	err = exportSPIFFE(root, "", certificates)
+	require.NoError(t, err)
+
+	dir := filepath.Join(root, "example.com")
+
+	svid, err := os.ReadFile(filepath.Join(dir, spiffeSVIDFile))
+	require.NoError(t, err)
+
+	chain, err := certcrypto.ParsePEMBundle(svid)
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+
+	assert.True(t, hasSPIFFEID(chain[0]))
+
+	rawKey, err := os.ReadFile(filepath.Join(dir, spiffeKeyFile))
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(rawKey)
+	require.NotNil(t, block)
+	assert.Equal(t, "PRIVATE KEY", block.Type)
+
+	_, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	require.NoError(t, err)
+
+	bundle, err := os.ReadFile(filepath.Join(dir, spiffeBundleFile))
+	require.NoError(t, err)
+
+	assert.Equal(t, certificates[0].Issuer, bundle)
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/storage"
+)
+
+// failureStatsKey the key of the failure statistics of the daemon, relative to the root of the storage.
+const failureStatsKey = "stats/failures.json"
+
+// Failure causes, derived from the problems returned by the CA.
+const (
+	causeConnectionRefused = "connection refused"
+	causeTimeout           = "timeout"
+	causeCAA               = "CAA"
+	causeDNS               = "DNS problem"
+	causeUnauthorized      = "unauthorized"
+	causeTLS               = "TLS"
+	causeConnection        = "connection"
+	causeRateLimited       = "rate limited"
+	causeOther             = "other"
+)
+
+// failureCause a cause of failure, and the detail of the problem returned by the CA.
+type failureCause struct {
+	Name   string
+	Detail string
+}
+
+// failureStats the failure causes aggregated over time.
+type failureStats struct {
+	Since  time.Time                     `json:"since"`
+	Causes map[string]*failureCauseStats `json:"causes"`
+}
+
+// failureCauseStats the statistics of a failure cause.
+type failureCauseStats struct {
+	Count      int       `json:"count"`
+	LastSeen   time.Time `json:"lastSeen"`
+	LastDomain string    `json:"lastDomain,omitempty"`
+	LastDetail string    `json:"lastDetail,omitempty"`
+}
+
+// add counts the causes of a failure.
+func (s *failureStats) add(domain string, causes []failureCause, now time.Time) {
+	if s.Causes == nil {
+		s.Causes = make(map[string]*failureCauseStats)
+	}
+
+	if s.Since.IsZero() {
+		s.Since = now.UTC()
+	}
+
+	for _, cause := range causes {
+		stats, ok := s.Causes[cause.Name]
+		if !ok {
+			stats = &failureCauseStats{}
+			s.Causes[cause.Name] = stats
+		}
+
+		stats.Count++
+		stats.LastSeen = now.UTC()
+		stats.LastDomain = domain
+		stats.LastDetail = cause.Detail
+	}
+}
+
+// top returns the names of the causes, sorted by decreasing count.
+func (s *failureStats) top(limit int) []string {
+	names := make([]string, 0, len(s.Causes))
+	for name := range s.Causes {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		ci, cj := s.Causes[names[i]].Count, s.Causes[names[j]].Count
+		if ci != cj {
+			return ci > cj
+		}
+
+		return names[i] < names[j]
+	})
+
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+
+	return names
+}
+
+// ReadFailureStats reads the failure statistics. Returns empty statistics if there are none.
+func (s *CertificatesStorage) ReadFailureStats() (*failureStats, error) {
+	raw, err := s.store.Get(context.Background(), failureStatsKey)
+	if errors.Is(err, storage.ErrNotExist) {
+		return &failureStats{Causes: make(map[string]*failureCauseStats)}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the failure statistics: %w", err)
+	}
+
+	var stats failureStats
+	if err = json.Unmarshal(raw, &stats); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the failure statistics: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// SaveFailureStats saves the failure statistics.
+func (s *CertificatesStorage) SaveFailureStats(stats *failureStats) error {
+	raw, err := json.MarshalIndent(stats, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the failure statistics: %w", err)
+	}
+
+	return s.store.Put(context.Background(), failureStatsKey, raw)
+}
+
+// recordFailureStats adds the causes of a failure to the failure statistics.
+func recordFailureStats(certsStorage *CertificatesStorage, domain string, causes []failureCause, now time.Time) {
+	stats, err := certsStorage.ReadFailureStats()
+	if err != nil {
+		log.Warnf("[%s] %v", domain, err)
+		return
+	}
+
+	stats.add(domain, causes, now)
+
+	if err = certsStorage.SaveFailureStats(stats); err != nil {
+		log.Warnf("[%s] unable to save the failure statistics: %v", domain, err)
+	}
+}
+
+// getFailureCauses returns the causes of a failure: a cause per problem (or sub-problem) returned by the CA,
+// or the cause "other" if the failure doesn't come from the CA (ex: a DNS provider error).
+func getFailureCauses(err error) []failureCause {
+	if err == nil {
+		return nil
+	}
+
+	var causes []failureCause
+
+	for _, problem := range findProblems(err) {
+		if len(problem.SubProblems) == 0 {
+			causes = append(causes, failureCause{Name: categorizeProblem(problem.Type, problem.Detail), Detail: problem.Detail})
+			continue
+		}
+
+		for _, sub := range problem.SubProblems {
+			causes = append(causes, failureCause{Name: categorizeProblem(sub.Type, sub.Detail), Detail: sub.Detail})
+		}
+	}
+
+	if len(causes) == 0 {
+		return []failureCause{{Name: causeOther, Detail: err.Error()}}
+	}
+
+	return causes
+}
+
+// findProblems returns the problems of the CA in the tree of errors (an error per domain for an order).
+func findProblems(err error) []*acme.ProblemDetails {
+	switch e := err.(type) {
+	case *acme.ProblemDetails:
+		return []*acme.ProblemDetails{e}
+
+	case interface{ Unwrap() []error }:
+		var problems []*acme.ProblemDetails
+		for _, child := range e.Unwrap() {
+			problems = append(problems, findProblems(child)...)
+		}
+
+		return problems
+
+	case interface{ Unwrap() error }:
+		if child := e.Unwrap(); child != nil {
+			return findProblems(child)
+		}
+	}
+
+	return nil
+}
+
+// categorizeProblem categorizes a problem by its detail (ex: "Timeout during connect"), then by its type.
+func categorizeProblem(problemType, detail string) string {
+	lower := strings.ToLower(detail)
+
+	switch {
+	case strings.Contains(lower, "connection refused"):
+		return causeConnectionRefused
+	case strings.Contains(lower, "timeout") || strings.Contains(lower, "timed out"):
+		return causeTimeout
+	case problemType == acme.CAAErr || strings.Contains(detail, "CAA"):
+		return causeCAA
+	case problemType == acme.DNSErr || strings.Contains(lower, "dns problem"):
+		return causeDNS
+	case problemType == acme.UnauthorizedErr || problemType == acme.IncorrectResponseErr:
+		return causeUnauthorized
+	case problemType == acme.TLSErr:
+		return causeTLS
+	case problemType == acme.ConnectionErr:
+		return causeConnection
+	case problemType == acme.RateLimitedErr:
+		return causeRateLimited
+	case problemType != "":
+		// the other types of errors (ex: urn:ietf:params:acme:error:malformed -> malformed).
+		return problemType[strings.LastIndex(problemType, ":")+1:]
+	default:
+		return causeOther
+	}
+}
+
+// countFailureCauses counts the causes of the failures.
+func countFailureCauses(counts map[string]int, causes []failureCause) map[string]int {
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+
+	for _, cause := range causes {
+		counts[cause.Name]++
+	}
+
+	return counts
+}
+
+// formatFailureCauses formats the counts of the causes (ex: "DNS problem=2, timeout=1"), sorted by decreasing count.
+func formatFailureCauses(counts map[string]int) string {
+	stats := &failureStats{Causes: make(map[string]*failureCauseStats)}
+	for name, count := range counts {
+		stats.Causes[name] = &failureCauseStats{Count: count}
+	}
+
+	var parts []string
+	for _, name := range stats.top(0) {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, counts[name]))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getFailureCauses(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected []failureCause
+	}{
+		{
+			desc: "connection refused",
+			err: fmt.Errorf("wrapped: %w", &acme.ProblemDetails{
+				Type:   acme.ConnectionErr,
+				Detail: "127.0.0.1: Fetching http://example.com/.well-known/acme-challenge/token: Connection refused",
+			}),
+			expected: []failureCause{
+				{Name: causeConnectionRefused, Detail: "127.0.0.1: Fetching http://example.com/.well-known/acme-challenge/token: Connection refused"},
+			},
+		},
+		{
+			desc: "timeout",
+			err:  &acme.ProblemDetails{Type: acme.ConnectionErr, Detail: "Timeout during connect (likely firewall problem)"},
+			expected: []failureCause{
+				{Name: causeTimeout, Detail: "Timeout during connect (likely firewall problem)"},
+			},
+		},
+		{
+			desc: "CAA",
+			err:  &acme.ProblemDetails{Type: acme.CAAErr, Detail: "CAA record for example.com prevents issuance"},
+			expected: []failureCause{
+				{Name: causeCAA, Detail: "CAA record for example.com prevents issuance"},
+			},
+		},
+		{
+			desc: "sub-problems",
+			err: &acme.ProblemDetails{
+				Type: "urn:ietf:params:acme:error:compound",
+				SubProblems: []acme.SubProblem{
+					{Type: acme.DNSErr, Detail: "DNS problem: NXDOMAIN looking up TXT for _acme-challenge.a.example.com"},
+					{Type: acme.UnauthorizedErr, Detail: "Incorrect TXT record found at _acme-challenge.b.example.com"},
+				},
+			},
+			expected: []failureCause{
+				{Name: causeDNS, Detail: "DNS problem: NXDOMAIN looking up TXT for _acme-challenge.a.example.com"},
+				{Name: causeUnauthorized, Detail: "Incorrect TXT record found at _acme-challenge.b.example.com"},
+			},
+		},
+		{
+			desc: "joined errors",
+			err: errors.Join(
+				fmt.Errorf("a.example.com: %w", &acme.ProblemDetails{Type: acme.TLSErr, Detail: "remote error: tls: handshake failure"}),
+				fmt.Errorf("b.example.com: %w", &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:malformed", Detail: "invalid"}),
+			),
+			expected: []failureCause{
+				{Name: causeTLS, Detail: "remote error: tls: handshake failure"},
+				{Name: "malformed", Detail: "invalid"},
+			},
+		},
+		{
+			desc: "rate limited",
+			err: &acme.RateLimitedError{
+				ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr, Detail: "too many certificates"},
+			},
+			expected: []failureCause{
+				{Name: causeRateLimited, Detail: "too many certificates"},
+			},
+		},
+		{
+			desc: "not a problem of the CA",
+			err:  errors.New("cloudflare: failed to create TXT record"),
+			expected: []failureCause{
+				{Name: causeOther, Detail: "cloudflare: failed to create TXT record"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, getFailureCauses(test.err))
+		})
+	}
+}
+
+func Test_failureStats(t *testing.T) {
+	certsStorage := newTestCertificatesStorage(t)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	recordFailureStats(certsStorage, "a.example.com", []failureCause{{Name: causeDNS, Detail: "NXDOMAIN"}}, now)
+	recordFailureStats(certsStorage, "b.example.com", []failureCause{{Name: causeTimeout, Detail: "timeout"}}, now.Add(time.Hour))
+	recordFailureStats(certsStorage, "c.example.com", []failureCause{{Name: causeDNS, Detail: "SERVFAIL"}}, now.Add(2*time.Hour))
+
+	stats, err := certsStorage.ReadFailureStats()
+	require.NoError(t, err)
+
+	assert.Equal(t, now, stats.Since)
+	assert.Equal(t, []string{causeDNS, causeTimeout}, stats.top(0))
+	assert.Equal(t, []string{causeDNS}, stats.top(1))
+
+	assert.Equal(t, &failureCauseStats{
+		Count:      2,
+		LastSeen:   now.Add(2 * time.Hour),
+		LastDomain: "c.example.com",
+		LastDetail: "SERVFAIL",
+	}, stats.Causes[causeDNS])
+}
+
+func Test_formatFailureCauses(t *testing.T) {
+	counts := countFailureCauses(nil, []failureCause{{Name: causeTimeout}, {Name: causeDNS}, {Name: causeDNS}})
+
+	assert.Equal(t, "DNS problem=2, timeout=1", formatFailureCauses(counts))
+}
@@ -16,6 +16,10 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Aliases: []string{"d"},
 			Usage:   "Add a domain to the process. Can be specified multiple times.",
 		},
+		&cli.StringSliceFlag{
+			Name:  "ip",
+			Usage: "Add an IP address (RFC 8738) to the process, validated with the http-01 or tls-alpn-01 challenge. Can be specified multiple times.",
+		},
 		&cli.StringFlag{
 			Name:    "server",
 			Aliases: []string{"s"},
@@ -33,6 +37,18 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Aliases: []string{"m"},
 			Usage:   "Email used for registration and recovery contact.",
 		},
+		&cli.StringSliceFlag{
+			Name:    "contact",
+			EnvVars: []string{"LEGO_CONTACT"},
+			Usage: "Additional contact URL of the account (ex: 'mailto:ops@example.com', 'tel:+1-201-555-0123'), validated against the rules of the CA." +
+				" The contacts of an existing account are updated when they change. Can be specified multiple times.",
+		},
+		&cli.StringFlag{
+			Name:    "account",
+			EnvVars: []string{"LEGO_ACCOUNT"},
+			Usage: "Name of the account to use. Allows several accounts (ex: with the same email) to coexist in the same storage directory." +
+				" Defaults to the email.",
+		},
 		&cli.StringFlag{
 			Name:    "csr",
 			Aliases: []string{"c"},
@@ -69,6 +85,12 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage:   "Directory to use for storing the data.",
 			Value:   defaultPath,
 		},
+		&cli.StringFlag{
+			Name:    "storage",
+			EnvVars: []string{"LEGO_STORAGE"},
+			Usage: "URL of the storage of the accounts and the certificates, instead of the '--path' directory." +
+				" Supported: file:///path, s3://bucket/prefix.",
+		},
 		&cli.BoolFlag{
 			Name:  "http",
 			Usage: "Use the HTTP-01 challenge to solve challenges. Can be mixed with other types of challenges.",
@@ -88,6 +110,35 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage: "Set the webroot folder to use for HTTP-01 based challenges to write directly to the .well-known/acme-challenge file." +
 				" This disables the built-in server and expects the given directory to be publicly served with access to .well-known/acme-challenge",
 		},
+		&cli.StringSliceFlag{
+			Name: "http.webroot-domain",
+			Usage: "Set the webroot folder of a domain, instead of the folder defined by '--http.webroot' (ex: example.org=/var/www/example.org)." +
+				" Can be specified multiple times.",
+		},
+		&cli.BoolFlag{
+			Name: "http.webroot-alias",
+			Usage: "The webroot folders are the targets of an alias of /.well-known/acme-challenge/ (ex: nginx 'alias', Apache 'Alias'):" +
+				" the challenge files are written directly in the folders.",
+		},
+		&cli.StringFlag{
+			Name: "http.webroot-self-check",
+			Usage: "Verify that the challenge files are served before notifying the CA, by sending a request to this address (ex: 127.0.0.1:80)" +
+				" with the domain as Host header. Hints about the configuration of the web server are displayed on failure.",
+		},
+		&cli.StringFlag{
+			Name:  "http.webroot-file-mode",
+			Usage: "The permissions of the challenge files written in the webroot folders (octal).",
+			Value: "0644",
+		},
+		&cli.StringFlag{
+			Name: "http.webroot-owner",
+			Usage: "The owner of the challenge files, and of the directories created in the webroot folders (ex: www-data:www-data, 33:33)." +
+				" Requires the permission to change the owner of a file (ex: root).",
+		},
+		&cli.DurationFlag{
+			Name:  "http.webroot-gc",
+			Usage: "Remove, at startup, the challenge files older than this duration from the webroot folders (ex: 24h): the files left by an interrupted run.",
+		},
 		&cli.StringSliceFlag{
 			Name:  "http.memcached-host",
 			Usage: "Set the memcached host(s) to use for HTTP-01 based challenges. Challenges will be written to all specified hosts.",
@@ -96,6 +147,25 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Name:  "http.s3-bucket",
 			Usage: "Set the S3 bucket name to use for HTTP-01 based challenges. Challenges will be written to the S3 bucket.",
 		},
+		&cli.StringSliceFlag{
+			Name: "http.perspective-checker",
+			Usage: "Set the URL of an external checker used to verify that HTTP-01 challenges are reachable before notifying the CA." +
+				" The {url} placeholder is replaced by the escaped URL of the challenge.",
+		},
+		&cli.BoolFlag{
+			Name: "http.self-check",
+			Usage: "Verify that the HTTP-01 challenges are served on the domains before notifying the CA." +
+				" Use '--ip-family' to check the address family used by the CA.",
+		},
+		&cli.BoolFlag{
+			Name: "http.cdn-detect",
+			Usage: "Detect the CDNs serving the apex domains (CNAME flattening) before solving HTTP-01 challenges." +
+				" A warning is logged if a CDN is detected.",
+		},
+		&cli.StringFlag{
+			Name:  "http.cdn-fallback",
+			Usage: "Set the challenge type (dns-01, tls-alpn-01) used instead of HTTP-01 when a CDN is detected. Requires '--http.cdn-detect'.",
+		},
 		&cli.BoolFlag{
 			Name:  "tls",
 			Usage: "Use the TLS-ALPN-01 challenge to solve challenges. Can be mixed with other types of challenges.",
@@ -105,21 +175,146 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage: "Set the port and interface to use for TLS-ALPN-01 based challenges to listen on. Supported: interface:port or :port.",
 			Value: ":443",
 		},
+		&cli.BoolFlag{
+			Name: "tls.reuse-port",
+			Usage: "Bind the port of the TLS-ALPN-01 server with SO_REUSEPORT, to coexist with a running service using SO_REUSEPORT." +
+				" Use with '--tls.passthrough' to forward the connections of the service.",
+		},
+		&cli.UintFlag{
+			Name:  "tls.listen-fd",
+			Usage: "Use the listening socket inherited from the parent process (file descriptor) for the TLS-ALPN-01 server, instead of binding the port.",
+		},
+		&cli.StringFlag{
+			Name: "tls.passthrough",
+			Usage: "Forward the connections not negotiating the acme-tls/1 protocol to this address (host:port), without terminating TLS," +
+				" so a running service remains reachable during TLS-ALPN-01 challenges.",
+		},
+		&cli.BoolFlag{
+			Name: "tls.proxy-protocol",
+			Usage: "Require a PROXY protocol header (v1 or v2) on the connections of the TLS-ALPN-01 server, to run behind a L4 load balancer." +
+				" The header is replayed to '--tls.passthrough'.",
+		},
+		&cli.StringFlag{
+			Name:  "tls.socket",
+			Usage: "Listen on this unix socket for TLS-ALPN-01 based challenges, instead of the port.",
+		},
+		&cli.StringFlag{
+			Name:  "tls.socket-mode",
+			Usage: "Set the file mode (octal) of the unix socket of the TLS-ALPN-01 server.",
+			Value: "0660",
+		},
 		&cli.StringFlag{
 			Name:  "dns",
 			Usage: "Solve a DNS-01 challenge using the specified provider. Can be mixed with other types of challenges. Run 'lego dnshelp' for help on usage.",
 		},
+		&cli.BoolFlag{
+			Name: "dns-plan",
+			Usage: "Log the TXT records (FQDN, value, TTL, zone) that the DNS provider would create and delete, without calling the API of the provider." +
+				" No certificate is obtained.",
+		},
+		&cli.StringFlag{
+			Name: "dns.cleanup-env-prefix",
+			Usage: "Remove the records with other credentials: the environment variables of the DNS provider prefixed by this value" +
+				" (ex: CLEANUP_ for CLEANUP_CLOUDFLARE_DNS_API_TOKEN) have priority over the non-prefixed ones.",
+		},
+		&cli.BoolFlag{
+			Name: "dns.deferred-cleanup",
+			Usage: "Do not remove the records: they are saved in a journal, and removed later by the 'gc' command" +
+				" (ex: a scheduled job with the clean-up credentials).",
+		},
+		&cli.StringSliceFlag{
+			Name: "dns.challenge-alias",
+			Usage: "Create the TXT record of a domain in the zone of an alias domain (ex: example.com=alias.example.net for _acme-challenge.alias.example.net)." +
+				" The record _acme-challenge.<domain> must be a CNAME to _acme-challenge.<alias>. Can be specified multiple times.",
+		},
 		&cli.BoolFlag{
 			Name:  "dns.disable-cp",
 			Usage: "By setting this flag to true, disables the need to await propagation of the TXT record to all authoritative name servers.",
 		},
+		&cli.BoolFlag{
+			Name: "dns.propagation-disable-rns",
+			Usage: "By setting this flag to true, disables the queries to the recursive name servers during the propagation check" +
+				" (CNAME resolution and '--dns.perspective-resolvers').",
+		},
+		&cli.DurationFlag{
+			Name: "dns.propagation-wait",
+			Usage: "Wait for this duration instead of checking the propagation of the TXT record:" +
+				" the DNS provider is trusted to have published the record (ex: 2m).",
+		},
+		&cli.StringFlag{
+			Name: "dns.propagation-policy",
+			Usage: "Set the number of authoritative name servers that must return the TXT record:" +
+				" 'all', 'any', or 'quorum' (a majority). The authoritative name servers are queried concurrently.",
+			Value: "all",
+		},
+		&cli.DurationFlag{
+			Name:  "dns.propagation-ns-timeout",
+			Usage: "Set the timeout of the query to each authoritative name server during the propagation check (ex: 5s).",
+		},
 		&cli.StringSliceFlag{
 			Name: "dns.resolvers",
 			Usage: "Set the resolvers to use for performing (recursive) CNAME resolving and apex domain determination." +
 				" For DNS-01 challenge verification, the authoritative DNS server is queried directly." +
-				" Supported: host:port." +
+				" Supported: host:port, or a DNS-over-HTTPS URL (ex: https://1.1.1.1/dns-query)." +
 				" The default is to use the system resolvers, or Google's DNS resolvers if the system's cannot be determined.",
 		},
+		&cli.BoolFlag{
+			Name: "dns.negative-cache-busting",
+			Usage: "When the resolvers return NXDOMAIN for the TXT record, retry with a randomized case and by rotating the resolvers" +
+				" to avoid negative caching of the propagation check.",
+		},
+		&cli.StringSliceFlag{
+			Name: "dns.perspective-resolvers",
+			Usage: "Set the resolvers used as additional vantage points to check the propagation of the TXT record before notifying the CA." +
+				" Supported: host:port, or a DNS-over-HTTPS URL (ex: https://1.1.1.1/dns-query).",
+		},
+		&cli.StringSliceFlag{
+			Name: "dns.zone-concurrency",
+			Usage: "Limit the number of DNS-01 challenges solved at the same time in a zone (ex: example.com=2)," +
+				" the other challenges of the zone are solved in the next rounds. '*=N' limits the other zones. Can be specified multiple times.",
+		},
+		&cli.StringFlag{
+			Name: "manual-auth-hook",
+			Usage: "With '--dns manual', the command executed to create each TXT record instead of asking for a confirmation." +
+				" The record is described by the environment variables LEGO_MANUAL_DOMAIN, LEGO_MANUAL_FQDN, LEGO_MANUAL_ZONE, and LEGO_MANUAL_VALUE.",
+		},
+		&cli.StringFlag{
+			Name:  "manual-cleanup-hook",
+			Usage: "With '--dns manual', the command executed to remove each TXT record. Same environment variables as '--manual-auth-hook'.",
+		},
+		&cli.StringFlag{
+			Name: "manual-export",
+			Usage: "With '--dns manual', write all the TXT records of the order to importable files (BIND zone snippet, PowerShell script, Terraform configuration)" +
+				" in this directory, and ask for a single confirmation.",
+		},
+		&cli.StringFlag{
+			Name: "ip-family",
+			Usage: "Select the IP family of the outgoing connections (ACME server, DNS queries, HTTP-01 checks, DNS provider APIs):" +
+				" 'any', 'ipv4', 'ipv6', 'prefer-ipv4', or 'prefer-ipv6'.",
+			Value: "any",
+		},
+		&cli.StringFlag{
+			Name: "bind.interface",
+			Usage: "Use the addresses of this network interface as source addresses of the HTTP connections (ACME server, HTTP-01 checks, DNS provider APIs)." +
+				" For the multi-homed hosts where the default route is not the route to the ACME server or to the APIs.",
+			EnvVars: []string{"LEGO_BIND_INTERFACE"},
+		},
+		&cli.StringFlag{
+			Name:    "bind.address",
+			Usage:   "Use this source IP address for the HTTP connections (ACME server, HTTP-01 checks, DNS provider APIs). Exclusive with '--bind.interface'.",
+			EnvVars: []string{"LEGO_BIND_ADDRESS"},
+		},
+		&cli.StringFlag{
+			Name:    "proxy.acme",
+			Usage:   "Connect to the ACME server through this proxy (socks5://[user:password@]host:port, http://host:port, or https://host:port) instead of the proxy of the environment (HTTPS_PROXY).",
+			EnvVars: []string{"LEGO_PROXY_ACME"},
+		},
+		&cli.StringFlag{
+			Name: "proxy.providers",
+			Usage: "Connect to the DNS provider APIs (and the HTTP-01 checks) through this proxy (socks5://[user:password@]host:port, http://host:port, or https://host:port)" +
+				" instead of the proxy of the environment (HTTPS_PROXY).",
+			EnvVars: []string{"LEGO_PROXY_PROVIDERS"},
+		},
 		&cli.IntFlag{
 			Name:  "http-timeout",
 			Usage: "Set the HTTP timeout value to a specific value in seconds.",
@@ -129,6 +324,21 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage: "Set the DNS timeout value to a specific value in seconds. Used only when performing authoritative name server queries.",
 			Value: 10,
 		},
+		&cli.StringFlag{
+			Name: "dns.transport",
+			Usage: "Set the transport of the DNS queries: 'udp' (TCP only for the truncated responses), 'tcp'," +
+				" or 'udp+tcp' (TCP also when the UDP query fails, ex: lossy networks).",
+			Value: "udp",
+		},
+		&cli.IntFlag{
+			Name:  "dns.retries",
+			Usage: "Set the number of retries of a failed DNS query (ex: timeout) on the same nameserver.",
+		},
+		&cli.UintFlag{
+			Name:  "dns.edns0-buffer-size",
+			Usage: "Set the EDNS0 UDP buffer size of the DNS queries (ex: 1232 to avoid the IP fragmentation). 0 disables EDNS0.",
+			Value: 4096,
+		},
 		&cli.BoolFlag{
 			Name:  "pem",
 			Usage: "Generate an additional .pem (base64) file by concatenating the .key and .crt files together.",
@@ -150,6 +360,21 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Value:   "RC2",
 			EnvVars: []string{"LEGO_PFX_FORMAT"},
 		},
+		&cli.BoolFlag{
+			Name: "ocsp",
+			Usage: "Generate an additional .ocsp file containing the OCSP response of the certificate (DER), for the OCSP stapling." +
+				" The response is refreshed by the renew command and the daemon, at the middle of its validity period.",
+			EnvVars: []string{"LEGO_OCSP"},
+		},
+		&cli.IntFlag{
+			Name: "challenges.batch-size",
+			Usage: "Solve the challenges by batches of this number of authorizations (ex: orders with hundreds of domains):" +
+				" the records of a batch are removed before the next batch is presented.",
+		},
+		&cli.DurationFlag{
+			Name:  "challenges.batch-interval",
+			Usage: "The pause between the batches of challenges (see '--challenges.batch-size').",
+		},
 		&cli.IntFlag{
 			Name:  "cert.timeout",
 			Usage: "Set the certificate timeout value to a specific value in seconds. Only used when obtaining certificates.",
@@ -160,10 +385,45 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage: "ACME overall requests limit.",
 			Value: certificate.DefaultOverallRequestLimit,
 		},
+		&cli.BoolFlag{
+			Name:  "idna.strict",
+			Usage: "Validate the domains with the IDNA2008/UTS-46 strict rules before ordering.",
+		},
+		&cli.DurationFlag{
+			Name: "clock-skew.max",
+			Usage: "Fail if the local clock differs from the clock of the CA (Date header) by more than this duration." +
+				" Disabled by default.",
+		},
+		&cli.BoolFlag{
+			Name:  "clock-skew.compensate",
+			Usage: "Use the clock of the CA for the renewal checks instead of failing when the clock skew exceeds --clock-skew.max.",
+		},
+		&cli.StringFlag{
+			Name:  "deploy-config",
+			Usage: "Path to a deployment configuration file (YAML): the certificates are deployed to the targets after being obtained or renewed.",
+		},
+		&cli.StringSliceFlag{
+			Name: "deploy",
+			Usage: "Deploy the certificates to a target after being obtained or renewed: 'type:option=value,...'" +
+				" (ex: 'file:certificate=/etc/ssl/{domain}.crt,key=/etc/ssl/{domain}.key,certificate_mode=0640')." +
+				" Types: file, scp, kubernetes, vault, haproxy, nginx, apache, ... Can be specified multiple times.",
+		},
 		&cli.StringFlag{
 			Name:  "user-agent",
 			Usage: "Add to the user-agent sent to the CA to identify an application embedding lego-cli",
 		},
+		&cli.StringFlag{
+			Name:    "log.level",
+			EnvVars: []string{"LEGO_LOG_LEVEL"},
+			Usage:   "The minimum level of the logs. Supported: debug, info, warn, error.",
+			Value:   "info",
+		},
+		&cli.StringFlag{
+			Name:    "log.format",
+			EnvVars: []string{"LEGO_LOG_FORMAT"},
+			Usage:   "The format of the logs. Supported: text, json (one JSON object by line, the domain is a field).",
+			Value:   "text",
+		},
 	}
 }
 
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	healthStatusOK     = "ok"
+	healthStatusFailed = "failed"
+)
+
+// healthStatus the status reported by the health endpoints.
+type healthStatus struct {
+	Status            string            `json:"status"`
+	LastRenewal       *time.Time        `json:"lastRenewal,omitempty"`
+	LastRenewalStatus string            `json:"lastRenewalStatus,omitempty"`
+	PendingFailures   map[string]string `json:"pendingFailures,omitempty"`
+	ACME              string            `json:"acme,omitempty"`
+}
+
+// healthMonitor tracks the renewals to expose them through the health endpoints:
+//   - /healthz (liveness): always OK while the process is able to respond.
+//   - /readyz (readiness): OK if there is no pending renewal failure and the CA is reachable.
+type healthMonitor struct {
+	mu sync.RWMutex
+
+	lastRenewal       time.Time
+	lastRenewalStatus string
+	failures          map[string]string
+
+	checkACME func() error
+}
+
+func newHealthMonitor(checkACME func() error) *healthMonitor {
+	return &healthMonitor{
+		failures:  map[string]string{},
+		checkACME: checkACME,
+	}
+}
+
+// recordRenewal records the result of a renewal.
+// A successful renewal clears the pending failure of the domain.
+func (h *healthMonitor) recordRenewal(domain string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastRenewal = time.Now().UTC()
+
+	if err != nil {
+		h.lastRenewalStatus = healthStatusFailed
+		h.failures[domain] = err.Error()
+
+		return
+	}
+
+	h.lastRenewalStatus = healthStatusOK
+	delete(h.failures, domain)
+}
+
+func (h *healthMonitor) status(readiness bool) (healthStatus, bool) {
+	h.mu.RLock()
+
+	status := healthStatus{
+		Status:            healthStatusOK,
+		LastRenewalStatus: h.lastRenewalStatus,
+	}
+
+	if !h.lastRenewal.IsZero() {
+		lastRenewal := h.lastRenewal
+		status.LastRenewal = &lastRenewal
+	}
+
+	if len(h.failures) > 0 {
+		status.PendingFailures = map[string]string{}
+		for domain, msg := range h.failures {
+			status.PendingFailures[domain] = msg
+		}
+	}
+
+	h.mu.RUnlock()
+
+	if !readiness {
+		return status, true
+	}
+
+	ready := len(status.PendingFailures) == 0
+
+	if h.checkACME != nil {
+		status.ACME = healthStatusOK
+
+		if err := h.checkACME(); err != nil {
+			status.ACME = err.Error()
+			ready = false
+		}
+	}
+
+	if !ready {
+		status.Status = healthStatusFailed
+	}
+
+	return status, ready
+}
+
+// handler returns the HTTP handler of the health endpoints.
+func (h *healthMonitor) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, _ *http.Request) {
+		h.writeStatus(rw, false)
+	})
+
+	mux.HandleFunc("/readyz", func(rw http.ResponseWriter, _ *http.Request) {
+		h.writeStatus(rw, true)
+	})
+
+	return mux
+}
+
+func (h *healthMonitor) writeStatus(rw http.ResponseWriter, readiness bool) {
+	status, ok := h.status(readiness)
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if !ok {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_ = json.NewEncoder(rw).Encode(status)
+}
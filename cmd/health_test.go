@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_healthMonitor(t *testing.T) {
+	var acmeErr error
+
+	monitor := newHealthMonitor(func() error { return acmeErr })
+
+	server := httptest.NewServer(monitor.handler())
+	t.Cleanup(server.Close)
+
+	status, code := getHealthStatus(t, server.URL+"/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, healthStatusOK, status.Status)
+	assert.Equal(t, healthStatusOK, status.ACME)
+	assert.Nil(t, status.LastRenewal)
+
+	monitor.recordRenewal("example.com", errors.New("oops"))
+
+	status, code = getHealthStatus(t, server.URL+"/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, healthStatusFailed, status.Status)
+	assert.Equal(t, healthStatusFailed, status.LastRenewalStatus)
+	assert.Equal(t, map[string]string{"example.com": "oops"}, status.PendingFailures)
+
+	// the liveness doesn't depend on the renewals.
+	status, code = getHealthStatus(t, server.URL+"/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, healthStatusOK, status.Status)
+
+	monitor.recordRenewal("example.com", nil)
+
+	status, code = getHealthStatus(t, server.URL+"/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, healthStatusOK, status.LastRenewalStatus)
+	assert.Empty(t, status.PendingFailures)
+	assert.NotNil(t, status.LastRenewal)
+
+	acmeErr = errors.New("unreachable")
+
+	status, code = getHealthStatus(t, server.URL+"/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unreachable", status.ACME)
+}
+
+func getHealthStatus(t *testing.T, uri string) (healthStatus, int) {
+	t.Helper()
+
+	resp, err := http.Get(uri)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	var status healthStatus
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	require.NoError(t, err)
+
+	return status, resp.StatusCode
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parseLabels parses labels in the format `key=value`.
+func parseLabels(values []string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for _, value := range values {
+		k, v, found := strings.Cut(value, "=")
+
+		k = strings.TrimSpace(k)
+		if !found || k == "" {
+			return nil, fmt.Errorf("invalid label %q: expected key=value", value)
+		}
+
+		labels[k] = strings.TrimSpace(v)
+	}
+
+	return labels, nil
+}
+
+// matchLabels checks if the labels contain all the labels of the selector.
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatLabels formats labels as `key=value` sorted by key.
+func formatLabels(labels map[string]string) string {
+	var keys []string
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, k+"="+labels[k])
+	}
+
+	return strings.Join(parts, ", ")
+}
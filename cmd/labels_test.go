@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseLabels(t *testing.T) {
+	labels, err := parseLabels([]string{"team=infra", "service = api", "empty="})
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"team":    "infra",
+		"service": "api",
+		"empty":   "",
+	}
+
+	assert.Equal(t, expected, labels)
+}
+
+func Test_parseLabels_error(t *testing.T) {
+	_, err := parseLabels([]string{"team"})
+	require.Error(t, err)
+
+	_, err = parseLabels([]string{"=infra"})
+	require.Error(t, err)
+}
+
+func Test_matchLabels(t *testing.T) {
+	labels := map[string]string{"team": "infra", "service": "api"}
+
+	assert.True(t, matchLabels(labels, nil))
+	assert.True(t, matchLabels(labels, map[string]string{"team": "infra"}))
+	assert.False(t, matchLabels(labels, map[string]string{"team": "dev"}))
+	assert.False(t, matchLabels(labels, map[string]string{"ticket": "123"}))
+	assert.False(t, matchLabels(nil, map[string]string{"team": "infra"}))
+}
+
+func Test_formatLabels(t *testing.T) {
+	assert.Equal(t, "service=api, team=infra", formatLabels(map[string]string{"team": "infra", "service": "api"}))
+}
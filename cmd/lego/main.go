@@ -38,9 +38,11 @@ func main() {
 	app.Before = cmd.Before
 
 	app.Commands = cmd.CreateCommands()
+	app.OnUsageError = cmd.OnUsageError
 
 	err = app.Run(os.Args)
 	if err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+)
+
+// maintenanceWindow a period of time during which the CA is known to be unavailable.
+type maintenanceWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// parseMaintenanceWindows parses windows in the format `start/end` (RFC3339).
+func parseMaintenanceWindows(values []string) ([]maintenanceWindow, error) {
+	var windows []maintenanceWindow
+
+	for _, value := range values {
+		start, end, found := strings.Cut(value, "/")
+		if !found {
+			return nil, fmt.Errorf("invalid maintenance window %q: expected start/end", value)
+		}
+
+		startTime, err := time.Parse(time.RFC3339, strings.TrimSpace(start))
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window %q: start: %w", value, err)
+		}
+
+		endTime, err := time.Parse(time.RFC3339, strings.TrimSpace(end))
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window %q: end: %w", value, err)
+		}
+
+		if !endTime.After(startTime) {
+			return nil, fmt.Errorf("invalid maintenance window %q: the end must be after the start", value)
+		}
+
+		windows = append(windows, maintenanceWindow{Start: startTime, End: endTime})
+	}
+
+	return windows, nil
+}
+
+// findMaintenanceWindow returns the window containing the given time, if any.
+func findMaintenanceWindow(windows []maintenanceWindow, now time.Time) (maintenanceWindow, bool) {
+	for _, window := range windows {
+		if !now.Before(window.Start) && now.Before(window.End) {
+			return window, true
+		}
+	}
+
+	return maintenanceWindow{}, false
+}
+
+// isMaintenanceError checks if the CA responded as being temporarily unavailable.
+func isMaintenanceError(err error) bool {
+	var pd *acme.ProblemDetails
+	if !errors.As(err, &pd) {
+		return false
+	}
+
+	return pd.HTTPStatus == http.StatusServiceUnavailable
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseMaintenanceWindows(t *testing.T) {
+	windows, err := parseMaintenanceWindows([]string{"2024-10-01T10:00:00Z/2024-10-01T12:00:00Z"})
+	require.NoError(t, err)
+
+	expected := []maintenanceWindow{{
+		Start: time.Date(2024, time.October, 1, 10, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC),
+	}}
+
+	assert.Equal(t, expected, windows)
+}
+
+func Test_parseMaintenanceWindows_error(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		value string
+	}{
+		{
+			desc:  "missing separator",
+			value: "2024-10-01T10:00:00Z",
+		},
+		{
+			desc:  "invalid start",
+			value: "foo/2024-10-01T12:00:00Z",
+		},
+		{
+			desc:  "invalid end",
+			value: "2024-10-01T10:00:00Z/foo",
+		},
+		{
+			desc:  "end before start",
+			value: "2024-10-01T12:00:00Z/2024-10-01T10:00:00Z",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseMaintenanceWindows([]string{test.value})
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_findMaintenanceWindow(t *testing.T) {
+	windows := []maintenanceWindow{{
+		Start: time.Date(2024, time.October, 1, 10, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC),
+	}}
+
+	_, ok := findMaintenanceWindow(windows, time.Date(2024, time.October, 1, 11, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+
+	_, ok = findMaintenanceWindow(windows, time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+}
+
+func Test_isMaintenanceError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &acme.ProblemDetails{HTTPStatus: http.StatusServiceUnavailable})
+	assert.True(t, isMaintenanceError(err))
+
+	err = &acme.ProblemDetails{HTTPStatus: http.StatusBadRequest}
+	assert.False(t, isMaintenanceError(err))
+}
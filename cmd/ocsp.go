@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/storage"
+	"golang.org/x/crypto/ocsp"
+)
+
+const ocspExt = ".ocsp"
+
+// ocspFetcher fetches the OCSP response of a certificate (ex: certificate.Certifier).
+type ocspFetcher interface {
+	GetOCSP(bundle []byte) ([]byte, *ocsp.Response, error)
+}
+
+// stapleOCSP updates the OCSP response file (.ocsp) of the certificate, if the "ocsp" option is enabled.
+// The errors are only logged: the certificate is usable without the OCSP response.
+func stapleOCSP(fetcher ocspFetcher, certsStorage *CertificatesStorage, domain string) {
+	if !certsStorage.ocsp {
+		return
+	}
+
+	updated, err := updateOCSPResponse(fetcher, certsStorage, domain, time.Now())
+	if err != nil {
+		log.Warnf("[%s] Unable to update the OCSP response: %v", domain, err)
+		return
+	}
+
+	if updated {
+		log.Infof("[%s] The OCSP response has been updated: %s", domain, certsStorage.GetFileName(domain, ocspExt))
+	}
+}
+
+// updateOCSPResponse fetches the OCSP response of the stored certificate, and saves it (DER) for the OCSP stapling.
+// The stored response is kept until the middle of its validity period, if it's the response of the certificate.
+// A response with a status other than "good" is not saved: a revoked certificate must not be stapled.
+func updateOCSPResponse(fetcher ocspFetcher, certsStorage *CertificatesStorage, domain string, now time.Time) (bool, error) {
+	bundle, err := certsStorage.ReadFile(domain, certExt)
+	if err != nil {
+		return false, fmt.Errorf("unable to read the certificate: %w", err)
+	}
+
+	leaf, err := certcrypto.ParsePEMCertificate(bundle)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse the certificate: %w", err)
+	}
+
+	stored := readOCSPResponse(certsStorage, domain)
+
+	// The stored response is usable if it's the response of the certificate (not of the previous certificate), and if it has not expired.
+	usable := stored != nil && stored.SerialNumber != nil && stored.SerialNumber.Cmp(leaf.SerialNumber) == 0 &&
+		!stored.NextUpdate.IsZero() && now.Before(stored.NextUpdate)
+
+	if usable && now.Before(stored.ThisUpdate.Add(stored.NextUpdate.Sub(stored.ThisUpdate)/2)) {
+		return false, nil
+	}
+
+	// The issuer certificate avoids the download of the issuer (AIA).
+	if issuer, errR := certsStorage.ReadFile(domain, issuerExt); errR == nil {
+		bundle = append(bundle, issuer...)
+	}
+
+	raw, response, err := fetcher.GetOCSP(bundle)
+	if err == nil && response == nil {
+		err = errors.New("no OCSP response")
+	}
+
+	if err == nil && response.Status != ocsp.Good {
+		usable = false
+		err = fmt.Errorf("the OCSP status of the certificate is not good: %s", ocspStatus(response.Status))
+	}
+
+	if err != nil {
+		if stored != nil && !usable {
+			if errR := certsStorage.RemoveFile(domain, ocspExt); errR != nil {
+				log.Warnf("[%s] Unable to remove the OCSP response: %v", domain, errR)
+			}
+		}
+
+		return false, err
+	}
+
+	err = certsStorage.WriteFile(domain, ocspExt, raw)
+	if err != nil {
+		return false, fmt.Errorf("unable to save the OCSP response: %w", err)
+	}
+
+	return true, nil
+}
+
+// readOCSPResponse returns the stored OCSP response, nil if there is no response.
+func readOCSPResponse(certsStorage *CertificatesStorage, domain string) *ocsp.Response {
+	raw, err := certsStorage.ReadFile(domain, ocspExt)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotExist) {
+			log.Warnf("[%s] Unable to read the OCSP response: %v", domain, err)
+		}
+
+		return nil
+	}
+
+	// The signature has been verified when the response has been fetched.
+	response, err := ocsp.ParseResponse(raw, nil)
+	if err != nil {
+		log.Warnf("[%s] Unable to parse the OCSP response: %v", domain, err)
+		return nil
+	}
+
+	return response
+}
+
+func ocspStatus(status int) string {
+	switch status {
+	case ocsp.Good:
+		return "good"
+	case ocsp.Revoked:
+		return "revoked"
+	case ocsp.Unknown:
+		return "unknown"
+	default:
+		return strconv.Itoa(status)
+	}
+}
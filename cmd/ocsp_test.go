@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+type fakeOCSPFetcher struct {
+	raw      []byte
+	response *ocsp.Response
+	err      error
+	calls    int
+}
+
+func (f *fakeOCSPFetcher) GetOCSP(_ []byte) ([]byte, *ocsp.Response, error) {
+	f.calls++
+
+	return f.raw, f.response, f.err
+}
+
+// ocspTestCertificate a self-signed certificate, signing its own OCSP responses.
+type ocspTestCertificate struct {
+	cert       *x509.Certificate
+	privateKey crypto.Signer
+}
+
+func newOCSPTestCertificate(t *testing.T, serial int64) *ocspTestCertificate {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &ocspTestCertificate{cert: cert, privateKey: privateKey}
+}
+
+func (c *ocspTestCertificate) save(t *testing.T, certsStorage *CertificatesStorage) {
+	t.Helper()
+
+	err := certsStorage.WriteFile("example.com", certExt, certcrypto.PEMEncode(certcrypto.DERCertificateBytes(c.cert.Raw)))
+	require.NoError(t, err)
+}
+
+func (c *ocspTestCertificate) response(t *testing.T, status int, thisUpdate time.Time) ([]byte, *ocsp.Response) {
+	t.Helper()
+
+	raw, err := ocsp.CreateResponse(c.cert, c.cert, ocsp.Response{
+		Status:       status,
+		SerialNumber: c.cert.SerialNumber,
+		ThisUpdate:   thisUpdate,
+		NextUpdate:   thisUpdate.Add(7 * 24 * time.Hour),
+	}, c.privateKey)
+	require.NoError(t, err)
+
+	response, err := ocsp.ParseResponse(raw, c.cert)
+	require.NoError(t, err)
+
+	return raw, response
+}
+
+func Test_updateOCSPResponse(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	certsStorage := newTestCertificatesStorage(t)
+
+	cert := newOCSPTestCertificate(t, 1)
+	cert.save(t, certsStorage)
+
+	raw, response := cert.response(t, ocsp.Good, now.Add(-time.Hour))
+	fetcher := &fakeOCSPFetcher{raw: raw, response: response}
+
+	// no stored response.
+	updated, err := updateOCSPResponse(fetcher, certsStorage, "example.com", now)
+	require.NoError(t, err)
+
+	assert.True(t, updated)
+	assert.Equal(t, 1, fetcher.calls)
+
+	stored, err := certsStorage.ReadFile("example.com", ocspExt)
+	require.NoError(t, err)
+
+	assert.Equal(t, raw, stored)
+
+	// the stored response is fresh.
+	updated, err = updateOCSPResponse(fetcher, certsStorage, "example.com", now.Add(24*time.Hour))
+	require.NoError(t, err)
+
+	assert.False(t, updated)
+	assert.Equal(t, 1, fetcher.calls)
+
+	// after the middle of the validity period of the stored response.
+	fetcher.raw, fetcher.response = cert.response(t, ocsp.Good, now.Add(4*24*time.Hour))
+
+	updated, err = updateOCSPResponse(fetcher, certsStorage, "example.com", now.Add(4*24*time.Hour))
+	require.NoError(t, err)
+
+	assert.True(t, updated)
+	assert.Equal(t, 2, fetcher.calls)
+}
+
+func Test_updateOCSPResponse_errors(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	previous := newOCSPTestCertificate(t, 1)
+	cert := newOCSPTestCertificate(t, 2)
+
+	previousRaw, _ := previous.response(t, ocsp.Good, now.Add(-time.Hour))
+	staleRaw, _ := cert.response(t, ocsp.Good, now.Add(-4*24*time.Hour))
+	revokedRaw, revokedResponse := cert.response(t, ocsp.Revoked, now.Add(-time.Hour))
+
+	testCases := []struct {
+		desc     string
+		stored   []byte
+		fetcher  *fakeOCSPFetcher
+		expected string
+		kept     bool
+	}{
+		{
+			desc:     "response of the previous certificate",
+			stored:   previousRaw,
+			fetcher:  &fakeOCSPFetcher{err: errors.New("responder unavailable")},
+			expected: "responder unavailable",
+		},
+		{
+			desc:     "stored response still valid",
+			stored:   staleRaw,
+			fetcher:  &fakeOCSPFetcher{err: errors.New("responder unavailable")},
+			expected: "responder unavailable",
+			kept:     true,
+		},
+		{
+			desc:     "revoked",
+			stored:   staleRaw,
+			fetcher:  &fakeOCSPFetcher{raw: revokedRaw, response: revokedResponse},
+			expected: "the OCSP status of the certificate is not good: revoked",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certsStorage := newTestCertificatesStorage(t)
+
+			cert.save(t, certsStorage)
+
+			err := certsStorage.WriteFile("example.com", ocspExt, test.stored)
+			require.NoError(t, err)
+
+			updated, err := updateOCSPResponse(test.fetcher, certsStorage, "example.com", now)
+			require.EqualError(t, err, test.expected)
+
+			assert.False(t, updated)
+			assert.Equal(t, test.kept, certsStorage.ExistsFile("example.com", ocspExt))
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/storage"
+)
+
+const (
+	// pendingExt the extension of the file of a pending order (deferred mode).
+	pendingExt = ".pending.json"
+	// orderExt the extension of the file of the order of a resumable request (--resume).
+	orderExt = ".order.json"
+)
+
+// resumableOrder the order of a request, saved until the certificate is obtained (--resume).
+type resumableOrder struct {
+	OrderURL       string   `json:"orderUrl"`
+	Domains        []string `json:"domains"`
+	Authorizations []string `json:"authorizations"`
+	Expires        string   `json:"expires,omitempty"`
+}
+
+// orderResumer creates, resumes, and finalizes the orders (ex: certificate.Certifier).
+type orderResumer interface {
+	GenerateOrder(request certificate.ObtainRequest) (*acme.ExtendedOrder, []acme.Authorization, error)
+	ResumeOrder(orderURL string) (*acme.ExtendedOrder, []acme.Authorization, error)
+	Challenge(order *acme.ExtendedOrder, authz []acme.Authorization, force bool) error
+	Finalize(order *acme.ExtendedOrder, authz []acme.Authorization, request certificate.ObtainRequest) (*certificate.Resource, error)
+}
+
+// SavePendingOrder saves the pending order of a domain (deferred mode).
+func (s *CertificatesStorage) SavePendingOrder(domain string, pending *certificate.PendingOrder) error {
+	raw, err := json.MarshalIndent(pending, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the pending order for domain %s: %w", domain, err)
+	}
+
+	return s.writeDomainFile(domain, pendingExt, raw)
+}
+
+// ReadPendingOrder reads the pending order of a domain (deferred mode).
+func (s *CertificatesStorage) ReadPendingOrder(domain string) (*certificate.PendingOrder, error) {
+	raw, err := s.ReadFile(domain, pendingExt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the pending order for domain %s: %w", domain, err)
+	}
+
+	var pending certificate.PendingOrder
+	if err = json.Unmarshal(raw, &pending); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the pending order for domain %s: %w", domain, err)
+	}
+
+	return &pending, nil
+}
+
+// RemovePendingOrder removes the pending order of a domain (deferred mode).
+func (s *CertificatesStorage) RemovePendingOrder(domain string) error {
+	return s.RemoveFile(domain, pendingExt)
+}
+
+// SaveResumableOrder saves the order of a resumable request (--resume).
+func (s *CertificatesStorage) SaveResumableOrder(domain string, order *resumableOrder) error {
+	raw, err := json.MarshalIndent(order, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the order for domain %s: %w", domain, err)
+	}
+
+	return s.writeDomainFile(domain, orderExt, raw)
+}
+
+// ReadResumableOrder reads the order of a resumable request (--resume).
+func (s *CertificatesStorage) ReadResumableOrder(domain string) (*resumableOrder, error) {
+	raw, err := s.ReadFile(domain, orderExt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the order for domain %s: %w", domain, err)
+	}
+
+	var order resumableOrder
+	if err = json.Unmarshal(raw, &order); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the order for domain %s: %w", domain, err)
+	}
+
+	return &order, nil
+}
+
+// obtainResumable obtains a certificate, resuming the order of an interrupted request if any (--resume).
+// The order is saved when it's created, and removed when the certificate is obtained or when the order can't be used anymore:
+// an interrupted request (ex: while waiting for the DNS propagation) is resumed without creating a new order.
+func obtainResumable(resumer orderResumer, certsStorage *CertificatesStorage, request certificate.ObtainRequest) (*certificate.Resource, error) {
+	domain := request.Domains[0]
+
+	order, authz := resumeOrder(resumer, certsStorage, request.Domains)
+	if order == nil {
+		var err error
+
+		order, authz, err = resumer.GenerateOrder(request)
+		if err != nil {
+			return nil, err
+		}
+
+		err = certsStorage.SaveResumableOrder(domain, &resumableOrder{
+			OrderURL:       order.Location,
+			Domains:        request.Domains,
+			Authorizations: order.Authorizations,
+			Expires:        order.Expires,
+		})
+		if err != nil {
+			log.Warnf("[%s] Unable to save the order, the request will not be resumable: %v", domain, err)
+		}
+	}
+
+	// The failed authorizations are deactivated: the order can't be resumed.
+	err := resumer.Challenge(order, authz, request.AlwaysDeactivateAuthorizations)
+	if err != nil {
+		removeResumableOrder(certsStorage, domain)
+		return nil, err
+	}
+
+	// The order is kept if the finalization fails (ex: network error): the order is still "ready".
+	cert, err := resumer.Finalize(order, authz, request)
+	if err != nil {
+		return nil, err
+	}
+
+	removeResumableOrder(certsStorage, domain)
+
+	return cert, nil
+}
+
+// resumeOrder returns the saved order of the domains and its authorizations, nil if there is no order to resume.
+func resumeOrder(resumer orderResumer, certsStorage *CertificatesStorage, domains []string) (*acme.ExtendedOrder, []acme.Authorization) {
+	domain := domains[0]
+
+	saved, err := certsStorage.ReadResumableOrder(domain)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotExist) {
+			log.Warnf("[%s] Unable to read the saved order, creating a new order: %v", domain, err)
+		}
+
+		return nil, nil
+	}
+
+	if !slices.Equal(saved.Domains, domains) {
+		log.Infof("[%s] The saved order is an order for other domains (%v), creating a new order.", domain, saved.Domains)
+		return nil, nil
+	}
+
+	order, authz, err := resumer.ResumeOrder(saved.OrderURL)
+	if err != nil {
+		log.Warnf("[%s] Unable to resume the order %s, creating a new order: %v", domain, saved.OrderURL, err)
+		removeResumableOrder(certsStorage, domain)
+
+		return nil, nil
+	}
+
+	return order, authz
+}
+
+func removeResumableOrder(certsStorage *CertificatesStorage, domain string) {
+	if err := certsStorage.RemoveFile(domain, orderExt); err != nil {
+		log.Warnf("[%s] Unable to remove the saved order: %v", domain, err)
+	}
+}
+
+// printPendingOrder prints the values of the challenges to place out-of-band.
+func printPendingOrder(w io.Writer, pending *certificate.PendingOrder, pendingFile string) {
+	_, _ = fmt.Fprintf(w, "The challenges must be placed before running the 'continue' command (order expires at %s):\n\n", pending.Expires)
+
+	for _, pc := range pending.Challenges {
+		switch pc.Type {
+		case challenge.DNS01:
+			_, _ = fmt.Fprintf(w, "%s\t60\tIN\tTXT\t%q\n", pc.FQDN, pc.Value)
+		case challenge.HTTP01:
+			_, _ = fmt.Fprintf(w, "http://%s%s\n\t%s\n", pc.Domain, pc.Path, pc.Value)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\nThe pending order has been saved to %s\n", pendingFile)
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificatesStorage_pendingOrder(t *testing.T) {
+	storage := newTestCertificatesStorage(t)
+
+	pending := &certificate.PendingOrder{
+		OrderURL: "https://example.org/order/1",
+		Domains:  []string{"*.example.com", "example.com"},
+		Challenges: []certificate.PendingChallenge{{
+			Domain:           "example.com",
+			Type:             challenge.DNS01,
+			AuthorizationURL: "https://example.org/authz/1",
+			ChallengeURL:     "https://example.org/chlg/1",
+			FQDN:             "_acme-challenge.example.com.",
+			Value:            "value",
+		}},
+	}
+
+	require.NoError(t, storage.SavePendingOrder("*.example.com", pending))
+	assert.True(t, storage.ExistsFile("*.example.com", pendingExt))
+
+	loaded, err := storage.ReadPendingOrder("*.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, pending, loaded)
+
+	require.NoError(t, storage.RemovePendingOrder("*.example.com"))
+	assert.False(t, storage.ExistsFile("*.example.com", pendingExt))
+
+	// Removing a missing pending order is not an error.
+	require.NoError(t, storage.RemovePendingOrder("*.example.com"))
+
+	_, err = storage.ReadPendingOrder("*.example.com")
+	require.Error(t, err)
+}
+
+func Test_printPendingOrder(t *testing.T) {
+	pending := &certificate.PendingOrder{
+		Expires: "2024-01-08T00:00:00Z",
+		Challenges: []certificate.PendingChallenge{
+			{Domain: "example.com", Type: challenge.DNS01, FQDN: "_acme-challenge.example.com.", Value: "dns-value"},
+			{Domain: "example.org", Type: challenge.HTTP01, Path: "/.well-known/acme-challenge/token", Value: "token.thumbprint"},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	printPendingOrder(buf, pending, "/tmp/example.com.pending.json")
+
+	expected := `The challenges must be placed before running the 'continue' command (order expires at 2024-01-08T00:00:00Z):
+
+_acme-challenge.example.com.	60	IN	TXT	"dns-value"
+http://example.org/.well-known/acme-challenge/token
+	token.thumbprint
+
+The pending order has been saved to /tmp/example.com.pending.json
+`
+
+	assert.Equal(t, expected, buf.String())
+}
+
+type fakeOrderResumer struct {
+	resumeErr    error
+	challengeErr error
+	finalizeErr  error
+
+	generated []string
+	resumed   []string
+}
+
+func (f *fakeOrderResumer) GenerateOrder(request certificate.ObtainRequest) (*acme.ExtendedOrder, []acme.Authorization, error) {
+	orderURL := "https://example.org/order/" + request.Domains[0]
+	f.generated = append(f.generated, orderURL)
+
+	return &acme.ExtendedOrder{Location: orderURL, Order: acme.Order{Authorizations: []string{"https://example.org/authz/1"}}}, nil, nil
+}
+
+func (f *fakeOrderResumer) ResumeOrder(orderURL string) (*acme.ExtendedOrder, []acme.Authorization, error) {
+	f.resumed = append(f.resumed, orderURL)
+
+	if f.resumeErr != nil {
+		return nil, nil, f.resumeErr
+	}
+
+	return &acme.ExtendedOrder{Location: orderURL}, nil, nil
+}
+
+func (f *fakeOrderResumer) Challenge(_ *acme.ExtendedOrder, _ []acme.Authorization, _ bool) error {
+	return f.challengeErr
+}
+
+func (f *fakeOrderResumer) Finalize(order *acme.ExtendedOrder, _ []acme.Authorization, request certificate.ObtainRequest) (*certificate.Resource, error) {
+	if f.finalizeErr != nil {
+		return nil, f.finalizeErr
+	}
+
+	return &certificate.Resource{Domain: request.Domains[0], CertURL: order.Location + "/cert"}, nil
+}
+
+func Test_obtainResumable(t *testing.T) {
+	certsStorage := newTestCertificatesStorage(t)
+
+	request := certificate.ObtainRequest{Domains: []string{"example.com", "www.example.com"}}
+
+	resumer := &fakeOrderResumer{finalizeErr: errors.New("connection reset")}
+
+	// the finalization fails: the order is kept.
+	_, err := obtainResumable(resumer, certsStorage, request)
+	require.EqualError(t, err, "connection reset")
+
+	assert.Equal(t, []string{"https://example.org/order/example.com"}, resumer.generated)
+
+	saved, err := certsStorage.ReadResumableOrder("example.com")
+	require.NoError(t, err)
+
+	expected := &resumableOrder{
+		OrderURL:       "https://example.org/order/example.com",
+		Domains:        []string{"example.com", "www.example.com"},
+		Authorizations: []string{"https://example.org/authz/1"},
+	}
+	assert.Equal(t, expected, saved)
+
+	// the saved order is resumed.
+	resumer.finalizeErr = nil
+
+	cert, err := obtainResumable(resumer, certsStorage, request)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://example.org/order/example.com/cert", cert.CertURL)
+	assert.Len(t, resumer.generated, 1)
+	assert.Equal(t, []string{"https://example.org/order/example.com"}, resumer.resumed)
+	assert.False(t, certsStorage.ExistsFile("example.com", orderExt))
+}
+
+func Test_obtainResumable_newOrder(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		saved   *resumableOrder
+		resumer *fakeOrderResumer
+		resumed int
+	}{
+		{
+			desc:    "order of other domains",
+			saved:   &resumableOrder{OrderURL: "https://example.org/order/old", Domains: []string{"example.com"}},
+			resumer: &fakeOrderResumer{},
+		},
+		{
+			desc:    "order not resumable",
+			saved:   &resumableOrder{OrderURL: "https://example.org/order/old", Domains: []string{"example.com", "www.example.com"}},
+			resumer: &fakeOrderResumer{resumeErr: errors.New("the order is invalid")},
+			resumed: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certsStorage := newTestCertificatesStorage(t)
+
+			require.NoError(t, certsStorage.SaveResumableOrder("example.com", test.saved))
+
+			_, err := obtainResumable(test.resumer, certsStorage, certificate.ObtainRequest{Domains: []string{"example.com", "www.example.com"}})
+			require.NoError(t, err)
+
+			assert.Len(t, test.resumer.resumed, test.resumed)
+			assert.Equal(t, []string{"https://example.org/order/example.com"}, test.resumer.generated)
+		})
+	}
+}
+
+func Test_obtainResumable_challengeFailed(t *testing.T) {
+	certsStorage := newTestCertificatesStorage(t)
+
+	resumer := &fakeOrderResumer{challengeErr: errors.New("propagation timeout")}
+
+	_, err := obtainResumable(resumer, certsStorage, certificate.ObtainRequest{Domains: []string{"example.com"}})
+	require.EqualError(t, err, "propagation timeout")
+
+	// the failed authorizations are deactivated: the order can't be resumed.
+	assert.False(t, certsStorage.ExistsFile("example.com", orderExt))
+}
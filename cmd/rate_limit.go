@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/ratelimit"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/log"
+	"github.com/urfave/cli/v2"
+)
+
+// rateLimitedExt the extension of the file of a renewal postponed by the rate limit of the CA (--ratelimit.resume).
+const rateLimitedExt = ".ratelimited.json"
+
+// rateLimitsRootKey the root key of the issuances tracked by CA server (acme/ratelimit).
+const rateLimitsRootKey = "ratelimits"
+
+// defaultRateLimitRetryAfter the duration to wait when the CA doesn't define the header Retry-After.
+const defaultRateLimitRetryAfter = time.Hour
+
+// rateLimitedRenewal a renewal postponed by the rate limit of the CA, resumed after the Retry-After window.
+type rateLimitedRenewal struct {
+	RetryAt time.Time `json:"retryAt"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// SaveRateLimited saves the postponed renewal of a domain.
+func (s *CertificatesStorage) SaveRateLimited(domain string, renewal *rateLimitedRenewal) error {
+	raw, err := json.MarshalIndent(renewal, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the rate-limited renewal for domain %s: %w", domain, err)
+	}
+
+	return s.writeDomainFile(domain, rateLimitedExt, raw)
+}
+
+// ReadRateLimited reads the postponed renewal of a domain. Returns nil if there is no postponed renewal.
+func (s *CertificatesStorage) ReadRateLimited(domain string) (*rateLimitedRenewal, error) {
+	if !s.ExistsFile(domain, rateLimitedExt) {
+		return nil, nil
+	}
+
+	raw, err := s.ReadFile(domain, rateLimitedExt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the rate-limited renewal for domain %s: %w", domain, err)
+	}
+
+	var renewal rateLimitedRenewal
+	if err = json.Unmarshal(raw, &renewal); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the rate-limited renewal for domain %s: %w", domain, err)
+	}
+
+	return &renewal, nil
+}
+
+// RemoveRateLimited removes the postponed renewal of a domain.
+func (s *CertificatesStorage) RemoveRateLimited(domain string) error {
+	return s.RemoveFile(domain, rateLimitedExt)
+}
+
+// getRateLimitRetryAfter returns the duration to wait if the error is a rate limit error of the CA,
+// or an order refused because it would exceed a rate limit (--ratelimit.enforce).
+func getRateLimitRetryAfter(err error) (time.Duration, bool) {
+	var exceeded *ratelimit.ExceededError
+	if errors.As(err, &exceeded) {
+		return max(time.Until(exceeded.RetryAt), 0), true
+	}
+
+	var rateLimited *acme.RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		return 0, false
+	}
+
+	if rateLimited.RetryAfter <= 0 {
+		return defaultRateLimitRetryAfter, true
+	}
+
+	return rateLimited.RetryAfter, true
+}
+
+// obtainWithRateLimit calls obtain, and retries once after the Retry-After window
+// if the request is rate limited by the CA and the window is shorter than maxWait.
+func obtainWithRateLimit(domain string, maxWait time.Duration, obtain func() (*certificate.Resource, error)) (*certificate.Resource, error) {
+	certRes, err := obtain()
+
+	retryAfter, ok := getRateLimitRetryAfter(err)
+	if !ok || retryAfter > maxWait {
+		return certRes, err
+	}
+
+	log.Infof("[%s] acme: rate limited by the CA: retrying in %s.", domain, retryAfter)
+
+	time.Sleep(retryAfter)
+
+	return obtain()
+}
+
+// checkRateLimited checks if the renewal of the domain has been postponed by the rate limit of the CA.
+// It returns postponed=true if the Retry-After window is not over,
+// and resumed=true if the window is over: the renewal must be done whatever the number of days left.
+func checkRateLimited(certsStorage *CertificatesStorage, domain string, now time.Time) (resumed, postponed bool) {
+	renewal, err := certsStorage.ReadRateLimited(domain)
+	if err != nil {
+		log.Warnf("[%s] renewal: %v", domain, err)
+		return false, false
+	}
+
+	if renewal == nil {
+		return false, false
+	}
+
+	if now.Before(renewal.RetryAt) {
+		log.Infof("[%s] renewal: rate limited by the CA: the renewal is postponed until %s.", domain, renewal.RetryAt.Format(time.RFC3339))
+		return false, true
+	}
+
+	log.Infof("[%s] renewal: resuming the renewal postponed by the rate limit of the CA.", domain)
+
+	return true, false
+}
+
+// postponeRateLimited saves the renewal of the domain to resume it after the Retry-After window,
+// if the error is a rate limit error of the CA.
+// Returns false if the renewal cannot be postponed.
+func postponeRateLimited(certsStorage *CertificatesStorage, domain string, now time.Time, err error) bool {
+	retryAfter, ok := getRateLimitRetryAfter(err)
+	if !ok {
+		return false
+	}
+
+	renewal := &rateLimitedRenewal{
+		RetryAt: now.Add(retryAfter).UTC(),
+		Error:   err.Error(),
+	}
+
+	if errS := certsStorage.SaveRateLimited(domain, renewal); errS != nil {
+		log.Warnf("[%s] renewal: %v", domain, errS)
+		return false
+	}
+
+	log.Warnf("[%s] renewal: rate limited by the CA: the renewal is postponed until %s: %v", domain, renewal.RetryAt.Format(time.RFC3339), err)
+
+	return true
+}
+
+// clearRateLimited removes the postponed renewal of the domain, after a successful renewal.
+func clearRateLimited(certsStorage *CertificatesStorage, domain string) {
+	if err := certsStorage.RemoveRateLimited(domain); err != nil {
+		log.Warnf("[%s] renewal: unable to remove the rate-limited renewal: %v", domain, err)
+	}
+}
+
+// issuanceLimiter checks the orders against the rate limits of the CA, and tracks the issued certificates in the storage.
+type issuanceLimiter struct {
+	tracker *ratelimit.Tracker
+	account string
+
+	// enforce refuses the orders exceeding a limit, instead of only logging a warning (--ratelimit.enforce).
+	enforce bool
+	// maxWait the orders exceeding a limit are delayed if the wait is shorter than this duration (--ratelimit.max-wait).
+	maxWait time.Duration
+}
+
+func newIssuanceLimiter(ctx *cli.Context, account string) *issuanceLimiter {
+	// The limits of the unknown CAs are not checked, but the rate limit errors of the CA are tracked.
+	limits, _ := ratelimit.ForServer(ctx.String("server"))
+
+	serverURL, err := url.Parse(ctx.String("server"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	serverPath := strings.NewReplacer(":", "_").Replace(serverURL.Host)
+
+	return &issuanceLimiter{
+		tracker: ratelimit.NewTracker(getStore(ctx), path.Join(rateLimitsRootKey, serverPath+".json"), limits),
+		account: account,
+		enforce: ctx.Bool("ratelimit.enforce"),
+		maxWait: ctx.Duration("ratelimit.max-wait"),
+	}
+}
+
+// obtain checks the order of the domains against the rate limits before calling obtain, and tracks the issued certificate.
+// An order exceeding a limit is delayed if the wait is shorter than maxWait, refused otherwise (*ratelimit.ExceededError),
+// or only logged if the limits are not enforced.
+func (l *issuanceLimiter) obtain(ctx context.Context, domain string, domains []string, obtain func() (*certificate.Resource, error)) (*certificate.Resource, error) {
+	err := l.tracker.Check(ctx, l.account, domains, time.Now())
+
+	var exceeded *ratelimit.ExceededError
+
+	switch {
+	case errors.As(err, &exceeded):
+		wait := time.Until(exceeded.RetryAt)
+
+		switch {
+		case !l.enforce:
+			log.Warnf("[%s] acme: the order would exceed a rate limit of the CA: %v", domain, exceeded)
+
+		case wait <= l.maxWait:
+			log.Infof("[%s] acme: the order would exceed a rate limit of the CA: delaying the order by %s: %v", domain, wait, exceeded)
+
+			time.Sleep(wait)
+
+		default:
+			return nil, exceeded
+		}
+
+	case err != nil:
+		log.Warnf("[%s] acme: unable to check the rate limits: %v", domain, err)
+	}
+
+	certRes, err := obtain()
+	if err != nil {
+		l.tracker.RecordError(ctx, l.account, domains, err, time.Now())
+
+		return certRes, err
+	}
+
+	if errR := l.tracker.Record(ctx, l.account, domains, time.Now()); errR != nil {
+		log.Warnf("[%s] acme: unable to track the issued certificate: %v", domain, errR)
+	}
+
+	return certRes, nil
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/acme/ratelimit"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rateLimited_postponeAndResume(t *testing.T) {
+	storage := newTestCertificatesStorage(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	err := fmt.Errorf("wrapped: %w", &acme.RateLimitedError{
+		ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr, HTTPStatus: 429},
+		RetryAfter:     2 * time.Hour,
+	})
+
+	require.True(t, postponeRateLimited(storage, "example.com", now, err))
+
+	renewal, errR := storage.ReadRateLimited("example.com")
+	require.NoError(t, errR)
+	assert.Equal(t, now.Add(2*time.Hour), renewal.RetryAt)
+
+	resumed, postponed := checkRateLimited(storage, "example.com", now.Add(time.Hour))
+	assert.False(t, resumed)
+	assert.True(t, postponed)
+
+	resumed, postponed = checkRateLimited(storage, "example.com", now.Add(3*time.Hour))
+	assert.True(t, resumed)
+	assert.False(t, postponed)
+
+	clearRateLimited(storage, "example.com")
+
+	resumed, postponed = checkRateLimited(storage, "example.com", now)
+	assert.False(t, resumed)
+	assert.False(t, postponed)
+}
+
+func Test_postponeRateLimited_otherError(t *testing.T) {
+	storage := newTestCertificatesStorage(t)
+
+	assert.False(t, postponeRateLimited(storage, "example.com", time.Now(), errors.New("oops")))
+	assert.False(t, storage.ExistsFile("example.com", rateLimitedExt))
+}
+
+func Test_obtainWithRateLimit(t *testing.T) {
+	rateLimited := &acme.RateLimitedError{
+		ProblemDetails: &acme.ProblemDetails{Type: acme.RateLimitedErr},
+		RetryAfter:     time.Millisecond,
+	}
+
+	testCases := []struct {
+		desc          string
+		maxWait       time.Duration
+		expectedCalls int
+	}{
+		{desc: "retry", maxWait: time.Second, expectedCalls: 2},
+		{desc: "window too long", maxWait: 0, expectedCalls: 1},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+
+			_, err := obtainWithRateLimit("example.com", test.maxWait, func() (*certificate.Resource, error) {
+				calls++
+				return nil, rateLimited
+			})
+			require.ErrorIs(t, err, rateLimited)
+
+			assert.Equal(t, test.expectedCalls, calls)
+		})
+	}
+}
+
+func Test_issuanceLimiter_obtain(t *testing.T) {
+	limits := ratelimit.Limits{DuplicateCertificates: ratelimit.Limit{Max: 1, Window: time.Hour}}
+
+	testCases := []struct {
+		desc          string
+		enforce       bool
+		maxWait       time.Duration
+		expectedCalls int
+		expected      string
+	}{
+		{
+			desc:          "not enforced",
+			expectedCalls: 2,
+		},
+		{
+			desc:          "enforced",
+			enforce:       true,
+			expectedCalls: 1,
+			expected:      "ratelimit: duplicate-certificates limit exceeded for example.com (1 in 1h0m0s), retry after ",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := &issuanceLimiter{
+				tracker: ratelimit.NewTracker(storage.NewFileSystem(t.TempDir()), "ratelimits/example.json", limits),
+				account: "bob",
+				enforce: test.enforce,
+				maxWait: test.maxWait,
+			}
+
+			var calls int
+
+			obtain := func() (*certificate.Resource, error) {
+				calls++
+				return &certificate.Resource{Domain: "example.com"}, nil
+			}
+
+			_, err := limiter.obtain(context.Background(), "example.com", []string{"example.com"}, obtain)
+			require.NoError(t, err)
+
+			_, err = limiter.obtain(context.Background(), "example.com", []string{"example.com"}, obtain)
+			if test.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.expected)
+
+				retryAfter, ok := getRateLimitRetryAfter(err)
+				require.True(t, ok)
+				assert.InDelta(t, time.Hour, retryAfter, float64(time.Minute))
+			}
+
+			assert.Equal(t, test.expectedCalls, calls)
+		})
+	}
+}
+
+func Test_issuanceLimiter_obtain_rateLimitError(t *testing.T) {
+	limiter := &issuanceLimiter{
+		tracker: ratelimit.NewTracker(storage.NewFileSystem(t.TempDir()), "ratelimits/example.json", ratelimit.Limits{}),
+		account: "bob",
+		enforce: true,
+	}
+
+	rateLimited := &acme.RateLimitedError{
+		ProblemDetails: &acme.ProblemDetails{
+			Type:   acme.RateLimitedErr,
+			Detail: `too many certificates (50) already issued for "example.com" in the last 168h0m0s, see https://letsencrypt.org/docs/rate-limits/`,
+		},
+		RetryAfter: 2 * time.Hour,
+	}
+
+	var calls int
+
+	_, err := limiter.obtain(context.Background(), "a.example.com", []string{"a.example.com"}, func() (*certificate.Resource, error) {
+		calls++
+		return nil, rateLimited
+	})
+	require.ErrorIs(t, err, rateLimited)
+
+	// the next order for the registered domain is refused without calling the CA.
+	_, err = limiter.obtain(context.Background(), "b.example.com", []string{"b.example.com"}, func() (*certificate.Resource, error) {
+		calls++
+		return nil, nil
+	})
+
+	var exceeded *ratelimit.ExceededError
+	require.ErrorAs(t, err, &exceeded)
+
+	assert.Equal(t, ratelimit.CertificatesPerDomain, exceeded.Limit)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/log"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// scheduleWindow a weekly period of time during which a certificate is allowed to be renewed.
+// The window ends the next day if the end is before the start (ex: 22:00-02:00).
+type scheduleWindow struct {
+	days  [7]bool
+	start time.Duration
+	end   time.Duration
+}
+
+// renewalSchedule the windows during which a certificate is allowed to be renewed (always if empty).
+type renewalSchedule []scheduleWindow
+
+// parseRenewalSchedule parses windows in the format `<days> <HH:MM>-<HH:MM>`,
+// where days is `*`, a day (`Sun`), a range (`Mon-Fri`), or a list (`Sat,Sun`).
+func parseRenewalSchedule(values []string) (renewalSchedule, error) {
+	var schedule renewalSchedule
+
+	for _, value := range values {
+		window, err := parseScheduleWindow(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid renewal window %q: %w", value, err)
+		}
+
+		schedule = append(schedule, window)
+	}
+
+	return schedule, nil
+}
+
+func parseScheduleWindow(value string) (scheduleWindow, error) {
+	var window scheduleWindow
+
+	days, hours, found := strings.Cut(strings.TrimSpace(value), " ")
+	if !found {
+		return window, errors.New("expected '<days> <HH:MM>-<HH:MM>'")
+	}
+
+	for _, part := range strings.Split(days, ",") {
+		if part == "*" {
+			window.days = [7]bool{true, true, true, true, true, true, true}
+			continue
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			last = first
+		}
+
+		from, ok := weekdays[strings.ToLower(first)]
+		if !ok {
+			return window, fmt.Errorf("unknown day %q", first)
+		}
+
+		to, ok := weekdays[strings.ToLower(last)]
+		if !ok {
+			return window, fmt.Errorf("unknown day %q", last)
+		}
+
+		for d := from; ; d = (d + 1) % 7 {
+			window.days[d] = true
+
+			if d == to {
+				break
+			}
+		}
+	}
+
+	start, end, found := strings.Cut(strings.TrimSpace(hours), "-")
+	if !found {
+		return window, errors.New("expected '<HH:MM>-<HH:MM>'")
+	}
+
+	var err error
+
+	window.start, err = parseClock(start)
+	if err != nil {
+		return window, err
+	}
+
+	window.end, err = parseClock(end)
+	if err != nil {
+		return window, err
+	}
+
+	if window.start == window.end {
+		return window, errors.New("the window is empty")
+	}
+
+	return window, nil
+}
+
+// parseClock parses a time of the day (HH:MM), 24:00 is allowed as the end of the day.
+func parseClock(value string) (time.Duration, error) {
+	h, m, found := strings.Cut(strings.TrimSpace(value), ":")
+	if !found {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", value)
+	}
+
+	hours, err := strconv.Atoi(h)
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, fmt.Errorf("invalid time %q: invalid hours", value)
+	}
+
+	minutes, err := strconv.Atoi(m)
+	if err != nil || minutes < 0 || minutes > 59 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("invalid time %q: invalid minutes", value)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// occurrences returns the periods of the window starting the given day (midnight),
+// and the day before (for the windows ending the next day).
+func (w scheduleWindow) occurrences(day time.Time) [][2]time.Time {
+	var periods [][2]time.Time
+
+	for _, d := range []time.Time{day.AddDate(0, 0, -1), day} {
+		if !w.days[d.Weekday()] {
+			continue
+		}
+
+		start := d.Add(w.start)
+
+		end := d.Add(w.end)
+		if w.end < w.start {
+			end = d.AddDate(0, 0, 1).Add(w.end)
+		}
+
+		periods = append(periods, [2]time.Time{start, end})
+	}
+
+	return periods
+}
+
+// contains checks if the time is inside a window of the schedule.
+func (s renewalSchedule) contains(now time.Time) bool {
+	if len(s) == 0 {
+		return true
+	}
+
+	day := midnight(now)
+
+	for _, window := range s {
+		for _, period := range window.occurrences(day) {
+			if !now.Before(period[0]) && now.Before(period[1]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// next returns the start of the next window after the time.
+func (s renewalSchedule) next(now time.Time) time.Time {
+	var next time.Time
+
+	day := midnight(now)
+
+	for i := 0; i <= 7; i++ {
+		for _, window := range s {
+			for _, period := range window.occurrences(day.AddDate(0, 0, i)) {
+				if period[0].After(now) && (next.IsZero() || period[0].Before(next)) {
+					next = period[0]
+				}
+			}
+		}
+
+		if !next.IsZero() {
+			return next
+		}
+	}
+
+	return next
+}
+
+// checkRenewalSchedule checks if the certificate of the domain can be renewed now.
+// Outside the windows, the renewal is postponed, unless the deadline (the expiration of the certificate,
+// or the end of the ARI suggested window) is before the next window.
+func checkRenewalSchedule(schedule renewalSchedule, domain string, now, deadline time.Time) bool {
+	if schedule.contains(now) {
+		return true
+	}
+
+	next := schedule.next(now)
+
+	if !next.Before(deadline) {
+		log.Warnf("[%s] renewal: outside the renewal windows, but the certificate must be renewed before the next window (%s): the certificate is renewed now.", domain, next)
+		return true
+	}
+
+	log.Infof("[%s] renewal: outside the renewal windows: the renewal is postponed to the next window (%s).", domain, next)
+
+	return false
+}
+
+// midnight returns the start of the day, in the location of the time.
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseRenewalSchedule_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected string
+	}{
+		{
+			desc:     "missing hours",
+			value:    "Sun",
+			expected: `invalid renewal window "Sun": expected '<days> <HH:MM>-<HH:MM>'`,
+		},
+		{
+			desc:     "unknown day",
+			value:    "Sunday 02:00-04:00",
+			expected: `invalid renewal window "Sunday 02:00-04:00": unknown day "Sunday"`,
+		},
+		{
+			desc:     "invalid time",
+			value:    "Sun 02:00-25:00",
+			expected: `invalid renewal window "Sun 02:00-25:00": invalid time "25:00": invalid hours`,
+		},
+		{
+			desc:     "empty window",
+			value:    "Sun 02:00-02:00",
+			expected: `invalid renewal window "Sun 02:00-02:00": the window is empty`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseRenewalSchedule([]string{test.value})
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func Test_renewalSchedule(t *testing.T) {
+	schedule, err := parseRenewalSchedule([]string{"Sun 02:00-04:00", "Mon-Fri 22:00-01:00"})
+	require.NoError(t, err)
+
+	// 2024-06-02 is a Sunday.
+	date := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.June, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		desc         string
+		now          time.Time
+		contains     bool
+		expectedNext time.Time
+	}{
+		{
+			desc:         "Sunday, in the window",
+			now:          date(2, 3, 0),
+			contains:     true,
+			expectedNext: date(3, 22, 0),
+		},
+		{
+			desc:         "Sunday, before the window",
+			now:          date(2, 1, 0),
+			expectedNext: date(2, 2, 0),
+		},
+		{
+			desc:         "Saturday, after the end of the Friday window",
+			now:          date(8, 0, 30),
+			contains:     true,
+			expectedNext: date(9, 2, 0),
+		},
+		{
+			desc:         "Saturday, outside the windows",
+			now:          date(8, 12, 0),
+			expectedNext: date(9, 2, 0),
+		},
+		{
+			desc:         "Wednesday, before the window",
+			now:          date(5, 21, 59),
+			expectedNext: date(5, 22, 0),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.contains, schedule.contains(test.now))
+			assert.Equal(t, test.expectedNext, schedule.next(test.now))
+		})
+	}
+}
+
+func Test_checkRenewalSchedule(t *testing.T) {
+	schedule, err := parseRenewalSchedule([]string{"Sun 02:00-04:00"})
+	require.NoError(t, err)
+
+	// Monday.
+	now := time.Date(2024, time.June, 3, 12, 0, 0, 0, time.UTC)
+
+	assert.False(t, checkRenewalSchedule(schedule, "example.com", now, now.Add(30*24*time.Hour)))
+	assert.True(t, checkRenewalSchedule(schedule, "example.com", now, now.Add(2*24*time.Hour)))
+	assert.True(t, checkRenewalSchedule(nil, "example.com", now, now.Add(30*24*time.Hour)))
+}
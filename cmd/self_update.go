@@ -0,0 +1,585 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/log"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Release channels.
+const (
+	channelStable = "stable" // the releases.
+	channelEdge   = "edge"   // the releases and the pre-releases.
+)
+
+const releasesRepository = "go-acme/lego"
+
+// releaseKeys the minisign public keys of the releases (base64, separated by commas).
+// Defined at build time: -X github.com/pya789/lego/v4/cmd.releaseKeys=...
+var releaseKeys = ""
+
+// maxArchiveSize the maximum size of a downloaded file.
+const maxArchiveSize = 200 << 20
+
+type githubRelease struct {
+	TagName    string        `json:"tag_name"`
+	Draft      bool          `json:"draft"`
+	Prerelease bool          `json:"prerelease"`
+	Assets     []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// platform the target of a binary.
+type platform struct {
+	OS   string
+	Arch string
+	Arm  string // the ARM version (ex: 7).
+	Mips string // the MIPS floating point mode (hardfloat, softfloat).
+}
+
+// currentPlatform returns the platform of the running binary.
+func currentPlatform() platform {
+	p := platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+	if p.Arch == "arm" {
+		p.Arm = "7"
+	}
+
+	if strings.HasPrefix(p.Arch, "mips") {
+		p.Mips = "hardfloat"
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return p
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "GOARM":
+			// ex: 7, 6,softfloat
+			p.Arm, _, _ = strings.Cut(setting.Value, ",")
+		case "GOMIPS", "GOMIPS64":
+			p.Mips = setting.Value
+		}
+	}
+
+	return p
+}
+
+// archiveName returns the name of the release archive of the platform (see .goreleaser.yml).
+func (p platform) archiveName(version string) string {
+	name := fmt.Sprintf("lego_v%s_%s_%s", strings.TrimPrefix(version, "v"), p.OS, p.Arch)
+
+	if p.Arm != "" {
+		name += "v" + p.Arm
+	}
+
+	if p.Mips != "" {
+		name += "_" + p.Mips
+	}
+
+	if p.OS == "windows" {
+		return name + ".zip"
+	}
+
+	return name + ".tar.gz"
+}
+
+// binaryName returns the name of the binary inside the release archive.
+func (p platform) binaryName() string {
+	if p.OS == "windows" {
+		return "lego.exe"
+	}
+
+	return "lego"
+}
+
+// selfUpdater updates the running binary with the binary of a release.
+type selfUpdater struct {
+	client   *http.Client
+	apiURL   string
+	channel  string
+	platform platform
+	keys     []minisignPublicKey
+}
+
+// latestRelease returns the most recent release of the channel.
+func (u *selfUpdater) latestRelease(ctx context.Context) (*githubRelease, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/releases?per_page=30", strings.TrimSuffix(u.apiURL, "/"), releasesRepository)
+
+	raw, err := u.download(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the releases: %w", err)
+	}
+
+	var releases []githubRelease
+
+	err = json.Unmarshal(raw, &releases)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the releases: %w", err)
+	}
+
+	var latest *githubRelease
+
+	for i, release := range releases {
+		if release.Draft || release.Prerelease && u.channel != channelEdge {
+			continue
+		}
+
+		if latest == nil || compareVersions(release.TagName, latest.TagName) > 0 {
+			latest = &releases[i]
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("no release found in the %s channel", u.channel)
+	}
+
+	return latest, nil
+}
+
+// fetchBinary downloads the archive of the platform, verifies its checksum and the signature of the checksums,
+// and returns the binary.
+func (u *selfUpdater) fetchBinary(ctx context.Context, release *githubRelease) ([]byte, error) {
+	version := strings.TrimPrefix(release.TagName, "v")
+
+	archiveName := u.platform.archiveName(version)
+	checksumsName := fmt.Sprintf("lego_%s_checksums.txt", version)
+
+	archiveURL, err := assetURL(release, archiveName)
+	if err != nil {
+		return nil, err
+	}
+
+	checksumsURL, err := assetURL(release, checksumsName)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureURL, err := assetURL(release, checksumsName+".minisig")
+	if err != nil {
+		return nil, err
+	}
+
+	checksums, err := u.download(ctx, checksumsURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", checksumsName, err)
+	}
+
+	signature, err := u.download(ctx, signatureURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s.minisig: %w", checksumsName, err)
+	}
+
+	err = verifyMinisign(u.keys, checksums, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", checksumsName, err)
+	}
+
+	expected, err := findChecksum(checksums, archiveName)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := u.download(ctx, archiveURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", archiveName, err)
+	}
+
+	sum := sha256.Sum256(archive)
+	if hex.EncodeToString(sum[:]) != expected {
+		return nil, fmt.Errorf("%s: checksum mismatch", archiveName)
+	}
+
+	binary, err := extractBinary(archive, archiveName, u.platform.binaryName())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", archiveName, err)
+	}
+
+	return binary, nil
+}
+
+func (u *selfUpdater) download(ctx context.Context, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) > maxArchiveSize {
+		return nil, errors.New("file too large")
+	}
+
+	return raw, nil
+}
+
+func assetURL(release *githubRelease, name string) (string, error) {
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset.BrowserDownloadURL, nil
+		}
+	}
+
+	return "", fmt.Errorf("the release %s has no asset %s", release.TagName, name)
+}
+
+// findChecksum returns the SHA-256 checksum of a file in a checksums file (sha256sum format).
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// extractBinary returns the content of the binary in a release archive (tar.gz or zip).
+func extractBinary(archive []byte, archiveName, binaryName string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range reader.File {
+			if path.Base(file.Name) != binaryName || file.FileInfo().IsDir() {
+				continue
+			}
+
+			rc, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+
+			defer func() { _ = rc.Close() }()
+
+			return io.ReadAll(io.LimitReader(rc, maxArchiveSize))
+		}
+
+		return nil, fmt.Errorf("%s not found in the archive", binaryName)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+
+	reader := tar.NewReader(gz)
+
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s not found in the archive", binaryName)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == binaryName {
+			return io.ReadAll(io.LimitReader(reader, maxArchiveSize))
+		}
+	}
+}
+
+// minisignPublicKey a minisign public key (Ed25519).
+type minisignPublicKey struct {
+	id  [8]byte
+	key ed25519.PublicKey
+}
+
+// parseMinisignPublicKey parses a minisign public key: the base64 line of the public key file.
+func parseMinisignPublicKey(value string) (minisignPublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return minisignPublicKey{}, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	if len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return minisignPublicKey{}, errors.New("invalid public key: not a minisign Ed25519 public key")
+	}
+
+	key := minisignPublicKey{key: ed25519.PublicKey(raw[10:])}
+	copy(key.id[:], raw[2:10])
+
+	return key, nil
+}
+
+// verifyMinisign verifies a minisign signature file with one of the public keys.
+// The signature and the global signature (the signature of the trusted comment) are verified.
+func verifyMinisign(keys []minisignPublicKey, message, signatureFile []byte) error {
+	lines := strings.Split(strings.ReplaceAll(string(signatureFile), "\r\n", "\n"), "\n")
+	if len(lines) < 4 {
+		return errors.New("invalid signature file")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return errors.New("invalid signature file")
+	}
+
+	trustedComment, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return errors.New("invalid signature file: missing trusted comment")
+	}
+
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("invalid signature file")
+	}
+
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		// pre-hashed signature.
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	default:
+		return fmt.Errorf("unsupported signature algorithm: %q", sig[:2])
+	}
+
+	for _, key := range keys {
+		if !bytes.Equal(key.id[:], sig[2:10]) {
+			continue
+		}
+
+		if !ed25519.Verify(key.key, message, sig[10:]) {
+			return errors.New("invalid signature")
+		}
+
+		if !ed25519.Verify(key.key, append(append([]byte{}, sig[10:]...), trustedComment...), globalSig) {
+			return errors.New("invalid signature of the trusted comment")
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("signed by an unknown key: %X", sig[2:10])
+}
+
+// installBinary replaces the binary with the new binary, the previous binary is kept (.old) for the rollback.
+// The new binary is checked (--version): the previous binary is restored if the new binary doesn't start.
+func installBinary(executable string, binary []byte) error {
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+
+	newFile := executable + ".new"
+	oldFile := executable + ".old"
+
+	// The permissions of os.WriteFile are modified by the umask.
+	err = os.WriteFile(newFile, binary, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(newFile) }()
+
+	err = os.Chmod(newFile, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	err = checkBinary(newFile)
+	if err != nil {
+		return fmt.Errorf("the new binary doesn't start: %w", err)
+	}
+
+	_ = os.Remove(oldFile)
+
+	// A running binary can be renamed (but not removed on Windows).
+	err = os.Rename(executable, oldFile)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(newFile, executable)
+	if err != nil {
+		if errR := os.Rename(oldFile, executable); errR != nil {
+			return errors.Join(err, fmt.Errorf("unable to restore the previous binary (%s): %w", oldFile, errR))
+		}
+
+		return err
+	}
+
+	err = checkBinary(executable)
+	if err != nil {
+		errR := rollbackBinary(executable)
+		if errR != nil {
+			return errors.Join(fmt.Errorf("the new binary doesn't start: %w", err), errR)
+		}
+
+		return fmt.Errorf("the new binary doesn't start, the previous binary has been restored: %w", err)
+	}
+
+	return nil
+}
+
+// rollbackBinary swaps the binary and the previous binary (.old): a rollback can be undone by another rollback.
+func rollbackBinary(executable string) error {
+	oldFile := executable + ".old"
+
+	_, err := os.Stat(oldFile)
+	if err != nil {
+		return fmt.Errorf("no previous binary: %w", err)
+	}
+
+	tmpFile := executable + ".rollback"
+
+	err = os.Rename(executable, tmpFile)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(oldFile, executable)
+	if err != nil {
+		if errR := os.Rename(tmpFile, executable); errR != nil {
+			return errors.Join(err, errR)
+		}
+
+		return err
+	}
+
+	return os.Rename(tmpFile, oldFile)
+}
+
+// checkBinary runs the binary with --version.
+func checkBinary(filename string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, filename, "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	log.Infof("self-update: %s", strings.TrimSpace(string(output)))
+
+	return nil
+}
+
+// currentExecutable returns the path of the running binary (symbolic links resolved).
+func currentExecutable() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(executable)
+}
+
+// compareVersions compares two versions (ex: v4.18.0, v4.19.0-rc.1).
+// A version that is not parsable (ex: dev) is older than all the versions.
+func compareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := range 3 {
+		if va.numbers[i] != vb.numbers[i] {
+			if va.numbers[i] < vb.numbers[i] {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	// a pre-release is older than the release.
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	case va.pre < vb.pre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+type semver struct {
+	numbers [3]int
+	pre     string
+}
+
+func parseVersion(value string) (semver, bool) {
+	core, pre, _ := strings.Cut(strings.TrimPrefix(value, "v"), "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+
+	v := semver{pre: pre}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return semver{}, false
+		}
+
+		v.numbers[i] = n
+	}
+
+	return v, true
+}
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignSigner signs the files like minisign.
+type minisignSigner struct {
+	id         [8]byte
+	privateKey ed25519.PrivateKey
+	publicKey  string
+}
+
+func newMinisignSigner(t *testing.T, id byte) *minisignSigner {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	s := &minisignSigner{id: [8]byte{id}, privateKey: priv}
+	s.publicKey = base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), s.id[:]...), pub...))
+
+	return s
+}
+
+func (s *minisignSigner) sign(message []byte, prehashed bool) []byte {
+	algorithm := "Ed"
+
+	if prehashed {
+		algorithm = "ED"
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	}
+
+	sig := ed25519.Sign(s.privateKey, message)
+	trustedComment := "timestamp:1700000000"
+	globalSig := ed25519.Sign(s.privateKey, append(append([]byte{}, sig...), trustedComment...))
+
+	return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte(algorithm), s.id[:]...), sig...)),
+		trustedComment,
+		base64.StdEncoding.EncodeToString(globalSig)))
+}
+
+func (s *minisignSigner) key(t *testing.T) minisignPublicKey {
+	t.Helper()
+
+	key, err := parseMinisignPublicKey(s.publicKey)
+	require.NoError(t, err)
+
+	return key
+}
+
+func Test_verifyMinisign(t *testing.T) {
+	signer := newMinisignSigner(t, 1)
+	other := newMinisignSigner(t, 2)
+
+	message := []byte("checksums")
+
+	testCases := []struct {
+		desc      string
+		keys      []minisignPublicKey
+		message   []byte
+		signature []byte
+		expected  string
+	}{
+		{
+			desc:      "legacy signature",
+			keys:      []minisignPublicKey{signer.key(t)},
+			message:   message,
+			signature: signer.sign(message, false),
+		},
+		{
+			desc:      "pre-hashed signature",
+			keys:      []minisignPublicKey{other.key(t), signer.key(t)},
+			message:   message,
+			signature: signer.sign(message, true),
+		},
+		{
+			desc:      "modified message",
+			keys:      []minisignPublicKey{signer.key(t)},
+			message:   []byte("modified"),
+			signature: signer.sign(message, true),
+			expected:  "invalid signature",
+		},
+		{
+			desc:      "unknown key",
+			keys:      []minisignPublicKey{other.key(t)},
+			message:   message,
+			signature: signer.sign(message, true),
+			expected:  "signed by an unknown key: 0100000000000000",
+		},
+		{
+			desc:      "invalid file",
+			keys:      []minisignPublicKey{signer.key(t)},
+			message:   message,
+			signature: []byte("untrusted comment: invalid\n"),
+			expected:  "invalid signature file",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifyMinisign(test.keys, test.message, test.signature)
+			if test.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func Test_verifyMinisign_trustedComment(t *testing.T) {
+	signer := newMinisignSigner(t, 1)
+
+	signature := bytes.Replace(signer.sign([]byte("checksums"), true), []byte("timestamp:1700000000"), []byte("timestamp:1800000000"), 1)
+
+	err := verifyMinisign([]minisignPublicKey{signer.key(t)}, []byte("checksums"), signature)
+	require.EqualError(t, err, "invalid signature of the trusted comment")
+}
+
+func Test_compareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "v4.18.0", b: "v4.18.0", expected: 0},
+		{a: "v4.18.0", b: "4.17.1", expected: 1},
+		{a: "v4.9.0", b: "v4.10.0", expected: -1},
+		{a: "v4.19.0-rc.1", b: "v4.18.0", expected: 1},
+		{a: "v4.19.0-rc.1", b: "v4.19.0", expected: -1},
+		{a: "v4.19.0-rc.2", b: "v4.19.0-rc.1", expected: 1},
+		{a: "v4.18.0", b: "dev", expected: 1},
+		{a: "dev", b: "dev", expected: 0},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, compareVersions(test.a, test.b))
+		})
+	}
+}
+
+func Test_platform_archiveName(t *testing.T) {
+	testCases := []struct {
+		platform platform
+		expected string
+	}{
+		{platform: platform{OS: "linux", Arch: "amd64"}, expected: "lego_v4.18.0_linux_amd64.tar.gz"},
+		{platform: platform{OS: "linux", Arch: "arm", Arm: "6"}, expected: "lego_v4.18.0_linux_armv6.tar.gz"},
+		{platform: platform{OS: "linux", Arch: "mipsle", Mips: "softfloat"}, expected: "lego_v4.18.0_linux_mipsle_softfloat.tar.gz"},
+		{platform: platform{OS: "windows", Arch: "arm64"}, expected: "lego_v4.18.0_windows_arm64.zip"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.expected, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, test.platform.archiveName("v4.18.0"))
+		})
+	}
+}
+
+func TestSelfUpdater(t *testing.T) {
+	signer := newMinisignSigner(t, 1)
+
+	p := platform{OS: "linux", Arch: "arm", Arm: "7"}
+
+	archive := tarGz(t, map[string][]byte{"LICENSE": []byte("MIT"), "lego": []byte("BINARY")})
+	archiveSum := sha256.Sum256(archive)
+
+	checksums := []byte(fmt.Sprintf("%s  lego_v4.19.0-rc.1_linux_armv7.tar.gz\n%s  lego_v4.19.0-rc.1_linux_amd64.tar.gz\n",
+		hex.EncodeToString(archiveSum[:]), hex.EncodeToString(make([]byte, 32))))
+
+	files := map[string][]byte{
+		"/download/lego_v4.19.0-rc.1_linux_armv7.tar.gz":   archive,
+		"/download/lego_4.19.0-rc.1_checksums.txt":         checksums,
+		"/download/lego_4.19.0-rc.1_checksums.txt.minisig": signer.sign(checksums, true),
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/repos/go-acme/lego/releases", func(rw http.ResponseWriter, _ *http.Request) {
+		var assets []githubAsset
+		for name := range files {
+			assets = append(assets, githubAsset{Name: filepath.Base(name), BrowserDownloadURL: server.URL + name})
+		}
+
+		_ = json.NewEncoder(rw).Encode([]githubRelease{
+			{TagName: "v4.19.0-rc.1", Prerelease: true, Assets: assets},
+			{TagName: "v4.20.0", Draft: true},
+			{TagName: "v4.18.0"},
+			{TagName: "v4.17.4"},
+		})
+	})
+
+	mux.HandleFunc("/download/", func(rw http.ResponseWriter, req *http.Request) {
+		content, ok := files[req.URL.Path]
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+
+		_, _ = rw.Write(content)
+	})
+
+	updater := &selfUpdater{
+		client:   server.Client(),
+		apiURL:   server.URL,
+		channel:  channelStable,
+		platform: p,
+		keys:     []minisignPublicKey{signer.key(t)},
+	}
+
+	release, err := updater.latestRelease(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "v4.18.0", release.TagName)
+
+	updater.channel = channelEdge
+
+	release, err = updater.latestRelease(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "v4.19.0-rc.1", release.TagName)
+
+	binary, err := updater.fetchBinary(context.Background(), release)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("BINARY"), binary)
+
+	// the archive doesn't match the checksum.
+	files["/download/lego_v4.19.0-rc.1_linux_armv7.tar.gz"] = tarGz(t, map[string][]byte{"lego": []byte("MALICIOUS")})
+
+	_, err = updater.fetchBinary(context.Background(), release)
+	require.EqualError(t, err, "lego_v4.19.0-rc.1_linux_armv7.tar.gz: checksum mismatch")
+
+	// the checksums are not signed by a trusted key.
+	updater.keys = []minisignPublicKey{newMinisignSigner(t, 2).key(t)}
+
+	_, err = updater.fetchBinary(context.Background(), release)
+	require.EqualError(t, err, "lego_4.19.0-rc.1_checksums.txt: signed by an unknown key: 0100000000000000")
+}
+
+func Test_installBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts")
+	}
+
+	executable := filepath.Join(t.TempDir(), "lego")
+
+	err := os.WriteFile(executable, []byte("#!/bin/sh\necho lego version v4.18.0\n"), 0o755)
+	require.NoError(t, err)
+
+	// the new binary doesn't start: the binary is not replaced.
+	err = installBinary(executable, []byte("#!/bin/sh\nexit 1\n"))
+	require.Error(t, err)
+
+	assertFileContent(t, executable, "#!/bin/sh\necho lego version v4.18.0\n")
+	assert.NoFileExists(t, executable+".new")
+
+	err = installBinary(executable, []byte("#!/bin/sh\necho lego version v4.19.0\n"))
+	require.NoError(t, err)
+
+	assertFileContent(t, executable, "#!/bin/sh\necho lego version v4.19.0\n")
+	assertFileContent(t, executable+".old", "#!/bin/sh\necho lego version v4.18.0\n")
+
+	info, err := os.Stat(executable)
+	require.NoError(t, err)
+
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	err = rollbackBinary(executable)
+	require.NoError(t, err)
+
+	assertFileContent(t, executable, "#!/bin/sh\necho lego version v4.18.0\n")
+	assertFileContent(t, executable+".old", "#!/bin/sh\necho lego version v4.19.0\n")
+}
+
+func Test_rollbackBinary_noPreviousBinary(t *testing.T) {
+	executable := filepath.Join(t.TempDir(), "lego")
+
+	err := os.WriteFile(executable, []byte("BINARY"), 0o755)
+	require.NoError(t, err)
+
+	err = rollbackBinary(executable)
+	require.ErrorContains(t, err, "no previous binary")
+
+	assertFileContent(t, executable, "BINARY")
+}
+
+func assertFileContent(t *testing.T, filename, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, string(content))
+}
+
+func tarGz(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		require.NoError(t, err)
+
+		_, err = tw.Write(content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
@@ -3,15 +3,24 @@ package cmd
 import (
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pya789/lego/v4/certcrypto"
 	"github.com/pya789/lego/v4/lego"
 	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/platform/ipfamily"
+	"github.com/pya789/lego/v4/platform/outbound"
 	"github.com/pya789/lego/v4/registration"
+	"github.com/pya789/lego/v4/storage"
+	_ "github.com/pya789/lego/v4/storage/s3" // registers the s3:// storage.
 	"github.com/urfave/cli/v2"
 )
 
@@ -25,7 +34,7 @@ func setup(ctx *cli.Context, accountsStorage *AccountsStorage) (*Account, *lego.
 	if accountsStorage.ExistsAccountFilePath() {
 		account = accountsStorage.LoadAccount(privateKey)
 	} else {
-		account = &Account{Email: accountsStorage.GetUserID(), key: privateKey}
+		account = &Account{Email: accountsStorage.GetEmail(), key: privateKey}
 	}
 
 	client := newClient(ctx, account, keyType)
@@ -37,29 +46,137 @@ func newClient(ctx *cli.Context, acc registration.User, keyType certcrypto.KeyTy
 	config := lego.NewConfig(acc)
 	config.CADirURL = ctx.String("server")
 
-	config.Certificate = lego.CertificateConfig{
-		KeyType:             keyType,
-		Timeout:             time.Duration(ctx.Int("cert.timeout")) * time.Second,
-		OverallRequestLimit: ctx.Int("overall-request-limit"),
+	config.Certificate.KeyType = keyType
+
+	// The key type of the account defaults is only used if the flag is not explicitly set.
+	if u, ok := acc.(registration.UserWithDefaults); ok && !ctx.IsSet("key-type") {
+		if defaults := u.GetDefaults(); defaults != nil && defaults.KeyType != "" {
+			config.Certificate.KeyType = defaults.KeyType
+		}
 	}
+
+	config.Certificate.Timeout = time.Duration(ctx.Int("cert.timeout")) * time.Second
+	config.Certificate.OverallRequestLimit = ctx.Int("overall-request-limit")
+	config.Certificate.StrictIDNA = ctx.Bool("idna.strict")
+	config.MaxClockSkew = ctx.Duration("clock-skew.max")
+	config.CompensateClockSkew = ctx.Bool("clock-skew.compensate")
 	config.UserAgent = getUserAgent(ctx)
 
 	if ctx.IsSet("http-timeout") {
 		config.HTTPClient.Timeout = time.Duration(ctx.Int("http-timeout")) * time.Second
 	}
 
+	setupOutbound(ctx, config.HTTPClient)
+	setupIPFamily(getIPFamily(ctx), config.HTTPClient)
+
 	client, err := lego.NewClient(config)
 	if err != nil {
+		var skewErr *lego.ClockSkewError
+		if errors.As(err, &skewErr) {
+			fatalConfigf("Could not create client: %v", err)
+		}
+
 		log.Fatalf("Could not create client: %v", err)
 	}
 
 	if client.GetExternalAccountRequired() && !ctx.IsSet("eab") {
-		log.Fatal("Server requires External Account Binding. Use --eab with --kid and --hmac.")
+		fatalConfig("Server requires External Account Binding. Use --eab with --kid and --hmac.")
 	}
 
 	return client
 }
 
+// getIPFamily the IP family (IPv4/IPv6) of the outgoing connections.
+func getIPFamily(ctx *cli.Context) ipfamily.Family {
+	family, err := ipfamily.Parse(ctx.String("ip-family"))
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	return family
+}
+
+var defaultTransportIPFamily sync.Once
+
+// setupIPFamily applies the IP family to the HTTP client of the ACME server, and to the default HTTP transport
+// (used by most of the DNS providers, and by the HTTP-01 checks).
+func setupIPFamily(family ipfamily.Family, client *http.Client) {
+	if family == ipfamily.Any {
+		return
+	}
+
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		transport.DialContext = family.DialContext(transport.DialContext)
+	}
+
+	defaultTransportIPFamily.Do(func() {
+		if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+			transport.DialContext = family.DialContext(transport.DialContext)
+		}
+	})
+}
+
+var defaultTransportOutbound sync.Once
+
+// setupOutbound applies the source addresses (--bind.*) and the proxies (--proxy.*) of the outgoing connections:
+// the HTTP client of the ACME server uses the "acme" proxy,
+// the default HTTP transport (used by most of the DNS providers, and by the HTTP-01 checks) uses the "providers" proxy.
+// Must be called before setupIPFamily: the IP family selects the destination addresses, then the source address is selected.
+func setupOutbound(ctx *cli.Context, client *http.Client) {
+	bind := getBind(ctx)
+	acmeProxy := getProxy(ctx, "proxy.acme")
+	providersProxy := getProxy(ctx, "proxy.providers")
+
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		applyOutbound(transport, bind, acmeProxy)
+	}
+
+	defaultTransportOutbound.Do(func() {
+		if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+			applyOutbound(transport, bind, providersProxy)
+		}
+	})
+}
+
+func applyOutbound(transport *http.Transport, bind *outbound.Bind, proxy *url.URL) {
+	if bind != nil {
+		// The settings of the dialer of the default HTTP transport.
+		transport.DialContext = bind.DialContext(&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+	}
+
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+}
+
+// getBind the source addresses of the outgoing connections, nil if not defined.
+func getBind(ctx *cli.Context) *outbound.Bind {
+	if !ctx.IsSet("bind.interface") && !ctx.IsSet("bind.address") {
+		return nil
+	}
+
+	bind, err := outbound.NewBind(ctx.String("bind.interface"), ctx.String("bind.address"))
+	if err != nil {
+		fatalConfigf("Invalid --bind.interface or --bind.address: %v", err)
+	}
+
+	return bind
+}
+
+// getProxy the proxy defined by the flag, nil if not defined.
+func getProxy(ctx *cli.Context, flag string) *url.URL {
+	if ctx.String(flag) == "" {
+		return nil
+	}
+
+	proxy, err := outbound.ParseProxy(ctx.String(flag))
+	if err != nil {
+		fatalConfigf("Invalid --%s: %v", flag, err)
+	}
+
+	return proxy
+}
+
 // getKeyType the type from which private keys should be generated.
 func getKeyType(ctx *cli.Context) certcrypto.KeyType {
 	keyType := ctx.String("key-type")
@@ -78,14 +195,14 @@ func getKeyType(ctx *cli.Context) certcrypto.KeyType {
 		return certcrypto.EC384
 	}
 
-	log.Fatalf("Unsupported KeyType: %s", keyType)
+	fatalConfigf("Unsupported KeyType: %s", keyType)
 	return ""
 }
 
 func getEmail(ctx *cli.Context) string {
 	email := ctx.String("email")
 	if email == "" {
-		log.Fatal("You have to pass an account (email address) to the program using --email or -m")
+		fatalConfig("You have to pass an account (email address) to the program using --email or -m, or an account name using --account")
 	}
 	return email
 }
@@ -94,6 +211,29 @@ func getUserAgent(ctx *cli.Context) string {
 	return strings.TrimSpace(fmt.Sprintf("%s lego-cli/%s", ctx.String("user-agent"), ctx.App.Version))
 }
 
+// getStore returns the storage defined by the "storage" option, or the filesystem storage in the "path" directory.
+func getStore(ctx *cli.Context) storage.Store {
+	if !ctx.IsSet("storage") {
+		return storage.NewFileSystem(ctx.String("path"))
+	}
+
+	store, err := storage.Open(ctx.String("storage"))
+	if err != nil {
+		fatalConfig(err)
+	}
+
+	return store
+}
+
+// storagePath returns the path of the file of the key for the filesystem storage, or the key for the other storages.
+func storagePath(store storage.Store, key string) string {
+	if fsStore, ok := store.(*storage.FileSystem); ok {
+		return fsStore.Path(key)
+	}
+
+	return key
+}
+
 func createNonExistingFolder(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return os.MkdirAll(path, 0o700)
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"math"
 	"net"
+	"os"
+	"os/user"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,30 +23,82 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
-func setupChallenges(ctx *cli.Context, client *lego.Client) {
+func setupChallenges(ctx *cli.Context, client *lego.Client, summary *runSummary) {
 	if !ctx.Bool("http") && !ctx.Bool("tls") && !ctx.IsSet("dns") {
-		log.Fatal("No challenge selected. You must specify at least one challenge: `--http`, `--tls`, `--dns`.")
+		fatalConfig("No challenge selected. You must specify at least one challenge: `--http`, `--tls`, `--dns`.")
 	}
 
 	if ctx.Bool("http") {
-		err := client.Challenge.SetHTTP01Provider(setupHTTPProvider(ctx))
+		var opts []http01.ChallengeOption
+		if ctx.IsSet("http.perspective-checker") {
+			opts = append(opts, http01.AddPerspectiveCheckers(nil, ctx.StringSlice("http.perspective-checker")))
+		}
+
+		if ctx.Bool("http.self-check") {
+			opts = append(opts, http01.EnableSelfCheck(nil))
+		}
+
+		if ctx.Bool("http.cdn-detect") {
+			opts = append(opts, http01.DetectCDN(nil))
+		}
+
+		if ctx.IsSet("http.cdn-fallback") {
+			fallback := challenge.Type(ctx.String("http.cdn-fallback"))
+			if fallback != challenge.DNS01 && fallback != challenge.TLSALPN01 {
+				fatalConfigf("Unsupported CDN fallback challenge: %s", fallback)
+			}
+
+			client.Challenge.SetCDNFallback(fallback)
+		}
+
+		err := client.Challenge.SetHTTP01Provider(summary.wrapProvider(setupHTTPProvider(ctx), challenge.HTTP01), opts...)
 		if err != nil {
 			log.Fatal(err)
 		}
 	}
 
 	if ctx.Bool("tls") {
-		err := client.Challenge.SetTLSALPN01Provider(setupTLSProvider(ctx))
+		err := client.Challenge.SetTLSALPN01Provider(summary.wrapProvider(setupTLSProvider(ctx), challenge.TLSALPN01))
 		if err != nil {
 			log.Fatal(err)
 		}
 	}
 
 	if ctx.IsSet("dns") {
-		setupDNS(ctx, client)
+		setupDNS(ctx, client, summary)
+	}
+
+	if ctx.IsSet("challenges.batch-size") {
+		if ctx.Int("challenges.batch-size") < 1 {
+			fatalConfig("--challenges.batch-size must be positive")
+		}
+
+		client.Challenge.SetBatches(ctx.Int("challenges.batch-size"), ctx.Duration("challenges.batch-interval"))
+	}
+
+	if ctx.IsSet("dns.zone-concurrency") {
+		client.Challenge.SetZoneConcurrency(parseZoneConcurrency(ctx.StringSlice("dns.zone-concurrency")))
 	}
 }
 
+// parseZoneConcurrency parses the concurrency limits of the zones (ex: example.com=2, *=1).
+func parseZoneConcurrency(values []string) map[string]int {
+	limits := make(map[string]int)
+
+	for _, value := range values {
+		zone, raw, found := strings.Cut(value, "=")
+
+		limit, err := strconv.Atoi(raw)
+		if !found || zone == "" || err != nil || limit < 1 {
+			fatalConfigf("Invalid --dns.zone-concurrency: %q, expected 'zone=limit' with a positive limit.", value)
+		}
+
+		limits[zone] = limit
+	}
+
+	return limits
+}
+
 //nolint:gocyclo // the complexity is expected.
 func setupHTTPProvider(ctx *cli.Context) challenge.Provider {
 	switch {
@@ -50,6 +107,52 @@ func setupHTTPProvider(ctx *cli.Context) challenge.Provider {
 		if err != nil {
 			log.Fatal(err)
 		}
+
+		for _, value := range ctx.StringSlice("http.webroot-domain") {
+			domain, path, found := strings.Cut(value, "=")
+			if !found || domain == "" || path == "" {
+				fatalConfigf("Invalid --http.webroot-domain: %q, expected 'domain=path'.", value)
+			}
+
+			err = ps.AddDomainPath(domain, path)
+			if err != nil {
+				fatalConfig(err)
+			}
+		}
+
+		ps.SetAlias(ctx.Bool("http.webroot-alias"))
+
+		if ctx.IsSet("http.webroot-self-check") {
+			ps.SetSelfCheck(ctx.String("http.webroot-self-check"))
+		}
+
+		mode, err := strconv.ParseUint(ctx.String("http.webroot-file-mode"), 8, 32)
+		if err != nil {
+			fatalConfigf("Invalid --http.webroot-file-mode: %q, expected an octal mode (ex: 0644).", ctx.String("http.webroot-file-mode"))
+		}
+
+		ps.SetFileMode(os.FileMode(mode))
+
+		if ctx.IsSet("http.webroot-owner") {
+			uid, gid, errO := parseOwner(ctx.String("http.webroot-owner"))
+			if errO != nil {
+				fatalConfigf("Invalid --http.webroot-owner: %v", errO)
+			}
+
+			ps.SetOwner(uid, gid)
+		}
+
+		if ctx.IsSet("http.webroot-gc") {
+			count, errR := ps.RemoveStale(ctx.Duration("http.webroot-gc"))
+			if errR != nil {
+				log.Warnf("webroot: %v", errR)
+			}
+
+			if count > 0 {
+				log.Infof("webroot: %d stale challenge file(s) removed", count)
+			}
+		}
+
 		return ps
 	case ctx.IsSet("http.memcached-host"):
 		ps, err := memcached.NewMemcachedProvider(ctx.StringSlice("http.memcached-host"))
@@ -66,7 +169,7 @@ func setupHTTPProvider(ctx *cli.Context) challenge.Provider {
 	case ctx.IsSet("http.port"):
 		iface := ctx.String("http.port")
 		if !strings.Contains(iface, ":") {
-			log.Fatalf("The --http switch only accepts interface:port or :port for its argument.")
+			fatalConfigf("The --http switch only accepts interface:port or :port for its argument.")
 		}
 
 		host, port, err := net.SplitHostPort(iface)
@@ -86,17 +189,71 @@ func setupHTTPProvider(ctx *cli.Context) challenge.Provider {
 		}
 		return srv
 	default:
-		log.Fatal("Invalid HTTP challenge options.")
+		fatalConfig("Invalid HTTP challenge options.")
 		return nil
 	}
 }
 
+// parseOwner parses an owner like "user:group", "user", or ":group" (names or numeric IDs).
+// The undefined IDs are -1 (not changed).
+func parseOwner(value string) (int, int, error) {
+	userName, groupName, _ := strings.Cut(value, ":")
+	if userName == "" && groupName == "" {
+		return 0, 0, fmt.Errorf("empty owner: %q", value)
+	}
+
+	uid, gid := -1, -1
+
+	if userName != "" {
+		id, err := strconv.Atoi(userName)
+		if err != nil {
+			u, errL := user.Lookup(userName)
+			if errL != nil {
+				return 0, 0, errL
+			}
+
+			id, err = strconv.Atoi(u.Uid)
+			if err != nil {
+				return 0, 0, fmt.Errorf("user %s: the owner is not supported on this platform", userName)
+			}
+		}
+
+		uid = id
+	}
+
+	if groupName != "" {
+		id, err := strconv.Atoi(groupName)
+		if err != nil {
+			g, errL := user.LookupGroup(groupName)
+			if errL != nil {
+				return 0, 0, errL
+			}
+
+			id, err = strconv.Atoi(g.Gid)
+			if err != nil {
+				return 0, 0, fmt.Errorf("group %s: the owner is not supported on this platform", groupName)
+			}
+		}
+
+		gid = id
+	}
+
+	return uid, gid, nil
+}
+
 func setupTLSProvider(ctx *cli.Context) challenge.Provider {
 	switch {
+	case ctx.IsSet("tls.socket"):
+		mode, err := strconv.ParseUint(ctx.String("tls.socket-mode"), 8, 32)
+		if err != nil {
+			fatalConfigf("Invalid --tls.socket-mode: %q, expected an octal mode (ex: 0660).", ctx.String("tls.socket-mode"))
+		}
+
+		return configureTLSServer(ctx, tlsalpn01.NewUnixProviderServer(ctx.String("tls.socket"), os.FileMode(mode)))
 	case ctx.IsSet("tls.port"):
 		iface := ctx.String("tls.port")
 		if !strings.Contains(iface, ":") {
-			log.Fatalf("The --tls switch only accepts interface:port or :port for its argument.")
+			fatalConfigf("The --tls switch only accepts interface:port or :port for its argument.")
 		}
 
 		host, port, err := net.SplitHostPort(iface)
@@ -104,31 +261,163 @@ func setupTLSProvider(ctx *cli.Context) challenge.Provider {
 			log.Fatal(err)
 		}
 
-		return tlsalpn01.NewProviderServer(host, port)
+		return configureTLSServer(ctx, tlsalpn01.NewProviderServer(host, port))
 	case ctx.Bool("tls"):
-		return tlsalpn01.NewProviderServer("", "")
+		return configureTLSServer(ctx, tlsalpn01.NewProviderServer("", ""))
 	default:
-		log.Fatal("Invalid HTTP challenge options.")
+		fatalConfig("Invalid HTTP challenge options.")
 		return nil
 	}
 }
 
-func setupDNS(ctx *cli.Context, client *lego.Client) {
-	provider, err := dns.NewDNSChallengeProviderByName(ctx.String("dns"))
+func configureTLSServer(ctx *cli.Context, srv *tlsalpn01.ProviderServer) *tlsalpn01.ProviderServer {
+	srv.SetReusePort(ctx.Bool("tls.reuse-port"))
+	srv.SetPassthrough(ctx.String("tls.passthrough"))
+	srv.SetProxyProtocol(ctx.Bool("tls.proxy-protocol"))
+
+	if ctx.IsSet("tls.listen-fd") {
+		fd := ctx.Uint("tls.listen-fd")
+
+		listener, err := net.FileListener(os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd)))
+		if err != nil {
+			fatalConfigf("Invalid --tls.listen-fd: %v", err)
+		}
+
+		err = srv.SetListener(listener)
+		if err != nil {
+			fatalConfigf("Invalid --tls.listen-fd: %v", err)
+		}
+	}
+
+	return srv
+}
+
+func setupDNS(ctx *cli.Context, client *lego.Client, summary *runSummary) {
+	provider, err := newDNSProvider(ctx)
 	if err != nil {
-		log.Fatal(err)
+		fatalConfig(err)
+	}
+
+	switch dns01.DNSTransport(ctx.String("dns.transport")) {
+	case dns01.DNSTransportUDP, dns01.DNSTransportTCP, dns01.DNSTransportUDPWithTCPFallback:
+	default:
+		fatalConfigf("Unsupported DNS transport: %s", ctx.String("dns.transport"))
+	}
+
+	if ctx.Int("dns.retries") < 0 {
+		fatalConfig("--dns.retries must be positive")
+	}
+
+	if ctx.Uint("dns.edns0-buffer-size") > math.MaxUint16 {
+		fatalConfigf("--dns.edns0-buffer-size must be lower than %d", math.MaxUint16+1)
+	}
+
+	if ctx.IsSet("dns.propagation-wait") && ctx.Duration("dns.propagation-wait") <= 0 {
+		fatalConfig("--dns.propagation-wait must be positive")
+	}
+
+	aliases := make(map[string]string)
+
+	for _, value := range ctx.StringSlice("dns.challenge-alias") {
+		domain, alias, found := strings.Cut(value, "=")
+		if !found || domain == "" || alias == "" {
+			fatalConfigf("Invalid --dns.challenge-alias: %q, expected 'domain=alias'.", value)
+		}
+
+		aliases[domain] = alias
+	}
+
+	summary.dnsProvider = provider
+
+	if ctx.Bool("dns-plan") {
+		provider = dns01.NewPlanProvider(provider)
 	}
 
 	servers := ctx.StringSlice("dns.resolvers")
-	err = client.Challenge.SetDNS01Provider(provider,
+	err = client.Challenge.SetDNS01Provider(summary.wrapProvider(provider, challenge.DNS01),
 		dns01.CondOption(len(servers) > 0,
 			dns01.AddRecursiveNameservers(dns01.ParseNameservers(ctx.StringSlice("dns.resolvers")))),
+		dns01.CondOption(len(aliases) > 0,
+			dns01.WithChallengeAlias(aliases)),
 		dns01.CondOption(ctx.Bool("dns.disable-cp"),
-			dns01.DisableCompletePropagationRequirement()),
+			dns01.DisableAuthoritativeNssPropagationRequirement()),
+		dns01.CondOption(ctx.Bool("dns.propagation-disable-rns"),
+			dns01.DisableRecursiveNssPropagationRequirement()),
+		dns01.CondOption(ctx.IsSet("dns.propagation-wait"),
+			dns01.PropagationWait(ctx.Duration("dns.propagation-wait"))),
+		dns01.CondOption(ctx.IsSet("dns.propagation-policy") || ctx.IsSet("dns.propagation-ns-timeout"),
+			dns01.WithPropagationPolicy(dns01.PropagationPolicy(ctx.String("dns.propagation-policy")), ctx.Duration("dns.propagation-ns-timeout"))),
+		dns01.CondOption(ctx.Bool("dns.negative-cache-busting"),
+			dns01.EnableNegativeCacheBusting()),
+		dns01.CondOption(ctx.IsSet("dns.perspective-resolvers"),
+			dns01.AddPerspectiveNameservers(ctx.StringSlice("dns.perspective-resolvers"))),
 		dns01.CondOption(ctx.IsSet("dns-timeout"),
 			dns01.AddDNSTimeout(time.Duration(ctx.Int("dns-timeout"))*time.Second)),
+		dns01.CondOption(ctx.IsSet("dns.transport"),
+			dns01.SetDNSTransport(dns01.DNSTransport(ctx.String("dns.transport")))),
+		dns01.CondOption(ctx.IsSet("dns.retries"),
+			dns01.AddDNSRetries(ctx.Int("dns.retries"))),
+		dns01.CondOption(ctx.IsSet("dns.edns0-buffer-size"),
+			dns01.AddEDNS0BufferSize(uint16(ctx.Uint("dns.edns0-buffer-size")))),
+		dns01.SetIPFamily(getIPFamily(ctx)),
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// newDNSProvider creates the DNS provider, the manual provider is configured with the "manual-*" options.
+// The records are removed with other credentials (--dns.cleanup-env-prefix), or later by the gc command (--dns.deferred-cleanup).
+func newDNSProvider(ctx *cli.Context) (challenge.Provider, error) {
+	name := ctx.String("dns")
+
+	if name != "manual" {
+		for _, flag := range []string{"manual-auth-hook", "manual-cleanup-hook", "manual-export"} {
+			if ctx.IsSet(flag) {
+				return nil, fmt.Errorf("--%s requires '--dns manual'", flag)
+			}
+		}
+
+		provider, err := dns.NewDNSChallengeProviderByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return splitDNSProvider(ctx, provider)
+	}
+
+	for _, flag := range []string{"dns.cleanup-env-prefix", "dns.deferred-cleanup"} {
+		if ctx.IsSet(flag) {
+			return nil, fmt.Errorf("--%s is not supported by the manual provider", flag)
+		}
+	}
+
+	return dns01.NewDNSProviderManualConfig(&dns01.ManualConfig{
+		ExportDir:   ctx.String("manual-export"),
+		AuthHook:    ctx.String("manual-auth-hook"),
+		CleanupHook: ctx.String("manual-cleanup-hook"),
+	})
+}
+
+// splitDNSProvider uses another provider to remove the records, if the clean-up is deferred or uses other credentials.
+func splitDNSProvider(ctx *cli.Context, provider challenge.Provider) (challenge.Provider, error) {
+	switch {
+	case ctx.Bool("dns.deferred-cleanup"):
+		journal := newDNSCleanupJournal(ctx)
+
+		log.Infof("The DNS records will be removed by the gc command (journal: %s).", journal.filename)
+
+		return dns01.NewSplitProvider(provider, journal), nil
+
+	case ctx.String("dns.cleanup-env-prefix") != "":
+		cleanUp, err := newDNSCleanupProvider(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clean-up provider: %w", err)
+		}
+
+		return dns01.NewSplitProvider(provider, cleanUp), nil
+
+	default:
+		return provider, nil
+	}
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/metrics"
+	"github.com/urfave/cli/v2"
+)
+
+// Certificate statuses in the summary.
+const (
+	summaryObtained  = "obtained"
+	summaryRenewed   = "renewed"
+	summarySkipped   = "skipped"
+	summaryPostponed = "postponed"
+	summaryFailed    = "failed"
+)
+
+// runSummary a machine-readable summary of a run/renew execution.
+// It is written to the file defined by the "summary-file" option.
+type runSummary struct {
+	Command         string                `json:"command"`
+	StartedAt       time.Time             `json:"startedAt"`
+	DurationSeconds float64               `json:"durationSeconds"`
+	Certificates    []certificateSummary  `json:"certificates,omitempty"`
+	Phases          []phaseSummary        `json:"phases,omitempty"`
+	ProviderCalls   []providerCallSummary `json:"providerCalls,omitempty"`
+	// FailureCauses the number of failures by cause (ex: "DNS problem", "timeout").
+	FailureCauses map[string]int `json:"failureCauses,omitempty"`
+
+	path string
+	mu   sync.Mutex
+
+	// metrics records the calls to the providers in the metrics (see the "metrics.listen" option).
+	metrics bool
+	// types the challenge type used for each domain.
+	types map[string]challenge.Type
+	// dnsProvider the DNS provider (see the "dns" option), nil if the DNS-01 challenge is not used.
+	dnsProvider challenge.Provider
+}
+
+type certificateSummary struct {
+	Domain  string               `json:"domain,omitempty"`
+	Status  string               `json:"status"`
+	CertURL string               `json:"certUrl,omitempty"`
+	Error   string               `json:"error,omitempty"`
+	Problem *acme.ProblemDetails `json:"problem,omitempty"`
+}
+
+type phaseSummary struct {
+	Name            string  `json:"name"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+type providerCallSummary struct {
+	Challenge       string  `json:"challenge"`
+	Action          string  `json:"action"`
+	Domain          string  `json:"domain"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+func newRunSummary(ctx *cli.Context, command string) *runSummary {
+	return &runSummary{
+		Command:   command,
+		StartedAt: time.Now().UTC(),
+		path:      ctx.String("summary-file"),
+		metrics:   ctx.String("metrics.listen") != "",
+	}
+}
+
+// phase records the duration of a phase started at the given time.
+func (s *runSummary) phase(name string, start time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Phases = append(s.Phases, phaseSummary{Name: name, DurationSeconds: time.Since(start).Seconds()})
+}
+
+func (s *runSummary) addCertificate(domain, status, certURL string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cert := certificateSummary{Domain: domain, Status: status, CertURL: certURL}
+
+	if err != nil {
+		cert.Error = err.Error()
+
+		var problem *acme.ProblemDetails
+		if errors.As(err, &problem) {
+			cert.Problem = problem
+		}
+
+		s.FailureCauses = countFailureCauses(s.FailureCauses, getFailureCauses(err))
+	}
+
+	s.Certificates = append(s.Certificates, cert)
+}
+
+func (s *runSummary) addProviderCall(call providerCallSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ProviderCalls = append(s.ProviderCalls, call)
+
+	if call.Action == "present" {
+		if s.types == nil {
+			s.types = map[string]challenge.Type{}
+		}
+
+		s.types[call.Domain] = challenge.Type(call.Challenge)
+	}
+}
+
+// challengeType returns the challenge type used for the domain (empty if the challenge has not been presented).
+func (s *runSummary) challengeType(domain string) challenge.Type {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.types[domain]
+}
+
+// write writes the summary file, if the "summary-file" option is defined.
+func (s *runSummary) write() {
+	if s.path == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.DurationSeconds = time.Since(s.StartedAt).Seconds()
+
+	data, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		log.Warnf("Unable to marshal the summary: %v", err)
+		return
+	}
+
+	err = os.WriteFile(s.path, data, filePerm)
+	if err != nil {
+		log.Warnf("Unable to write the summary file %s: %v", s.path, err)
+	}
+}
+
+// wrapProvider wraps a challenge provider to record the calls to the provider in the summary and the metrics.
+// The optional interfaces (timeout, sequential, propagation waiter) of the provider are preserved.
+func (s *runSummary) wrapProvider(provider challenge.Provider, chlg challenge.Type) challenge.Provider {
+	if s.path == "" && !s.metrics {
+		return provider
+	}
+
+	rp := &recordingProvider{provider: provider, challenge: chlg, summary: s}
+
+	tp, isTimeout := provider.(timeoutProvider)
+	sp, isSequential := provider.(sequentialProvider)
+	wp, isWaiter := provider.(dns01.PropagationWaiter)
+
+	switch {
+	case isTimeout && isSequential && isWaiter:
+		return &struct {
+			*recordingProvider
+			timeoutProvider
+			sequentialProvider
+			dns01.PropagationWaiter
+		}{rp, tp, sp, wp}
+	case isTimeout && isSequential:
+		return &struct {
+			*recordingProvider
+			timeoutProvider
+			sequentialProvider
+		}{rp, tp, sp}
+	case isTimeout && isWaiter:
+		return &struct {
+			*recordingProvider
+			timeoutProvider
+			dns01.PropagationWaiter
+		}{rp, tp, wp}
+	case isSequential && isWaiter:
+		return &struct {
+			*recordingProvider
+			sequentialProvider
+			dns01.PropagationWaiter
+		}{rp, sp, wp}
+	case isTimeout:
+		return &struct {
+			*recordingProvider
+			timeoutProvider
+		}{rp, tp}
+	case isSequential:
+		return &struct {
+			*recordingProvider
+			sequentialProvider
+		}{rp, sp}
+	case isWaiter:
+		return &struct {
+			*recordingProvider
+			dns01.PropagationWaiter
+		}{rp, wp}
+	default:
+		return rp
+	}
+}
+
+type timeoutProvider interface {
+	Timeout() (timeout, interval time.Duration)
+}
+
+type sequentialProvider interface {
+	Sequential() time.Duration
+}
+
+type recordingProvider struct {
+	provider  challenge.Provider
+	challenge challenge.Type
+	summary   *runSummary
+}
+
+func (p *recordingProvider) Present(domain, token, keyAuth string) error {
+	start := time.Now()
+
+	err := p.provider.Present(domain, token, keyAuth)
+
+	p.record("present", domain, start, err)
+
+	return err
+}
+
+func (p *recordingProvider) CleanUp(domain, token, keyAuth string) error {
+	start := time.Now()
+
+	err := p.provider.CleanUp(domain, token, keyAuth)
+
+	p.record("cleanup", domain, start, err)
+
+	return err
+}
+
+// PresentContext passes the context to the wrapped provider (see challenge.ProviderContext).
+func (p *recordingProvider) PresentContext(ctx context.Context, domain, token, keyAuth string) error {
+	start := time.Now()
+
+	err := challenge.Present(ctx, p.provider, domain, token, keyAuth)
+
+	p.record("present", domain, start, err)
+
+	return err
+}
+
+// CleanUpContext passes the context to the wrapped provider (see challenge.ProviderContext).
+func (p *recordingProvider) CleanUpContext(ctx context.Context, domain, token, keyAuth string) error {
+	start := time.Now()
+
+	err := challenge.CleanUp(ctx, p.provider, domain, token, keyAuth)
+
+	p.record("cleanup", domain, start, err)
+
+	return err
+}
+
+// Unwrap returns the wrapped provider.
+func (p *recordingProvider) Unwrap() challenge.Provider {
+	return p.provider
+}
+
+func (p *recordingProvider) record(action, domain string, start time.Time, err error) {
+	duration := time.Since(start)
+
+	call := providerCallSummary{
+		Challenge:       p.challenge.String(),
+		Action:          action,
+		Domain:          domain,
+		DurationSeconds: duration.Seconds(),
+	}
+
+	if err != nil {
+		call.Error = err.Error()
+	}
+
+	p.summary.addProviderCall(call)
+
+	if p.summary.metrics {
+		metrics.ObserveProviderCall(p.challenge, action, duration, err)
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_runSummary_write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+
+	summary := &runSummary{Command: "run", StartedAt: time.Now().UTC(), path: path}
+
+	summary.phase("obtain", time.Now())
+	summary.addCertificate("example.com", summaryFailed, "",
+		fmt.Errorf("wrapped: %w", &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:rateLimited", HTTPStatus: 429}))
+
+	provider := summary.wrapProvider(&fakeProvider{err: errors.New("oops")}, challenge.DNS01)
+
+	err := provider.Present("example.com", "token", "keyAuth")
+	require.Error(t, err)
+
+	summary.write()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var result runSummary
+	err = json.Unmarshal(data, &result)
+	require.NoError(t, err)
+
+	assert.Equal(t, "run", result.Command)
+
+	require.Len(t, result.Phases, 1)
+	assert.Equal(t, "obtain", result.Phases[0].Name)
+
+	require.Len(t, result.Certificates, 1)
+	assert.Equal(t, summaryFailed, result.Certificates[0].Status)
+	require.NotNil(t, result.Certificates[0].Problem)
+	assert.Equal(t, 429, result.Certificates[0].Problem.HTTPStatus)
+
+	assert.Equal(t, map[string]int{causeRateLimited: 1}, result.FailureCauses)
+
+	require.Len(t, result.ProviderCalls, 1)
+	assert.Equal(t, providerCallSummary{
+		Challenge:       "dns-01",
+		Action:          "present",
+		Domain:          "example.com",
+		DurationSeconds: result.ProviderCalls[0].DurationSeconds,
+		Error:           "oops",
+	}, result.ProviderCalls[0])
+}
+
+func Test_runSummary_wrapProvider(t *testing.T) {
+	summary := &runSummary{path: "summary.json"}
+
+	provider := summary.wrapProvider(&fakeTimeoutProvider{}, challenge.DNS01)
+
+	p, ok := provider.(timeoutProvider)
+	require.True(t, ok)
+
+	timeout, interval := p.Timeout()
+	assert.Equal(t, time.Minute, timeout)
+	assert.Equal(t, time.Second, interval)
+
+	_, ok = provider.(sequentialProvider)
+	assert.False(t, ok)
+}
+
+func Test_runSummary_wrapProvider_context(t *testing.T) {
+	summary := &runSummary{path: "summary.json"}
+
+	provider := summary.wrapProvider(&fakeProvider{}, challenge.DNS01)
+
+	p, ok := provider.(challenge.ProviderContext)
+	require.True(t, ok)
+
+	require.NoError(t, p.PresentContext(context.Background(), "example.com", "token", "keyAuth"))
+	require.NoError(t, p.CleanUpContext(context.Background(), "example.com", "token", "keyAuth"))
+
+	require.Len(t, summary.ProviderCalls, 2)
+	assert.Equal(t, "present", summary.ProviderCalls[0].Action)
+	assert.Equal(t, "cleanup", summary.ProviderCalls[1].Action)
+}
+
+func Test_runSummary_wrapProvider_disabled(t *testing.T) {
+	summary := &runSummary{}
+
+	provider := &fakeProvider{}
+
+	assert.Same(t, provider, summary.wrapProvider(provider, challenge.DNS01))
+}
+
+type fakeProvider struct {
+	err error
+}
+
+func (p *fakeProvider) Present(_, _, _ string) error {
+	return p.err
+}
+
+func (p *fakeProvider) CleanUp(_, _, _ string) error {
+	return p.err
+}
+
+type fakeTimeoutProvider struct {
+	fakeProvider
+}
+
+func (p *fakeTimeoutProvider) Timeout() (timeout, interval time.Duration) {
+	return time.Minute, time.Second
+}
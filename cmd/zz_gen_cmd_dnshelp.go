@@ -56,6 +56,7 @@ func allDNSCodes() string {
 		"epik",
 		"exec",
 		"exoscale",
+		"farm",
 		"freemyip",
 		"gandi",
 		"gandiv5",
@@ -115,6 +116,7 @@ func allDNSCodes() string {
 		"rfc2136",
 		"rimuhosting",
 		"route53",
+		"router",
 		"safedns",
 		"sakuracloud",
 		"scaleway",
@@ -1046,16 +1048,36 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "EXOSCALE_API_VERSION":	API version: v2 (default), or v1 (legacy DNS API)`)
 		ew.writeln(`	- "EXOSCALE_API_ZONE":	API zone`)
 		ew.writeln(`	- "EXOSCALE_ENDPOINT":	API endpoint URL`)
 		ew.writeln(`	- "EXOSCALE_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "EXOSCALE_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "EXOSCALE_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "EXOSCALE_TTL":	The TTL of the TXT record used for the DNS challenge`)
+		ew.writeln(`	- "EXOSCALE_ZONE_ID":	UUID of the DNS zone (API v2 only), skips the listing of the zones`)
 
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/exoscale`)
 
+	case "farm":
+		// generated from: providers/dns/farm/farm.toml
+		ew.writeln(`Configuration for Multi-credential farm.`)
+		ew.writeln(`Code:	'farm'`)
+		ew.writeln(`Since:	'v4.18.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "FARM_PROVIDERS":	Comma-separated list of the DNS providers, each one optionally followed by the prefix of its environment variables (ex: cloudflare:CF1_,cloudflare:CF2_)`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "FARM_POLLING_INTERVAL":	Time between DNS propagation check, the shortest interval of the members is used if shorter`)
+		ew.writeln(`	- "FARM_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation, the longest timeout of the members is used if longer`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/farm`)
+
 	case "freemyip":
 		// generated from: providers/dns/freemyip/freemyip.toml
 		ew.writeln(`Configuration for freemyip.com.`)
@@ -1138,6 +1160,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln(`	- "GCE_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "GCE_TTL":	The TTL of the TXT record used for the DNS challenge`)
 		ew.writeln(`	- "GCE_ZONE_ID":	Allows to skip the automatic detection of the zone`)
+		ew.writeln(`	- "GCE_ZONE_VISIBILITY":	Restricts the managed zones to the given visibility: 'public' or 'private' (by default: public zones, and private zones if GCE_ALLOW_PRIVATE_ZONE is enabled)`)
 
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/gcloud`)
@@ -1239,6 +1262,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln(`	- "HETZNER_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "HETZNER_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "HETZNER_TTL":	The TTL of the TXT record used for the DNS challenge`)
+		ew.writeln(`	- "HETZNER_ZONE_ID":	The ID of a zone (skips the lookup of the ID of this zone, the other zones are looked up by name)`)
 
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/hetzner`)
@@ -1319,10 +1343,12 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "HTTPREQ_BULK":	Sends all the records in one request, requires the 'RAW' mode (by default: false)`)
 		ew.writeln(`	- "HTTPREQ_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "HTTPREQ_PASSWORD":	Basic authentication password`)
 		ew.writeln(`	- "HTTPREQ_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "HTTPREQ_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "HTTPREQ_PROPAGATION_URL":	The URL of the propagation status of the records`)
 		ew.writeln(`	- "HTTPREQ_USERNAME":	Basic authentication username`)
 
 		ew.writeln()
@@ -1930,6 +1956,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln(`	- "NETLIFY_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "NETLIFY_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "NETLIFY_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "NETLIFY_SKIP_DNS_CHECK":	Skip the check of the nameservers of the zone (the domain must use Netlify DNS, not only Netlify hosting) (Default: false)`)
 		ew.writeln(`	- "NETLIFY_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
 		ew.writeln()
@@ -2130,6 +2157,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "PDNS_API_VERSION":	Skip API version autodetection and use the provided version number.`)
+		ew.writeln(`	- "PDNS_CA_CERTIFICATES":	Paths of the PEM certificates of the CAs trusted for the API (in addition to the system roots), separated by the OS path list separator`)
 		ew.writeln(`	- "PDNS_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "PDNS_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "PDNS_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
@@ -2153,6 +2181,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "PLESK_CA_CERTIFICATES":	Paths of the PEM certificates of the CAs trusted for the API (in addition to the system roots), separated by the OS path list separator`)
 		ew.writeln(`	- "PLESK_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "PLESK_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "PLESK_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
@@ -2175,6 +2204,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "PORKBUN_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "PORKBUN_MAX_RETRIES":	The maximum number of retries of the API calls rejected during the propagation of a change (Default: 5)`)
 		ew.writeln(`	- "PORKBUN_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "PORKBUN_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "PORKBUN_TTL":	The TTL of the TXT record used for the DNS challenge`)
@@ -2231,16 +2261,17 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 
 		ew.writeln(`Credentials:`)
-		ew.writeln(`	- "REGRU_PASSWORD":	API password`)
+		ew.writeln(`	- "REGRU_PASSWORD":	API password (optional with the authentication by a TLS client certificate)`)
 		ew.writeln(`	- "REGRU_USERNAME":	API username`)
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "REGRU_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "REGRU_MAX_RETRIES":	The maximum number of retries of the API calls rejected because of the rate limits (Default: 5)`)
 		ew.writeln(`	- "REGRU_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "REGRU_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
-		ew.writeln(`	- "REGRU_TLS_CERT":	authentication certificate`)
-		ew.writeln(`	- "REGRU_TLS_KEY":	authentication private key`)
+		ew.writeln(`	- "REGRU_TLS_CERT":	Client certificate (PEM) of the API authentication by certificate, replaces the password`)
+		ew.writeln(`	- "REGRU_TLS_KEY":	Private key (PEM) of the client certificate`)
 		ew.writeln(`	- "REGRU_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
 		ew.writeln()
@@ -2319,6 +2350,24 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/route53`)
 
+	case "router":
+		// generated from: providers/dns/router/router.toml
+		ew.writeln(`Configuration for Multi-provider router.`)
+		ew.writeln(`Code:	'router'`)
+		ew.writeln(`Since:	'v4.18.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "ROUTER_CONFIG_FILE":	Path to the YAML file of the routes`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "ROUTER_POLLING_INTERVAL":	Time between DNS propagation check, the shortest interval of the routed providers is used if shorter`)
+		ew.writeln(`	- "ROUTER_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation, the longest timeout of the routed providers is used if longer`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/router`)
+
 	case "safedns":
 		// generated from: providers/dns/safedns/safedns.toml
 		ew.writeln(`Configuration for UKFast SafeDNS.`)
@@ -2368,7 +2417,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln()
 
 		ew.writeln(`Credentials:`)
-		ew.writeln(`	- "SCW_PROJECT_ID":	Project to use (optional)`)
+		ew.writeln(`	- "SCW_PROJECT_ID":	Project of the DNS zones, required with a project-scoped API key (optional, default: SCW_DEFAULT_PROJECT_ID)`)
 		ew.writeln(`	- "SCW_SECRET_KEY":	Secret key`)
 		ew.writeln()
 
@@ -2377,6 +2426,7 @@ func displayDNSHelp(w io.Writer, name string) error {
 		ew.writeln(`	- "SCW_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "SCW_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "SCW_TTL":	The TTL of the TXT record used for the DNS challenge`)
+		ew.writeln(`	- "SCW_WAIT_ZONE_UPDATE":	Wait for the changes to be applied on the Scaleway nameservers (status of the zone), before the propagation check (Default: true)`)
 
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/scaleway`)
@@ -0,0 +1,306 @@
+// Package delegation helps to issue certificates for the domains of customers (ex: SaaS platforms),
+// by delegating their DNS-01 challenges to a zone managed by the platform.
+//
+// Each customer creates a CNAME record from the challenge record of its domain (_acme-challenge.<domain>)
+// to a target in the delegated zone, the TXT records are then created in the delegated zone by the DNS provider of the platform.
+//
+//	d, err := delegation.New("acme.platform.example")
+//
+//	// the instructions for the customer.
+//	instruction := d.Instruction("customer.example.org")
+//	fmt.Println(instruction.Record())
+//
+//	// before the issuance.
+//	err = d.Ready(ctx, "customer.example.org", "*.customer.example.org")
+//
+//	err = client.Challenge.SetDNS01Provider(d.Provider(platformProvider))
+package delegation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pya789/lego/v4/challenge/dns01"
+)
+
+// DefaultTTL the TTL of the CNAME records in the instructions.
+const DefaultTTL = 3600
+
+// The statuses of the delegation of a domain.
+const (
+	// StatusDelegated the CNAME record targets the expected target.
+	StatusDelegated = "delegated"
+	// StatusMissing the CNAME record doesn't exist.
+	StatusMissing = "missing"
+	// StatusMismatch the CNAME record targets another target.
+	StatusMismatch = "mismatch"
+)
+
+// TargetFunc returns the label of the target of a domain, in the delegated zone.
+type TargetFunc func(domain string) string
+
+// Option configures a Delegator.
+type Option func(d *Delegator)
+
+// WithNameservers defines the recursive nameservers used to check the delegations (ex: 8.8.8.8:53).
+func WithNameservers(nameservers ...string) Option {
+	return func(d *Delegator) {
+		d.nameservers = dns01.ParseNameservers(nameservers)
+	}
+}
+
+// WithTarget defines the label of the target of a domain (default: a hash of the domain).
+// The label must be unique per domain.
+func WithTarget(fn TargetFunc) Option {
+	return func(d *Delegator) {
+		d.target = fn
+	}
+}
+
+// WithTTL defines the TTL of the CNAME records in the instructions.
+func WithTTL(ttl int) Option {
+	return func(d *Delegator) {
+		d.ttl = ttl
+	}
+}
+
+// Delegator generates the delegation instructions of the domains of customers, and checks the delegations.
+type Delegator struct {
+	zone        string
+	nameservers []string
+	target      TargetFunc
+	ttl         int
+	timeout     time.Duration
+}
+
+// New creates a Delegator for the delegated zone (ex: acme.platform.example).
+func New(zone string, opts ...Option) (*Delegator, error) {
+	zone = strings.ToLower(strings.TrimSpace(zone))
+	if zone == "" || zone == "." {
+		return nil, errors.New("delegation: missing zone")
+	}
+
+	d := &Delegator{
+		zone:        dns01.ToFqdn(zone),
+		nameservers: defaultNameservers(),
+		target:      hashTarget,
+		ttl:         DefaultTTL,
+		timeout:     10 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d, nil
+}
+
+// Instruction the CNAME record to create by the customer.
+type Instruction struct {
+	// Domain the domain of the customer (the wildcard domains share the instruction of their base domain).
+	Domain string `json:"domain"`
+	// Name the FQDN of the CNAME record (ex: _acme-challenge.customer.example.org.).
+	Name string `json:"name"`
+	// Target the FQDN targeted by the CNAME record, in the delegated zone.
+	Target string `json:"target"`
+	// TTL the suggested TTL of the record.
+	TTL int `json:"ttl"`
+}
+
+// Record returns the CNAME record in the zone file format.
+func (i Instruction) Record() string {
+	return fmt.Sprintf("%s %d IN CNAME %s", i.Name, i.TTL, i.Target)
+}
+
+// String returns the instruction as a sentence.
+func (i Instruction) String() string {
+	return fmt.Sprintf("create the DNS record %s CNAME %s", i.Name, i.Target)
+}
+
+// Status the status of the delegation of a domain.
+type Status struct {
+	Instruction
+
+	// Status the status of the delegation (delegated, missing, mismatch).
+	Status string `json:"status"`
+	// Current the target of the existing CNAME record (mismatch only).
+	Current string `json:"current,omitempty"`
+}
+
+// Instruction returns the delegation instruction of a domain.
+func (d *Delegator) Instruction(domain string) Instruction {
+	domain = baseDomain(domain)
+
+	return Instruction{
+		Domain: domain,
+		Name:   dns01.ToFqdn("_acme-challenge." + domain),
+		Target: dns01.ToFqdn(d.target(domain) + "." + d.zone),
+		TTL:    d.ttl,
+	}
+}
+
+// Check checks the CNAME record of a domain.
+func (d *Delegator) Check(ctx context.Context, domain string) (*Status, error) {
+	instruction := d.Instruction(domain)
+
+	status := &Status{Instruction: instruction, Status: StatusMissing}
+
+	current, err := d.lookupCNAME(ctx, instruction.Name)
+	if err != nil {
+		return nil, fmt.Errorf("delegation: %s: %w", instruction.Domain, err)
+	}
+
+	switch {
+	case current == "":
+		status.Status = StatusMissing
+	case strings.EqualFold(current, instruction.Target):
+		status.Status = StatusDelegated
+	default:
+		status.Status = StatusMismatch
+		status.Current = current
+	}
+
+	return status, nil
+}
+
+// Ready checks that the domains are delegated, before the issuance of a certificate.
+// Returns a *NotReadyError containing the statuses of the domains not delegated.
+func (d *Delegator) Ready(ctx context.Context, domains ...string) error {
+	notReady := &NotReadyError{}
+
+	seen := make(map[string]struct{})
+
+	for _, domain := range domains {
+		base := baseDomain(domain)
+		if _, ok := seen[base]; ok {
+			continue
+		}
+
+		seen[base] = struct{}{}
+
+		status, err := d.Check(ctx, base)
+		if err != nil {
+			return err
+		}
+
+		if status.Status != StatusDelegated {
+			notReady.Statuses = append(notReady.Statuses, *status)
+		}
+	}
+
+	if len(notReady.Statuses) > 0 {
+		return notReady
+	}
+
+	return nil
+}
+
+// lookupCNAME returns the target of the CNAME record of the FQDN, following the chains of CNAME records.
+// Returns an empty string if the FQDN has no CNAME record.
+func (d *Delegator) lookupCNAME(ctx context.Context, fqdn string) (string, error) {
+	var target string
+
+	// the chains are limited, to avoid the loops.
+	for range 10 {
+		next, err := d.queryCNAME(ctx, fqdn)
+		if err != nil {
+			return "", err
+		}
+
+		if next == "" {
+			break
+		}
+
+		target = next
+		fqdn = next
+
+		// the target is in the delegated zone: the chain ends in the zone of the platform.
+		if dns.IsSubDomain(d.zone, strings.ToLower(target)) {
+			break
+		}
+	}
+
+	return strings.ToLower(target), nil
+}
+
+func (d *Delegator) queryCNAME(ctx context.Context, fqdn string) (string, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(fqdn, dns.TypeCNAME)
+	m.RecursionDesired = true
+
+	client := &dns.Client{Timeout: d.timeout}
+
+	var errAll error
+
+	for _, ns := range d.nameservers {
+		r, _, err := client.ExchangeContext(ctx, m, ns)
+		if err != nil {
+			errAll = errors.Join(errAll, err)
+			continue
+		}
+
+		if r.Rcode != dns.RcodeSuccess && r.Rcode != dns.RcodeNameError {
+			errAll = errors.Join(errAll, fmt.Errorf("%s: unexpected response code: %s", ns, dns.RcodeToString[r.Rcode]))
+			continue
+		}
+
+		for _, rr := range r.Answer {
+			if cn, ok := rr.(*dns.CNAME); ok && strings.EqualFold(cn.Hdr.Name, fqdn) {
+				return cn.Target, nil
+			}
+		}
+
+		return "", nil
+	}
+
+	return "", fmt.Errorf("query CNAME %s: %w", fqdn, errAll)
+}
+
+// NotReadyError the domains not delegated.
+type NotReadyError struct {
+	Statuses []Status
+}
+
+func (e *NotReadyError) Error() string {
+	var parts []string
+
+	for _, status := range e.Statuses {
+		msg := fmt.Sprintf("%s: %s (%s)", status.Domain, status.Status, status.Instruction)
+		if status.Current != "" {
+			msg = fmt.Sprintf("%s: %s to %s (%s)", status.Domain, status.Status, status.Current, status.Instruction)
+		}
+
+		parts = append(parts, msg)
+	}
+
+	return "delegation: domains not delegated: " + strings.Join(parts, ", ")
+}
+
+// hashTarget returns a label derived from the domain: unique, stable, and with a valid length.
+func hashTarget(domain string) string {
+	sum := sha256.Sum256([]byte(domain))
+
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:20]))
+}
+
+// baseDomain returns the domain in lower case, without the wildcard and the trailing dot.
+func baseDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	return dns01.UnFqdn(strings.TrimPrefix(domain, "*."))
+}
+
+func defaultNameservers() []string {
+	config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil || len(config.Servers) == 0 {
+		return []string{"8.8.8.8:53", "1.1.1.1:53"}
+	}
+
+	return dns01.ParseNameservers(config.Servers)
+}
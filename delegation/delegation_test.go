@@ -0,0 +1,176 @@
+package delegation
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDNSServer starts a DNS server answering the CNAME records (name -> target).
+func setupDNSServer(t *testing.T, records map[string]string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(req)
+
+			q := req.Question[0]
+
+			target, ok := records[q.Name]
+			if !ok {
+				m.Rcode = dns.RcodeNameError
+			} else if q.Qtype == dns.TypeCNAME {
+				m.Answer = append(m.Answer, &dns.CNAME{
+					Hdr:    dns.RR_Header{Name: q.Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+					Target: target,
+				})
+			}
+
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return conn.LocalAddr().String()
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(" ")
+	require.EqualError(t, err, "delegation: missing zone")
+}
+
+func TestDelegator_Instruction(t *testing.T) {
+	t.Parallel()
+
+	d, err := New("ACME.platform.example", WithTarget(func(domain string) string {
+		return "c-" + dns01.UnFqdn(domain)[:8]
+	}))
+	require.NoError(t, err)
+
+	instruction := d.Instruction("*.Customer.example.org.")
+
+	expected := Instruction{
+		Domain: "customer.example.org",
+		Name:   "_acme-challenge.customer.example.org.",
+		Target: "c-customer.acme.platform.example.",
+		TTL:    DefaultTTL,
+	}
+
+	assert.Equal(t, expected, instruction)
+	assert.Equal(t, "_acme-challenge.customer.example.org. 3600 IN CNAME c-customer.acme.platform.example.", instruction.Record())
+}
+
+func TestDelegator_Instruction_hash(t *testing.T) {
+	t.Parallel()
+
+	d, err := New("acme.platform.example")
+	require.NoError(t, err)
+
+	a := d.Instruction("a.example.org")
+	b := d.Instruction("b.example.org")
+
+	assert.NotEqual(t, a.Target, b.Target)
+	assert.Equal(t, a, d.Instruction("*.a.example.org"))
+	assert.Len(t, dns.SplitDomainName(a.Target)[0], 32)
+}
+
+func TestDelegator_Check(t *testing.T) {
+	t.Parallel()
+
+	d, err := New("acme.platform.example")
+	require.NoError(t, err)
+
+	delegated := d.Instruction("delegated.example.org")
+	chained := d.Instruction("chained.example.org")
+
+	addr := setupDNSServer(t, map[string]string{
+		delegated.Name:                   delegated.Target,
+		"_acme-challenge.other.example.": "other.example.net.",
+		chained.Name:                     "intermediate.example.net.",
+		"intermediate.example.net.":      chained.Target,
+	})
+
+	d.nameservers = []string{addr}
+
+	testCases := []struct {
+		desc            string
+		domain          string
+		expectedStatus  string
+		expectedCurrent string
+	}{
+		{
+			desc:           "delegated",
+			domain:         "delegated.example.org",
+			expectedStatus: StatusDelegated,
+		},
+		{
+			desc:           "delegated through a chain",
+			domain:         "chained.example.org",
+			expectedStatus: StatusDelegated,
+		},
+		{
+			desc:           "missing",
+			domain:         "missing.example.org",
+			expectedStatus: StatusMissing,
+		},
+		{
+			desc:            "mismatch",
+			domain:          "other.example",
+			expectedStatus:  StatusMismatch,
+			expectedCurrent: "other.example.net.",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			status, err := d.Check(context.Background(), test.domain)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, status.Status)
+			assert.Equal(t, test.expectedCurrent, status.Current)
+		})
+	}
+}
+
+func TestDelegator_Ready(t *testing.T) {
+	t.Parallel()
+
+	d, err := New("acme.platform.example", WithTarget(func(domain string) string {
+		return dns.SplitDomainName(domain)[0]
+	}))
+	require.NoError(t, err)
+
+	addr := setupDNSServer(t, map[string]string{
+		"_acme-challenge.a.example.org.": "a.acme.platform.example.",
+	})
+
+	d.nameservers = []string{addr}
+
+	err = d.Ready(context.Background(), "a.example.org", "*.a.example.org")
+	require.NoError(t, err)
+
+	err = d.Ready(context.Background(), "a.example.org", "b.example.org", "*.b.example.org")
+	require.EqualError(t, err, "delegation: domains not delegated: b.example.org: missing"+
+		" (create the DNS record _acme-challenge.b.example.org. CNAME b.acme.platform.example.)")
+
+	var notReady *NotReadyError
+	require.ErrorAs(t, err, &notReady)
+	require.Len(t, notReady.Statuses, 1)
+	assert.Equal(t, "b.example.org", notReady.Statuses[0].Domain)
+}
@@ -0,0 +1,79 @@
+package delegation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+)
+
+// Provider returns a DNS-01 challenge provider creating the TXT records in the delegated zone, with the DNS provider of the platform.
+// The challenge record of a domain must be delegated to its target (see Instruction):
+// the CNAME record is followed by the DNS-01 challenge (the CNAME support must not be disabled).
+func (d *Delegator) Provider(provider challenge.Provider) *DNSProvider {
+	return &DNSProvider{delegator: d, provider: provider}
+}
+
+// DNSProvider a DNS-01 challenge provider solving the challenges of the delegated domains.
+type DNSProvider struct {
+	delegator *Delegator
+	provider  challenge.Provider
+}
+
+// Present creates the TXT record in the delegated zone.
+func (p *DNSProvider) Present(domain, token, keyAuth string) error {
+	return p.PresentContext(context.Background(), domain, token, keyAuth)
+}
+
+// PresentContext creates the TXT record in the delegated zone,
+// if the challenge record of the domain is delegated to its target.
+func (p *DNSProvider) PresentContext(ctx context.Context, domain, token, keyAuth string) error {
+	err := p.checkTarget(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	return challenge.Present(ctx, p.provider, domain, token, keyAuth)
+}
+
+// CleanUp removes the TXT record from the delegated zone.
+func (p *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	return p.CleanUpContext(context.Background(), domain, token, keyAuth)
+}
+
+// CleanUpContext removes the TXT record from the delegated zone.
+func (p *DNSProvider) CleanUpContext(ctx context.Context, domain, token, keyAuth string) error {
+	err := p.checkTarget(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	return challenge.CleanUp(ctx, p.provider, domain, token, keyAuth)
+}
+
+// Timeout returns the timeout and interval of the DNS provider of the platform.
+func (p *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	if provider, ok := p.provider.(challenge.ProviderTimeout); ok {
+		return provider.Timeout()
+	}
+
+	return dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+}
+
+// checkTarget checks that the effective FQDN of the challenge (after the resolution of the CNAME records) is the target of the domain:
+// the records of the customers are only created in the delegated zone.
+func (p *DNSProvider) checkTarget(domain, keyAuth string) error {
+	instruction := p.delegator.Instruction(domain)
+
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	if !strings.EqualFold(info.EffectiveFQDN, instruction.Target) {
+		return fmt.Errorf("delegation: %s: the challenge record is not delegated (%s): %s resolves to %s",
+			instruction.Domain, instruction, instruction.Name, info.EffectiveFQDN)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package delegation
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockProvider struct {
+	presented []string
+	cleaned   []string
+}
+
+func (p *mockProvider) Present(domain, _, keyAuth string) error {
+	p.presented = append(p.presented, dns01.GetChallengeInfo(domain, keyAuth).EffectiveFQDN)
+	return nil
+}
+
+func (p *mockProvider) CleanUp(domain, _, keyAuth string) error {
+	p.cleaned = append(p.cleaned, dns01.GetChallengeInfo(domain, keyAuth).EffectiveFQDN)
+	return nil
+}
+
+func TestDNSProvider(t *testing.T) {
+	d, err := New("acme.platform.example", WithTarget(func(domain string) string {
+		return dns.SplitDomainName(domain)[0]
+	}))
+	require.NoError(t, err)
+
+	addr := setupDNSServer(t, map[string]string{
+		"_acme-challenge.a.example.org.": "a.acme.platform.example.",
+		"_acme-challenge.b.example.org.": "a.acme.platform.example.",
+	})
+
+	require.NoError(t, dns01.AddRecursiveNameservers([]string{addr})(&dns01.Challenge{}))
+
+	mock := &mockProvider{}
+
+	provider := d.Provider(mock)
+
+	require.NoError(t, provider.Present("a.example.org", "token", "keyAuth"))
+	require.NoError(t, provider.CleanUp("a.example.org", "token", "keyAuth"))
+
+	// the challenge record of b.example.org targets the record of a.example.org.
+	err = provider.Present("b.example.org", "token", "keyAuth")
+	require.EqualError(t, err, "delegation: b.example.org: the challenge record is not delegated"+
+		" (create the DNS record _acme-challenge.b.example.org. CNAME b.acme.platform.example.):"+
+		" _acme-challenge.b.example.org. resolves to a.acme.platform.example.")
+
+	err = provider.Present("c.example.org", "token", "keyAuth")
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"a.acme.platform.example."}, mock.presented)
+	assert.Equal(t, []string{"a.acme.platform.example."}, mock.cleaned)
+}
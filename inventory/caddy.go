@@ -0,0 +1,169 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CaddySource reads the domains from a Caddy configuration (Caddyfile or JSON).
+// Each site block of a Caddyfile (or each route of a JSON configuration) is a certificate.
+type CaddySource struct {
+	path string
+}
+
+// NewCaddySource creates a CaddySource.
+func NewCaddySource(path string) (*CaddySource, error) {
+	if path == "" {
+		return nil, errors.New("inventory: caddy: missing path")
+	}
+
+	return &CaddySource{path: path}, nil
+}
+
+// Entries implements Source.
+func (s *CaddySource) Entries(_ context.Context) ([]Entry, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: caddy: %w", err)
+	}
+
+	var entries []Entry
+
+	if strings.EqualFold(filepath.Ext(s.path), ".json") {
+		entries, err = parseCaddyJSON(data)
+	} else {
+		entries, err = parseCaddyfile(data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("inventory: caddy: %w", err)
+	}
+
+	return entries, nil
+}
+
+// parseCaddyfile extracts the addresses of the site blocks.
+// The global options block, the snippets, and the addresses without a host are ignored.
+func parseCaddyfile(data []byte) ([]Entry, error) {
+	var entries []Entry
+
+	var depth int
+
+	var addresses []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		if depth == 0 && !strings.HasPrefix(line, "(") && !strings.HasPrefix(line, "import ") {
+			fields := splitDomains(strings.TrimSuffix(line, "{"))
+			for _, field := range fields {
+				if host := caddyHost(field); host != "" {
+					addresses = append(addresses, host)
+				}
+			}
+
+			// The addresses of a site block can be defined on several lines.
+			if !strings.HasSuffix(line, ",") {
+				if len(addresses) > 0 {
+					entries = append(entries, Entry{Source: "caddy", Name: addresses[0], Domains: addresses})
+				}
+
+				addresses = nil
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth < 0 {
+			return nil, errors.New("unbalanced braces")
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// caddyHost extracts the host of a site address (`[scheme://]host[:port][/path]`).
+func caddyHost(address string) string {
+	if strings.ContainsAny(address, "{}") {
+		return ""
+	}
+
+	if _, after, ok := strings.Cut(address, "://"); ok {
+		address = after
+	}
+
+	address, _, _ = strings.Cut(address, "/")
+
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+
+	if address == "" || address == "localhost" {
+		return ""
+	}
+
+	return address
+}
+
+type caddyConfig struct {
+	Apps struct {
+		HTTP struct {
+			Servers map[string]struct {
+				Routes []struct {
+					Match []struct {
+						Host []string `json:"host"`
+					} `json:"match"`
+				} `json:"routes"`
+			} `json:"servers"`
+		} `json:"http"`
+	} `json:"apps"`
+}
+
+func parseCaddyJSON(data []byte) ([]Entry, error) {
+	var cfg caddyConfig
+
+	err := json.Unmarshal(data, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(cfg.Apps.HTTP.Servers))
+	for name := range cfg.Apps.HTTP.Servers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var entries []Entry
+
+	for _, name := range names {
+		for _, route := range cfg.Apps.HTTP.Servers[name].Routes {
+			var hosts []string
+			for _, match := range route.Match {
+				hosts = append(hosts, match.Host...)
+			}
+
+			if len(hosts) == 0 {
+				continue
+			}
+
+			entries = append(entries, Entry{Source: "caddy", Name: name, Domains: hosts})
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,94 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConsulTag the prefix of the Consul service tags defining the domains of a certificate.
+const ConsulTag = "lego.domains="
+
+// ConsulSource reads the domains from the tags of the services of the Consul catalog.
+// Each tag `lego.domains=<domains>` is a certificate (the domains are separated by commas).
+type ConsulSource struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+}
+
+// NewConsulSource creates a ConsulSource.
+// The address defaults to the CONSUL_HTTP_ADDR environment variable, the token is read from CONSUL_HTTP_TOKEN.
+func NewConsulSource(address string) (*ConsulSource, error) {
+	if address == "" {
+		address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+
+	if address == "" {
+		return nil, errors.New("inventory: consul: missing address")
+	}
+
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	baseURL, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: consul: %w", err)
+	}
+
+	return &ConsulSource{
+		baseURL:    baseURL,
+		token:      os.Getenv("CONSUL_HTTP_TOKEN"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Entries implements Source.
+func (s *ConsulSource) Entries(ctx context.Context) ([]Entry, error) {
+	endpoint := s.baseURL.JoinPath("v1", "catalog", "services")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: consul: %w", err)
+	}
+
+	if s.token != "" {
+		req.Header.Set("X-Consul-Token", s.token)
+	}
+
+	services := map[string][]string{}
+
+	err = getJSON(s.httpClient, req, &services)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: consul: %w", err)
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var entries []Entry
+
+	for _, name := range names {
+		for _, tag := range services[name] {
+			value, ok := strings.CutPrefix(tag, ConsulTag)
+			if !ok {
+				continue
+			}
+
+			entries = append(entries, Entry{Source: "consul", Name: name, Domains: splitDomains(value)})
+		}
+	}
+
+	return entries, nil
+}
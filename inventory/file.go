@@ -0,0 +1,83 @@
+package inventory
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileSource reads the domains from files.
+// Each line of a file is a certificate: the domains are separated by commas or spaces.
+// The empty lines and the lines starting with `#` are ignored.
+type FileSource struct {
+	pattern string
+}
+
+// NewFileSource creates a FileSource for the files matching a glob pattern.
+func NewFileSource(pattern string) (*FileSource, error) {
+	if pattern == "" {
+		return nil, errors.New("inventory: file: missing pattern")
+	}
+
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("inventory: file: %w", err)
+	}
+
+	return &FileSource{pattern: pattern}, nil
+}
+
+// Entries implements Source.
+func (s *FileSource) Entries(_ context.Context) ([]Entry, error) {
+	matches, err := filepath.Glob(s.pattern)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: file: %w", err)
+	}
+
+	var entries []Entry
+
+	for _, match := range matches {
+		fileEntries, err := readFile(match)
+		if err != nil {
+			return nil, fmt.Errorf("inventory: file: %w", err)
+		}
+
+		entries = append(entries, fileEntries...)
+	}
+
+	return entries, nil
+}
+
+func readFile(filename string) ([]Entry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	var entries []Entry
+
+	scanner := bufio.NewScanner(file)
+
+	var number int
+	for scanner.Scan() {
+		number++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entries = append(entries, Entry{
+			Source:  "file",
+			Name:    fmt.Sprintf("%s:%d", filename, number),
+			Domains: splitDomains(line),
+		})
+	}
+
+	return entries, scanner.Err()
+}
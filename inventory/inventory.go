@@ -0,0 +1,124 @@
+// Package inventory reads the domains to manage from external sources (files, Kubernetes, Traefik, Caddy, Consul).
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Entry is a set of domains for one certificate.
+type Entry struct {
+	// Source the name of the source (ex: "file", "kubernetes").
+	Source string `json:"source"`
+	// Name identifies the entry inside the source (ex: a file path, an Ingress name).
+	Name string `json:"name"`
+	// Domains the domains of the certificate, the first domain is the main domain.
+	Domains []string `json:"domains"`
+}
+
+// Source provides the domains to manage.
+type Source interface {
+	Entries(ctx context.Context) ([]Entry, error)
+}
+
+// NewSource creates a source from a specification: `<kind>[:<value>]`.
+//
+// Supported kinds:
+//   - `file:<glob>`: files containing one certificate by line (domains separated by commas or spaces).
+//   - `kubernetes[:<namespace>]`: Ingress objects (in-cluster configuration).
+//   - `traefik:<glob>`: Traefik dynamic configuration files (YAML or TOML).
+//   - `caddy:<path>`: Caddyfile or Caddy JSON configuration.
+//   - `consul:<address>`: Consul catalog services tagged with `lego.domains=<domains>`.
+func NewSource(spec string) (Source, error) {
+	kind, value, _ := strings.Cut(spec, ":")
+
+	switch kind {
+	case "file":
+		return NewFileSource(value)
+	case "kubernetes", "k8s":
+		return NewIngressSource(value)
+	case "traefik":
+		return NewTraefikSource(value)
+	case "caddy":
+		return NewCaddySource(value)
+	case "consul":
+		return NewConsulSource(value)
+	default:
+		return nil, fmt.Errorf("inventory: unsupported source %q", kind)
+	}
+}
+
+// Collect reads the entries of all the sources.
+// The domains are normalized and the entries with the same domains are merged.
+// The entries of the sources without error are returned even if some sources fail.
+func Collect(ctx context.Context, sources ...Source) ([]Entry, error) {
+	var errs []error
+
+	var entries []Entry
+
+	seen := map[string]struct{}{}
+
+	for _, src := range sources {
+		srcEntries, err := src.Entries(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, entry := range srcEntries {
+			entry.Domains = normalize(entry.Domains)
+			if len(entry.Domains) == 0 {
+				continue
+			}
+
+			key := entryKey(entry.Domains)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Domains[0] < entries[j].Domains[0]
+	})
+
+	return entries, errors.Join(errs...)
+}
+
+func normalize(domains []string) []string {
+	var result []string
+
+	for _, domain := range domains {
+		domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain == "" || slices.Contains(result, domain) {
+			continue
+		}
+
+		result = append(result, domain)
+	}
+
+	return result
+}
+
+// entryKey the key used to merge the entries: the main domain and the sorted SANs.
+func entryKey(domains []string) string {
+	sans := slices.Clone(domains[1:])
+	sort.Strings(sans)
+
+	return domains[0] + "|" + strings.Join(sans, ",")
+}
+
+// splitDomains splits a list of domains separated by commas or spaces.
+func splitDomains(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
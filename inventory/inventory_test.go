@@ -0,0 +1,182 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sourceMock struct {
+	entries []Entry
+	err     error
+}
+
+func (s sourceMock) Entries(_ context.Context) ([]Entry, error) {
+	return s.entries, s.err
+}
+
+func TestCollect(t *testing.T) {
+	sources := []Source{
+		sourceMock{entries: []Entry{
+			{Source: "a", Name: "1", Domains: []string{"www.example.com", "Example.com."}},
+			{Source: "a", Name: "2", Domains: []string{"example.org", "", "example.org"}},
+			{Source: "a", Name: "3", Domains: []string{""}},
+		}},
+		sourceMock{err: errors.New("boom")},
+		sourceMock{entries: []Entry{
+			{Source: "b", Name: "1", Domains: []string{"www.example.com", "example.com"}},
+			{Source: "b", Name: "2", Domains: []string{"example.com", "www.example.com"}},
+		}},
+	}
+
+	entries, err := Collect(context.Background(), sources...)
+	require.EqualError(t, err, "boom")
+
+	expected := []Entry{
+		{Source: "b", Name: "2", Domains: []string{"example.com", "www.example.com"}},
+		{Source: "a", Name: "2", Domains: []string{"example.org"}},
+		{Source: "a", Name: "1", Domains: []string{"www.example.com", "example.com"}},
+	}
+
+	assert.Equal(t, expected, entries)
+}
+
+func TestNewSource(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		spec     string
+		expected Source
+	}{
+		{
+			desc:     "file",
+			spec:     "file:fixtures/*.txt",
+			expected: &FileSource{pattern: "fixtures/*.txt"},
+		},
+		{
+			desc:     "traefik",
+			spec:     "traefik:/etc/traefik/dynamic/*.yml",
+			expected: &TraefikSource{pattern: "/etc/traefik/dynamic/*.yml"},
+		},
+		{
+			desc:     "caddy",
+			spec:     "caddy:/etc/caddy/Caddyfile",
+			expected: &CaddySource{path: "/etc/caddy/Caddyfile"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := NewSource(test.spec)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, src)
+		})
+	}
+}
+
+func TestNewSource_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		spec     string
+		expected string
+	}{
+		{
+			desc:     "unknown",
+			spec:     "foo:bar",
+			expected: `inventory: unsupported source "foo"`,
+		},
+		{
+			desc:     "missing pattern",
+			spec:     "file",
+			expected: "inventory: file: missing pattern",
+		},
+		{
+			desc:     "invalid pattern",
+			spec:     "traefik:[",
+			expected: "inventory: traefik: syntax error in pattern",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewSource(test.spec)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestFileSource_Entries(t *testing.T) {
+	src, err := NewFileSource("fixtures/*.txt")
+	require.NoError(t, err)
+
+	entries, err := src.Entries(context.Background())
+	require.NoError(t, err)
+
+	expected := []Entry{
+		{Source: "file", Name: "fixtures/domains.txt:2", Domains: []string{"example.com", "www.example.com"}},
+		{Source: "file", Name: "fixtures/domains.txt:4", Domains: []string{"example.org", "api.example.org"}},
+	}
+
+	assert.Equal(t, expected, entries)
+}
+
+func TestTraefikSource_Entries(t *testing.T) {
+	src, err := NewTraefikSource("fixtures/traefik.*")
+	require.NoError(t, err)
+
+	entries, err := src.Entries(context.Background())
+	require.NoError(t, err)
+
+	expected := []Entry{
+		{Source: "traefik", Name: "web", Domains: []string{"example.org", "www.example.org"}},
+		{Source: "traefik", Name: "api", Domains: []string{"example.net", "*.example.net"}},
+		{Source: "traefik", Name: "web", Domains: []string{"example.com", "www.example.com"}},
+	}
+
+	assert.Equal(t, expected, entries)
+}
+
+func TestCaddySource_Entries(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		path     string
+		expected []Entry
+	}{
+		{
+			desc: "Caddyfile",
+			path: "fixtures/Caddyfile",
+			expected: []Entry{
+				{Source: "caddy", Name: "example.com", Domains: []string{"example.com", "www.example.com"}},
+				{Source: "caddy", Name: "api.example.com", Domains: []string{"api.example.com", "api.example.org"}},
+			},
+		},
+		{
+			desc: "JSON",
+			path: "fixtures/caddy.json",
+			expected: []Entry{
+				{Source: "caddy", Name: "srv0", Domains: []string{"example.com", "www.example.com"}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := NewCaddySource(test.path)
+			require.NoError(t, err)
+
+			entries, err := src.Entries(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, entries)
+		})
+	}
+}
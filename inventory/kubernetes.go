@@ -0,0 +1,155 @@
+package inventory
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Kubernetes in-cluster configuration.
+const (
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// IngressSource reads the domains from the Kubernetes Ingress objects.
+// Each TLS section of an Ingress is a certificate,
+// the hosts of the rules are used if the Ingress has no TLS section.
+type IngressSource struct {
+	baseURL    *url.URL
+	namespace  string
+	token      string
+	httpClient *http.Client
+}
+
+// NewIngressSource creates an IngressSource with the in-cluster configuration.
+// An empty namespace means all the namespaces.
+func NewIngressSource(namespace string) (*IngressSource, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("inventory: kubernetes: not running inside a cluster (KUBERNETES_SERVICE_HOST, KUBERNETES_SERVICE_PORT)")
+	}
+
+	token, err := os.ReadFile(serviceAccountTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: kubernetes: %w", err)
+	}
+
+	caCert, err := os.ReadFile(serviceAccountCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: kubernetes: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("inventory: kubernetes: invalid CA certificate")
+	}
+
+	baseURL, _ := url.Parse("https://" + net.JoinHostPort(host, port))
+
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+
+	return &IngressSource{
+		baseURL:    baseURL,
+		namespace:  namespace,
+		token:      strings.TrimSpace(string(token)),
+		httpClient: client,
+	}, nil
+}
+
+// Entries implements Source.
+func (s *IngressSource) Entries(ctx context.Context) ([]Entry, error) {
+	endpoint := s.baseURL.JoinPath("apis", "networking.k8s.io", "v1")
+	if s.namespace != "" {
+		endpoint = endpoint.JoinPath("namespaces", s.namespace)
+	}
+
+	endpoint = endpoint.JoinPath("ingresses")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: kubernetes: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	var list ingressList
+
+	err = getJSON(s.httpClient, req, &list)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: kubernetes: %w", err)
+	}
+
+	var entries []Entry
+
+	for _, item := range list.Items {
+		name := item.Metadata.Namespace + "/" + item.Metadata.Name
+
+		for _, section := range item.Spec.TLS {
+			entries = append(entries, Entry{Source: "kubernetes", Name: name, Domains: section.Hosts})
+		}
+
+		if len(item.Spec.TLS) > 0 {
+			continue
+		}
+
+		var hosts []string
+		for _, rule := range item.Spec.Rules {
+			hosts = append(hosts, rule.Host)
+		}
+
+		entries = append(entries, Entry{Source: "kubernetes", Name: name, Domains: hosts})
+	}
+
+	return entries, nil
+}
+
+type ingressList struct {
+	Items []ingress `json:"items"`
+}
+
+type ingress struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		TLS []struct {
+			Hosts []string `json:"hosts"`
+		} `json:"tls"`
+		Rules []struct {
+			Host string `json:"host"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+func getJSON(client *http.Client, req *http.Request, result any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %s: %s", req.URL.Redacted(), resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,76 @@
+package inventory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngressSource_Entries(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /apis/networking.k8s.io/v1/namespaces/web/ingresses", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		http.ServeFile(rw, req, "fixtures/ingresses.json")
+	})
+
+	baseURL, _ := url.Parse(server.URL)
+
+	src := &IngressSource{baseURL: baseURL, namespace: "web", token: "secret", httpClient: server.Client()}
+
+	entries, err := src.Entries(context.Background())
+	require.NoError(t, err)
+
+	expected := []Entry{
+		{Source: "kubernetes", Name: "web/shop", Domains: []string{"shop.example.com", "www.shop.example.com"}},
+		{Source: "kubernetes", Name: "web/shop", Domains: []string{"api.shop.example.com"}},
+		{Source: "kubernetes", Name: "web/blog", Domains: []string{"blog.example.com"}},
+	}
+
+	assert.Equal(t, expected, entries)
+}
+
+func TestConsulSource_Entries(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /v1/catalog/services", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Consul-Token") != "secret" {
+			http.Error(rw, "unauthorized", http.StatusForbidden)
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{
+  "consul": [],
+  "web": ["primary", "lego.domains=example.com,www.example.com"],
+  "api": ["lego.domains=api.example.com"]
+}`))
+	})
+
+	t.Setenv("CONSUL_HTTP_TOKEN", "secret")
+
+	src, err := NewConsulSource(server.URL)
+	require.NoError(t, err)
+
+	entries, err := src.Entries(context.Background())
+	require.NoError(t, err)
+
+	expected := []Entry{
+		{Source: "consul", Name: "api", Domains: []string{"api.example.com"}},
+		{Source: "consul", Name: "web", Domains: []string{"example.com", "www.example.com"}},
+	}
+
+	assert.Equal(t, expected, entries)
+}
@@ -0,0 +1,136 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	hostMatcher = regexp.MustCompile(`\bHost\(([^)]*)\)`)
+	hostValue   = regexp.MustCompile("[`\"]([^`\"]+)[`\"]")
+)
+
+// TraefikSource reads the domains from the routers of the Traefik dynamic configuration files (YAML or TOML).
+// Each router with a TLS section is a certificate:
+// the domains are the TLS domains of the router or, if not defined, the hosts of the `Host` matchers of its rule.
+type TraefikSource struct {
+	pattern string
+}
+
+// NewTraefikSource creates a TraefikSource for the files matching a glob pattern.
+func NewTraefikSource(pattern string) (*TraefikSource, error) {
+	if pattern == "" {
+		return nil, errors.New("inventory: traefik: missing pattern")
+	}
+
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("inventory: traefik: %w", err)
+	}
+
+	return &TraefikSource{pattern: pattern}, nil
+}
+
+// Entries implements Source.
+func (s *TraefikSource) Entries(_ context.Context) ([]Entry, error) {
+	matches, err := filepath.Glob(s.pattern)
+	if err != nil {
+		return nil, fmt.Errorf("inventory: traefik: %w", err)
+	}
+
+	var entries []Entry
+
+	for _, match := range matches {
+		cfg, err := readTraefikConfig(match)
+		if err != nil {
+			return nil, fmt.Errorf("inventory: traefik: %s: %w", match, err)
+		}
+
+		names := make([]string, 0, len(cfg.HTTP.Routers))
+		for name := range cfg.HTTP.Routers {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			router := cfg.HTTP.Routers[name]
+			if router.TLS == nil {
+				continue
+			}
+
+			for _, domain := range router.TLS.Domains {
+				entries = append(entries, Entry{Source: "traefik", Name: name, Domains: append([]string{domain.Main}, domain.SANs...)})
+			}
+
+			if len(router.TLS.Domains) > 0 {
+				continue
+			}
+
+			entries = append(entries, Entry{Source: "traefik", Name: name, Domains: parseHostRule(router.Rule)})
+		}
+	}
+
+	return entries, nil
+}
+
+type traefikConfig struct {
+	HTTP struct {
+		Routers map[string]traefikRouter `yaml:"routers" toml:"routers"`
+	} `yaml:"http" toml:"http"`
+}
+
+type traefikRouter struct {
+	Rule string `yaml:"rule" toml:"rule"`
+	TLS  *struct {
+		Domains []struct {
+			Main string   `yaml:"main" toml:"main"`
+			SANs []string `yaml:"sans" toml:"sans"`
+		} `yaml:"domains" toml:"domains"`
+	} `yaml:"tls" toml:"tls"`
+}
+
+func readTraefikConfig(filename string) (*traefikConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &traefikConfig{}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, cfg)
+	default:
+		return nil, errors.New("unsupported file extension")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// parseHostRule extracts the hosts of the `Host` matchers of a router rule.
+func parseHostRule(rule string) []string {
+	var hosts []string
+
+	for _, matcher := range hostMatcher.FindAllStringSubmatch(rule, -1) {
+		for _, value := range hostValue.FindAllStringSubmatch(matcher[1], -1) {
+			hosts = append(hosts, value[1])
+		}
+	}
+
+	return hosts
+}
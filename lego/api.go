@@ -0,0 +1,56 @@
+package lego
+
+import (
+	"context"
+	"crypto"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/registration"
+	"golang.org/x/crypto/ocsp"
+)
+
+var (
+	_ CertifierAPI = (*certificate.Certifier)(nil)
+	_ RegistrarAPI = (*registration.Registrar)(nil)
+)
+
+// CertifierAPI is the interface of the certificate facade (Client.Certificate).
+// It allows the applications to replace the facade by a mock (see the legomock package) in their tests.
+type CertifierAPI interface {
+	Obtain(request certificate.ObtainRequest) (*certificate.Resource, error)
+	ObtainContext(ctx context.Context, request certificate.ObtainRequest) (*certificate.Resource, error)
+	ObtainForCSR(request certificate.ObtainForCSRRequest) (*certificate.Resource, error)
+	ObtainForCSRContext(ctx context.Context, request certificate.ObtainForCSRRequest) (*certificate.Resource, error)
+	GenerateOrder(request certificate.ObtainRequest) (*acme.ExtendedOrder, []acme.Authorization, error)
+	ResumeOrder(orderURL string) (*acme.ExtendedOrder, []acme.Authorization, error)
+	Challenge(order *acme.ExtendedOrder, authz []acme.Authorization, force bool) error
+	Finalize(order *acme.ExtendedOrder, authz []acme.Authorization, request certificate.ObtainRequest) (*certificate.Resource, error)
+	Prepare(request certificate.ObtainRequest, chlgType challenge.Type) (*certificate.PendingOrder, error)
+	Continue(pending *certificate.PendingOrder, request certificate.ObtainRequest) (*certificate.Resource, error)
+	PreAuthorize(domains []string) ([]certificate.PreAuthorization, error)
+	Renew(certRes certificate.Resource, bundle, mustStaple bool, preferredChain string) (*certificate.Resource, error)
+	RenewWithOptions(certRes certificate.Resource, options *certificate.RenewOptions) (*certificate.Resource, error)
+	GetRenewalInfo(req certificate.RenewalInfoRequest) (*certificate.RenewalInfoResponse, error)
+	RenewalInfo(certRes certificate.Resource) (*certificate.RenewalInfoResponse, error)
+	Revoke(cert []byte) error
+	RevokeWithReason(cert []byte, reason *uint) error
+	RevokeWithKey(cert []byte, privateKey crypto.PrivateKey, reason *uint) error
+	CheckRevocation(bundle []byte) (*certificate.RevocationStatus, error)
+	GetOCSP(bundle []byte) ([]byte, *ocsp.Response, error)
+	Get(url string, bundle bool) (*certificate.Resource, error)
+}
+
+// RegistrarAPI is the interface of the registration facade (Client.Registration).
+// It allows the applications to replace the facade by a mock (see the legomock package) in their tests.
+type RegistrarAPI interface {
+	Register(options registration.RegisterOptions) (*registration.Resource, error)
+	RegisterWithExternalAccountBinding(options registration.RegisterEABOptions) (*registration.Resource, error)
+	QueryRegistration() (*registration.Resource, error)
+	UpdateRegistration(options registration.RegisterOptions) (*registration.Resource, error)
+	UpdateContacts(additional []string) (*registration.Resource, error)
+	DeleteRegistration() error
+	ResolveAccountByKey() (*registration.Resource, error)
+	SignPayload(url string, payload []byte) ([]byte, error)
+}
@@ -2,11 +2,14 @@ package lego
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/pya789/lego/v4/acme/api"
 	"github.com/pya789/lego/v4/certificate"
 	"github.com/pya789/lego/v4/challenge/resolver"
+	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/registration"
 )
 
@@ -16,6 +19,19 @@ type Client struct {
 	Challenge    *resolver.SolverManager
 	Registration *registration.Registrar
 	core         *api.Core
+
+	// clockOffset the clock skew compensated by Now.
+	clockOffset time.Duration
+}
+
+// ClockSkewError is returned when the difference between the local clock and the clock of the CA exceeds the maximum clock skew.
+type ClockSkewError struct {
+	Skew    time.Duration
+	MaxSkew time.Duration
+}
+
+func (e *ClockSkewError) Error() string {
+	return fmt.Sprintf("the local clock differs from the clock of the CA by %s (maximum: %s): check the time synchronization (NTP) of the system", e.Skew, e.MaxSkew)
 }
 
 // NewClient creates a new ACME client on behalf of the user.
@@ -50,19 +66,50 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, err
 	}
 
+	var clockOffset time.Duration
+
+	if skew := core.GetClockSkew(); config.MaxClockSkew > 0 && skew.Abs() > config.MaxClockSkew {
+		if !config.CompensateClockSkew {
+			return nil, &ClockSkewError{Skew: skew, MaxSkew: config.MaxClockSkew}
+		}
+
+		log.Warnf("The local clock differs from the clock of the CA by %s: the clock of the CA is used for the validity checks.", skew)
+
+		clockOffset = skew
+	}
+
 	solversManager := resolver.NewSolversManager(core)
 
 	prober := resolver.NewProber(solversManager)
-	certifier := certificate.NewCertifier(core, prober, certificate.CertifierOptions{KeyType: config.Certificate.KeyType, Timeout: config.Certificate.Timeout, OverallRequestLimit: config.Certificate.OverallRequestLimit})
+	certifier := certificate.NewCertifier(core, prober, certificate.CertifierOptions{
+		KeyType:             config.Certificate.KeyType,
+		Timeout:             config.Certificate.Timeout,
+		OverallRequestLimit: config.Certificate.OverallRequestLimit,
+		PreferredChain:      config.Certificate.PreferredChain,
+		StrictIDNA:          config.Certificate.StrictIDNA,
+	})
 
 	return &Client{
 		Certificate:  certifier,
 		Challenge:    solversManager,
 		Registration: registration.NewRegistrar(core, config.User),
 		core:         core,
+		clockOffset:  clockOffset,
 	}, nil
 }
 
+// ClockSkew returns the difference between the clock of the CA and the local clock.
+// A positive value means that the local clock is behind the clock of the CA.
+func (c *Client) ClockSkew() time.Duration {
+	return c.core.GetClockSkew()
+}
+
+// Now returns the current time.
+// The clock skew is compensated if the skew exceeds Config.MaxClockSkew and Config.CompensateClockSkew is true.
+func (c *Client) Now() time.Time {
+	return time.Now().Add(c.clockOffset)
+}
+
 // GetToSURL returns the current ToS URL from the Directory.
 func (c *Client) GetToSURL() string {
 	return c.core.GetDirectory().Meta.TermsOfService
@@ -72,3 +119,13 @@ func (c *Client) GetToSURL() string {
 func (c *Client) GetExternalAccountRequired() bool {
 	return c.core.GetDirectory().Meta.ExternalAccountRequired
 }
+
+// GetProfiles returns the certificate profiles advertised by the Directory, by name, with their description.
+func (c *Client) GetProfiles() map[string]string {
+	return c.core.GetDirectory().Meta.Profiles
+}
+
+// SupportsPreAuthorization returns true if the CA supports the pre-authorization (newAuthz URL of the Directory).
+func (c *Client) SupportsPreAuthorization() bool {
+	return c.core.GetDirectory().NewAuthzURL != ""
+}
@@ -46,10 +46,19 @@ type Config struct {
 	UserAgent   string
 	HTTPClient  *http.Client
 	Certificate CertificateConfig
+
+	// MaxClockSkew the maximum difference allowed between the local clock and the clock of the CA (0 disables the check).
+	// The creation of the client fails with a ClockSkewError if the difference is greater,
+	// unless CompensateClockSkew is true.
+	MaxClockSkew time.Duration
+	// CompensateClockSkew uses the clock of the CA for the validity checks (Client.Now) instead of failing.
+	CompensateClockSkew bool
 }
 
+// NewConfig creates a new configuration.
+// If the user implements registration.UserWithDefaults, the account defaults are applied to the configuration.
 func NewConfig(user registration.User) *Config {
-	return &Config{
+	config := &Config{
 		CADirURL:   LEDirectoryProduction,
 		User:       user,
 		HTTPClient: createDefaultHTTPClient(),
@@ -58,12 +67,36 @@ func NewConfig(user registration.User) *Config {
 			Timeout: 30 * time.Second,
 		},
 	}
+
+	if u, ok := user.(registration.UserWithDefaults); ok {
+		config.Certificate.applyDefaults(u.GetDefaults())
+	}
+
+	return config
 }
 
 type CertificateConfig struct {
 	KeyType             certcrypto.KeyType
 	Timeout             time.Duration
 	OverallRequestLimit int
+	// PreferredChain the chain used when a request doesn't define its own preferred chain.
+	PreferredChain string
+	// StrictIDNA validates the domains with the IDNA2008/UTS-46 strict rules before ordering.
+	StrictIDNA bool
+}
+
+func (c *CertificateConfig) applyDefaults(defaults *registration.Defaults) {
+	if defaults == nil {
+		return
+	}
+
+	if defaults.KeyType != "" {
+		c.KeyType = defaults.KeyType
+	}
+
+	if defaults.PreferredChain != "" {
+		c.PreferredChain = defaults.PreferredChain
+	}
 }
 
 // createDefaultHTTPClient Creates an HTTP client with a reasonable timeout value
@@ -4,8 +4,13 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certcrypto"
 	"github.com/pya789/lego/v4/platform/tester"
 	"github.com/pya789/lego/v4/registration"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +39,53 @@ func TestNewClient(t *testing.T) {
 	assert.NotNil(t, client)
 }
 
+func TestNewClient_clockSkew(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+
+		_ = tester.WriteJSONResponse(rw, acme.Directory{
+			NewNonceURL:   "http://example.com/nonce",
+			NewAccountURL: "http://example.com/account",
+			NewOrderURL:   "http://example.com/newOrder",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 32)
+	require.NoError(t, err, "Could not generate test key")
+
+	config := NewConfig(mockUser{email: "test@test.com", privatekey: key})
+	config.CADirURL = server.URL
+	config.MaxClockSkew = 5 * time.Minute
+
+	_, err = NewClient(config)
+
+	var skewErr *ClockSkewError
+	require.ErrorAs(t, err, &skewErr)
+	assert.InDelta(t, time.Hour, skewErr.Skew, float64(2*time.Second))
+
+	config.CompensateClockSkew = true
+
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	assert.WithinDuration(t, time.Now().Add(time.Hour), client.Now(), 2*time.Second)
+}
+
+func TestNewConfig_defaults(t *testing.T) {
+	user := mockUserWithDefaults{
+		defaults: &registration.Defaults{
+			KeyType:        certcrypto.EC384,
+			PreferredChain: "ISRG Root X1",
+		},
+	}
+
+	config := NewConfig(user)
+
+	assert.Equal(t, certcrypto.EC384, config.Certificate.KeyType)
+	assert.Equal(t, "ISRG Root X1", config.Certificate.PreferredChain)
+}
+
 type mockUser struct {
 	email      string
 	regres     *registration.Resource
@@ -43,3 +95,10 @@ type mockUser struct {
 func (u mockUser) GetEmail() string                        { return u.email }
 func (u mockUser) GetRegistration() *registration.Resource { return u.regres }
 func (u mockUser) GetPrivateKey() crypto.PrivateKey        { return u.privatekey }
+
+type mockUserWithDefaults struct {
+	mockUser
+	defaults *registration.Defaults
+}
+
+func (u mockUserWithDefaults) GetDefaults() *registration.Defaults { return u.defaults }
@@ -0,0 +1,164 @@
+// Package legomock provides mocks of the lego.Client facades, based on testify/mock,
+// to unit test the applications without an ACME server.
+package legomock
+
+import (
+	"context"
+	"crypto"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/ocsp"
+)
+
+// Certifier is a mock of lego.CertifierAPI.
+type Certifier struct {
+	mock.Mock
+}
+
+// NewCertifier creates a Certifier mock, the expectations are asserted at the end of the test.
+func NewCertifier(t mock.TestingT) *Certifier {
+	m := &Certifier{}
+	m.Test(t)
+
+	if c, ok := t.(interface{ Cleanup(func()) }); ok {
+		c.Cleanup(func() { m.AssertExpectations(t) })
+	}
+
+	return m
+}
+
+func (m *Certifier) Obtain(request certificate.ObtainRequest) (*certificate.Resource, error) {
+	args := m.Called(request)
+	return resource(args, 0), args.Error(1)
+}
+
+func (m *Certifier) ObtainContext(ctx context.Context, request certificate.ObtainRequest) (*certificate.Resource, error) {
+	args := m.Called(ctx, request)
+	return resource(args, 0), args.Error(1)
+}
+
+func (m *Certifier) ObtainForCSR(request certificate.ObtainForCSRRequest) (*certificate.Resource, error) {
+	args := m.Called(request)
+	return resource(args, 0), args.Error(1)
+}
+
+func (m *Certifier) ObtainForCSRContext(ctx context.Context, request certificate.ObtainForCSRRequest) (*certificate.Resource, error) {
+	args := m.Called(ctx, request)
+	return resource(args, 0), args.Error(1)
+}
+
+func (m *Certifier) GenerateOrder(request certificate.ObtainRequest) (*acme.ExtendedOrder, []acme.Authorization, error) {
+	args := m.Called(request)
+
+	order, _ := args.Get(0).(*acme.ExtendedOrder)
+	authz, _ := args.Get(1).([]acme.Authorization)
+
+	return order, authz, args.Error(2)
+}
+
+func (m *Certifier) ResumeOrder(orderURL string) (*acme.ExtendedOrder, []acme.Authorization, error) {
+	args := m.Called(orderURL)
+
+	order, _ := args.Get(0).(*acme.ExtendedOrder)
+	authz, _ := args.Get(1).([]acme.Authorization)
+
+	return order, authz, args.Error(2)
+}
+
+func (m *Certifier) Challenge(order *acme.ExtendedOrder, authz []acme.Authorization, force bool) error {
+	return m.Called(order, authz, force).Error(0)
+}
+
+func (m *Certifier) Finalize(order *acme.ExtendedOrder, authz []acme.Authorization, request certificate.ObtainRequest) (*certificate.Resource, error) {
+	args := m.Called(order, authz, request)
+	return resource(args, 0), args.Error(1)
+}
+
+func (m *Certifier) Prepare(request certificate.ObtainRequest, chlgType challenge.Type) (*certificate.PendingOrder, error) {
+	args := m.Called(request, chlgType)
+
+	pending, _ := args.Get(0).(*certificate.PendingOrder)
+
+	return pending, args.Error(1)
+}
+
+func (m *Certifier) Continue(pending *certificate.PendingOrder, request certificate.ObtainRequest) (*certificate.Resource, error) {
+	args := m.Called(pending, request)
+	return resource(args, 0), args.Error(1)
+}
+
+func (m *Certifier) PreAuthorize(domains []string) ([]certificate.PreAuthorization, error) {
+	args := m.Called(domains)
+
+	authz, _ := args.Get(0).([]certificate.PreAuthorization)
+
+	return authz, args.Error(1)
+}
+
+func (m *Certifier) Renew(certRes certificate.Resource, bundle, mustStaple bool, preferredChain string) (*certificate.Resource, error) {
+	args := m.Called(certRes, bundle, mustStaple, preferredChain)
+	return resource(args, 0), args.Error(1)
+}
+
+func (m *Certifier) RenewWithOptions(certRes certificate.Resource, options *certificate.RenewOptions) (*certificate.Resource, error) {
+	args := m.Called(certRes, options)
+	return resource(args, 0), args.Error(1)
+}
+
+func (m *Certifier) GetRenewalInfo(req certificate.RenewalInfoRequest) (*certificate.RenewalInfoResponse, error) {
+	args := m.Called(req)
+
+	info, _ := args.Get(0).(*certificate.RenewalInfoResponse)
+
+	return info, args.Error(1)
+}
+
+func (m *Certifier) RenewalInfo(certRes certificate.Resource) (*certificate.RenewalInfoResponse, error) {
+	args := m.Called(certRes)
+
+	info, _ := args.Get(0).(*certificate.RenewalInfoResponse)
+
+	return info, args.Error(1)
+}
+
+func (m *Certifier) Revoke(cert []byte) error {
+	return m.Called(cert).Error(0)
+}
+
+func (m *Certifier) RevokeWithReason(cert []byte, reason *uint) error {
+	return m.Called(cert, reason).Error(0)
+}
+
+func (m *Certifier) RevokeWithKey(cert []byte, privateKey crypto.PrivateKey, reason *uint) error {
+	return m.Called(cert, privateKey, reason).Error(0)
+}
+
+func (m *Certifier) CheckRevocation(bundle []byte) (*certificate.RevocationStatus, error) {
+	args := m.Called(bundle)
+
+	status, _ := args.Get(0).(*certificate.RevocationStatus)
+
+	return status, args.Error(1)
+}
+
+func (m *Certifier) GetOCSP(bundle []byte) ([]byte, *ocsp.Response, error) {
+	args := m.Called(bundle)
+
+	raw, _ := args.Get(0).([]byte)
+	response, _ := args.Get(1).(*ocsp.Response)
+
+	return raw, response, args.Error(2)
+}
+
+func (m *Certifier) Get(url string, bundle bool) (*certificate.Resource, error) {
+	args := m.Called(url, bundle)
+	return resource(args, 0), args.Error(1)
+}
+
+func resource(args mock.Arguments, index int) *certificate.Resource {
+	res, _ := args.Get(index).(*certificate.Resource)
+	return res
+}
@@ -0,0 +1,46 @@
+package legomock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/lego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ lego.CertifierAPI = (*Certifier)(nil)
+	_ lego.RegistrarAPI = (*Registrar)(nil)
+)
+
+func TestCertifier(t *testing.T) {
+	certifier := NewCertifier(t)
+
+	request := certificate.ObtainRequest{Domains: []string{"example.com"}}
+
+	certifier.On("Obtain", request).Return(&certificate.Resource{Domain: "example.com"}, nil).Once()
+	certifier.On("Revoke", []byte("cert")).Return(errors.New("unauthorized")).Once()
+
+	var api lego.CertifierAPI = certifier
+
+	res, err := api.Obtain(request)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", res.Domain)
+
+	err = api.Revoke([]byte("cert"))
+	require.EqualError(t, err, "unauthorized")
+}
+
+func TestRegistrar(t *testing.T) {
+	registrar := NewRegistrar(t)
+
+	registrar.On("QueryRegistration").Return(nil, errors.New("account does not exist")).Once()
+
+	var api lego.RegistrarAPI = registrar
+
+	res, err := api.QueryRegistration()
+	require.EqualError(t, err, "account does not exist")
+	assert.Nil(t, res)
+}
@@ -0,0 +1,70 @@
+package legomock
+
+import (
+	"github.com/pya789/lego/v4/registration"
+	"github.com/stretchr/testify/mock"
+)
+
+// Registrar is a mock of lego.RegistrarAPI.
+type Registrar struct {
+	mock.Mock
+}
+
+// NewRegistrar creates a Registrar mock, the expectations are asserted at the end of the test.
+func NewRegistrar(t mock.TestingT) *Registrar {
+	m := &Registrar{}
+	m.Test(t)
+
+	if c, ok := t.(interface{ Cleanup(func()) }); ok {
+		c.Cleanup(func() { m.AssertExpectations(t) })
+	}
+
+	return m
+}
+
+func (m *Registrar) Register(options registration.RegisterOptions) (*registration.Resource, error) {
+	args := m.Called(options)
+	return account(args, 0), args.Error(1)
+}
+
+func (m *Registrar) RegisterWithExternalAccountBinding(options registration.RegisterEABOptions) (*registration.Resource, error) {
+	args := m.Called(options)
+	return account(args, 0), args.Error(1)
+}
+
+func (m *Registrar) QueryRegistration() (*registration.Resource, error) {
+	args := m.Called()
+	return account(args, 0), args.Error(1)
+}
+
+func (m *Registrar) UpdateRegistration(options registration.RegisterOptions) (*registration.Resource, error) {
+	args := m.Called(options)
+	return account(args, 0), args.Error(1)
+}
+
+func (m *Registrar) UpdateContacts(additional []string) (*registration.Resource, error) {
+	args := m.Called(additional)
+	return account(args, 0), args.Error(1)
+}
+
+func (m *Registrar) DeleteRegistration() error {
+	return m.Called().Error(0)
+}
+
+func (m *Registrar) ResolveAccountByKey() (*registration.Resource, error) {
+	args := m.Called()
+	return account(args, 0), args.Error(1)
+}
+
+func (m *Registrar) SignPayload(url string, payload []byte) ([]byte, error) {
+	args := m.Called(url, payload)
+
+	signed, _ := args.Get(0).([]byte)
+
+	return signed, args.Error(1)
+}
+
+func account(args mock.Arguments, index int) *registration.Resource {
+	res, _ := args.Get(index).(*registration.Resource)
+	return res
+}
@@ -1,13 +1,21 @@
 package log
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 // Logger is an optional custom logger.
 var Logger StdLogger = log.New(os.Stderr, "", log.LstdFlags)
 
+// leveled the logger with levels and fields, replaces Logger when defined (see SetLeveledLogger).
+var leveled LeveledLogger
+
+// minLevel the minimum level of the entries written with Logger (see SetLevel).
+var minLevel = LevelInfo
+
 // StdLogger interface for Standard Logger.
 type StdLogger interface {
 	Fatal(args ...interface{})
@@ -18,42 +26,157 @@ type StdLogger interface {
 	Printf(format string, args ...interface{})
 }
 
+// LeveledLogger is a logger supporting levels and fields (ex: a *slog.Logger, see NewSlogLogger).
+// When defined, it replaces Logger: the domain of the messages starting with "[domain]" is passed as the "domain" field.
+type LeveledLogger interface {
+	Log(level Level, msg string, fields ...Field)
+}
+
+// Field a field of a log entry.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// SetLeveledLogger defines the logger with levels and fields, used instead of Logger (nil to use Logger).
+// It must be called before the first log entry.
+func SetLeveledLogger(logger LeveledLogger) {
+	leveled = logger
+}
+
+// SetLevel defines the minimum level of the entries written with Logger (the default level is LevelInfo).
+// The level of a LeveledLogger is defined by the logger itself.
+func SetLevel(level Level) {
+	minLevel = level
+}
+
 // Fatal writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Fatal(args ...interface{}) {
+	if leveled != nil {
+		logEntry(LevelError, fmt.Sprint(args...))
+		os.Exit(1)
+	}
+
 	Logger.Fatal(args...)
 }
 
 // Fatalf writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Fatalf(format string, args ...interface{}) {
+	if leveled != nil {
+		logEntry(LevelError, fmt.Sprintf(format, args...))
+		os.Exit(1)
+	}
+
 	Logger.Fatalf(format, args...)
 }
 
 // Print writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Print(args ...interface{}) {
+	if leveled != nil {
+		logEntry(LevelInfo, fmt.Sprint(args...))
+		return
+	}
+
 	Logger.Print(args...)
 }
 
 // Println writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Println(args ...interface{}) {
+	if leveled != nil {
+		logEntry(LevelInfo, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+		return
+	}
+
 	Logger.Println(args...)
 }
 
 // Printf writes a log entry.
 // It uses Logger if not nil, otherwise it uses the default log.Logger.
 func Printf(format string, args ...interface{}) {
+	if leveled != nil {
+		logEntry(LevelInfo, fmt.Sprintf(format, args...))
+		return
+	}
+
 	Logger.Printf(format, args...)
 }
 
 // Warnf writes a log entry.
 func Warnf(format string, args ...interface{}) {
-	Printf("[WARN] "+format, args...)
+	logf(LevelWarn, format, args...)
 }
 
 // Infof writes a log entry.
 func Infof(format string, args ...interface{}) {
-	Printf("[INFO] "+format, args...)
+	logf(LevelInfo, format, args...)
+}
+
+// Debugf writes a log entry.
+// The entry is only written if the level is LevelDebug.
+func Debugf(format string, args ...interface{}) {
+	logf(LevelDebug, format, args...)
+}
+
+// Log writes a log entry with fields.
+func Log(level Level, msg string, fields ...Field) {
+	if leveled != nil {
+		leveled.Log(level, msg, fields...)
+		return
+	}
+
+	if level < minLevel {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(msg)
+
+	for _, field := range fields {
+		fmt.Fprintf(&b, " %s=%v", field.Key, field.Value)
+	}
+
+	Printf("[%s] %s", level, b.String())
+}
+
+func logf(level Level, format string, args ...interface{}) {
+	if leveled != nil {
+		logEntry(level, fmt.Sprintf(format, args...))
+		return
+	}
+
+	if level < minLevel {
+		return
+	}
+
+	Printf("["+level.String()+"] "+format, args...)
+}
+
+// logEntry writes an entry with the leveled logger, the "[domain]" prefix of the message is passed as a field.
+func logEntry(level Level, msg string) {
+	domain, rest := splitDomain(msg)
+	if domain == "" {
+		leveled.Log(level, msg)
+		return
+	}
+
+	leveled.Log(level, rest, Field{Key: "domain", Value: domain})
+}
+
+// splitDomain splits a message like "[example.com] acme: ..." into the domain and the rest of the message.
+func splitDomain(msg string) (string, string) {
+	if !strings.HasPrefix(msg, "[") {
+		return "", msg
+	}
+
+	domain, rest, ok := strings.Cut(msg[1:], "] ")
+	// a domain (or an IP address), not a word like "[INFO]".
+	if !ok || !strings.ContainsAny(domain, ".:") || strings.ContainsAny(domain, " []") {
+		return "", msg
+	}
+
+	return domain, rest
 }
@@ -0,0 +1,120 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfof_level(t *testing.T) {
+	buf := setupStdLogger(t)
+
+	SetLevel(LevelWarn)
+
+	Infof("[%s] acme: obtaining", "example.com")
+	Warnf("[%s] acme: failed", "example.com")
+	Debugf("hidden")
+
+	assert.Equal(t, "[WARN] [example.com] acme: failed\n", buf.String())
+}
+
+func TestLog_std(t *testing.T) {
+	buf := setupStdLogger(t)
+
+	SetLevel(LevelDebug)
+
+	Debugf("visible")
+	Log(LevelInfo, "renewed", Field{Key: "domain", Value: "example.com"}, Field{Key: "days", Value: 30})
+
+	assert.Equal(t, "[DEBUG] visible\n[INFO] renewed domain=example.com days=30\n", buf.String())
+}
+
+func TestSetLeveledLogger(t *testing.T) {
+	setupStdLogger(t)
+
+	buf := &bytes.Buffer{}
+
+	SetLeveledLogger(NewSlogLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		Level: LevelInfo.SlogLevel(),
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))))
+	t.Cleanup(func() { SetLeveledLogger(nil) })
+
+	Warnf("[%s] acme: error presenting token", "*.example.com")
+	Infof("[INFO] not a domain")
+	Debugf("hidden")
+	Log(LevelError, "renewal failed", Field{Key: "attempts", Value: 3})
+
+	var entries []map[string]any
+
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var entry map[string]any
+		require.NoError(t, dec.Decode(&entry))
+
+		entries = append(entries, entry)
+	}
+
+	expected := []map[string]any{
+		{"level": "WARN", "msg": "acme: error presenting token", "domain": "*.example.com"},
+		{"level": "INFO", "msg": "[INFO] not a domain"},
+		{"level": "ERROR", "msg": "renewal failed", "attempts": float64(3)},
+	}
+
+	assert.Equal(t, expected, entries)
+}
+
+func TestParseLevel(t *testing.T) {
+	testCases := []struct {
+		value         string
+		expected      Level
+		expectedError string
+	}{
+		{value: "debug", expected: LevelDebug},
+		{value: "INFO", expected: LevelInfo},
+		{value: "warning", expected: LevelWarn},
+		{value: "error", expected: LevelError},
+		{value: "trace", expected: LevelInfo, expectedError: `unknown log level: "trace"`},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			level, err := ParseLevel(test.value)
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expected, level)
+		})
+	}
+}
+
+func setupStdLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+
+	backup := Logger
+	Logger = log.New(buf, "", 0)
+
+	t.Cleanup(func() {
+		Logger = backup
+		SetLevel(LevelInfo)
+	})
+
+	return buf
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge"
+)
+
+// Order statuses.
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// DefaultBuckets the buckets (in seconds) of the duration of the calls to the challenge providers.
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Default the registry of the lego metrics.
+var Default = NewRegistry()
+
+// The lego metrics.
+var (
+	// Orders the number of certificate orders, by status (success, failure).
+	Orders = Default.NewCounter("lego_orders_total",
+		"The number of certificate orders, by status.", "status")
+
+	// Challenges the number of challenges, by type (ex: dns-01) and final status (valid, invalid, skipped).
+	Challenges = Default.NewCounter("lego_challenges_total",
+		"The number of challenges, by type and final status.", "type", "status")
+
+	// ProviderDuration the duration of the calls to the challenge providers, by challenge type, action (present, cleanup), and status.
+	ProviderDuration = Default.NewHistogram("lego_provider_api_duration_seconds",
+		"The duration of the calls to the challenge providers, in seconds.", DefaultBuckets, "challenge", "action", "status")
+
+	// ValidationFailures the number of challenges rejected by the CA, by type of problem (ex: dns, unauthorized).
+	ValidationFailures = Default.NewCounter("lego_validation_failures_total",
+		"The number of challenges rejected by the CA, by type of problem.", "type")
+
+	// CertificateExpiry the number of days before the expiration of the certificates, by domain.
+	CertificateExpiry = Default.NewGauge("lego_certificate_expiry_days",
+		"The number of days before the expiration of the certificate.", "domain")
+)
+
+// ObserveOrder records the result of a certificate order.
+func ObserveOrder(err error) {
+	Orders.Inc(status(err))
+}
+
+// ObserveChallenge records the final status of a challenge (valid, invalid, skipped),
+// and the type of the problem if the challenge has been rejected by the CA.
+// The intermediate statuses (presenting, validating, cleaning-up) are ignored.
+func ObserveChallenge(chlg challenge.Type, st challenge.Status, err error) {
+	switch st {
+	case challenge.StatusValid, challenge.StatusSkipped:
+		Challenges.Inc(chlg.String(), string(st))
+
+	case challenge.StatusInvalid:
+		Challenges.Inc(chlg.String(), string(st))
+
+		var problem *acme.ProblemDetails
+		if errors.As(err, &problem) && problem.Type != "" {
+			ValidationFailures.Inc(strings.TrimPrefix(problem.Type, "urn:ietf:params:acme:error:"))
+		} else {
+			ValidationFailures.Inc("unknown")
+		}
+
+	default:
+	}
+}
+
+// ObserveProviderCall records the duration of a call to a challenge provider (action: present, cleanup).
+func ObserveProviderCall(chlg challenge.Type, action string, duration time.Duration, err error) {
+	ProviderDuration.Observe(duration.Seconds(), chlg.String(), action, status(err))
+}
+
+// SetCertificateExpiry records the expiration date of the certificate of a domain.
+func SetCertificateExpiry(domain string, notAfter time.Time) {
+	CertificateExpiry.Set(time.Until(notAfter).Hours()/24, domain)
+}
+
+// Progress returns a progress function recording the orders and the challenges of a certificate request
+// (see certificate.ObtainRequest.Progress).
+// typeOf returns the type of the challenge used for a domain, next is called after the recording (both can be nil).
+func Progress(typeOf func(domain string) challenge.Type, next certificate.ProgressFunc) certificate.ProgressFunc {
+	return func(event certificate.ProgressEvent) {
+		switch {
+		case event.Phase == certificate.PhaseDone:
+			ObserveOrder(event.Err)
+
+		case event.Phase == certificate.PhaseChallenges && event.Domain != "":
+			var chlg challenge.Type
+			if typeOf != nil {
+				chlg = typeOf(event.Domain)
+			}
+
+			ObserveChallenge(chlg, event.Status, event.Err)
+		}
+
+		if next != nil {
+			next(event)
+		}
+	}
+}
+
+func status(err error) string {
+	if err != nil {
+		return StatusFailure
+	}
+
+	return StatusSuccess
+}
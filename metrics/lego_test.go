@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pya789/lego/v4/acme"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	var events []certificate.ProgressEvent
+
+	progress := Progress(func(domain string) challenge.Type {
+		return challenge.DNS01
+	}, func(event certificate.ProgressEvent) {
+		events = append(events, event)
+	})
+
+	problem := &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:dns", Detail: "NXDOMAIN"}
+
+	progress(certificate.ProgressEvent{Phase: certificate.PhaseChallenges})
+	progress(certificate.ProgressEvent{Phase: certificate.PhaseChallenges, Domain: "a.progress.example.com", Status: challenge.StatusPresenting})
+	progress(certificate.ProgressEvent{Phase: certificate.PhaseChallenges, Domain: "a.progress.example.com", Status: challenge.StatusValid})
+	progress(certificate.ProgressEvent{Phase: certificate.PhaseChallenges, Domain: "b.progress.example.com", Status: challenge.StatusInvalid, Err: problem})
+	progress(certificate.ProgressEvent{Phase: certificate.PhaseDone, Err: errors.New("oops")})
+
+	assert.Len(t, events, 5)
+
+	buf := &bytes.Buffer{}
+
+	err := Default.Write(buf)
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), `lego_orders_total{status="failure"} 1`)
+	assert.Contains(t, buf.String(), `lego_challenges_total{type="dns-01",status="valid"} 1`)
+	assert.Contains(t, buf.String(), `lego_challenges_total{type="dns-01",status="invalid"} 1`)
+	assert.Contains(t, buf.String(), `lego_validation_failures_total{type="dns"} 1`)
+	assert.NotContains(t, buf.String(), `status="presenting"`)
+}
@@ -0,0 +1,263 @@
+// Package metrics exposes metrics of the certificate requests (orders, challenges, provider calls, expiration)
+// in the Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// Registry a set of metrics exposed together.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*metric
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewCounter registers a counter: a value that only increases.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{metric: r.register(kindCounter, name, help, nil, labels)}
+}
+
+// NewGauge registers a gauge: a value that can go up and down.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{metric: r.register(kindGauge, name, help, nil, labels)}
+}
+
+// NewHistogram registers a histogram: the distribution of observed values in buckets.
+// The buckets are the upper bounds of the buckets, in increasing order (the +Inf bucket is implicit).
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{metric: r.register(kindHistogram, name, help, buckets, labels)}
+}
+
+func (r *Registry) register(kind, name, help string, buckets []float64, labels []string) *metric {
+	m := &metric{
+		kind:    kind,
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+
+	r.mu.Lock()
+	r.metrics = append(r.metrics, m)
+	r.mu.Unlock()
+
+	return m
+}
+
+// Write writes the metrics in the Prometheus text format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := make([]*metric, len(r.metrics))
+	copy(metrics, r.metrics)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	for _, m := range metrics {
+		m.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+// Handler returns an HTTP handler serving the metrics in the Prometheus text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		_ = r.Write(rw)
+	})
+}
+
+// Counter a value that only increases.
+type Counter struct {
+	*metric
+}
+
+// Inc increments the counter of the label values by 1.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds the value (must be positive) to the counter of the label values.
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		return
+	}
+
+	c.update(labelValues, func(s *series) { s.value += value })
+}
+
+// Gauge a value that can go up and down.
+type Gauge struct {
+	*metric
+}
+
+// Set sets the value of the gauge of the label values.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.update(labelValues, func(s *series) { s.value = value })
+}
+
+// Delete removes the gauge of the label values.
+func (g *Gauge) Delete(labelValues ...string) {
+	g.mu.Lock()
+	delete(g.series, seriesKey(labelValues))
+	g.mu.Unlock()
+}
+
+// Histogram the distribution of observed values.
+type Histogram struct {
+	*metric
+}
+
+// Observe adds an observed value to the histogram of the label values.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.update(labelValues, func(s *series) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(h.buckets))
+		}
+
+		for i, upper := range h.buckets {
+			if value <= upper {
+				s.counts[i]++
+			}
+		}
+
+		s.sum += value
+		s.count++
+	})
+}
+
+type metric struct {
+	kind    string
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series the value of a metric for a set of label values.
+type series struct {
+	labelValues []string
+
+	value float64
+
+	// histogram only: the cumulative counts of the buckets, the sum and the count of the observed values.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (m *metric) update(labelValues []string, fn func(s *series)) {
+	// the missing label values are empty, the extra label values are ignored.
+	values := make([]string, len(m.labels))
+	copy(values, labelValues)
+
+	key := seriesKey(values)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labelValues: values}
+		m.series[key] = s
+	}
+
+	fn(s)
+}
+
+func (m *metric) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n", m.name, escapeHelp(m.help))
+	_, _ = fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := m.series[key]
+
+		if m.kind != kindHistogram {
+			_, _ = fmt.Fprintf(w, "%s%s %s\n", m.name, formatLabels(m.labels, s.labelValues), formatValue(s.value))
+			continue
+		}
+
+		names := append(append([]string{}, m.labels...), "le")
+
+		for i, upper := range m.buckets {
+			values := append(append([]string{}, s.labelValues...), formatValue(upper))
+			_, _ = fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, formatLabels(names, values), s.counts[i])
+		}
+
+		values := append(append([]string{}, s.labelValues...), "+Inf")
+		_, _ = fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, formatLabels(names, values), s.count)
+		_, _ = fmt.Fprintf(w, "%s_sum%s %s\n", m.name, formatLabels(m.labels, s.labelValues), formatValue(s.sum))
+		_, _ = fmt.Fprintf(w, "%s_count%s %d\n", m.name, formatLabels(m.labels, s.labelValues), s.count)
+	}
+}
+
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(names))
+	for i, name := range names {
+		parts = append(parts, name+`="`+escapeLabelValue(values[i])+`"`)
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
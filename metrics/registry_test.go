@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	registry := NewRegistry()
+
+	counter := registry.NewCounter("test_total", "A counter.", "type", "status")
+	counter.Inc("dns-01", "valid")
+	counter.Inc("dns-01", "valid")
+	counter.Inc("http-01", "invalid")
+	counter.Add(-1, "http-01", "invalid")
+
+	gauge := registry.NewGauge("test_days", "A gauge.", "domain")
+	gauge.Set(12.5, `a"b\c`)
+	gauge.Set(3, "example.org")
+	gauge.Delete("example.org")
+
+	histogram := registry.NewHistogram("test_seconds", "A histogram.", []float64{0.5, 1}, "action")
+	histogram.Observe(0.25, "present")
+	histogram.Observe(0.75, "present")
+	histogram.Observe(2, "present")
+
+	buf := &bytes.Buffer{}
+
+	err := registry.Write(buf)
+	require.NoError(t, err)
+
+	expected := `# HELP test_total A counter.
+# TYPE test_total counter
+test_total{type="dns-01",status="valid"} 2
+test_total{type="http-01",status="invalid"} 1
+# HELP test_days A gauge.
+# TYPE test_days gauge
+test_days{domain="a\"b\\c"} 12.5
+# HELP test_seconds A histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{action="present",le="0.5"} 1
+test_seconds_bucket{action="present",le="1"} 2
+test_seconds_bucket{action="present",le="+Inf"} 3
+test_seconds_sum{action="present"} 3
+test_seconds_count{action="present"} 3
+`
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("test_total", "A counter.").Inc()
+
+	server := httptest.NewServer(registry.Handler())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "# HELP test_total A counter.\n# TYPE test_total counter\ntest_total 1\n", string(body))
+}
@@ -3,21 +3,54 @@ package env
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pya789/lego/v4/log"
 )
 
+// EnvPrefix the name of the environment variable defining a prefix for the names of all the environment variables.
+// The prefix can be overridden for the environment variables of a namespace (ex: a DNS provider)
+// with the environment variable named EnvPrefix + "_" + namespace (ex: LEGO_ENV_PREFIX_CLOUDFLARE).
+const EnvPrefix = "LEGO_ENV_PREFIX"
+
+// EnvCredentialsDirectory the name of the environment variable defined by systemd
+// with the path of the directory containing the credentials of the service.
+const EnvCredentialsDirectory = "CREDENTIALS_DIRECTORY"
+
+// fallbackWarnings the prefixed environment variables already reported as missing.
+var fallbackWarnings sync.Map
+
+// Getter reads the environment variables.
+// The zero value resolves the prefixes defined by EnvPrefix.
+type Getter struct {
+	prefix string
+}
+
+// WithPrefix returns a Getter with a prefix for the names of all the environment variables,
+// overriding the prefixes defined by EnvPrefix (ex: to create a second DNS provider with other credentials).
+// The prefixed environment variables have priority over the non-prefixed ones.
+func WithPrefix(prefix string) Getter {
+	return Getter{prefix: prefix}
+}
+
 // Get environment variables.
 func Get(names ...string) (map[string]string, error) {
+	return Getter{}.Get(names...)
+}
+
+// Get environment variables.
+func (g Getter) Get(names ...string) (map[string]string, error) {
 	values := map[string]string{}
 
 	var missingEnvVars []string
 	for _, envVar := range names {
-		value := GetOrFile(envVar)
+		value := g.GetOrFile(envVar)
 		if value == "" {
 			missingEnvVars = append(missingEnvVars, envVar)
 		}
@@ -55,6 +88,11 @@ func Get(names ...string) (map[string]string, error) {
 //	env.GetWithFallback([]string{"LEGO_ONE", "LEGO_TWO"})
 //	// => error
 func GetWithFallback(groups ...[]string) (map[string]string, error) {
+	return Getter{}.GetWithFallback(groups...)
+}
+
+// GetWithFallback Get environment variable values (see the function GetWithFallback).
+func (g Getter) GetWithFallback(groups ...[]string) (map[string]string, error) {
 	values := map[string]string{}
 
 	var missingEnvVars []string
@@ -63,7 +101,7 @@ func GetWithFallback(groups ...[]string) (map[string]string, error) {
 			return nil, errors.New("undefined environment variable names")
 		}
 
-		value, envVar := getOneWithFallback(names[0], names[1:]...)
+		value, envVar := g.getOneWithFallback(names[0], names[1:]...)
 		if value == "" {
 			missingEnvVars = append(missingEnvVars, envVar)
 			continue
@@ -79,7 +117,12 @@ func GetWithFallback(groups ...[]string) (map[string]string, error) {
 }
 
 func GetOneWithFallback[T any](main string, defaultValue T, fn func(string) (T, error), names ...string) T {
-	v, _ := getOneWithFallback(main, names...)
+	return GetOneWithFallbackFrom(Getter{}, main, defaultValue, fn, names...)
+}
+
+// GetOneWithFallbackFrom reads an environment variable value, or its fallbacks, with the getter (see GetOneWithFallback).
+func GetOneWithFallbackFrom[T any](g Getter, main string, defaultValue T, fn func(string) (T, error), names ...string) T {
+	v, _ := g.getOneWithFallback(main, names...)
 
 	value, err := fn(v)
 	if err != nil {
@@ -89,14 +132,14 @@ func GetOneWithFallback[T any](main string, defaultValue T, fn func(string) (T,
 	return value
 }
 
-func getOneWithFallback(main string, names ...string) (string, string) {
-	value := GetOrFile(main)
+func (g Getter) getOneWithFallback(main string, names ...string) (string, string) {
+	value := g.GetOrFile(main)
 	if value != "" {
 		return value, main
 	}
 
 	for _, name := range names {
-		value := GetOrFile(name)
+		value := g.GetOrFile(name)
 		if value != "" {
 			return value, main
 		}
@@ -108,29 +151,49 @@ func getOneWithFallback(main string, names ...string) (string, string) {
 // GetOrDefaultString returns the given environment variable value as a string.
 // Returns the default if the env var cannot be found.
 func GetOrDefaultString(envVar string, defaultValue string) string {
-	return getOrDefault(envVar, defaultValue, ParseString)
+	return Getter{}.GetOrDefaultString(envVar, defaultValue)
+}
+
+// GetOrDefaultString returns the given environment variable value (see the function GetOrDefaultString).
+func (g Getter) GetOrDefaultString(envVar string, defaultValue string) string {
+	return getOrDefault(g, envVar, defaultValue, ParseString)
 }
 
 // GetOrDefaultBool returns the given environment variable value as a boolean.
 // Returns the default if the env var cannot be coopered to a boolean, or is not found.
 func GetOrDefaultBool(envVar string, defaultValue bool) bool {
-	return getOrDefault(envVar, defaultValue, strconv.ParseBool)
+	return Getter{}.GetOrDefaultBool(envVar, defaultValue)
+}
+
+// GetOrDefaultBool returns the given environment variable value (see the function GetOrDefaultBool).
+func (g Getter) GetOrDefaultBool(envVar string, defaultValue bool) bool {
+	return getOrDefault(g, envVar, defaultValue, strconv.ParseBool)
 }
 
 // GetOrDefaultInt returns the given environment variable value as an integer.
 // Returns the default if the env var cannot be coopered to an int, or is not found.
 func GetOrDefaultInt(envVar string, defaultValue int) int {
-	return getOrDefault(envVar, defaultValue, strconv.Atoi)
+	return Getter{}.GetOrDefaultInt(envVar, defaultValue)
+}
+
+// GetOrDefaultInt returns the given environment variable value (see the function GetOrDefaultInt).
+func (g Getter) GetOrDefaultInt(envVar string, defaultValue int) int {
+	return getOrDefault(g, envVar, defaultValue, strconv.Atoi)
 }
 
 // GetOrDefaultSecond returns the given environment variable value as a time.Duration (second).
 // Returns the default if the env var cannot be coopered to an int, or is not found.
 func GetOrDefaultSecond(envVar string, defaultValue time.Duration) time.Duration {
-	return getOrDefault(envVar, defaultValue, ParseSecond)
+	return Getter{}.GetOrDefaultSecond(envVar, defaultValue)
+}
+
+// GetOrDefaultSecond returns the given environment variable value (see the function GetOrDefaultSecond).
+func (g Getter) GetOrDefaultSecond(envVar string, defaultValue time.Duration) time.Duration {
+	return getOrDefault(g, envVar, defaultValue, ParseSecond)
 }
 
-func getOrDefault[T any](envVar string, defaultValue T, fn func(string) (T, error)) T {
-	v, err := fn(GetOrFile(envVar))
+func getOrDefault[T any](g Getter, envVar string, defaultValue T, fn func(string) (T, error)) T {
+	v, err := fn(g.GetOrFile(envVar))
 	if err != nil {
 		return defaultValue
 	}
@@ -141,7 +204,38 @@ func getOrDefault[T any](envVar string, defaultValue T, fn func(string) (T, erro
 // GetOrFile Attempts to resolve 'key' as an environment variable.
 // Failing that, it will check to see if '<key>_FILE' exists.
 // If so, it will attempt to read from the referenced file to populate a value.
+// Failing that, it will attempt to read the systemd credential named 'key'.
+//
+// If a prefix is defined (see EnvPrefix), the prefixed environment variable is resolved first.
+// A warning is logged when the prefixed environment variable is missing and the non-prefixed one is used instead.
 func GetOrFile(envVar string) string {
+	return Getter{}.GetOrFile(envVar)
+}
+
+// GetOrFile resolves an environment variable (see the function GetOrFile),
+// with the prefix of the getter if defined.
+func (g Getter) GetOrFile(envVar string) string {
+	prefix := g.getPrefix(envVar)
+	if prefix == "" {
+		return getOrFile(envVar)
+	}
+
+	value := getOrFile(prefix + envVar)
+	if value != "" {
+		return value
+	}
+
+	value = getOrFile(envVar)
+	if value != "" {
+		if _, warned := fallbackWarnings.LoadOrStore(prefix+envVar, struct{}{}); !warned {
+			log.Warnf("The environment variable %s is not defined (prefix %q): %s is used instead.", prefix+envVar, prefix, envVar)
+		}
+	}
+
+	return value
+}
+
+func getOrFile(envVar string) string {
 	envVarValue := os.Getenv(envVar)
 	if envVarValue != "" {
 		return envVarValue
@@ -150,7 +244,7 @@ func GetOrFile(envVar string) string {
 	fileVar := envVar + "_FILE"
 	fileVarValue := os.Getenv(fileVar)
 	if fileVarValue == "" {
-		return envVarValue
+		return getCredential(envVar)
 	}
 
 	fileContents, err := os.ReadFile(fileVarValue)
@@ -162,6 +256,58 @@ func GetOrFile(envVar string) string {
 	return strings.TrimSuffix(string(fileContents), "\n")
 }
 
+// getCredential reads the value from the systemd credentials (LoadCredential, SetCredential, etc.).
+// The name of the credential is the name of the environment variable.
+func getCredential(envVar string) string {
+	dir := os.Getenv(EnvCredentialsDirectory)
+	if dir == "" || strings.ContainsAny(envVar, `/\`) {
+		return ""
+	}
+
+	filename := filepath.Join(dir, envVar)
+
+	fileContents, err := os.ReadFile(filename)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Failed to read the credential %s: %s", filename, err)
+		}
+
+		return ""
+	}
+
+	return strings.TrimSuffix(string(fileContents), "\n")
+}
+
+// getPrefix returns the prefix of the environment variable.
+// The prefix of the getter has priority over the prefixes defined by the environment variables,
+// the prefix of the longest matching namespace (LEGO_ENV_PREFIX_<namespace>) has priority over the global prefix (LEGO_ENV_PREFIX).
+func (g Getter) getPrefix(envVar string) string {
+	if g.prefix != "" {
+		return g.prefix
+	}
+
+	var prefix, namespace string
+
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+
+		ns, found := strings.CutPrefix(name, EnvPrefix+"_")
+		if !found || ns == "" || !strings.HasPrefix(envVar, ns+"_") {
+			continue
+		}
+
+		if len(ns) > len(namespace) {
+			prefix, namespace = value, ns
+		}
+	}
+
+	if namespace != "" {
+		return prefix
+	}
+
+	return os.Getenv(EnvPrefix)
+}
+
 // ParseSecond parses env var value (string) to a second (time.Duration).
 func ParseSecond(s string) (time.Duration, error) {
 	v, err := strconv.Atoi(s)
@@ -1,10 +1,14 @@
 package env
 
 import (
+	"bytes"
+	stdlog "log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/pya789/lego/v4/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -406,3 +410,181 @@ func TestGetOrFile_PrefersEnvVars(t *testing.T) {
 
 	assert.Equal(t, "lego_env", value)
 }
+
+func TestGetOrFile_prefix(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "no prefix",
+			envVars: map[string]string{
+				"TEST_LEGO_ENV_VAR":         "lego_env",
+				"STAGING_TEST_LEGO_ENV_VAR": "lego_staging",
+			},
+			expected: "lego_env",
+		},
+		{
+			desc: "global prefix",
+			envVars: map[string]string{
+				EnvPrefix:                   "STAGING_",
+				"TEST_LEGO_ENV_VAR":         "lego_env",
+				"STAGING_TEST_LEGO_ENV_VAR": "lego_staging",
+			},
+			expected: "lego_staging",
+		},
+		{
+			desc: "global prefix: fallback",
+			envVars: map[string]string{
+				EnvPrefix:           "STAGING_",
+				"TEST_LEGO_ENV_VAR": "lego_env",
+			},
+			expected: "lego_env",
+		},
+		{
+			desc: "namespace prefix",
+			envVars: map[string]string{
+				EnvPrefix:                   "STAGING_",
+				EnvPrefix + "_TEST_LEGO":    "OTHER_",
+				"TEST_LEGO_ENV_VAR":         "lego_env",
+				"STAGING_TEST_LEGO_ENV_VAR": "lego_staging",
+				"OTHER_TEST_LEGO_ENV_VAR":   "lego_other",
+			},
+			expected: "lego_other",
+		},
+		{
+			desc: "longest namespace prefix",
+			envVars: map[string]string{
+				EnvPrefix + "_TEST":          "STAGING_",
+				EnvPrefix + "_TEST_LEGO":     "OTHER_",
+				"STAGING_TEST_LEGO_ENV_VAR":  "lego_staging",
+				"OTHER_TEST_LEGO_ENV_VAR":    "lego_other",
+				"TEST_LEGO_ENV_VAR":          "lego_env",
+				EnvPrefix + "_TEST_LEGO_ENV": "",
+			},
+			expected: "lego_env",
+		},
+		{
+			desc: "namespace prefix: not matching",
+			envVars: map[string]string{
+				EnvPrefix + "_TEST_LEG":   "OTHER_",
+				"TEST_LEGO_ENV_VAR":       "lego_env",
+				"OTHER_TEST_LEGO_ENV_VAR": "lego_other",
+			},
+			expected: "lego_env",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			for k, v := range test.envVars {
+				t.Setenv(k, v)
+			}
+
+			assert.Equal(t, test.expected, GetOrFile("TEST_LEGO_ENV_VAR"))
+		})
+	}
+}
+
+func TestGetOrFile_prefixFallbackWarning(t *testing.T) {
+	backup := log.Logger
+	t.Cleanup(func() { log.Logger = backup })
+
+	buf := &bytes.Buffer{}
+	log.Logger = stdlog.New(buf, "", 0)
+
+	t.Setenv(EnvPrefix, "WARN_")
+	t.Setenv("TEST_LEGO_ENV_WARN", "lego_env")
+
+	assert.Equal(t, "lego_env", GetOrFile("TEST_LEGO_ENV_WARN"))
+	assert.Equal(t, "lego_env", GetOrFile("TEST_LEGO_ENV_WARN"))
+
+	// only one warning by environment variable.
+	assert.Equal(t, "[WARN] The environment variable WARN_TEST_LEGO_ENV_WARN is not defined (prefix \"WARN_\"): TEST_LEGO_ENV_WARN is used instead.\n", buf.String())
+
+	buf.Reset()
+
+	t.Setenv("WARN_TEST_LEGO_ENV_WARN", "lego_warn")
+
+	assert.Equal(t, "lego_warn", GetOrFile("TEST_LEGO_ENV_WARN"))
+	assert.Empty(t, buf.String())
+}
+
+func TestGetOrFile_credentials(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "TEST_LEGO_ENV_VAR"), []byte("lego_credential\n"), 0o600)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "STAGING_TEST_LEGO_ENV_VAR"), []byte("lego_staging"), 0o600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc:     "credential",
+			envVars:  map[string]string{EnvCredentialsDirectory: dir},
+			expected: "lego_credential",
+		},
+		{
+			desc: "env var has priority",
+			envVars: map[string]string{
+				EnvCredentialsDirectory: dir,
+				"TEST_LEGO_ENV_VAR":     "lego_env",
+			},
+			expected: "lego_env",
+		},
+		{
+			desc: "prefixed credential",
+			envVars: map[string]string{
+				EnvCredentialsDirectory: dir,
+				EnvPrefix:               "STAGING_",
+			},
+			expected: "lego_staging",
+		},
+		{
+			desc:    "no credentials directory",
+			envVars: map[string]string{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Setenv(EnvCredentialsDirectory, "")
+
+			for k, v := range test.envVars {
+				t.Setenv(k, v)
+			}
+
+			assert.Equal(t, test.expected, GetOrFile("TEST_LEGO_ENV_VAR"))
+		})
+	}
+
+	t.Run("missing credential", func(t *testing.T) {
+		t.Setenv(EnvCredentialsDirectory, dir)
+
+		assert.Empty(t, GetOrFile("TEST_LEGO_MISSING"))
+	})
+}
+
+func TestWithPrefix(t *testing.T) {
+	t.Setenv(EnvPrefix+"_TEST_LEGO", "OTHER_")
+	t.Setenv("TEST_LEGO_ENV_VAR", "lego_env")
+	t.Setenv("TEST_LEGO_ENV_OTHER", "lego_other")
+	t.Setenv("CLEANUP_TEST_LEGO_ENV_VAR", "lego_cleanup")
+
+	values, err := WithPrefix("CLEANUP_").Get("TEST_LEGO_ENV_VAR", "TEST_LEGO_ENV_OTHER")
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"TEST_LEGO_ENV_VAR":   "lego_cleanup",
+		"TEST_LEGO_ENV_OTHER": "lego_other",
+	}
+	assert.Equal(t, expected, values)
+
+	assert.Equal(t, "lego_env", GetOrFile("TEST_LEGO_ENV_VAR"))
+}
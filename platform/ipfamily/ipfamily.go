@@ -0,0 +1,147 @@
+// Package ipfamily selects the IP address family (IPv4/IPv6) of the outgoing connections.
+package ipfamily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Family the selection of the IP address family.
+type Family string
+
+const (
+	// Any uses the default behavior of the system.
+	Any Family = ""
+	// IPv4 uses only IPv4.
+	IPv4 Family = "ipv4"
+	// IPv6 uses only IPv6.
+	IPv6 Family = "ipv6"
+	// PreferIPv4 uses IPv4 first, and IPv6 if IPv4 fails.
+	PreferIPv4 Family = "prefer-ipv4"
+	// PreferIPv6 uses IPv6 first, and IPv4 if IPv6 fails.
+	PreferIPv6 Family = "prefer-ipv6"
+)
+
+// DialFunc a function to establish a connection (ex: net.Dialer.DialContext).
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Parse parses a family ("any", "ipv4", "ipv6", "prefer-ipv4", "prefer-ipv6").
+func Parse(value string) (Family, error) {
+	switch f := Family(strings.ToLower(value)); f {
+	case Any, "any":
+		return Any, nil
+	case IPv4, IPv6, PreferIPv4, PreferIPv6:
+		return f, nil
+	default:
+		return Any, fmt.Errorf("unsupported IP family: %s", value)
+	}
+}
+
+// Network restricts the network (tcp, udp, ip) to the family, if the family is forced (ex: tcp -> tcp6).
+func (f Family) Network(network string) string {
+	if network != "tcp" && network != "udp" && network != "ip" {
+		return network
+	}
+
+	switch f {
+	case IPv4:
+		return network + "4"
+	case IPv6:
+		return network + "6"
+	default:
+		return network
+	}
+}
+
+// Sort returns the IPs matching the family, the preferred family first.
+func (f Family) Sort(ips []net.IP) []net.IP {
+	var v4, v6 []net.IP
+
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+
+	switch f {
+	case IPv4:
+		return v4
+	case IPv6:
+		return v6
+	case PreferIPv4:
+		return append(v4, v6...)
+	case PreferIPv6:
+		return append(v6, v4...)
+	default:
+		return ips
+	}
+}
+
+// Resolve returns the addresses (host:port) of the host, matching the family, the preferred family first.
+// An IP address is returned as is, if it matches the family.
+func (f Family) Resolve(ctx context.Context, address string) ([]string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		ips, err = net.DefaultResolver.LookupIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ips = f.Sort(ips)
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no %s address for %s", f, host)
+	}
+
+	var addresses []string
+	for _, ip := range ips {
+		addresses = append(addresses, net.JoinHostPort(ip.String(), port))
+	}
+
+	return addresses, nil
+}
+
+// DialContext wraps a dial function to connect only to the addresses of the family, the preferred family first.
+// If the dial function is nil, a net.Dialer is used.
+func (f Family) DialContext(dial DialFunc) DialFunc {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	if f == Any {
+		return dial
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		addresses, err := f.Resolve(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+
+		var errAll error
+
+		for _, addr := range addresses {
+			conn, errD := dial(ctx, f.Network(network), addr)
+			if errD == nil {
+				return conn, nil
+			}
+
+			errAll = errors.Join(errAll, errD)
+		}
+
+		return nil, errAll
+	}
+}
@@ -0,0 +1,83 @@
+package ipfamily
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected Family
+	}{
+		{value: "", expected: Any},
+		{value: "any", expected: Any},
+		{value: "IPv4", expected: IPv4},
+		{value: "ipv6", expected: IPv6},
+		{value: "prefer-ipv4", expected: PreferIPv4},
+		{value: "prefer-ipv6", expected: PreferIPv6},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			family, err := Parse(test.value)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, family)
+		})
+	}
+
+	_, err := Parse("ipv5")
+	require.EqualError(t, err, "unsupported IP family: ipv5")
+}
+
+func TestFamily_Network(t *testing.T) {
+	assert.Equal(t, "tcp4", IPv4.Network("tcp"))
+	assert.Equal(t, "udp6", IPv6.Network("udp"))
+	assert.Equal(t, "tcp", PreferIPv6.Network("tcp"))
+	assert.Equal(t, "tcp", Any.Network("tcp"))
+	assert.Equal(t, "unix", IPv4.Network("unix"))
+}
+
+func TestFamily_Sort(t *testing.T) {
+	ips := []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1"), net.ParseIP("192.0.2.2")}
+
+	assert.Equal(t, ips, Any.Sort(ips))
+	assert.Equal(t, []net.IP{ips[0], ips[2]}, IPv4.Sort(ips))
+	assert.Equal(t, []net.IP{ips[1]}, IPv6.Sort(ips))
+	assert.Equal(t, []net.IP{ips[0], ips[2], ips[1]}, PreferIPv4.Sort(ips))
+	assert.Equal(t, []net.IP{ips[1], ips[0], ips[2]}, PreferIPv6.Sort(ips))
+}
+
+func TestFamily_Resolve(t *testing.T) {
+	addresses, err := PreferIPv6.Resolve(context.Background(), "192.0.2.1:53")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.1:53"}, addresses)
+
+	_, err = IPv6.Resolve(context.Background(), "192.0.2.1:53")
+	require.EqualError(t, err, "no ipv6 address for 192.0.2.1")
+}
+
+func TestFamily_DialContext(t *testing.T) {
+	var calls []string
+
+	dial := func(_ context.Context, network, address string) (net.Conn, error) {
+		calls = append(calls, network+" "+address)
+		return nil, errors.New("refused")
+	}
+
+	_, err := IPv4.DialContext(dial)(context.Background(), "tcp", "192.0.2.1:443")
+	require.Error(t, err)
+
+	_, err = PreferIPv6.DialContext(dial)(context.Background(), "tcp", "[2001:db8::1]:443")
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"tcp4 192.0.2.1:443", "tcp [2001:db8::1]:443"}, calls)
+}
@@ -0,0 +1,165 @@
+// Package outbound selects the source address (network interface) and the proxy of the outgoing connections,
+// for the multi-homed hosts where the default route is not the route to the ACME server or to the DNS provider APIs.
+package outbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Bind the source addresses of the outgoing connections.
+type Bind struct {
+	name    string
+	sources []net.IP
+}
+
+// NewBind creates a Bind from a network interface (the addresses of the interface are the source addresses),
+// or from a source IP address.
+func NewBind(iface, address string) (*Bind, error) {
+	switch {
+	case iface != "" && address != "":
+		return nil, errors.New("the network interface and the source address are mutually exclusive")
+
+	case address != "":
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid source address: %s", address)
+		}
+
+		return &Bind{name: address, sources: []net.IP{ip}}, nil
+
+	case iface != "":
+		return newInterfaceBind(iface)
+
+	default:
+		return nil, errors.New("a network interface or a source address is required")
+	}
+}
+
+func newInterfaceBind(name string) (*Bind, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("network interface %s: %w", name, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("network interface %s: %w", name, err)
+	}
+
+	return newBindFromAddrs(name, addrs)
+}
+
+func newBindFromAddrs(name string, addrs []net.Addr) (*Bind, error) {
+	b := &Bind{name: name}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+
+		// The link-local addresses are not routable: they can't reach the ACME server or the provider APIs.
+		if ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+
+		b.sources = append(b.sources, ipNet.IP)
+	}
+
+	if len(b.sources) == 0 {
+		return nil, fmt.Errorf("network interface %s: no usable address", name)
+	}
+
+	return b, nil
+}
+
+// Sources returns the source addresses usable to connect to the address (host:port) with the network (ex: tcp, tcp4).
+// All the source addresses are returned if the host is a name.
+func (b *Bind) Sources(network, address string) []net.IP {
+	v4 := strings.HasSuffix(network, "4")
+	v6 := strings.HasSuffix(network, "6")
+
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		if ip := net.ParseIP(host); ip != nil {
+			v4 = ip.To4() != nil
+			v6 = !v4
+		}
+	}
+
+	var sources []net.IP
+
+	for _, ip := range b.sources {
+		if v4 && ip.To4() == nil || v6 && ip.To4() != nil {
+			continue
+		}
+
+		sources = append(sources, ip)
+	}
+
+	return sources
+}
+
+// DialContext returns a dial function using the dialer with the source addresses.
+// The source addresses of the family of the destination are tried in order.
+func (b *Bind) DialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		sources := b.Sources(network, address)
+		if len(sources) == 0 {
+			return nil, fmt.Errorf("no source address of %s to connect to %s", b.name, address)
+		}
+
+		var errAll error
+
+		for _, ip := range sources {
+			d := *dialer
+			d.LocalAddr = localAddr(network, ip)
+
+			conn, err := d.DialContext(ctx, network, address)
+			if err == nil {
+				return conn, nil
+			}
+
+			errAll = errors.Join(errAll, err)
+		}
+
+		return nil, errAll
+	}
+}
+
+func localAddr(network string, ip net.IP) net.Addr {
+	if strings.HasPrefix(network, "udp") {
+		return &net.UDPAddr{IP: ip}
+	}
+
+	return &net.TCPAddr{IP: ip}
+}
+
+// ParseProxy parses the URL of a proxy: socks5://[user:password@]host:port, http://host:port, or https://host:port.
+// With SOCKS5, the host names are resolved by the proxy.
+func ParseProxy(value string) (*url.URL, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "socks5", "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q: socks5, http, or https", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy URL: missing host: %s", u.Redacted())
+	}
+
+	return u, nil
+}
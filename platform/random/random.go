@@ -0,0 +1,106 @@
+// Package random provides the random source of the non-cryptographic random values
+// (jitters, randomized DNS queries, serial numbers of the temporary challenge certificates).
+//
+// The source can be replaced by a deterministic source to reproduce the failure scenarios in the tests:
+// with the environment variable LEGO_RANDOM_SEED, or with SetSeed/SetSource.
+// The production keys are never generated with this source.
+package random
+
+import (
+	crand "crypto/rand"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EnvSeed the environment variable defining the seed of the deterministic mode.
+const EnvSeed = "LEGO_RANDOM_SEED"
+
+var (
+	mu            sync.Mutex
+	rnd           = rand.New(rand.NewSource(time.Now().UnixNano()))
+	deterministic bool
+)
+
+func init() {
+	if value, ok := os.LookupEnv(EnvSeed); ok {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			SetSeed(seed)
+		}
+	}
+}
+
+// SetSource replaces the random source.
+// The source is used to generate all the random values, including Reader: the values are reproducible.
+func SetSource(src rand.Source) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	rnd = rand.New(src)
+	deterministic = true
+}
+
+// SetSeed replaces the random source by a deterministic source initialized with the seed.
+func SetSeed(seed int64) {
+	SetSource(rand.NewSource(seed))
+}
+
+// Reset restores the default (non-deterministic) random source.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	deterministic = false
+}
+
+// Int63n returns a non-negative random number in [0,n). It panics if n <= 0.
+func Int63n(n int64) int64 {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return rnd.Int63n(n)
+}
+
+// Intn returns a non-negative random number in [0,n). It panics if n <= 0.
+func Intn(n int) int {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return rnd.Intn(n)
+}
+
+// Duration returns a random duration in [0,d), or 0 if d <= 0.
+func Duration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(Int63n(int64(d)))
+}
+
+// Reader returns a reader of random bytes: crypto/rand.Reader by default, the random source in deterministic mode.
+// Note: the key generation functions of the standard library are not deterministic, whatever the reader.
+func Reader() io.Reader {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !deterministic {
+		return crand.Reader
+	}
+
+	return reader{}
+}
+
+type reader struct{}
+
+func (reader) Read(p []byte) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return rnd.Read(p)
+}
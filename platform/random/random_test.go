@@ -0,0 +1,48 @@
+package random
+
+import (
+	crand "crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSeed(t *testing.T) {
+	t.Cleanup(Reset)
+
+	values := func() []int64 {
+		SetSeed(42)
+
+		var result []int64
+
+		result = append(result, Int63n(1000), int64(Intn(1000)), int64(Duration(time.Hour)))
+
+		buf := make([]byte, 8)
+		_, err := io.ReadFull(Reader(), buf)
+		require.NoError(t, err)
+
+		for _, b := range buf {
+			result = append(result, int64(b))
+		}
+
+		return result
+	}
+
+	assert.Equal(t, values(), values())
+}
+
+func TestReset(t *testing.T) {
+	SetSeed(42)
+	Reset()
+
+	assert.Equal(t, crand.Reader, Reader())
+}
+
+func TestDuration(t *testing.T) {
+	assert.Zero(t, Duration(0))
+	assert.Zero(t, Duration(-time.Second))
+	assert.Less(t, Duration(time.Second), time.Second)
+}
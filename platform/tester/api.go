@@ -27,9 +27,16 @@ func SetupFakeAPI(t *testing.T) (*http.ServeMux, string) {
 			NewNonceURL:   server.URL + "/nonce",
 			NewAccountURL: server.URL + "/account",
 			NewOrderURL:   server.URL + "/newOrder",
+			NewAuthzURL:   server.URL + "/newAuthz",
 			RevokeCertURL: server.URL + "/revokeCert",
 			KeyChangeURL:  server.URL + "/keyChange",
 			RenewalInfo:   server.URL + "/renewalInfo",
+			Meta: acme.Meta{
+				Profiles: map[string]string{
+					"classic":    "The default profile.",
+					"shortlived": "A profile for the short-lived certificates.",
+				},
+			},
 		})
 
 		mux.HandleFunc("/nonce", func(w http.ResponseWriter, r *http.Request) {
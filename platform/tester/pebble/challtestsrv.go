@@ -0,0 +1,101 @@
+package pebble
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge/dns01"
+)
+
+// ChallTestSrv a client of the management API of challtestsrv, the DNS mock of Pebble.
+// https://github.com/letsencrypt/pebble/tree/main/cmd/pebble-challtestsrv
+type ChallTestSrv struct {
+	baseURL    string
+	HTTPClient *http.Client
+}
+
+// NewChallTestSrv creates a client of the management API of challtestsrv (ex: http://127.0.0.1:8055).
+func NewChallTestSrv(baseURL string) *ChallTestSrv {
+	return &ChallTestSrv{
+		baseURL:    baseURL,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetTXT adds a TXT record.
+func (c *ChallTestSrv) SetTXT(ctx context.Context, fqdn, value string) error {
+	return c.post(ctx, "/set-txt", map[string]any{"host": dns01.ToFqdn(fqdn), "value": value})
+}
+
+// ClearTXT removes the TXT records of the FQDN.
+func (c *ChallTestSrv) ClearTXT(ctx context.Context, fqdn string) error {
+	return c.post(ctx, "/clear-txt", map[string]any{"host": dns01.ToFqdn(fqdn)})
+}
+
+// AddA adds A records.
+// The hosts without A records resolve to 127.0.0.1.
+func (c *ChallTestSrv) AddA(ctx context.Context, host string, addresses ...string) error {
+	return c.post(ctx, "/add-a", map[string]any{"host": dns01.ToFqdn(host), "addresses": addresses})
+}
+
+// ClearA removes the A records of the host.
+func (c *ChallTestSrv) ClearA(ctx context.Context, host string) error {
+	return c.post(ctx, "/clear-a", map[string]any{"host": dns01.ToFqdn(host)})
+}
+
+// DNSProvider returns a DNS-01 challenge provider creating the TXT records in challtestsrv.
+func (c *ChallTestSrv) DNSProvider() *DNSProvider {
+	return &DNSProvider{client: c}
+}
+
+func (c *ChallTestSrv) post(ctx context.Context, path string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("challtestsrv: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("challtestsrv: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("challtestsrv: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("challtestsrv: %s: unexpected status code: %d: %s", path, resp.StatusCode, string(raw))
+	}
+
+	return nil
+}
+
+// DNSProvider a DNS-01 challenge provider creating the TXT records in challtestsrv.
+type DNSProvider struct {
+	client *ChallTestSrv
+}
+
+// Present creates the TXT record.
+func (d *DNSProvider) Present(domain, _, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	return d.client.SetTXT(context.Background(), info.EffectiveFQDN, info.Value)
+}
+
+// CleanUp removes the TXT records.
+func (d *DNSProvider) CleanUp(domain, _, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	return d.client.ClearTXT(context.Background(), info.EffectiveFQDN)
+}
@@ -0,0 +1,143 @@
+package pebble
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"strings"
+	"testing"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/lego"
+	"github.com/pya789/lego/v4/registration"
+)
+
+// User a test ACME account.
+type User struct {
+	Email        string
+	Registration *registration.Resource
+	PrivateKey   crypto.PrivateKey
+}
+
+// GetEmail returns the email of the account.
+func (u *User) GetEmail() string {
+	return u.Email
+}
+
+// GetRegistration returns the registration of the account.
+func (u *User) GetRegistration() *registration.Resource {
+	return u.Registration
+}
+
+// GetPrivateKey returns the private key of the account.
+func (u *User) GetPrivateKey() crypto.PrivateKey {
+	return u.PrivateKey
+}
+
+// NewClient creates a lego client with a new account registered on Pebble.
+// The challenge providers must be defined by the test.
+func (e *Environment) NewClient(t testing.TB) *lego.Client {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("pebble: generate the account key: %v", err)
+	}
+
+	user := &User{Email: "test@example.com", PrivateKey: privateKey}
+
+	config := lego.NewConfig(user)
+	config.CADirURL = e.DirectoryURL()
+	config.HTTPClient = e.HTTPClient()
+	config.Certificate.KeyType = certcrypto.EC256
+
+	client, err := lego.NewClient(config)
+	if err != nil {
+		t.Fatalf("pebble: create the client: %v", err)
+	}
+
+	user.Registration, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	if err != nil {
+		t.Fatalf("pebble: register the account: %v", err)
+	}
+
+	return client
+}
+
+// DNS01Options returns the options of the DNS-01 challenge, using the DNS server of Pebble for the propagation check.
+// With challtestsrv, the propagation check on the authoritative nameservers is disabled (challtestsrv is the only nameserver).
+func (e *Environment) DNS01Options() []dns01.ChallengeOption {
+	options := []dns01.ChallengeOption{dns01.AddRecursiveNameservers([]string{e.DNSServer()})}
+
+	if e.challSrv != nil {
+		options = append(options, dns01.DisableCompletePropagationRequirement())
+	}
+
+	return options
+}
+
+// Obtain obtains a certificate for the domains, and checks it.
+func (e *Environment) Obtain(t testing.TB, client *lego.Client, domains ...string) *certificate.Resource {
+	t.Helper()
+
+	res, err := client.Certificate.Obtain(certificate.ObtainRequest{Domains: domains, Bundle: true})
+	if err != nil {
+		t.Fatalf("pebble: obtain: %v", err)
+	}
+
+	checkResource(t, res, domains)
+
+	return res
+}
+
+// Renew renews the certificate, and checks that a new certificate is issued for the same domains.
+func (e *Environment) Renew(t testing.TB, client *lego.Client, res *certificate.Resource) *certificate.Resource {
+	t.Helper()
+
+	cert := parseCertificate(t, res)
+
+	renewed, err := client.Certificate.RenewWithOptions(*res, &certificate.RenewOptions{Bundle: true})
+	if err != nil {
+		t.Fatalf("pebble: renew: %v", err)
+	}
+
+	checkResource(t, renewed, cert.DNSNames)
+
+	if parseCertificate(t, renewed).SerialNumber.Cmp(cert.SerialNumber) == 0 {
+		t.Fatalf("pebble: renew: the certificate has not been renewed")
+	}
+
+	return renewed
+}
+
+func checkResource(t testing.TB, res *certificate.Resource, domains []string) {
+	t.Helper()
+
+	if res == nil || len(res.Certificate) == 0 || len(res.PrivateKey) == 0 {
+		t.Fatalf("pebble: the certificate or the private key is missing")
+	}
+
+	cert := parseCertificate(t, res)
+
+	for _, domain := range domains {
+		// a wildcard domain is checked with a subdomain.
+		if err := cert.VerifyHostname(strings.Replace(domain, "*", "wildcard", 1)); err != nil {
+			t.Fatalf("pebble: %v", err)
+		}
+	}
+}
+
+func parseCertificate(t testing.TB, res *certificate.Resource) *x509.Certificate {
+	t.Helper()
+
+	cert, err := certcrypto.ParsePEMCertificate(res.Certificate)
+	if err != nil {
+		t.Fatalf("pebble: %v", err)
+	}
+
+	return cert
+}
@@ -0,0 +1,416 @@
+// Package pebble runs end-to-end tests against Pebble, the test ACME server of Let's Encrypt.
+// It launches Pebble and its DNS mock (challtestsrv), from the binaries or the container images,
+// and provides helpers to run the obtain and renew flows with a challenge provider.
+//
+//	func TestProvider(t *testing.T) {
+//		env := pebble.Start(t, pebble.Options{})
+//
+//		client := env.NewClient(t)
+//
+//		err := client.Challenge.SetDNS01Provider(provider, env.DNS01Options()...)
+//		require.NoError(t, err)
+//
+//		res := env.Obtain(t, client, "example.com")
+//		env.Renew(t, client, res)
+//	}
+//
+// The tests are skipped when the binaries (or the container runtime) are not available.
+package pebble
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/platform/wait"
+)
+
+// Default values of the options.
+const (
+	DefaultBinary         = "pebble"
+	DefaultChallSrvBinary = "pebble-challtestsrv"
+
+	DefaultListenAddress             = "127.0.0.1:14000"
+	DefaultManagementListenAddress   = "127.0.0.1:15000"
+	DefaultDNSAddress                = "127.0.0.1:8053"
+	DefaultChallSrvManagementAddress = "127.0.0.1:8055"
+
+	DefaultHTTPPort = 5002
+	DefaultTLSPort  = 5001
+
+	DefaultContainerRuntime = "docker"
+)
+
+// Options the options of the test environment.
+// The zero value launches the binaries from the PATH, with the default addresses.
+type Options struct {
+	// Binary the path of the Pebble binary (default: pebble).
+	Binary string
+	// ChallSrvBinary the path of the challtestsrv binary (default: pebble-challtestsrv).
+	ChallSrvBinary string
+
+	// Image the container image of Pebble (ex: ghcr.io/letsencrypt/pebble:latest), used instead of the binary.
+	Image string
+	// ChallSrvImage the container image of challtestsrv (ex: ghcr.io/letsencrypt/pebble-challtestsrv:latest), used instead of the binary.
+	ChallSrvImage string
+	// ContainerRuntime the command running the containers (default: docker).
+	// The containers use the network of the host (Linux only).
+	ContainerRuntime string
+
+	// ListenAddress the address of the ACME API (default: 127.0.0.1:14000).
+	ListenAddress string
+	// ManagementListenAddress the address of the management API of Pebble (default: 127.0.0.1:15000).
+	ManagementListenAddress string
+	// HTTPPort the port used by Pebble to validate the HTTP-01 challenges (default: 5002).
+	HTTPPort int
+	// TLSPort the port used by Pebble to validate the TLS-ALPN-01 challenges (default: 5001).
+	TLSPort int
+
+	// DNSServer the DNS server used by Pebble to validate the challenges (ex: 8.8.8.8:53).
+	// When empty, challtestsrv is launched and used as DNS server (see DNSAddress).
+	DNSServer string
+	// DNSAddress the address of the DNS server of challtestsrv (default: 127.0.0.1:8053).
+	DNSAddress string
+	// ChallSrvManagementAddress the address of the management API of challtestsrv (default: 127.0.0.1:8055).
+	ChallSrvManagementAddress string
+
+	// Env the additional environment variables of Pebble (ex: PEBBLE_WFE_NONCEREJECT=0).
+	Env []string
+
+	// StartTimeout the maximum waiting time for the start of Pebble (default: 30s).
+	StartTimeout time.Duration
+}
+
+// Environment a running test environment.
+type Environment struct {
+	options    Options
+	httpClient *http.Client
+	challSrv   *ChallTestSrv
+}
+
+// Start launches Pebble, and challtestsrv if no DNS server is defined.
+// The processes (or containers) are stopped at the end of the test.
+// The test is skipped if a binary, or the container runtime, is not found.
+func Start(t testing.TB, options Options) *Environment {
+	t.Helper()
+
+	options = withDefaults(options)
+
+	dir := t.TempDir()
+
+	certPool, err := writeConfig(dir, options)
+	if err != nil {
+		t.Fatalf("pebble: %v", err)
+	}
+
+	env := &Environment{
+		options: options,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: certPool}},
+		},
+	}
+
+	dnsServer := options.DNSServer
+
+	if dnsServer == "" {
+		dnsServer = options.DNSAddress
+
+		run(t, options, options.ChallSrvBinary, options.ChallSrvImage, "", nil, challSrvArgs(options)...)
+
+		env.challSrv = NewChallTestSrv("http://" + options.ChallSrvManagementAddress)
+	}
+
+	configFile := filepath.Join(dir, "pebble-config.json")
+	if options.Image != "" {
+		configFile = "/config/pebble-config.json"
+	}
+
+	run(t, options, options.Binary, options.Image, dir,
+		append([]string{"PEBBLE_VA_NOSLEEP=1"}, options.Env...),
+		"-config", configFile, "-dnsserver", dnsServer)
+
+	err = wait.For("pebble", options.StartTimeout, 200*time.Millisecond, func() (bool, error) {
+		resp, errG := env.httpClient.Get(env.DirectoryURL())
+		if errG != nil {
+			return false, errG
+		}
+
+		_ = resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK, nil
+	})
+	if err != nil {
+		t.Fatalf("pebble: %v", err)
+	}
+
+	return env
+}
+
+// DirectoryURL returns the URL of the ACME directory.
+func (e *Environment) DirectoryURL() string {
+	return "https://" + e.options.ListenAddress + "/dir"
+}
+
+// HTTPClient returns an HTTP client trusting the certificate of the ACME API.
+func (e *Environment) HTTPClient() *http.Client {
+	return e.httpClient
+}
+
+// DNSServer returns the DNS server used by Pebble to validate the challenges.
+func (e *Environment) DNSServer() string {
+	if e.options.DNSServer != "" {
+		return e.options.DNSServer
+	}
+
+	return e.options.DNSAddress
+}
+
+// ChallSrv returns the client of the management API of challtestsrv (nil if a DNS server is defined).
+func (e *Environment) ChallSrv() *ChallTestSrv {
+	return e.challSrv
+}
+
+// HTTPPort returns the port used by Pebble to validate the HTTP-01 challenges.
+func (e *Environment) HTTPPort() string {
+	return strconv.Itoa(e.options.HTTPPort)
+}
+
+// TLSPort returns the port used by Pebble to validate the TLS-ALPN-01 challenges.
+func (e *Environment) TLSPort() string {
+	return strconv.Itoa(e.options.TLSPort)
+}
+
+// run launches a binary, or a container if the image is defined, and stops it at the end of the test.
+func run(t testing.TB, options Options, binary, image, configDir string, env []string, args ...string) {
+	t.Helper()
+
+	if image != "" {
+		runContainer(t, options.ContainerRuntime, image, configDir, env, args...)
+		return
+	}
+
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		t.Skipf("pebble: %s not found: %v", binary, err)
+	}
+
+	var output bytes.Buffer
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Start()
+	if err != nil {
+		t.Fatalf("pebble: %s: %v", binary, err)
+	}
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+
+		if t.Failed() {
+			t.Logf("%s output:\n%s", binary, output.String())
+		}
+	})
+}
+
+func runContainer(t testing.TB, runtime, image, configDir string, env []string, args ...string) {
+	t.Helper()
+
+	path, err := exec.LookPath(runtime)
+	if err != nil {
+		t.Skipf("pebble: %s not found: %v", runtime, err)
+	}
+
+	runArgs := []string{"run", "--detach", "--rm", "--network", "host"}
+
+	if configDir != "" {
+		runArgs = append(runArgs, "--volume", configDir+":/config:ro")
+	}
+
+	for _, value := range env {
+		runArgs = append(runArgs, "--env", value)
+	}
+
+	runArgs = append(append(runArgs, image), args...)
+
+	output, err := exec.Command(path, runArgs...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("pebble: %s %s: %v: %s", runtime, image, err, output)
+	}
+
+	id := strings.TrimSpace(string(output))
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			logs, _ := exec.Command(path, "logs", id).CombinedOutput()
+			t.Logf("%s logs:\n%s", image, logs)
+		}
+
+		_ = exec.Command(path, "rm", "--force", id).Run()
+	})
+}
+
+func challSrvArgs(options Options) []string {
+	return []string{
+		"-dns01", options.DNSAddress,
+		"-management", options.ChallSrvManagementAddress,
+		// the other servers are not used: the HTTP-01 and TLS-ALPN-01 challenges are solved by the tested client.
+		"-http01", "",
+		"-https01", "",
+		"-tlsalpn01", "",
+		"-doh", "",
+		"-defaultIPv4", "127.0.0.1",
+		"-defaultIPv6", "",
+	}
+}
+
+func withDefaults(options Options) Options {
+	options.Binary = orDefault(options.Binary, DefaultBinary)
+	options.ChallSrvBinary = orDefault(options.ChallSrvBinary, DefaultChallSrvBinary)
+	options.ContainerRuntime = orDefault(options.ContainerRuntime, DefaultContainerRuntime)
+	options.ListenAddress = orDefault(options.ListenAddress, DefaultListenAddress)
+	options.ManagementListenAddress = orDefault(options.ManagementListenAddress, DefaultManagementListenAddress)
+	options.DNSAddress = orDefault(options.DNSAddress, DefaultDNSAddress)
+	options.ChallSrvManagementAddress = orDefault(options.ChallSrvManagementAddress, DefaultChallSrvManagementAddress)
+
+	if options.HTTPPort == 0 {
+		options.HTTPPort = DefaultHTTPPort
+	}
+
+	if options.TLSPort == 0 {
+		options.TLSPort = DefaultTLSPort
+	}
+
+	if options.StartTimeout == 0 {
+		options.StartTimeout = 30 * time.Second
+	}
+
+	return options
+}
+
+func orDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+// writeConfig writes the configuration of Pebble, and the certificate of the ACME API, in the directory.
+// Returns a pool containing the certificate.
+func writeConfig(dir string, options Options) (*x509.CertPool, error) {
+	certPEM, keyPEM, err := generateCertificate(options.ListenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "key.pem"), keyPEM, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	// the paths inside the container.
+	certDir := dir
+	if options.Image != "" {
+		certDir = "/config"
+	}
+
+	config := map[string]any{
+		"pebble": map[string]any{
+			"listenAddress":                  options.ListenAddress,
+			"managementListenAddress":        options.ManagementListenAddress,
+			"certificate":                    filepath.Join(certDir, "cert.pem"),
+			"privateKey":                     filepath.Join(certDir, "key.pem"),
+			"httpPort":                       options.HTTPPort,
+			"tlsPort":                        options.TLSPort,
+			"ocspResponderURL":               "",
+			"externalAccountBindingRequired": false,
+		},
+	}
+
+	raw, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "pebble-config.json"), raw, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+
+	return pool, nil
+}
+
+// generateCertificate generates a self-signed certificate for the host of the address, localhost, and the loopback addresses.
+func generateCertificate(address string) ([]byte, []byte, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "pebble"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	if host, _, errS := net.SplitHostPort(address); errS == nil && host != "" {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal private key: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
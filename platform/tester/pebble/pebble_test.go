@@ -0,0 +1,110 @@
+package pebble
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeConfig(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		options         Options
+		expectedCertDir func(dir string) string
+	}{
+		{
+			desc:            "binary",
+			options:         withDefaults(Options{}),
+			expectedCertDir: func(dir string) string { return dir },
+		},
+		{
+			desc:            "container",
+			options:         withDefaults(Options{Image: "ghcr.io/letsencrypt/pebble:latest"}),
+			expectedCertDir: func(string) string { return "/config" },
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			pool, err := writeConfig(dir, test.options)
+			require.NoError(t, err)
+			require.NotNil(t, pool)
+
+			raw, err := os.ReadFile(filepath.Join(dir, "pebble-config.json"))
+			require.NoError(t, err)
+
+			var config struct {
+				Pebble struct {
+					ListenAddress string `json:"listenAddress"`
+					Certificate   string `json:"certificate"`
+					PrivateKey    string `json:"privateKey"`
+					HTTPPort      int    `json:"httpPort"`
+					TLSPort       int    `json:"tlsPort"`
+				} `json:"pebble"`
+			}
+
+			err = json.Unmarshal(raw, &config)
+			require.NoError(t, err)
+
+			certDir := test.expectedCertDir(dir)
+
+			assert.Equal(t, DefaultListenAddress, config.Pebble.ListenAddress)
+			assert.Equal(t, filepath.Join(certDir, "cert.pem"), config.Pebble.Certificate)
+			assert.Equal(t, filepath.Join(certDir, "key.pem"), config.Pebble.PrivateKey)
+			assert.Equal(t, DefaultHTTPPort, config.Pebble.HTTPPort)
+			assert.Equal(t, DefaultTLSPort, config.Pebble.TLSPort)
+
+			assert.FileExists(t, filepath.Join(dir, "cert.pem"))
+			assert.FileExists(t, filepath.Join(dir, "key.pem"))
+		})
+	}
+}
+
+func TestChallTestSrv_DNSProvider(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		raw, _ := io.ReadAll(req.Body)
+		requests = append(requests, req.URL.Path+" "+string(raw))
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewChallTestSrv(server.URL).DNSProvider()
+
+	require.NoError(t, provider.Present("example.com", "token", "keyAuth"))
+	require.NoError(t, provider.CleanUp("example.com", "token", "keyAuth"))
+
+	expected := []string{
+		`/set-txt {"host":"_acme-challenge.example.com.","value":"pW9ZKG0xz_PCriK-nCMOjADy9eJcgGWIzkkj2fN4uZM"}`,
+		`/clear-txt {"host":"_acme-challenge.example.com."}`,
+	}
+
+	assert.Equal(t, expected, requests)
+}
+
+func TestStart(t *testing.T) {
+	if _, ok := os.LookupEnv("LEGO_E2E_TESTS"); !ok {
+		t.Skip("skipping test: e2e tests are disabled (no 'LEGO_E2E_TESTS' env var)")
+	}
+
+	env := Start(t, Options{})
+
+	client := env.NewClient(t)
+
+	err := client.Challenge.SetDNS01Provider(env.ChallSrv().DNSProvider(), env.DNS01Options()...)
+	require.NoError(t, err)
+
+	res := env.Obtain(t, client, "*.example.com", "example.com")
+	env.Renew(t, client, res)
+}
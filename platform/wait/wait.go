@@ -1,25 +1,37 @@
 package wait
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/pya789/lego/v4/log"
 )
 
+// ErrTimeout is returned when the time limit is exceeded.
+var ErrTimeout = errors.New("time limit exceeded")
+
 // For polls the given function 'f', once every 'interval', up to 'timeout'.
 func For(msg string, timeout, interval time.Duration, f func() (bool, error)) error {
+	return ForContext(context.Background(), msg, timeout, interval, f)
+}
+
+// ForContext is like For, the polling stops when the context is done.
+func ForContext(ctx context.Context, msg string, timeout, interval time.Duration, f func() (bool, error)) error {
 	log.Infof("Wait for %s [timeout: %s, interval: %s]", msg, timeout, interval)
 
 	var lastErr error
 	timeUp := time.After(timeout)
 	for {
 		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", msg, ctx.Err())
 		case <-timeUp:
 			if lastErr == nil {
-				return fmt.Errorf("%s: time limit exceeded", msg)
+				return fmt.Errorf("%s: %w", msg, ErrTimeout)
 			}
-			return fmt.Errorf("%s: time limit exceeded: last error: %w", msg, lastErr)
+			return fmt.Errorf("%s: %w: last error: %w", msg, ErrTimeout, lastErr)
 		default:
 		}
 
@@ -31,6 +43,10 @@ func For(msg string, timeout, interval time.Duration, f func() (bool, error)) er
 			lastErr = err
 		}
 
-		time.Sleep(interval)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", msg, ctx.Err())
+		case <-time.After(interval):
+		}
 	}
 }
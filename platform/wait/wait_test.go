@@ -1,6 +1,8 @@
 package wait
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -18,9 +20,27 @@ func TestForTimeout(t *testing.T) {
 	case <-timeout:
 		t.Fatal("timeout exceeded")
 	case err := <-c:
-		if err == nil {
+		if !errors.Is(err, ErrTimeout) {
 			t.Errorf("expected timeout error; got %v", err)
 		}
 		t.Logf("%v", err)
 	}
 }
+
+func TestForContext_canceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err := ForContext(ctx, "", time.Minute, time.Second, func() (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context error; got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the wait was not interrupted: %s", elapsed)
+	}
+}
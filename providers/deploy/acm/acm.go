@@ -0,0 +1,203 @@
+// Package acm implements a deploy target importing the certificates into AWS Certificate Manager (ACM).
+package acm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/pya789/lego/v4/certificate"
+)
+
+// Config is used to configure the ACM target.
+type Config struct {
+	// Region the AWS region of the certificate (ex: us-east-1 for CloudFront).
+	Region string `yaml:"region"`
+	// Profile the name of the AWS shared configuration profile (optional).
+	Profile string `yaml:"profile"`
+	// Tags the tags of the certificate (only applied on the first import).
+	Tags map[string]string `yaml:"tags"`
+	// Timeout the timeout of the import.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewDefaultConfig returns a default configuration for the ACM target.
+func NewDefaultConfig() *Config {
+	return &Config{
+		Timeout: 30 * time.Second,
+	}
+}
+
+// Target imports the certificates into ACM.
+// The ARN of the certificate is kept between the deployments, so the certificate is re-imported instead of duplicated.
+type Target struct {
+	config      *Config
+	endpoint    string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	httpClient  *http.Client
+}
+
+// NewTarget returns a Target instance.
+// The credentials are read from the default AWS configuration (environment variables, shared configuration, IAM role).
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("acm: the configuration is nil")
+	}
+
+	if config.Region == "" {
+		return nil, errors.New("acm: missing region")
+	}
+
+	credentials, err := loadCredentials(config)
+	if err != nil {
+		return nil, fmt.Errorf("acm: %w", err)
+	}
+
+	return &Target{
+		config:      config,
+		endpoint:    fmt.Sprintf("https://acm.%s.amazonaws.com/", config.Region),
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		httpClient:  &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Deploy imports the certificate into ACM as a new certificate.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	_, err := t.DeployRemote(ctx, res, "")
+	return err
+}
+
+// DeployRemote imports the certificate into ACM, and returns its ARN.
+// The certificate identified by the ARN is replaced if the ARN is defined.
+func (t *Target) DeployRemote(ctx context.Context, res *certificate.Resource, arn string) (string, error) {
+	leaf, chain, err := splitCertificates(res)
+	if err != nil {
+		return "", fmt.Errorf("acm: %w", err)
+	}
+
+	request := importCertificateRequest{
+		Certificate:      leaf,
+		PrivateKey:       res.PrivateKey,
+		CertificateChain: chain,
+		CertificateArn:   arn,
+	}
+
+	// The tags cannot be defined when a certificate is re-imported.
+	if arn == "" {
+		for key, value := range t.config.Tags {
+			request.Tags = append(request.Tags, tag{Key: key, Value: value})
+		}
+
+		sort.Slice(request.Tags, func(i, j int) bool { return request.Tags[i].Key < request.Tags[j].Key })
+	}
+
+	var response importCertificateResponse
+
+	err = t.do(ctx, "CertificateManager.ImportCertificate", request, &response)
+	if err != nil {
+		return "", fmt.Errorf("acm: import: %w", err)
+	}
+
+	return response.CertificateArn, nil
+}
+
+func (t *Target) do(ctx context.Context, action string, payload, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", action)
+
+	credentials, err := t.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("credentials: %w", err)
+	}
+
+	hash := sha256.Sum256(body)
+
+	err = t.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), "acm", t.config.Region, time.Now())
+	if err != nil {
+		return fmt.Errorf("sign request: %w", err)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiError
+		if json.Unmarshal(raw, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s: %s", apiErr.Type, apiErr.Message)
+		}
+
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, string(raw))
+	}
+
+	return json.Unmarshal(raw, result)
+}
+
+// splitCertificates returns the leaf certificate and its chain (PEM):
+// ACM requires the leaf certificate and the chain in separate fields.
+func splitCertificates(res *certificate.Resource) ([]byte, []byte, error) {
+	block, rest := pem.Decode(res.Certificate)
+	if block == nil {
+		return nil, nil, errors.New("invalid certificate")
+	}
+
+	chain := bytes.TrimSpace(rest)
+	if len(chain) == 0 {
+		chain = res.IssuerCertificate
+	}
+
+	return pem.EncodeToMemory(block), chain, nil
+}
+
+type importCertificateRequest struct {
+	Certificate      []byte `json:"Certificate"`
+	PrivateKey       []byte `json:"PrivateKey"`
+	CertificateChain []byte `json:"CertificateChain,omitempty"`
+	CertificateArn   string `json:"CertificateArn,omitempty"`
+	Tags             []tag  `json:"Tags,omitempty"`
+}
+
+type tag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type importCertificateResponse struct {
+	CertificateArn string `json:"CertificateArn"`
+}
+
+type apiError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
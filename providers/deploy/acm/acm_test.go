@@ -0,0 +1,98 @@
+package acm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	leafPEM   = "-----BEGIN CERTIFICATE-----\nbGVhZg==\n-----END CERTIFICATE-----\n"
+	issuerPEM = "-----BEGIN CERTIFICATE-----\naXNzdWVy\n-----END CERTIFICATE-----\n"
+)
+
+func setupTest(t *testing.T, handler http.HandlerFunc) *Target {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := NewDefaultConfig()
+	config.Region = "us-east-1"
+	config.Tags = map[string]string{"team": "infra", "env": "prod"}
+
+	return &Target{
+		config:      config,
+		endpoint:    server.URL,
+		credentials: credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		signer:      v4.NewSigner(),
+		httpClient:  server.Client(),
+	}
+}
+
+func TestTarget_DeployRemote(t *testing.T) {
+	var requests []importCertificateRequest
+
+	target := setupTest(t, func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "CertificateManager.ImportCertificate", req.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/"))
+		assert.Contains(t, req.Header.Get("Authorization"), "/us-east-1/acm/aws4_request")
+
+		raw, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var request importCertificateRequest
+		require.NoError(t, json.Unmarshal(raw, &request))
+
+		requests = append(requests, request)
+
+		_, _ = io.WriteString(rw, `{"CertificateArn":"arn:aws:acm:us-east-1:123456789012:certificate/abc"}`)
+	})
+
+	res := &certificate.Resource{
+		Domain:            "example.com",
+		Certificate:       []byte(leafPEM + issuerPEM),
+		IssuerCertificate: []byte(issuerPEM),
+		PrivateKey:        []byte("key"),
+	}
+
+	arn, err := target.DeployRemote(context.Background(), res, "")
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:acm:us-east-1:123456789012:certificate/abc", arn)
+
+	_, err = target.DeployRemote(context.Background(), res, arn)
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+
+	assert.Equal(t, leafPEM, string(requests[0].Certificate))
+	assert.Equal(t, strings.TrimSpace(issuerPEM), string(requests[0].CertificateChain))
+	assert.Equal(t, "key", string(requests[0].PrivateKey))
+	assert.Empty(t, requests[0].CertificateArn)
+	assert.Equal(t, []tag{{Key: "env", Value: "prod"}, {Key: "team", Value: "infra"}}, requests[0].Tags)
+
+	assert.Equal(t, arn, requests[1].CertificateArn)
+	assert.Empty(t, requests[1].Tags)
+}
+
+func TestTarget_DeployRemote_error(t *testing.T) {
+	target := setupTest(t, func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(rw, `{"__type":"ValidationException","message":"invalid certificate"}`)
+	})
+
+	res := &certificate.Resource{Domain: "example.com", Certificate: []byte(leafPEM), PrivateKey: []byte("key")}
+
+	_, err := target.DeployRemote(context.Background(), res, "")
+	require.EqualError(t, err, "acm: import: ValidationException: invalid certificate")
+}
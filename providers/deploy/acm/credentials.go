@@ -0,0 +1,23 @@
+package acm
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// loadCredentials reads the credentials from the default AWS configuration.
+func loadCredentials(cfg *Config) (aws.CredentialsProvider, error) {
+	var opts []func(*config.LoadOptions) error
+	if cfg.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(cfg.Profile))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(context.Background(), append(opts, config.WithRegion(cfg.Region))...)
+	if err != nil {
+		return nil, err
+	}
+
+	return awsCfg.Credentials, nil
+}
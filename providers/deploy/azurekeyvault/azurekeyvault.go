@@ -0,0 +1,220 @@
+// Package azurekeyvault implements a deploy target importing the certificates into Azure Key Vault.
+package azurekeyvault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/pya789/lego/v4/certificate"
+)
+
+const apiVersion = "7.4"
+
+const scope = "https://vault.azure.net/.default"
+
+var invalidNameChars = regexp.MustCompile(`[^0-9a-zA-Z-]`)
+
+// Config is used to configure the Azure Key Vault target.
+type Config struct {
+	// VaultURL the URL of the vault (ex: https://example.vault.azure.net).
+	VaultURL string `yaml:"vault_url"`
+	// CertificateName the name of the certificate in the vault (default: derived from the domain).
+	CertificateName string `yaml:"certificate_name"`
+	// Tags the tags of the certificate.
+	Tags map[string]string `yaml:"tags"`
+	// Timeout the timeout of the import.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewDefaultConfig returns a default configuration for the Azure Key Vault target.
+func NewDefaultConfig() *Config {
+	return &Config{
+		Timeout: 30 * time.Second,
+	}
+}
+
+// Target imports the certificates into Azure Key Vault.
+// A renewed certificate is imported as a new version of the existing certificate.
+type Target struct {
+	config     *Config
+	credential azcore.TokenCredential
+	httpClient *http.Client
+}
+
+// NewTarget returns a Target instance.
+// The credentials are read from the environment (environment variables, workload identity, managed identity, Azure CLI).
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("azurekeyvault: the configuration is nil")
+	}
+
+	if config.VaultURL == "" {
+		return nil, errors.New("azurekeyvault: missing vault URL")
+	}
+
+	if config.CertificateName != "" && invalidNameChars.MatchString(config.CertificateName) {
+		return nil, fmt.Errorf("azurekeyvault: invalid certificate name %q: only alphanumeric characters and dashes are allowed", config.CertificateName)
+	}
+
+	credential, err := loadCredential()
+	if err != nil {
+		return nil, fmt.Errorf("azurekeyvault: %w", err)
+	}
+
+	return &Target{
+		config:     config,
+		credential: credential,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Deploy imports the certificate into the vault.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	_, err := t.DeployRemote(ctx, res, "")
+	return err
+}
+
+// DeployRemote imports the certificate into the vault, and returns the ID of the certificate.
+// The certificate is imported as a new version of the certificate identified by remoteID, if defined.
+func (t *Target) DeployRemote(ctx context.Context, res *certificate.Resource, remoteID string) (string, error) {
+	if res.PrivateKey == nil {
+		return "", errors.New("azurekeyvault: the private key is required")
+	}
+
+	name := t.certificateName(res.Domain, remoteID)
+
+	value := bytes.TrimSpace(res.PrivateKey)
+	value = append(value, '\n')
+	value = append(value, res.Certificate...)
+
+	request := importRequest{
+		Value: string(value),
+		Policy: &certificatePolicy{
+			SecretProperties: &secretProperties{ContentType: "application/x-pem-file"},
+		},
+		Tags: t.config.Tags,
+	}
+
+	endpoint := fmt.Sprintf("%s/certificates/%s/import?api-version=%s", strings.TrimSuffix(t.config.VaultURL, "/"), name, apiVersion)
+
+	var response importResponse
+
+	err := t.do(ctx, endpoint, request, &response)
+	if err != nil {
+		return "", fmt.Errorf("azurekeyvault: import %s: %w", name, err)
+	}
+
+	return response.ID, nil
+}
+
+// certificateName returns the name of the certificate in the vault.
+// The name of the previously imported certificate is reused to create a new version instead of a new certificate.
+func (t *Target) certificateName(domain, remoteID string) string {
+	if t.config.CertificateName != "" {
+		return t.config.CertificateName
+	}
+
+	if name := nameFromID(remoteID); name != "" {
+		return name
+	}
+
+	return invalidNameChars.ReplaceAllString(domain, "-")
+}
+
+func (t *Target) do(ctx context.Context, endpoint string, payload, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	token, err := t.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return fmt.Errorf("get token: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr errorResponse
+		if json.Unmarshal(raw, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%s: %s", apiErr.Error.Code, apiErr.Error.Message)
+		}
+
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, string(raw))
+	}
+
+	return json.Unmarshal(raw, result)
+}
+
+// nameFromID extracts the name of a certificate from its ID (https://{vault}/certificates/{name}/{version}).
+func nameFromID(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	u, err := url.Parse(id)
+	if err != nil {
+		return ""
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "certificates" {
+		return ""
+	}
+
+	return parts[1]
+}
+
+type importRequest struct {
+	Value  string             `json:"value"`
+	Policy *certificatePolicy `json:"policy,omitempty"`
+	Tags   map[string]string  `json:"tags,omitempty"`
+}
+
+type certificatePolicy struct {
+	SecretProperties *secretProperties `json:"secret_props,omitempty"`
+}
+
+type secretProperties struct {
+	ContentType string `json:"contentType"`
+}
+
+type importResponse struct {
+	ID string `json:"id"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
@@ -0,0 +1,96 @@
+package azurekeyvault
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type credentialMock struct{}
+
+func (credentialMock) GetToken(_ context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token-" + opts.Scopes[0], ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func setupTest(t *testing.T, config *Config, handler http.HandlerFunc) *Target {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config.VaultURL = server.URL + "/"
+
+	return &Target{
+		config:     config,
+		credential: credentialMock{},
+		httpClient: server.Client(),
+	}
+}
+
+func TestTarget_DeployRemote(t *testing.T) {
+	var paths []string
+
+	var request importRequest
+
+	target := setupTest(t, NewDefaultConfig(), func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer token-"+scope, req.Header.Get("Authorization"))
+		assert.Equal(t, apiVersion, req.URL.Query().Get("api-version"))
+
+		paths = append(paths, req.URL.Path)
+
+		raw, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, &request))
+
+		_, _ = io.WriteString(rw, `{"id":"https://example.vault.azure.net/certificates/my-cert/v1"}`)
+	})
+
+	res := &certificate.Resource{
+		Domain:      "*.example.com",
+		Certificate: []byte("cert\n"),
+		PrivateKey:  []byte("key\n"),
+	}
+
+	id, err := target.DeployRemote(context.Background(), res, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.vault.azure.net/certificates/my-cert/v1", id)
+
+	assert.Equal(t, "key\ncert\n", request.Value)
+	assert.Equal(t, "application/x-pem-file", request.Policy.SecretProperties.ContentType)
+
+	_, err = target.DeployRemote(context.Background(), res, id)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/certificates/--example-com/import", "/certificates/my-cert/import"}, paths)
+}
+
+func TestTarget_DeployRemote_error(t *testing.T) {
+	config := NewDefaultConfig()
+	config.CertificateName = "example"
+
+	target := setupTest(t, config, func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(rw, `{"error":{"code":"Forbidden","message":"access denied"}}`)
+	})
+
+	res := &certificate.Resource{Domain: "example.com", Certificate: []byte("cert"), PrivateKey: []byte("key")}
+
+	_, err := target.DeployRemote(context.Background(), res, "")
+	require.EqualError(t, err, "azurekeyvault: import example: Forbidden: access denied")
+}
+
+func Test_nameFromID(t *testing.T) {
+	assert.Equal(t, "my-cert", nameFromID("https://example.vault.azure.net/certificates/my-cert/v1"))
+	assert.Empty(t, nameFromID("https://example.vault.azure.net/secrets/my-cert/v1"))
+	assert.Empty(t, nameFromID(""))
+}
@@ -0,0 +1,218 @@
+// Package certstore implements a deploy target importing the certificates into the Windows certificate store,
+// and optionally binding them to an IIS site (HTTP.sys).
+package certstore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // the thumbprint of a certificate is a SHA-1 hash.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/log"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// iisAppID the application ID of IIS, used for the HTTP.sys SSL bindings.
+const iisAppID = "{4dc3e181-e14b-4a21-b022-59fc669b0914}"
+
+// Config is used to configure the Windows certificate store target.
+type Config struct {
+	// Store the name of the certificate store of the local machine (ex: My, WebHosting).
+	Store string `yaml:"store"`
+	// PFXFormat the encoding of the PFX file imported into the store: RC2, DES, SHA256.
+	PFXFormat string `yaml:"pfx_format"`
+	// Binding the HTTP.sys SSL binding to update (optional):
+	// `<ip>:<port>` (ex: 0.0.0.0:443), or `<hostname>:<port>` for SNI bindings (ex: example.com:443).
+	Binding string `yaml:"binding"`
+	// AppID the application ID of the SSL binding.
+	AppID string `yaml:"app_id"`
+	// Timeout the timeout of each command.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewDefaultConfig returns a default configuration for the Windows certificate store target.
+func NewDefaultConfig() *Config {
+	return &Config{
+		Store:     "My",
+		PFXFormat: "DES",
+		AppID:     iisAppID,
+		Timeout:   time.Minute,
+	}
+}
+
+// Target imports the certificates into the Windows certificate store.
+type Target struct {
+	config  *Config
+	encoder *pkcs12.Encoder
+
+	run func(ctx context.Context, name string, args ...string) error
+}
+
+// NewTarget returns a Target instance.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("certstore: the configuration is nil")
+	}
+
+	if !supported {
+		return nil, errors.New("certstore: the Windows certificate store is only available on Windows")
+	}
+
+	if config.Store == "" {
+		return nil, errors.New("certstore: missing store")
+	}
+
+	encoder, err := getPFXEncoder(config.PFXFormat)
+	if err != nil {
+		return nil, fmt.Errorf("certstore: %w", err)
+	}
+
+	return &Target{config: config, encoder: encoder, run: runCommand}, nil
+}
+
+// Deploy imports the certificate (and its private key) into the store, then updates the SSL binding.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	password, err := randomPassword()
+	if err != nil {
+		return fmt.Errorf("certstore: %w", err)
+	}
+
+	pfxData, thumbprint, err := t.encodePFX(res, password)
+	if err != nil {
+		return fmt.Errorf("certstore: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "lego-certstore")
+	if err != nil {
+		return fmt.Errorf("certstore: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	pfxFile := filepath.Join(dir, "certificate.pfx")
+
+	err = os.WriteFile(pfxFile, pfxData, 0o600)
+	if err != nil {
+		return fmt.Errorf("certstore: %w", err)
+	}
+
+	err = t.command(ctx, "certutil", "-f", "-p", password, "-importPFX", t.config.Store, pfxFile)
+	if err != nil {
+		return fmt.Errorf("certstore: import: %w", err)
+	}
+
+	log.Infof("[%s] certstore: certificate %s imported into the store %s", res.Domain, thumbprint, t.config.Store)
+
+	if t.config.Binding == "" {
+		return nil
+	}
+
+	err = t.bind(ctx, thumbprint)
+	if err != nil {
+		return fmt.Errorf("certstore: binding %s: %w", t.config.Binding, err)
+	}
+
+	return nil
+}
+
+// bind updates the HTTP.sys SSL binding, the binding is created if it doesn't exist.
+func (t *Target) bind(ctx context.Context, thumbprint string) error {
+	key := "ipport"
+	if host, _, _ := strings.Cut(t.config.Binding, ":"); strings.Trim(host, "0123456789.[]") != "" {
+		key = "hostnameport"
+	}
+
+	args := []string{
+		key + "=" + t.config.Binding,
+		"certhash=" + thumbprint,
+		"certstorename=" + t.config.Store,
+		"appid=" + t.config.AppID,
+	}
+
+	err := t.command(ctx, "netsh", append([]string{"http", "update", "sslcert"}, args...)...)
+	if err == nil {
+		return nil
+	}
+
+	return t.command(ctx, "netsh", append([]string{"http", "add", "sslcert"}, args...)...)
+}
+
+func (t *Target) command(ctx context.Context, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+
+	return t.run(ctx, name, args...)
+}
+
+// encodePFX encodes the certificate, its chain, and its private key, and returns the thumbprint of the certificate.
+func (t *Target) encodePFX(res *certificate.Resource, password string) ([]byte, string, error) {
+	certificates, err := certcrypto.ParsePEMBundle(res.Certificate)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(res.IssuerCertificate) > 0 && len(certificates) == 1 {
+		issuers, errI := certcrypto.ParsePEMBundle(res.IssuerCertificate)
+		if errI != nil {
+			return nil, "", errI
+		}
+
+		certificates = append(certificates, issuers...)
+	}
+
+	privateKey, err := certcrypto.ParsePEMPrivateKey(res.PrivateKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pfxData, err := t.encoder.Encode(privateKey, certificates[0], certificates[1:], password)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sum := sha1.Sum(certificates[0].Raw) //nolint:gosec // the thumbprint of a certificate is a SHA-1 hash.
+
+	return pfxData, strings.ToUpper(hex.EncodeToString(sum[:])), nil
+}
+
+func getPFXEncoder(pfxFormat string) (*pkcs12.Encoder, error) {
+	switch pfxFormat {
+	case "SHA256":
+		return pkcs12.Modern2023, nil
+	case "DES":
+		return pkcs12.LegacyDES, nil
+	case "RC2":
+		return pkcs12.LegacyRC2, nil
+	default:
+		return nil, fmt.Errorf("invalid PFX format: %s", pfxFormat)
+	}
+}
+
+func randomPassword() (string, error) {
+	data := make([]byte, 16)
+
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(data), nil
+}
+
+func runCommand(ctx context.Context, name string, args ...string) error {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
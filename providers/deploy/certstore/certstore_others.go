@@ -0,0 +1,6 @@
+//go:build !windows
+
+package certstore
+
+// supported the Windows certificate store is only available on Windows.
+const supported = false
@@ -0,0 +1,165 @@
+package certstore
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type commandRecorder struct {
+	commands []string
+	fail     map[string]error
+}
+
+func (r *commandRecorder) run(_ context.Context, name string, args ...string) error {
+	cmd := name + " " + strings.Join(args, " ")
+	r.commands = append(r.commands, cmd)
+
+	for prefix, err := range r.fail {
+		if strings.HasPrefix(cmd, prefix) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newTestTarget(t *testing.T, binding string, recorder *commandRecorder) *Target {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.Binding = binding
+
+	encoder, err := getPFXEncoder(config.PFXFormat)
+	require.NoError(t, err)
+
+	return &Target{config: config, encoder: encoder, run: recorder.run}
+}
+
+func TestTarget_Deploy(t *testing.T) {
+	res := generateResource(t)
+
+	recorder := &commandRecorder{}
+
+	target := newTestTarget(t, "", recorder)
+
+	err := target.Deploy(context.Background(), res)
+	require.NoError(t, err)
+
+	require.Len(t, recorder.commands, 1)
+	assert.Regexp(t, `^certutil -f -p [0-9a-f]{32} -importPFX My .+certificate\.pfx$`, recorder.commands[0])
+}
+
+func TestTarget_Deploy_binding(t *testing.T) {
+	res := generateResource(t)
+
+	testCases := []struct {
+		desc     string
+		binding  string
+		fail     map[string]error
+		expected []string
+	}{
+		{
+			desc:    "update IP binding",
+			binding: "0.0.0.0:443",
+			expected: []string{
+				"netsh http update sslcert ipport=0.0.0.0:443 certhash={thumbprint} certstorename=My appid=" + iisAppID,
+			},
+		},
+		{
+			desc:    "add SNI binding",
+			binding: "example.com:443",
+			fail:    map[string]error{"netsh http update": errors.New("not found")},
+			expected: []string{
+				"netsh http update sslcert hostnameport=example.com:443 certhash={thumbprint} certstorename=My appid=" + iisAppID,
+				"netsh http add sslcert hostnameport=example.com:443 certhash={thumbprint} certstorename=My appid=" + iisAppID,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := &commandRecorder{fail: test.fail}
+
+			target := newTestTarget(t, test.binding, recorder)
+
+			err := target.Deploy(context.Background(), res)
+			require.NoError(t, err)
+
+			_, thumbprint, err := target.encodePFX(res, "secret")
+			require.NoError(t, err)
+
+			var expected []string
+			for _, cmd := range test.expected {
+				expected = append(expected, strings.ReplaceAll(cmd, "{thumbprint}", thumbprint))
+			}
+
+			assert.Equal(t, expected, recorder.commands[1:])
+		})
+	}
+}
+
+func TestTarget_encodePFX(t *testing.T) {
+	res := generateResource(t)
+
+	target := newTestTarget(t, "", &commandRecorder{})
+
+	pfxData, thumbprint, err := target.encodePFX(res, "secret")
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^[0-9A-F]{40}$`, thumbprint)
+
+	_, cert, _, err := pkcs12.DecodeChain(pfxData, "secret")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", cert.Subject.CommonName)
+}
+
+func TestNewTarget_unsupported(t *testing.T) {
+	if supported {
+		t.Skip("only on the platforms other than Windows")
+	}
+
+	_, err := NewTarget(NewDefaultConfig())
+	require.EqualError(t, err, "certstore: the Windows certificate store is only available on Windows")
+}
+
+func generateResource(t *testing.T) *certificate.Resource {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return &certificate.Resource{
+		Domain:      "example.com",
+		Certificate: certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der)),
+		PrivateKey:  certcrypto.PEMEncode(key),
+	}
+}
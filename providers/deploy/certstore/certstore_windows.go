@@ -0,0 +1,6 @@
+//go:build windows
+
+package certstore
+
+// supported the Windows certificate store is available.
+const supported = true
@@ -0,0 +1,204 @@
+// Package database implements deploy targets for database servers (PostgreSQL, MySQL, MariaDB):
+// the certificate files are written with the permissions and the ownership required by the server,
+// then the TLS configuration of the server is reloaded.
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+)
+
+// Config is used to configure the database target.
+type Config struct {
+	// Certificate the path where the certificate (and its chain) is written.
+	Certificate string `yaml:"certificate"`
+	// Key the path where the private key is written.
+	Key string `yaml:"key"`
+	// CA the path where the issuer certificate is written (optional).
+	CA string `yaml:"ca"`
+	// Owner the owner of the files (user name or ID).
+	Owner string `yaml:"owner"`
+	// Group the group of the files (group name or ID).
+	Group string `yaml:"group"`
+	// CertificateMode the permissions of the certificate files.
+	CertificateMode os.FileMode `yaml:"certificate_mode"`
+	// KeyMode the permissions of the private key file.
+	KeyMode os.FileMode `yaml:"key_mode"`
+	// ReloadCommand the command (and its arguments) used to reload the TLS configuration of the server.
+	ReloadCommand []string `yaml:"reload_command"`
+	// Timeout the timeout of the reload command.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewPostgreSQLConfig returns a default configuration for PostgreSQL.
+// PostgreSQL refuses a private key readable by other users than the owner (or the group if the owner is root).
+func NewPostgreSQLConfig() *Config {
+	return &Config{
+		Owner:           "postgres",
+		Group:           "postgres",
+		CertificateMode: 0o644,
+		KeyMode:         0o600,
+		ReloadCommand:   []string{"psql", "--no-psqlrc", "--command", "SELECT pg_reload_conf()"},
+		Timeout:         30 * time.Second,
+	}
+}
+
+// NewMySQLConfig returns a default configuration for MySQL (8.0.16 and later).
+func NewMySQLConfig() *Config {
+	return &Config{
+		Owner:           "mysql",
+		Group:           "mysql",
+		CertificateMode: 0o644,
+		KeyMode:         0o600,
+		ReloadCommand:   []string{"mysql", "--execute", "ALTER INSTANCE RELOAD TLS"},
+		Timeout:         30 * time.Second,
+	}
+}
+
+// NewMariaDBConfig returns a default configuration for MariaDB (10.4 and later).
+func NewMariaDBConfig() *Config {
+	return &Config{
+		Owner:           "mysql",
+		Group:           "mysql",
+		CertificateMode: 0o644,
+		KeyMode:         0o600,
+		ReloadCommand:   []string{"mariadb", "--execute", "FLUSH SSL"},
+		Timeout:         30 * time.Second,
+	}
+}
+
+// Target writes the certificate files of a database server, and reloads its TLS configuration.
+type Target struct {
+	config *Config
+	uid    int
+	gid    int
+}
+
+// NewTarget returns a Target instance.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("database: the configuration is nil")
+	}
+
+	if config.Certificate == "" || config.Key == "" {
+		return nil, errors.New("database: missing certificate or key path")
+	}
+
+	uid, err := lookupID(config.Owner, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+
+		return u.Uid, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("database: owner: %w", err)
+	}
+
+	gid, err := lookupID(config.Group, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+
+		return g.Gid, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("database: group: %w", err)
+	}
+
+	return &Target{config: config, uid: uid, gid: gid}, nil
+}
+
+// Deploy writes the certificate files, then reloads the TLS configuration of the server.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	files := []struct {
+		path    string
+		content []byte
+		mode    os.FileMode
+	}{
+		{path: t.config.Certificate, content: res.Certificate, mode: t.config.CertificateMode},
+		{path: t.config.Key, content: res.PrivateKey, mode: t.config.KeyMode},
+		{path: t.config.CA, content: res.IssuerCertificate, mode: t.config.CertificateMode},
+	}
+
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+
+		err := t.writeFile(file.path, file.content, file.mode)
+		if err != nil {
+			return fmt.Errorf("database: %w", err)
+		}
+	}
+
+	if len(t.config.ReloadCommand) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, t.config.ReloadCommand[0], t.config.ReloadCommand[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("database: reload: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// writeFile writes a file through a temporary file, so the server never reads a partial file.
+func (t *Target) writeFile(filename string, content []byte, mode os.FileMode) error {
+	tmp := filename + ".tmp"
+
+	err := os.WriteFile(tmp, content, mode)
+	if err != nil {
+		return err
+	}
+
+	// The permissions of os.WriteFile are modified by the umask.
+	err = os.Chmod(tmp, mode)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if t.uid >= 0 || t.gid >= 0 {
+		err = os.Chown(tmp, t.uid, t.gid)
+		if err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+	}
+
+	return os.Rename(tmp, filename)
+}
+
+// lookupID returns the numeric ID of a user or a group, -1 if the name is empty.
+func lookupID(name string, lookup func(name string) (string, error)) (int, error) {
+	if name == "" {
+		return -1, nil
+	}
+
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	id, err := lookup(name)
+	if err != nil {
+		return -1, err
+	}
+
+	return strconv.Atoi(id)
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget_Deploy(t *testing.T) {
+	dir := t.TempDir()
+
+	config := NewPostgreSQLConfig()
+	config.Certificate = filepath.Join(dir, "server.crt")
+	config.Key = filepath.Join(dir, "server.key")
+	config.CA = filepath.Join(dir, "ca.crt")
+	config.Owner = strconv.Itoa(os.Getuid())
+	config.Group = strconv.Itoa(os.Getgid())
+	config.ReloadCommand = []string{"true"}
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{
+		Domain:            "db.example.com",
+		Certificate:       []byte("cert"),
+		PrivateKey:        []byte("key"),
+		IssuerCertificate: []byte("issuer"),
+	}
+
+	err = target.Deploy(context.Background(), res)
+	require.NoError(t, err)
+
+	assertFile(t, config.Certificate, "cert", 0o644)
+	assertFile(t, config.Key, "key", 0o600)
+	assertFile(t, config.CA, "issuer", 0o644)
+}
+
+func TestTarget_Deploy_reloadError(t *testing.T) {
+	dir := t.TempDir()
+
+	config := NewMySQLConfig()
+	config.Certificate = filepath.Join(dir, "server-cert.pem")
+	config.Key = filepath.Join(dir, "server-key.pem")
+	config.Owner = ""
+	config.Group = ""
+	config.ReloadCommand = []string{"false"}
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	err = target.Deploy(context.Background(), &certificate.Resource{Certificate: []byte("cert"), PrivateKey: []byte("key")})
+	require.ErrorContains(t, err, "database: reload: exit status 1")
+}
+
+func TestNewTarget_unknownOwner(t *testing.T) {
+	config := NewMariaDBConfig()
+	config.Certificate = "/etc/mysql/ssl/server-cert.pem"
+	config.Key = "/etc/mysql/ssl/server-key.pem"
+	config.Owner = "lego-unknown-user"
+
+	_, err := NewTarget(config)
+	require.ErrorContains(t, err, "database: owner:")
+}
+
+func assertFile(t *testing.T, filename, content string, mode os.FileMode) {
+	t.Helper()
+
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, mode, info.Mode().Perm())
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, content, string(data))
+}
@@ -0,0 +1,253 @@
+// Package deploy implements the deployment of the certificates to external targets (web servers, proxies, stores).
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/log"
+	"gopkg.in/yaml.v2"
+)
+
+// Target deploys a certificate to an external system.
+type Target interface {
+	Deploy(ctx context.Context, res *certificate.Resource) error
+}
+
+// RemoteTarget deploys a certificate to an external system storing it as a remote object (ex: a cloud certificate store).
+// The ID of the remote object is kept between the deployments, so the next deployments update the object instead of creating a new one.
+type RemoteTarget interface {
+	Target
+
+	// DeployRemote deploys the certificate and returns the ID of the remote object.
+	// The remote object is updated if remoteID is not empty.
+	DeployRemote(ctx context.Context, res *certificate.Resource, remoteID string) (string, error)
+}
+
+// Config the deployment configuration.
+//
+//	targets:
+//	  - name: edge
+//	    type: haproxy
+//	    domains: [example.com]
+//	    config:
+//	      socket: unix:/run/haproxy/admin.sock
+//	      certificate: /etc/haproxy/certs/example.com.pem
+type Config struct {
+	Targets []*TargetConfig `yaml:"targets"`
+}
+
+// LoadConfig reads a deployment configuration file (YAML).
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("deploy: %w", err)
+	}
+
+	cfg := &Config{}
+
+	err = yaml.UnmarshalStrict(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("deploy: %s: %w", filename, err)
+	}
+
+	return cfg, nil
+}
+
+// ParseTarget creates a target from its short definition: `type:option=value,option=value` (ex: `file:certificate=/etc/ssl/{domain}.crt,mode=0644`).
+// The options are the fields of the `config` section, except `name` and `domains` which define the name and the domains of the target.
+// A list is separated by semicolons (ex: `domains=example.com;example.org`).
+func ParseTarget(value string) (*TargetConfig, error) {
+	targetType, options, _ := strings.Cut(value, ":")
+	if targetType == "" {
+		return nil, fmt.Errorf("deploy: invalid target: %q", value)
+	}
+
+	raw := map[string]interface{}{"type": targetType}
+	config := map[string]interface{}{}
+
+	for _, option := range strings.Split(options, ",") {
+		if strings.TrimSpace(option) == "" {
+			continue
+		}
+
+		key, val, found := strings.Cut(option, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("deploy: %s: invalid option: %q, expected 'option=value'", targetType, option)
+		}
+
+		key = strings.TrimSpace(key)
+
+		switch key {
+		case "name":
+			raw[key] = val
+		case "domains":
+			raw[key] = strings.Split(val, ";")
+		default:
+			config[key] = parseOptionValue(val)
+		}
+	}
+
+	if len(config) > 0 {
+		raw["config"] = config
+	}
+
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("deploy: %s: %w", targetType, err)
+	}
+
+	target := &TargetConfig{}
+
+	err = yaml.UnmarshalStrict(data, target)
+	if err != nil {
+		return nil, fmt.Errorf("deploy: %w", err)
+	}
+
+	return target, nil
+}
+
+// parseOptionValue returns the value of an option with its YAML type (ex: 0644, true, 30s), or a list if it contains semicolons.
+func parseOptionValue(value string) interface{} {
+	if strings.Contains(value, ";") {
+		var values []interface{}
+		for _, v := range strings.Split(value, ";") {
+			values = append(values, parseOptionValue(v))
+		}
+
+		return values
+	}
+
+	var v interface{}
+
+	err := yaml.Unmarshal([]byte(value), &v)
+	if err != nil {
+		return value
+	}
+
+	switch v.(type) {
+	case int, float64, bool:
+		return v
+	default:
+		return value
+	}
+}
+
+// Deploy deploys the certificate to all the targets matching its domain.
+// All the targets are called even if a deployment fails.
+// The remote object IDs, indexed by target name, are read from and updated in remoteIDs (can be nil).
+func (c *Config) Deploy(ctx context.Context, res *certificate.Resource, remoteIDs map[string]string) error {
+	var errs []error
+
+	for _, target := range c.Targets {
+		if !target.Match(res.Domain) {
+			continue
+		}
+
+		err := target.deploy(ctx, res, remoteIDs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("deploy: %s (%s): %w", target.Name, target.Type, err))
+			continue
+		}
+
+		log.Infof("[%s] deploy: certificate deployed to %s (%s)", res.Domain, target.Name, target.Type)
+	}
+
+	return errors.Join(errs...)
+}
+
+// TargetConfig the configuration of a deployment target.
+type TargetConfig struct {
+	// Name the name of the target (used in the logs).
+	Name string `yaml:"name"`
+	// Type the type of the target (ex: haproxy).
+	Type string `yaml:"type"`
+	// Domains the main domains of the certificates deployed to the target (all the certificates if empty).
+	Domains []string `yaml:"domains"`
+
+	target Target
+}
+
+// NewTargetConfig creates the configuration of a target with its implementation (ex: a custom Target).
+// The name is the type if empty.
+func NewTargetConfig(name, typ string, domains []string, target Target) *TargetConfig {
+	if name == "" {
+		name = typ
+	}
+
+	return &TargetConfig{Name: name, Type: typ, Domains: domains, target: target}
+}
+
+// UnmarshalYAML creates the target from its type and its `config` section.
+func (t *TargetConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var base targetConfig[interface{}]
+
+	err := unmarshal(&base)
+	if err != nil {
+		return err
+	}
+
+	t.Name = base.Name
+	t.Type = base.Type
+	t.Domains = base.Domains
+
+	if t.Name == "" {
+		t.Name = t.Type
+	}
+
+	t.target, err = newTarget(t.Type, unmarshal)
+	if err != nil {
+		return fmt.Errorf("target %s: %w", t.Name, err)
+	}
+
+	return nil
+}
+
+// Match returns true if the certificate of the domain must be deployed to the target.
+func (t *TargetConfig) Match(domain string) bool {
+	if len(t.Domains) == 0 {
+		return true
+	}
+
+	for _, d := range t.Domains {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *TargetConfig) deploy(ctx context.Context, res *certificate.Resource, remoteIDs map[string]string) error {
+	remote, ok := t.target.(RemoteTarget)
+	if !ok || remoteIDs == nil {
+		return t.target.Deploy(ctx, res)
+	}
+
+	id, err := remote.DeployRemote(ctx, res, remoteIDs[t.Name])
+	if err != nil {
+		return err
+	}
+
+	remoteIDs[t.Name] = id
+
+	return nil
+}
+
+// targetConfig the raw configuration of a target, with a typed `config` section.
+type targetConfig[T any] struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	Domains []string `yaml:"domains"`
+	Config  *T       `yaml:"config"`
+}
+
+// decodeConfig fills the configuration of a target from its `config` section.
+// The default values of the configuration are kept if they are not defined.
+func decodeConfig[T any](unmarshal func(interface{}) error, cfg *T) error {
+	return unmarshal(&targetConfig[T]{Config: cfg})
+}
@@ -0,0 +1,285 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/providers/deploy/file"
+	"github.com/pya789/lego/v4/providers/deploy/haproxy"
+	"github.com/pya789/lego/v4/providers/deploy/webserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+type targetMock struct {
+	err      error
+	deployed []string
+}
+
+func (t *targetMock) Deploy(_ context.Context, res *certificate.Resource) error {
+	t.deployed = append(t.deployed, res.Domain)
+	return t.err
+}
+
+type remoteTargetMock struct {
+	targetMock
+	ids []string
+}
+
+func (t *remoteTargetMock) DeployRemote(ctx context.Context, res *certificate.Resource, remoteID string) (string, error) {
+	t.ids = append(t.ids, remoteID)
+	return "id-" + res.Domain, t.Deploy(ctx, res)
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("fixtures", "deploy.yml"))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Targets, 3)
+
+	edge := cfg.Targets[0]
+	assert.Equal(t, "edge", edge.Name)
+	assert.Equal(t, "haproxy", edge.Type)
+	assert.Equal(t, []string{"example.com", "example.org"}, edge.Domains)
+
+	assert.IsType(t, &haproxy.Target{}, edge.target)
+
+	other := cfg.Targets[1]
+	assert.Equal(t, "haproxy", other.Name)
+	assert.Empty(t, other.Domains)
+
+	assert.IsType(t, &webserver.Target{}, cfg.Targets[2].target)
+}
+
+func Test_decodeConfig(t *testing.T) {
+	content := `
+type: haproxy
+config:
+  socket: unix:/run/haproxy/admin.sock
+  certificate: /etc/haproxy/certs/example.com.pem
+  timeout: 30s
+`
+
+	cfg := haproxy.NewDefaultConfig()
+
+	err := yaml.Unmarshal([]byte(content), &targetConfig[haproxy.Config]{Config: cfg})
+	require.NoError(t, err)
+
+	expected := &haproxy.Config{
+		Socket:      "unix:/run/haproxy/admin.sock",
+		Certificate: "/etc/haproxy/certs/example.com.pem",
+		WriteFile:   true,
+		Timeout:     30 * time.Second,
+	}
+
+	assert.Equal(t, expected, cfg)
+}
+
+func TestLoadConfig_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		content  string
+		expected string
+	}{
+		{
+			desc:     "unknown type",
+			content:  "targets:\n  - name: foo\n    type: foo\n",
+			expected: "target foo: unrecognized deploy target: foo",
+		},
+		{
+			desc:     "invalid config",
+			content:  "targets:\n  - type: haproxy\n    config:\n      certificate: /etc/haproxy/certs/example.com.pem\n",
+			expected: "target haproxy: haproxy: missing socket",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(t.TempDir(), "deploy.yml")
+
+			err := os.WriteFile(filename, []byte(test.content), 0o600)
+			require.NoError(t, err)
+
+			_, err = LoadConfig(filename)
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	target, err := ParseTarget("file:name=web,domains=example.com;example.org,certificate=/etc/ssl/{domain}.crt,key=/etc/ssl/{domain}.key,certificate_mode=0640,group=33")
+	require.NoError(t, err)
+
+	assert.Equal(t, "web", target.Name)
+	assert.Equal(t, "file", target.Type)
+	assert.Equal(t, []string{"example.com", "example.org"}, target.Domains)
+
+	assert.IsType(t, &file.Target{}, target.target)
+
+	target, err = ParseTarget("haproxy:socket=unix:/run/haproxy/admin.sock,certificate=/etc/haproxy/certs/example.com.pem,timeout=30s")
+	require.NoError(t, err)
+
+	assert.Equal(t, "haproxy", target.Name)
+	assert.Empty(t, target.Domains)
+}
+
+func TestParseTarget_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected string
+	}{
+		{
+			desc:     "missing type",
+			value:    ":certificate=/etc/ssl/example.com.crt",
+			expected: `deploy: invalid target: ":certificate=/etc/ssl/example.com.crt"`,
+		},
+		{
+			desc:     "invalid option",
+			value:    "file:certificate",
+			expected: `deploy: file: invalid option: "certificate", expected 'option=value'`,
+		},
+		{
+			desc:     "unknown option",
+			value:    "file:certificate=/etc/ssl/example.com.crt,foo=bar",
+			expected: "field foo not found",
+		},
+		{
+			desc:     "unknown type",
+			value:    "foo:bar=baz",
+			expected: "target foo: unrecognized deploy target: foo",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseTarget(test.value)
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+type customConfig struct {
+	Path string `yaml:"path"`
+	Mode int    `yaml:"mode"`
+}
+
+type customTarget struct {
+	targetMock
+	config *customConfig
+}
+
+func TestRegisterTarget(t *testing.T) {
+	var targets []*customTarget
+
+	RegisterTarget("custom", func(unmarshal func(interface{}) error) (Target, error) {
+		cfg := &customConfig{Mode: 0o600}
+
+		err := unmarshal(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		target := &customTarget{config: cfg}
+		targets = append(targets, target)
+
+		return target, nil
+	})
+
+	assert.Panics(t, func() { RegisterTarget("custom", nil) })
+
+	cfg := &Config{}
+
+	target, err := ParseTarget("custom:path=/srv/certs,domains=example.com")
+	require.NoError(t, err)
+
+	cfg.Targets = append(cfg.Targets, target)
+
+	target, err = ParseTarget("custom")
+	require.NoError(t, err)
+
+	cfg.Targets = append(cfg.Targets, target)
+
+	_, err = ParseTarget("custom:foo=bar")
+	require.ErrorContains(t, err, "field foo not found")
+
+	err = cfg.Deploy(context.Background(), &certificate.Resource{Domain: "example.com"}, nil)
+	require.NoError(t, err)
+
+	err = cfg.Deploy(context.Background(), &certificate.Resource{Domain: "example.org"}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, targets, 2)
+
+	assert.Equal(t, &customConfig{Path: "/srv/certs", Mode: 0o600}, targets[0].config)
+	assert.Equal(t, []string{"example.com"}, targets[0].deployed)
+
+	assert.Equal(t, &customConfig{Mode: 0o600}, targets[1].config)
+	assert.Equal(t, []string{"example.com", "example.org"}, targets[1].deployed)
+}
+
+func TestNewTargetConfig(t *testing.T) {
+	custom := &targetMock{}
+
+	cfg := &Config{Targets: []*TargetConfig{
+		NewTargetConfig("", "custom", []string{"example.com"}, custom),
+	}}
+
+	assert.Equal(t, "custom", cfg.Targets[0].Name)
+
+	err := cfg.Deploy(context.Background(), &certificate.Resource{Domain: "example.com"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com"}, custom.deployed)
+}
+
+func TestConfig_Deploy(t *testing.T) {
+	all := &targetMock{}
+	selected := &targetMock{err: errors.New("boom")}
+
+	cfg := &Config{Targets: []*TargetConfig{
+		{Name: "all", Type: "mock", target: all},
+		{Name: "selected", Type: "mock", Domains: []string{"Example.org"}, target: selected},
+	}}
+
+	err := cfg.Deploy(context.Background(), &certificate.Resource{Domain: "example.com"}, nil)
+	require.NoError(t, err)
+
+	err = cfg.Deploy(context.Background(), &certificate.Resource{Domain: "example.org"}, nil)
+	require.EqualError(t, err, "deploy: selected (mock): boom")
+
+	assert.Equal(t, []string{"example.com", "example.org"}, all.deployed)
+	assert.Equal(t, []string{"example.org"}, selected.deployed)
+}
+
+func TestConfig_Deploy_remote(t *testing.T) {
+	local := &targetMock{}
+	remote := &remoteTargetMock{}
+
+	cfg := &Config{Targets: []*TargetConfig{
+		{Name: "local", Type: "mock", target: local},
+		{Name: "remote", Type: "mock", target: remote},
+	}}
+
+	remoteIDs := map[string]string{}
+
+	err := cfg.Deploy(context.Background(), &certificate.Resource{Domain: "example.com"}, remoteIDs)
+	require.NoError(t, err)
+
+	err = cfg.Deploy(context.Background(), &certificate.Resource{Domain: "example.com"}, remoteIDs)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"remote": "id-example.com"}, remoteIDs)
+	assert.Equal(t, []string{"", "id-example.com"}, remote.ids)
+	assert.Equal(t, []string{"example.com", "example.com"}, local.deployed)
+}
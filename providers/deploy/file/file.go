@@ -0,0 +1,190 @@
+// Package file implements a deploy target copying the certificate files to local paths, with their permissions and owner.
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pya789/lego/v4/certificate"
+)
+
+// Config is used to configure the file target.
+// The paths can contain the placeholder {domain}, replaced by the main domain of the certificate.
+type Config struct {
+	// Certificate the path of the certificate (and its chain).
+	Certificate string `yaml:"certificate"`
+	// Key the path of the private key (optional).
+	Key string `yaml:"key"`
+	// CA the path of the issuer certificate (optional).
+	CA string `yaml:"ca"`
+	// Bundle the path of a file containing the private key and the certificate, ex: for HAProxy (optional).
+	Bundle string `yaml:"bundle"`
+	// Owner the owner of the files (user name or ID, optional).
+	Owner string `yaml:"owner"`
+	// Group the group of the files (group name or ID, optional).
+	Group string `yaml:"group"`
+	// CertificateMode the permissions of the certificate files.
+	CertificateMode os.FileMode `yaml:"certificate_mode"`
+	// KeyMode the permissions of the files containing the private key.
+	KeyMode os.FileMode `yaml:"key_mode"`
+}
+
+// NewDefaultConfig returns a default configuration for the file target.
+func NewDefaultConfig() *Config {
+	return &Config{
+		CertificateMode: 0o644,
+		KeyMode:         0o600,
+	}
+}
+
+// Target copies the certificate files to local paths.
+type Target struct {
+	config *Config
+	uid    int
+	gid    int
+}
+
+// NewTarget returns a Target instance.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("file: the configuration is nil")
+	}
+
+	if config.Certificate == "" && config.Bundle == "" {
+		return nil, errors.New("file: missing certificate or bundle path")
+	}
+
+	uid, err := lookupID(config.Owner, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+
+		return u.Uid, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("file: owner: %w", err)
+	}
+
+	gid, err := lookupID(config.Group, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+
+		return g.Gid, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("file: group: %w", err)
+	}
+
+	return &Target{config: config, uid: uid, gid: gid}, nil
+}
+
+// Deploy writes the certificate files.
+// Each file is written to a temporary file renamed at the end: the readers never see a partial file.
+func (t *Target) Deploy(_ context.Context, res *certificate.Resource) error {
+	if len(res.PrivateKey) == 0 && (t.config.Key != "" || t.config.Bundle != "") {
+		return errors.New("file: the private key is not available (CSR)")
+	}
+
+	files := []struct {
+		path    string
+		content []byte
+		mode    os.FileMode
+	}{
+		{path: t.config.Certificate, content: res.Certificate, mode: t.config.CertificateMode},
+		{path: t.config.Key, content: res.PrivateKey, mode: t.config.KeyMode},
+		{path: t.config.CA, content: res.IssuerCertificate, mode: t.config.CertificateMode},
+		{path: t.config.Bundle, content: bundle(res), mode: t.config.KeyMode},
+	}
+
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+
+		filename := strings.ReplaceAll(f.path, "{domain}", sanitizedDomain(res.Domain))
+
+		err := t.writeFile(filename, f.content, f.mode)
+		if err != nil {
+			return fmt.Errorf("file: %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+func (t *Target) writeFile(filename string, content []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp.Name(), mode)
+	if err != nil {
+		return err
+	}
+
+	if t.uid >= 0 || t.gid >= 0 {
+		err = os.Chown(tmp.Name(), t.uid, t.gid)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// bundle returns the private key followed by the certificate (and its chain).
+func bundle(res *certificate.Resource) []byte {
+	content := append([]byte{}, res.PrivateKey...)
+
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+
+	return append(content, res.Certificate...)
+}
+
+// lookupID returns the numeric ID of a user or a group, -1 if the name is empty.
+func lookupID(name string, lookup func(name string) (string, error)) (int, error) {
+	if name == "" {
+		return -1, nil
+	}
+
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	id, err := lookup(name)
+	if err != nil {
+		return -1, err
+	}
+
+	return strconv.Atoi(id)
+}
+
+// sanitizedDomain returns the domain usable in a file name, like in the data directory (ex: *.example.com: _.example.com).
+func sanitizedDomain(domain string) string {
+	return strings.NewReplacer(":", "-", "*", "_").Replace(domain)
+}
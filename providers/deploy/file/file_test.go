@@ -0,0 +1,110 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget_Deploy(t *testing.T) {
+	dir := t.TempDir()
+
+	config := NewDefaultConfig()
+	config.Certificate = filepath.Join(dir, "{domain}.crt")
+	config.Key = filepath.Join(dir, "{domain}.key")
+	config.CA = filepath.Join(dir, "{domain}.issuer.crt")
+	config.Bundle = filepath.Join(dir, "{domain}.pem")
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{
+		Domain:            "*.example.com",
+		Certificate:       []byte("CERTIFICATE\n"),
+		PrivateKey:        []byte("KEY"),
+		IssuerCertificate: []byte("ISSUER\n"),
+	}
+
+	err = target.Deploy(context.Background(), res)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"_.example.com.crt":        "CERTIFICATE\n",
+		"_.example.com.key":        "KEY",
+		"_.example.com.issuer.crt": "ISSUER\n",
+		"_.example.com.pem":        "KEY\nCERTIFICATE\n",
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, len(expected))
+
+	for name, content := range expected {
+		data, errR := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, errR)
+
+		assert.Equal(t, content, string(data), name)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "_.example.com.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	info, err = os.Stat(filepath.Join(dir, "_.example.com.key"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestTarget_Deploy_noPrivateKey(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Certificate = filepath.Join(t.TempDir(), "example.com.crt")
+	config.Key = filepath.Join(t.TempDir(), "example.com.key")
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	err = target.Deploy(context.Background(), &certificate.Resource{Domain: "example.com", Certificate: []byte("CERTIFICATE")})
+	require.EqualError(t, err, "file: the private key is not available (CSR)")
+}
+
+func TestNewTarget_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *Config
+		expected string
+	}{
+		{
+			desc:     "nil",
+			expected: "file: the configuration is nil",
+		},
+		{
+			desc:     "missing paths",
+			config:   NewDefaultConfig(),
+			expected: "file: missing certificate or bundle path",
+		},
+		{
+			desc:     "unknown owner",
+			config:   &Config{Certificate: "example.com.crt", Owner: "lego-unknown-user"},
+			expected: "file: owner: user: unknown user lego-unknown-user",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewTarget(test.config)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Package haproxy implements a deploy target updating the certificates of HAProxy through its runtime API, without reload.
+package haproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+)
+
+// Config is used to configure the HAProxy target.
+type Config struct {
+	// Socket the address of the runtime API: `unix:<path>`, `<path>`, or `<host>:<port>`.
+	Socket string `yaml:"socket"`
+	// Certificate the path of the certificate in the HAProxy configuration.
+	Certificate string `yaml:"certificate"`
+	// CrtList the crt-list where a new certificate is added (optional).
+	CrtList string `yaml:"crt_list"`
+	// WriteFile writes the certificate to its path, so it's used after a restart of HAProxy.
+	WriteFile bool `yaml:"write_file"`
+	// Timeout the timeout of each command.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewDefaultConfig returns a default configuration for the HAProxy target.
+func NewDefaultConfig() *Config {
+	return &Config{
+		WriteFile: true,
+		Timeout:   10 * time.Second,
+	}
+}
+
+// Target updates a certificate of HAProxy through its runtime API.
+type Target struct {
+	config *Config
+	dialer *net.Dialer
+}
+
+// NewTarget returns a Target instance configured for HAProxy.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("haproxy: the configuration is nil")
+	}
+
+	if config.Socket == "" {
+		return nil, errors.New("haproxy: missing socket")
+	}
+
+	if config.Certificate == "" {
+		return nil, errors.New("haproxy: missing certificate path")
+	}
+
+	return &Target{config: config, dialer: &net.Dialer{}}, nil
+}
+
+// Deploy updates the certificate in a transaction (`set ssl cert`, then `commit ssl cert`).
+// The certificate is created (`new ssl cert`) and added to the crt-list if it's unknown by HAProxy.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	payload := pemPayload(res)
+
+	if t.config.WriteFile {
+		err := os.WriteFile(t.config.Certificate, payload, 0o600)
+		if err != nil {
+			return fmt.Errorf("haproxy: write certificate: %w", err)
+		}
+	}
+
+	err := t.setCertificate(ctx, payload)
+	if err != nil && t.config.CrtList != "" && isUnknownCertificate(err) {
+		err = t.addCertificate(ctx, payload)
+	}
+
+	if err != nil {
+		return fmt.Errorf("haproxy: %w", err)
+	}
+
+	return nil
+}
+
+func (t *Target) setCertificate(ctx context.Context, payload []byte) error {
+	resp, err := t.command(ctx, fmt.Sprintf("set ssl cert %s <<\n%s\n", t.config.Certificate, payload))
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(resp, "Transaction created") && !strings.Contains(resp, "Transaction updated") {
+		return &commandError{command: "set ssl cert", response: resp}
+	}
+
+	return t.commit(ctx)
+}
+
+func (t *Target) addCertificate(ctx context.Context, payload []byte) error {
+	resp, err := t.command(ctx, "new ssl cert "+t.config.Certificate+"\n")
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(resp, "New empty certificate store") {
+		return &commandError{command: "new ssl cert", response: resp}
+	}
+
+	err = t.setCertificate(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err = t.command(ctx, fmt.Sprintf("add ssl crt-list %s %s\n", t.config.CrtList, t.config.Certificate))
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(resp, "Success!") {
+		return &commandError{command: "add ssl crt-list", response: resp}
+	}
+
+	return nil
+}
+
+func (t *Target) commit(ctx context.Context) error {
+	resp, err := t.command(ctx, "commit ssl cert "+t.config.Certificate+"\n")
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(resp, "Success!") {
+		_, _ = t.command(ctx, "abort ssl cert "+t.config.Certificate+"\n")
+
+		return &commandError{command: "commit ssl cert", response: resp}
+	}
+
+	return nil
+}
+
+// command sends a command to the runtime API (one connection by command) and returns the response.
+func (t *Target) command(ctx context.Context, cmd string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+
+	network, address := parseSocket(t.config.Socket)
+
+	conn, err := t.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return "", fmt.Errorf("dial runtime API: %w", err)
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	_, err = io.WriteString(conn, cmd)
+	if err != nil {
+		return "", fmt.Errorf("send command: %w", err)
+	}
+
+	resp, err := io.ReadAll(conn)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	return strings.TrimSpace(string(resp)), nil
+}
+
+// pemPayload the certificate (and its chain) followed by the private key.
+func pemPayload(res *certificate.Resource) []byte {
+	payload := bytes.TrimSpace(res.Certificate)
+	payload = append(payload, '\n')
+	payload = append(payload, bytes.TrimSpace(res.PrivateKey)...)
+
+	return append(payload, '\n')
+}
+
+func parseSocket(socket string) (string, string) {
+	if path, ok := strings.CutPrefix(socket, "unix:"); ok {
+		return "unix", path
+	}
+
+	if strings.HasPrefix(socket, "/") {
+		return "unix", socket
+	}
+
+	return "tcp", strings.TrimPrefix(socket, "tcp:")
+}
+
+type commandError struct {
+	command  string
+	response string
+}
+
+func (e *commandError) Error() string {
+	return fmt.Sprintf("%s: %s", e.command, e.response)
+}
+
+func isUnknownCertificate(err error) bool {
+	var cmdErr *commandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+
+	return strings.Contains(cmdErr.response, "Can't replace a certificate which is not referenced by the configuration") ||
+		strings.Contains(cmdErr.response, "unknown certificate")
+}
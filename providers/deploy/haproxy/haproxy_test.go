@@ -0,0 +1,190 @@
+package haproxy
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRuntimeAPI a minimal HAProxy runtime API (one command by connection).
+type fakeRuntimeAPI struct {
+	mu       sync.Mutex
+	known    bool
+	commands []string
+	payload  string
+}
+
+func (f *fakeRuntimeAPI) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fields := strings.Fields(line)
+	cmd := strings.Join(fields[:3], " ")
+	f.commands = append(f.commands, cmd)
+
+	switch cmd {
+	case "set ssl cert":
+		var payload strings.Builder
+		for {
+			l, errR := reader.ReadString('\n')
+			if errR != nil || l == "\n" {
+				break
+			}
+
+			payload.WriteString(l)
+		}
+
+		if !f.known {
+			_, _ = io.WriteString(conn, "Can't replace a certificate which is not referenced by the configuration!\n")
+			return
+		}
+
+		f.payload = payload.String()
+		_, _ = io.WriteString(conn, "Transaction created for certificate "+fields[3]+"!\n")
+
+	case "new ssl cert":
+		f.known = true
+		_, _ = io.WriteString(conn, "New empty certificate store '"+fields[3]+"'!\n")
+
+	case "commit ssl cert":
+		_, _ = io.WriteString(conn, "Committing "+fields[3]+"\nSuccess!\n")
+
+	case "add ssl crt-list":
+		_, _ = io.WriteString(conn, "Inserting certificate '"+fields[4]+"' in crt-list '"+fields[3]+"'.\nSuccess!\n")
+
+	default:
+		_, _ = io.WriteString(conn, "Unknown command.\n")
+	}
+}
+
+func setupTest(t *testing.T, known bool) (*fakeRuntimeAPI, string) {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	api := &fakeRuntimeAPI{known: known}
+
+	go func() {
+		for {
+			conn, errA := listener.Accept()
+			if errA != nil {
+				return
+			}
+
+			go api.handle(conn)
+		}
+	}()
+
+	return api, socket
+}
+
+func TestTarget_Deploy(t *testing.T) {
+	api, socket := setupTest(t, true)
+
+	certPath := filepath.Join(t.TempDir(), "example.com.pem")
+
+	config := NewDefaultConfig()
+	config.Socket = "unix:" + socket
+	config.Certificate = certPath
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{Domain: "example.com", Certificate: []byte("CERT\n"), PrivateKey: []byte("KEY\n")}
+
+	err = target.Deploy(context.Background(), res)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"set ssl cert", "commit ssl cert"}, api.commands)
+	assert.Equal(t, "CERT\nKEY\n", api.payload)
+
+	data, err := os.ReadFile(certPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "CERT\nKEY\n", string(data))
+}
+
+func TestTarget_Deploy_newCertificate(t *testing.T) {
+	api, socket := setupTest(t, false)
+
+	config := NewDefaultConfig()
+	config.Socket = socket
+	config.Certificate = "/etc/haproxy/certs/example.com.pem"
+	config.CrtList = "/etc/haproxy/crt-list.txt"
+	config.WriteFile = false
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{Domain: "example.com", Certificate: []byte("CERT"), PrivateKey: []byte("KEY")}
+
+	err = target.Deploy(context.Background(), res)
+	require.NoError(t, err)
+
+	expected := []string{"set ssl cert", "new ssl cert", "set ssl cert", "commit ssl cert", "add ssl crt-list"}
+	assert.Equal(t, expected, api.commands)
+}
+
+func TestTarget_Deploy_unknownCertificate(t *testing.T) {
+	_, socket := setupTest(t, false)
+
+	config := NewDefaultConfig()
+	config.Socket = socket
+	config.Certificate = "/etc/haproxy/certs/example.com.pem"
+	config.WriteFile = false
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{Domain: "example.com", Certificate: []byte("CERT"), PrivateKey: []byte("KEY")}
+
+	err = target.Deploy(context.Background(), res)
+	require.EqualError(t, err, "haproxy: set ssl cert: Can't replace a certificate which is not referenced by the configuration!")
+}
+
+func Test_parseSocket(t *testing.T) {
+	testCases := []struct {
+		socket          string
+		expectedNetwork string
+		expectedAddress string
+	}{
+		{socket: "unix:/run/haproxy/admin.sock", expectedNetwork: "unix", expectedAddress: "/run/haproxy/admin.sock"},
+		{socket: "/run/haproxy/admin.sock", expectedNetwork: "unix", expectedAddress: "/run/haproxy/admin.sock"},
+		{socket: "127.0.0.1:9999", expectedNetwork: "tcp", expectedAddress: "127.0.0.1:9999"},
+		{socket: "tcp:127.0.0.1:9999", expectedNetwork: "tcp", expectedAddress: "127.0.0.1:9999"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.socket, func(t *testing.T) {
+			t.Parallel()
+
+			network, address := parseSocket(test.socket)
+			assert.Equal(t, test.expectedNetwork, network)
+			assert.Equal(t, test.expectedAddress, address)
+		})
+	}
+}
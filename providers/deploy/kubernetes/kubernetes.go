@@ -0,0 +1,236 @@
+// Package kubernetes implements a deploy target storing the certificates in Kubernetes TLS secrets (kubernetes.io/tls).
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+)
+
+// The files of the service account of a pod (in-cluster configuration).
+const (
+	serviceAccountTokenFile     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAFile        = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// Config is used to configure the Kubernetes target.
+// By default, the target uses the service account of the pod (in-cluster configuration).
+type Config struct {
+	// APIServer the URL of the API server (default: https://$KUBERNETES_SERVICE_HOST:$KUBERNETES_SERVICE_PORT).
+	APIServer string `yaml:"api_server"`
+	// Token the bearer token (default: the content of TokenFile).
+	Token string `yaml:"token"`
+	// TokenFile the path of the file containing the bearer token (default: the token of the service account).
+	TokenFile string `yaml:"token_file"`
+	// CAFile the path of the CA certificate of the API server (default: the CA of the service account).
+	CAFile string `yaml:"ca_file"`
+	// Namespace the namespace of the secret (default: the namespace of the pod).
+	Namespace string `yaml:"namespace"`
+	// Secret the name of the secret, can contain the placeholder {domain} (ex: tls-{domain}).
+	Secret string `yaml:"secret"`
+	// Labels the labels of the secret (optional).
+	Labels map[string]string `yaml:"labels"`
+	// Timeout the timeout of the requests to the API server.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewDefaultConfig returns a default configuration for the Kubernetes target.
+func NewDefaultConfig() *Config {
+	config := &Config{
+		TokenFile: serviceAccountTokenFile,
+		CAFile:    serviceAccountCAFile,
+		Timeout:   30 * time.Second,
+	}
+
+	if host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"); host != "" && port != "" {
+		config.APIServer = "https://" + net.JoinHostPort(host, port)
+	}
+
+	if namespace, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+		config.Namespace = strings.TrimSpace(string(namespace))
+	}
+
+	return config
+}
+
+// Target stores the certificates in TLS secrets.
+type Target struct {
+	config     *Config
+	baseURL    *url.URL
+	httpClient *http.Client
+}
+
+// NewTarget returns a Target instance.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("kubernetes: the configuration is nil")
+	}
+
+	if config.APIServer == "" {
+		return nil, errors.New("kubernetes: missing API server (not running in a cluster)")
+	}
+
+	if config.Namespace == "" || config.Secret == "" {
+		return nil, errors.New("kubernetes: missing namespace or secret name")
+	}
+
+	baseURL, err := url.Parse(config.APIServer)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: invalid API server: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.CAFile != "" {
+		pem, errR := os.ReadFile(config.CAFile)
+		if errR != nil {
+			return nil, fmt.Errorf("kubernetes: CA: %w", errR)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("kubernetes: CA: no certificate found in %s", config.CAFile)
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &Target{
+		config:     config,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: config.Timeout, Transport: transport},
+	}, nil
+}
+
+type secret struct {
+	APIVersion string            `json:"apiVersion,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	Metadata   secretMetadata    `json:"metadata"`
+	Type       string            `json:"type,omitempty"`
+	Data       map[string][]byte `json:"data"`
+}
+
+type secretMetadata struct {
+	Name      string            `json:"name,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// Deploy updates the data of the secret (merge patch), or creates the secret if it doesn't exist.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	if len(res.PrivateKey) == 0 {
+		return errors.New("kubernetes: the private key is not available (CSR)")
+	}
+
+	name := secretName(t.config.Secret, res.Domain)
+
+	s := secret{
+		Metadata: secretMetadata{Labels: t.config.Labels},
+		Data: map[string][]byte{
+			"tls.crt": res.Certificate,
+			"tls.key": res.PrivateKey,
+		},
+	}
+
+	if len(res.IssuerCertificate) > 0 {
+		s.Data["ca.crt"] = res.IssuerCertificate
+	}
+
+	endpoint := t.baseURL.JoinPath("api", "v1", "namespaces", t.config.Namespace, "secrets")
+
+	status, err := t.do(ctx, http.MethodPatch, endpoint.JoinPath(name), "application/merge-patch+json", s)
+	if err != nil {
+		return fmt.Errorf("kubernetes: secret %s/%s: %w", t.config.Namespace, name, err)
+	}
+
+	if status != http.StatusNotFound {
+		return nil
+	}
+
+	s.APIVersion = "v1"
+	s.Kind = "Secret"
+	s.Type = "kubernetes.io/tls"
+	s.Metadata.Name = name
+	s.Metadata.Namespace = t.config.Namespace
+
+	_, err = t.do(ctx, http.MethodPost, endpoint, "application/json", s)
+	if err != nil {
+		return fmt.Errorf("kubernetes: create secret %s/%s: %w", t.config.Namespace, name, err)
+	}
+
+	return nil
+}
+
+// do sends the request, and returns the status code.
+// The status 404 (not found) is not an error.
+func (t *Target) do(ctx context.Context, method string, endpoint *url.URL, contentType string, payload any) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+
+	token, err := t.token()
+	if err != nil {
+		return 0, err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode/100 == 2 {
+		return resp.StatusCode, nil
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	return resp.StatusCode, fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+}
+
+// token returns the bearer token, read at each deployment: the tokens of the service accounts are rotated.
+func (t *Target) token() (string, error) {
+	if t.config.Token != "" || t.config.TokenFile == "" {
+		return t.config.Token, nil
+	}
+
+	token, err := os.ReadFile(t.config.TokenFile)
+	if err != nil {
+		return "", fmt.Errorf("token: %w", err)
+	}
+
+	return strings.TrimSpace(string(token)), nil
+}
+
+// secretName returns the name of the secret of the domain (ex: tls-{domain}: tls-wildcard.example.com for *.example.com).
+func secretName(pattern, domain string) string {
+	return strings.ReplaceAll(pattern, "{domain}", strings.ToLower(strings.ReplaceAll(domain, "*", "wildcard")))
+}
@@ -0,0 +1,172 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPIServer a minimal Kubernetes API server storing the secrets.
+type fakeAPIServer struct {
+	mu       sync.Mutex
+	secrets  map[string]secret
+	requests []string
+}
+
+func (f *fakeAPIServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, req.Method+" "+req.URL.Path)
+
+	if req.Header.Get("Authorization") != "Bearer secret-token" {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var s secret
+
+	err := json.NewDecoder(req.Body).Decode(&s)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPatch:
+		if req.Header.Get("Content-Type") != "application/merge-patch+json" {
+			http.Error(rw, "invalid content type", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		name := req.URL.Path[len("/api/v1/namespaces/default/secrets/"):]
+
+		existing, ok := f.secrets[name]
+		if !ok {
+			http.Error(rw, "not found", http.StatusNotFound)
+			return
+		}
+
+		for k, v := range s.Data {
+			existing.Data[k] = v
+		}
+
+		f.secrets[name] = existing
+
+	case http.MethodPost:
+		f.secrets[s.Metadata.Name] = s
+		rw.WriteHeader(http.StatusCreated)
+
+	default:
+		http.Error(rw, "unsupported method", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestTarget_Deploy(t *testing.T) {
+	api := &fakeAPIServer{secrets: map[string]secret{}}
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	config := &Config{
+		APIServer: server.URL,
+		Token:     "secret-token",
+		Namespace: "default",
+		Secret:    "tls-{domain}",
+		Labels:    map[string]string{"app": "web"},
+	}
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{
+		Domain:            "*.example.com",
+		Certificate:       []byte("CERTIFICATE"),
+		PrivateKey:        []byte("KEY"),
+		IssuerCertificate: []byte("ISSUER"),
+	}
+
+	// creation
+	err = target.Deploy(context.Background(), res)
+	require.NoError(t, err)
+
+	require.Contains(t, api.secrets, "tls-wildcard.example.com")
+
+	created := api.secrets["tls-wildcard.example.com"]
+	assert.Equal(t, "kubernetes.io/tls", created.Type)
+	assert.Equal(t, "default", created.Metadata.Namespace)
+	assert.Equal(t, map[string]string{"app": "web"}, created.Metadata.Labels)
+	assert.Equal(t, []byte("CERTIFICATE"), created.Data["tls.crt"])
+	assert.Equal(t, []byte("KEY"), created.Data["tls.key"])
+	assert.Equal(t, []byte("ISSUER"), created.Data["ca.crt"])
+
+	// update
+	res.Certificate = []byte("RENEWED")
+
+	err = target.Deploy(context.Background(), res)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("RENEWED"), api.secrets["tls-wildcard.example.com"].Data["tls.crt"])
+
+	expected := []string{
+		"PATCH /api/v1/namespaces/default/secrets/tls-wildcard.example.com",
+		"POST /api/v1/namespaces/default/secrets",
+		"PATCH /api/v1/namespaces/default/secrets/tls-wildcard.example.com",
+	}
+	assert.Equal(t, expected, api.requests)
+}
+
+func TestTarget_Deploy_error(t *testing.T) {
+	server := httptest.NewServer(&fakeAPIServer{secrets: map[string]secret{}})
+	t.Cleanup(server.Close)
+
+	target, err := NewTarget(&Config{APIServer: server.URL, Token: "invalid", Namespace: "default", Secret: "tls"})
+	require.NoError(t, err)
+
+	err = target.Deploy(context.Background(), &certificate.Resource{Domain: "example.com", Certificate: []byte("CERTIFICATE"), PrivateKey: []byte("KEY")})
+	require.EqualError(t, err, "kubernetes: secret default/tls: unexpected status code: 401: unauthorized")
+}
+
+func TestNewTarget_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *Config
+		expected string
+	}{
+		{
+			desc:     "nil",
+			expected: "kubernetes: the configuration is nil",
+		},
+		{
+			desc:     "missing API server",
+			config:   &Config{Namespace: "default", Secret: "tls"},
+			expected: "kubernetes: missing API server (not running in a cluster)",
+		},
+		{
+			desc:     "missing secret",
+			config:   &Config{APIServer: "https://127.0.0.1:6443", Namespace: "default"},
+			expected: "kubernetes: missing namespace or secret name",
+		},
+		{
+			desc:     "missing CA file",
+			config:   &Config{APIServer: "https://127.0.0.1:6443", Namespace: "default", Secret: "tls", CAFile: "missing.crt"},
+			expected: "kubernetes: CA: open missing.crt: no such file or directory",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewTarget(test.config)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
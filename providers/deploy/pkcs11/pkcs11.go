@@ -0,0 +1,227 @@
+// Package pkcs11 implements a deploy target loading the certificates and the private keys into a PKCS#11 token (HSM, SoftHSM, YubiHSM),
+// for the appliances that can only read from hardware-backed stores.
+package pkcs11
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // the default object ID is the SHA-1 hash of the public key, as the subject key identifier.
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/log"
+)
+
+// Config is used to configure the PKCS#11 target.
+type Config struct {
+	// Tool the path of the OpenSC `pkcs11-tool` command.
+	Tool string `yaml:"tool"`
+	// Module the path of the PKCS#11 library of the token (ex: /usr/lib/softhsm/libsofthsm2.so).
+	Module string `yaml:"module"`
+	// TokenLabel the label of the token.
+	TokenLabel string `yaml:"token_label"`
+	// Slot the ID of the slot, used if the token label is not defined.
+	Slot string `yaml:"slot"`
+	// PIN the user PIN of the token.
+	PIN string `yaml:"pin"`
+	// PINFile the path of a file containing the user PIN of the token, used if the PIN is not defined.
+	PINFile string `yaml:"pin_file"`
+	// Label the label of the objects (the domain of the certificate if empty).
+	Label string `yaml:"label"`
+	// ID the ID of the objects, hex encoded (the SHA-1 hash of the public key if empty).
+	ID string `yaml:"id"`
+	// Chain also loads the issuer certificates, with the labels `<label>-chain-<n>`.
+	Chain bool `yaml:"chain"`
+	// Timeout the timeout of each command.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewDefaultConfig returns a default configuration for the PKCS#11 target.
+func NewDefaultConfig() *Config {
+	return &Config{
+		Tool:    "pkcs11-tool",
+		Timeout: 30 * time.Second,
+	}
+}
+
+// Target loads the certificates and the private keys into a PKCS#11 token.
+type Target struct {
+	config *Config
+	pin    string
+
+	run func(ctx context.Context, name string, args ...string) error
+}
+
+// NewTarget returns a Target instance.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("pkcs11: the configuration is nil")
+	}
+
+	if config.Module == "" {
+		return nil, errors.New("pkcs11: missing module")
+	}
+
+	if config.TokenLabel == "" && config.Slot == "" {
+		return nil, errors.New("pkcs11: missing token label or slot")
+	}
+
+	if config.ID != "" {
+		if _, err := hex.DecodeString(config.ID); err != nil {
+			return nil, fmt.Errorf("pkcs11: invalid ID: %w", err)
+		}
+	}
+
+	pin := config.PIN
+	if pin == "" && config.PINFile != "" {
+		data, err := os.ReadFile(config.PINFile)
+		if err != nil {
+			return nil, fmt.Errorf("pkcs11: PIN file: %w", err)
+		}
+
+		pin = strings.TrimSpace(string(data))
+	}
+
+	if pin == "" {
+		return nil, errors.New("pkcs11: missing PIN")
+	}
+
+	return &Target{config: config, pin: pin, run: runCommand}, nil
+}
+
+// Deploy replaces the objects of the previous certificate by the new certificate and its private key.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	certificates, err := certcrypto.ParsePEMBundle(res.Certificate)
+	if err != nil {
+		return fmt.Errorf("pkcs11: %w", err)
+	}
+
+	if len(res.IssuerCertificate) > 0 && len(certificates) == 1 {
+		issuers, errI := certcrypto.ParsePEMBundle(res.IssuerCertificate)
+		if errI != nil {
+			return fmt.Errorf("pkcs11: %w", errI)
+		}
+
+		certificates = append(certificates, issuers...)
+	}
+
+	if len(res.PrivateKey) == 0 {
+		return errors.New("pkcs11: missing private key")
+	}
+
+	label := t.config.Label
+	if label == "" {
+		label = res.Domain
+	}
+
+	id := strings.ToLower(t.config.ID)
+	if id == "" {
+		id = subjectKeyID(certificates[0])
+	}
+
+	dir, err := os.MkdirTemp("", "lego-pkcs11")
+	if err != nil {
+		return fmt.Errorf("pkcs11: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	keyFile := filepath.Join(dir, "private.key")
+
+	err = os.WriteFile(keyFile, res.PrivateKey, 0o600)
+	if err != nil {
+		return fmt.Errorf("pkcs11: %w", err)
+	}
+
+	objects := []object{
+		{kind: "privkey", label: label, file: keyFile},
+		{kind: "cert", label: label, data: certificates[0].Raw},
+	}
+
+	if t.config.Chain {
+		for i, cert := range certificates[1:] {
+			objects = append(objects, object{kind: "cert", label: label + "-chain-" + strconv.Itoa(i+1), data: cert.Raw})
+		}
+	}
+
+	// The objects of the previous certificate are removed first: the labels are shared between the certificates.
+	// The errors are ignored because the objects don't exist during the first deployment.
+	for _, obj := range objects {
+		_ = t.command(ctx, "--delete-object", "--type", obj.kind, "--label", obj.label)
+	}
+
+	for i, obj := range objects {
+		if obj.file == "" {
+			obj.file = filepath.Join(dir, fmt.Sprintf("object-%d.der", i))
+
+			err = os.WriteFile(obj.file, obj.data, 0o600)
+			if err != nil {
+				return fmt.Errorf("pkcs11: %w", err)
+			}
+		}
+
+		objID := id
+		if i > 1 {
+			// The issuer certificates must not share the ID of the private key.
+			objID = subjectKeyID(certificates[i-1])
+		}
+
+		err = t.command(ctx, "--write-object", obj.file, "--type", obj.kind, "--label", obj.label, "--id", objID)
+		if err != nil {
+			return fmt.Errorf("pkcs11: write %s %s: %w", obj.kind, obj.label, err)
+		}
+	}
+
+	log.Infof("[%s] pkcs11: certificate and private key loaded into the token with the label %s (ID %s)", res.Domain, label, id)
+
+	return nil
+}
+
+// command runs the pkcs11-tool command on the token.
+func (t *Target) command(ctx context.Context, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+
+	base := []string{"--module", t.config.Module, "--login", "--pin", t.pin}
+
+	if t.config.TokenLabel != "" {
+		base = append(base, "--token-label", t.config.TokenLabel)
+	} else {
+		base = append(base, "--slot", t.config.Slot)
+	}
+
+	return t.run(ctx, t.config.Tool, append(base, args...)...)
+}
+
+// object a PKCS#11 object to write into the token.
+type object struct {
+	kind  string
+	label string
+	file  string
+	data  []byte
+}
+
+// subjectKeyID returns the SHA-1 hash of the public key of the certificate, hex encoded.
+func subjectKeyID(cert *x509.Certificate) string {
+	sum := sha1.Sum(cert.RawSubjectPublicKeyInfo) //nolint:gosec // the default object ID is the SHA-1 hash of the public key.
+
+	return hex.EncodeToString(sum[:])
+}
+
+func runCommand(ctx context.Context, name string, args ...string) error {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package pkcs11
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/certcrypto"
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseCommand = "pkcs11-tool --module /usr/lib/softhsm/libsofthsm2.so --login --pin 1234 --token-label lego "
+
+type commandRecorder struct {
+	commands []string
+	fail     map[string]error
+}
+
+func (r *commandRecorder) run(_ context.Context, name string, args ...string) error {
+	// the temporary files are replaced by their base names.
+	for i, arg := range args {
+		if i > 0 && args[i-1] == "--write-object" {
+			args[i] = filepath.Base(arg)
+		}
+	}
+
+	cmd := name + " " + strings.Join(args, " ")
+	r.commands = append(r.commands, cmd)
+
+	for prefix, err := range r.fail {
+		if strings.HasPrefix(cmd, baseCommand+prefix) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newTestTarget(t *testing.T, recorder *commandRecorder, update func(*Config)) *Target {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.Module = "/usr/lib/softhsm/libsofthsm2.so"
+	config.TokenLabel = "lego"
+	config.PIN = "1234"
+
+	if update != nil {
+		update(config)
+	}
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	target.run = recorder.run
+
+	return target
+}
+
+func TestNewTarget(t *testing.T) {
+	pinFile := filepath.Join(t.TempDir(), "pin")
+	require.NoError(t, os.WriteFile(pinFile, []byte("5678\n"), 0o600))
+
+	testCases := []struct {
+		desc     string
+		config   *Config
+		expected string
+	}{
+		{
+			desc:     "missing module",
+			config:   &Config{TokenLabel: "lego", PIN: "1234"},
+			expected: "pkcs11: missing module",
+		},
+		{
+			desc:     "missing token",
+			config:   &Config{Module: "softhsm.so", PIN: "1234"},
+			expected: "pkcs11: missing token label or slot",
+		},
+		{
+			desc:     "missing PIN",
+			config:   &Config{Module: "softhsm.so", Slot: "0"},
+			expected: "pkcs11: missing PIN",
+		},
+		{
+			desc:     "invalid ID",
+			config:   &Config{Module: "softhsm.so", Slot: "0", PIN: "1234", ID: "xyz"},
+			expected: "pkcs11: invalid ID: encoding/hex: invalid byte: U+0078 'x'",
+		},
+		{
+			desc:   "PIN file",
+			config: &Config{Module: "softhsm.so", Slot: "0", PINFile: pinFile},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			target, err := NewTarget(test.config)
+			if test.expected != "" {
+				require.EqualError(t, err, test.expected)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "5678", target.pin)
+		})
+	}
+}
+
+func TestTarget_Deploy(t *testing.T) {
+	res, leaf, issuer := generateResource(t)
+
+	testCases := []struct {
+		desc     string
+		update   func(*Config)
+		expected []string
+	}{
+		{
+			desc: "default",
+			expected: []string{
+				"--delete-object --type privkey --label example.com",
+				"--delete-object --type cert --label example.com",
+				"--write-object private.key --type privkey --label example.com --id " + subjectKeyID(leaf),
+				"--write-object object-1.der --type cert --label example.com --id " + subjectKeyID(leaf),
+			},
+		},
+		{
+			desc: "label, ID, and chain",
+			update: func(config *Config) {
+				config.Label = "web"
+				config.ID = "01AB"
+				config.Chain = true
+			},
+			expected: []string{
+				"--delete-object --type privkey --label web",
+				"--delete-object --type cert --label web",
+				"--delete-object --type cert --label web-chain-1",
+				"--write-object private.key --type privkey --label web --id 01ab",
+				"--write-object object-1.der --type cert --label web --id 01ab",
+				"--write-object object-2.der --type cert --label web-chain-1 --id " + subjectKeyID(issuer),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// the previous objects don't exist.
+			recorder := &commandRecorder{fail: map[string]error{"--delete-object": errors.New("object not found")}}
+
+			target := newTestTarget(t, recorder, test.update)
+
+			err := target.Deploy(context.Background(), res)
+			require.NoError(t, err)
+
+			var expected []string
+			for _, cmd := range test.expected {
+				expected = append(expected, baseCommand+cmd)
+			}
+
+			assert.Equal(t, expected, recorder.commands)
+		})
+	}
+}
+
+func TestTarget_Deploy_error(t *testing.T) {
+	res, _, _ := generateResource(t)
+
+	recorder := &commandRecorder{fail: map[string]error{"--write-object private.key": errors.New("CKR_PIN_INCORRECT")}}
+
+	target := newTestTarget(t, recorder, nil)
+
+	err := target.Deploy(context.Background(), res)
+	require.EqualError(t, err, "pkcs11: write privkey example.com: CKR_PIN_INCORRECT")
+
+	assert.Len(t, recorder.commands, 3)
+}
+
+func generateResource(t *testing.T) (*certificate.Resource, *x509.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	issuerTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	issuerDER, err := x509.CreateCertificate(rand.Reader, issuerTemplate, issuerTemplate, &issuerKey.PublicKey, issuerKey)
+	require.NoError(t, err)
+
+	issuer, err := x509.ParseCertificate(issuerDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{
+		Domain:            "example.com",
+		Certificate:       certcrypto.PEMEncode(certcrypto.DERCertificateBytes(der)),
+		IssuerCertificate: certcrypto.PEMEncode(certcrypto.DERCertificateBytes(issuerDER)),
+		PrivateKey:        certcrypto.PEMEncode(key),
+	}
+
+	return res, leaf, issuer
+}
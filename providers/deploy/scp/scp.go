@@ -0,0 +1,286 @@
+// Package scp implements a deploy target copying the certificate files to remote hosts over SSH (SCP).
+package scp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/log"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Config is used to configure the SCP target.
+type Config struct {
+	// Hosts the remote hosts (`host` or `host:port`).
+	Hosts []string `yaml:"hosts"`
+	// User the SSH user.
+	User string `yaml:"user"`
+	// PrivateKey the path of the SSH private key.
+	PrivateKey string `yaml:"private_key"`
+	// PrivateKeyPassphrase the passphrase of the SSH private key (optional).
+	PrivateKeyPassphrase string `yaml:"private_key_passphrase"`
+	// KnownHosts the path of the known_hosts file used to verify the host keys:
+	// only the hosts with a known host key are allowed.
+	KnownHosts string `yaml:"known_hosts"`
+	// Certificate the remote path of the certificate (and its chain).
+	Certificate string `yaml:"certificate"`
+	// Key the remote path of the private key.
+	Key string `yaml:"key"`
+	// CA the remote path of the issuer certificate (optional).
+	CA string `yaml:"ca"`
+	// PostCommand a command executed on the remote hosts after the copy (optional).
+	PostCommand string `yaml:"post_command"`
+	// Timeout the timeout of the deployment to a host.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewDefaultConfig returns a default configuration for the SCP target.
+func NewDefaultConfig() *Config {
+	return &Config{
+		User:       "root",
+		KnownHosts: filepath.Join("~", ".ssh", "known_hosts"),
+		Timeout:    time.Minute,
+	}
+}
+
+// Target copies the certificate files to remote hosts.
+type Target struct {
+	config       *Config
+	clientConfig *ssh.ClientConfig
+}
+
+// NewTarget returns a Target instance.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("scp: the configuration is nil")
+	}
+
+	if len(config.Hosts) == 0 {
+		return nil, errors.New("scp: missing hosts")
+	}
+
+	if config.Certificate == "" || config.Key == "" {
+		return nil, errors.New("scp: missing certificate or key path")
+	}
+
+	signer, err := readPrivateKey(expandHome(config.PrivateKey), config.PrivateKeyPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("scp: private key: %w", err)
+	}
+
+	hostKeyCallback, err := knownhosts.New(expandHome(config.KnownHosts))
+	if err != nil {
+		return nil, fmt.Errorf("scp: known hosts: %w", err)
+	}
+
+	clientConfig := &ssh.ClientConfig{
+		User:            config.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         config.Timeout,
+	}
+
+	return &Target{config: config, clientConfig: clientConfig}, nil
+}
+
+// Deploy copies the certificate files to all the hosts, then executes the post command.
+// All the hosts are processed even if a deployment fails.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	var errs []error
+
+	for _, host := range t.config.Hosts {
+		err := t.deployHost(ctx, host, res)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("scp: %s: %w", host, err))
+			continue
+		}
+
+		log.Infof("[%s] scp: certificate copied to %s", res.Domain, host)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *Target) deployHost(ctx context.Context, host string, res *certificate.Resource) error {
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+
+	dialer := &net.Dialer{}
+
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, host, t.clientConfig)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	client := ssh.NewClient(sshConn, chans, reqs)
+
+	defer func() { _ = client.Close() }()
+
+	files := []struct {
+		path    string
+		content []byte
+		mode    os.FileMode
+	}{
+		{path: t.config.Certificate, content: res.Certificate, mode: 0o644},
+		{path: t.config.Key, content: res.PrivateKey, mode: 0o600},
+		{path: t.config.CA, content: res.IssuerCertificate, mode: 0o644},
+	}
+
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+
+		err = copyFile(client, file.path, file.content, file.mode)
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", file.path, err)
+		}
+	}
+
+	if t.config.PostCommand == "" {
+		return nil
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = session.Close() }()
+
+	output, err := session.CombinedOutput(t.config.PostCommand)
+	if err != nil {
+		return fmt.Errorf("post command: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// copyFile copies a file with the SCP protocol (sink mode: `scp -t`).
+func copyFile(client *ssh.Client, remotePath string, content []byte, mode os.FileMode) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = session.Close() }()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	err = session.Start("scp -t " + shellQuote(remotePath))
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(stdout)
+
+	err = readAck(reader)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdin, "C%04o %d %s\n", mode.Perm(), len(content), path.Base(remotePath))
+	if err != nil {
+		return err
+	}
+
+	err = readAck(reader)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(stdin, io.MultiReader(bytes.NewReader(content), bytes.NewReader([]byte{0})))
+	if err != nil {
+		return err
+	}
+
+	err = readAck(reader)
+	if err != nil {
+		return err
+	}
+
+	_ = stdin.Close()
+
+	return session.Wait()
+}
+
+// readAck reads the response of the remote SCP: 0 (OK), 1 (warning), or 2 (error) followed by a message.
+func readAck(reader *bufio.Reader) error {
+	code, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if code == 0 {
+		return nil
+	}
+
+	msg, _ := reader.ReadString('\n')
+
+	return fmt.Errorf("remote scp: %s", strings.TrimSpace(msg))
+}
+
+func readPrivateKey(filename, passphrase string) (ssh.Signer, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+
+	return ssh.ParsePrivateKey(data)
+}
+
+func expandHome(filename string) string {
+	rest, ok := strings.CutPrefix(filename, "~"+string(filepath.Separator))
+	if !ok {
+		return filename
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filename
+	}
+
+	return filepath.Join(home, rest)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,232 @@
+package scp
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// fakeServer a minimal SSH server supporting `scp -t` and the execution of commands.
+type fakeServer struct {
+	address string
+
+	mu       sync.Mutex
+	files    map[string]string
+	modes    map[string]string
+	commands []string
+}
+
+func setupServer(t *testing.T, clientKey ssh.PublicKey) (*fakeServer, ssh.PublicKey) {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if clientKey == nil || string(key.Marshal()) != string(clientKey.Marshal()) {
+				return nil, fmt.Errorf("unknown key")
+			}
+
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &fakeServer{address: listener.Addr().String(), files: map[string]string{}, modes: map[string]string{}}
+
+	go func() {
+		for {
+			conn, errA := listener.Accept()
+			if errA != nil {
+				return
+			}
+
+			go server.handleConn(conn, config)
+		}
+	}()
+
+	return server, hostSigner.PublicKey()
+}
+
+func (s *fakeServer) handleConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handleSession(channel, requests)
+	}
+}
+
+func (s *fakeServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer func() { _ = channel.Close() }()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+
+		_ = req.Reply(true, nil)
+
+		length := binary.BigEndian.Uint32(req.Payload)
+		command := string(req.Payload[4 : 4+length])
+
+		if target, ok := strings.CutPrefix(command, "scp -t "); ok {
+			s.sink(channel, strings.Trim(target, "'"))
+		} else {
+			s.mu.Lock()
+			s.commands = append(s.commands, command)
+			s.mu.Unlock()
+
+			_, _ = io.WriteString(channel, "ok\n")
+		}
+
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+
+		return
+	}
+}
+
+func (s *fakeServer) sink(channel ssh.Channel, target string) {
+	reader := bufio.NewReader(channel)
+
+	_, _ = channel.Write([]byte{0})
+
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+
+	var mode, name string
+	var size int
+	_, _ = fmt.Sscanf(header, "C%s %d %s", &mode, &size, &name)
+
+	_, _ = channel.Write([]byte{0})
+
+	content := make([]byte, size+1)
+	_, _ = io.ReadFull(reader, content)
+
+	s.mu.Lock()
+	s.files[target] = string(content[:size])
+	s.modes[target] = mode
+	s.mu.Unlock()
+
+	_, _ = channel.Write([]byte{0})
+}
+
+func setupTest(t *testing.T) (*Config, *fakeServer) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	_, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600))
+
+	clientSigner, err := ssh.NewSignerFromKey(clientPriv)
+	require.NoError(t, err)
+
+	server, hostKey := setupServer(t, clientSigner.PublicKey())
+
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{server.address}, hostKey)+"\n"), 0o600))
+
+	config := NewDefaultConfig()
+	config.Hosts = []string{server.address}
+	config.User = "lego"
+	config.PrivateKey = keyFile
+	config.KnownHosts = knownHostsFile
+	config.Certificate = "/etc/appliance/tls/cert.pem"
+	config.Key = "/etc/appliance/tls/key.pem"
+
+	return config, server
+}
+
+func TestTarget_Deploy(t *testing.T) {
+	config, server := setupTest(t)
+	config.PostCommand = "systemctl reload appliance"
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{Domain: "example.com", Certificate: []byte("cert"), PrivateKey: []byte("key")}
+
+	err = target.Deploy(context.Background(), res)
+	require.NoError(t, err)
+
+	expectedFiles := map[string]string{
+		"/etc/appliance/tls/cert.pem": "cert",
+		"/etc/appliance/tls/key.pem":  "key",
+	}
+	assert.Equal(t, expectedFiles, server.files)
+
+	expectedModes := map[string]string{
+		"/etc/appliance/tls/cert.pem": "0644",
+		"/etc/appliance/tls/key.pem":  "0600",
+	}
+	assert.Equal(t, expectedModes, server.modes)
+
+	assert.Equal(t, []string{"systemctl reload appliance"}, server.commands)
+}
+
+func TestTarget_Deploy_unknownHost(t *testing.T) {
+	config, server := setupTest(t)
+
+	// The known_hosts file doesn't contain the host key of this host.
+	other, _ := setupServer(t, nil)
+	config.Hosts = []string{other.address, server.address}
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{Domain: "example.com", Certificate: []byte("cert"), PrivateKey: []byte("key")}
+
+	err = target.Deploy(context.Background(), res)
+	require.ErrorContains(t, err, "scp: "+other.address+": ssh: handshake failed: knownhosts: key is unknown")
+
+	assert.Len(t, server.files, 2)
+}
+
+func Test_shellQuote(t *testing.T) {
+	assert.Equal(t, `'/etc/it'\''s/cert.pem'`, shellQuote("/etc/it's/cert.pem"))
+}
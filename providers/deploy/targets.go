@@ -0,0 +1,185 @@
+package deploy
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pya789/lego/v4/providers/deploy/acm"
+	"github.com/pya789/lego/v4/providers/deploy/azurekeyvault"
+	"github.com/pya789/lego/v4/providers/deploy/certstore"
+	"github.com/pya789/lego/v4/providers/deploy/database"
+	"github.com/pya789/lego/v4/providers/deploy/file"
+	"github.com/pya789/lego/v4/providers/deploy/haproxy"
+	"github.com/pya789/lego/v4/providers/deploy/kubernetes"
+	"github.com/pya789/lego/v4/providers/deploy/pkcs11"
+	"github.com/pya789/lego/v4/providers/deploy/scp"
+	"github.com/pya789/lego/v4/providers/deploy/vault"
+	"github.com/pya789/lego/v4/providers/deploy/webserver"
+)
+
+// TargetFactory creates a target from its `config` section.
+// unmarshal decodes the `config` section into a configuration value, the fields not defined in the section are kept.
+type TargetFactory func(unmarshal func(interface{}) error) (Target, error)
+
+var (
+	factories   = map[string]TargetFactory{}
+	factoriesMu sync.RWMutex
+)
+
+// RegisterTarget registers a custom target type, used by the configuration files and by ParseTarget.
+// A registered type takes precedence over a built-in type of the same name.
+// It panics if the type is empty, if factory is nil, or if the type is already registered.
+func RegisterTarget(typ string, factory TargetFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if typ == "" || factory == nil {
+		panic("deploy: RegisterTarget: empty type or nil factory")
+	}
+
+	if _, dup := factories[typ]; dup {
+		panic("deploy: RegisterTarget called twice for the type " + typ)
+	}
+
+	factories[typ] = factory
+}
+
+// rawConfig keeps the `config` section of a target, decoded by the factory of a registered type.
+type rawConfig struct {
+	unmarshal func(interface{}) error
+}
+
+func (r *rawConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	r.unmarshal = unmarshal
+	return nil
+}
+
+// newRegisteredTarget creates a target of a registered type.
+func newRegisteredTarget(factory TargetFactory, unmarshal func(interface{}) error) (Target, error) {
+	raw := targetConfig[rawConfig]{Config: &rawConfig{}}
+
+	err := unmarshal(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw.Config == nil || raw.Config.unmarshal == nil {
+		return factory(func(interface{}) error { return nil })
+	}
+
+	return factory(raw.Config.unmarshal)
+}
+
+// newTarget creates a target by its type.
+func newTarget(targetType string, unmarshal func(interface{}) error) (Target, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[targetType]
+	factoriesMu.RUnlock()
+
+	if ok {
+		return newRegisteredTarget(factory, unmarshal)
+	}
+
+	switch targetType {
+	case "acm":
+		cfg := acm.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return acm.NewTarget(cfg)
+	case "azurekeyvault":
+		cfg := azurekeyvault.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return azurekeyvault.NewTarget(cfg)
+	case "certstore":
+		cfg := certstore.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return certstore.NewTarget(cfg)
+	case "file":
+		cfg := file.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return file.NewTarget(cfg)
+	case "haproxy":
+		cfg := haproxy.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return haproxy.NewTarget(cfg)
+	case "kubernetes":
+		cfg := kubernetes.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return kubernetes.NewTarget(cfg)
+	case "mariadb":
+		cfg := database.NewMariaDBConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return database.NewTarget(cfg)
+	case "mysql":
+		cfg := database.NewMySQLConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return database.NewTarget(cfg)
+	case "nginx":
+		cfg := webserver.NewNginxConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return webserver.NewTarget(cfg)
+	case "apache":
+		cfg := webserver.NewApacheConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return webserver.NewTarget(cfg)
+	case "pkcs11":
+		cfg := pkcs11.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return pkcs11.NewTarget(cfg)
+	case "postgresql":
+		cfg := database.NewPostgreSQLConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return database.NewTarget(cfg)
+	case "scp":
+		cfg := scp.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return scp.NewTarget(cfg)
+	case "vault":
+		cfg := vault.NewDefaultConfig()
+		if err := decodeConfig(unmarshal, cfg); err != nil {
+			return nil, err
+		}
+
+		return vault.NewTarget(cfg)
+	default:
+		return nil, fmt.Errorf("unrecognized deploy target: %s", targetType)
+	}
+}
@@ -0,0 +1,212 @@
+// Package vault implements a deploy target storing the certificates in a HashiCorp Vault KV secrets engine (version 1 or 2).
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+)
+
+// Config is used to configure the Vault target.
+type Config struct {
+	// Address the address of the Vault server (default: $VAULT_ADDR).
+	Address string `yaml:"address"`
+	// Token the Vault token (default: $VAULT_TOKEN).
+	Token string `yaml:"token"`
+	// TokenFile the path of a file containing the Vault token (ex: written by the Vault agent).
+	TokenFile string `yaml:"token_file"`
+	// Namespace the Vault namespace (Vault Enterprise, default: $VAULT_NAMESPACE).
+	Namespace string `yaml:"namespace"`
+	// CAFile the path of the CA certificate of the Vault server (default: $VAULT_CACERT).
+	CAFile string `yaml:"ca_file"`
+	// Mount the path of the KV secrets engine.
+	Mount string `yaml:"mount"`
+	// Path the path of the secret in the secrets engine, can contain the placeholder {domain} (ex: certificates/{domain}).
+	Path string `yaml:"path"`
+	// KVVersion the version of the KV secrets engine (1 or 2).
+	KVVersion int `yaml:"kv_version"`
+	// Timeout the timeout of the requests to the Vault server.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewDefaultConfig returns a default configuration for the Vault target.
+func NewDefaultConfig() *Config {
+	return &Config{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		CAFile:    os.Getenv("VAULT_CACERT"),
+		Mount:     "secret",
+		KVVersion: 2,
+		Timeout:   30 * time.Second,
+	}
+}
+
+// Target stores the certificates in a KV secrets engine.
+type Target struct {
+	config     *Config
+	baseURL    *url.URL
+	httpClient *http.Client
+}
+
+// NewTarget returns a Target instance.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("vault: the configuration is nil")
+	}
+
+	if config.Address == "" {
+		return nil, errors.New("vault: missing address")
+	}
+
+	if config.Token == "" && config.TokenFile == "" {
+		return nil, errors.New("vault: missing token")
+	}
+
+	if config.Mount == "" || config.Path == "" {
+		return nil, errors.New("vault: missing mount or path")
+	}
+
+	if config.KVVersion != 1 && config.KVVersion != 2 {
+		return nil, fmt.Errorf("vault: unsupported KV version: %d", config.KVVersion)
+	}
+
+	baseURL, err := url.Parse(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("vault: invalid address: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.CAFile != "" {
+		pem, errR := os.ReadFile(config.CAFile)
+		if errR != nil {
+			return nil, fmt.Errorf("vault: CA: %w", errR)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("vault: CA: no certificate found in %s", config.CAFile)
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &Target{
+		config:     config,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: config.Timeout, Transport: transport},
+	}, nil
+}
+
+// Deploy writes the certificate, the private key, and the issuer certificate in the secret (a new version with KV version 2).
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	data := map[string]string{
+		"domain":      res.Domain,
+		"certificate": string(res.Certificate),
+	}
+
+	if len(res.PrivateKey) > 0 {
+		data["private_key"] = string(res.PrivateKey)
+	}
+
+	if len(res.IssuerCertificate) > 0 {
+		data["issuer_certificate"] = string(res.IssuerCertificate)
+	}
+
+	secretPath := strings.Trim(strings.ReplaceAll(t.config.Path, "{domain}", res.Domain), "/")
+
+	var (
+		endpoint *url.URL
+		payload  any
+	)
+
+	switch t.config.KVVersion {
+	case 1:
+		endpoint = t.baseURL.JoinPath("v1", strings.Trim(t.config.Mount, "/"), secretPath)
+		payload = data
+	default:
+		endpoint = t.baseURL.JoinPath("v1", strings.Trim(t.config.Mount, "/"), "data", secretPath)
+		payload = map[string]any{"data": data}
+	}
+
+	err := t.write(ctx, endpoint, payload)
+	if err != nil {
+		return fmt.Errorf("vault: %s/%s: %w", strings.Trim(t.config.Mount, "/"), secretPath, err)
+	}
+
+	return nil
+}
+
+func (t *Target) write(ctx context.Context, endpoint *url.URL, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	token, err := t.token()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", token)
+
+	if t.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", t.config.Namespace)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	var apiErr struct {
+		Errors []string `json:"errors"`
+	}
+
+	if json.Unmarshal(raw, &apiErr) == nil && len(apiErr.Errors) > 0 {
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, strings.Join(apiErr.Errors, ", "))
+	}
+
+	return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+}
+
+// token returns the Vault token, the token file is read at each deployment: the token can be renewed by the Vault agent.
+func (t *Target) token() (string, error) {
+	if t.config.TokenFile == "" {
+		return t.config.Token, nil
+	}
+
+	token, err := os.ReadFile(t.config.TokenFile)
+	if err != nil {
+		return "", fmt.Errorf("token: %w", err)
+	}
+
+	return strings.TrimSpace(string(token)), nil
+}
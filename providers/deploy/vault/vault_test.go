@@ -0,0 +1,149 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget_Deploy(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		kvVersion    int
+		expectedPath string
+		wrapped      bool
+	}{
+		{
+			desc:         "KV version 2",
+			kvVersion:    2,
+			expectedPath: "/v1/kv/data/certificates/example.com",
+			wrapped:      true,
+		},
+		{
+			desc:         "KV version 1",
+			kvVersion:    1,
+			expectedPath: "/v1/kv/certificates/example.com",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				path    string
+				payload map[string]any
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPost || req.Header.Get("X-Vault-Token") != "s.token" || req.Header.Get("X-Vault-Namespace") != "team" {
+					http.Error(rw, `{"errors":["permission denied"]}`, http.StatusForbidden)
+					return
+				}
+
+				path = req.URL.Path
+
+				_ = json.NewDecoder(req.Body).Decode(&payload)
+
+				rw.WriteHeader(http.StatusNoContent)
+			}))
+			t.Cleanup(server.Close)
+
+			target, err := NewTarget(&Config{
+				Address:   server.URL,
+				Token:     "s.token",
+				Namespace: "team",
+				Mount:     "kv",
+				Path:      "certificates/{domain}",
+				KVVersion: test.kvVersion,
+			})
+			require.NoError(t, err)
+
+			res := &certificate.Resource{
+				Domain:            "example.com",
+				Certificate:       []byte("CERTIFICATE"),
+				PrivateKey:        []byte("KEY"),
+				IssuerCertificate: []byte("ISSUER"),
+			}
+
+			err = target.Deploy(context.Background(), res)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedPath, path)
+
+			expected := map[string]any{
+				"domain":             "example.com",
+				"certificate":        "CERTIFICATE",
+				"private_key":        "KEY",
+				"issuer_certificate": "ISSUER",
+			}
+
+			if test.wrapped {
+				assert.Equal(t, map[string]any{"data": expected}, payload)
+			} else {
+				assert.Equal(t, expected, payload)
+			}
+		})
+	}
+}
+
+func TestTarget_Deploy_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, `{"errors":["permission denied"]}`, http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	target, err := NewTarget(&Config{Address: server.URL, Token: "invalid", Mount: "secret", Path: "certificates/{domain}", KVVersion: 2})
+	require.NoError(t, err)
+
+	err = target.Deploy(context.Background(), &certificate.Resource{Domain: "example.com", Certificate: []byte("CERTIFICATE")})
+	require.EqualError(t, err, "vault: secret/certificates/example.com: unexpected status code: 403: permission denied")
+}
+
+func TestNewTarget_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *Config
+		expected string
+	}{
+		{
+			desc:     "nil",
+			expected: "vault: the configuration is nil",
+		},
+		{
+			desc:     "missing address",
+			config:   &Config{Token: "s.token", Mount: "secret", Path: "certificates", KVVersion: 2},
+			expected: "vault: missing address",
+		},
+		{
+			desc:     "missing token",
+			config:   &Config{Address: "https://vault.example.com:8200", Mount: "secret", Path: "certificates", KVVersion: 2},
+			expected: "vault: missing token",
+		},
+		{
+			desc:     "missing path",
+			config:   &Config{Address: "https://vault.example.com:8200", Token: "s.token", Mount: "secret", KVVersion: 2},
+			expected: "vault: missing mount or path",
+		},
+		{
+			desc:     "unsupported KV version",
+			config:   &Config{Address: "https://vault.example.com:8200", Token: "s.token", Mount: "secret", Path: "certificates", KVVersion: 3},
+			expected: "vault: unsupported KV version: 3",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewTarget(test.config)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Package webserver implements deploy targets for web servers (nginx, Apache):
+// the configuration is tested before a graceful reload, and the previous certificate is restored on failure.
+package webserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/pya789/lego/v4/log"
+)
+
+// Config is used to configure the web server target.
+type Config struct {
+	// Certificate the path where the certificate (and its chain) is written (optional).
+	Certificate string `yaml:"certificate"`
+	// Key the path where the private key is written (optional).
+	Key string `yaml:"key"`
+	// TestCommand the command used to test the configuration of the web server.
+	TestCommand string `yaml:"test_command"`
+	// ReloadCommand the command used to gracefully reload the web server.
+	ReloadCommand string `yaml:"reload_command"`
+	// Timeout the timeout of each command.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NewNginxConfig returns a default configuration for nginx.
+func NewNginxConfig() *Config {
+	return &Config{
+		TestCommand:   "nginx -t",
+		ReloadCommand: "nginx -s reload",
+		Timeout:       30 * time.Second,
+	}
+}
+
+// NewApacheConfig returns a default configuration for Apache.
+func NewApacheConfig() *Config {
+	return &Config{
+		TestCommand:   "apachectl configtest",
+		ReloadCommand: "apachectl graceful",
+		Timeout:       30 * time.Second,
+	}
+}
+
+// Target writes the certificate files, tests the configuration, and reloads a web server.
+type Target struct {
+	config *Config
+}
+
+// NewTarget returns a Target instance.
+func NewTarget(config *Config) (*Target, error) {
+	if config == nil {
+		return nil, errors.New("webserver: the configuration is nil")
+	}
+
+	if config.TestCommand == "" || config.ReloadCommand == "" {
+		return nil, errors.New("webserver: missing test or reload command")
+	}
+
+	if (config.Certificate == "") != (config.Key == "") {
+		return nil, errors.New("webserver: the certificate and key paths must be defined together")
+	}
+
+	return &Target{config: config}, nil
+}
+
+// Deploy writes the certificate files, then reloads the web server only if its configuration is valid.
+// The previous certificate files are restored if the test or the reload fails.
+func (t *Target) Deploy(ctx context.Context, res *certificate.Resource) error {
+	backups, err := t.writeFiles(res)
+	if err != nil {
+		return fmt.Errorf("webserver: %w", err)
+	}
+
+	err = t.run(ctx, t.config.TestCommand)
+	if err != nil {
+		return t.rollback(backups, fmt.Errorf("webserver: invalid configuration: %w", err))
+	}
+
+	err = t.run(ctx, t.config.ReloadCommand)
+	if err != nil {
+		return t.rollback(backups, fmt.Errorf("webserver: reload: %w", err))
+	}
+
+	return nil
+}
+
+// writeFiles writes the certificate files and returns the previous content of the files.
+func (t *Target) writeFiles(res *certificate.Resource) (map[string][]byte, error) {
+	backups := map[string][]byte{}
+
+	if t.config.Certificate == "" {
+		return backups, nil
+	}
+
+	files := map[string][]byte{
+		t.config.Certificate: res.Certificate,
+		t.config.Key:         res.PrivateKey,
+	}
+
+	for filename := range files {
+		previous, err := os.ReadFile(filename)
+		switch {
+		case err == nil:
+			backups[filename] = previous
+		case errors.Is(err, os.ErrNotExist):
+			backups[filename] = nil
+		default:
+			return nil, err
+		}
+	}
+
+	for filename, content := range files {
+		err := os.WriteFile(filename, content, 0o600)
+		if err != nil {
+			return nil, t.rollback(backups, err)
+		}
+	}
+
+	return backups, nil
+}
+
+// rollback restores the previous certificate files.
+// The files without previous content are removed.
+func (t *Target) rollback(backups map[string][]byte, cause error) error {
+	errs := []error{cause}
+
+	for filename, content := range backups {
+		var err error
+		if content == nil {
+			err = os.Remove(filename)
+			if errors.Is(err, os.ErrNotExist) {
+				err = nil
+			}
+		} else {
+			err = os.WriteFile(filename, content, 0o600)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rollback: %w", err))
+		}
+	}
+
+	if len(backups) > 0 && len(errs) == 1 {
+		log.Warnf("webserver: the previous certificate files have been restored")
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *Target) run(ctx context.Context, command string) error {
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+
+	parts := strings.Fields(command)
+
+	output, err := exec.CommandContext(ctx, parts[0], parts[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", command, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package webserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pya789/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget_Deploy(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		testCommand   string
+		reloadCommand string
+		expectedCert  string
+		expectedKey   string
+		requireErr    require.ErrorAssertionFunc
+	}{
+		{
+			desc:          "success",
+			testCommand:   "true",
+			reloadCommand: "true",
+			expectedCert:  "new cert",
+			expectedKey:   "new key",
+			requireErr:    require.NoError,
+		},
+		{
+			desc:          "invalid configuration",
+			testCommand:   "false",
+			reloadCommand: "true",
+			expectedCert:  "old cert",
+			expectedKey:   "old key",
+			requireErr:    require.Error,
+		},
+		{
+			desc:          "reload failed",
+			testCommand:   "true",
+			reloadCommand: "false",
+			expectedCert:  "old cert",
+			expectedKey:   "old key",
+			requireErr:    require.Error,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			config := NewNginxConfig()
+			config.Certificate = filepath.Join(dir, "example.com.crt")
+			config.Key = filepath.Join(dir, "example.com.key")
+			config.TestCommand = test.testCommand
+			config.ReloadCommand = test.reloadCommand
+
+			require.NoError(t, os.WriteFile(config.Certificate, []byte("old cert"), 0o600))
+			require.NoError(t, os.WriteFile(config.Key, []byte("old key"), 0o600))
+
+			target, err := NewTarget(config)
+			require.NoError(t, err)
+
+			res := &certificate.Resource{Domain: "example.com", Certificate: []byte("new cert"), PrivateKey: []byte("new key")}
+
+			err = target.Deploy(context.Background(), res)
+			test.requireErr(t, err)
+
+			assertFileContent(t, config.Certificate, test.expectedCert)
+			assertFileContent(t, config.Key, test.expectedKey)
+		})
+	}
+}
+
+func TestTarget_Deploy_rollbackNewFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	config := NewApacheConfig()
+	config.Certificate = filepath.Join(dir, "example.com.crt")
+	config.Key = filepath.Join(dir, "example.com.key")
+	config.TestCommand = "false"
+
+	target, err := NewTarget(config)
+	require.NoError(t, err)
+
+	res := &certificate.Resource{Domain: "example.com", Certificate: []byte("new cert"), PrivateKey: []byte("new key")}
+
+	err = target.Deploy(context.Background(), res)
+	require.Error(t, err)
+
+	assert.NoFileExists(t, config.Certificate)
+	assert.NoFileExists(t, config.Key)
+}
+
+func TestNewTarget_errors(t *testing.T) {
+	config := NewNginxConfig()
+	config.Certificate = "/etc/nginx/certs/example.com.crt"
+
+	_, err := NewTarget(config)
+	require.EqualError(t, err, "webserver: the certificate and key paths must be defined together")
+}
+
+func assertFileContent(t *testing.T, filename, expected string) {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, string(data))
+}
@@ -43,7 +43,13 @@ type DNSProvider struct {
 // NewDNSProvider creates an ACME-DNS provider using file based account storage.
 // Its configuration is loaded from the environment by reading EnvAPIBase and EnvStoragePath.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIBase, EnvStoragePath)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIBase, EnvStoragePath)
 	if err != nil {
 		return nil, fmt.Errorf("acme-dns: %w", err)
 	}
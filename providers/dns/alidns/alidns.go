@@ -49,11 +49,15 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 600),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
-		HTTPTimeout:        env.GetOrDefaultSecond(EnvHTTPTimeout, 10*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 600),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		HTTPTimeout:        e.GetOrDefaultSecond(EnvHTTPTimeout, 10*time.Second),
 	}
 }
 
@@ -68,23 +72,29 @@ type DNSProvider struct {
 // - Other than that, credentials must be passed in the environment variables:
 // ALICLOUD_ACCESS_KEY, ALICLOUD_SECRET_KEY, and optionally ALICLOUD_SECURITY_TOKEN.
 func NewDNSProvider() (*DNSProvider, error) {
-	config := NewDefaultConfig()
-	config.RegionID = env.GetOrFile(EnvRegionID)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	config := newDefaultConfig(e)
+	config.RegionID = e.GetOrFile(EnvRegionID)
 
-	values, err := env.Get(EnvRAMRole)
+	values, err := e.Get(EnvRAMRole)
 	if err == nil {
 		config.RAMRole = values[EnvRAMRole]
 		return NewDNSProviderConfig(config)
 	}
 
-	values, err = env.Get(EnvAccessKey, EnvSecretKey)
+	values, err = e.Get(EnvAccessKey, EnvSecretKey)
 	if err != nil {
 		return nil, fmt.Errorf("alicloud: %w", err)
 	}
 
 	config.APIKey = values[EnvAccessKey]
 	config.SecretKey = values[EnvSecretKey]
-	config.SecurityToken = env.GetOrFile(EnvSecurityToken)
+	config.SecurityToken = e.GetOrFile(EnvSecurityToken)
 
 	return NewDNSProviderConfig(config)
 }
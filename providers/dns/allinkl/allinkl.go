@@ -38,11 +38,15 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -61,12 +65,18 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for all-inkl.
 // Credentials must be passed in the environment variable: ALL_INKL_LOGIN, ALL_INKL_PASSWORD.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvLogin, EnvPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvLogin, EnvPassword)
 	if err != nil {
 		return nil, fmt.Errorf("allinkl: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Login = values[EnvLogin]
 	config.Password = values[EnvPassword]
 
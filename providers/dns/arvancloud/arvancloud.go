@@ -39,12 +39,16 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -61,12 +65,18 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for ArvanCloud.
 // Credentials must be passed in the environment variable: ARVANCLOUD_API_KEY.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIKey)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIKey)
 	if err != nil {
 		return nil, fmt.Errorf("arvancloud: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.APIKey = values[EnvAPIKey]
 
 	return NewDNSProviderConfig(config)
@@ -39,10 +39,14 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 300),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 300),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 	}
 }
 
@@ -58,13 +62,19 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // AURORA_API_KEY and AURORA_SECRET.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIKey, EnvSecret)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIKey, EnvSecret)
 	if err != nil {
 		return nil, fmt.Errorf("aurora: %w", err)
 	}
 
-	config := NewDefaultConfig()
-	config.BaseURL = env.GetOrFile(EnvEndpoint)
+	config := newDefaultConfig(e)
+	config.BaseURL = e.GetOrFile(EnvEndpoint)
 	config.APIKey = values[EnvAPIKey]
 	config.Secret = values[EnvSecret]
 
@@ -43,16 +43,20 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
-	endpoint, _ := url.Parse(env.GetOrDefaultString(EnvAPIEndpoint, internal.DefaultEndpoint))
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
+	endpoint, _ := url.Parse(e.GetOrDefaultString(EnvAPIEndpoint, internal.DefaultEndpoint))
 
 	return &Config{
 		Endpoint:           endpoint,
-		Context:            env.GetOrDefaultInt(EnvAPIEndpointContext, internal.DefaultEndpointContext),
-		TTL:                env.GetOrDefaultInt(EnvTTL, 600),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		Context:            e.GetOrDefaultInt(EnvAPIEndpointContext, internal.DefaultEndpointContext),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 600),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -66,12 +70,18 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for autoDNS.
 // Credentials must be passed in the environment variables.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIUser, EnvAPIPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIUser, EnvAPIPassword)
 	if err != nil {
 		return nil, fmt.Errorf("autodns: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Username = values[EnvAPIUser]
 	config.Password = values[EnvAPIPassword]
 
@@ -62,11 +62,15 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                     env.GetOrDefaultInt(EnvTTL, 60),
-		PropagationTimeout:      env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
-		PollingInterval:         env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
-		MetadataEndpoint:        env.GetOrFile(EnvMetadataEndpoint),
+		TTL:                     e.GetOrDefaultInt(EnvTTL, 60),
+		PropagationTimeout:      e.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
+		PollingInterval:         e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		MetadataEndpoint:        e.GetOrFile(EnvMetadataEndpoint),
 		ResourceManagerEndpoint: aazure.PublicCloud.ResourceManagerEndpoint,
 		ActiveDirectoryEndpoint: aazure.PublicCloud.ActiveDirectoryEndpoint,
 	}
@@ -86,9 +90,15 @@ type DNSProvider struct {
 // see: https://github.com/Azure/go-autorest/blob/v10.14.0/autorest/azure/auth/auth.go#L38-L42
 // Deprecated: use azuredns instead.
 func NewDNSProvider() (*DNSProvider, error) {
-	config := NewDefaultConfig()
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	config := newDefaultConfig(e)
 
-	environmentName := env.GetOrFile(EnvEnvironment)
+	environmentName := e.GetOrFile(EnvEnvironment)
 	if environmentName != "" {
 		var environment aazure.Environment
 		switch environmentName {
@@ -108,12 +118,12 @@ func NewDNSProvider() (*DNSProvider, error) {
 		config.ActiveDirectoryEndpoint = environment.ActiveDirectoryEndpoint
 	}
 
-	config.SubscriptionID = env.GetOrFile(EnvSubscriptionID)
-	config.ResourceGroup = env.GetOrFile(EnvResourceGroup)
-	config.ClientSecret = env.GetOrFile(EnvClientSecret)
-	config.ClientID = env.GetOrFile(EnvClientID)
-	config.TenantID = env.GetOrFile(EnvTenantID)
-	config.PrivateZone = env.GetOrDefaultBool(EnvPrivateZone, false)
+	config.SubscriptionID = e.GetOrFile(EnvSubscriptionID)
+	config.ResourceGroup = e.GetOrFile(EnvResourceGroup)
+	config.ClientSecret = e.GetOrFile(EnvClientSecret)
+	config.ClientID = e.GetOrFile(EnvClientID)
+	config.TenantID = e.GetOrFile(EnvTenantID)
+	config.PrivateZone = e.GetOrDefaultBool(EnvPrivateZone, false)
 
 	return NewDNSProviderConfig(config)
 }
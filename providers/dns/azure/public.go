@@ -57,17 +57,16 @@ func (d *dnsProviderPublic) Present(domain, token, keyAuth string) error {
 	uniqRecords := map[string]struct{}{info.Value: {}}
 	if rset.RecordSetProperties != nil && rset.TxtRecords != nil {
 		for _, txtRecord := range *rset.TxtRecords {
-			// Assume Value doesn't contain multiple strings
 			values := to.StringSlice(txtRecord.Value)
 			if len(values) > 0 {
-				uniqRecords[values[0]] = struct{}{}
+				uniqRecords[dns01.JoinTXTValue(values)] = struct{}{}
 			}
 		}
 	}
 
 	var txtRecords []dns.TxtRecord
 	for txt := range uniqRecords {
-		txtRecords = append(txtRecords, dns.TxtRecord{Value: &[]string{txt}})
+		txtRecords = append(txtRecords, dns.TxtRecord{Value: to.StringSlicePtr(dns01.SplitTXTValue(txt))})
 	}
 
 	rec := dns.RecordSet{
@@ -17,6 +17,7 @@ import (
 	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
 )
 
 // Environment variables names.
@@ -82,10 +83,14 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 60),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 60),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
 		Environment:        cloud.AzurePublic,
 	}
 }
@@ -97,9 +102,15 @@ type DNSProvider struct {
 
 // NewDNSProvider returns a DNSProvider instance configured for azuredns.
 func NewDNSProvider() (*DNSProvider, error) {
-	config := NewDefaultConfig()
+	return NewDNSProviderFromEnv(env.Getter{})
+}
 
-	environmentName := env.GetOrFile(EnvEnvironment)
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	config := newDefaultConfig(e)
+
+	environmentName := e.GetOrFile(EnvEnvironment)
 	if environmentName != "" {
 		switch environmentName {
 		case "china":
@@ -115,20 +126,20 @@ func NewDNSProvider() (*DNSProvider, error) {
 		config.Environment = cloud.AzurePublic
 	}
 
-	config.SubscriptionID = env.GetOrFile(EnvSubscriptionID)
-	config.ResourceGroup = env.GetOrFile(EnvResourceGroup)
-	config.PrivateZone = env.GetOrDefaultBool(EnvPrivateZone, false)
+	config.SubscriptionID = e.GetOrFile(EnvSubscriptionID)
+	config.ResourceGroup = e.GetOrFile(EnvResourceGroup)
+	config.PrivateZone = e.GetOrDefaultBool(EnvPrivateZone, false)
 
-	config.ClientID = env.GetOrFile(EnvClientID)
-	config.ClientSecret = env.GetOrFile(EnvClientSecret)
-	config.TenantID = env.GetOrFile(EnvTenantID)
+	config.ClientID = e.GetOrFile(EnvClientID)
+	config.ClientSecret = e.GetOrFile(EnvClientSecret)
+	config.TenantID = e.GetOrFile(EnvTenantID)
 
-	config.OIDCToken = env.GetOrFile(EnvOIDCToken)
-	config.OIDCTokenFilePath = env.GetOrFile(EnvOIDCTokenFilePath)
+	config.OIDCToken = e.GetOrFile(EnvOIDCToken)
+	config.OIDCTokenFilePath = e.GetOrFile(EnvOIDCTokenFilePath)
 
-	config.ServiceDiscoveryFilter = env.GetOrFile(EnvServiceDiscoveryFilter)
+	config.ServiceDiscoveryFilter = e.GetOrFile(EnvServiceDiscoveryFilter)
 
-	oidcValues, _ := env.GetWithFallback(
+	oidcValues, _ := e.GetWithFallback(
 		[]string{EnvOIDCRequestURL, EnvGitHubOIDCRequestURL},
 		[]string{EnvOIDCRequestToken, EnvGitHubOIDCRequestToken},
 	)
@@ -136,8 +147,8 @@ func NewDNSProvider() (*DNSProvider, error) {
 	config.OIDCRequestURL = oidcValues[EnvOIDCRequestURL]
 	config.OIDCRequestToken = oidcValues[EnvOIDCRequestToken]
 
-	config.AuthMethod = env.GetOrFile(EnvAuthMethod)
-	config.AuthMSITimeout = env.GetOrDefaultSecond(EnvAuthMSITimeout, 2*time.Second)
+	config.AuthMethod = e.GetOrFile(EnvAuthMethod)
+	config.AuthMSITimeout = e.GetOrDefaultSecond(EnvAuthMSITimeout, 2*time.Second)
 
 	return NewDNSProviderConfig(config)
 }
@@ -271,6 +282,21 @@ func getAuthZone(fqdn string) (string, error) {
 	return authZone, nil
 }
 
+// categorizeError categorizes the errors of the Azure API with their HTTP status codes.
+func categorizeError(err error) error {
+	var authErr *azidentity.AuthenticationFailedError
+	if errors.As(err, &authErr) {
+		return challenge.AuthError(err)
+	}
+
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return errutils.CategorizeStatusCode(respErr.StatusCode, err)
+	}
+
+	return err
+}
+
 func deref[T any](v *T) T {
 	if v == nil {
 		var zero T
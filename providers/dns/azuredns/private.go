@@ -12,6 +12,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 )
 
@@ -76,7 +77,7 @@ func (d *DNSProviderPrivate) Present(domain, _, keyAuth string) error {
 
 	var txtRecords []*armprivatedns.TxtRecord
 	for txt := range uniqRecords {
-		txtRecords = append(txtRecords, &armprivatedns.TxtRecord{Value: to.SliceOfPtrs(txt)})
+		txtRecords = append(txtRecords, &armprivatedns.TxtRecord{Value: to.SliceOfPtrs(dns01.SplitTXTValue(txt)...)})
 	}
 
 	rec := armprivatedns.RecordSet{
@@ -132,7 +133,7 @@ func (d *DNSProviderPrivate) getHostedZone(fqdn string) (ServiceDiscoveryZone, e
 
 	azureZone, exists := d.serviceDiscoveryZones[dns01.UnFqdn(authZone)]
 	if !exists {
-		return ServiceDiscoveryZone{}, fmt.Errorf("could not find zone (from discovery): %s", authZone)
+		return ServiceDiscoveryZone{}, challenge.NotFoundZone(fmt.Errorf("could not find zone (from discovery): %s", authZone))
 	}
 
 	return azureZone, nil
@@ -164,25 +165,37 @@ func newPrivateZoneClient(zone ServiceDiscoveryZone, credential azcore.TokenCred
 }
 
 func (c privateZoneClient) Get(ctx context.Context, subDomain string) (armprivatedns.RecordSetsClientGetResponse, error) {
-	return c.recordClient.Get(ctx, c.zone.ResourceGroup, c.zone.Name, armprivatedns.RecordTypeTXT, subDomain, nil)
+	resp, err := c.recordClient.Get(ctx, c.zone.ResourceGroup, c.zone.Name, armprivatedns.RecordTypeTXT, subDomain, nil)
+
+	return resp, categorizeError(err)
 }
 
 func (c privateZoneClient) CreateOrUpdate(ctx context.Context, subDomain string, rec armprivatedns.RecordSet) (armprivatedns.RecordSetsClientCreateOrUpdateResponse, error) {
-	return c.recordClient.CreateOrUpdate(ctx, c.zone.ResourceGroup, c.zone.Name, armprivatedns.RecordTypeTXT, subDomain, rec, nil)
+	resp, err := c.recordClient.CreateOrUpdate(ctx, c.zone.ResourceGroup, c.zone.Name, armprivatedns.RecordTypeTXT, subDomain, rec, nil)
+
+	return resp, categorizeError(err)
 }
 
 func (c privateZoneClient) Delete(ctx context.Context, subDomain string) (armprivatedns.RecordSetsClientDeleteResponse, error) {
-	return c.recordClient.Delete(ctx, c.zone.ResourceGroup, c.zone.Name, armprivatedns.RecordTypeTXT, subDomain, nil)
+	resp, err := c.recordClient.Delete(ctx, c.zone.ResourceGroup, c.zone.Name, armprivatedns.RecordTypeTXT, subDomain, nil)
+
+	return resp, categorizeError(err)
 }
 
 func privateUniqueRecords(recordSet armprivatedns.RecordSet, value string) map[string]struct{} {
 	uniqRecords := map[string]struct{}{value: {}}
 	if recordSet.Properties != nil && recordSet.Properties.TxtRecords != nil {
 		for _, txtRecord := range recordSet.Properties.TxtRecords {
-			// Assume Value doesn't contain multiple strings
-			if len(txtRecord.Value) > 0 {
-				uniqRecords[deref(txtRecord.Value[0])] = struct{}{}
+			if len(txtRecord.Value) == 0 {
+				continue
 			}
+
+			var values []string
+			for _, v := range txtRecord.Value {
+				values = append(values, deref(v))
+			}
+
+			uniqRecords[dns01.JoinTXTValue(values)] = struct{}{}
 		}
 	}
 
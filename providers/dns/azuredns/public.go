@@ -12,6 +12,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dns/armdns"
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 )
 
@@ -75,7 +76,7 @@ func (d *DNSProviderPublic) Present(domain, _, keyAuth string) error {
 
 	var txtRecords []*armdns.TxtRecord
 	for txt := range uniqRecords {
-		txtRecords = append(txtRecords, &armdns.TxtRecord{Value: to.SliceOfPtrs(txt)})
+		txtRecords = append(txtRecords, &armdns.TxtRecord{Value: to.SliceOfPtrs(dns01.SplitTXTValue(txt)...)})
 	}
 
 	rec := armdns.RecordSet{
@@ -131,7 +132,7 @@ func (d *DNSProviderPublic) getHostedZone(fqdn string) (ServiceDiscoveryZone, er
 
 	azureZone, exists := d.serviceDiscoveryZones[dns01.UnFqdn(authZone)]
 	if !exists {
-		return ServiceDiscoveryZone{}, fmt.Errorf("could not find zone (from discovery): %s", authZone)
+		return ServiceDiscoveryZone{}, challenge.NotFoundZone(fmt.Errorf("could not find zone (from discovery): %s", authZone))
 	}
 
 	return azureZone, nil
@@ -162,25 +163,37 @@ func newPublicZoneClient(zone ServiceDiscoveryZone, credential azcore.TokenCrede
 }
 
 func (c publicZoneClient) Get(ctx context.Context, subDomain string) (armdns.RecordSetsClientGetResponse, error) {
-	return c.recordClient.Get(ctx, c.zone.ResourceGroup, c.zone.Name, subDomain, armdns.RecordTypeTXT, nil)
+	resp, err := c.recordClient.Get(ctx, c.zone.ResourceGroup, c.zone.Name, subDomain, armdns.RecordTypeTXT, nil)
+
+	return resp, categorizeError(err)
 }
 
 func (c publicZoneClient) CreateOrUpdate(ctx context.Context, subDomain string, rec armdns.RecordSet) (armdns.RecordSetsClientCreateOrUpdateResponse, error) {
-	return c.recordClient.CreateOrUpdate(ctx, c.zone.ResourceGroup, c.zone.Name, subDomain, armdns.RecordTypeTXT, rec, nil)
+	resp, err := c.recordClient.CreateOrUpdate(ctx, c.zone.ResourceGroup, c.zone.Name, subDomain, armdns.RecordTypeTXT, rec, nil)
+
+	return resp, categorizeError(err)
 }
 
 func (c publicZoneClient) Delete(ctx context.Context, subDomain string) (armdns.RecordSetsClientDeleteResponse, error) {
-	return c.recordClient.Delete(ctx, c.zone.ResourceGroup, c.zone.Name, subDomain, armdns.RecordTypeTXT, nil)
+	resp, err := c.recordClient.Delete(ctx, c.zone.ResourceGroup, c.zone.Name, subDomain, armdns.RecordTypeTXT, nil)
+
+	return resp, categorizeError(err)
 }
 
 func publicUniqueRecords(recordSet armdns.RecordSet, value string) map[string]struct{} {
 	uniqRecords := map[string]struct{}{value: {}}
 	if recordSet.Properties != nil && recordSet.Properties.TxtRecords != nil {
 		for _, txtRecord := range recordSet.Properties.TxtRecords {
-			// Assume Value doesn't contain multiple strings
-			if len(txtRecord.Value) > 0 {
-				uniqRecords[deref(txtRecord.Value[0])] = struct{}{}
+			if len(txtRecord.Value) == 0 {
+				continue
 			}
+
+			var values []string
+			for _, v := range txtRecord.Value {
+				values = append(values, deref(v))
+			}
+
+			uniqRecords[dns01.JoinTXTValue(values)] = struct{}{}
 		}
 	}
 
@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pya789/lego/v4/challenge/dns01"
@@ -35,28 +37,50 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
 	}
 }
 
+type dnsClient interface {
+	List(ctx context.Context, opts *bunny.PaginationOptions) (*bunny.DNSZones, error)
+	AddDNSRecord(ctx context.Context, dnsZoneID int64, opts *bunny.AddOrUpdateDNSRecordOptions) (*bunny.DNSRecord, error)
+	DeleteDNSRecord(ctx context.Context, dnsZoneID int64, dnsRecordID int64) error
+}
+
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
-	client *bunny.Client
+	client dnsClient
+
+	recordIDs   map[string]int64
+	recordIDsMu sync.Mutex
+
+	// only for testing purpose.
+	findZoneByFqdn func(fqdn string) (string, error)
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for bunny.
 // Credentials must be passed in the environment variable: BUNNY_API_KEY.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIKey)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIKey)
 	if err != nil {
 		return nil, fmt.Errorf("bunny: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.APIKey = values[EnvAPIKey]
 
 	return NewDNSProviderConfig(config)
@@ -78,7 +102,12 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 
 	client := bunny.NewClient(config.APIKey)
 
-	return &DNSProvider{config: config, client: client}, nil
+	return &DNSProvider{
+		config:         config,
+		client:         client.DNSZone,
+		recordIDs:      make(map[string]int64),
+		findZoneByFqdn: dns01.FindZoneByFqdn,
+	}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
@@ -91,34 +120,39 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := getZone(info.EffectiveFQDN)
-	if err != nil {
-		return fmt.Errorf("bunny: could not find zone for domain %q: %w", domain, err)
-	}
-
 	ctx := context.Background()
 
-	zone, err := d.findZone(ctx, authZone)
+	zone, err := d.findZone(ctx, info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("bunny: %w", err)
 	}
 
-	subDomain, err := dns01.ExtractSubDomain(info.EffectiveFQDN, authZone)
+	subDomain, err := dns01.ExtractSubDomain(info.EffectiveFQDN, deref(zone.Domain))
 	if err != nil {
 		return fmt.Errorf("bunny: %w", err)
 	}
 
+	// The record is explicitly enabled, and not linked to a pull zone (CDN).
 	record := &bunny.AddOrUpdateDNSRecordOptions{
-		Type:  pointer(bunny.DNSRecordTypeTXT),
-		Name:  pointer(subDomain),
-		Value: pointer(info.Value),
-		TTL:   pointer(int32(d.config.TTL)),
+		Type:        pointer(bunny.DNSRecordTypeTXT),
+		Name:        pointer(subDomain),
+		Value:       pointer(info.Value),
+		TTL:         pointer(int32(d.config.TTL)),
+		Accelerated: pointer(false),
+		Disabled:    pointer(false),
 	}
 
-	if _, err := d.client.DNSZone.AddDNSRecord(ctx, deref(zone.ID), record); err != nil {
+	newRecord, err := d.client.AddDNSRecord(ctx, deref(zone.ID), record)
+	if err != nil {
 		return fmt.Errorf("bunny: failed to add TXT record: fqdn=%s, zoneID=%d: %w", info.EffectiveFQDN, deref(zone.ID), err)
 	}
 
+	if newRecord != nil && newRecord.ID != nil {
+		d.recordIDsMu.Lock()
+		d.recordIDs[token] = deref(newRecord.ID)
+		d.recordIDsMu.Unlock()
+	}
+
 	return nil
 }
 
@@ -126,55 +160,90 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := getZone(info.EffectiveFQDN)
-	if err != nil {
-		return fmt.Errorf("bunny: could not find zone for domain %q: %w", domain, err)
-	}
-
 	ctx := context.Background()
 
-	zone, err := d.findZone(ctx, authZone)
+	zone, err := d.findZone(ctx, info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("bunny: %w", err)
 	}
 
-	subDomain, err := dns01.ExtractSubDomain(info.EffectiveFQDN, authZone)
-	if err != nil {
-		return fmt.Errorf("bunny: %w", err)
-	}
+	d.recordIDsMu.Lock()
+	recordID, ok := d.recordIDs[token]
+	d.recordIDsMu.Unlock()
 
-	var record *bunny.DNSRecord
-	for _, r := range zone.Records {
-		if deref(r.Name) == subDomain && deref(r.Type) == bunny.DNSRecordTypeTXT {
-			r := r
-			record = &r
-			break
+	if !ok {
+		// the record was not created by this instance (ex: deferred clean-up): the record is found by its value.
+		subDomain, errS := dns01.ExtractSubDomain(info.EffectiveFQDN, deref(zone.Domain))
+		if errS != nil {
+			return fmt.Errorf("bunny: %w", errS)
 		}
-	}
 
-	if record == nil {
-		return fmt.Errorf("bunny: could not find TXT record zone=%d, subdomain=%s", deref(zone.ID), subDomain)
+		record := findTXTRecord(zone.Records, subDomain, info.Value)
+		if record == nil {
+			return fmt.Errorf("bunny: could not find TXT record zone=%d, subdomain=%s", deref(zone.ID), subDomain)
+		}
+
+		recordID = deref(record.ID)
 	}
 
-	if err := d.client.DNSZone.DeleteDNSRecord(ctx, deref(zone.ID), deref(record.ID)); err != nil {
-		return fmt.Errorf("bunny: failed to delete TXT record: id=%d, name=%s: %w", deref(record.ID), deref(record.Name), err)
+	if err := d.client.DeleteDNSRecord(ctx, deref(zone.ID), recordID); err != nil {
+		return fmt.Errorf("bunny: failed to delete TXT record: id=%d, fqdn=%s: %w", recordID, info.EffectiveFQDN, err)
 	}
 
+	d.recordIDsMu.Lock()
+	delete(d.recordIDs, token)
+	d.recordIDsMu.Unlock()
+
 	return nil
 }
 
-func (d *DNSProvider) findZone(ctx context.Context, authZone string) (*bunny.DNSZone, error) {
-	zones, err := d.client.DNSZone.List(ctx, nil)
+// findZone finds the zone of the FQDN in all the zones of the account.
+// The zone found by the SOA lookup is preferred, otherwise the zone with the longest matching domain is used
+// (ex: a zone delegated from a parent zone hosted elsewhere).
+func (d *DNSProvider) findZone(ctx context.Context, fqdn string) (*bunny.DNSZone, error) {
+	authZone, err := d.findZoneByFqdn(fqdn)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not find zone for %q: %w", fqdn, err)
 	}
 
+	authZone = dns01.UnFqdn(authZone)
+	name := dns01.UnFqdn(fqdn)
+
 	var zone *bunny.DNSZone
-	for _, item := range zones.Items {
-		if item != nil && deref(item.Domain) == authZone {
-			zone = item
+
+	opts := &bunny.PaginationOptions{Page: 1}
+
+	for {
+		zones, err := d.client.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range zones.Items {
+			if item == nil {
+				continue
+			}
+
+			domain := deref(item.Domain)
+
+			if domain == authZone {
+				return item, nil
+			}
+
+			if !strings.HasSuffix(name, "."+domain) {
+				continue
+			}
+
+			if zone == nil || len(domain) > len(deref(zone.Domain)) {
+				zone = item
+			}
+		}
+
+		if !deref(zones.HasMoreItems) {
 			break
 		}
+
+		opts.Page++
 	}
 
 	if zone == nil {
@@ -184,20 +253,27 @@ func (d *DNSProvider) findZone(ctx context.Context, authZone string) (*bunny.DNS
 	return zone, nil
 }
 
-func getZone(fqdn string) (string, error) {
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
-	if err != nil {
-		return "", err
-	}
+// findTXTRecord finds the TXT record with the given name and value.
+// The records linked to a pull zone (CDN) and the disabled records are ignored.
+func findTXTRecord(records []bunny.DNSRecord, subDomain, value string) *bunny.DNSRecord {
+	for _, r := range records {
+		if deref(r.Type) != bunny.DNSRecordTypeTXT || deref(r.Name) != subDomain || deref(r.Value) != value {
+			continue
+		}
 
-	zone := dns01.UnFqdn(authZone)
+		if deref(r.LinkName) != "" || deref(r.AcceleratedPullZoneID) != 0 || deref(r.Disabled) {
+			continue
+		}
 
-	return zone, nil
+		return &r
+	}
+
+	return nil
 }
 
-func pointer[T string | int | int32 | int64](v T) *T { return &v }
+func pointer[T string | int | int32 | int64 | bool](v T) *T { return &v }
 
-func deref[T string | int | int32 | int64](v *T) T {
+func deref[T string | int | int32 | int64 | bool](v *T) T {
 	if v == nil {
 		var zero T
 		return zero
@@ -0,0 +1,214 @@
+package bunny
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nrdcg/bunny-go"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider_Present_mock(t *testing.T) {
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	testCases := []struct {
+		desc          string
+		authZone      string
+		pages         []*bunny.DNSZones
+		expectedName  string
+		addErr        error
+		expectedError string
+	}{
+		{
+			desc:     "success",
+			authZone: "example.com.",
+			pages: []*bunny.DNSZones{
+				{Items: []*bunny.DNSZone{{ID: pointer[int64](1), Domain: pointer("example.com")}}},
+			},
+			expectedName: "_acme-challenge.sub",
+		},
+		{
+			desc:     "zone on the second page",
+			authZone: "example.com.",
+			pages: []*bunny.DNSZones{
+				{Items: []*bunny.DNSZone{{ID: pointer[int64](2), Domain: pointer("example.org")}}, HasMoreItems: pointer(true)},
+				{Items: []*bunny.DNSZone{{ID: pointer[int64](1), Domain: pointer("example.com")}}},
+			},
+			expectedName: "_acme-challenge.sub",
+		},
+		{
+			// the zone found by the SOA lookup is not hosted by Bunny, the parent zone is used.
+			desc:     "linked zone",
+			authZone: "sub.example.com.",
+			pages: []*bunny.DNSZones{
+				{Items: []*bunny.DNSZone{
+					{ID: pointer[int64](3), Domain: pointer("com")},
+					{ID: pointer[int64](1), Domain: pointer("example.com")},
+					{ID: pointer[int64](4), Domain: pointer("other.example.com")},
+				}},
+			},
+			expectedName: "_acme-challenge.sub",
+		},
+		{
+			desc:     "zone not found",
+			authZone: "example.com.",
+			pages: []*bunny.DNSZones{
+				{Items: []*bunny.DNSZone{{ID: pointer[int64](2), Domain: pointer("example.org")}}},
+			},
+			expectedError: "bunny: could not find DNSZone zone=example.com",
+		},
+		{
+			desc:     "add error",
+			authZone: "example.com.",
+			pages: []*bunny.DNSZones{
+				{Items: []*bunny.DNSZone{{ID: pointer[int64](1), Domain: pointer("example.com")}}},
+			},
+			expectedName:  "_acme-challenge.sub",
+			addErr:        errors.New("oops"),
+			expectedError: "bunny: failed to add TXT record: fqdn=_acme-challenge.sub.example.com., zoneID=1: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client, test.authZone)
+
+			for i, page := range test.pages {
+				client.On("List", int32(i+1)).Return(page, nil).Once()
+			}
+
+			if test.expectedName != "" {
+				client.On("AddDNSRecord", int64(1), &bunny.AddOrUpdateDNSRecordOptions{
+					Type:        pointer(bunny.DNSRecordTypeTXT),
+					Name:        pointer(test.expectedName),
+					Value:       pointer(info.Value),
+					TTL:         pointer(int32(minTTL)),
+					Accelerated: pointer(false),
+					Disabled:    pointer(false),
+				}).Return(&bunny.DNSRecord{ID: pointer[int64](42)}, test.addErr)
+			}
+
+			err := provider.Present("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				assert.Equal(t, map[string]int64{"token": 42}, provider.recordIDs)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+				assert.Empty(t, provider.recordIDs)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_CleanUp_mock(t *testing.T) {
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	testCases := []struct {
+		desc             string
+		recordIDs        map[string]int64
+		records          []bunny.DNSRecord
+		expectedRecordID int64
+		expectedError    string
+	}{
+		{
+			desc:             "known record ID",
+			recordIDs:        map[string]int64{"token": 42},
+			expectedRecordID: 42,
+		},
+		{
+			desc:      "record found by its value",
+			recordIDs: map[string]int64{},
+			records: []bunny.DNSRecord{
+				// a record linked to a pull zone (CDN).
+				{ID: pointer[int64](10), Type: pointer(bunny.DNSRecordTypeTXT), Name: pointer("_acme-challenge.sub"), Value: pointer(info.Value), LinkName: pointer("cdn")},
+				// a disabled record.
+				{ID: pointer[int64](11), Type: pointer(bunny.DNSRecordTypeTXT), Name: pointer("_acme-challenge.sub"), Value: pointer(info.Value), Disabled: pointer(true)},
+				// another TXT record with the same name.
+				{ID: pointer[int64](12), Type: pointer(bunny.DNSRecordTypeTXT), Name: pointer("_acme-challenge.sub"), Value: pointer("other")},
+				{ID: pointer[int64](13), Type: pointer(bunny.DNSRecordTypeTXT), Name: pointer("_acme-challenge.sub"), Value: pointer(info.Value)},
+			},
+			expectedRecordID: 13,
+		},
+		{
+			desc:      "record not found",
+			recordIDs: map[string]int64{},
+			records: []bunny.DNSRecord{
+				{ID: pointer[int64](12), Type: pointer(bunny.DNSRecordTypeTXT), Name: pointer("_acme-challenge.sub"), Value: pointer("other")},
+			},
+			expectedError: "bunny: could not find TXT record zone=1, subdomain=_acme-challenge.sub",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client, "example.com.")
+			provider.recordIDs = test.recordIDs
+
+			client.On("List", int32(1)).Return(&bunny.DNSZones{Items: []*bunny.DNSZone{
+				{ID: pointer[int64](1), Domain: pointer("example.com"), Records: test.records},
+			}}, nil)
+
+			if test.expectedRecordID != 0 {
+				client.On("DeleteDNSRecord", int64(1), test.expectedRecordID).Return(nil)
+			}
+
+			err := provider.CleanUp("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				assert.Empty(t, provider.recordIDs)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func newMockedProvider(t *testing.T, client dnsClient, authZone string) *DNSProvider {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client = client
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return authZone, nil
+	}
+
+	return provider
+}
+
+type mockedClient struct {
+	mock.Mock
+}
+
+func (c *mockedClient) List(_ context.Context, opts *bunny.PaginationOptions) (*bunny.DNSZones, error) {
+	args := c.Called(opts.Page)
+	return args.Get(0).(*bunny.DNSZones), args.Error(1)
+}
+
+func (c *mockedClient) AddDNSRecord(_ context.Context, dnsZoneID int64, opts *bunny.AddOrUpdateDNSRecordOptions) (*bunny.DNSRecord, error) {
+	args := c.Called(dnsZoneID, opts)
+	return args.Get(0).(*bunny.DNSRecord), args.Error(1)
+}
+
+func (c *mockedClient) DeleteDNSRecord(_ context.Context, dnsZoneID int64, dnsRecordID int64) error {
+	args := c.Called(dnsZoneID, dnsRecordID)
+	return args.Error(0)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/platform/wait"
+	"github.com/pya789/lego/v4/providers/dns/internal/retryhttp"
 )
 
 const (
@@ -35,21 +37,35 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt("CLOUDFLARE_TTL", minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond("CLOUDFLARE_PROPAGATION_TIMEOUT", 2*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond("CLOUDFLARE_POLLING_INTERVAL", 2*time.Second),
-		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond("CLOUDFLARE_HTTP_TIMEOUT", 30*time.Second),
-		},
+		TTL:                e.GetOrDefaultInt("CLOUDFLARE_TTL", minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond("CLOUDFLARE_PROPAGATION_TIMEOUT", 2*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond("CLOUDFLARE_POLLING_INTERVAL", 2*time.Second),
+		// the rate limits (429) and the server errors are retried, honoring the Retry-After header.
+		HTTPClient: retryhttp.NewClient(e.GetOrDefaultSecond("CLOUDFLARE_HTTP_TIMEOUT", 30*time.Second)),
 	}
 }
 
+type dnsClient interface {
+	ZoneIDByName(fdqn string) (string, error)
+	CreateDNSRecord(ctx context.Context, zoneID string, rr cloudflare.CreateDNSRecordParams) (cloudflare.DNSRecord, error)
+	DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error
+	ZoneNameServers(ctx context.Context, zoneID string) ([]string, error)
+	VerifyCredentials(ctx context.Context) error
+}
+
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
-	client *metaClient
+	client dnsClient
 	config *Config
 
+	// only for testing purpose.
+	findZoneByFqdn func(fqdn string) (string, error)
+
 	recordIDs   map[string]string
 	recordIDsMu sync.Mutex
 }
@@ -67,13 +83,19 @@ type DNSProvider struct {
 // You can split the Zone:Read and DNS:Edit permissions across multiple API tokens:
 // in this case pass both CLOUDFLARE_ZONE_API_TOKEN and CLOUDFLARE_DNS_API_TOKEN accordingly.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.GetWithFallback(
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.GetWithFallback(
 		[]string{"CLOUDFLARE_EMAIL", "CF_API_EMAIL"},
 		[]string{"CLOUDFLARE_API_KEY", "CF_API_KEY"},
 	)
 	if err != nil {
 		var errT error
-		values, errT = env.GetWithFallback(
+		values, errT = e.GetWithFallback(
 			[]string{"CLOUDFLARE_DNS_API_TOKEN", "CF_DNS_API_TOKEN"},
 			[]string{"CLOUDFLARE_ZONE_API_TOKEN", "CF_ZONE_API_TOKEN", "CLOUDFLARE_DNS_API_TOKEN", "CF_DNS_API_TOKEN"},
 		)
@@ -83,7 +105,7 @@ func NewDNSProvider() (*DNSProvider, error) {
 		}
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.AuthEmail = values["CLOUDFLARE_EMAIL"]
 	config.AuthKey = values["CLOUDFLARE_API_KEY"]
 	config.AuthToken = values["CLOUDFLARE_DNS_API_TOKEN"]
@@ -108,9 +130,10 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 	}
 
 	return &DNSProvider{
-		client:    client,
-		config:    config,
-		recordIDs: make(map[string]string),
+		client:         client,
+		config:         config,
+		findZoneByFqdn: dns01.FindZoneByFqdn,
+		recordIDs:      make(map[string]string),
 	}, nil
 }
 
@@ -124,7 +147,7 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("cloudflare: could not find zone for domain %q: %w", domain, err)
 	}
@@ -155,11 +178,51 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	return nil
 }
 
+// WaitForPropagation waits until the TXT record is served by the Cloudflare authoritative nameservers of the zone.
+// It avoids waiting for the propagation through the public recursive nameservers.
+func (d *DNSProvider) WaitForPropagation(domain, token, keyAuth string) error {
+	return d.WaitForPropagationContext(context.Background(), domain, token, keyAuth)
+}
+
+// WaitForPropagationContext is like WaitForPropagation, the polling stops when the context is done.
+func (d *DNSProvider) WaitForPropagationContext(ctx context.Context, domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
+	if err != nil {
+		return fmt.Errorf("cloudflare: could not find zone for domain %q: %w", domain, err)
+	}
+
+	zoneID, err := d.client.ZoneIDByName(authZone)
+	if err != nil {
+		return fmt.Errorf("cloudflare: failed to find zone %s: %w", authZone, err)
+	}
+
+	nameservers, err := d.client.ZoneNameServers(ctx, zoneID)
+	if err != nil {
+		return fmt.Errorf("cloudflare: failed to get the nameservers of the zone %s: %w", authZone, err)
+	}
+
+	if len(nameservers) == 0 {
+		return fmt.Errorf("cloudflare: no nameservers for the zone %s", authZone)
+	}
+
+	return wait.ForContext(ctx, "cloudflare propagation", d.config.PropagationTimeout, d.config.PollingInterval, func() (bool, error) {
+		return dns01.CheckAuthoritativeNameservers(info.EffectiveFQDN, info.Value, nameservers)
+	})
+}
+
+// ChecksAuthoritativeNameservers reports that WaitForPropagation queries the authoritative nameservers (DNS queries):
+// the waiter is not used when the propagation check on the authoritative nameservers is disabled.
+func (d *DNSProvider) ChecksAuthoritativeNameservers() bool {
+	return true
+}
+
 // CleanUp removes the TXT record matching the specified parameters.
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("cloudflare: could not find zone for domain %q: %w", domain, err)
 	}
@@ -179,7 +242,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	err = d.client.DeleteDNSRecord(context.Background(), zoneID, recordID)
 	if err != nil {
-		log.Printf("cloudflare: failed to delete TXT record: %w", err)
+		log.Printf("cloudflare: failed to delete TXT record: %v", err)
 	}
 
 	// Delete record ID from map
@@ -189,3 +252,13 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	return nil
 }
+
+// VerifyCredentials checks the tokens (or the API key), without changing the records.
+func (d *DNSProvider) VerifyCredentials(ctx context.Context) error {
+	err := d.client.VerifyCredentials(ctx)
+	if err != nil {
+		return fmt.Errorf("cloudflare: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider_Present_mock(t *testing.T) {
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	testCases := []struct {
+		desc          string
+		zoneIDErr     error
+		createErr     error
+		callCreate    bool
+		expectedError string
+	}{
+		{
+			desc:       "success",
+			callCreate: true,
+		},
+		{
+			desc:          "zone ID error",
+			zoneIDErr:     errors.New("zone not found"),
+			expectedError: "cloudflare: failed to find zone example.com.: zone not found",
+		},
+		{
+			desc:          "create error",
+			createErr:     errors.New("oops"),
+			callCreate:    true,
+			expectedError: "cloudflare: failed to create TXT record: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("ZoneIDByName", "example.com.").Return("zoneA", test.zoneIDErr)
+
+			if test.callCreate {
+				client.On("CreateDNSRecord", "zoneA", cloudflare.CreateDNSRecordParams{
+					Type:    "TXT",
+					Name:    "_acme-challenge.sub.example.com",
+					Content: info.Value,
+					TTL:     provider.config.TTL,
+				}).Return(cloudflare.DNSRecord{ID: "recordA"}, test.createErr)
+			}
+
+			err := provider.Present("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				assert.Equal(t, map[string]string{"token": "recordA"}, provider.recordIDs)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+				assert.Empty(t, provider.recordIDs)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_CleanUp_mock(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		recordIDs     map[string]string
+		deleteErr     error
+		callDelete    bool
+		expectedError string
+	}{
+		{
+			desc:       "success",
+			recordIDs:  map[string]string{"token": "recordA"},
+			callDelete: true,
+		},
+		{
+			desc:          "unknown record ID",
+			recordIDs:     map[string]string{},
+			expectedError: "cloudflare: unknown record ID for '_acme-challenge.sub.example.com.'",
+		},
+		{
+			// the error is only logged: the record is forgotten.
+			desc:       "delete error",
+			recordIDs:  map[string]string{"token": "recordA"},
+			deleteErr:  errors.New("oops"),
+			callDelete: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+			provider.recordIDs = test.recordIDs
+
+			client.On("ZoneIDByName", "example.com.").Return("zoneA", nil)
+
+			if test.callDelete {
+				client.On("DeleteDNSRecord", "zoneA", "recordA").Return(test.deleteErr)
+			}
+
+			err := provider.CleanUp("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				assert.Empty(t, provider.recordIDs)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func newMockedProvider(t *testing.T, client dnsClient) *DNSProvider {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.AuthToken = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client = client
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider
+}
+
+type mockedClient struct {
+	mock.Mock
+}
+
+func (c *mockedClient) ZoneIDByName(fdqn string) (string, error) {
+	args := c.Called(fdqn)
+	return args.String(0), args.Error(1)
+}
+
+func (c *mockedClient) CreateDNSRecord(_ context.Context, zoneID string, rr cloudflare.CreateDNSRecordParams) (cloudflare.DNSRecord, error) {
+	args := c.Called(zoneID, rr)
+	return args.Get(0).(cloudflare.DNSRecord), args.Error(1)
+}
+
+func (c *mockedClient) DeleteDNSRecord(_ context.Context, zoneID, recordID string) error {
+	args := c.Called(zoneID, recordID)
+	return args.Error(0)
+}
+
+func (c *mockedClient) ZoneNameServers(_ context.Context, zoneID string) ([]string, error) {
+	args := c.Called(zoneID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (c *mockedClient) VerifyCredentials(_ context.Context) error {
+	args := c.Called()
+	return args.Error(0)
+}
+
+func TestMetaClient_VerifyCredentials(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		status        string
+		statusCode    int
+		expectedError string
+	}{
+		{
+			desc:       "active",
+			status:     "active",
+			statusCode: http.StatusOK,
+		},
+		{
+			desc:          "disabled",
+			status:        "disabled",
+			statusCode:    http.StatusOK,
+			expectedError: "the API token abc is disabled",
+		},
+		{
+			desc:          "invalid",
+			statusCode:    http.StatusUnauthorized,
+			expectedError: "Invalid API Token (1000)",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			mux := http.NewServeMux()
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			mux.HandleFunc("/user/tokens/verify", func(rw http.ResponseWriter, _ *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				rw.WriteHeader(test.statusCode)
+
+				if test.statusCode != http.StatusOK {
+					_, _ = fmt.Fprint(rw, `{"success":false,"errors":[{"code":1000,"message":"Invalid API Token"}],"messages":[],"result":null}`)
+					return
+				}
+
+				_, _ = fmt.Fprintf(rw, `{"success":true,"errors":[],"messages":[],"result":{"id":"abc","status":%q}}`, test.status)
+			})
+
+			api, err := cloudflare.NewWithAPIToken("secret", cloudflare.BaseURL(server.URL))
+			require.NoError(t, err)
+
+			client := &metaClient{clientEdit: api, clientRead: api}
+
+			err = client.VerifyCredentials(context.Background())
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, test.expectedError)
+			assert.Equal(t, challenge.ErrorCategoryAuth, challenge.GetErrorCategory(err))
+		})
+	}
+}
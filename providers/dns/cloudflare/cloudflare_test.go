@@ -1,9 +1,17 @@
 package cloudflare
 
 import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/miekg/dns"
+	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/tester"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -191,10 +199,14 @@ func TestNewDNSProviderWithToken(t *testing.T) {
 			require.NotNil(t, p)
 			assert.Equal(t, test.expected.dnsToken, p.config.AuthToken)
 			assert.Equal(t, test.expected.zoneToken, p.config.ZoneToken)
+
+			client, ok := p.client.(*metaClient)
+			require.True(t, ok)
+
 			if test.expected.sameClient {
-				assert.Equal(t, p.client.clientRead, p.client.clientEdit)
+				assert.Equal(t, client.clientRead, client.clientEdit)
 			} else {
-				assert.NotEqual(t, p.client.clientRead, p.client.clientEdit)
+				assert.NotEqual(t, client.clientRead, client.clientEdit)
 			}
 		})
 	}
@@ -292,3 +304,119 @@ func TestLiveCleanUp(t *testing.T) {
 	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
 	require.NoError(t, err)
 }
+
+func TestDNSProvider_WaitForPropagation(t *testing.T) {
+	// TXT value of the key authorization "key".
+	const value = "LHDhK3oGRvkiefQnx7OOczTY5Tic_xZ6HcMOc_gmtoM"
+
+	testCases := []struct {
+		desc        string
+		txt         string
+		nameservers func(addr string) []string
+		expected    string
+	}{
+		{
+			desc:        "propagated",
+			txt:         value,
+			nameservers: func(addr string) []string { return []string{addr} },
+		},
+		{
+			desc:        "not propagated",
+			txt:         "other",
+			nameservers: func(addr string) []string { return []string{addr} },
+			expected:    "cloudflare propagation: time limit exceeded",
+		},
+		{
+			desc:        "no nameservers",
+			nameservers: func(string) []string { return nil },
+			expected:    "cloudflare: no nameservers for the zone example.com.",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			addr := setupDNSServer(t, test.txt)
+
+			mux := http.NewServeMux()
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			mux.HandleFunc("GET /zones/zoneID", func(rw http.ResponseWriter, _ *http.Request) {
+				_ = json.NewEncoder(rw).Encode(cloudflare.ZoneResponse{
+					Response: cloudflare.Response{Success: true},
+					Result:   cloudflare.Zone{ID: "zoneID", Name: "example.com", NameServers: test.nameservers(addr)},
+				})
+			})
+
+			api, err := cloudflare.NewWithAPIToken("secret", cloudflare.BaseURL(server.URL))
+			require.NoError(t, err)
+
+			config := NewDefaultConfig()
+			config.PropagationTimeout = 500 * time.Millisecond
+			config.PollingInterval = 100 * time.Millisecond
+
+			p := &DNSProvider{
+				client: &metaClient{
+					clientEdit: api,
+					clientRead: api,
+					zones:      map[string]string{"example.com.": "zoneID"},
+					zonesMu:    &sync.RWMutex{},
+				},
+				config:         config,
+				findZoneByFqdn: dns01.FindZoneByFqdn,
+				recordIDs:      make(map[string]string),
+			}
+
+			err = p.WaitForPropagation("example.com", "token", "key")
+			if test.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.expected)
+			}
+		})
+	}
+}
+
+// setupDNSServer starts a DNS server, used as recursive and authoritative nameserver of the zone example.com.
+// It returns the TXT record txt for all the TXT queries.
+func setupDNSServer(t *testing.T, txt string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(req)
+
+			q := req.Question[0]
+
+			switch q.Qtype {
+			case dns.TypeSOA:
+				m.Answer = append(m.Answer, &dns.SOA{
+					Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 60},
+					Ns:  "ns.example.com.", Mbox: "admin.example.com.", Minttl: 60,
+				})
+			case dns.TypeTXT:
+				m.Answer = append(m.Answer, &dns.TXT{
+					Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60},
+					Txt: []string{txt},
+				})
+			}
+
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	require.NoError(t, dns01.AddRecursiveNameservers([]string{conn.LocalAddr().String()})(&dns01.Challenge{}))
+
+	dns01.ClearFqdnCache()
+	t.Cleanup(dns01.ClearFqdnCache)
+
+	return conn.LocalAddr().String()
+}
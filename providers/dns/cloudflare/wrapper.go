@@ -2,10 +2,15 @@ package cloudflare
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/cloudflare/cloudflare-go"
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/providers/dns/internal/retryhttp"
 )
 
 type metaClient struct {
@@ -17,9 +22,11 @@ type metaClient struct {
 }
 
 func newClient(config *Config) (*metaClient, error) {
+	opts := clientOptions(config)
+
 	// with AuthKey/AuthEmail we can access all available APIs
 	if config.AuthToken == "" {
-		client, err := cloudflare.New(config.AuthKey, config.AuthEmail, cloudflare.HTTPClient(config.HTTPClient))
+		client, err := cloudflare.New(config.AuthKey, config.AuthEmail, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -32,7 +39,7 @@ func newClient(config *Config) (*metaClient, error) {
 		}, nil
 	}
 
-	dns, err := cloudflare.NewWithAPIToken(config.AuthToken, cloudflare.HTTPClient(config.HTTPClient))
+	dns, err := cloudflare.NewWithAPIToken(config.AuthToken, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +53,7 @@ func newClient(config *Config) (*metaClient, error) {
 		}, nil
 	}
 
-	zone, err := cloudflare.NewWithAPIToken(config.ZoneToken, cloudflare.HTTPClient(config.HTTPClient))
+	zone, err := cloudflare.NewWithAPIToken(config.ZoneToken, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -59,16 +66,43 @@ func newClient(config *Config) (*metaClient, error) {
 	}, nil
 }
 
+// clientOptions returns the options of the Cloudflare clients.
+// The retries of the library are disabled if the HTTP client already retries the requests (retryhttp):
+// unlike the library, it honors the Retry-After header of the rate limits.
+func clientOptions(config *Config) []cloudflare.Option {
+	opts := []cloudflare.Option{cloudflare.HTTPClient(config.HTTPClient)}
+
+	if retryhttp.IsRetrying(config.HTTPClient) {
+		opts = append(opts, cloudflare.UsingRetryPolicy(0, 0, 0))
+	}
+
+	return opts
+}
+
 func (m *metaClient) CreateDNSRecord(ctx context.Context, zoneID string, rr cloudflare.CreateDNSRecordParams) (cloudflare.DNSRecord, error) {
-	return m.clientEdit.CreateDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), rr)
+	record, err := m.clientEdit.CreateDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), rr)
+
+	return record, categorizeError(err)
 }
 
 func (m *metaClient) DNSRecords(ctx context.Context, zoneID string, rr cloudflare.ListDNSRecordsParams) ([]cloudflare.DNSRecord, *cloudflare.ResultInfo, error) {
-	return m.clientEdit.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), rr)
+	records, info, err := m.clientEdit.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), rr)
+
+	return records, info, categorizeError(err)
 }
 
 func (m *metaClient) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
-	return m.clientEdit.DeleteDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), recordID)
+	return categorizeError(m.clientEdit.DeleteDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), recordID))
+}
+
+// ZoneNameServers returns the authoritative nameservers assigned by Cloudflare to the zone.
+func (m *metaClient) ZoneNameServers(ctx context.Context, zoneID string) ([]string, error) {
+	zone, err := m.clientRead.ZoneDetails(ctx, zoneID)
+	if err != nil {
+		return nil, categorizeError(err)
+	}
+
+	return zone.NameServers, nil
 }
 
 func (m *metaClient) ZoneIDByName(fdqn string) (string, error) {
@@ -82,7 +116,12 @@ func (m *metaClient) ZoneIDByName(fdqn string) (string, error) {
 
 	id, err := m.clientRead.ZoneIDByName(dns01.UnFqdn(fdqn))
 	if err != nil {
-		return "", err
+		// The client doesn't provide a typed error when the zone is not found.
+		if strings.Contains(err.Error(), "zone could not be found") {
+			return "", challenge.NotFoundZone(err)
+		}
+
+		return "", categorizeError(err)
 	}
 
 	m.zonesMu.Lock()
@@ -90,3 +129,61 @@ func (m *metaClient) ZoneIDByName(fdqn string) (string, error) {
 	m.zonesMu.Unlock()
 	return id, nil
 }
+
+// VerifyCredentials checks the tokens (or the API key), without changing the records.
+func (m *metaClient) VerifyCredentials(ctx context.Context) error {
+	clients := []*cloudflare.API{m.clientEdit}
+	if m.clientRead != m.clientEdit {
+		clients = append(clients, m.clientRead)
+	}
+
+	for _, client := range clients {
+		err := verifyClient(ctx, client)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func verifyClient(ctx context.Context, client *cloudflare.API) error {
+	if client.APIToken == "" {
+		// API key and email
+		_, err := client.UserDetails(ctx)
+
+		return categorizeError(err)
+	}
+
+	token, err := client.VerifyAPIToken(ctx)
+	if err != nil {
+		return categorizeError(err)
+	}
+
+	if token.Status != "active" {
+		return challenge.AuthError(fmt.Errorf("the API token %s is %s", token.ID, token.Status))
+	}
+
+	return nil
+}
+
+// categorizeError categorizes the errors of the Cloudflare API with their types.
+func categorizeError(err error) error {
+	var typedErr interface{ Type() cloudflare.ErrorType }
+	if !errors.As(err, &typedErr) {
+		return err
+	}
+
+	switch typedErr.Type() {
+	case cloudflare.ErrorTypeAuthentication, cloudflare.ErrorTypeAuthorization:
+		return challenge.AuthError(err)
+	case cloudflare.ErrorTypeRateLimit:
+		return challenge.Throttled(err)
+	case cloudflare.ErrorTypeService:
+		return challenge.Transient(err)
+	case cloudflare.ErrorTypeRequest, cloudflare.ErrorTypeNotFound:
+		return challenge.Permanent(err)
+	default:
+		return err
+	}
+}
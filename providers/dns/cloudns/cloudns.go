@@ -42,12 +42,16 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 60),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 180*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 10*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 60),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 180*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 10*time.Second),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -62,22 +66,28 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // CLOUDNS_AUTH_ID and CLOUDNS_AUTH_PASSWORD.
 func NewDNSProvider() (*DNSProvider, error) {
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
 	var subAuthID string
-	authID := env.GetOrFile(EnvAuthID)
+	authID := e.GetOrFile(EnvAuthID)
 	if authID == "" {
-		subAuthID = env.GetOrFile(EnvSubAuthID)
+		subAuthID = e.GetOrFile(EnvSubAuthID)
 	}
 
 	if authID == "" && subAuthID == "" {
 		return nil, fmt.Errorf("ClouDNS: some credentials information are missing: %s or %s", EnvAuthID, EnvSubAuthID)
 	}
 
-	values, err := env.Get(EnvAuthPassword)
+	values, err := e.Get(EnvAuthPassword)
 	if err != nil {
 		return nil, fmt.Errorf("ClouDNS: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.AuthID = authID
 	config.SubAuthID = subAuthID
 	config.AuthPassword = values[EnvAuthPassword]
@@ -52,10 +52,14 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 10),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 10*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 10*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 10),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 10*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 10*time.Second),
 	}
 }
 
@@ -71,9 +75,15 @@ type DNSProvider struct {
 // OS_AUTH_URL, OS_USERNAME, OS_PASSWORD, OS_REGION_NAME.
 // Or you can specify OS_CLOUD to read the credentials from the according cloud entry.
 func NewDNSProvider() (*DNSProvider, error) {
-	config := NewDefaultConfig()
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	config := newDefaultConfig(e)
 
-	val, err := env.Get(EnvCloud)
+	val, err := e.Get(EnvCloud)
 	if err == nil {
 		opts, erro := clientconfig.AuthOptions(&clientconfig.ClientOpts{
 			Cloud: val[EnvCloud],
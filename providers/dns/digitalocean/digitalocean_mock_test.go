@@ -0,0 +1,139 @@
+package digitalocean
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/providers/dns/digitalocean/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider_Present_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	info := dns01.GetChallengeInfo("example.com", "key")
+
+	testCases := []struct {
+		desc          string
+		response      *internal.TxtRecordResponse
+		addErr        error
+		expectedError string
+	}{
+		{
+			desc:     "success",
+			response: &internal.TxtRecordResponse{DomainRecord: internal.Record{ID: 1234567}},
+		},
+		{
+			desc:          "add error",
+			addErr:        errors.New("oops"),
+			expectedError: "digitalocean: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			record := internal.Record{Type: "TXT", Name: "_acme-challenge.example.com.", Data: info.Value, TTL: provider.config.TTL}
+
+			client.On("AddTxtRecord", "example.com.", record).Return(test.response, test.addErr)
+
+			err := provider.Present("example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+				assert.NotContains(t, provider.recordIDs, "token")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 1234567, provider.recordIDs["token"])
+		})
+	}
+}
+
+func TestDNSProvider_CleanUp_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	t.Run("success", func(t *testing.T) {
+		client := &mockedClient{}
+
+		provider := newMockedProvider(t, client)
+		provider.recordIDs["token"] = 1234567
+
+		client.On("RemoveTxtRecord", "example.com.", 1234567).Return(nil)
+
+		err := provider.CleanUp("example.com", "token", "key")
+		require.NoError(t, err)
+
+		client.AssertExpectations(t)
+
+		assert.NotContains(t, provider.recordIDs, "token")
+	})
+
+	t.Run("unknown record ID", func(t *testing.T) {
+		client := &mockedClient{}
+
+		provider := newMockedProvider(t, client)
+
+		err := provider.CleanUp("example.com", "token", "key")
+		require.EqualError(t, err, "digitalocean: unknown record ID for '_acme-challenge.example.com.'")
+
+		client.AssertExpectations(t)
+	})
+
+	t.Run("remove error", func(t *testing.T) {
+		client := &mockedClient{}
+
+		provider := newMockedProvider(t, client)
+		provider.recordIDs["token"] = 1234567
+
+		client.On("RemoveTxtRecord", "example.com.", 1234567).Return(errors.New("oops"))
+
+		err := provider.CleanUp("example.com", "token", "key")
+		require.EqualError(t, err, "digitalocean: oops")
+
+		client.AssertExpectations(t)
+
+		assert.Contains(t, provider.recordIDs, "token")
+	})
+}
+
+func newMockedProvider(t *testing.T, client dnsClient) *DNSProvider {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.AuthToken = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client = client
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider
+}
+
+type mockedClient struct {
+	mock.Mock
+}
+
+func (c *mockedClient) AddTxtRecord(_ context.Context, zone string, record internal.Record) (*internal.TxtRecordResponse, error) {
+	args := c.Called(zone, record)
+	return args.Get(0).(*internal.TxtRecordResponse), args.Error(1)
+}
+
+func (c *mockedClient) RemoveTxtRecord(_ context.Context, zone string, recordID int) error {
+	args := c.Called(zone, recordID)
+	return args.Error(0)
+}
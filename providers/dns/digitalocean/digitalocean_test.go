@@ -30,6 +30,10 @@ func setupTest(t *testing.T) (*DNSProvider, *http.ServeMux) {
 	provider, err := NewDNSProviderConfig(config)
 	require.NoError(t, err)
 
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
 	return provider, mux
 }
 
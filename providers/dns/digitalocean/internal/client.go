@@ -125,7 +125,7 @@ func parseError(req *http.Request, resp *http.Response) error {
 		return errutils.NewUnexpectedStatusCodeError(req, resp.StatusCode, raw)
 	}
 
-	return fmt.Errorf("[status code %d] %w", resp.StatusCode, errInfo)
+	return errutils.CategorizeStatusCode(resp.StatusCode, fmt.Errorf("[status code %d] %w", resp.StatusCode, errInfo))
 }
 
 func OAuthStaticAccessToken(client *http.Client, accessToken string) *http.Client {
@@ -1,10 +1,9 @@
 package dns
 
 import (
-	"fmt"
-
 	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/platform/config/env"
 	"github.com/pya789/lego/v4/providers/dns/acmedns"
 	"github.com/pya789/lego/v4/providers/dns/alidns"
 	"github.com/pya789/lego/v4/providers/dns/allinkl"
@@ -47,6 +46,7 @@ import (
 	"github.com/pya789/lego/v4/providers/dns/epik"
 	"github.com/pya789/lego/v4/providers/dns/exec"
 	"github.com/pya789/lego/v4/providers/dns/exoscale"
+	"github.com/pya789/lego/v4/providers/dns/farm"
 	"github.com/pya789/lego/v4/providers/dns/freemyip"
 	"github.com/pya789/lego/v4/providers/dns/gandi"
 	"github.com/pya789/lego/v4/providers/dns/gandiv5"
@@ -106,6 +106,7 @@ import (
 	"github.com/pya789/lego/v4/providers/dns/rfc2136"
 	"github.com/pya789/lego/v4/providers/dns/rimuhosting"
 	"github.com/pya789/lego/v4/providers/dns/route53"
+	"github.com/pya789/lego/v4/providers/dns/router"
 	"github.com/pya789/lego/v4/providers/dns/safedns"
 	"github.com/pya789/lego/v4/providers/dns/sakuracloud"
 	"github.com/pya789/lego/v4/providers/dns/scaleway"
@@ -138,271 +139,283 @@ import (
 )
 
 // NewDNSChallengeProviderByName Factory for DNS providers.
+// The providers registered with RegisterProvider are used if the name doesn't match a built-in provider.
 func NewDNSChallengeProviderByName(name string) (challenge.Provider, error) {
+	return NewDNSChallengeProviderByNameFromEnv(name, env.Getter{})
+}
+
+// NewDNSChallengeProviderByNameFromEnv Factory for DNS providers,
+// the built-in providers are configured with the environment variables read by the getter (ex: with a prefix, see env.WithPrefix).
+// The providers registered with RegisterProvider don't use the getter.
+func NewDNSChallengeProviderByNameFromEnv(name string, e env.Getter) (challenge.Provider, error) {
 	switch name {
 	case "acme-dns": // TODO(ldez): remove "-" in v5
-		return acmedns.NewDNSProvider()
+		return acmedns.NewDNSProviderFromEnv(e)
 	case "alidns":
-		return alidns.NewDNSProvider()
+		return alidns.NewDNSProviderFromEnv(e)
 	case "allinkl":
-		return allinkl.NewDNSProvider()
+		return allinkl.NewDNSProviderFromEnv(e)
 	case "arvancloud":
-		return arvancloud.NewDNSProvider()
+		return arvancloud.NewDNSProviderFromEnv(e)
 	case "azure":
-		return azure.NewDNSProvider()
+		return azure.NewDNSProviderFromEnv(e)
 	case "azuredns":
-		return azuredns.NewDNSProvider()
+		return azuredns.NewDNSProviderFromEnv(e)
 	case "auroradns":
-		return auroradns.NewDNSProvider()
+		return auroradns.NewDNSProviderFromEnv(e)
 	case "autodns":
-		return autodns.NewDNSProvider()
+		return autodns.NewDNSProviderFromEnv(e)
 	case "bindman":
-		return bindman.NewDNSProvider()
+		return bindman.NewDNSProviderFromEnv(e)
 	case "bluecat":
-		return bluecat.NewDNSProvider()
+		return bluecat.NewDNSProviderFromEnv(e)
 	case "brandit":
-		return brandit.NewDNSProvider()
+		return brandit.NewDNSProviderFromEnv(e)
 	case "bunny":
-		return bunny.NewDNSProvider()
+		return bunny.NewDNSProviderFromEnv(e)
 	case "checkdomain":
-		return checkdomain.NewDNSProvider()
+		return checkdomain.NewDNSProviderFromEnv(e)
 	case "civo":
-		return civo.NewDNSProvider()
+		return civo.NewDNSProviderFromEnv(e)
 	case "clouddns":
-		return clouddns.NewDNSProvider()
+		return clouddns.NewDNSProviderFromEnv(e)
 	case "cloudflare":
-		return cloudflare.NewDNSProvider()
+		return cloudflare.NewDNSProviderFromEnv(e)
 	case "cloudns":
-		return cloudns.NewDNSProvider()
+		return cloudns.NewDNSProviderFromEnv(e)
 	case "cloudru":
-		return cloudru.NewDNSProvider()
+		return cloudru.NewDNSProviderFromEnv(e)
 	case "cloudxns":
-		return cloudxns.NewDNSProvider()
+		return cloudxns.NewDNSProviderFromEnv(e)
 	case "conoha":
-		return conoha.NewDNSProvider()
+		return conoha.NewDNSProviderFromEnv(e)
 	case "constellix":
-		return constellix.NewDNSProvider()
+		return constellix.NewDNSProviderFromEnv(e)
 	case "cpanel":
-		return cpanel.NewDNSProvider()
+		return cpanel.NewDNSProviderFromEnv(e)
 	case "derak":
-		return derak.NewDNSProvider()
+		return derak.NewDNSProviderFromEnv(e)
 	case "desec":
-		return desec.NewDNSProvider()
+		return desec.NewDNSProviderFromEnv(e)
 	case "designate":
-		return designate.NewDNSProvider()
+		return designate.NewDNSProviderFromEnv(e)
 	case "digitalocean":
-		return digitalocean.NewDNSProvider()
+		return digitalocean.NewDNSProviderFromEnv(e)
 	case "dnshomede":
-		return dnshomede.NewDNSProvider()
+		return dnshomede.NewDNSProviderFromEnv(e)
 	case "dnsimple":
-		return dnsimple.NewDNSProvider()
+		return dnsimple.NewDNSProviderFromEnv(e)
 	case "dnsmadeeasy":
-		return dnsmadeeasy.NewDNSProvider()
+		return dnsmadeeasy.NewDNSProviderFromEnv(e)
 	case "dnspod":
-		return dnspod.NewDNSProvider()
+		return dnspod.NewDNSProviderFromEnv(e)
 	case "dode":
-		return dode.NewDNSProvider()
+		return dode.NewDNSProviderFromEnv(e)
 	case "domeneshop", "domainnameshop":
-		return domeneshop.NewDNSProvider()
+		return domeneshop.NewDNSProviderFromEnv(e)
 	case "dreamhost":
-		return dreamhost.NewDNSProvider()
+		return dreamhost.NewDNSProviderFromEnv(e)
 	case "duckdns":
-		return duckdns.NewDNSProvider()
+		return duckdns.NewDNSProviderFromEnv(e)
 	case "dyn":
-		return dyn.NewDNSProvider()
+		return dyn.NewDNSProviderFromEnv(e)
 	case "dynu":
-		return dynu.NewDNSProvider()
+		return dynu.NewDNSProviderFromEnv(e)
 	case "easydns":
-		return easydns.NewDNSProvider()
+		return easydns.NewDNSProviderFromEnv(e)
 	case "edgedns", "fastdns": // "fastdns" is for compatibility with v3, must be dropped in v5
-		return edgedns.NewDNSProvider()
+		return edgedns.NewDNSProviderFromEnv(e)
 	case "efficientip":
-		return efficientip.NewDNSProvider()
+		return efficientip.NewDNSProviderFromEnv(e)
 	case "epik":
-		return epik.NewDNSProvider()
+		return epik.NewDNSProviderFromEnv(e)
 	case "exec":
-		return exec.NewDNSProvider()
+		return exec.NewDNSProviderFromEnv(e)
 	case "exoscale":
-		return exoscale.NewDNSProvider()
+		return exoscale.NewDNSProviderFromEnv(e)
+	case farm.Name:
+		return farm.NewDNSProviderFromEnv(e, NewDNSChallengeProviderByNameFromEnv)
 	case "freemyip":
-		return freemyip.NewDNSProvider()
+		return freemyip.NewDNSProviderFromEnv(e)
 	case "gandi":
-		return gandi.NewDNSProvider()
+		return gandi.NewDNSProviderFromEnv(e)
 	case "gandiv5":
-		return gandiv5.NewDNSProvider()
+		return gandiv5.NewDNSProviderFromEnv(e)
 	case "gcloud":
-		return gcloud.NewDNSProvider()
+		return gcloud.NewDNSProviderFromEnv(e)
 	case "gcore":
-		return gcore.NewDNSProvider()
+		return gcore.NewDNSProviderFromEnv(e)
 	case "glesys":
-		return glesys.NewDNSProvider()
+		return glesys.NewDNSProviderFromEnv(e)
 	case "godaddy":
-		return godaddy.NewDNSProvider()
+		return godaddy.NewDNSProviderFromEnv(e)
 	case "googledomains":
-		return googledomains.NewDNSProvider()
+		return googledomains.NewDNSProviderFromEnv(e)
 	case "hetzner":
-		return hetzner.NewDNSProvider()
+		return hetzner.NewDNSProviderFromEnv(e)
 	case "hostingde":
-		return hostingde.NewDNSProvider()
+		return hostingde.NewDNSProviderFromEnv(e)
 	case "hosttech":
-		return hosttech.NewDNSProvider()
+		return hosttech.NewDNSProviderFromEnv(e)
 	case "httpnet":
-		return httpnet.NewDNSProvider()
+		return httpnet.NewDNSProviderFromEnv(e)
 	case "httpreq":
-		return httpreq.NewDNSProvider()
+		return httpreq.NewDNSProviderFromEnv(e)
 	case "hurricane":
-		return hurricane.NewDNSProvider()
+		return hurricane.NewDNSProviderFromEnv(e)
 	case "hyperone":
-		return hyperone.NewDNSProvider()
+		return hyperone.NewDNSProviderFromEnv(e)
 	case "ibmcloud":
-		return ibmcloud.NewDNSProvider()
+		return ibmcloud.NewDNSProviderFromEnv(e)
 	case "iij":
-		return iij.NewDNSProvider()
+		return iij.NewDNSProviderFromEnv(e)
 	case "iijdpf":
-		return iijdpf.NewDNSProvider()
+		return iijdpf.NewDNSProviderFromEnv(e)
 	case "infoblox":
-		return infoblox.NewDNSProvider()
+		return infoblox.NewDNSProviderFromEnv(e)
 	case "infomaniak":
-		return infomaniak.NewDNSProvider()
+		return infomaniak.NewDNSProviderFromEnv(e)
 	case "internetbs":
-		return internetbs.NewDNSProvider()
+		return internetbs.NewDNSProviderFromEnv(e)
 	case "inwx":
-		return inwx.NewDNSProvider()
+		return inwx.NewDNSProviderFromEnv(e)
 	case "ionos":
-		return ionos.NewDNSProvider()
+		return ionos.NewDNSProviderFromEnv(e)
 	case "ipv64":
-		return ipv64.NewDNSProvider()
+		return ipv64.NewDNSProviderFromEnv(e)
 	case "iwantmyname":
-		return iwantmyname.NewDNSProvider()
+		return iwantmyname.NewDNSProviderFromEnv(e)
 	case "joker":
-		return joker.NewDNSProvider()
+		return joker.NewDNSProviderFromEnv(e)
 	case "liara":
-		return liara.NewDNSProvider()
+		return liara.NewDNSProviderFromEnv(e)
 	case "lightsail":
-		return lightsail.NewDNSProvider()
+		return lightsail.NewDNSProviderFromEnv(e)
 	case "linode", "linodev4": // "linodev4" is for compatibility with v3, must be dropped in v5
-		return linode.NewDNSProvider()
+		return linode.NewDNSProviderFromEnv(e)
 	case "liquidweb":
-		return liquidweb.NewDNSProvider()
+		return liquidweb.NewDNSProviderFromEnv(e)
 	case "loopia":
-		return loopia.NewDNSProvider()
+		return loopia.NewDNSProviderFromEnv(e)
 	case "luadns":
-		return luadns.NewDNSProvider()
+		return luadns.NewDNSProviderFromEnv(e)
 	case "mailinabox":
-		return mailinabox.NewDNSProvider()
+		return mailinabox.NewDNSProviderFromEnv(e)
 	case "manual":
 		return dns01.NewDNSProviderManual()
 	case "metaname":
-		return metaname.NewDNSProvider()
+		return metaname.NewDNSProviderFromEnv(e)
 	case "mydnsjp":
-		return mydnsjp.NewDNSProvider()
+		return mydnsjp.NewDNSProviderFromEnv(e)
 	case "mythicbeasts":
-		return mythicbeasts.NewDNSProvider()
+		return mythicbeasts.NewDNSProviderFromEnv(e)
 	case "namecheap":
-		return namecheap.NewDNSProvider()
+		return namecheap.NewDNSProviderFromEnv(e)
 	case "namedotcom":
-		return namedotcom.NewDNSProvider()
+		return namedotcom.NewDNSProviderFromEnv(e)
 	case "namesilo":
-		return namesilo.NewDNSProvider()
+		return namesilo.NewDNSProviderFromEnv(e)
 	case "nearlyfreespeech":
-		return nearlyfreespeech.NewDNSProvider()
+		return nearlyfreespeech.NewDNSProviderFromEnv(e)
 	case "netcup":
-		return netcup.NewDNSProvider()
+		return netcup.NewDNSProviderFromEnv(e)
 	case "netlify":
-		return netlify.NewDNSProvider()
+		return netlify.NewDNSProviderFromEnv(e)
 	case "nicmanager":
-		return nicmanager.NewDNSProvider()
+		return nicmanager.NewDNSProviderFromEnv(e)
 	case "nifcloud":
-		return nifcloud.NewDNSProvider()
+		return nifcloud.NewDNSProviderFromEnv(e)
 	case "njalla":
-		return njalla.NewDNSProvider()
+		return njalla.NewDNSProviderFromEnv(e)
 	case "nodion":
-		return nodion.NewDNSProvider()
+		return nodion.NewDNSProviderFromEnv(e)
 	case "ns1":
-		return ns1.NewDNSProvider()
+		return ns1.NewDNSProviderFromEnv(e)
 	case "oraclecloud":
-		return oraclecloud.NewDNSProvider()
+		return oraclecloud.NewDNSProviderFromEnv(e)
 	case "otc":
-		return otc.NewDNSProvider()
+		return otc.NewDNSProviderFromEnv(e)
 	case "ovh":
-		return ovh.NewDNSProvider()
+		return ovh.NewDNSProviderFromEnv(e)
 	case "pdns":
-		return pdns.NewDNSProvider()
+		return pdns.NewDNSProviderFromEnv(e)
 	case "plesk":
-		return plesk.NewDNSProvider()
+		return plesk.NewDNSProviderFromEnv(e)
 	case "porkbun":
-		return porkbun.NewDNSProvider()
+		return porkbun.NewDNSProviderFromEnv(e)
 	case "rackspace":
-		return rackspace.NewDNSProvider()
+		return rackspace.NewDNSProviderFromEnv(e)
 	case "rcodezero":
-		return rcodezero.NewDNSProvider()
+		return rcodezero.NewDNSProviderFromEnv(e)
 	case "regru":
-		return regru.NewDNSProvider()
+		return regru.NewDNSProviderFromEnv(e)
 	case "rfc2136":
-		return rfc2136.NewDNSProvider()
+		return rfc2136.NewDNSProviderFromEnv(e)
 	case "rimuhosting":
-		return rimuhosting.NewDNSProvider()
+		return rimuhosting.NewDNSProviderFromEnv(e)
 	case "route53":
-		return route53.NewDNSProvider()
+		return route53.NewDNSProviderFromEnv(e)
+	case router.Name:
+		return router.NewDNSProviderFromEnv(e, NewDNSChallengeProviderByNameFromEnv)
 	case "safedns":
-		return safedns.NewDNSProvider()
+		return safedns.NewDNSProviderFromEnv(e)
 	case "sakuracloud":
-		return sakuracloud.NewDNSProvider()
+		return sakuracloud.NewDNSProviderFromEnv(e)
 	case "scaleway":
-		return scaleway.NewDNSProvider()
+		return scaleway.NewDNSProviderFromEnv(e)
 	case "selectel":
-		return selectel.NewDNSProvider()
+		return selectel.NewDNSProviderFromEnv(e)
 	case "selectelv2":
-		return selectelv2.NewDNSProvider()
+		return selectelv2.NewDNSProviderFromEnv(e)
 	case "servercow":
-		return servercow.NewDNSProvider()
+		return servercow.NewDNSProviderFromEnv(e)
 	case "shellrent":
-		return shellrent.NewDNSProvider()
+		return shellrent.NewDNSProviderFromEnv(e)
 	case "simply":
-		return simply.NewDNSProvider()
+		return simply.NewDNSProviderFromEnv(e)
 	case "sonic":
-		return sonic.NewDNSProvider()
+		return sonic.NewDNSProviderFromEnv(e)
 	case "stackpath":
-		return stackpath.NewDNSProvider()
+		return stackpath.NewDNSProviderFromEnv(e)
 	case "tencentcloud":
-		return tencentcloud.NewDNSProvider()
+		return tencentcloud.NewDNSProviderFromEnv(e)
 	case "transip":
-		return transip.NewDNSProvider()
+		return transip.NewDNSProviderFromEnv(e)
 	case "ultradns":
-		return ultradns.NewDNSProvider()
+		return ultradns.NewDNSProviderFromEnv(e)
 	case "variomedia":
-		return variomedia.NewDNSProvider()
+		return variomedia.NewDNSProviderFromEnv(e)
 	case "vegadns":
-		return vegadns.NewDNSProvider()
+		return vegadns.NewDNSProviderFromEnv(e)
 	case "vercel":
-		return vercel.NewDNSProvider()
+		return vercel.NewDNSProviderFromEnv(e)
 	case "versio":
-		return versio.NewDNSProvider()
+		return versio.NewDNSProviderFromEnv(e)
 	case "vinyldns":
-		return vinyldns.NewDNSProvider()
+		return vinyldns.NewDNSProviderFromEnv(e)
 	case "vkcloud":
-		return vkcloud.NewDNSProvider()
+		return vkcloud.NewDNSProviderFromEnv(e)
 	case "vscale":
-		return vscale.NewDNSProvider()
+		return vscale.NewDNSProviderFromEnv(e)
 	case "vultr":
-		return vultr.NewDNSProvider()
+		return vultr.NewDNSProviderFromEnv(e)
 	case "webnames":
-		return webnames.NewDNSProvider()
+		return webnames.NewDNSProviderFromEnv(e)
 	case "websupport":
-		return websupport.NewDNSProvider()
+		return websupport.NewDNSProviderFromEnv(e)
 	case "wedos":
-		return wedos.NewDNSProvider()
+		return wedos.NewDNSProviderFromEnv(e)
 	case "yandex":
-		return yandex.NewDNSProvider()
+		return yandex.NewDNSProviderFromEnv(e)
 	case "yandex360":
-		return yandex360.NewDNSProvider()
+		return yandex360.NewDNSProviderFromEnv(e)
 	case "yandexcloud":
-		return yandexcloud.NewDNSProvider()
+		return yandexcloud.NewDNSProviderFromEnv(e)
 	case "zoneee":
-		return zoneee.NewDNSProvider()
+		return zoneee.NewDNSProviderFromEnv(e)
 	case "zonomi":
-		return zonomi.NewDNSProvider()
+		return zonomi.NewDNSProviderFromEnv(e)
 	default:
-		return nil, fmt.Errorf("unrecognized DNS provider: %s", name)
+		return newRegisteredProvider(name)
 	}
 }
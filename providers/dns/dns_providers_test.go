@@ -3,6 +3,7 @@ package dns
 import (
 	"testing"
 
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/platform/tester"
 	"github.com/pya789/lego/v4/providers/dns/exec"
 	"github.com/stretchr/testify/assert"
@@ -38,3 +39,20 @@ func TestUnknownDNSProvider(t *testing.T) {
 	require.Error(t, err)
 	assert.Nil(t, provider)
 }
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider("test-registered", func() (challenge.Provider, error) {
+		return &exec.DNSProvider{}, nil
+	})
+
+	assert.Contains(t, RegisteredProviders(), "test-registered")
+
+	provider, err := NewDNSChallengeProviderByName("test-registered")
+	require.NoError(t, err)
+
+	assert.IsType(t, &exec.DNSProvider{}, provider)
+
+	assert.Panics(t, func() {
+		RegisterProvider("test-registered", func() (challenge.Provider, error) { return nil, nil })
+	})
+}
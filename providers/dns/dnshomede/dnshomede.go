@@ -37,12 +37,16 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 20*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
-		SequenceInterval:   env.GetOrDefaultSecond(EnvSequenceInterval, 2*time.Minute),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 20*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		SequenceInterval:   e.GetOrDefaultSecond(EnvSequenceInterval, 2*time.Minute),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -56,8 +60,14 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for dnsHome.de.
 // Credentials must be passed in the environment variable: DNSHOMEDE_CREDENTIALS.
 func NewDNSProvider() (*DNSProvider, error) {
-	config := NewDefaultConfig()
-	values, err := env.Get(EnvCredentials)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	config := newDefaultConfig(e)
+	values, err := e.Get(EnvCredentials)
 	if err != nil {
 		return nil, fmt.Errorf("dnshomede: %w", err)
 	}
@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"github.com/dnsimple/dnsimple-go/dnsimple"
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
 	"golang.org/x/oauth2"
 )
 
@@ -39,18 +41,58 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		Debug:              env.GetOrDefaultBool(EnvDebug, false),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		Debug:              e.GetOrDefaultBool(EnvDebug, false),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 	}
 }
 
+type dnsClient interface {
+	Whoami(ctx context.Context) (*dnsimple.WhoamiResponse, error)
+	ListZones(ctx context.Context, accountID string, options *dnsimple.ZoneListOptions) (*dnsimple.ZonesResponse, error)
+	ListRecords(ctx context.Context, accountID, zoneName string, options *dnsimple.ZoneRecordListOptions) (*dnsimple.ZoneRecordsResponse, error)
+	CreateRecord(ctx context.Context, accountID, zoneName string, recordAttributes dnsimple.ZoneRecordAttributes) (*dnsimple.ZoneRecordResponse, error)
+	DeleteRecord(ctx context.Context, accountID, zoneName string, recordID int64) (*dnsimple.ZoneRecordResponse, error)
+}
+
+// apiClient gathers the services of the DNSimple client used by the provider.
+type apiClient struct {
+	*dnsimple.Client
+}
+
+func (c *apiClient) Whoami(ctx context.Context) (*dnsimple.WhoamiResponse, error) {
+	return c.Identity.Whoami(ctx)
+}
+
+func (c *apiClient) ListZones(ctx context.Context, accountID string, options *dnsimple.ZoneListOptions) (*dnsimple.ZonesResponse, error) {
+	return c.Zones.ListZones(ctx, accountID, options)
+}
+
+func (c *apiClient) ListRecords(ctx context.Context, accountID, zoneName string, options *dnsimple.ZoneRecordListOptions) (*dnsimple.ZoneRecordsResponse, error) {
+	return c.Zones.ListRecords(ctx, accountID, zoneName, options)
+}
+
+func (c *apiClient) CreateRecord(ctx context.Context, accountID, zoneName string, recordAttributes dnsimple.ZoneRecordAttributes) (*dnsimple.ZoneRecordResponse, error) {
+	return c.Zones.CreateRecord(ctx, accountID, zoneName, recordAttributes)
+}
+
+func (c *apiClient) DeleteRecord(ctx context.Context, accountID, zoneName string, recordID int64) (*dnsimple.ZoneRecordResponse, error) {
+	return c.Zones.DeleteRecord(ctx, accountID, zoneName, recordID)
+}
+
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
-	client *dnsimple.Client
+	client dnsClient
+
+	// only for testing purpose.
+	findZoneByFqdn func(fqdn string) (string, error)
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for dnsimple.
@@ -58,9 +100,15 @@ type DNSProvider struct {
 //
 // See: https://developer.dnsimple.com/v2/#authentication
 func NewDNSProvider() (*DNSProvider, error) {
-	config := NewDefaultConfig()
-	config.AccessToken = env.GetOrFile(EnvOAuthToken)
-	config.BaseURL = env.GetOrFile(EnvBaseURL)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	config := newDefaultConfig(e)
+	config.AccessToken = e.GetOrFile(EnvOAuthToken)
+	config.BaseURL = e.GetOrFile(EnvBaseURL)
 
 	return NewDNSProviderConfig(config)
 }
@@ -85,7 +133,11 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 
 	client.Debug = config.Debug
 
-	return &DNSProvider{client: client, config: config}, nil
+	return &DNSProvider{
+		config:         config,
+		client:         &apiClient{Client: client},
+		findZoneByFqdn: dns01.FindZoneByFqdn,
+	}, nil
 }
 
 // Present creates a TXT record to fulfill the dns-01 challenge.
@@ -107,9 +159,9 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("dnsimple: %w", err)
 	}
 
-	_, err = d.client.Zones.CreateRecord(context.Background(), accountID, zoneName, recordAttributes)
+	_, err = d.client.CreateRecord(context.Background(), accountID, zoneName, recordAttributes)
 	if err != nil {
-		return fmt.Errorf("dnsimple: API call failed: %w", err)
+		return fmt.Errorf("dnsimple: API call failed: %w", categorizeError(err))
 	}
 
 	return nil
@@ -131,9 +183,9 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	var lastErr error
 	for _, rec := range records {
-		_, err := d.client.Zones.DeleteRecord(context.Background(), accountID, rec.ZoneID, rec.ID)
+		_, err := d.client.DeleteRecord(context.Background(), accountID, rec.ZoneID, rec.ID)
 		if err != nil {
-			lastErr = fmt.Errorf("dnsimple: %w", err)
+			lastErr = fmt.Errorf("dnsimple: %w", categorizeError(err))
 		}
 	}
 
@@ -147,7 +199,7 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 }
 
 func (d *DNSProvider) getHostedZone(domain string) (string, error) {
-	authZone, err := dns01.FindZoneByFqdn(domain)
+	authZone, err := d.findZoneByFqdn(domain)
 	if err != nil {
 		return "", fmt.Errorf("could not find zone for FQDN %q: %w", domain, err)
 	}
@@ -159,9 +211,9 @@ func (d *DNSProvider) getHostedZone(domain string) (string, error) {
 
 	zoneName := dns01.UnFqdn(authZone)
 
-	zones, err := d.client.Zones.ListZones(context.Background(), accountID, &dnsimple.ZoneListOptions{NameLike: &zoneName})
+	zones, err := d.client.ListZones(context.Background(), accountID, &dnsimple.ZoneListOptions{NameLike: &zoneName})
 	if err != nil {
-		return "", fmt.Errorf("API call failed: %w", err)
+		return "", fmt.Errorf("API call failed: %w", categorizeError(err))
 	}
 
 	var hostedZone dnsimple.Zone
@@ -172,7 +224,7 @@ func (d *DNSProvider) getHostedZone(domain string) (string, error) {
 	}
 
 	if hostedZone.ID == 0 {
-		return "", fmt.Errorf("zone %s not found in DNSimple for domain %s", authZone, domain)
+		return "", challenge.NotFoundZone(fmt.Errorf("zone %s not found in DNSimple for domain %s", authZone, domain))
 	}
 
 	return hostedZone.Name, nil
@@ -194,9 +246,9 @@ func (d *DNSProvider) findTxtRecords(fqdn string) ([]dnsimple.ZoneRecord, error)
 		return nil, err
 	}
 
-	result, err := d.client.Zones.ListRecords(context.Background(), accountID, zoneName, &dnsimple.ZoneRecordListOptions{Name: &subDomain, Type: dnsimple.String("TXT"), ListOptions: dnsimple.ListOptions{}})
+	result, err := d.client.ListRecords(context.Background(), accountID, zoneName, &dnsimple.ZoneRecordListOptions{Name: &subDomain, Type: dnsimple.String("TXT"), ListOptions: dnsimple.ListOptions{}})
 	if err != nil {
-		return nil, fmt.Errorf("API call has failed: %w", err)
+		return nil, fmt.Errorf("API call has failed: %w", categorizeError(err))
 	}
 
 	return result.Data, nil
@@ -217,9 +269,9 @@ func newTxtRecord(zoneName, fqdn, value string, ttl int) (dnsimple.ZoneRecordAtt
 }
 
 func (d *DNSProvider) getAccountID() (string, error) {
-	whoamiResponse, err := d.client.Identity.Whoami(context.Background())
+	whoamiResponse, err := d.client.Whoami(context.Background())
 	if err != nil {
-		return "", err
+		return "", categorizeError(err)
 	}
 
 	if whoamiResponse.Data.Account == nil {
@@ -228,3 +280,13 @@ func (d *DNSProvider) getAccountID() (string, error) {
 
 	return strconv.FormatInt(whoamiResponse.Data.Account.ID, 10), nil
 }
+
+// categorizeError categorizes the errors of the API with their HTTP status codes.
+func categorizeError(err error) error {
+	var errResp *dnsimple.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.HTTPResponse == nil {
+		return err
+	}
+
+	return errutils.CategorizeStatusCode(errResp.HTTPResponse.StatusCode, err)
+}
@@ -0,0 +1,184 @@
+package dnsimple
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dnsimple/dnsimple-go/dnsimple"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider_Present_mock(t *testing.T) {
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	testCases := []struct {
+		desc          string
+		account       *dnsimple.Account
+		zones         []dnsimple.Zone
+		createErr     error
+		callCreate    bool
+		expectedError string
+	}{
+		{
+			desc:       "success",
+			account:    &dnsimple.Account{ID: 123},
+			zones:      []dnsimple.Zone{{ID: 1, Name: "example.com"}},
+			callCreate: true,
+		},
+		{
+			desc:          "user token",
+			expectedError: "dnsimple: user tokens are not supported, please use an account token",
+		},
+		{
+			desc:          "zone not found",
+			account:       &dnsimple.Account{ID: 123},
+			zones:         []dnsimple.Zone{{ID: 1, Name: "example.com.other"}},
+			expectedError: "dnsimple: zone example.com. not found in DNSimple for domain _acme-challenge.sub.example.com.",
+		},
+		{
+			desc:          "create error",
+			account:       &dnsimple.Account{ID: 123},
+			zones:         []dnsimple.Zone{{ID: 1, Name: "example.com"}},
+			createErr:     errors.New("oops"),
+			callCreate:    true,
+			expectedError: "dnsimple: API call failed: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("Whoami").Return(&dnsimple.WhoamiResponse{Data: &dnsimple.WhoamiData{Account: test.account}}, nil)
+
+			if test.account != nil {
+				zoneName := "example.com"
+				client.On("ListZones", "123", &dnsimple.ZoneListOptions{NameLike: &zoneName}).
+					Return(&dnsimple.ZonesResponse{Data: test.zones}, nil)
+			}
+
+			if test.callCreate {
+				subDomain := "_acme-challenge.sub"
+				client.On("CreateRecord", "123", "example.com", dnsimple.ZoneRecordAttributes{
+					Type:    "TXT",
+					Name:    &subDomain,
+					Content: info.Value,
+					TTL:     provider.config.TTL,
+				}).Return(&dnsimple.ZoneRecordResponse{}, test.createErr)
+			}
+
+			err := provider.Present("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_CleanUp_mock(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		deleteErr     error
+		expectedError string
+	}{
+		{
+			desc: "success",
+		},
+		{
+			desc:          "delete error",
+			deleteErr:     errors.New("oops"),
+			expectedError: "dnsimple: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("Whoami").Return(&dnsimple.WhoamiResponse{Data: &dnsimple.WhoamiData{Account: &dnsimple.Account{ID: 123}}}, nil)
+
+			zoneName := "example.com"
+			client.On("ListZones", "123", &dnsimple.ZoneListOptions{NameLike: &zoneName}).
+				Return(&dnsimple.ZonesResponse{Data: []dnsimple.Zone{{ID: 1, Name: "example.com"}}}, nil)
+
+			subDomain := "_acme-challenge.sub"
+			client.On("ListRecords", "123", "example.com", &dnsimple.ZoneRecordListOptions{Name: &subDomain, Type: dnsimple.String("TXT")}).
+				Return(&dnsimple.ZoneRecordsResponse{Data: []dnsimple.ZoneRecord{
+					{ID: 1, ZoneID: "example.com"},
+					{ID: 2, ZoneID: "example.com"},
+				}}, nil)
+
+			// all the records are deleted, even if a deletion fails.
+			client.On("DeleteRecord", "123", "example.com", int64(1)).Return(&dnsimple.ZoneRecordResponse{}, test.deleteErr)
+			client.On("DeleteRecord", "123", "example.com", int64(2)).Return(&dnsimple.ZoneRecordResponse{}, nil)
+
+			err := provider.CleanUp("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func newMockedProvider(t *testing.T, client dnsClient) *DNSProvider {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.AccessToken = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client = client
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider
+}
+
+type mockedClient struct {
+	mock.Mock
+}
+
+func (c *mockedClient) Whoami(_ context.Context) (*dnsimple.WhoamiResponse, error) {
+	args := c.Called()
+	return args.Get(0).(*dnsimple.WhoamiResponse), args.Error(1)
+}
+
+func (c *mockedClient) ListZones(_ context.Context, accountID string, options *dnsimple.ZoneListOptions) (*dnsimple.ZonesResponse, error) {
+	args := c.Called(accountID, options)
+	return args.Get(0).(*dnsimple.ZonesResponse), args.Error(1)
+}
+
+func (c *mockedClient) ListRecords(_ context.Context, accountID, zoneName string, options *dnsimple.ZoneRecordListOptions) (*dnsimple.ZoneRecordsResponse, error) {
+	args := c.Called(accountID, zoneName, options)
+	return args.Get(0).(*dnsimple.ZoneRecordsResponse), args.Error(1)
+}
+
+func (c *mockedClient) CreateRecord(_ context.Context, accountID, zoneName string, recordAttributes dnsimple.ZoneRecordAttributes) (*dnsimple.ZoneRecordResponse, error) {
+	args := c.Called(accountID, zoneName, recordAttributes)
+	return args.Get(0).(*dnsimple.ZoneRecordResponse), args.Error(1)
+}
+
+func (c *mockedClient) DeleteRecord(_ context.Context, accountID, zoneName string, recordID int64) (*dnsimple.ZoneRecordResponse, error) {
+	args := c.Called(accountID, zoneName, recordID)
+	return args.Get(0).(*dnsimple.ZoneRecordResponse), args.Error(1)
+}
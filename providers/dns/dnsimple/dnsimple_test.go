@@ -65,7 +65,9 @@ func TestNewDNSProvider(t *testing.T) {
 
 				baseURL := os.Getenv(EnvBaseURL)
 				if baseURL != "" {
-					assert.Equal(t, baseURL, p.client.BaseURL)
+					client, ok := p.client.(*apiClient)
+					require.True(t, ok)
+					assert.Equal(t, baseURL, client.BaseURL)
 				}
 			} else {
 				require.EqualError(t, err, test.expected)
@@ -112,7 +114,9 @@ func TestNewDNSProviderConfig(t *testing.T) {
 				require.NotNil(t, p.client)
 
 				if test.baseURL != "" {
-					assert.Equal(t, test.baseURL, p.client.BaseURL)
+					client, ok := p.client.(*apiClient)
+					require.True(t, ok)
+					assert.Equal(t, test.baseURL, client.BaseURL)
 				}
 			} else {
 				require.EqualError(t, err, test.expected)
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"slices"
-	"strings"
 	"time"
 
 	configdns "github.com/akamai/AkamaiOPEN-edgegrid-golang/configdns-v2"
@@ -49,10 +48,14 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, defaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, defaultPollInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, defaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, defaultPollInterval),
 		Config:             edgegrid.Config{MaxBody: maxBody},
 	}
 }
@@ -72,10 +75,16 @@ type DNSProvider struct {
 //
 // See also: https://developer.akamai.com/api/getting-started
 func NewDNSProvider() (*DNSProvider, error) {
-	config := NewDefaultConfig()
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	config := newDefaultConfig(e)
 
-	rcPath := env.GetOrDefaultString(EnvEdgeRc, "")
-	rcSection := env.GetOrDefaultString(EnvEdgeRcSection, "")
+	rcPath := e.GetOrDefaultString(EnvEdgeRc, "")
+	rcSection := e.GetOrDefaultString(EnvEdgeRcSection, "")
 
 	conf, err := edgegrid.Init(rcPath, rcSection)
 	if err != nil {
@@ -132,7 +141,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 			return nil
 		}
 
-		record.Target = append(record.Target, `"`+info.Value+`"`)
+		record.Target = append(record.Target, dns01.QuoteTXTValue(info.Value))
 		record.TTL = d.config.TTL
 
 		err = record.Update(zone)
@@ -147,7 +156,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		Name:       info.EffectiveFQDN,
 		RecordType: "TXT",
 		TTL:        d.config.TTL,
-		Target:     []string{`"` + info.Value + `"`},
+		Target:     []string{dns01.QuoteTXTValue(info.Value)},
 	}
 
 	err = record.Save(zone)
@@ -189,7 +198,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	var newRData []string
 	for _, val := range existingRec.Target {
-		val = strings.Trim(val, `"`)
+		val = dns01.UnquoteTXTValue(val)
 		if val == info.Value {
 			continue
 		}
@@ -226,7 +235,7 @@ func getZone(domain string) (string, error) {
 
 func containsValue(values []string, value string) bool {
 	return slices.ContainsFunc(values, func(val string) bool {
-		return strings.Trim(val, `"`) == value
+		return dns01.UnquoteTXTValue(val) == value
 	})
 }
 
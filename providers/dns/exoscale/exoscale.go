@@ -5,11 +5,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	egoscale "github.com/exoscale/egoscale/v2"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/exoscale/internal"
 )
 
 // Default Exoscale API endpoint.
@@ -19,14 +24,24 @@ const defaultBaseURL = "https://api.exoscale.com/v2"
 // Each data center location hosts the API and API zone determines which one to connect to.
 const defaultAPIZone = "ch-gva-2"
 
+// API versions.
+const (
+	// APIVersion2 the API v2: the zones are identified by UUIDs, the API keys can be scoped with IAM roles.
+	APIVersion2 = "v2"
+	// APIVersion1 the legacy DNS API (v1): the zones are identified by names.
+	APIVersion1 = "v1"
+)
+
 // Environment variables names.
 const (
 	envNamespace = "EXOSCALE_"
 
-	EnvAPISecret = envNamespace + "API_SECRET"
-	EnvAPIKey    = envNamespace + "API_KEY"
-	EnvEndpoint  = envNamespace + "ENDPOINT"
-	EnvAPIZone   = envNamespace + "API_ZONE"
+	EnvAPISecret  = envNamespace + "API_SECRET"
+	EnvAPIKey     = envNamespace + "API_KEY"
+	EnvEndpoint   = envNamespace + "ENDPOINT"
+	EnvAPIZone    = envNamespace + "API_ZONE"
+	EnvAPIVersion = envNamespace + "API_VERSION"
+	EnvZoneID     = envNamespace + "ZONE_ID"
 
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
@@ -36,9 +51,14 @@ const (
 
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
-	APIKey             string
-	APISecret          string
-	Endpoint           string
+	APIKey    string
+	APISecret string
+	Endpoint  string
+	// APIVersion the version of the API: APIVersion2 (default), or APIVersion1 (legacy DNS API).
+	APIVersion string
+	// ZoneID the UUID of the zone (API v2 only), the zones are not listed if defined.
+	ZoneID string
+
 	HTTPTimeout        time.Duration
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
@@ -47,39 +67,69 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                int64(env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL)),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
-		HTTPTimeout:        env.GetOrDefaultSecond(EnvHTTPTimeout, 60*time.Second),
+		APIVersion:         e.GetOrDefaultString(EnvAPIVersion, APIVersion2),
+		TTL:                int64(e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL)),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		HTTPTimeout:        e.GetOrDefaultSecond(EnvHTTPTimeout, 60*time.Second),
 	}
 }
 
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config  *Config
-	client  *egoscale.Client
 	apiZone string
+
+	// client the API v2 client.
+	client *egoscale.Client
+	// legacyClient the DNS API v1 client.
+	legacyClient *internal.Client
+
+	recordIDs   map[string]recordRef
+	recordIDsMu sync.Mutex
+}
+
+// recordRef the zone and the ID of a record:
+// the UUIDs for the API v2, the zone name and the numeric ID for the DNS API v1.
+type recordRef struct {
+	zone     string
+	recordID string
 }
 
 // NewDNSProvider Credentials must be passed in the environment variables:
 // EXOSCALE_API_KEY, EXOSCALE_API_SECRET, EXOSCALE_ENDPOINT.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIKey, EnvAPISecret)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIKey, EnvAPISecret)
 	if err != nil {
 		return nil, fmt.Errorf("exoscale: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.APIKey = values[EnvAPIKey]
 	config.APISecret = values[EnvAPISecret]
-	config.Endpoint = env.GetOrFile(EnvEndpoint)
+	config.Endpoint = e.GetOrFile(EnvEndpoint)
+	config.ZoneID = e.GetOrFile(EnvZoneID)
 
-	return NewDNSProviderConfig(config)
+	return newDNSProviderConfig(e, config)
 }
 
 // NewDNSProviderConfig return a DNSProvider instance configured for Exoscale.
 func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	return newDNSProviderConfig(env.Getter{}, config)
+}
+
+func newDNSProviderConfig(e env.Getter, config *Config) (*DNSProvider, error) {
 	if config == nil {
 		return nil, errors.New("exoscale: the configuration of the DNS provider is nil")
 	}
@@ -88,25 +138,51 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("exoscale: credentials missing")
 	}
 
-	if config.Endpoint == "" {
-		config.Endpoint = defaultBaseURL
+	provider := &DNSProvider{
+		config:    config,
+		apiZone:   e.GetOrDefaultString(EnvAPIZone, defaultAPIZone),
+		recordIDs: make(map[string]recordRef),
 	}
 
-	client, err := egoscale.NewClient(
-		config.APIKey,
-		config.APISecret,
-		egoscale.ClientOptWithAPIEndpoint(config.Endpoint),
-		egoscale.ClientOptWithTimeout(config.HTTPTimeout),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("exoscale: initializing client: %w", err)
+	switch config.APIVersion {
+	case "", APIVersion2:
+		if config.Endpoint == "" {
+			config.Endpoint = defaultBaseURL
+		}
+
+		client, err := egoscale.NewClient(
+			config.APIKey,
+			config.APISecret,
+			egoscale.ClientOptWithAPIEndpoint(config.Endpoint),
+			egoscale.ClientOptWithTimeout(config.HTTPTimeout),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("exoscale: initializing client: %w", err)
+		}
+
+		provider.client = client
+
+	case APIVersion1:
+		client := internal.NewClient(config.APIKey, config.APISecret)
+
+		if config.Endpoint != "" {
+			baseURL, err := url.Parse(config.Endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("exoscale: %w", err)
+			}
+
+			client.BaseURL = baseURL
+		}
+
+		client.HTTPClient = &http.Client{Timeout: config.HTTPTimeout}
+
+		provider.legacyClient = client
+
+	default:
+		return nil, fmt.Errorf("exoscale: unsupported API version %q (supported: %s, %s)", config.APIVersion, APIVersion2, APIVersion1)
 	}
 
-	return &DNSProvider{
-		client:  client,
-		config:  config,
-		apiZone: env.GetOrDefaultString(EnvAPIZone, defaultAPIZone),
-	}, nil
+	return provider, nil
 }
 
 // Present creates a TXT record to fulfill the dns-01 challenge.
@@ -119,25 +195,19 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("exoscale: %w", err)
 	}
 
-	zone, err := d.findExistingZone(zoneName)
+	var ref recordRef
+	if d.legacyClient != nil {
+		ref, err = d.createLegacyRecord(ctx, zoneName, recordName, info.Value)
+	} else {
+		ref, err = d.createRecord(ctx, zoneName, recordName, info.Value)
+	}
 	if err != nil {
 		return fmt.Errorf("exoscale: %w", err)
 	}
-	if zone == nil {
-		return fmt.Errorf("exoscale: zone %q not found", zoneName)
-	}
 
-	record := egoscale.DNSDomainRecord{
-		Name:    pointer(recordName),
-		TTL:     pointer(d.config.TTL),
-		Content: pointer(info.Value),
-		Type:    pointer("TXT"),
-	}
-
-	_, err = d.client.CreateDNSDomainRecord(ctx, d.apiZone, deref(zone.ID), &record)
-	if err != nil {
-		return fmt.Errorf("exoscale: error while creating DNS record: %w", err)
-	}
+	d.recordIDsMu.Lock()
+	d.recordIDs[token] = ref
+	d.recordIDsMu.Unlock()
 
 	return nil
 }
@@ -147,30 +217,44 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	ctx := context.Background()
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	zoneName, recordName, err := d.findZoneAndRecordName(info.EffectiveFQDN)
-	if err != nil {
-		return fmt.Errorf("exoscale: %w", err)
-	}
+	d.recordIDsMu.Lock()
+	ref, ok := d.recordIDs[token]
+	d.recordIDsMu.Unlock()
 
-	zone, err := d.findExistingZone(zoneName)
-	if err != nil {
-		return fmt.Errorf("exoscale: %w", err)
-	}
-	if zone == nil {
-		return fmt.Errorf("exoscale: zone %q not found", zoneName)
+	if !ok {
+		// the record has not been created by this provider instance (ex: deferred clean-up).
+		zoneName, recordName, err := d.findZoneAndRecordName(info.EffectiveFQDN)
+		if err != nil {
+			return fmt.Errorf("exoscale: %w", err)
+		}
+
+		if d.legacyClient != nil {
+			ref, err = d.findLegacyRecord(ctx, zoneName, recordName, info.Value)
+		} else {
+			ref, err = d.findRecord(ctx, zoneName, recordName, info.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("exoscale: %w", err)
+		}
+
+		if ref.recordID == "" {
+			return nil
+		}
 	}
 
-	recordID, err := d.findExistingRecordID(deref(zone.ID), recordName, info.Value)
+	var err error
+	if d.legacyClient != nil {
+		err = d.deleteLegacyRecord(ctx, ref)
+	} else {
+		err = d.client.DeleteDNSDomainRecord(ctx, d.apiZone, ref.zone, &egoscale.DNSDomainRecord{ID: pointer(ref.recordID)})
+	}
 	if err != nil {
-		return err
+		return fmt.Errorf("exoscale: error while deleting DNS record: %w", err)
 	}
 
-	if recordID != "" {
-		err = d.client.DeleteDNSDomainRecord(ctx, d.apiZone, deref(zone.ID), &egoscale.DNSDomainRecord{ID: &recordID})
-		if err != nil {
-			return fmt.Errorf("exoscale: error while deleting DNS record: %w", err)
-		}
-	}
+	d.recordIDsMu.Lock()
+	delete(d.recordIDs, token)
+	d.recordIDsMu.Unlock()
 
 	return nil
 }
@@ -181,42 +265,118 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 	return d.config.PropagationTimeout, d.config.PollingInterval
 }
 
-// findExistingZone Query Exoscale to find an existing zone for this name.
-// Returns nil result if no zone could be found.
-func (d *DNSProvider) findExistingZone(zoneName string) (*egoscale.DNSDomain, error) {
-	ctx := context.Background()
+// createRecord creates a record with the API v2.
+func (d *DNSProvider) createRecord(ctx context.Context, zoneName, recordName, value string) (recordRef, error) {
+	zoneID, err := d.findZoneID(ctx, zoneName)
+	if err != nil {
+		return recordRef{}, err
+	}
+
+	record := egoscale.DNSDomainRecord{
+		Name:    pointer(recordName),
+		TTL:     pointer(d.config.TTL),
+		Content: pointer(value),
+		Type:    pointer("TXT"),
+	}
+
+	newRecord, err := d.client.CreateDNSDomainRecord(ctx, d.apiZone, zoneID, &record)
+	if err != nil {
+		return recordRef{}, fmt.Errorf("error while creating DNS record: %w", err)
+	}
+
+	return recordRef{zone: zoneID, recordID: deref(newRecord.ID)}, nil
+}
+
+// findRecord finds a record with the API v2.
+// Returns an empty record ID if no record could be found.
+func (d *DNSProvider) findRecord(ctx context.Context, zoneName, recordName, value string) (recordRef, error) {
+	zoneID, err := d.findZoneID(ctx, zoneName)
+	if err != nil {
+		return recordRef{}, err
+	}
+
+	records, err := d.client.ListDNSDomainRecords(ctx, d.apiZone, zoneID)
+	if err != nil {
+		return recordRef{}, fmt.Errorf("error while retrieving DNS records: %w", err)
+	}
+
+	for _, record := range records {
+		if deref(record.Name) == recordName && deref(record.Type) == "TXT" && deref(record.Content) == value {
+			return recordRef{zone: zoneID, recordID: deref(record.ID)}, nil
+		}
+	}
+
+	return recordRef{zone: zoneID}, nil
+}
+
+// findZoneID returns the UUID of the zone:
+// the configured zone UUID (EXOSCALE_ZONE_ID), or the UUID of the zone matching the name.
+func (d *DNSProvider) findZoneID(ctx context.Context, zoneName string) (string, error) {
+	if d.config.ZoneID != "" {
+		return d.config.ZoneID, nil
+	}
 
 	zones, err := d.client.ListDNSDomains(ctx, d.apiZone)
 	if err != nil {
-		return nil, fmt.Errorf("error while retrieving DNS zones: %w", err)
+		// the IAM role of the API key can forbid the listing of the zones.
+		return "", fmt.Errorf("error while retrieving DNS zones (the IAM role of the API key must allow the listing of the DNS domains, or the zone UUID must be defined with %s): %w", EnvZoneID, err)
 	}
 
 	for _, zone := range zones {
 		if zone.UnicodeName != nil && deref(zone.UnicodeName) == zoneName {
-			return &zone, nil
+			return deref(zone.ID), nil
 		}
 	}
 
-	return nil, nil
+	return "", fmt.Errorf("zone %q not found", zoneName)
 }
 
-// findExistingRecordID Query Exoscale to find an existing record for this name.
-// Returns empty result if no record could be found.
-func (d *DNSProvider) findExistingRecordID(zoneID, recordName, value string) (string, error) {
-	ctx := context.Background()
+// createLegacyRecord creates a record with the DNS API v1.
+func (d *DNSProvider) createLegacyRecord(ctx context.Context, zoneName, recordName, value string) (recordRef, error) {
+	zone, err := d.legacyClient.GetDomain(ctx, zoneName)
+	if err != nil {
+		return recordRef{}, fmt.Errorf("zone %q not found: %w", zoneName, err)
+	}
 
-	records, err := d.client.ListDNSDomainRecords(ctx, d.apiZone, zoneID)
+	record := internal.Record{
+		Name:       recordName,
+		RecordType: "TXT",
+		Content:    value,
+		TTL:        int(d.config.TTL),
+	}
+
+	newRecord, err := d.legacyClient.CreateRecord(ctx, zone.Name, record)
+	if err != nil {
+		return recordRef{}, fmt.Errorf("error while creating DNS record: %w", err)
+	}
+
+	return recordRef{zone: zone.Name, recordID: strconv.FormatInt(newRecord.ID, 10)}, nil
+}
+
+// findLegacyRecord finds a record with the DNS API v1.
+// Returns an empty record ID if no record could be found.
+func (d *DNSProvider) findLegacyRecord(ctx context.Context, zoneName, recordName, value string) (recordRef, error) {
+	records, err := d.legacyClient.GetRecords(ctx, zoneName)
 	if err != nil {
-		return "", fmt.Errorf("error while retrieving DNS records: %w", err)
+		return recordRef{}, fmt.Errorf("error while retrieving DNS records: %w", err)
 	}
 
 	for _, record := range records {
-		if deref(record.Name) == recordName && deref(record.Type) == "TXT" && deref(record.Content) == value {
-			return deref(record.ID), nil
+		if record.Name == recordName && record.RecordType == "TXT" && record.Content == value {
+			return recordRef{zone: zoneName, recordID: strconv.FormatInt(record.ID, 10)}, nil
 		}
 	}
 
-	return "", nil
+	return recordRef{zone: zoneName}, nil
+}
+
+func (d *DNSProvider) deleteLegacyRecord(ctx context.Context, ref recordRef) error {
+	recordID, err := strconv.ParseInt(ref.recordID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid record ID %q: %w", ref.recordID, err)
+	}
+
+	return d.legacyClient.DeleteRecord(ctx, ref.zone, recordID)
 }
 
 // findZoneAndRecordName Extract DNS zone and DNS entry name.
@@ -1,6 +1,8 @@
 package exoscale
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -78,16 +80,30 @@ func TestNewDNSProvider(t *testing.T) {
 
 func TestNewDNSProviderConfig(t *testing.T) {
 	testCases := []struct {
-		desc      string
-		apiKey    string
-		apiSecret string
-		expected  string
+		desc       string
+		apiKey     string
+		apiSecret  string
+		apiVersion string
+		expected   string
 	}{
 		{
 			desc:      "success",
 			apiKey:    "123",
 			apiSecret: "456",
 		},
+		{
+			desc:       "success (API v1)",
+			apiKey:     "123",
+			apiSecret:  "456",
+			apiVersion: APIVersion1,
+		},
+		{
+			desc:       "unsupported API version",
+			apiKey:     "123",
+			apiSecret:  "456",
+			apiVersion: "v3",
+			expected:   `exoscale: unsupported API version "v3" (supported: v2, v1)`,
+		},
 		{
 			desc:     "missing credentials",
 			expected: "exoscale: credentials missing",
@@ -109,6 +125,9 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			config := NewDefaultConfig()
 			config.APIKey = test.apiKey
 			config.APISecret = test.apiSecret
+			if test.apiVersion != "" {
+				config.APIVersion = test.apiVersion
+			}
 
 			p, err := NewDNSProviderConfig(config)
 
@@ -116,7 +135,12 @@ func TestNewDNSProviderConfig(t *testing.T) {
 				require.NoError(t, err)
 				require.NotNil(t, p)
 				require.NotNil(t, p.config)
-				require.NotNil(t, p.client)
+
+				if test.apiVersion == APIVersion1 {
+					require.NotNil(t, p.legacyClient)
+				} else {
+					require.NotNil(t, p.client)
+				}
 			} else {
 				require.EqualError(t, err, test.expected)
 			}
@@ -172,6 +196,47 @@ func TestDNSProvider_FindZoneAndRecordName(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_CleanUp_legacy(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	var deleted bool
+
+	mux.HandleFunc("/domains/example.com/records/1234", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodDelete {
+			http.Error(rw, "unsupported method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("X-DNS-Token") != "key:secret" {
+			http.Error(rw, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		deleted = true
+
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	config := NewDefaultConfig()
+	config.APIKey = "key"
+	config.APISecret = "secret"
+	config.APIVersion = APIVersion1
+	config.Endpoint = server.URL
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.recordIDs["token"] = recordRef{zone: "example.com", recordID: "1234"}
+
+	err = provider.CleanUp("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	assert.True(t, deleted)
+	assert.Empty(t, provider.recordIDs)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
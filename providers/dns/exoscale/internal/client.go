@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
+)
+
+// DefaultBaseURL the endpoint of the legacy DNS API (v1).
+const DefaultBaseURL = "https://api.exoscale.com/dns/v1"
+
+const tokenHeader = "X-DNS-Token"
+
+// Client the Exoscale DNS API v1 client.
+// The zones are identified by their names.
+type Client struct {
+	apiKey    string
+	apiSecret string
+
+	BaseURL    *url.URL
+	HTTPClient *http.Client
+}
+
+// NewClient Creates a new Client.
+func NewClient(apiKey, apiSecret string) *Client {
+	baseURL, _ := url.Parse(DefaultBaseURL)
+
+	return &Client{
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetDomain gets a domain (zone) by name.
+func (c *Client) GetDomain(ctx context.Context, name string) (*Domain, error) {
+	endpoint := c.BaseURL.JoinPath("domains", name)
+
+	req, err := newJSONRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response DomainResponse
+
+	err = c.do(req, http.StatusOK, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Domain, nil
+}
+
+// GetRecords gets the records of a domain.
+func (c *Client) GetRecords(ctx context.Context, domain string) ([]Record, error) {
+	endpoint := c.BaseURL.JoinPath("domains", domain, "records")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []RecordResponse
+
+	err = c.do(req, http.StatusOK, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, r := range response {
+		records = append(records, r.Record)
+	}
+
+	return records, nil
+}
+
+// CreateRecord creates a record.
+func (c *Client) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	endpoint := c.BaseURL.JoinPath("domains", domain, "records")
+
+	req, err := newJSONRequest(ctx, http.MethodPost, endpoint, RecordResponse{Record: record})
+	if err != nil {
+		return nil, err
+	}
+
+	var response RecordResponse
+
+	err = c.do(req, http.StatusOK, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Record, nil
+}
+
+// DeleteRecord deletes a record.
+func (c *Client) DeleteRecord(ctx context.Context, domain string, recordID int64) error {
+	endpoint := c.BaseURL.JoinPath("domains", domain, "records", strconv.FormatInt(recordID, 10))
+
+	req, err := newJSONRequest(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.do(req, http.StatusNoContent, nil)
+}
+
+func (c *Client) do(req *http.Request, expectedStatus int, result any) error {
+	req.Header.Set(tokenHeader, c.apiKey+":"+c.apiSecret)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return errutils.NewHTTPDoError(req, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != expectedStatus {
+		return errutils.NewUnexpectedResponseStatusCodeError(req, resp)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errutils.NewReadResponseError(req, resp.StatusCode, err)
+	}
+
+	err = json.Unmarshal(raw, result)
+	if err != nil {
+		return errutils.NewUnmarshalError(req, resp.StatusCode, raw, err)
+	}
+
+	return nil
+}
+
+func newJSONRequest(ctx context.Context, method string, endpoint *url.URL, payload any) (*http.Request, error) {
+	buf := new(bytes.Buffer)
+
+	if payload != nil {
+		err := json.NewEncoder(buf).Encode(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request JSON body: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), buf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T, method, pattern string, status int, file string) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc(pattern, func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != method {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := req.Header.Get(tokenHeader)
+		if token != "key:secret" {
+			http.Error(rw, fmt.Sprintf("invalid token: %s", token), http.StatusUnauthorized)
+			return
+		}
+
+		if file == "" {
+			rw.WriteHeader(status)
+			return
+		}
+
+		open, err := os.Open(file)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		defer func() { _ = open.Close() }()
+
+		rw.WriteHeader(status)
+		_, err = io.Copy(rw, open)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	client := NewClient("key", "secret")
+	client.HTTPClient = server.Client()
+	client.BaseURL, _ = url.Parse(server.URL)
+
+	return client
+}
+
+func TestClient_GetDomain(t *testing.T) {
+	client := setupTest(t, http.MethodGet, "/domains/example.com", http.StatusOK, "./fixtures/get_domain.json")
+
+	domain, err := client.GetDomain(context.Background(), "example.com")
+	require.NoError(t, err)
+
+	expected := &Domain{ID: 2361, Name: "example.com", UnicodeName: "example.com", State: "hosted"}
+
+	assert.Equal(t, expected, domain)
+}
+
+func TestClient_GetDomain_error(t *testing.T) {
+	client := setupTest(t, http.MethodGet, "/domains/example.com", http.StatusNotFound, "")
+
+	_, err := client.GetDomain(context.Background(), "example.com")
+	require.Error(t, err)
+}
+
+func TestClient_GetRecords(t *testing.T) {
+	client := setupTest(t, http.MethodGet, "/domains/example.com/records", http.StatusOK, "./fixtures/get_records.json")
+
+	records, err := client.GetRecords(context.Background(), "example.com")
+	require.NoError(t, err)
+
+	expected := []Record{
+		{ID: 1234, DomainID: 2361, Name: "_acme-challenge", RecordType: "TXT", Content: "txtTXTtxt", TTL: 120},
+		{ID: 1235, DomainID: 2361, Name: "www", RecordType: "A", Content: "192.0.2.1", TTL: 3600},
+	}
+
+	assert.Equal(t, expected, records)
+}
+
+func TestClient_CreateRecord(t *testing.T) {
+	client := setupTest(t, http.MethodPost, "/domains/example.com/records", http.StatusOK, "./fixtures/create_record.json")
+
+	record := Record{Name: "_acme-challenge", RecordType: "TXT", Content: "txtTXTtxt", TTL: 120}
+
+	newRecord, err := client.CreateRecord(context.Background(), "example.com", record)
+	require.NoError(t, err)
+
+	expected := &Record{ID: 1234, DomainID: 2361, Name: "_acme-challenge", RecordType: "TXT", Content: "txtTXTtxt", TTL: 120}
+
+	assert.Equal(t, expected, newRecord)
+}
+
+func TestClient_DeleteRecord(t *testing.T) {
+	client := setupTest(t, http.MethodDelete, "/domains/example.com/records/1234", http.StatusNoContent, "")
+
+	err := client.DeleteRecord(context.Background(), "example.com", 1234)
+	require.NoError(t, err)
+}
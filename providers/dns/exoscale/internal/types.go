@@ -0,0 +1,29 @@
+package internal
+
+// DomainResponse the response of the domain endpoint.
+type DomainResponse struct {
+	Domain Domain `json:"domain"`
+}
+
+// Domain a DNS domain (zone).
+type Domain struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	UnicodeName string `json:"unicode_name,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+// RecordResponse the request and response body of the record endpoints.
+type RecordResponse struct {
+	Record Record `json:"record"`
+}
+
+// Record a DNS record.
+type Record struct {
+	ID         int64  `json:"id,omitempty"`
+	DomainID   int64  `json:"domain_id,omitempty"`
+	Name       string `json:"name"`
+	RecordType string `json:"record_type"`
+	Content    string `json:"content"`
+	TTL        int    `json:"ttl,omitempty"`
+}
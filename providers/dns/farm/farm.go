@@ -0,0 +1,247 @@
+// Package farm implements a DNS provider spreading the challenges over several DNS providers (ex: several credentials of the same DNS service).
+package farm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/log"
+	"github.com/pya789/lego/v4/platform/config/env"
+)
+
+// Environment variables names.
+const (
+	envNamespace = "FARM_"
+
+	EnvProviders = envNamespace + "PROVIDERS"
+
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+)
+
+// Name the name of the provider (its own name cannot be used as a member).
+const Name = "farm"
+
+// Factory creates a DNS provider by name, configured with the environment variables read by the getter
+// (ex: dns.NewDNSChallengeProviderByNameFromEnv).
+type Factory func(name string, e env.Getter) (challenge.Provider, error)
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	// Members the DNS providers, all able to manage the records of all the zones.
+	Members []challenge.Provider
+
+	// PropagationTimeout and PollingInterval are the minimum values:
+	// the longest timeout and the shortest interval of the members are used if they are beyond.
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
+	return &Config{
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+	}
+}
+
+// DNSProvider implements the challenge.Provider interface.
+// The zones are assigned to the members in turn, at their first challenge:
+// all the records of a zone are created and removed by the same member.
+type DNSProvider struct {
+	config *Config
+
+	mu sync.Mutex
+	// the index of the member of each zone.
+	zones map[string]int
+	next  int
+
+	findZone func(fqdn string) (string, error)
+}
+
+// NewDNSProvider returns a DNSProvider instance configured with the members of FARM_PROVIDERS,
+// the DNS providers are created by the factory.
+// FARM_PROVIDERS is a comma-separated list of providers, each one optionally followed by the prefix of its environment variables
+// (ex: cloudflare:CF1_,cloudflare:CF2_ for CF1_CLOUDFLARE_DNS_API_TOKEN and CF2_CLOUDFLARE_DNS_API_TOKEN).
+func NewDNSProvider(factory Factory) (*DNSProvider, error) {
+	return NewDNSProviderFromEnv(env.Getter{}, factory)
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix), the DNS providers are created by the factory.
+func NewDNSProviderFromEnv(e env.Getter, factory Factory) (*DNSProvider, error) {
+	values, err := e.Get(EnvProviders)
+	if err != nil {
+		return nil, fmt.Errorf("farm: %w", err)
+	}
+
+	members, err := LoadMembers(values[EnvProviders], factory)
+	if err != nil {
+		return nil, fmt.Errorf("farm: %w", err)
+	}
+
+	config := newDefaultConfig(e)
+	config.Members = members
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured with members.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("farm: the configuration of the DNS provider is nil")
+	}
+
+	if len(config.Members) == 0 {
+		return nil, errors.New("farm: no member")
+	}
+
+	for i, member := range config.Members {
+		if member == nil {
+			return nil, fmt.Errorf("farm: member %d: missing provider", i)
+		}
+	}
+
+	return &DNSProvider{
+		config:   config,
+		zones:    make(map[string]int),
+		findZone: dns01.FindZoneByFqdn,
+	}, nil
+}
+
+// LoadMembers creates the members from a comma-separated list of providers (ex: cloudflare:CF1_,cloudflare:CF2_),
+// the DNS providers are created by the factory.
+func LoadMembers(value string, factory Factory) ([]challenge.Provider, error) {
+	if factory == nil {
+		return nil, errors.New("the provider factory is nil")
+	}
+
+	var members []challenge.Provider
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, prefix, _ := strings.Cut(item, ":")
+
+		if name == Name {
+			return nil, fmt.Errorf("member %q: the provider %q cannot be a member", item, Name)
+		}
+
+		provider, err := factory(name, env.WithPrefix(prefix))
+		if err != nil {
+			return nil, fmt.Errorf("member %q: %w", item, err)
+		}
+
+		members = append(members, provider)
+	}
+
+	return members, nil
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	return d.PresentContext(context.Background(), domain, token, keyAuth)
+}
+
+// PresentContext creates a TXT record with the member of the zone of the domain.
+func (d *DNSProvider) PresentContext(ctx context.Context, domain, token, keyAuth string) error {
+	member, err := d.member(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	return challenge.Present(ctx, member, domain, token, keyAuth)
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	return d.CleanUpContext(context.Background(), domain, token, keyAuth)
+}
+
+// CleanUpContext removes the TXT record with the member of the zone of the domain.
+func (d *DNSProvider) CleanUpContext(ctx context.Context, domain, token, keyAuth string) error {
+	member, err := d.member(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	return challenge.CleanUp(ctx, member, domain, token, keyAuth)
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation:
+// the longest timeout and the shortest interval of the members, bounded by the configuration.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	timeout, interval = d.config.PropagationTimeout, d.config.PollingInterval
+
+	for _, member := range d.config.Members {
+		p, ok := member.(challenge.ProviderTimeout)
+		if !ok {
+			continue
+		}
+
+		t, i := p.Timeout()
+
+		timeout = max(timeout, t)
+
+		if i > 0 {
+			interval = min(interval, i)
+		}
+	}
+
+	return timeout, interval
+}
+
+// VerifyCredentials checks the credentials of the members able to check them (challenge.CredentialsVerifier).
+func (d *DNSProvider) VerifyCredentials(ctx context.Context) error {
+	var errs []error
+
+	for i, member := range d.config.Members {
+		_, err := challenge.VerifyCredentials(ctx, member)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("member %d: %w", i, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("farm: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// member returns the member of the zone of the TXT record, the zone is assigned to the next member at its first challenge.
+func (d *DNSProvider) member(domain, keyAuth string) (challenge.Provider, error) {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	zone, err := d.findZone(info.EffectiveFQDN)
+	if err != nil {
+		return nil, fmt.Errorf("farm: could not find zone for domain %q: %w", domain, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	index, ok := d.zones[zone]
+	if !ok {
+		index = d.next
+		d.next = (d.next + 1) % len(d.config.Members)
+		d.zones[zone] = index
+
+		log.Debugf("farm: the zone %s is assigned to the member %d", dns01.UnFqdn(zone), index)
+	}
+
+	return d.config.Members[index], nil
+}
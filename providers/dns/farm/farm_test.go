@@ -0,0 +1,193 @@
+package farm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var envTest = tester.NewEnvTest(EnvProviders)
+
+type mockProvider struct {
+	name     string
+	timeout  time.Duration
+	interval time.Duration
+
+	presented []string
+	cleaned   []string
+}
+
+func (p *mockProvider) Present(domain, _, _ string) error {
+	p.presented = append(p.presented, domain)
+	return nil
+}
+
+func (p *mockProvider) CleanUp(domain, _, _ string) error {
+	p.cleaned = append(p.cleaned, domain)
+	return nil
+}
+
+func (p *mockProvider) Timeout() (time.Duration, time.Duration) {
+	return p.timeout, p.interval
+}
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvProviders: "cloudflare:CF1_, cloudflare:CF2_",
+			},
+		},
+		{
+			desc:     "missing providers",
+			envVars:  map[string]string{},
+			expected: "farm: some credentials information are missing: FARM_PROVIDERS",
+		},
+		{
+			desc: "no member",
+			envVars: map[string]string{
+				EnvProviders: " , ",
+			},
+			expected: "farm: no member",
+		},
+		{
+			desc: "farm member",
+			envVars: map[string]string{
+				EnvProviders: "cloudflare,farm",
+			},
+			expected: `farm: member "farm": the provider "farm" cannot be a member`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider(func(name string, e env.Getter) (challenge.Provider, error) {
+				return &mockProvider{name: name}, nil
+			})
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				assert.Len(t, p.config.Members, 2)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestLoadMembers(t *testing.T) {
+	t.Setenv("CF1_FARM_TEST", "one")
+	t.Setenv("CF2_FARM_TEST", "two")
+
+	var values []string
+
+	members, err := LoadMembers("cloudflare:CF1_,cloudflare:CF2_", func(name string, e env.Getter) (challenge.Provider, error) {
+		// the environment variables are resolved with the prefix of the member.
+		values = append(values, name+":"+e.GetOrFile("FARM_TEST"))
+
+		return &mockProvider{name: name}, nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, members, 2)
+
+	assert.Equal(t, []string{"cloudflare:one", "cloudflare:two"}, values)
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	first := &mockProvider{name: "first"}
+	second := &mockProvider{name: "second"}
+
+	config := NewDefaultConfig()
+	config.Members = []challenge.Provider{first, second}
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	// the zone is the last 2 labels of the FQDN.
+	p.findZone = func(fqdn string) (string, error) {
+		labels := strings.Split(dns01.UnFqdn(fqdn), ".")
+		return dns01.ToFqdn(strings.Join(labels[len(labels)-2:], ".")), nil
+	}
+
+	for _, domain := range []string{"example.com", "example.org", "a.example.com", "example.net", "b.example.org"} {
+		require.NoError(t, p.Present(domain, "token", "keyAuth"))
+	}
+
+	require.NoError(t, p.CleanUp("b.example.org", "token", "keyAuth"))
+
+	// the zones are assigned in turn, the records of a zone are managed by the same member.
+	assert.Equal(t, []string{"example.com", "a.example.com", "example.net"}, first.presented)
+	assert.Equal(t, []string{"example.org", "b.example.org"}, second.presented)
+	assert.Equal(t, []string{"b.example.org"}, second.cleaned)
+	assert.Empty(t, first.cleaned)
+}
+
+func TestDNSProvider_Timeout(t *testing.T) {
+	config := &Config{
+		PropagationTimeout: time.Minute,
+		PollingInterval:    10 * time.Second,
+		Members: []challenge.Provider{
+			&mockProvider{timeout: 5 * time.Minute, interval: 20 * time.Second},
+			&mockProvider{timeout: 30 * time.Second, interval: 5 * time.Second},
+		},
+	}
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	timeout, interval := p.Timeout()
+
+	assert.Equal(t, 5*time.Minute, timeout)
+	assert.Equal(t, 5*time.Second, interval)
+}
+
+type verifierProvider struct {
+	mockProvider
+
+	err error
+}
+
+func (p *verifierProvider) VerifyCredentials(_ context.Context) error {
+	return p.err
+}
+
+func TestDNSProvider_VerifyCredentials(t *testing.T) {
+	config := &Config{
+		Members: []challenge.Provider{
+			&verifierProvider{},
+			// the members unable to check their credentials are ignored.
+			&mockProvider{},
+			&verifierProvider{err: errors.New("invalid token")},
+		},
+	}
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = p.VerifyCredentials(context.Background())
+	require.EqualError(t, err, "farm: member 2: invalid token")
+}
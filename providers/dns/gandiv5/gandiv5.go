@@ -52,20 +52,29 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 20*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 20*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 20*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 20*time.Second),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 10*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 10*time.Second),
 		},
 	}
 }
 
+type dnsClient interface {
+	AddTXTRecord(ctx context.Context, domain, name, value string, ttl int) error
+	DeleteTXTRecord(ctx context.Context, domain, name string) error
+}
+
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
-	client *internal.Client
+	client dnsClient
 
 	inProgressFQDNs map[string]inProgressInfo
 	inProgressMu    sync.Mutex
@@ -79,10 +88,16 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for Gandi.
 // Credentials must be passed in the environment variable: GANDIV5_API_KEY.
 func NewDNSProvider() (*DNSProvider, error) {
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
 	// TODO(ldez): rewrite this when APIKey will be removed.
-	config := NewDefaultConfig()
-	config.APIKey = env.GetOrFile(EnvAPIKey)
-	config.PersonalAccessToken = env.GetOrFile(EnvPersonalAccessToken)
+	config := newDefaultConfig(e)
+	config.APIKey = e.GetOrFile(EnvAPIKey)
+	config.PersonalAccessToken = e.GetOrFile(EnvPersonalAccessToken)
 
 	return NewDNSProviderConfig(config)
 }
@@ -0,0 +1,144 @@
+package gandiv5
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider_Present_mock(t *testing.T) {
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	testCases := []struct {
+		desc          string
+		addErr        error
+		expectedError string
+	}{
+		{
+			desc: "success",
+		},
+		{
+			desc:          "add error",
+			addErr:        errors.New("oops"),
+			expectedError: "oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("AddTXTRecord", "example.com", "_acme-challenge.sub", info.Value, provider.config.TTL).Return(test.addErr)
+
+			err := provider.Present("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				assert.Equal(t, map[string]inProgressInfo{
+					info.EffectiveFQDN: {authZone: "example.com.", fieldName: "_acme-challenge.sub"},
+				}, provider.inProgressFQDNs)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+				assert.Empty(t, provider.inProgressFQDNs)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_CleanUp_mock(t *testing.T) {
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	testCases := []struct {
+		desc          string
+		inProgress    map[string]inProgressInfo
+		deleteErr     error
+		callDelete    bool
+		expectedError string
+	}{
+		{
+			desc: "success",
+			inProgress: map[string]inProgressInfo{
+				info.EffectiveFQDN: {authZone: "example.com.", fieldName: "_acme-challenge.sub"},
+			},
+			callDelete: true,
+		},
+		{
+			desc:       "not in progress",
+			inProgress: map[string]inProgressInfo{},
+		},
+		{
+			desc: "delete error",
+			inProgress: map[string]inProgressInfo{
+				info.EffectiveFQDN: {authZone: "example.com.", fieldName: "_acme-challenge.sub"},
+			},
+			deleteErr:     errors.New("oops"),
+			callDelete:    true,
+			expectedError: "gandiv5: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+			provider.inProgressFQDNs = test.inProgress
+
+			if test.callDelete {
+				client.On("DeleteTXTRecord", "example.com", "_acme-challenge.sub").Return(test.deleteErr)
+			}
+
+			err := provider.CleanUp("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+
+			assert.Empty(t, provider.inProgressFQDNs)
+		})
+	}
+}
+
+func newMockedProvider(t *testing.T, client dnsClient) *DNSProvider {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.PersonalAccessToken = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client = client
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider
+}
+
+type mockedClient struct {
+	mock.Mock
+}
+
+func (c *mockedClient) AddTXTRecord(_ context.Context, domain, name, value string, ttl int) error {
+	args := c.Called(domain, name, value, ttl)
+	return args.Error(0)
+}
+
+func (c *mockedClient) DeleteTXTRecord(_ context.Context, domain, name string) error {
+	args := c.Called(domain, name)
+	return args.Error(0)
+}
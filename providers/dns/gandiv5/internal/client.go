@@ -213,5 +213,5 @@ func parseError(req *http.Request, resp *http.Response) error {
 		return errutils.NewUnexpectedStatusCodeError(req, resp.StatusCode, raw)
 	}
 
-	return fmt.Errorf("%d: request failed: %s", resp.StatusCode, response.Message)
+	return errutils.CategorizeStatusCode(resp.StatusCode, fmt.Errorf("%d: request failed: %s", resp.StatusCode, response.Message))
 }
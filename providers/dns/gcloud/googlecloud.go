@@ -8,13 +8,16 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/compute/metadata"
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/platform/config/env"
 	"github.com/pya789/lego/v4/platform/wait"
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/dns/v1"
@@ -26,6 +29,12 @@ const (
 	changeStatusDone = "done"
 )
 
+// Managed zone visibilities.
+const (
+	visibilityPublic  = "public"
+	visibilityPrivate = "private"
+)
+
 // Environment variables names.
 const (
 	envNamespace = "GCE_"
@@ -34,6 +43,7 @@ const (
 	EnvProject          = envNamespace + "PROJECT"
 	EnvZoneID           = envNamespace + "ZONE_ID"
 	EnvAllowPrivateZone = envNamespace + "ALLOW_PRIVATE_ZONE"
+	EnvZoneVisibility   = envNamespace + "ZONE_VISIBILITY"
 	EnvDebug            = envNamespace + "DEBUG"
 
 	EnvTTL                = envNamespace + "TTL"
@@ -47,6 +57,7 @@ type Config struct {
 	Project            string
 	ZoneID             string
 	AllowPrivateZone   bool
+	ZoneVisibility     string
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	TTL                int
@@ -55,13 +66,18 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		Debug:              env.GetOrDefaultBool(EnvDebug, false),
-		ZoneID:             env.GetOrDefaultString(EnvZoneID, ""),
-		AllowPrivateZone:   env.GetOrDefaultBool(EnvAllowPrivateZone, false),
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 180*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 5*time.Second),
+		Debug:              e.GetOrDefaultBool(EnvDebug, false),
+		ZoneID:             e.GetOrDefaultString(EnvZoneID, ""),
+		AllowPrivateZone:   e.GetOrDefaultBool(EnvAllowPrivateZone, false),
+		ZoneVisibility:     e.GetOrDefaultString(EnvZoneVisibility, ""),
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 180*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 5*time.Second),
 	}
 }
 
@@ -69,6 +85,18 @@ func NewDefaultConfig() *Config {
 type DNSProvider struct {
 	config *Config
 	client *dns.Service
+
+	changes   map[string]pendingChange
+	changesMu sync.Mutex
+
+	tx   *transaction
+	txMu sync.Mutex
+}
+
+// pendingChange a change not yet applied by Cloud DNS.
+type pendingChange struct {
+	zone string
+	id   string
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for Google Cloud DNS.
@@ -77,19 +105,29 @@ type DNSProvider struct {
 // A Service Account can be passed in the environment variable: GCE_SERVICE_ACCOUNT
 // or by specifying the keyfile location: GCE_SERVICE_ACCOUNT_FILE.
 func NewDNSProvider() (*DNSProvider, error) {
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
 	// Use a service account file if specified via environment variable.
-	if saKey := env.GetOrFile(EnvServiceAccount); saKey != "" {
-		return NewDNSProviderServiceAccountKey([]byte(saKey))
+	if saKey := e.GetOrFile(EnvServiceAccount); saKey != "" {
+		return newDNSProviderServiceAccountKey(e, []byte(saKey))
 	}
 
 	// Use default credentials.
-	project := env.GetOrDefaultString(EnvProject, autodetectProjectID())
-	return NewDNSProviderCredentials(project)
+	project := e.GetOrDefaultString(EnvProject, autodetectProjectID())
+	return newDNSProviderCredentials(e, project)
 }
 
 // NewDNSProviderCredentials uses the supplied credentials
 // to return a DNSProvider instance configured for Google Cloud DNS.
 func NewDNSProviderCredentials(project string) (*DNSProvider, error) {
+	return newDNSProviderCredentials(env.Getter{}, project)
+}
+
+func newDNSProviderCredentials(e env.Getter, project string) (*DNSProvider, error) {
 	if project == "" {
 		return nil, errors.New("googlecloud: project name missing")
 	}
@@ -99,7 +137,7 @@ func NewDNSProviderCredentials(project string) (*DNSProvider, error) {
 		return nil, fmt.Errorf("googlecloud: unable to get Google Cloud client: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Project = project
 	config.HTTPClient = client
 
@@ -109,13 +147,17 @@ func NewDNSProviderCredentials(project string) (*DNSProvider, error) {
 // NewDNSProviderServiceAccountKey uses the supplied service account JSON
 // to return a DNSProvider instance configured for Google Cloud DNS.
 func NewDNSProviderServiceAccountKey(saKey []byte) (*DNSProvider, error) {
+	return newDNSProviderServiceAccountKey(env.Getter{}, saKey)
+}
+
+func newDNSProviderServiceAccountKey(e env.Getter, saKey []byte) (*DNSProvider, error) {
 	if len(saKey) == 0 {
 		return nil, errors.New("googlecloud: Service Account is missing")
 	}
 
 	// If GCE_PROJECT is non-empty it overrides the project in the service
 	// account file.
-	project := env.GetOrDefaultString(EnvProject, "")
+	project := e.GetOrDefaultString(EnvProject, "")
 	if project == "" {
 		// read project id from service account file
 		var datJSON struct {
@@ -134,7 +176,7 @@ func NewDNSProviderServiceAccountKey(saKey []byte) (*DNSProvider, error) {
 	}
 	client := conf.Client(context.Background())
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Project = project
 	config.HTTPClient = client
 
@@ -165,12 +207,22 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("googlecloud: unable to create Google Cloud DNS service: client is nil")
 	}
 
+	switch config.ZoneVisibility {
+	case "", visibilityPublic, visibilityPrivate:
+	default:
+		return nil, fmt.Errorf("googlecloud: invalid zone visibility %q (expected %q or %q)", config.ZoneVisibility, visibilityPublic, visibilityPrivate)
+	}
+
 	svc, err := dns.NewService(context.Background(), option.WithHTTPClient(config.HTTPClient))
 	if err != nil {
 		return nil, fmt.Errorf("googlecloud: unable to create Google Cloud DNS service: %w", err)
 	}
 
-	return &DNSProvider{config: config, client: svc}, nil
+	return &DNSProvider{
+		config:  config,
+		client:  svc,
+		changes: make(map[string]pendingChange),
+	}, nil
 }
 
 // Present creates a TXT record to fulfill the dns-01 challenge.
@@ -182,6 +234,18 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("googlecloud: %w", err)
 	}
 
+	staged, err := d.stage(zone, info.EffectiveFQDN, func(zc *zoneChanges, values []string) []string {
+		zc.tokens = append(zc.tokens, token)
+		return addValue(values, info.Value)
+	})
+	if staged {
+		if err != nil {
+			return fmt.Errorf("googlecloud: %w", err)
+		}
+
+		return nil
+	}
+
 	// Look for existing records.
 	existingRrSet, err := d.findTxtRecords(zone, info.EffectiveFQDN)
 	if err != nil {
@@ -204,8 +268,16 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 
 	// Attempt to delete the existing records before adding the new one.
 	if len(existingRrSet) > 0 {
-		if err = d.applyChanges(zone, &dns.Change{Deletions: existingRrSet}); err != nil {
-			return fmt.Errorf("googlecloud: %w", err)
+		chg, errC := d.applyChanges(zone, &dns.Change{Deletions: existingRrSet})
+		if errC != nil {
+			return fmt.Errorf("googlecloud: %w", errC)
+		}
+
+		// wait for change to be acknowledged
+		if chg != nil && chg.Status != changeStatusDone {
+			if err = d.waitForChange(context.Background(), zone, chg.Id, 30*time.Second, 3*time.Second); err != nil {
+				return fmt.Errorf("googlecloud: %w", err)
+			}
 		}
 	}
 
@@ -229,14 +301,53 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		Additions: []*dns.ResourceRecordSet{rec},
 	}
 
-	if err = d.applyChanges(zone, change); err != nil {
+	chg, err := d.applyChanges(zone, change)
+	if err != nil {
 		return fmt.Errorf("googlecloud: %w", err)
 	}
 
+	// wait for change to be acknowledged
+	if chg != nil && chg.Status != changeStatusDone {
+		if err = d.waitForChange(context.Background(), zone, chg.Id, 30*time.Second, 3*time.Second); err != nil {
+			return fmt.Errorf("googlecloud: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func (d *DNSProvider) applyChanges(zone string, change *dns.Change) error {
+// IdempotentPresent reports that Present skips the records already created: it can be called again safely.
+func (d *DNSProvider) IdempotentPresent() bool {
+	return true
+}
+
+// WaitForPropagation waits until the status of the change of the TXT record is "done":
+// Cloud DNS reports that the change has been applied to its authoritative nameservers.
+// Only the changes applied by a transaction are pending, Present waits for its changes.
+func (d *DNSProvider) WaitForPropagation(domain, token, keyAuth string) error {
+	return d.WaitForPropagationContext(context.Background(), domain, token, keyAuth)
+}
+
+// WaitForPropagationContext is like WaitForPropagation, the polling stops when the context is done.
+func (d *DNSProvider) WaitForPropagationContext(ctx context.Context, domain, token, keyAuth string) error {
+	d.changesMu.Lock()
+	chg, ok := d.changes[token]
+	d.changesMu.Unlock()
+
+	if !ok {
+		// the change is already done, or the record already existed.
+		return nil
+	}
+
+	err := d.waitForChange(ctx, chg.zone, chg.id, d.config.PropagationTimeout, d.config.PollingInterval)
+	if err != nil {
+		return fmt.Errorf("googlecloud: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DNSProvider) applyChanges(zone string, change *dns.Change) (*dns.Change, error) {
 	if d.config.Debug {
 		data, _ := json.Marshal(change)
 		log.Printf("change (Create): %s", string(data))
@@ -246,30 +357,25 @@ func (d *DNSProvider) applyChanges(zone string, change *dns.Change) error {
 	if err != nil {
 		var v *googleapi.Error
 		if errors.As(err, &v) && v.Code == http.StatusNotFound {
-			return nil
+			return nil, nil
 		}
 
 		data, _ := json.Marshal(change)
-		return fmt.Errorf("failed to perform changes [zone %s, change %s]: %w", zone, string(data), err)
-	}
-
-	if chg.Status == changeStatusDone {
-		return nil
+		return nil, fmt.Errorf("failed to perform changes [zone %s, change %s]: %w", zone, string(data), categorizeError(err))
 	}
 
-	chgID := chg.Id
+	return chg, nil
+}
 
-	// wait for change to be acknowledged
-	return wait.For("apply change", 30*time.Second, 3*time.Second, func() (bool, error) {
+func (d *DNSProvider) waitForChange(ctx context.Context, zone, chgID string, timeout, interval time.Duration) error {
+	return wait.ForContext(ctx, "apply change", timeout, interval, func() (bool, error) {
 		if d.config.Debug {
-			data, _ := json.Marshal(change)
-			log.Printf("change (Get): %s", string(data))
+			log.Printf("change (Get): %s", chgID)
 		}
 
-		chg, err = d.client.Changes.Get(d.config.Project, zone, chgID).Do()
+		chg, err := d.client.Changes.Get(d.config.Project, zone, chgID).Context(ctx).Do()
 		if err != nil {
-			data, _ := json.Marshal(change)
-			return false, fmt.Errorf("failed to get changes [zone %s, change %s]: %w", zone, string(data), err)
+			return false, fmt.Errorf("failed to get changes [zone %s, change %s]: %w", zone, chgID, categorizeError(err))
 		}
 
 		if chg.Status == changeStatusDone {
@@ -284,11 +390,26 @@ func (d *DNSProvider) applyChanges(zone string, change *dns.Change) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
+	d.changesMu.Lock()
+	delete(d.changes, token)
+	d.changesMu.Unlock()
+
 	zone, err := d.getHostedZone(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("googlecloud: %w", err)
 	}
 
+	staged, err := d.stage(zone, info.EffectiveFQDN, func(_ *zoneChanges, values []string) []string {
+		return removeValue(values, info.Value)
+	})
+	if staged {
+		if err != nil {
+			return fmt.Errorf("googlecloud: %w", err)
+		}
+
+		return nil
+	}
+
 	records, err := d.findTxtRecords(zone, info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("googlecloud: %w", err)
@@ -300,7 +421,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	_, err = d.client.Changes.Create(d.config.Project, zone, &dns.Change{Deletions: records}).Do()
 	if err != nil {
-		return fmt.Errorf("googlecloud: %w", err)
+		return fmt.Errorf("googlecloud: %w", categorizeError(err))
 	}
 	return nil
 }
@@ -319,20 +440,59 @@ func (d *DNSProvider) getHostedZone(domain string) (string, error) {
 	}
 
 	if len(zones) == 0 {
-		return "", fmt.Errorf("no matching domain found for domain %s", authZone)
+		return "", challenge.NotFoundZone(fmt.Errorf("no matching domain found for domain %s", authZone))
 	}
 
+	var peeringZones []*dns.ManagedZone
+
 	for _, z := range zones {
-		if z.Visibility == "public" || z.Visibility == "" || (z.Visibility == "private" && d.config.AllowPrivateZone) {
-			return z.Name, nil
+		if !d.isVisibilityAllowed(z.Visibility) {
+			continue
+		}
+
+		// The records of a peering zone are resolved by the zone of the target network:
+		// the records must be created inside this zone.
+		if z.PeeringConfig != nil {
+			peeringZones = append(peeringZones, z)
+			continue
+		}
+
+		return z.Name, nil
+	}
+
+	if len(peeringZones) > 0 {
+		z := peeringZones[0]
+
+		var network string
+		if z.PeeringConfig.TargetNetwork != nil {
+			network = z.PeeringConfig.TargetNetwork.NetworkUrl
 		}
+
+		return "", fmt.Errorf("the zone %s (%s) is a DNS peering zone, the records must be created in the zone of the target network %q", z.Name, authZone, network)
+	}
+
+	switch {
+	case d.config.ZoneVisibility != "":
+		return "", challenge.NotFoundZone(fmt.Errorf("no %s zone found for domain %s", d.config.ZoneVisibility, authZone))
+	case d.config.AllowPrivateZone:
+		return "", challenge.NotFoundZone(fmt.Errorf("no public or private zone found for domain %s", authZone))
+	default:
+		return "", challenge.NotFoundZone(fmt.Errorf("no public zone found for domain %s", authZone))
+	}
+}
+
+// isVisibilityAllowed checks the visibility of a managed zone against the configuration.
+// If the zone visibility is not explicitly defined, the public zones are allowed, and the private zones only if AllowPrivateZone is enabled.
+func (d *DNSProvider) isVisibilityAllowed(visibility string) bool {
+	if visibility == "" {
+		visibility = visibilityPublic
 	}
 
-	if d.config.AllowPrivateZone {
-		return "", fmt.Errorf("no public or private zone found for domain %s", authZone)
+	if d.config.ZoneVisibility != "" {
+		return visibility == d.config.ZoneVisibility
 	}
 
-	return "", fmt.Errorf("no public zone found for domain %s", authZone)
+	return visibility == visibilityPublic || (visibility == visibilityPrivate && d.config.AllowPrivateZone)
 }
 
 // lookupHostedZoneID finds the managed zone ID in Google.
@@ -352,7 +512,7 @@ func (d *DNSProvider) lookupHostedZoneID(domain string) (string, []*dns.ManagedZ
 	if d.config.ZoneID != "" {
 		zone, err := d.client.ManagedZones.Get(d.config.Project, d.config.ZoneID).Do()
 		if err != nil {
-			return "", nil, fmt.Errorf("API call ManagedZones.Get for explicit zone ID %q in project %q failed: %w", d.config.ZoneID, d.config.Project, err)
+			return "", nil, fmt.Errorf("API call ManagedZones.Get for explicit zone ID %q in project %q failed: %w", d.config.ZoneID, d.config.Project, categorizeError(err))
 		}
 
 		return zone.DnsName, []*dns.ManagedZone{zone}, nil
@@ -368,7 +528,7 @@ func (d *DNSProvider) lookupHostedZoneID(domain string) (string, []*dns.ManagedZ
 		DnsName(authZone).
 		Do()
 	if err != nil {
-		return "", nil, fmt.Errorf("API call ManagedZones.List failed: %w", err)
+		return "", nil, fmt.Errorf("API call ManagedZones.List failed: %w", categorizeError(err))
 	}
 
 	return authZone, zones.ManagedZones, nil
@@ -377,12 +537,22 @@ func (d *DNSProvider) lookupHostedZoneID(domain string) (string, []*dns.ManagedZ
 func (d *DNSProvider) findTxtRecords(zone, fqdn string) ([]*dns.ResourceRecordSet, error) {
 	recs, err := d.client.ResourceRecordSets.List(d.config.Project, zone).Name(fqdn).Type("TXT").Do()
 	if err != nil {
-		return nil, err
+		return nil, categorizeError(err)
 	}
 
 	return recs.Rrsets, nil
 }
 
+// categorizeError categorizes the errors of the Google Cloud API with their HTTP status codes.
+func categorizeError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	return errutils.CategorizeStatusCode(apiErr.Code, err)
+}
+
 func mustUnquote(raw string) string {
 	clean, err := strconv.Unquote(raw)
 	if err != nil {
@@ -9,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2/google"
@@ -385,6 +387,249 @@ func TestPresentSkipExistingRR(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPresentPeeringZone(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	// lookupHostedZoneID: /manhattan/managedZones/test?alt=json
+	mux.HandleFunc("/dns/v1/projects/manhattan/managedZones/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		mz := &dns.ManagedZone{
+			Name:       "test",
+			DnsName:    "lego.wtf.",
+			Visibility: "private",
+			PeeringConfig: &dns.ManagedZonePeeringConfig{
+				TargetNetwork: &dns.ManagedZonePeeringConfigTargetNetwork{NetworkUrl: "network-a"},
+			},
+		}
+
+		err := json.NewEncoder(w).Encode(mz)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	config := NewDefaultConfig()
+	config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	config.Project = "manhattan"
+	config.ZoneID = "test"
+	config.ZoneVisibility = "private"
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	p.client.BasePath = server.URL
+
+	err = p.Present("lego.wtf", "", "")
+	require.EqualError(t, err, `googlecloud: the zone test (lego.wtf.) is a DNS peering zone, the records must be created in the zone of the target network "network-a"`)
+}
+
+func TestDNSProvider_Commit(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	// lookupHostedZoneID: /manhattan/managedZones/test?alt=json
+	mux.HandleFunc("/dns/v1/projects/manhattan/managedZones/test", func(w http.ResponseWriter, _ *http.Request) {
+		err := json.NewEncoder(w).Encode(&dns.ManagedZone{Name: "test", DnsName: "lego.wtf.", Visibility: "public"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	// findTxtRecords: /manhattan/managedZones/test/rrsets?alt=json&name=_acme-challenge.lego.wtf.&type=TXT
+	mux.HandleFunc("/dns/v1/projects/manhattan/managedZones/test/rrsets", func(w http.ResponseWriter, _ *http.Request) {
+		rrslr := &dns.ResourceRecordSetsListResponse{
+			Rrsets: []*dns.ResourceRecordSet{{
+				Name:    "_acme-challenge.lego.wtf.",
+				Rrdatas: []string{`"huji"`},
+				Ttl:     120,
+				Type:    "TXT",
+			}},
+		}
+
+		err := json.NewEncoder(w).Encode(rrslr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	var changes []dns.Change
+
+	// applyChanges [Create]: /manhattan/managedZones/test/changes?alt=json
+	mux.HandleFunc("/dns/v1/projects/manhattan/managedZones/test/changes", func(w http.ResponseWriter, r *http.Request) {
+		var chgReq dns.Change
+		if err := json.NewDecoder(r.Body).Decode(&chgReq); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		changes = append(changes, chgReq)
+
+		chgResp := chgReq
+		chgResp.Status = changeStatusDone
+
+		if err := json.NewEncoder(w).Encode(chgResp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	config := NewDefaultConfig()
+	config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	config.Project = "manhattan"
+	config.ZoneID = "test"
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	p.client.BasePath = server.URL
+
+	require.NoError(t, p.Begin())
+
+	// wildcard and apex: the same record set.
+	require.NoError(t, p.Present("lego.wtf", "token1", "a"))
+	require.NoError(t, p.Present("lego.wtf", "token2", "b"))
+
+	assert.Empty(t, changes)
+
+	require.NoError(t, p.Commit())
+
+	require.Len(t, changes, 1)
+	require.Len(t, changes[0].Deletions, 1)
+	require.Len(t, changes[0].Additions, 1)
+
+	expected := []string{"huji", dns01.GetChallengeInfo("lego.wtf", "a").Value, dns01.GetChallengeInfo("lego.wtf", "b").Value}
+	assert.Equal(t, expected, changes[0].Additions[0].Rrdatas)
+
+	require.NoError(t, p.Begin())
+	require.NoError(t, p.CleanUp("lego.wtf", "token1", "a"))
+	p.Rollback()
+
+	assert.Len(t, changes, 1)
+	require.EqualError(t, p.Commit(), "googlecloud: no transaction in progress")
+}
+
+func TestDNSProvider_WaitForPropagation(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	// lookupHostedZoneID: /manhattan/managedZones/test?alt=json
+	mux.HandleFunc("/dns/v1/projects/manhattan/managedZones/test", func(w http.ResponseWriter, _ *http.Request) {
+		err := json.NewEncoder(w).Encode(&dns.ManagedZone{Name: "test", DnsName: "lego.wtf.", Visibility: "public"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	// findTxtRecords: /manhattan/managedZones/test/rrsets?alt=json&name=_acme-challenge.lego.wtf.&type=TXT
+	mux.HandleFunc("/dns/v1/projects/manhattan/managedZones/test/rrsets", func(w http.ResponseWriter, _ *http.Request) {
+		err := json.NewEncoder(w).Encode(&dns.ResourceRecordSetsListResponse{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	// applyChanges [Create]: /manhattan/managedZones/test/changes?alt=json
+	mux.HandleFunc("/dns/v1/projects/manhattan/managedZones/test/changes", func(w http.ResponseWriter, r *http.Request) {
+		var chgReq dns.Change
+		if err := json.NewDecoder(r.Body).Decode(&chgReq); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		chgResp := chgReq
+		chgResp.Id = "1"
+		chgResp.Status = "pending"
+
+		if err := json.NewEncoder(w).Encode(chgResp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	var gets int
+
+	// waitForChange [Get]: /manhattan/managedZones/test/changes/1?alt=json
+	mux.HandleFunc("/dns/v1/projects/manhattan/managedZones/test/changes/1", func(w http.ResponseWriter, _ *http.Request) {
+		gets++
+
+		if err := json.NewEncoder(w).Encode(&dns.Change{Id: "1", Status: changeStatusDone}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	config := NewDefaultConfig()
+	config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	config.Project = "manhattan"
+	config.ZoneID = "test"
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	p.client.BasePath = server.URL
+
+	// without a transaction, Present waits for the change.
+	require.NoError(t, p.Present("lego.wtf", "token1", "a"))
+	assert.Equal(t, 1, gets)
+
+	require.NoError(t, p.WaitForPropagation("lego.wtf", "token1", "a"))
+	assert.Equal(t, 1, gets)
+
+	// with a transaction, the change is pending until WaitForPropagation.
+	require.NoError(t, p.Begin())
+	require.NoError(t, p.Present("lego.wtf", "token2", "b"))
+	require.NoError(t, p.Commit())
+	assert.Equal(t, 1, gets)
+
+	require.NoError(t, p.WaitForPropagation("lego.wtf", "token2", "b"))
+	assert.Equal(t, 2, gets)
+
+	// the pending change is forgotten by the clean-up.
+	require.NoError(t, p.CleanUp("lego.wtf", "token2", "b"))
+
+	require.NoError(t, p.WaitForPropagation("lego.wtf", "token2", "b"))
+	assert.Equal(t, 2, gets)
+}
+
+func TestDNSProvider_isVisibilityAllowed(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		zoneVisibility   string
+		allowPrivateZone bool
+		visibility       string
+		expected         bool
+	}{
+		{desc: "default: public", visibility: "public", expected: true},
+		{desc: "default: empty", visibility: "", expected: true},
+		{desc: "default: private", visibility: "private", expected: false},
+		{desc: "allow private zone: private", allowPrivateZone: true, visibility: "private", expected: true},
+		{desc: "public only: private", zoneVisibility: "public", allowPrivateZone: true, visibility: "private", expected: false},
+		{desc: "private only: private", zoneVisibility: "private", visibility: "private", expected: true},
+		{desc: "private only: public", zoneVisibility: "private", visibility: "public", expected: false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			d := &DNSProvider{config: &Config{ZoneVisibility: test.zoneVisibility, AllowPrivateZone: test.allowPrivateZone}}
+
+			assert.Equal(t, test.expected, d.isVisibilityAllowed(test.visibility))
+		})
+	}
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
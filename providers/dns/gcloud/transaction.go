@@ -0,0 +1,181 @@
+package gcloud
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/pya789/lego/v4/log"
+	"google.golang.org/api/dns/v1"
+)
+
+// transaction the changes staged between Begin and Commit, by managed zone.
+type transaction struct {
+	zones     map[string]*zoneChanges
+	zoneNames []string
+}
+
+// zoneChanges the changes of the TXT record sets of a managed zone.
+type zoneChanges struct {
+	recordSets map[string]*stagedRecordSet
+	fqdns      []string
+
+	// tokens the tokens of the presented challenges, associated to the change.
+	tokens []string
+}
+
+// stagedRecordSet a TXT record set: the record sets before the transaction, and the values after the transaction.
+type stagedRecordSet struct {
+	existing []*dns.ResourceRecordSet
+	values   []string
+}
+
+// Begin starts a transaction: Present and CleanUp stage the changes until Commit.
+func (d *DNSProvider) Begin() error {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+
+	if d.tx != nil {
+		return errors.New("googlecloud: a transaction is already in progress")
+	}
+
+	d.tx = &transaction{zones: make(map[string]*zoneChanges)}
+
+	return nil
+}
+
+// Commit applies the staged changes, in one change by managed zone.
+// If the change of a managed zone fails, the changes of the other managed zones are reverted.
+func (d *DNSProvider) Commit() error {
+	d.txMu.Lock()
+	tx := d.tx
+	d.tx = nil
+	d.txMu.Unlock()
+
+	if tx == nil {
+		return errors.New("googlecloud: no transaction in progress")
+	}
+
+	var applied []string
+
+	for _, zone := range tx.zoneNames {
+		zc := tx.zones[zone]
+
+		change := zc.change(d.config.TTL)
+		if len(change.Additions) == 0 && len(change.Deletions) == 0 {
+			continue
+		}
+
+		chg, err := d.applyChanges(zone, change)
+		if err != nil {
+			d.revert(tx, applied)
+
+			return fmt.Errorf("googlecloud: %w", err)
+		}
+
+		applied = append(applied, zone)
+
+		if chg != nil && chg.Status != changeStatusDone {
+			d.changesMu.Lock()
+			for _, token := range zc.tokens {
+				d.changes[token] = pendingChange{zone: zone, id: chg.Id}
+			}
+			d.changesMu.Unlock()
+		}
+	}
+
+	return nil
+}
+
+// Rollback discards the staged changes.
+func (d *DNSProvider) Rollback() {
+	d.txMu.Lock()
+	d.tx = nil
+	d.txMu.Unlock()
+}
+
+// revert restores the record sets of the managed zones already changed by a transaction.
+func (d *DNSProvider) revert(tx *transaction, zones []string) {
+	for _, zone := range zones {
+		change := tx.zones[zone].change(d.config.TTL)
+
+		_, err := d.applyChanges(zone, &dns.Change{Additions: change.Deletions, Deletions: change.Additions})
+		if err != nil {
+			log.Warnf("googlecloud: failed to revert the changes of the managed zone %s: %v", zone, err)
+		}
+	}
+}
+
+// stage updates the values of a TXT record set in the current transaction.
+// It returns false if there is no transaction in progress.
+func (d *DNSProvider) stage(zone, fqdn string, update func(zc *zoneChanges, values []string) []string) (bool, error) {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+
+	if d.tx == nil {
+		return false, nil
+	}
+
+	zc, ok := d.tx.zones[zone]
+	if !ok {
+		zc = &zoneChanges{recordSets: make(map[string]*stagedRecordSet)}
+		d.tx.zones[zone] = zc
+		d.tx.zoneNames = append(d.tx.zoneNames, zone)
+	}
+
+	rs, ok := zc.recordSets[fqdn]
+	if !ok {
+		existing, err := d.findTxtRecords(zone, fqdn)
+		if err != nil {
+			return true, err
+		}
+
+		rs = &stagedRecordSet{existing: existing}
+		for _, rrSet := range existing {
+			for _, rr := range rrSet.Rrdatas {
+				rs.values = addValue(rs.values, mustUnquote(rr))
+			}
+		}
+
+		zc.recordSets[fqdn] = rs
+		zc.fqdns = append(zc.fqdns, fqdn)
+	}
+
+	rs.values = update(zc, rs.values)
+
+	return true, nil
+}
+
+// change returns the change replacing the existing record sets by the staged values.
+func (z *zoneChanges) change(ttl int) *dns.Change {
+	change := &dns.Change{}
+
+	for _, fqdn := range z.fqdns {
+		rs := z.recordSets[fqdn]
+
+		change.Deletions = append(change.Deletions, rs.existing...)
+
+		if len(rs.values) > 0 {
+			change.Additions = append(change.Additions, &dns.ResourceRecordSet{
+				Name:    fqdn,
+				Rrdatas: rs.values,
+				Ttl:     int64(ttl),
+				Type:    "TXT",
+			})
+		}
+	}
+
+	return change
+}
+
+func addValue(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+
+	return append(values, value)
+}
+
+func removeValue(values []string, value string) []string {
+	return slices.DeleteFunc(slices.Clone(values), func(v string) bool { return v == value })
+}
@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
 	"github.com/pya789/lego/v4/providers/dns/godaddy/internal"
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
+	"github.com/pya789/lego/v4/providers/dns/internal/retryhttp"
 )
 
 const minTTL = 600
@@ -28,6 +31,11 @@ const (
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
 )
 
+type dnsClient interface {
+	GetRecords(ctx context.Context, domainZone, rType, recordName string) ([]internal.DNSRecord, error)
+	UpdateTxtRecords(ctx context.Context, records []internal.DNSRecord, domainZone, recordName string) error
+}
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	APIKey             string
@@ -40,32 +48,45 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
-		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
-		},
+		TTL:                e.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		// the rate limits (429) and the server errors are retried, honoring the delay of the rate limits (retryAfterSec).
+		HTTPClient: retryhttp.NewClient(e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			retryhttp.WithRetryAfter(internal.RetryAfter)),
 	}
 }
 
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
-	client *internal.Client
+	client dnsClient
+
+	// only for testing purpose.
+	findZoneByFqdn func(fqdn string) (string, error)
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for godaddy.
 // Credentials must be passed in the environment variables:
 // GODADDY_API_KEY and GODADDY_API_SECRET.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIKey, EnvAPISecret)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIKey, EnvAPISecret)
 	if err != nil {
 		return nil, fmt.Errorf("godaddy: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.APIKey = values[EnvAPIKey]
 	config.APISecret = values[EnvAPISecret]
 
@@ -92,7 +113,11 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		client.HTTPClient = config.HTTPClient
 	}
 
-	return &DNSProvider{config: config, client: client}, nil
+	return &DNSProvider{
+		config:         config,
+		client:         client,
+		findZoneByFqdn: dns01.FindZoneByFqdn,
+	}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
@@ -105,7 +130,7 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("godaddy: could not find zone for domain %q: %w", domain, err)
 	}
@@ -121,11 +146,16 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 
 	records, err := d.client.GetRecords(ctx, authZone, "TXT", subDomain)
 	if err != nil {
-		return fmt.Errorf("godaddy: failed to get TXT records: %w", err)
+		return fmt.Errorf("godaddy: failed to get TXT records: %w", categorizeError(err))
 	}
 
 	var newRecords []internal.DNSRecord
 	for _, record := range records {
+		if record.Data == info.Value {
+			// already created by a previous call.
+			return nil
+		}
+
 		if record.Data != "" {
 			newRecords = append(newRecords, record)
 		}
@@ -151,7 +181,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("godaddy: could not find zone for domain %q: %w", domain, err)
 	}
@@ -167,7 +197,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	records, err := d.client.GetRecords(ctx, authZone, "TXT", subDomain)
 	if err != nil {
-		return fmt.Errorf("godaddy: failed to get TXT records: %w", err)
+		return fmt.Errorf("godaddy: failed to get TXT records: %w", categorizeError(err))
 	}
 
 	if len(records) == 0 {
@@ -199,3 +229,18 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	return nil
 }
+
+// IdempotentPresent reports that Present doesn't create the record again if it already exists.
+func (d *DNSProvider) IdempotentPresent() bool {
+	return true
+}
+
+// categorizeError categorizes the unknown domains (the API responds with a 404).
+func categorizeError(err error) error {
+	var statusErr *errutils.UnexpectedStatusCodeError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return challenge.NotFoundZone(err)
+	}
+
+	return err
+}
@@ -0,0 +1,199 @@
+package godaddy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/providers/dns/godaddy/internal"
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider_Present_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	existing := internal.DNSRecord{Type: "TXT", Name: "_acme-challenge.sub", Data: "other", TTL: 600}
+
+	testCases := []struct {
+		desc          string
+		records       []internal.DNSRecord
+		getErr        error
+		updateErr     error
+		callUpdate    bool
+		expected      []internal.DNSRecord
+		expectedError string
+	}{
+		{
+			desc:       "no existing records",
+			callUpdate: true,
+			expected: []internal.DNSRecord{
+				{Type: "TXT", Name: "_acme-challenge.sub", Data: info.Value, TTL: 600},
+			},
+		},
+		{
+			desc:       "existing records are kept",
+			records:    []internal.DNSRecord{existing, {Name: "empty"}},
+			callUpdate: true,
+			expected: []internal.DNSRecord{
+				existing,
+				{Type: "TXT", Name: "_acme-challenge.sub", Data: info.Value, TTL: 600},
+			},
+		},
+		{
+			desc:    "already present",
+			records: []internal.DNSRecord{existing, {Type: "TXT", Name: "_acme-challenge.sub", Data: info.Value, TTL: 600}},
+		},
+		{
+			desc:          "get records error",
+			getErr:        errors.New("oops"),
+			expectedError: "godaddy: failed to get TXT records: oops",
+		},
+		{
+			desc:       "update error",
+			updateErr:  errors.New("oops"),
+			callUpdate: true,
+			expected: []internal.DNSRecord{
+				{Type: "TXT", Name: "_acme-challenge.sub", Data: info.Value, TTL: 600},
+			},
+			expectedError: "godaddy: failed to add TXT record: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("GetRecords", "example.com", "TXT", "_acme-challenge.sub").Return(test.records, test.getErr)
+
+			if test.callUpdate {
+				client.On("UpdateTxtRecords", test.expected, "example.com", "_acme-challenge.sub").Return(test.updateErr)
+			}
+
+			err := provider.Present("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_CleanUp_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	challenge := internal.DNSRecord{Type: "TXT", Name: "_acme-challenge.sub", Data: info.Value, TTL: 600}
+	other := internal.DNSRecord{Type: "TXT", Name: "other", Data: "other", TTL: 600}
+
+	testCases := []struct {
+		desc       string
+		records    []internal.DNSRecord
+		allRecords []internal.DNSRecord
+		callAll    bool
+		expected   []internal.DNSRecord
+	}{
+		{
+			desc: "no records",
+		},
+		{
+			desc:       "other records are kept",
+			records:    []internal.DNSRecord{challenge},
+			allRecords: []internal.DNSRecord{challenge, other},
+			callAll:    true,
+			expected:   []internal.DNSRecord{other},
+		},
+		{
+			desc:       "empty record when no records are kept",
+			records:    []internal.DNSRecord{challenge},
+			allRecords: []internal.DNSRecord{challenge},
+			callAll:    true,
+			expected:   []internal.DNSRecord{{Name: "empty", Data: ""}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("GetRecords", "example.com", "TXT", "_acme-challenge.sub").Return(test.records, nil)
+
+			if test.callAll {
+				client.On("GetRecords", "example.com", "TXT", "").Return(test.allRecords, nil)
+				client.On("UpdateTxtRecords", test.expected, "example.com", "").Return(nil)
+			}
+
+			err := provider.CleanUp("sub.example.com", "token", "key")
+			require.NoError(t, err)
+
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func newMockedProvider(t *testing.T, client dnsClient) *DNSProvider {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.APIKey = "key"
+	config.APISecret = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client = client
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider
+}
+
+type mockedClient struct {
+	mock.Mock
+}
+
+func (c *mockedClient) GetRecords(_ context.Context, domainZone, rType, recordName string) ([]internal.DNSRecord, error) {
+	args := c.Called(domainZone, rType, recordName)
+	return args.Get(0).([]internal.DNSRecord), args.Error(1)
+}
+
+func (c *mockedClient) UpdateTxtRecords(_ context.Context, records []internal.DNSRecord, domainZone, recordName string) error {
+	args := c.Called(records, domainZone, recordName)
+	return args.Error(0)
+}
+
+func TestDNSProvider_Present_mock_unknownDomain(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	client := &mockedClient{}
+
+	provider := newMockedProvider(t, client)
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.godaddy.com/v1/domains/example.com/records/TXT/_acme-challenge.sub", http.NoBody)
+
+	client.On("GetRecords", "example.com", "TXT", "_acme-challenge.sub").
+		Return([]internal.DNSRecord(nil), errutils.NewUnexpectedStatusCodeError(req, http.StatusNotFound, []byte(`{"code":"NOT_FOUND"}`)))
+
+	err := provider.Present("sub.example.com", "token", "key")
+	require.Error(t, err)
+
+	assert.Equal(t, challenge.ErrorCategoryZoneNotFound, challenge.GetErrorCategory(err))
+}
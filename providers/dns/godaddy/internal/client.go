@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
+	"github.com/pya789/lego/v4/providers/dns/internal/retryhttp"
 )
 
 // DefaultBaseURL represents the API endpoint to call.
@@ -96,6 +97,29 @@ func (c *Client) do(req *http.Request, result any) error {
 	return nil
 }
 
+// RetryAfter reads the delay of a rate limit from the body of the response (retryAfterSec),
+// or from the Retry-After header (retryhttp.RetryAfterFunc).
+// The body of the response can be read again.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{Reader: io.MultiReader(bytes.NewReader(raw), resp.Body), Closer: resp.Body}
+
+	if err != nil {
+		return retryhttp.RetryAfterHeader(resp)
+	}
+
+	var apiErr APIError
+	if json.Unmarshal(raw, &apiErr) == nil && apiErr.RetryAfterSec > 0 {
+		return time.Duration(apiErr.RetryAfterSec) * time.Second, true
+	}
+
+	return retryhttp.RetryAfterHeader(resp)
+}
+
 func newJSONRequest(ctx context.Context, method string, endpoint *url.URL, payload any) (*http.Request, error) {
 	buf := new(bytes.Buffer)
 
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -9,7 +10,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -141,3 +144,35 @@ func testHandler(method string, statusCode int, filename string) http.HandlerFun
 		}
 	}
 }
+
+func TestRetryAfter(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("fixtures", "error_rate_limit.json"))
+	require.NoError(t, err)
+
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(raw)),
+	}
+
+	delay, ok := RetryAfter(resp)
+	assert.True(t, ok)
+	assert.Equal(t, 12*time.Second, delay)
+
+	// the body can be read again.
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, raw, body)
+}
+
+func TestRetryAfter_header(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"3"}},
+		Body:       io.NopCloser(strings.NewReader(`{"code":"TOO_MANY_REQUESTS"}`)),
+	}
+
+	delay, ok := RetryAfter(resp)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+}
@@ -13,3 +13,11 @@ type DNSRecord struct {
 	Service  string `json:"service,omitempty"`
 	Weight   int    `json:"weight,omitempty"`
 }
+
+// APIError an error returned by the API.
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// RetryAfterSec the number of seconds before the next request (rate limits only).
+	RetryAfterSec int `json:"retryAfterSec,omitempty"`
+}
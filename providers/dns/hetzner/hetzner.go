@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/pya789/lego/v4/challenge/dns01"
@@ -21,15 +22,27 @@ const (
 
 	EnvAPIKey = envNamespace + "API_KEY"
 
+	EnvZoneID = envNamespace + "ZONE_ID"
+
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
 )
 
+type dnsClient interface {
+	GetZoneID(ctx context.Context, domain string) (string, error)
+	GetZone(ctx context.Context, zoneID string) (*internal.Zone, error)
+	GetTxtRecord(ctx context.Context, name, value, zoneID string) (*internal.DNSRecord, error)
+	CreateRecord(ctx context.Context, record internal.DNSRecord) error
+	DeleteRecord(ctx context.Context, recordID string) error
+	VerifyAPIKey(ctx context.Context) error
+}
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	APIKey             string
+	ZoneID             string
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	TTL                int
@@ -38,12 +51,17 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		ZoneID:             e.GetOrDefaultString(EnvZoneID, ""),
+		TTL:                e.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -51,18 +69,33 @@ func NewDefaultConfig() *Config {
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
-	client *internal.Client
+	client dnsClient
+
+	// zoneIDs caches the zone IDs by zone name: the lookups are shared by the challenges (Present and CleanUp).
+	zoneIDs   map[string]string
+	zoneIDsMu sync.Mutex
+	// configZoneLoaded the name of the configured zone ID has been loaded (in zoneIDs).
+	configZoneLoaded bool
+
+	// only for testing purpose.
+	findZoneByFqdn func(fqdn string) (string, error)
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for hetzner.
 // Credentials must be passed in the environment variable: HETZNER_API_KEY.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIKey)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIKey)
 	if err != nil {
 		return nil, fmt.Errorf("hetzner: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.APIKey = values[EnvAPIKey]
 
 	return NewDNSProviderConfig(config)
@@ -88,7 +121,12 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		client.HTTPClient = config.HTTPClient
 	}
 
-	return &DNSProvider{config: config, client: client}, nil
+	return &DNSProvider{
+		config:         config,
+		client:         client,
+		zoneIDs:        map[string]string{},
+		findZoneByFqdn: dns01.FindZoneByFqdn,
+	}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
@@ -101,7 +139,7 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("hetzner: could not find zone for domain %q: %w", domain, err)
 	}
@@ -110,7 +148,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 
 	ctx := context.Background()
 
-	zoneID, err := d.client.GetZoneID(ctx, zone)
+	zoneID, err := d.getZoneID(ctx, zone)
 	if err != nil {
 		return fmt.Errorf("hetzner: %w", err)
 	}
@@ -139,7 +177,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("hetzner: could not find zone for domain %q: %w", domain, err)
 	}
@@ -148,7 +186,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	ctx := context.Background()
 
-	zoneID, err := d.client.GetZoneID(ctx, zone)
+	zoneID, err := d.getZoneID(ctx, zone)
 	if err != nil {
 		return fmt.Errorf("hetzner: %w", err)
 	}
@@ -169,3 +207,44 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	return nil
 }
+
+// getZoneID returns the ID of the zone: the configured zone ID if it is the ID of the zone, or the zone ID found by the API (cached).
+// The name of the configured zone ID is loaded once, the other zones (ex: a certificate for several zones) are looked up by name.
+// The lock is held during the lookup: the concurrent challenges of a zone share a single lookup.
+func (d *DNSProvider) getZoneID(ctx context.Context, zone string) (string, error) {
+	d.zoneIDsMu.Lock()
+	defer d.zoneIDsMu.Unlock()
+
+	if d.config.ZoneID != "" && !d.configZoneLoaded {
+		configZone, err := d.client.GetZone(ctx, d.config.ZoneID)
+		if err != nil {
+			return "", fmt.Errorf("could not get the zone %s (%s): %w", d.config.ZoneID, EnvZoneID, err)
+		}
+
+		d.zoneIDs[dns01.UnFqdn(configZone.Name)] = configZone.ID
+		d.configZoneLoaded = true
+	}
+
+	if zoneID, ok := d.zoneIDs[zone]; ok {
+		return zoneID, nil
+	}
+
+	zoneID, err := d.client.GetZoneID(ctx, zone)
+	if err != nil {
+		return "", err
+	}
+
+	d.zoneIDs[zone] = zoneID
+
+	return zoneID, nil
+}
+
+// VerifyCredentials checks the API key, without changing the records.
+func (d *DNSProvider) VerifyCredentials(ctx context.Context) error {
+	err := d.client.VerifyAPIKey(ctx)
+	if err != nil {
+		return fmt.Errorf("hetzner: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/providers/dns/hetzner/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider_Present_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	testCases := []struct {
+		desc          string
+		zoneIDErr     error
+		createErr     error
+		callCreate    bool
+		expectedError string
+	}{
+		{
+			desc:       "success",
+			callCreate: true,
+		},
+		{
+			desc:          "zone ID error",
+			zoneIDErr:     errors.New("zone not found"),
+			expectedError: "hetzner: zone not found",
+		},
+		{
+			desc:          "create error",
+			createErr:     errors.New("oops"),
+			callCreate:    true,
+			expectedError: "hetzner: failed to add TXT record: fqdn=_acme-challenge.sub.example.com., zoneID=zoneA: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("GetZoneID", "example.com").Return("zoneA", test.zoneIDErr)
+
+			if test.callCreate {
+				client.On("CreateRecord", internal.DNSRecord{
+					Type:   "TXT",
+					Name:   "_acme-challenge.sub",
+					Value:  info.Value,
+					TTL:    provider.config.TTL,
+					ZoneID: "zoneA",
+				}).Return(test.createErr)
+			}
+
+			err := provider.Present("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_CleanUp_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	testCases := []struct {
+		desc          string
+		getErr        error
+		deleteErr     error
+		callDelete    bool
+		expectedError string
+	}{
+		{
+			desc:       "success",
+			callDelete: true,
+		},
+		{
+			desc:          "record not found",
+			getErr:        errors.New("record not found"),
+			expectedError: "hetzner: record not found",
+		},
+		{
+			desc:          "delete error",
+			deleteErr:     errors.New("oops"),
+			callDelete:    true,
+			expectedError: "hetzner: failed to delete TXT record: id=recordA, name=_acme-challenge.sub: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("GetZoneID", "example.com").Return("zoneA", nil)
+
+			var record *internal.DNSRecord
+			if test.getErr == nil {
+				record = &internal.DNSRecord{ID: "recordA", Name: "_acme-challenge.sub"}
+			}
+
+			client.On("GetTxtRecord", "_acme-challenge.sub", info.Value, "zoneA").Return(record, test.getErr)
+
+			if test.callDelete {
+				client.On("DeleteRecord", "recordA").Return(test.deleteErr)
+			}
+
+			err := provider.CleanUp("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_zoneIDCache_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	client := &mockedClient{}
+
+	provider := newMockedProvider(t, client)
+
+	// the zone ID is only looked up once for all the challenges of the zone.
+	client.On("GetZoneID", "example.com").Return("zoneA", nil).Once()
+	client.On("CreateRecord", mock.Anything).Return(nil).Twice()
+	client.On("GetTxtRecord", mock.Anything, mock.Anything, "zoneA").Return(&internal.DNSRecord{ID: "recordA"}, nil).Twice()
+	client.On("DeleteRecord", "recordA").Return(nil).Twice()
+
+	for _, domain := range []string{"a.example.com", "b.example.com"} {
+		require.NoError(t, provider.Present(domain, "token", "key"))
+		require.NoError(t, provider.CleanUp(domain, "token", "key"))
+	}
+
+	client.AssertExpectations(t)
+}
+
+func TestDNSProvider_zoneIDConfig_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	client := &mockedClient{}
+
+	provider := newMockedProvider(t, client)
+	provider.config.ZoneID = "zoneB"
+
+	// the name of the configured zone ID is loaded once, and the configured zone ID skips the lookup.
+	client.On("GetZone", "zoneB").Return(&internal.Zone{ID: "zoneB", Name: "example.com"}, nil).Once()
+	client.On("CreateRecord", mock.MatchedBy(func(record internal.DNSRecord) bool {
+		return record.ZoneID == "zoneB"
+	})).Return(nil).Twice()
+
+	require.NoError(t, provider.Present("a.example.com", "token", "key"))
+	require.NoError(t, provider.Present("b.example.com", "token", "key"))
+
+	client.AssertExpectations(t)
+	client.AssertNotCalled(t, "GetZoneID", mock.Anything)
+}
+
+func TestDNSProvider_zoneIDConfig_otherZone_mock(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	client := &mockedClient{}
+
+	provider := newMockedProvider(t, client)
+	provider.config.ZoneID = "zoneB"
+
+	// the configured zone ID is not the ID of the zone of the domain: the zone is looked up by name.
+	client.On("GetZone", "zoneB").Return(&internal.Zone{ID: "zoneB", Name: "example.org"}, nil).Once()
+	client.On("GetZoneID", "example.com").Return("zoneA", nil).Once()
+	client.On("CreateRecord", mock.MatchedBy(func(record internal.DNSRecord) bool {
+		return record.ZoneID == "zoneA"
+	})).Return(nil).Once()
+
+	require.NoError(t, provider.Present("sub.example.com", "token", "key"))
+
+	client.AssertExpectations(t)
+}
+
+func newMockedProvider(t *testing.T, client dnsClient) *DNSProvider {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client = client
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider
+}
+
+type mockedClient struct {
+	mock.Mock
+}
+
+func (c *mockedClient) GetZoneID(_ context.Context, domain string) (string, error) {
+	args := c.Called(domain)
+	return args.String(0), args.Error(1)
+}
+
+func (c *mockedClient) GetZone(_ context.Context, zoneID string) (*internal.Zone, error) {
+	args := c.Called(zoneID)
+	return args.Get(0).(*internal.Zone), args.Error(1)
+}
+
+func (c *mockedClient) GetTxtRecord(_ context.Context, name, value, zoneID string) (*internal.DNSRecord, error) {
+	args := c.Called(name, value, zoneID)
+	return args.Get(0).(*internal.DNSRecord), args.Error(1)
+}
+
+func (c *mockedClient) CreateRecord(_ context.Context, record internal.DNSRecord) error {
+	args := c.Called(record)
+	return args.Error(0)
+}
+
+func (c *mockedClient) DeleteRecord(_ context.Context, recordID string) error {
+	args := c.Called(recordID)
+	return args.Error(0)
+}
+
+func (c *mockedClient) VerifyAPIKey(_ context.Context) error {
+	args := c.Called()
+	return args.Error(0)
+}
@@ -10,7 +10,8 @@ import (
 const envDomain = envNamespace + "DOMAIN"
 
 var envTest = tester.NewEnvTest(
-	EnvAPIKey).
+	EnvAPIKey,
+	EnvZoneID).
 	WithDomain(envDomain)
 
 func TestNewDNSProvider(t *testing.T) {
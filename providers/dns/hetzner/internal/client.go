@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
 )
 
@@ -141,7 +142,7 @@ func (c *Client) DeleteRecord(ctx context.Context, recordID string) error {
 
 // GetZoneID gets the zone ID for a domain.
 func (c *Client) GetZoneID(ctx context.Context, domain string) (string, error) {
-	zones, err := c.getZones(ctx, domain)
+	zones, err := c.getZones(ctx, url.Values{"name": {domain}})
 	if err != nil {
 		return "", err
 	}
@@ -152,15 +153,53 @@ func (c *Client) GetZoneID(ctx context.Context, domain string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("could not get zone for domain %s not found", domain)
+	return "", challenge.NotFoundZone(fmt.Errorf("could not get zone for domain %s not found", domain))
+}
+
+// GetZone gets a zone by its ID.
+// https://dns.hetzner.com/api-docs#operation/GetZone
+func (c *Client) GetZone(ctx context.Context, zoneID string) (*Zone, error) {
+	endpoint := c.baseURL.JoinPath("api", "v1", "zones", zoneID)
+
+	req, err := c.newRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get zone: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errutils.NewHTTPDoError(req, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errutils.NewUnexpectedResponseStatusCodeError(req, resp)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errutils.NewReadResponseError(req, resp.StatusCode, err)
+	}
+
+	zone := &ZoneResponse{}
+	err = json.Unmarshal(raw, zone)
+	if err != nil {
+		return nil, errutils.NewUnmarshalError(req, resp.StatusCode, raw, err)
+	}
+
+	return &zone.Zone, nil
+}
+
+// VerifyAPIKey checks the API key, by listing the first zone.
+func (c *Client) VerifyAPIKey(ctx context.Context) error {
+	_, err := c.getZones(ctx, url.Values{"per_page": {"1"}})
+	return err
 }
 
 // https://dns.hetzner.com/api-docs#operation/GetZones
-func (c *Client) getZones(ctx context.Context, name string) (*Zones, error) {
+func (c *Client) getZones(ctx context.Context, query url.Values) (*Zones, error) {
 	endpoint := c.baseURL.JoinPath("api", "v1", "zones")
-
-	query := endpoint.Query()
-	query.Set("name", name)
 	endpoint.RawQuery = query.Encode()
 
 	req, err := c.newRequest(ctx, http.MethodGet, endpoint, nil)
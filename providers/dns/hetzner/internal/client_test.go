@@ -10,6 +10,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -174,3 +175,71 @@ func TestClient_GetZoneID(t *testing.T) {
 
 	assert.Equal(t, "zoneA", zoneID)
 }
+
+func TestClient_GetZone(t *testing.T) {
+	const apiKey = "myKeyE"
+
+	client, mux := setupTest(t, apiKey)
+
+	mux.HandleFunc("/api/v1/zones/zoneA", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		auth := req.Header.Get(authHeader)
+		if auth != apiKey {
+			http.Error(rw, fmt.Sprintf("invalid API key: %s", auth), http.StatusUnauthorized)
+			return
+		}
+
+		file, err := os.Open("./fixtures/get_zone.json")
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = file.Close() }()
+
+		_, err = io.Copy(rw, file)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	zone, err := client.GetZone(context.Background(), "zoneA")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Zone{ID: "zoneA", Name: "example.com"}, zone)
+}
+
+func TestClient_VerifyAPIKey(t *testing.T) {
+	const apiKey = "myKeyD"
+
+	client, mux := setupTest(t, apiKey)
+
+	mux.HandleFunc("/api/v1/zones", func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("per_page") != "1" {
+			http.Error(rw, fmt.Sprintf("unexpected query: %s", req.URL.RawQuery), http.StatusBadRequest)
+			return
+		}
+
+		auth := req.Header.Get(authHeader)
+		if auth != apiKey {
+			http.Error(rw, fmt.Sprintf("invalid API key: %s", auth), http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{"zones":[]}`))
+	})
+
+	err := client.VerifyAPIKey(context.Background())
+	require.NoError(t, err)
+
+	client.apiKey = "revoked"
+
+	err = client.VerifyAPIKey(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, challenge.ErrorCategoryAuth, challenge.GetErrorCategory(err))
+}
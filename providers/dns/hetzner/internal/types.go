@@ -22,6 +22,11 @@ type Zone struct {
 	Name string `json:"name"`
 }
 
+// ZoneResponse a DNS zone.
+type ZoneResponse struct {
+	Zone Zone `json:"zone"`
+}
+
 // Zones a set of DNS zones.
 type Zones struct {
 	Zones []Zone `json:"zones"`
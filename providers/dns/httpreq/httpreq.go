@@ -7,12 +7,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/platform/wait"
 	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
 )
 
@@ -25,6 +28,9 @@ const (
 	EnvUsername = envNamespace + "USERNAME"
 	EnvPassword = envNamespace + "PASSWORD"
 
+	EnvBulk           = envNamespace + "BULK"
+	EnvPropagationURL = envNamespace + "PROPAGATION_URL"
+
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
@@ -41,12 +47,30 @@ type messageRaw struct {
 	KeyAuth string `json:"keyAuth"`
 }
 
+type recordRaw struct {
+	Domain  string `json:"domain"`
+	Token   string `json:"token"`
+	KeyAuth string `json:"keyAuth"`
+	FQDN    string `json:"fqdn"`
+	Value   string `json:"value"`
+}
+
+type messageBulk struct {
+	Records []recordRaw `json:"records"`
+}
+
+type propagationStatus struct {
+	Propagated bool `json:"propagated"`
+}
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	Endpoint           *url.URL
 	Mode               string
 	Username           string
 	Password           string
+	Bulk               bool
+	PropagationURL     *url.URL
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	HTTPClient         *http.Client
@@ -54,11 +78,15 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -66,11 +94,23 @@ func NewDefaultConfig() *Config {
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
+
+	// changes staged by the transaction of the bulk mode (between Begin and Commit).
+	inTx      bool
+	presents  []recordRaw
+	cleanUps  []recordRaw
+	pendingMu sync.Mutex
 }
 
 // NewDNSProvider returns a DNSProvider instance.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvEndpoint)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("httpreq: %w", err)
 	}
@@ -80,11 +120,20 @@ func NewDNSProvider() (*DNSProvider, error) {
 		return nil, fmt.Errorf("httpreq: %w", err)
 	}
 
-	config := NewDefaultConfig()
-	config.Mode = env.GetOrFile(EnvMode)
-	config.Username = env.GetOrFile(EnvUsername)
-	config.Password = env.GetOrFile(EnvPassword)
+	config := newDefaultConfig(e)
+	config.Mode = e.GetOrFile(EnvMode)
+	config.Username = e.GetOrFile(EnvUsername)
+	config.Password = e.GetOrFile(EnvPassword)
 	config.Endpoint = endpoint
+	config.Bulk = e.GetOrDefaultBool(EnvBulk, false)
+
+	if rawURL := e.GetOrFile(EnvPropagationURL); rawURL != "" {
+		config.PropagationURL, err = url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("httpreq: %w", err)
+		}
+	}
+
 	return NewDNSProviderConfig(config)
 }
 
@@ -98,6 +147,10 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("httpreq: the endpoint is missing")
 	}
 
+	if config.Bulk && config.Mode != "RAW" {
+		return nil, errors.New("httpreq: the bulk mode requires the RAW mode")
+	}
+
 	return &DNSProvider{config: config}, nil
 }
 
@@ -111,6 +164,10 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	ctx := context.Background()
 
+	if d.config.Bulk {
+		return d.bulk(ctx, "/present", &d.presents, newRecordRaw(domain, token, keyAuth))
+	}
+
 	if d.config.Mode == "RAW" {
 		msg := &messageRaw{
 			Domain:  domain,
@@ -142,6 +199,10 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	ctx := context.Background()
 
+	if d.config.Bulk {
+		return d.bulk(ctx, "/cleanup", &d.cleanUps, newRecordRaw(domain, token, keyAuth))
+	}
+
 	if d.config.Mode == "RAW" {
 		msg := &messageRaw{
 			Domain:  domain,
@@ -169,15 +230,129 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	return nil
 }
 
+// Begin starts the transaction of the bulk mode:
+// the records are staged until Commit, and sent in one request.
+// Without the bulk mode, the records are sent one by one.
+func (d *DNSProvider) Begin() error {
+	d.pendingMu.Lock()
+	defer d.pendingMu.Unlock()
+
+	if d.config.Bulk {
+		d.inTx = true
+		d.presents = nil
+		d.cleanUps = nil
+	}
+
+	return nil
+}
+
+// Commit sends the staged records of the bulk mode: one request for the created records, and one for the removed records.
+func (d *DNSProvider) Commit() error {
+	d.pendingMu.Lock()
+	defer d.pendingMu.Unlock()
+
+	if !d.inTx {
+		return nil
+	}
+
+	presents, cleanUps := d.presents, d.cleanUps
+
+	d.inTx = false
+	d.presents = nil
+	d.cleanUps = nil
+
+	ctx := context.Background()
+
+	if len(presents) > 0 {
+		err := d.doPost(ctx, "/present", &messageBulk{Records: presents})
+		if err != nil {
+			return fmt.Errorf("httpreq: %w", err)
+		}
+	}
+
+	if len(cleanUps) > 0 {
+		err := d.doPost(ctx, "/cleanup", &messageBulk{Records: cleanUps})
+		if err != nil {
+			return fmt.Errorf("httpreq: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Rollback discards the staged records of the bulk mode.
+func (d *DNSProvider) Rollback() {
+	d.pendingMu.Lock()
+	defer d.pendingMu.Unlock()
+
+	d.inTx = false
+	d.presents = nil
+	d.cleanUps = nil
+}
+
+// WaitForPropagation polls the propagation status URL, if defined, until the record is propagated.
+// Without propagation status URL, the propagation is checked on the recursive nameservers.
+func (d *DNSProvider) WaitForPropagation(domain, token, keyAuth string) error {
+	return d.WaitForPropagationContext(context.Background(), domain, token, keyAuth)
+}
+
+// WaitForPropagationContext is like WaitForPropagation, the polling stops when the context is done.
+func (d *DNSProvider) WaitForPropagationContext(ctx context.Context, domain, token, keyAuth string) error {
+	if d.config.PropagationURL == nil {
+		return fmt.Errorf("httpreq: no propagation status URL: %w", errors.ErrUnsupported)
+	}
+
+	msg := &messageBulk{Records: []recordRaw{newRecordRaw(domain, token, keyAuth)}}
+
+	err := wait.ForContext(ctx, "httpreq propagation", d.config.PropagationTimeout, d.config.PollingInterval, func() (bool, error) {
+		var status propagationStatus
+
+		errD := d.do(ctx, d.config.PropagationURL, msg, &status)
+		if errD != nil {
+			return false, errD
+		}
+
+		return status.Propagated, nil
+	})
+	if err != nil {
+		return fmt.Errorf("httpreq: %w", err)
+	}
+
+	return nil
+}
+
+// bulk stages the record if a transaction is started, otherwise sends it immediately.
+func (d *DNSProvider) bulk(ctx context.Context, uri string, staged *[]recordRaw, record recordRaw) error {
+	d.pendingMu.Lock()
+
+	if d.inTx {
+		*staged = append(*staged, record)
+		d.pendingMu.Unlock()
+
+		return nil
+	}
+
+	d.pendingMu.Unlock()
+
+	err := d.doPost(ctx, uri, &messageBulk{Records: []recordRaw{record}})
+	if err != nil {
+		return fmt.Errorf("httpreq: %w", err)
+	}
+
+	return nil
+}
+
 func (d *DNSProvider) doPost(ctx context.Context, uri string, msg any) error {
+	return d.do(ctx, d.config.Endpoint.JoinPath(uri), msg, nil)
+}
+
+func (d *DNSProvider) do(ctx context.Context, endpoint *url.URL, msg, result any) error {
 	reqBody := new(bytes.Buffer)
 	err := json.NewEncoder(reqBody).Encode(msg)
 	if err != nil {
 		return fmt.Errorf("failed to create request JSON body: %w", err)
 	}
 
-	endpoint := d.config.Endpoint.JoinPath(uri)
-
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), reqBody)
 	if err != nil {
 		return fmt.Errorf("unable to create request: %w", err)
@@ -201,5 +376,31 @@ func (d *DNSProvider) doPost(ctx context.Context, uri string, msg any) error {
 		return errutils.NewUnexpectedResponseStatusCodeError(req, resp)
 	}
 
+	if result == nil {
+		return nil
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errutils.NewReadResponseError(req, resp.StatusCode, err)
+	}
+
+	err = json.Unmarshal(raw, result)
+	if err != nil {
+		return errutils.NewUnmarshalError(req, resp.StatusCode, raw, err)
+	}
+
 	return nil
 }
+
+func newRecordRaw(domain, token, keyAuth string) recordRaw {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	return recordRaw{
+		Domain:  domain,
+		Token:   token,
+		KeyAuth: keyAuth,
+		FQDN:    info.EffectiveFQDN,
+		Value:   info.Value,
+	}
+}
@@ -2,6 +2,7 @@ package httpreq
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,22 +69,38 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	testCases := []struct {
 		desc     string
 		endpoint *url.URL
+		mode     string
+		bulk     bool
 		expected string
 	}{
 		{
 			desc:     "success",
 			endpoint: mustParse("http://localhost:8090"),
 		},
+		{
+			desc:     "success bulk mode",
+			endpoint: mustParse("http://localhost:8090"),
+			mode:     "RAW",
+			bulk:     true,
+		},
 		{
 			desc:     "missing endpoint",
 			expected: "httpreq: the endpoint is missing",
 		},
+		{
+			desc:     "bulk mode without RAW mode",
+			endpoint: mustParse("http://localhost:8090"),
+			bulk:     true,
+			expected: "httpreq: the bulk mode requires the RAW mode",
+		},
 	}
 
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
 			config := NewDefaultConfig()
 			config.Endpoint = test.endpoint
+			config.Mode = test.mode
+			config.Bulk = test.bulk
 
 			p, err := NewDNSProviderConfig(config)
 
@@ -256,6 +274,112 @@ func TestNewDNSProvider_Cleanup(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_bulk(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	requests := map[string][]messageBulk{}
+
+	for _, uri := range []string{"/present", "/cleanup"} {
+		mux.HandleFunc(uri, func(rw http.ResponseWriter, req *http.Request) {
+			msg := messageBulk{}
+			err := json.NewDecoder(req.Body).Decode(&msg)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			requests[uri] = append(requests[uri], msg)
+		})
+	}
+
+	config := NewDefaultConfig()
+	config.Endpoint = mustParse(server.URL)
+	config.Mode = "RAW"
+	config.Bulk = true
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Begin())
+	require.NoError(t, p.Present("example.com", "tokenA", "keyA"))
+	require.NoError(t, p.Present("example.com", "tokenB", "keyB"))
+
+	assert.Empty(t, requests)
+
+	require.NoError(t, p.Commit())
+
+	require.Len(t, requests["/present"], 1)
+	require.Len(t, requests["/present"][0].Records, 2)
+
+	assert.Equal(t, "_acme-challenge.example.com.", requests["/present"][0].Records[0].FQDN)
+	assert.Equal(t, "tokenA", requests["/present"][0].Records[0].Token)
+	assert.Equal(t, "_acme-challenge.example.com.", requests["/present"][0].Records[1].FQDN)
+	assert.Equal(t, "tokenB", requests["/present"][0].Records[1].Token)
+
+	require.NoError(t, p.Begin())
+	require.NoError(t, p.CleanUp("example.com", "tokenA", "keyA"))
+	require.NoError(t, p.CleanUp("example.com", "tokenB", "keyB"))
+	require.NoError(t, p.Commit())
+
+	require.Len(t, requests["/cleanup"], 1)
+	require.Len(t, requests["/cleanup"][0].Records, 2)
+
+	// Without transaction, the records are sent immediately.
+	require.NoError(t, p.Present("example.org", "tokenC", "keyC"))
+
+	require.Len(t, requests["/present"], 2)
+	require.Len(t, requests["/present"][1].Records, 1)
+	assert.Equal(t, "tokenC", requests["/present"][1].Records[0].Token)
+
+	// The rolled back records are not sent.
+	require.NoError(t, p.Begin())
+	require.NoError(t, p.Present("example.net", "tokenD", "keyD"))
+	p.Rollback()
+	require.NoError(t, p.Commit())
+
+	assert.Len(t, requests["/present"], 2)
+}
+
+func TestDNSProvider_WaitForPropagation(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/status", func(rw http.ResponseWriter, req *http.Request) {
+		msg := messageBulk{}
+		err := json.NewDecoder(req.Body).Decode(&msg)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(propagationStatus{Propagated: len(msg.Records) == 1})
+	})
+
+	config := NewDefaultConfig()
+	config.Endpoint = mustParse(server.URL)
+	config.PropagationURL = mustParse(server.URL + "/status")
+	config.Mode = "RAW"
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	require.NoError(t, p.WaitForPropagation("example.com", "tokenA", "keyA"))
+}
+
+func TestDNSProvider_WaitForPropagation_unsupported(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Endpoint = mustParse("http://localhost:8090")
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = p.WaitForPropagation("example.com", "token", "key")
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+}
+
 func successHandler(rw http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
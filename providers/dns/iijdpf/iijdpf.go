@@ -41,11 +41,15 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		Endpoint:           env.GetOrDefaultString(EnvAPIEndpoint, dpfapi.DefaultEndpoint),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 660*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 5*time.Second),
-		TTL:                env.GetOrDefaultInt(EnvTTL, 300),
+		Endpoint:           e.GetOrDefaultString(EnvAPIEndpoint, dpfapi.DefaultEndpoint),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 660*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 5*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 300),
 	}
 }
 
@@ -59,12 +63,18 @@ type DNSProvider struct {
 
 // NewDNSProvider returns a DNSProvider instance configured for IIJ DNS.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIToken, EnvServiceCode)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIToken, EnvServiceCode)
 	if err != nil {
 		return nil, fmt.Errorf("iijdpf: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Token = values[EnvAPIToken]
 	config.ServiceCode = values[EnvServiceCode]
 
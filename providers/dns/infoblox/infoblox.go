@@ -64,16 +64,20 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		DNSView:     env.GetOrDefaultString(EnvDNSView, "External"),
-		WapiVersion: env.GetOrDefaultString(EnvWApiVersion, "2.11"),
-		Port:        env.GetOrDefaultString(EnvPort, "443"),
-		SSLVerify:   env.GetOrDefaultBool(EnvSSLVerify, true),
-
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
-		HTTPTimeout:        env.GetOrDefaultInt(EnvHTTPTimeout, 30),
+		DNSView:     e.GetOrDefaultString(EnvDNSView, "External"),
+		WapiVersion: e.GetOrDefaultString(EnvWApiVersion, "2.11"),
+		Port:        e.GetOrDefaultString(EnvPort, "443"),
+		SSLVerify:   e.GetOrDefaultBool(EnvSSLVerify, true),
+
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		HTTPTimeout:        e.GetOrDefaultInt(EnvHTTPTimeout, 30),
 	}
 }
 
@@ -94,12 +98,18 @@ type DNSProvider struct {
 // INFOBLOX_DNS_VIEW, INFOBLOX_WAPI_VERSION
 // INFOBLOX_SSL_VERIFY.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvHost, EnvUsername, EnvPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvHost, EnvUsername, EnvPassword)
 	if err != nil {
 		return nil, fmt.Errorf("infoblox: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Host = values[EnvHost]
 	config.Username = values[EnvUsername]
 	config.Password = values[EnvPassword]
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+
+	"github.com/pya789/lego/v4/challenge"
 )
 
 const legoDebugClientVerboseError = "LEGO_DEBUG_CLIENT_VERBOSE_ERROR"
@@ -40,6 +42,11 @@ func (h HTTPDoError) Unwrap() error {
 	return h.err
 }
 
+// ErrorCategory the failure of the communication is transient.
+func (h HTTPDoError) ErrorCategory() challenge.ErrorCategory {
+	return challenge.ErrorCategoryTransient
+}
+
 // ReadResponseError use with `io.ReadAll` when reading response body.
 type ReadResponseError struct {
 	req        *http.Request
@@ -131,3 +138,34 @@ func (u UnexpectedStatusCodeError) Error() string {
 
 	return msg + fmt.Sprintf(" [status code: %d] body: %s", u.StatusCode, string(u.Body))
 }
+
+// ErrorCategory returns the category matching the status code.
+func (u UnexpectedStatusCodeError) ErrorCategory() challenge.ErrorCategory {
+	return StatusCodeCategory(u.StatusCode)
+}
+
+// StatusCodeCategory returns the error category matching an HTTP status code.
+func StatusCodeCategory(statusCode int) challenge.ErrorCategory {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return challenge.ErrorCategoryAuth
+	case statusCode == http.StatusTooManyRequests:
+		return challenge.ErrorCategoryThrottled
+	case statusCode >= http.StatusInternalServerError || statusCode == http.StatusRequestTimeout:
+		return challenge.ErrorCategoryTransient
+	case statusCode >= http.StatusBadRequest:
+		return challenge.ErrorCategoryPermanent
+	default:
+		return ""
+	}
+}
+
+// CategorizeStatusCode wraps the error of an API response with the category matching the HTTP status code.
+func CategorizeStatusCode(statusCode int, err error) error {
+	category := StatusCodeCategory(statusCode)
+	if err == nil || category == "" {
+		return err
+	}
+
+	return &challenge.ProviderError{Category: category, Err: err}
+}
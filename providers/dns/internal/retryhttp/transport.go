@@ -0,0 +1,294 @@
+// Package retryhttp implements an HTTP transport retrying the requests rejected by a rate limit (429) or a server error (5xx),
+// with an exponential backoff honoring the delay requested by the server (Retry-After).
+package retryhttp
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pya789/lego/v4/log"
+)
+
+// Default values of the Transport.
+const (
+	DefaultMaxRetries = 4
+	DefaultMinWait    = time.Second
+	DefaultMaxWait    = 30 * time.Second
+)
+
+// RetryAfterFunc returns the delay requested by the server before the next attempt (ex: a rate limit reset header),
+// false if the response doesn't contain a delay.
+type RetryAfterFunc func(resp *http.Response) (time.Duration, bool)
+
+// Option configures a Transport.
+type Option func(t *Transport)
+
+// WithMaxRetries defines the maximum number of retries of a request (0 disables the retries).
+func WithMaxRetries(maxRetries int) Option {
+	return func(t *Transport) {
+		t.maxRetries = max(maxRetries, 0)
+	}
+}
+
+// WithBackoff defines the delay before the first retry, doubled after each retry up to maxWait.
+// A request is not retried if the server requests a delay longer than maxWait.
+func WithBackoff(minWait, maxWait time.Duration) Option {
+	return func(t *Transport) {
+		t.minWait = minWait
+		t.maxWait = max(minWait, maxWait)
+	}
+}
+
+// WithRetryAfter defines how the delay requested by the server is read (default: the Retry-After header).
+func WithRetryAfter(fn RetryAfterFunc) Option {
+	return func(t *Transport) {
+		t.retryAfter = fn
+	}
+}
+
+// WithIdempotentMethods defines the methods retried after a server error,
+// in addition to the idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE).
+// Ex: an API replacing all the records of a zone with a POST request.
+func WithIdempotentMethods(methods ...string) Option {
+	return func(t *Transport) {
+		t.idempotent = append(t.idempotent, methods...)
+	}
+}
+
+// Transport an http.RoundTripper retrying the requests.
+//
+// The rate limits (429) and the unavailability of the server (503) are retried whatever the method:
+// the request was not processed.
+// The other server errors (500, 502, 504) and the network errors are only retried for the idempotent methods.
+// The requests with a body are only retried if the body can be read again (http.Request.GetBody).
+//
+// The timeout of the http.Client includes all the attempts.
+type Transport struct {
+	base http.RoundTripper
+
+	maxRetries int
+	minWait    time.Duration
+	maxWait    time.Duration
+	retryAfter RetryAfterFunc
+	idempotent []string
+}
+
+// NewTransport creates a Transport retrying the requests sent with the base transport (http.DefaultTransport if nil).
+func NewTransport(base http.RoundTripper, opts ...Option) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t := &Transport{
+		base:       base,
+		maxRetries: DefaultMaxRetries,
+		minWait:    DefaultMinWait,
+		maxWait:    DefaultMaxWait,
+		retryAfter: RetryAfterHeader,
+		idempotent: []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// NewClient creates an HTTP client retrying the requests, the timeout includes all the attempts.
+func NewClient(timeout time.Duration, opts ...Option) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewTransport(nil, opts...),
+	}
+}
+
+// Wrap returns a copy of the HTTP client retrying the requests (http.DefaultClient if nil).
+// The client is returned as is if it already retries the requests.
+func Wrap(client *http.Client, opts ...Option) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if IsRetrying(client) {
+		return client
+	}
+
+	c := *client
+	c.Transport = NewTransport(client.Transport, opts...)
+
+	return &c
+}
+
+// IsRetrying returns true if the transport of the HTTP client is a Transport.
+func IsRetrying(client *http.Client) bool {
+	if client == nil {
+		return false
+	}
+
+	_, ok := client.Transport.(*Transport)
+
+	return ok
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attemptReq := req
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(attemptReq)
+
+		delay, retry := t.shouldRetry(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		// the query is not logged: it can contain credentials.
+		target := req.URL.Host + req.URL.Path
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+
+			log.Infof("retryhttp: %s %s: %s, retrying in %s (%d/%d)", req.Method, target, resp.Status, delay, attempt+1, t.maxRetries)
+		} else {
+			log.Infof("retryhttp: %s %s: %v, retrying in %s (%d/%d)", req.Method, target, err, delay, attempt+1, t.maxRetries)
+		}
+
+		err = sleep(req.Context(), delay)
+		if err != nil {
+			return nil, err
+		}
+
+		attemptReq, err = rewind(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry returns the delay before the next attempt, or false if the request must not be retried.
+func (t *Transport) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= t.maxRetries || !canRewind(req) || req.Context().Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return t.backoff(attempt), t.isIdempotent(req.Method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		delay, ok := t.retryAfter(resp)
+		if !ok {
+			return t.backoff(attempt), true
+		}
+
+		// the server requests a delay longer than the maximum wait: the error is returned.
+		return delay, delay <= t.maxWait
+
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return t.backoff(attempt), t.isIdempotent(req.Method)
+
+	default:
+		return 0, false
+	}
+}
+
+// backoff returns the exponential delay of the attempt, with a random jitter (between the half and the full delay).
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.maxWait
+	if attempt < 32 {
+		delay = min(t.minWait<<attempt, t.maxWait)
+	}
+
+	if delay <= 1 {
+		return delay
+	}
+
+	return delay/2 + rand.N(delay/2)
+}
+
+func (t *Transport) isIdempotent(method string) bool {
+	return slices.Contains(t.idempotent, method)
+}
+
+// RetryAfterHeader reads the Retry-After header (seconds or HTTP date).
+func RetryAfterHeader(resp *http.Response) (time.Duration, bool) {
+	return ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+}
+
+// ResetHeader returns a RetryAfterFunc reading a header containing the number of seconds before the reset of the rate limit
+// (ex: X-RateLimit-Reset), or the Retry-After header if the header is missing.
+func ResetHeader(name string) RetryAfterFunc {
+	return func(resp *http.Response) (time.Duration, bool) {
+		seconds, err := strconv.ParseInt(resp.Header.Get(name), 10, 64)
+		if err != nil || seconds < 0 {
+			return RetryAfterHeader(resp)
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+}
+
+// ParseRetryAfter parses the value of a Retry-After header: a number of seconds, or an HTTP date.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
+// canRewind returns true if the body of the request can be sent again.
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of the request with a new body.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = body
+
+	return r, nil
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
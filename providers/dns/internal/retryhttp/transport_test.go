@@ -0,0 +1,242 @@
+package retryhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		index := int(calls.Add(1)) - 1
+
+		body, _ := io.ReadAll(req.Body)
+
+		status := http.StatusOK
+		if index < len(statuses) {
+			status = statuses[index]
+		}
+
+		if status == http.StatusTooManyRequests {
+			rw.Header().Set("Retry-After", "0")
+		}
+
+		rw.WriteHeader(status)
+		_, _ = rw.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		method         string
+		statuses       []int
+		expectedStatus int
+		expectedCalls  int32
+	}{
+		{
+			desc:           "success",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+		{
+			desc:           "rate limited",
+			method:         http.MethodPost,
+			statuses:       []int{http.StatusTooManyRequests, http.StatusTooManyRequests},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  3,
+		},
+		{
+			desc:           "service unavailable",
+			method:         http.MethodPost,
+			statuses:       []int{http.StatusServiceUnavailable},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  2,
+		},
+		{
+			desc:           "server error, idempotent method",
+			method:         http.MethodPut,
+			statuses:       []int{http.StatusBadGateway, http.StatusInternalServerError},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  3,
+		},
+		{
+			desc:           "server error, non-idempotent method",
+			method:         http.MethodPost,
+			statuses:       []int{http.StatusBadGateway},
+			expectedStatus: http.StatusBadGateway,
+			expectedCalls:  1,
+		},
+		{
+			desc:           "client error",
+			method:         http.MethodGet,
+			statuses:       []int{http.StatusBadRequest},
+			expectedStatus: http.StatusBadRequest,
+			expectedCalls:  1,
+		},
+		{
+			desc:           "too many retries",
+			method:         http.MethodGet,
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCalls:  3,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server, calls := setupServer(t, test.statuses...)
+
+			client := NewClient(10*time.Second, WithMaxRetries(2), WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+			req, err := http.NewRequest(test.method, server.URL, strings.NewReader("content"))
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedCalls, calls.Load())
+
+			// the body is sent again at each attempt.
+			if resp.StatusCode == http.StatusOK {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, "content", string(body))
+			}
+		})
+	}
+}
+
+func TestTransport_RoundTrip_retryAfterTooLong(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		rw.Header().Set("Retry-After", "3600")
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(10*time.Second, WithBackoff(time.Millisecond, time.Minute))
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestTransport_RoundTrip_idempotentMethods(t *testing.T) {
+	server, calls := setupServer(t, http.StatusInternalServerError)
+
+	client := NewClient(10*time.Second, WithBackoff(time.Millisecond, 10*time.Millisecond), WithIdempotentMethods(http.MethodPost))
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("content"))
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestTransport_RoundTrip_canceled(t *testing.T) {
+	server, calls := setupServer(t, http.StatusServiceUnavailable)
+
+	client := NewClient(10*time.Second, WithBackoff(time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, http.NoBody)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestWrap(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	wrapped := Wrap(client)
+
+	assert.True(t, IsRetrying(wrapped))
+	assert.False(t, IsRetrying(client))
+	assert.Equal(t, 5*time.Second, wrapped.Timeout)
+
+	assert.Same(t, wrapped, Wrap(wrapped))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{desc: "empty", value: ""},
+		{desc: "seconds", value: "120", expected: 2 * time.Minute, ok: true},
+		{desc: "negative", value: "-1"},
+		{desc: "date", value: "Mon, 01 Jan 2024 12:00:30 GMT", expected: 30 * time.Second, ok: true},
+		{desc: "past date", value: "Mon, 01 Jan 2024 11:00:00 GMT", expected: 0, ok: true},
+		{desc: "invalid", value: "soon"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			delay, ok := ParseRetryAfter(test.value, now)
+
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, delay)
+		})
+	}
+}
+
+func TestResetHeader(t *testing.T) {
+	fn := ResetHeader("X-Ratelimit-Reset")
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("X-Ratelimit-Reset", "7")
+
+	delay, ok := fn(resp)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, delay)
+
+	resp.Header = http.Header{}
+	resp.Header.Set("Retry-After", "3")
+
+	delay, ok = fn(resp)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+}
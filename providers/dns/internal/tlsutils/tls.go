@@ -0,0 +1,75 @@
+package tlsutils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ParsePaths parses a list of file paths separated by os.PathListSeparator (ex: the value of an environment variable).
+func ParsePaths(value string) []string {
+	var paths []string
+
+	for _, path := range strings.Split(value, string(os.PathListSeparator)) {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// TrustCACertificates adds the PEM certificates of the files to the trusted roots of the HTTP client,
+// in addition to the system roots (ex: the internal CA of a self-hosted DNS server).
+// The trust store of the ACME endpoint (LEGO_CA_CERTIFICATES) is not used.
+// The transport of the client is cloned: the transports shared with other clients are not modified.
+func TrustCACertificates(client *http.Client, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	if client == nil {
+		return errors.New("the HTTP client is nil")
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read CA certificates: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(raw) {
+			return fmt.Errorf("no PEM certificate found in %q", path)
+		}
+	}
+
+	var transport *http.Transport
+
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return fmt.Errorf("unsupported HTTP transport: %T", client.Transport)
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+
+	transport.TLSClientConfig.RootCAs = pool
+
+	client.Transport = transport
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package tlsutils
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePaths(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected []string
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:     "one path",
+			value:    "/etc/ca.pem",
+			expected: []string{"/etc/ca.pem"},
+		},
+		{
+			desc:     "several paths",
+			value:    "/etc/a.pem" + string(os.PathListSeparator) + " /etc/b.pem" + string(os.PathListSeparator),
+			expected: []string{"/etc/a.pem", "/etc/b.pem"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, ParsePaths(test.value))
+		})
+	}
+}
+
+func TestTrustCACertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+
+	raw := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, raw, 0o600))
+
+	client := &http.Client{}
+
+	// the certificate of the server is not trusted by default.
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+
+	err = TrustCACertificates(client, []string{caFile})
+	require.NoError(t, err)
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// the default transport is not modified.
+	assert.NotSame(t, http.DefaultTransport, client.Transport)
+}
+
+func TestTrustCACertificates_invalidFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+	err := TrustCACertificates(&http.Client{}, []string{caFile})
+	require.EqualError(t, err, `no PEM certificate found in "`+caFile+`"`)
+}
@@ -40,12 +40,16 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL: env.GetOrDefaultInt(EnvTTL, 300),
+		TTL: e.GetOrDefaultInt(EnvTTL, 300),
 		// INWX has rather unstable propagation delays, thus using a larger default value
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 360*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
-		Sandbox:            env.GetOrDefaultBool(EnvSandbox, false),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 360*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		Sandbox:            e.GetOrDefaultBool(EnvSandbox, false),
 	}
 }
 
@@ -60,15 +64,21 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // INWX_USERNAME, INWX_PASSWORD, and INWX_SHARED_SECRET.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvUsername, EnvPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvUsername, EnvPassword)
 	if err != nil {
 		return nil, fmt.Errorf("inwx: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Username = values[EnvUsername]
 	config.Password = values[EnvPassword]
-	config.SharedSecret = env.GetOrFile(EnvSharedSecret)
+	config.SharedSecret = e.GetOrFile(EnvSharedSecret)
 
 	return NewDNSProviderConfig(config)
 }
@@ -3,7 +3,6 @@ package joker
 
 import (
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/pya789/lego/v4/challenge"
@@ -49,15 +48,19 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		APIMode:            env.GetOrDefaultString(EnvMode, modeDMAPI),
-		Debug:              env.GetOrDefaultBool(EnvDebug, false),
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
-		SequenceInterval:   env.GetOrDefaultSecond(EnvSequenceInterval, dns01.DefaultPropagationTimeout),
+		APIMode:            e.GetOrDefaultString(EnvMode, modeDMAPI),
+		Debug:              e.GetOrDefaultBool(EnvDebug, false),
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		SequenceInterval:   e.GetOrDefaultSecond(EnvSequenceInterval, dns01.DefaultPropagationTimeout),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 60*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 60*time.Second),
 		},
 	}
 }
@@ -65,11 +68,17 @@ func NewDefaultConfig() *Config {
 // NewDNSProvider returns a DNSProvider instance configured for Joker.
 // Credentials must be passed in the environment variable JOKER_API_KEY.
 func NewDNSProvider() (challenge.ProviderTimeout, error) {
-	if os.Getenv(EnvMode) == modeSVC {
-		return newSvcProvider()
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (challenge.ProviderTimeout, error) {
+	if e.GetOrFile(EnvMode) == modeSVC {
+		return newSvcProvider(e)
 	}
 
-	return newDmapiProvider()
+	return newDmapiProvider(e)
 }
 
 // NewDNSProviderConfig return a DNSProvider instance configured for Joker.
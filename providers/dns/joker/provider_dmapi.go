@@ -20,18 +20,18 @@ type dmapiProvider struct {
 
 // newDmapiProvider returns a DNSProvider instance configured for Joker.
 // Credentials must be passed in the environment variable: JOKER_USERNAME, JOKER_PASSWORD or JOKER_API_KEY.
-func newDmapiProvider() (*dmapiProvider, error) {
-	values, err := env.Get(EnvAPIKey)
+func newDmapiProvider(e env.Getter) (*dmapiProvider, error) {
+	values, err := e.Get(EnvAPIKey)
 	if err != nil {
 		var errU error
-		values, errU = env.Get(EnvUsername, EnvPassword)
+		values, errU = e.Get(EnvUsername, EnvPassword)
 		if errU != nil {
 			//nolint:errorlint // false-positive
 			return nil, fmt.Errorf("joker: %v or %v", errU, err)
 		}
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.APIKey = values[EnvAPIKey]
 	config.Username = values[EnvUsername]
 	config.Password = values[EnvPassword]
@@ -19,13 +19,13 @@ type svcProvider struct {
 
 // newSvcProvider returns a DNSProvider instance configured for Joker.
 // Credentials must be passed in the environment variable: JOKER_USERNAME, JOKER_PASSWORD.
-func newSvcProvider() (*svcProvider, error) {
-	values, err := env.Get(EnvUsername, EnvPassword)
+func newSvcProvider(e env.Getter) (*svcProvider, error) {
+	values, err := e.Get(EnvUsername, EnvPassword)
 	if err != nil {
 		return nil, fmt.Errorf("joker: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Username = values[EnvUsername]
 	config.Password = values[EnvPassword]
 
@@ -3,6 +3,7 @@ package joker
 import (
 	"testing"
 
+	"github.com/pya789/lego/v4/platform/config/env"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,7 +54,7 @@ func Test_newSvcProvider(t *testing.T) {
 
 			envTest.Apply(test.envVars)
 
-			p, err := newSvcProvider()
+			p, err := newSvcProvider(env.Getter{})
 
 			if test.expected != "" {
 				require.EqualError(t, err, test.expected)
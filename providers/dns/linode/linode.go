@@ -9,8 +9,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
 	"github.com/linode/linodego"
 	"golang.org/x/oauth2"
 )
@@ -44,11 +46,15 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 0),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 15*time.Second),
-		HTTPTimeout:        env.GetOrDefaultSecond(EnvHTTPTimeout, 0),
+		TTL:                e.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 0),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 15*time.Second),
+		HTTPTimeout:        e.GetOrDefaultSecond(EnvHTTPTimeout, 0),
 	}
 }
 
@@ -66,12 +72,18 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for Linode.
 // Credentials must be passed in the environment variable: LINODE_TOKEN.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvToken)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvToken)
 	if err != nil {
 		return nil, fmt.Errorf("linode: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Token = values[EnvToken]
 
 	return NewDNSProviderConfig(config)
@@ -141,7 +153,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	}
 
 	_, err = d.client.CreateDomainRecord(context.Background(), zone.domainID, createOpts)
-	return err
+	return categorizeError(err)
 }
 
 // CleanUp removes the TXT record matching the specified parameters.
@@ -157,7 +169,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	listOpts := linodego.NewListOptions(0, `{"type":"TXT"}`)
 	resources, err := d.client.ListDomainRecords(context.Background(), zone.domainID, listOpts)
 	if err != nil {
-		return err
+		return categorizeError(err)
 	}
 
 	// Remove the specified resource, if it exists.
@@ -165,7 +177,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		if (resource.Name == dns01.UnFqdn(info.EffectiveFQDN) || resource.Name == zone.resourceName) &&
 			resource.Target == info.Value {
 			if err := d.client.DeleteDomainRecord(context.Background(), zone.domainID, resource.ID); err != nil {
-				return err
+				return categorizeError(err)
 			}
 		}
 	}
@@ -189,11 +201,11 @@ func (d *DNSProvider) getHostedZoneInfo(fqdn string) (*hostedZoneInfo, error) {
 	listOpts := linodego.NewListOptions(0, string(filter))
 	domains, err := d.client.ListDomains(context.Background(), listOpts)
 	if err != nil {
-		return nil, err
+		return nil, categorizeError(err)
 	}
 
 	if len(domains) == 0 {
-		return nil, errors.New("domain not found")
+		return nil, challenge.NotFoundZone(errors.New("domain not found"))
 	}
 
 	subDomain, err := dns01.ExtractSubDomain(fqdn, authZone)
@@ -206,3 +218,13 @@ func (d *DNSProvider) getHostedZoneInfo(fqdn string) (*hostedZoneInfo, error) {
 		resourceName: subDomain,
 	}, nil
 }
+
+// categorizeError categorizes the errors of the Linode API with their HTTP status codes.
+func categorizeError(err error) error {
+	var apiErr *linodego.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	return errutils.CategorizeStatusCode(apiErr.Code, err)
+}
@@ -48,12 +48,16 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
 		BaseURL:            defaultBaseURL,
-		TTL:                env.GetOneWithFallback(EnvTTL, 300, strconv.Atoi, altEnvName(EnvTTL)),
-		PropagationTimeout: env.GetOneWithFallback(EnvPropagationTimeout, 2*time.Minute, env.ParseSecond, altEnvName(EnvPropagationTimeout)),
-		PollingInterval:    env.GetOneWithFallback(EnvPollingInterval, 2*time.Second, env.ParseSecond, altEnvName(EnvPollingInterval)),
-		HTTPTimeout:        env.GetOneWithFallback(EnvHTTPTimeout, 1*time.Minute, env.ParseSecond, altEnvName(EnvHTTPTimeout)),
+		TTL:                env.GetOneWithFallbackFrom(e, EnvTTL, 300, strconv.Atoi, altEnvName(EnvTTL)),
+		PropagationTimeout: env.GetOneWithFallbackFrom(e, EnvPropagationTimeout, 2*time.Minute, env.ParseSecond, altEnvName(EnvPropagationTimeout)),
+		PollingInterval:    env.GetOneWithFallbackFrom(e, EnvPollingInterval, 2*time.Second, env.ParseSecond, altEnvName(EnvPollingInterval)),
+		HTTPTimeout:        env.GetOneWithFallbackFrom(e, EnvHTTPTimeout, 1*time.Minute, env.ParseSecond, altEnvName(EnvHTTPTimeout)),
 	}
 }
 
@@ -67,7 +71,13 @@ type DNSProvider struct {
 
 // NewDNSProvider returns a DNSProvider instance configured for Liquid Web.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.GetWithFallback(
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.GetWithFallback(
 		[]string{EnvUsername, altEnvName(EnvUsername)},
 		[]string{EnvPassword, altEnvName(EnvPassword)},
 	)
@@ -75,11 +85,11 @@ func NewDNSProvider() (*DNSProvider, error) {
 		return nil, fmt.Errorf("liquidweb: %w", err)
 	}
 
-	config := NewDefaultConfig()
-	config.BaseURL = env.GetOneWithFallback(EnvURL, defaultBaseURL, env.ParseString, altEnvName(EnvURL))
+	config := newDefaultConfig(e)
+	config.BaseURL = env.GetOneWithFallbackFrom(e, EnvURL, defaultBaseURL, env.ParseString, altEnvName(EnvURL))
 	config.Username = values[EnvUsername]
 	config.Password = values[EnvPassword]
-	config.Zone = env.GetOneWithFallback(EnvZone, "", env.ParseString, altEnvName(EnvZone))
+	config.Zone = env.GetOneWithFallbackFrom(e, EnvZone, "", env.ParseString, altEnvName(EnvZone))
 
 	return NewDNSProviderConfig(config)
 }
@@ -35,9 +35,13 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 4*time.Second),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 4*time.Second),
 	}
 }
 
@@ -51,12 +55,18 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // MAILINABOX_EMAIL, MAILINABOX_PASSWORD, and MAILINABOX_BASE_URL.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvBaseURL, EnvEmail, EnvPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvBaseURL, EnvEmail, EnvPassword)
 	if err != nil {
 		return nil, fmt.Errorf("mailinabox: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.BaseURL = values[EnvBaseURL]
 	config.Email = values[EnvEmail]
 	config.Password = values[EnvPassword]
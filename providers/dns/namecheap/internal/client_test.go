@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -92,6 +93,8 @@ func TestClient_GetHosts_error(t *testing.T) {
 
 	_, err := client.GetHosts(context.Background(), "foo", "example.com")
 	require.ErrorAs(t, err, &apiError{})
+
+	assert.Equal(t, challenge.ErrorCategoryAuth, challenge.GetErrorCategory(err))
 }
 
 func TestClient_SetHosts(t *testing.T) {
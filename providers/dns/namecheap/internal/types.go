@@ -3,6 +3,8 @@ package internal
 import (
 	"encoding/xml"
 	"fmt"
+
+	"github.com/pya789/lego/v4/challenge"
 )
 
 // Record describes a DNS record returned by the Namecheap DNS gethosts API.
@@ -26,6 +28,21 @@ func (a apiError) Error() string {
 	return fmt.Sprintf("%s [%d]", a.Description, a.Number)
 }
 
+// ErrorCategory returns the category matching the error number.
+// https://www.namecheap.com/support/api/error-codes/
+func (a apiError) ErrorCategory() challenge.ErrorCategory {
+	switch a.Number {
+	case 1011102, // API Key is invalid or API access has not been enabled.
+		1011150: // RequestIP is invalid (the IP is not whitelisted).
+		return challenge.ErrorCategoryAuth
+	case 2016166, // Domain is not associated with your account.
+		2019166: // Domain not found.
+		return challenge.ErrorCategoryZoneNotFound
+	default:
+		return ""
+	}
+}
+
 type setHostsResponse struct {
 	XMLName xml.Name   `xml:"ApiResponse"`
 	Status  string     `xml:"Status,attr"`
@@ -13,6 +13,7 @@ import (
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/internal/retryhttp"
 	"github.com/pya789/lego/v4/providers/dns/namecheap/internal"
 	"golang.org/x/net/publicsuffix"
 )
@@ -102,20 +103,25 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	baseURL := internal.DefaultBaseURL
-	if env.GetOrDefaultBool(EnvSandbox, false) {
+	if e.GetOrDefaultBool(EnvSandbox, false) {
 		baseURL = internal.SandboxBaseURL
 	}
 
 	return &Config{
 		BaseURL:            baseURL,
-		Debug:              env.GetOrDefaultBool(EnvDebug, false),
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 60*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 15*time.Second),
-		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 60*time.Second),
-		},
+		Debug:              e.GetOrDefaultBool(EnvDebug, false),
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 60*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 15*time.Second),
+		// the rate limits (429) and the server errors are retried:
+		// the POST requests replace all the records of the domain (setHosts), they are also retried.
+		HTTPClient: retryhttp.NewClient(e.GetOrDefaultSecond(EnvHTTPTimeout, 60*time.Second),
+			retryhttp.WithIdempotentMethods(http.MethodPost)),
 	}
 }
 
@@ -129,12 +135,18 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // NAMECHEAP_API_USER and NAMECHEAP_API_KEY.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIUser, EnvAPIKey)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIUser, EnvAPIKey)
 	if err != nil {
 		return nil, fmt.Errorf("namecheap: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.APIUser = values[EnvAPIUser]
 	config.APIKey = values[EnvAPIKey]
 
@@ -33,6 +33,40 @@ func NewClient(hc *http.Client) *Client {
 	return &Client{baseURL: baseURL, httpClient: hc}
 }
 
+// GetDNSZone gets a DNS zone.
+func (c *Client) GetDNSZone(ctx context.Context, zoneID string) (*DNSZone, error) {
+	endpoint := c.baseURL.JoinPath("dns_zones", zoneID)
+
+	req, err := newJSONRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errutils.NewHTTPDoError(req, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errutils.NewUnexpectedResponseStatusCodeError(req, resp)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errutils.NewReadResponseError(req, resp.StatusCode, err)
+	}
+
+	var zone DNSZone
+	err = json.Unmarshal(raw, &zone)
+	if err != nil {
+		return nil, errutils.NewUnmarshalError(req, resp.StatusCode, raw, err)
+	}
+
+	return &zone, nil
+}
+
 // GetRecords gets a DNS records.
 func (c *Client) GetRecords(ctx context.Context, zoneID string) ([]DNSRecord, error) {
 	endpoint := c.baseURL.JoinPath("dns_zones", zoneID, "dns_records")
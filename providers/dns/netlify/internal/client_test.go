@@ -27,6 +27,49 @@ func setupTest(t *testing.T, token string) (*Client, *http.ServeMux) {
 	return client, mux
 }
 
+func TestClient_GetDNSZone(t *testing.T) {
+	client, mux := setupTest(t, "tokenA")
+
+	mux.HandleFunc("/dns_zones/example_org", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, "unsupported method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		auth := req.Header.Get("Authorization")
+		if auth != "Bearer tokenA" {
+			http.Error(rw, fmt.Sprintf("invali token: %s", auth), http.StatusUnauthorized)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		file, err := os.Open("./fixtures/get_dns_zone.json")
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = file.Close() }()
+
+		_, err = io.Copy(rw, file)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	zone, err := client.GetDNSZone(context.Background(), "example_org")
+	require.NoError(t, err)
+
+	expected := &DNSZone{
+		ID:         "u6b4336178f002e0a06bb0b6",
+		Name:       "example.org",
+		DNSServers: []string{"dns1.p01.nsone.net", "dns2.p01.nsone.net", "dns3.p01.nsone.net", "dns4.p01.nsone.net"},
+	}
+
+	assert.Equal(t, expected, zone)
+}
+
 func TestClient_GetRecords(t *testing.T) {
 	client, mux := setupTest(t, "tokenA")
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
 	"github.com/pya789/lego/v4/providers/dns/netlify/internal"
 )
 
@@ -21,6 +23,8 @@ const (
 
 	EnvToken = envNamespace + "TOKEN"
 
+	EnvSkipDNSCheck = envNamespace + "SKIP_DNS_CHECK"
+
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
@@ -34,16 +38,24 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// SkipDNSCheck disables the check of the nameservers of the zone (ex: a domain in the process of being delegated to Netlify DNS).
+	SkipDNSCheck bool
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 300),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 300),
+		SkipDNSCheck:       e.GetOrDefaultBool(EnvSkipDNSCheck, false),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -60,12 +72,18 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for Netlify.
 // Credentials must be passed in the environment variable: NETLIFY_TOKEN.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvToken)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvToken)
 	if err != nil {
 		return nil, fmt.Errorf("netlify: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Token = values[EnvToken]
 
 	return NewDNSProviderConfig(config)
@@ -107,6 +125,17 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 
 	authZone = dns01.UnFqdn(authZone)
 
+	ctx := context.Background()
+
+	zoneID := strings.ReplaceAll(authZone, ".", "_")
+
+	if !d.config.SkipDNSCheck {
+		err = d.checkNetlifyDNS(ctx, zoneID, authZone)
+		if err != nil {
+			return fmt.Errorf("netlify: %w", err)
+		}
+	}
+
 	record := internal.DNSRecord{
 		Hostname: dns01.UnFqdn(info.EffectiveFQDN),
 		TTL:      d.config.TTL,
@@ -114,7 +143,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		Value:    info.Value,
 	}
 
-	resp, err := d.client.CreateRecord(context.Background(), strings.ReplaceAll(authZone, ".", "_"), record)
+	resp, err := d.client.CreateRecord(ctx, zoneID, record)
 	if err != nil {
 		return fmt.Errorf("netlify: failed to create TXT records: fqdn=%s, authZone=%s: %w", info.EffectiveFQDN, authZone, err)
 	}
@@ -157,3 +186,51 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	return nil
 }
+
+// checkNetlifyDNS checks that the zone is served by Netlify DNS.
+// A domain only hosted by Netlify (with the DNS of another provider) can have a Netlify DNS zone:
+// the records created in this zone are never seen by the CA.
+func (d *DNSProvider) checkNetlifyDNS(ctx context.Context, zoneID, authZone string) error {
+	zone, err := d.client.GetDNSZone(ctx, zoneID)
+	if err != nil {
+		var statusErr *errutils.UnexpectedStatusCodeError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("the zone %s is not managed by Netlify DNS: add the domain to Netlify DNS, or use the DNS provider of the domain", authZone)
+		}
+
+		return fmt.Errorf("failed to get the DNS zone %s: %w", authZone, err)
+	}
+
+	nameservers, err := net.DefaultResolver.LookupNS(ctx, authZone)
+	if err != nil {
+		return fmt.Errorf("could not find the nameservers of the zone %s: %w", authZone, err)
+	}
+
+	var hosts []string
+	for _, ns := range nameservers {
+		hosts = append(hosts, ns.Host)
+	}
+
+	return checkDelegation(authZone, zone.DNSServers, hosts)
+}
+
+// checkDelegation checks that at least one of the nameservers of the zone is a Netlify DNS server.
+func checkDelegation(authZone string, dnsServers, nameservers []string) error {
+	if len(dnsServers) == 0 {
+		// the Netlify DNS servers of the zone are unknown.
+		return nil
+	}
+
+	for _, ns := range nameservers {
+		for _, server := range dnsServers {
+			if strings.EqualFold(dns01.UnFqdn(ns), dns01.UnFqdn(server)) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("the domain %s doesn't use Netlify DNS (nameservers: %s, Netlify DNS servers: %s):"+
+		" delegate the domain to the Netlify DNS servers, use the DNS provider of the domain,"+
+		" or disable this check with %s if the delegation is in progress",
+		authZone, strings.Join(nameservers, ", "), strings.Join(dnsServers, ", "), EnvSkipDNSCheck)
+}
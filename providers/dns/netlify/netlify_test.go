@@ -87,6 +87,47 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func Test_checkDelegation(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		dnsServers    []string
+		nameservers   []string
+		expectedError string
+	}{
+		{
+			desc:        "delegated",
+			dnsServers:  []string{"dns1.p01.nsone.net", "dns2.p01.nsone.net"},
+			nameservers: []string{"DNS2.p01.nsone.net.", "dns3.example.com."},
+		},
+		{
+			desc:        "unknown Netlify DNS servers",
+			nameservers: []string{"ns1.example.com."},
+		},
+		{
+			desc:        "not delegated",
+			dnsServers:  []string{"dns1.p01.nsone.net", "dns2.p01.nsone.net"},
+			nameservers: []string{"ns1.example.com.", "ns2.example.com."},
+			expectedError: "the domain example.com doesn't use Netlify DNS" +
+				" (nameservers: ns1.example.com., ns2.example.com., Netlify DNS servers: dns1.p01.nsone.net, dns2.p01.nsone.net):" +
+				" delegate the domain to the Netlify DNS servers, use the DNS provider of the domain," +
+				" or disable this check with NETLIFY_SKIP_DNS_CHECK if the delegation is in progress",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkDelegation("example.com", test.dnsServers, test.nameservers)
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
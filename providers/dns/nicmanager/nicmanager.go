@@ -50,12 +50,16 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 5*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 5*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 10*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 10*time.Second),
 		},
 	}
 }
@@ -74,19 +78,25 @@ type DNSProvider struct {
 // NICMANAGER_API_OTP
 // NICMANAGER_API_MODE.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvPassword)
 	if err != nil {
 		return nil, fmt.Errorf("nicmanager: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Password = values[EnvPassword]
 
-	config.Mode = env.GetOrDefaultString(EnvMode, internal.ModeAnycast)
-	config.Username = env.GetOrFile(EnvUsername)
-	config.Login = env.GetOrFile(EnvLogin)
-	config.Email = env.GetOrFile(EnvEmail)
-	config.OTPSecret = env.GetOrFile(EnvOTP)
+	config.Mode = e.GetOrDefaultString(EnvMode, internal.ModeAnycast)
+	config.Username = e.GetOrFile(EnvUsername)
+	config.Login = e.GetOrFile(EnvLogin)
+	config.Email = e.GetOrFile(EnvEmail)
+	config.OTPSecret = e.GetOrFile(EnvOTP)
 
 	if config.TTL < minTTL {
 		return nil, fmt.Errorf("TTL must be higher than %d: %d", minTTL, config.TTL)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
 	"github.com/ovh/go-ovh/ovh"
 )
 
@@ -80,12 +81,16 @@ func (c *Config) hasAppKeyAuth() bool {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, ovh.DefaultTimeout),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, ovh.DefaultTimeout),
 		},
 	}
 }
@@ -102,17 +107,23 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // OVH_ENDPOINT (must be either "ovh-eu" or "ovh-ca"), OVH_APPLICATION_KEY, OVH_APPLICATION_SECRET, OVH_CONSUMER_KEY.
 func NewDNSProvider() (*DNSProvider, error) {
-	config := NewDefaultConfig()
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	config := newDefaultConfig(e)
 
 	// https://github.com/ovh/go-ovh/blob/6817886d12a8c5650794b28da635af9fcdfd1162/ovh/configuration.go#L105
-	config.APIEndpoint = env.GetOrDefaultString(EnvEndpoint, "ovh-eu")
+	config.APIEndpoint = e.GetOrDefaultString(EnvEndpoint, "ovh-eu")
 
-	config.ApplicationKey = env.GetOrFile(EnvApplicationKey)
-	config.ApplicationSecret = env.GetOrFile(EnvApplicationSecret)
-	config.ConsumerKey = env.GetOrFile(EnvConsumerKey)
+	config.ApplicationKey = e.GetOrFile(EnvApplicationKey)
+	config.ApplicationSecret = e.GetOrFile(EnvApplicationSecret)
+	config.ConsumerKey = e.GetOrFile(EnvConsumerKey)
 
-	clientID := env.GetOrFile(EnvClientID)
-	clientSecret := env.GetOrFile(EnvClientSecret)
+	clientID := e.GetOrFile(EnvClientID)
+	clientSecret := e.GetOrFile(EnvClientSecret)
 
 	if clientID != "" || clientSecret != "" {
 		config.OAuth2Config = &OAuth2Config{
@@ -170,14 +181,14 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	var respData Record
 	err = d.client.Post(reqURL, reqData, &respData)
 	if err != nil {
-		return fmt.Errorf("ovh: error when call api to add record (%s): %w", reqURL, err)
+		return fmt.Errorf("ovh: error when call api to add record (%s): %w", reqURL, categorizeError(err))
 	}
 
 	// Apply the change
 	reqURL = fmt.Sprintf("/domain/zone/%s/refresh", authZone)
 	err = d.client.Post(reqURL, nil, nil)
 	if err != nil {
-		return fmt.Errorf("ovh: error when call api to refresh zone (%s): %w", reqURL, err)
+		return fmt.Errorf("ovh: error when call api to refresh zone (%s): %w", reqURL, categorizeError(err))
 	}
 
 	d.recordIDsMu.Lock()
@@ -210,14 +221,14 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	err = d.client.Delete(reqURL, nil)
 	if err != nil {
-		return fmt.Errorf("ovh: error when call OVH api to delete challenge record (%s): %w", reqURL, err)
+		return fmt.Errorf("ovh: error when call OVH api to delete challenge record (%s): %w", reqURL, categorizeError(err))
 	}
 
 	// Apply the change
 	reqURL = fmt.Sprintf("/domain/zone/%s/refresh", authZone)
 	err = d.client.Post(reqURL, nil, nil)
 	if err != nil {
-		return fmt.Errorf("ovh: error when call api to refresh zone (%s): %w", reqURL, err)
+		return fmt.Errorf("ovh: error when call api to refresh zone (%s): %w", reqURL, categorizeError(err))
 	}
 
 	// Delete record ID from map
@@ -255,3 +266,13 @@ func newClient(config *Config) (*ovh.Client, error) {
 
 	return client, nil
 }
+
+// categorizeError categorizes the errors of the OVH API with their HTTP status codes.
+func categorizeError(err error) error {
+	var apiErr *ovh.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	return errutils.CategorizeStatusCode(apiErr.Code, err)
+}
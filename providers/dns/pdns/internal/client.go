@@ -195,7 +195,7 @@ func (c *Client) do(req *http.Request) (json.RawMessage, error) {
 			return nil, errutils.NewUnmarshalError(req, resp.StatusCode, msg, err)
 		}
 		if errInfo.ShortMsg != "" {
-			return nil, fmt.Errorf("error talking to PDNS API: %w", errInfo)
+			return nil, errutils.CategorizeStatusCode(resp.StatusCode, fmt.Errorf("error talking to PDNS API: %w", errInfo))
 		}
 	}
 
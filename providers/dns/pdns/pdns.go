@@ -12,6 +12,7 @@ import (
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/internal/tlsutils"
 	"github.com/pya789/lego/v4/providers/dns/pdns/internal"
 )
 
@@ -28,6 +29,7 @@ const (
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
 	EnvServerName         = envNamespace + "SERVER_NAME"
+	EnvCACertificates     = envNamespace + "CA_CERTIFICATES"
 )
 
 // Config is used to configure the creation of the DNSProvider.
@@ -40,33 +42,58 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// CACertificates the paths of the PEM certificates of the CAs trusted for the API, in addition to the system roots
+	// (ex: a self-hosted server with an internal CA).
+	CACertificates []string
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		ServerName:         env.GetOrDefaultString(EnvServerName, "localhost"),
-		APIVersion:         env.GetOrDefaultInt(EnvAPIVersion, 0),
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		ServerName:         e.GetOrDefaultString(EnvServerName, "localhost"),
+		APIVersion:         e.GetOrDefaultInt(EnvAPIVersion, 0),
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
+		CACertificates: tlsutils.ParsePaths(e.GetOrDefaultString(EnvCACertificates, "")),
 	}
 }
 
+type dnsClient interface {
+	APIVersion() int
+	GetHostedZone(ctx context.Context, authZone string) (*internal.HostedZone, error)
+	UpdateRecords(ctx context.Context, zone *internal.HostedZone, sets internal.RRSets) error
+	Notify(ctx context.Context, zone *internal.HostedZone) error
+}
+
 // DNSProvider implements the challenge.Provider interface.
 type DNSProvider struct {
 	config *Config
-	client *internal.Client
+	client dnsClient
+
+	// only for testing purpose.
+	findZoneByFqdn func(fqdn string) (string, error)
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for pdns.
 // Credentials must be passed in the environment variable:
 // PDNS_API_URL and PDNS_API_KEY.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAPIKey, EnvAPIURL)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAPIKey, EnvAPIURL)
 	if err != nil {
 		return nil, fmt.Errorf("pdns: %w", err)
 	}
@@ -76,7 +103,7 @@ func NewDNSProvider() (*DNSProvider, error) {
 		return nil, fmt.Errorf("pdns: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Host = hostURL
 	config.APIKey = values[EnvAPIKey]
 
@@ -99,6 +126,15 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 
 	client := internal.NewClient(config.Host, config.ServerName, config.APIVersion, config.APIKey)
 
+	if config.HTTPClient != nil {
+		client.HTTPClient = config.HTTPClient
+	}
+
+	err := tlsutils.TrustCACertificates(client.HTTPClient, config.CACertificates)
+	if err != nil {
+		return nil, fmt.Errorf("pdns: %w", err)
+	}
+
 	if config.APIVersion <= 0 {
 		err := client.SetAPIVersion(context.Background())
 		if err != nil {
@@ -106,7 +142,11 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		}
 	}
 
-	return &DNSProvider{config: config, client: client}, nil
+	return &DNSProvider{
+		config:         config,
+		client:         client,
+		findZoneByFqdn: dns01.FindZoneByFqdn,
+	}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
@@ -119,7 +159,7 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("pdns: could not find zone for domain %q: %w", domain, err)
 	}
@@ -147,7 +187,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	}
 
 	rec := internal.Record{
-		Content:  "\"" + info.Value + "\"",
+		Content:  dns01.QuoteTXTValue(info.Value),
 		Disabled: false,
 
 		// pre-v1 API
@@ -181,7 +221,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
-	authZone, err := dns01.FindZoneByFqdn(info.EffectiveFQDN)
+	authZone, err := d.findZoneByFqdn(info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("pdns: could not find zone for domain %q: %w", domain, err)
 	}
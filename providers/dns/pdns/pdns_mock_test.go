@@ -0,0 +1,211 @@
+package pdns
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/providers/dns/pdns/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider_Present_mock(t *testing.T) {
+	info := dns01.GetChallengeInfo("sub.example.com", "key")
+
+	record := internal.Record{Content: `"other"`, Name: "_acme-challenge.sub.example.com.", Type: "TXT", TTL: 120}
+
+	testCases := []struct {
+		desc          string
+		apiVersion    int
+		zone          *internal.HostedZone
+		updateErr     error
+		expectedName  string
+		expected      []internal.Record
+		expectedError string
+	}{
+		{
+			desc:         "new record",
+			apiVersion:   1,
+			zone:         &internal.HostedZone{ID: "zoneA"},
+			expectedName: "_acme-challenge.sub.example.com.",
+			expected: []internal.Record{
+				{Content: `"` + info.Value + `"`, Name: "_acme-challenge.sub.example.com.", Type: "TXT", TTL: 120},
+			},
+		},
+		{
+			desc:       "existing records are kept",
+			apiVersion: 1,
+			zone: &internal.HostedZone{ID: "zoneA", RRSets: []internal.RRSet{
+				{Name: "_acme-challenge.sub.example.com.", Type: "TXT", Records: []internal.Record{record}},
+			}},
+			expectedName: "_acme-challenge.sub.example.com.",
+			expected: []internal.Record{
+				record,
+				{Content: `"` + info.Value + `"`, Name: "_acme-challenge.sub.example.com.", Type: "TXT", TTL: 120},
+			},
+		},
+		{
+			desc:         "pre-v1 API",
+			zone:         &internal.HostedZone{ID: "zoneA"},
+			expectedName: "_acme-challenge.sub.example.com",
+			expected: []internal.Record{
+				{Content: `"` + info.Value + `"`, Name: "_acme-challenge.sub.example.com", Type: "TXT", TTL: 120},
+			},
+		},
+		{
+			desc:          "update error",
+			apiVersion:    1,
+			zone:          &internal.HostedZone{ID: "zoneA"},
+			updateErr:     errors.New("oops"),
+			expectedName:  "_acme-challenge.sub.example.com.",
+			expected:      []internal.Record{{Content: `"` + info.Value + `"`, Name: "_acme-challenge.sub.example.com.", Type: "TXT", TTL: 120}},
+			expectedError: "pdns: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("GetHostedZone", "example.com.").Return(test.zone, nil)
+			client.On("APIVersion").Return(test.apiVersion)
+
+			client.On("UpdateRecords", test.zone, internal.RRSets{RRSets: []internal.RRSet{{
+				Name:       test.expectedName,
+				ChangeType: "REPLACE",
+				Type:       "TXT",
+				Kind:       "Master",
+				TTL:        120,
+				Records:    test.expected,
+			}}}).Return(test.updateErr)
+
+			if test.updateErr == nil {
+				client.On("Notify", test.zone).Return(nil)
+			}
+
+			err := provider.Present("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_CleanUp_mock(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		zone          *internal.HostedZone
+		callUpdate    bool
+		updateErr     error
+		expectedError string
+	}{
+		{
+			desc: "success",
+			zone: &internal.HostedZone{ID: "zoneA", RRSets: []internal.RRSet{
+				{Name: "_acme-challenge.sub.example.com.", Type: "TXT"},
+			}},
+			callUpdate: true,
+		},
+		{
+			desc:          "no existing record",
+			zone:          &internal.HostedZone{ID: "zoneA"},
+			expectedError: "pdns: no existing record found for _acme-challenge.sub.example.com.",
+		},
+		{
+			desc: "update error",
+			zone: &internal.HostedZone{ID: "zoneA", RRSets: []internal.RRSet{
+				{Name: "_acme-challenge.sub.example.com.", Type: "TXT"},
+			}},
+			callUpdate:    true,
+			updateErr:     errors.New("oops"),
+			expectedError: "pdns: oops",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := &mockedClient{}
+
+			provider := newMockedProvider(t, client)
+
+			client.On("GetHostedZone", "example.com.").Return(test.zone, nil)
+
+			if test.callUpdate {
+				client.On("UpdateRecords", test.zone, internal.RRSets{RRSets: []internal.RRSet{{
+					Name:       "_acme-challenge.sub.example.com.",
+					Type:       "TXT",
+					ChangeType: "DELETE",
+				}}}).Return(test.updateErr)
+			}
+
+			if test.callUpdate && test.updateErr == nil {
+				client.On("Notify", test.zone).Return(nil)
+			}
+
+			err := provider.CleanUp("sub.example.com", "token", "key")
+
+			client.AssertExpectations(t)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func newMockedProvider(t *testing.T, client dnsClient) *DNSProvider {
+	t.Helper()
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.Host = &url.URL{Scheme: "http", Host: "localhost:8081"}
+	// avoids the request of the API version.
+	config.APIVersion = 1
+	config.TTL = 120
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	provider.client = client
+	provider.findZoneByFqdn = func(_ string) (string, error) {
+		return "example.com.", nil
+	}
+
+	return provider
+}
+
+type mockedClient struct {
+	mock.Mock
+}
+
+func (c *mockedClient) APIVersion() int {
+	args := c.Called()
+	return args.Int(0)
+}
+
+func (c *mockedClient) GetHostedZone(_ context.Context, authZone string) (*internal.HostedZone, error) {
+	args := c.Called(authZone)
+	return args.Get(0).(*internal.HostedZone), args.Error(1)
+}
+
+func (c *mockedClient) UpdateRecords(_ context.Context, zone *internal.HostedZone, sets internal.RRSets) error {
+	args := c.Called(zone, sets)
+	return args.Error(0)
+}
+
+func (c *mockedClient) Notify(_ context.Context, zone *internal.HostedZone) error {
+	args := c.Called(zone)
+	return args.Error(0)
+}
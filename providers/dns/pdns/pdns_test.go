@@ -80,6 +80,7 @@ func TestNewDNSProviderConfig(t *testing.T) {
 		apiKey           string
 		customAPIVersion int
 		host             *url.URL
+		caCertificates   []string
 		expected         string
 	}{
 		{
@@ -108,6 +109,14 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			apiKey:   "123",
 			expected: "pdns: API URL missing",
 		},
+		{
+			desc:             "missing CA certificates file",
+			apiKey:           "123",
+			customAPIVersion: 1,
+			host:             mustParse("http://example.com"),
+			caCertificates:   []string{"/nonexistent/ca.pem"},
+			expected:         "pdns: read CA certificates: open /nonexistent/ca.pem: no such file or directory",
+		},
 	}
 
 	for _, test := range testCases {
@@ -116,6 +125,7 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			config.APIKey = test.apiKey
 			config.Host = test.host
 			config.APIVersion = test.customAPIVersion
+			config.CACertificates = test.caCertificates
 
 			p, err := NewDNSProviderConfig(config)
 
@@ -12,6 +12,7 @@ import (
 
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/providers/dns/internal/tlsutils"
 	"github.com/pya789/lego/v4/providers/dns/plesk/internal"
 )
 
@@ -27,6 +28,7 @@ const (
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+	EnvCACertificates     = envNamespace + "CA_CERTIFICATES"
 )
 
 // Config is used to configure the creation of the DNSProvider.
@@ -39,17 +41,26 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// CACertificates the paths of the PEM certificates of the CAs trusted for the API, in addition to the system roots
+	// (ex: a Plesk server with an internal CA).
+	CACertificates []string
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 300),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 300),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
+		CACertificates: tlsutils.ParsePaths(e.GetOrDefaultString(EnvCACertificates, "")),
 	}
 }
 
@@ -66,12 +77,18 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // PLESK_USERNAME and PLESK_PASSWORD.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvServerBaseURL, EnvUsername, EnvPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvServerBaseURL, EnvUsername, EnvPassword)
 	if err != nil {
 		return nil, fmt.Errorf("plesk: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.baseURL = values[EnvServerBaseURL]
 	config.Username = values[EnvUsername]
 	config.Password = values[EnvPassword]
@@ -104,6 +121,11 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		client.HTTPClient = config.HTTPClient
 	}
 
+	err = tlsutils.TrustCACertificates(client.HTTPClient, config.CACertificates)
+	if err != nil {
+		return nil, fmt.Errorf("plesk: %w", err)
+	}
+
 	return &DNSProvider{
 		config:    config,
 		client:    client,
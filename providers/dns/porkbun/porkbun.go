@@ -10,9 +10,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
+	"github.com/nrdcg/porkbun"
 	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/platform/config/env"
-	"github.com/nrdcg/porkbun"
+	"github.com/pya789/lego/v4/platform/wait"
 )
 
 // Environment variables names.
@@ -26,10 +29,19 @@ const (
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+	EnvMaxRetries         = envNamespace + "MAX_RETRIES"
 )
 
 const minTTL = 300
 
+// nameservers the authoritative nameservers of the zones hosted by Porkbun.
+var nameservers = []string{
+	"curitiba.ns.porkbun.com",
+	"fortaleza.ns.porkbun.com",
+	"maceio.ns.porkbun.com",
+	"salvador.ns.porkbun.com",
+}
+
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
 	APIKey             string
@@ -38,16 +50,24 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// MaxRetries the maximum number of retries of the API calls rejected because of the eventual consistency of the API.
+	MaxRetries int
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 10*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 10*time.Second),
-		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 10*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 10*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, minTTL),
+		MaxRetries:         e.GetOrDefaultInt(EnvMaxRetries, 5),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -59,18 +79,28 @@ type DNSProvider struct {
 
 	recordIDs   map[string]int
 	recordIDsMu sync.Mutex
+
+	// only for testing purpose.
+	nameservers []string
+	backOff     func() backoff.BackOff
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for Porkbun.
 // Credentials must be passed in the environment variables:
 // PORKBUN_SECRET_API_KEY, PORKBUN_PAPI_KEY.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvSecretAPIKey, EnvAPIKey)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvSecretAPIKey, EnvAPIKey)
 	if err != nil {
 		return nil, fmt.Errorf("porkbun: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.SecretAPIKey = values[EnvSecretAPIKey]
 	config.APIKey = values[EnvAPIKey]
 
@@ -98,9 +128,11 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 	}
 
 	return &DNSProvider{
-		config:    config,
-		client:    client,
-		recordIDs: make(map[string]int),
+		config:      config,
+		client:      client,
+		recordIDs:   make(map[string]int),
+		nameservers: nameservers,
+		backOff:     newBackOff,
 	}, nil
 }
 
@@ -128,7 +160,13 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 
 	ctx := context.Background()
 
-	recordID, err := d.client.CreateRecord(ctx, dns01.UnFqdn(zoneName), record)
+	var recordID int
+
+	err = d.retry(ctx, func() error {
+		var errC error
+		recordID, errC = d.client.CreateRecord(ctx, dns01.UnFqdn(zoneName), record)
+		return errC
+	})
 	if err != nil {
 		return fmt.Errorf("porkbun: failed to create record: %w", err)
 	}
@@ -159,14 +197,75 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	ctx := context.Background()
 
-	err = d.client.DeleteRecord(ctx, dns01.UnFqdn(zoneName), recordID)
+	err = d.retry(ctx, func() error {
+		return d.client.DeleteRecord(ctx, dns01.UnFqdn(zoneName), recordID)
+	})
 	if err != nil {
 		return fmt.Errorf("porkbun: failed to delete record: %w", err)
 	}
 
+	d.recordIDsMu.Lock()
+	delete(d.recordIDs, token)
+	d.recordIDsMu.Unlock()
+
 	return nil
 }
 
+// WaitForPropagation waits until the TXT record is served by the Porkbun authoritative nameservers.
+// It avoids waiting for the propagation through the public recursive nameservers.
+func (d *DNSProvider) WaitForPropagation(domain, token, keyAuth string) error {
+	return d.WaitForPropagationContext(context.Background(), domain, token, keyAuth)
+}
+
+// WaitForPropagationContext is like WaitForPropagation, the polling stops when the context is done.
+func (d *DNSProvider) WaitForPropagationContext(ctx context.Context, domain, _, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	return wait.ForContext(ctx, "porkbun propagation", d.config.PropagationTimeout, d.config.PollingInterval, func() (bool, error) {
+		return dns01.CheckAuthoritativeNameservers(info.EffectiveFQDN, info.Value, d.nameservers)
+	})
+}
+
+// ChecksAuthoritativeNameservers reports that WaitForPropagation queries the authoritative nameservers (DNS queries):
+// the waiter is not used when the propagation check on the authoritative nameservers is disabled.
+func (d *DNSProvider) ChecksAuthoritativeNameservers() bool {
+	return true
+}
+
+// retry calls the operation until it succeeds, or fails with an error not caused by the eventual consistency of the API.
+// The API can reject a call with a "400 Bad Request" shortly after a change of the zone (ex: the deletion of a record just created).
+func (d *DNSProvider) retry(ctx context.Context, operation func() error) error {
+	bo := backoff.WithContext(backoff.WithMaxRetries(d.backOff(), uint64(max(d.config.MaxRetries, 0))), ctx)
+
+	notify := func(err error, delay time.Duration) {
+		log.Infof("porkbun: retrying in %s: %v", delay.Round(time.Millisecond), err)
+	}
+
+	return backoff.RetryNotify(func() error {
+		err := operation()
+		if err != nil && !isEventualConsistencyError(err) {
+			return backoff.Permanent(err)
+		}
+
+		return err
+	}, bo, notify)
+}
+
+// isEventualConsistencyError checks if the error is a "400 Bad Request" error, returned by the API during the propagation of a change.
+func isEventualConsistencyError(err error) bool {
+	var serverErr *porkbun.ServerError
+
+	return errors.As(err, &serverErr) && serverErr.StatusCode == http.StatusBadRequest
+}
+
+func newBackOff() backoff.BackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = 2 * time.Second
+	bo.MaxInterval = 30 * time.Second
+
+	return bo
+}
+
 // splitDomain splits the hostname from the authoritative zone, and returns both parts.
 func splitDomain(fqdn string) (string, string, error) {
 	zone, err := dns01.FindZoneByFqdn(fqdn)
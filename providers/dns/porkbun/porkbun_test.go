@@ -1,9 +1,14 @@
 package porkbun
 
 import (
+	"context"
+	"net/http"
 	"testing"
 
+	"github.com/cenkalti/backoff/v4"
+	"github.com/nrdcg/porkbun"
 	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -118,6 +123,79 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_retry(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		errs           []error
+		expectedCalls  int
+		expectedStatus int
+	}{
+		{
+			desc:          "success",
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		{
+			desc: "eventual consistency",
+			errs: []error{
+				&porkbun.ServerError{StatusCode: http.StatusBadRequest, Message: "Invalid record ID."},
+				&porkbun.ServerError{StatusCode: http.StatusBadRequest, Message: "Invalid record ID."},
+				nil,
+			},
+			expectedCalls: 3,
+		},
+		{
+			desc:           "permanent error",
+			errs:           []error{&porkbun.ServerError{StatusCode: http.StatusForbidden, Message: "Invalid API key."}},
+			expectedCalls:  1,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc: "too many retries",
+			errs: []error{
+				&porkbun.ServerError{StatusCode: http.StatusBadRequest, Message: "Invalid record ID."},
+				&porkbun.ServerError{StatusCode: http.StatusBadRequest, Message: "Invalid record ID."},
+				&porkbun.ServerError{StatusCode: http.StatusBadRequest, Message: "Invalid record ID."},
+			},
+			expectedCalls:  3,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := NewDefaultConfig()
+			config.SecretAPIKey = "secret"
+			config.APIKey = "key"
+			config.MaxRetries = 2
+
+			p, err := NewDNSProviderConfig(config)
+			require.NoError(t, err)
+
+			p.backOff = func() backoff.BackOff { return &backoff.ZeroBackOff{} }
+
+			var calls int
+
+			err = p.retry(context.Background(), func() error {
+				calls++
+				return test.errs[calls-1]
+			})
+
+			if test.expectedStatus == 0 {
+				require.NoError(t, err)
+			} else {
+				var serverErr *porkbun.ServerError
+				require.ErrorAs(t, err, &serverErr)
+				assert.Equal(t, test.expectedStatus, serverErr.StatusCode)
+			}
+
+			assert.Equal(t, test.expectedCalls, calls)
+		})
+	}
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
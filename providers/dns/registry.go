@@ -0,0 +1,64 @@
+package dns
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pya789/lego/v4/challenge"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]func() (challenge.Provider, error){}
+)
+
+// RegisterProvider registers a DNS provider factory under a name,
+// to create the provider with NewDNSChallengeProviderByName (ex: private DNS backends).
+// The built-in providers take precedence over the registered providers with the same name.
+// It panics if the name is empty, if the factory is nil, or if the name is already registered.
+func RegisterProvider(name string, factory func() (challenge.Provider, error)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" {
+		panic("dns: RegisterProvider with an empty name")
+	}
+
+	if factory == nil {
+		panic("dns: RegisterProvider factory is nil for " + name)
+	}
+
+	if _, dup := registry[name]; dup {
+		panic("dns: RegisterProvider called twice for " + name)
+	}
+
+	registry[name] = factory
+}
+
+// RegisteredProviders returns the sorted names of the providers registered with RegisterProvider.
+func RegisteredProviders() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func newRegisteredProvider(name string) (challenge.Provider, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unrecognized DNS provider: %s", name)
+	}
+
+	return factory()
+}
@@ -11,10 +11,18 @@ import (
 	"time"
 
 	"github.com/pya789/lego/v4/providers/dns/internal/errutils"
+	"golang.org/x/time/rate"
 )
 
 const defaultBaseURL = "https://api.reg.ru/api/regru2/"
 
+// The API allows 1200 requests per hour (per account and per IP address):
+// the requests are spread to avoid the rejection of the bursts.
+const (
+	defaultRequestsPerMinute = 20
+	defaultBurst             = 5
+)
+
 // Client the reg.ru client.
 type Client struct {
 	username string
@@ -22,9 +30,12 @@ type Client struct {
 
 	baseURL    *url.URL
 	HTTPClient *http.Client
+
+	limiter *rate.Limiter
 }
 
 // NewClient Creates a reg.ru client.
+// The password can be empty when the client is authenticated by a TLS client certificate.
 func NewClient(username, password string) *Client {
 	baseURL, _ := url.Parse(defaultBaseURL)
 
@@ -33,6 +44,7 @@ func NewClient(username, password string) *Client {
 		password:   password,
 		baseURL:    baseURL,
 		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+		limiter:    rate.NewLimiter(rate.Every(time.Minute/defaultRequestsPerMinute), defaultBurst),
 	}
 }
 
@@ -78,7 +90,11 @@ func (c Client) doRequest(ctx context.Context, request any, fragments ...string)
 
 	query := endpoint.Query()
 	query.Set("username", c.username)
-	query.Set("password", c.password)
+
+	if c.password != "" {
+		query.Set("password", c.password)
+	}
+
 	endpoint.RawQuery = query.Encode()
 
 	inputData, err := json.Marshal(request)
@@ -97,6 +113,11 @@ func (c Client) doRequest(ctx context.Context, request any, fragments ...string)
 
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
+	err = c.limiter.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, errutils.NewHTTPDoError(req, err)
@@ -2,13 +2,17 @@ package internal
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -130,3 +134,79 @@ func TestAddTXTRecord_errors(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_AddTXTRecord_rateLimit(t *testing.T) {
+	var password string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		password = req.URL.Query().Get("password")
+
+		_, _ = fmt.Fprint(rw, `{"result":"error","error_code":"IP_EXCEEDED_ALLOWED_CONNECTION_RATE","error_text":"Your IP exceeded allowed connection rate"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	// authentication by a certificate: no password.
+	client := NewClient("user", "")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	err := client.AddTXTRecord(context.Background(), "example.com", "_acme-challenge", "txttxttxt")
+	require.Error(t, err)
+
+	assert.True(t, IsRateLimitError(err))
+	assert.Empty(t, password)
+}
+
+func TestClient_limiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, `{"result":"success","answer":{"domains":[{"dname":"example.com","result":"success"}]}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("user", "secret")
+	client.baseURL, _ = url.Parse(server.URL)
+	client.limiter = rate.NewLimiter(rate.Every(time.Minute), 1)
+
+	require.NoError(t, client.AddTXTRecord(context.Background(), "example.com", "_acme-challenge", "txttxttxt"))
+
+	// the next request is queued until the end of the context.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	t.Cleanup(cancel)
+
+	err := client.AddTXTRecord(ctx, "example.com", "_acme-challenge", "txttxttxt")
+	require.Error(t, err)
+}
+
+func TestIsRateLimitError(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc:     "account rate limit",
+			err:      fmt.Errorf("wrapped: %w", APIResponse{Result: "error", ErrorCode: "ACCOUNT_EXCEEDED_ALLOWED_CONNECTION_RATE"}),
+			expected: true,
+		},
+		{
+			desc:     "domain rate limit",
+			err:      DomainResponse{Result: "error", ErrorCode: "IP_EXCEEDED_ALLOWED_CONNECTION_RATE"},
+			expected: true,
+		},
+		{
+			desc: "other error",
+			err:  APIResponse{Result: "error", ErrorCode: "NO_AUTH"},
+		},
+		{
+			desc: "no API error",
+			err:  context.DeadlineExceeded,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, IsRateLimitError(test.err))
+		})
+	}
+}
@@ -1,9 +1,19 @@
 package internal
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
 
 const successResult = "success"
 
+// The error codes of the rejection of a request because of the rate limits.
+var rateLimitErrorCodes = []string{
+	"IP_EXCEEDED_ALLOWED_CONNECTION_RATE",
+	"ACCOUNT_EXCEEDED_ALLOWED_CONNECTION_RATE",
+}
+
 // APIResponse is the representation of an API response.
 type APIResponse struct {
 	Result string `json:"result"`
@@ -35,6 +45,21 @@ func (a APIResponse) HasError() error {
 	return nil
 }
 
+// IsRateLimitError checks if the error is the rejection of a request because of the rate limits of the API.
+func IsRateLimitError(err error) bool {
+	var apiErr APIResponse
+	if errors.As(err, &apiErr) {
+		return slices.Contains(rateLimitErrorCodes, apiErr.ErrorCode)
+	}
+
+	var domainErr DomainResponse
+	if errors.As(err, &domainErr) {
+		return slices.Contains(rateLimitErrorCodes, domainErr.ErrorCode)
+	}
+
+	return false
+}
+
 // Answer is the representation of an API response answer.
 type Answer struct {
 	Domains []DomainResponse `json:"domains,omitempty"`
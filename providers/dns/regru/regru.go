@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/platform/config/env"
 	"github.com/pya789/lego/v4/providers/dns/regru/internal"
 )
@@ -27,6 +29,7 @@ const (
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
 	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+	EnvMaxRetries         = envNamespace + "MAX_RETRIES"
 )
 
 // Config is used to configure the creation of the DNSProvider.
@@ -40,16 +43,24 @@ type Config struct {
 	PollingInterval    time.Duration
 	TTL                int
 	HTTPClient         *http.Client
+
+	// MaxRetries the maximum number of retries of the API calls rejected because of the rate limits.
+	MaxRetries int
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 300),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 300),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		MaxRetries:         e.GetOrDefaultInt(EnvMaxRetries, 5),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -58,22 +69,40 @@ func NewDefaultConfig() *Config {
 type DNSProvider struct {
 	config *Config
 	client *internal.Client
+
+	// only for testing purpose.
+	backOff func() backoff.BackOff
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for reg.ru.
 // Credentials must be passed in the environment variables:
-// REGRU_USERNAME and REGRU_PASSWORD.
+// REGRU_USERNAME and REGRU_PASSWORD,
+// or REGRU_USERNAME, REGRU_TLS_CERT, and REGRU_TLS_KEY (authentication by a TLS client certificate).
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvUsername, EnvPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	tlsCert := e.GetOrDefaultString(EnvTLSCert, "")
+
+	names := []string{EnvUsername, EnvPassword}
+	if tlsCert != "" {
+		// the password is optional with the authentication by a certificate.
+		names = []string{EnvUsername}
+	}
+
+	values, err := e.Get(names...)
 	if err != nil {
 		return nil, fmt.Errorf("regru: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Username = values[EnvUsername]
-	config.Password = values[EnvPassword]
-	config.TLSCert = env.GetOrDefaultString(EnvTLSCert, "")
-	config.TLSKey = env.GetOrDefaultString(EnvTLSKey, "")
+	config.Password = e.GetOrDefaultString(EnvPassword, "")
+	config.TLSCert = tlsCert
+	config.TLSKey = e.GetOrDefaultString(EnvTLSKey, "")
 
 	return NewDNSProviderConfig(config)
 }
@@ -84,7 +113,7 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("regru: the configuration of the DNS provider is nil")
 	}
 
-	if config.Username == "" || config.Password == "" {
+	if config.Username == "" || (config.Password == "" && config.TLSCert == "") {
 		return nil, errors.New("regru: incomplete credentials, missing username and/or password")
 	}
 
@@ -108,14 +137,16 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 			return nil, fmt.Errorf("regru: %w", err)
 		}
 
-		client.HTTPClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				Certificates: []tls.Certificate{tlsCert},
-			},
+		// keeps the proxy and the timeouts of the default transport.
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{
+			Certificates: []tls.Certificate{tlsCert},
 		}
+
+		client.HTTPClient.Transport = transport
 	}
 
-	return &DNSProvider{config: config, client: client}, nil
+	return &DNSProvider{config: config, client: client, backOff: newBackOff}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
@@ -138,7 +169,11 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("regru: %w", err)
 	}
 
-	err = d.client.AddTXTRecord(context.Background(), dns01.UnFqdn(authZone), subDomain, info.Value)
+	ctx := context.Background()
+
+	err = d.retry(ctx, func() error {
+		return d.client.AddTXTRecord(ctx, dns01.UnFqdn(authZone), subDomain, info.Value)
+	})
 	if err != nil {
 		return fmt.Errorf("regru: failed to create TXT records [domain: %s, sub domain: %s]: %w",
 			dns01.UnFqdn(authZone), subDomain, err)
@@ -161,7 +196,11 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		return fmt.Errorf("regru: %w", err)
 	}
 
-	err = d.client.RemoveTxtRecord(context.Background(), dns01.UnFqdn(authZone), subDomain, info.Value)
+	ctx := context.Background()
+
+	err = d.retry(ctx, func() error {
+		return d.client.RemoveTxtRecord(ctx, dns01.UnFqdn(authZone), subDomain, info.Value)
+	})
 	if err != nil {
 		return fmt.Errorf("regru: failed to remove TXT records [domain: %s, sub domain: %s]: %w",
 			dns01.UnFqdn(authZone), subDomain, err)
@@ -169,3 +208,30 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	return nil
 }
+
+// retry calls the operation until it succeeds, or fails with an error not caused by the rate limits of the API.
+// The requests of an order with many domains can exceed the rate limits, even spread by the client.
+func (d *DNSProvider) retry(ctx context.Context, operation func() error) error {
+	bo := backoff.WithContext(backoff.WithMaxRetries(d.backOff(), uint64(max(d.config.MaxRetries, 0))), ctx)
+
+	notify := func(err error, delay time.Duration) {
+		log.Infof("regru: rate limit exceeded, retrying in %s: %v", delay.Round(time.Millisecond), err)
+	}
+
+	return backoff.RetryNotify(func() error {
+		err := operation()
+		if err != nil && !internal.IsRateLimitError(err) {
+			return backoff.Permanent(err)
+		}
+
+		return err
+	}, bo, notify)
+}
+
+func newBackOff() backoff.BackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = 10 * time.Second
+	bo.MaxInterval = time.Minute
+
+	return bo
+}
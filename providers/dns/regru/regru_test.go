@@ -1,6 +1,13 @@
 package regru
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"testing"
 	"time"
 
@@ -12,10 +19,14 @@ const envDomain = envNamespace + "DOMAIN"
 
 var envTest = tester.NewEnvTest(
 	EnvUsername,
-	EnvPassword).
+	EnvPassword,
+	EnvTLSCert,
+	EnvTLSKey).
 	WithDomain(envDomain)
 
 func TestNewDNSProvider(t *testing.T) {
+	tlsCert, tlsKey := generateCertificate(t)
+
 	testCases := []struct {
 		desc     string
 		envVars  map[string]string
@@ -52,6 +63,22 @@ func TestNewDNSProvider(t *testing.T) {
 			},
 			expected: "regru: some credentials information are missing: REGRU_PASSWORD",
 		},
+		{
+			desc: "success with certificate",
+			envVars: map[string]string{
+				EnvUsername: "api_username",
+				EnvTLSCert:  tlsCert,
+				EnvTLSKey:   tlsKey,
+			},
+		},
+		{
+			desc: "certificate without key",
+			envVars: map[string]string{
+				EnvUsername: "api_username",
+				EnvTLSCert:  tlsCert,
+			},
+			expected: "regru: TLS key is missing",
+		},
 	}
 
 	for _, test := range testCases {
@@ -75,10 +102,14 @@ func TestNewDNSProvider(t *testing.T) {
 }
 
 func TestNewDNSProviderConfig(t *testing.T) {
+	tlsCert, tlsKey := generateCertificate(t)
+
 	testCases := []struct {
 		desc     string
 		username string
 		password string
+		tlsCert  string
+		tlsKey   string
 		expected string
 	}{
 		{
@@ -102,6 +133,25 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			password: "",
 			expected: "regru: incomplete credentials, missing username and/or password",
 		},
+		{
+			desc:     "success with certificate",
+			username: "api_username",
+			tlsCert:  tlsCert,
+			tlsKey:   tlsKey,
+		},
+		{
+			desc:     "certificate without username",
+			tlsCert:  tlsCert,
+			tlsKey:   tlsKey,
+			expected: "regru: incomplete credentials, missing username and/or password",
+		},
+		{
+			desc:     "invalid certificate",
+			username: "api_username",
+			tlsCert:  tlsCert,
+			tlsKey:   "invalid",
+			expected: "regru: tls: failed to find any PEM data in key input",
+		},
 	}
 
 	for _, test := range testCases {
@@ -109,6 +159,8 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			config := NewDefaultConfig()
 			config.Username = test.username
 			config.Password = test.password
+			config.TLSCert = test.tlsCert
+			config.TLSKey = test.tlsKey
 
 			p, err := NewDNSProviderConfig(config)
 
@@ -123,6 +175,32 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func generateCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "api_username"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return string(certPEM), string(keyPEM)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
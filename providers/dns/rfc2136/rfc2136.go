@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
 	"github.com/miekg/dns"
@@ -44,13 +45,17 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TSIGAlgorithm:      env.GetOrDefaultString(EnvTSIGAlgorithm, dns.HmacSHA1),
-		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, env.GetOrDefaultSecond("RFC2136_TIMEOUT", 60*time.Second)),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
-		SequenceInterval:   env.GetOrDefaultSecond(EnvSequenceInterval, dns01.DefaultPropagationTimeout),
-		DNSTimeout:         env.GetOrDefaultSecond(EnvDNSTimeout, 10*time.Second),
+		TSIGAlgorithm:      e.GetOrDefaultString(EnvTSIGAlgorithm, dns.HmacSHA1),
+		TTL:                e.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, e.GetOrDefaultSecond("RFC2136_TIMEOUT", 60*time.Second)),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		SequenceInterval:   e.GetOrDefaultSecond(EnvSequenceInterval, dns01.DefaultPropagationTimeout),
+		DNSTimeout:         e.GetOrDefaultSecond(EnvDNSTimeout, 10*time.Second),
 	}
 }
 
@@ -69,15 +74,21 @@ type DNSProvider struct {
 // RFC2136_PROPAGATION_TIMEOUT: DNS propagation timeout in time.ParseDuration format. (60s)
 // To disable TSIG authentication, leave the RFC2136_TSIG* variables unset.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvNameserver)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvNameserver)
 	if err != nil {
 		return nil, fmt.Errorf("rfc2136: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Nameserver = values[EnvNameserver]
-	config.TSIGKey = env.GetOrFile(EnvTSIGKey)
-	config.TSIGSecret = env.GetOrFile(EnvTSIGSecret)
+	config.TSIGKey = e.GetOrFile(EnvTSIGKey)
+	config.TSIGSecret = e.GetOrFile(EnvTSIGSecret)
 
 	return NewDNSProviderConfig(config)
 }
@@ -136,6 +147,11 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	return nil
 }
 
+// IdempotentPresent reports that Present replaces the record set: it can be called again safely.
+func (d *DNSProvider) IdempotentPresent() bool {
+	return true
+}
+
 // CleanUp removes the TXT record matching the specified parameters.
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
@@ -157,7 +173,7 @@ func (d *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
 	// Create RR
 	rr := new(dns.TXT)
 	rr.Hdr = dns.RR_Header{Name: fqdn, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: uint32(ttl)}
-	rr.Txt = []string{value}
+	rr.Txt = dns01.SplitTXTValue(value)
 	rrs := []dns.RR{rr}
 
 	// Create dynamic update packet
@@ -191,11 +207,29 @@ func (d *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
 	// Send the query
 	reply, _, err := c.Exchange(m, d.config.Nameserver)
 	if err != nil {
-		return fmt.Errorf("DNS update failed: %w", err)
+		if errors.Is(err, dns.ErrSig) || errors.Is(err, dns.ErrSecret) {
+			return challenge.AuthError(fmt.Errorf("DNS update failed: %w", err))
+		}
+
+		return challenge.Transient(fmt.Errorf("DNS update failed: %w", err))
 	}
 	if reply != nil && reply.Rcode != dns.RcodeSuccess {
-		return fmt.Errorf("DNS update failed: server replied: %s", dns.RcodeToString[reply.Rcode])
+		return categorizeRcode(reply.Rcode, fmt.Errorf("DNS update failed: server replied: %s", dns.RcodeToString[reply.Rcode]))
 	}
 
 	return nil
 }
+
+// categorizeRcode categorizes the failure of a dynamic update with the response code of the server.
+func categorizeRcode(rcode int, err error) error {
+	switch rcode {
+	case dns.RcodeNotAuth, dns.RcodeRefused, dns.RcodeBadSig, dns.RcodeBadKey, dns.RcodeBadTime:
+		return challenge.AuthError(err)
+	case dns.RcodeServerFailure:
+		return challenge.Transient(err)
+	case dns.RcodeNotZone, dns.RcodeNameError:
+		return challenge.NotFoundZone(err)
+	default:
+		return challenge.Permanent(err)
+	}
+}
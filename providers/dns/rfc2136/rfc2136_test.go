@@ -2,6 +2,7 @@ package rfc2136
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
@@ -89,6 +91,29 @@ func TestServerError(t *testing.T) {
 	}
 }
 
+func Test_categorizeRcode(t *testing.T) {
+	testCases := []struct {
+		rcode    int
+		expected challenge.ErrorCategory
+	}{
+		{rcode: dns.RcodeNotAuth, expected: challenge.ErrorCategoryAuth},
+		{rcode: dns.RcodeRefused, expected: challenge.ErrorCategoryAuth},
+		{rcode: dns.RcodeServerFailure, expected: challenge.ErrorCategoryTransient},
+		{rcode: dns.RcodeNotZone, expected: challenge.ErrorCategoryZoneNotFound},
+		{rcode: dns.RcodeFormatError, expected: challenge.ErrorCategoryPermanent},
+	}
+
+	for _, test := range testCases {
+		t.Run(dns.RcodeToString[test.rcode], func(t *testing.T) {
+			t.Parallel()
+
+			err := categorizeRcode(test.rcode, errors.New("oops"))
+
+			assert.Equal(t, test.expected, challenge.GetErrorCategory(err))
+		})
+	}
+}
+
 func TestTsigClient(t *testing.T) {
 	dns01.ClearFqdnCache()
 	dns.HandleFunc(fakeZone, serverHandlerReturnSuccess)
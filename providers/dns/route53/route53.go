@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -17,6 +18,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	awstypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/pya789/lego/v4/challenge"
 	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/config/env"
 	"github.com/pya789/lego/v4/platform/wait"
@@ -66,17 +68,21 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		HostedZoneID:  env.GetOrFile(EnvHostedZoneID),
-		MaxRetries:    env.GetOrDefaultInt(EnvMaxRetries, 5),
-		AssumeRoleArn: env.GetOrDefaultString(EnvAssumeRoleArn, ""),
-		ExternalID:    env.GetOrDefaultString(EnvExternalID, ""),
+		HostedZoneID:  e.GetOrFile(EnvHostedZoneID),
+		MaxRetries:    e.GetOrDefaultInt(EnvMaxRetries, 5),
+		AssumeRoleArn: e.GetOrDefaultString(EnvAssumeRoleArn, ""),
+		ExternalID:    e.GetOrDefaultString(EnvExternalID, ""),
 
-		WaitForRecordSetsChanged: env.GetOrDefaultBool(EnvWaitForRecordSetsChanged, true),
+		WaitForRecordSetsChanged: e.GetOrDefaultBool(EnvWaitForRecordSetsChanged, true),
 
-		TTL:                env.GetOrDefaultInt(EnvTTL, 10),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 4*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 10),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 4*time.Second),
 	}
 }
 
@@ -84,6 +90,12 @@ func NewDefaultConfig() *Config {
 type DNSProvider struct {
 	client *route53.Client
 	config *Config
+
+	changeIDs   map[string]*string
+	changeIDsMu sync.Mutex
+
+	tx   *transaction
+	txMu sync.Mutex
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for the AWS Route 53 service.
@@ -98,7 +110,13 @@ type DNSProvider struct {
 //
 // See also: https://github.com/aws/aws-sdk-go/wiki/configuring-sdk
 func NewDNSProvider() (*DNSProvider, error) {
-	return NewDNSProviderConfig(NewDefaultConfig())
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	return NewDNSProviderConfig(newDefaultConfig(e))
 }
 
 // NewDNSProviderConfig takes a given config and returns a custom configured DNSProvider instance.
@@ -108,7 +126,11 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 	}
 
 	if config.Client != nil {
-		return &DNSProvider{client: config.Client, config: config}, nil
+		return &DNSProvider{
+			client:    config.Client,
+			config:    config,
+			changeIDs: make(map[string]*string),
+		}, nil
 	}
 
 	ctx := context.Background()
@@ -119,8 +141,9 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 	}
 
 	return &DNSProvider{
-		client: route53.NewFromConfig(cfg),
-		config: config,
+		client:    route53.NewFromConfig(cfg),
+		config:    config,
+		changeIDs: make(map[string]*string),
 	}, nil
 }
 
@@ -139,12 +162,25 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("route53: failed to determine hosted zone ID: %w", err)
 	}
 
+	staged, err := d.stage(ctx, hostedZoneID, info.EffectiveFQDN, func(zc *zoneChanges, values []string) []string {
+		zc.tokens = append(zc.tokens, token)
+		zc.wait = zc.wait || d.config.WaitForRecordSetsChanged
+		return addValue(values, dns01.QuoteTXTValue(info.Value))
+	})
+	if staged {
+		if err != nil {
+			return fmt.Errorf("route53: %w", err)
+		}
+
+		return nil
+	}
+
 	records, err := d.getExistingRecordSets(ctx, hostedZoneID, info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("route53: %w", err)
 	}
 
-	realValue := `"` + info.Value + `"`
+	realValue := dns01.QuoteTXTValue(info.Value)
 
 	var found bool
 	for _, record := range records {
@@ -164,7 +200,47 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		ResourceRecords: records,
 	}
 
-	err = d.changeRecord(ctx, awstypes.ChangeActionUpsert, hostedZoneID, recordSet)
+	changeID, err := d.changeRecord(ctx, awstypes.ChangeActionUpsert, hostedZoneID, recordSet)
+	if err != nil {
+		return fmt.Errorf("route53: %w", err)
+	}
+
+	d.changeIDsMu.Lock()
+	d.changeIDs[token] = changeID
+	d.changeIDsMu.Unlock()
+
+	if d.config.WaitForRecordSetsChanged {
+		err = d.waitForChange(ctx, changeID)
+		if err != nil {
+			return fmt.Errorf("route53: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// IdempotentPresent reports that Present upserts the record set, without duplicating the value: it can be called again safely.
+func (d *DNSProvider) IdempotentPresent() bool {
+	return true
+}
+
+// WaitForPropagation waits until the change of the TXT record is INSYNC:
+// Route 53 reports that the change has been propagated to all its authoritative DNS servers.
+func (d *DNSProvider) WaitForPropagation(domain, token, keyAuth string) error {
+	return d.WaitForPropagationContext(context.Background(), domain, token, keyAuth)
+}
+
+// WaitForPropagationContext is like WaitForPropagation, the polling stops when the context is done.
+func (d *DNSProvider) WaitForPropagationContext(ctx context.Context, domain, token, keyAuth string) error {
+	d.changeIDsMu.Lock()
+	changeID, ok := d.changeIDs[token]
+	d.changeIDsMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("route53: unknown change ID for '%s'", dns01.GetChallengeInfo(domain, keyAuth).EffectiveFQDN)
+	}
+
+	err := d.waitForChange(ctx, changeID)
 	if err != nil {
 		return fmt.Errorf("route53: %w", err)
 	}
@@ -177,11 +253,27 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	ctx := context.Background()
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
+	d.changeIDsMu.Lock()
+	delete(d.changeIDs, token)
+	d.changeIDsMu.Unlock()
+
 	hostedZoneID, err := d.getHostedZoneID(ctx, info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("failed to determine Route 53 hosted zone ID: %w", err)
 	}
 
+	staged, err := d.stage(ctx, hostedZoneID, info.EffectiveFQDN, func(zc *zoneChanges, values []string) []string {
+		zc.wait = zc.wait || d.config.WaitForRecordSetsChanged
+		return removeValue(values, dns01.QuoteTXTValue(info.Value))
+	})
+	if staged {
+		if err != nil {
+			return fmt.Errorf("route53: %w", err)
+		}
+
+		return nil
+	}
+
 	existingRecords, err := d.getExistingRecordSets(ctx, hostedZoneID, info.EffectiveFQDN)
 	if err != nil {
 		return fmt.Errorf("route53: %w", err)
@@ -193,7 +285,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	var nonLegoRecords []awstypes.ResourceRecord
 	for _, record := range existingRecords {
-		if deref(record.Value) != `"`+info.Value+`"` {
+		if deref(record.Value) != dns01.QuoteTXTValue(info.Value) {
 			nonLegoRecords = append(nonLegoRecords, record)
 		}
 	}
@@ -214,49 +306,58 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		recordSet.ResourceRecords = existingRecords
 	}
 
-	err = d.changeRecord(ctx, action, hostedZoneID, recordSet)
+	changeID, err := d.changeRecord(ctx, action, hostedZoneID, recordSet)
 	if err != nil {
 		return fmt.Errorf("route53: %w", err)
 	}
 
+	if d.config.WaitForRecordSetsChanged {
+		err = d.waitForChange(ctx, changeID)
+		if err != nil {
+			return fmt.Errorf("route53: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func (d *DNSProvider) changeRecord(ctx context.Context, action awstypes.ChangeAction, hostedZoneID string, recordSet *awstypes.ResourceRecordSet) error {
+func (d *DNSProvider) changeRecord(ctx context.Context, action awstypes.ChangeAction, hostedZoneID string, recordSet *awstypes.ResourceRecordSet) (*string, error) {
+	return d.changeRecordSets(ctx, hostedZoneID, []awstypes.Change{{
+		Action:            action,
+		ResourceRecordSet: recordSet,
+	}})
+}
+
+func (d *DNSProvider) changeRecordSets(ctx context.Context, hostedZoneID string, changes []awstypes.Change) (*string, error) {
 	recordSetInput := &route53.ChangeResourceRecordSetsInput{
 		HostedZoneId: aws.String(hostedZoneID),
 		ChangeBatch: &awstypes.ChangeBatch{
 			Comment: aws.String("Managed by Lego"),
-			Changes: []awstypes.Change{{
-				Action:            action,
-				ResourceRecordSet: recordSet,
-			}},
+			Changes: changes,
 		},
 	}
 
 	resp, err := d.client.ChangeResourceRecordSets(ctx, recordSetInput)
 	if err != nil {
-		return fmt.Errorf("failed to change record set: %w", err)
+		return nil, fmt.Errorf("failed to change record set: %w", categorizeError(err))
 	}
 
-	changeID := resp.ChangeInfo.Id
-
-	if d.config.WaitForRecordSetsChanged {
-		return wait.For("route53", d.config.PropagationTimeout, d.config.PollingInterval, func() (bool, error) {
-			resp, err := d.client.GetChange(ctx, &route53.GetChangeInput{Id: changeID})
-			if err != nil {
-				return false, fmt.Errorf("failed to query change status: %w", err)
-			}
+	return resp.ChangeInfo.Id, nil
+}
 
-			if resp.ChangeInfo.Status == awstypes.ChangeStatusInsync {
-				return true, nil
-			}
+func (d *DNSProvider) waitForChange(ctx context.Context, changeID *string) error {
+	return wait.ForContext(ctx, "route53", d.config.PropagationTimeout, d.config.PollingInterval, func() (bool, error) {
+		resp, err := d.client.GetChange(ctx, &route53.GetChangeInput{Id: changeID})
+		if err != nil {
+			return false, fmt.Errorf("failed to query change status: %w", categorizeError(err))
+		}
 
-			return false, fmt.Errorf("unable to retrieve change: ID=%s", deref(changeID))
-		})
-	}
+		if resp.ChangeInfo.Status == awstypes.ChangeStatusInsync {
+			return true, nil
+		}
 
-	return nil
+		return false, fmt.Errorf("unable to retrieve change: ID=%s", deref(changeID))
+	})
 }
 
 func (d *DNSProvider) getExistingRecordSets(ctx context.Context, hostedZoneID, fqdn string) ([]awstypes.ResourceRecord, error) {
@@ -268,7 +369,7 @@ func (d *DNSProvider) getExistingRecordSets(ctx context.Context, hostedZoneID, f
 
 	recordSetsOutput, err := d.client.ListResourceRecordSets(ctx, listInput)
 	if err != nil {
-		return nil, err
+		return nil, categorizeError(err)
 	}
 
 	if recordSetsOutput == nil {
@@ -302,7 +403,7 @@ func (d *DNSProvider) getHostedZoneID(ctx context.Context, fqdn string) (string,
 	}
 	resp, err := d.client.ListHostedZonesByName(ctx, reqParams)
 	if err != nil {
-		return "", err
+		return "", categorizeError(err)
 	}
 
 	var hostedZoneID string
@@ -315,7 +416,7 @@ func (d *DNSProvider) getHostedZoneID(ctx context.Context, fqdn string) (string,
 	}
 
 	if hostedZoneID == "" {
-		return "", fmt.Errorf("zone %s not found for domain %s", authZone, fqdn)
+		return "", challenge.NotFoundZone(fmt.Errorf("zone %s not found for domain %s", authZone, fqdn))
 	}
 
 	hostedZoneID = strings.TrimPrefix(hostedZoneID, "/hostedzone/")
@@ -392,6 +493,30 @@ func createAWSConfigCheckParams(config *Config) error {
 	return nil
 }
 
+// categorizeError categorizes the errors of the AWS API with their error codes.
+func categorizeError(err error) error {
+	var apiErr interface{ ErrorCode() string }
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.ErrorCode() {
+	case "AccessDenied", "AccessDeniedException", "InvalidClientTokenId", "SignatureDoesNotMatch",
+		"ExpiredToken", "UnrecognizedClientException", "MissingAuthenticationToken":
+		return challenge.AuthError(err)
+	case "Throttling", "ThrottlingException", "PriorRequestNotComplete":
+		return challenge.Throttled(err)
+	case "NoSuchHostedZone":
+		return challenge.NotFoundZone(err)
+	case "InvalidChangeBatch", "InvalidInput":
+		return challenge.Permanent(err)
+	case "ServiceUnavailable", "InternalFailure":
+		return challenge.Transient(err)
+	default:
+		return err
+	}
+}
+
 func deref[T string | int | int32 | int64 | bool](v *T) T {
 	if v == nil {
 		var zero T
@@ -2,7 +2,11 @@ package route53
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,7 +14,9 @@ import (
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/pya789/lego/v4/challenge/dns01"
 	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/pya789/lego/v4/platform/wait"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -41,8 +47,9 @@ func makeTestProvider(t *testing.T, serverURL string) *DNSProvider {
 	}
 
 	return &DNSProvider{
-		client: route53.NewFromConfig(cfg),
-		config: NewDefaultConfig(),
+		client:    route53.NewFromConfig(cfg),
+		config:    NewDefaultConfig(),
+		changeIDs: make(map[string]*string),
 	}
 }
 
@@ -177,6 +184,40 @@ func TestDNSProvider_Present(t *testing.T) {
 	require.NoError(t, err, "Expected Present to return no error")
 }
 
+func TestDNSProvider_WaitForPropagation(t *testing.T) {
+	mockResponses := MockResponseMap{
+		"/2013-04-01/hostedzonesbyname":        {StatusCode: 200, Body: ListHostedZonesByNameResponse},
+		"/2013-04-01/hostedzone/ABCDEFG/rrset": {StatusCode: 200, Body: ChangeResourceRecordSetsResponse},
+		"/2013-04-01/change/123456":            {StatusCode: 200, Body: GetChangeResponse},
+		"/2013-04-01/hostedzone/ABCDEFG/rrset?name=_acme-challenge.example.com.&type=TXT": {
+			StatusCode: 200,
+			Body:       "",
+		},
+	}
+
+	serverURL := setupTest(t, mockResponses)
+
+	defer envTest.RestoreEnv()
+	envTest.ClearEnv()
+	provider := makeTestProvider(t, serverURL)
+
+	domain := "example.com"
+	keyAuth := "123456d=="
+
+	err := provider.Present(domain, "token", keyAuth)
+	require.NoError(t, err)
+
+	err = provider.WaitForPropagation(domain, "token", keyAuth)
+	require.NoError(t, err)
+
+	err = provider.CleanUp(domain, "token", keyAuth)
+	require.NoError(t, err)
+
+	// the change ID is removed by the clean-up.
+	err = provider.WaitForPropagation(domain, "token", keyAuth)
+	require.Error(t, err)
+}
+
 func Test_createAWSConfig(t *testing.T) {
 	testCases := []struct {
 		desc             string
@@ -308,3 +349,104 @@ func requireErr(t *testing.T, err error, wantErr string) {
 		require.EqualError(t, err, wantErr)
 	}
 }
+
+func TestDNSProvider_Commit(t *testing.T) {
+	var batches []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2013-04-01/hostedzonesbyname", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(ListHostedZonesByNameResponse))
+	})
+	mux.HandleFunc("/2013-04-01/hostedzone/ABCDEFG/rrset", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			batches = append(batches, string(body))
+
+			_, _ = w.Write([]byte(ChangeResourceRecordSetsResponse))
+			return
+		}
+
+		_, _ = w.Write([]byte(""))
+	})
+	mux.HandleFunc("/2013-04-01/change/123456", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(GetChangeResponse))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	defer envTest.RestoreEnv()
+	envTest.ClearEnv()
+	provider := makeTestProvider(t, server.URL)
+	provider.config.HostedZoneID = "ABCDEFG"
+
+	require.NoError(t, provider.Begin())
+
+	// wildcard and apex: the same record set.
+	require.NoError(t, provider.Present("example.com", "token1", "123456d=="))
+	require.NoError(t, provider.Present("example.com", "token2", "654321d=="))
+
+	assert.Empty(t, batches)
+
+	require.NoError(t, provider.Commit())
+
+	require.Len(t, batches, 1)
+	assert.Equal(t, 1, strings.Count(batches[0], "<Change>"))
+	assert.Contains(t, batches[0], dns01.GetChallengeInfo("example.com", "123456d==").Value)
+	assert.Contains(t, batches[0], dns01.GetChallengeInfo("example.com", "654321d==").Value)
+
+	require.NoError(t, provider.WaitForPropagation("example.com", "token1", "123456d=="))
+	require.NoError(t, provider.WaitForPropagation("example.com", "token2", "654321d=="))
+
+	require.NoError(t, provider.Begin())
+	require.NoError(t, provider.Present("example.com", "token3", "123456d=="))
+	provider.Rollback()
+
+	assert.Len(t, batches, 1)
+	require.EqualError(t, provider.Commit(), "route53: no transaction in progress")
+}
+
+func TestDNSProvider_Commit_waitError(t *testing.T) {
+	var batches []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2013-04-01/hostedzone/ABCDEFG/rrset", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			batches = append(batches, string(body))
+
+			_, _ = w.Write([]byte(ChangeResourceRecordSetsResponse))
+			return
+		}
+
+		_, _ = w.Write([]byte(""))
+	})
+	mux.HandleFunc("/2013-04-01/change/123456", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Replace(GetChangeResponse, "INSYNC", "PENDING", 1)))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	defer envTest.RestoreEnv()
+	envTest.ClearEnv()
+	provider := makeTestProvider(t, server.URL)
+	provider.config.HostedZoneID = "ABCDEFG"
+	provider.config.PropagationTimeout = 50 * time.Millisecond
+	provider.config.PollingInterval = 10 * time.Millisecond
+
+	require.NoError(t, provider.Begin())
+
+	require.NoError(t, provider.Present("example.com", "token1", "123456d=="))
+
+	err := provider.Commit()
+	require.ErrorIs(t, err, wait.ErrTimeout)
+
+	// the change batch and the revert of the change batch.
+	require.Len(t, batches, 2)
+	assert.Contains(t, batches[0], "<Action>UPSERT</Action>")
+	assert.Contains(t, batches[1], "<Action>DELETE</Action>")
+	assert.Contains(t, batches[1], dns01.GetChallengeInfo("example.com", "123456d==").Value)
+
+	require.Error(t, provider.WaitForPropagation("example.com", "token1", "123456d=="))
+}
@@ -0,0 +1,237 @@
+package route53
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/pya789/lego/v4/log"
+)
+
+// transaction the changes staged between Begin and Commit, by hosted zone.
+type transaction struct {
+	zones   map[string]*zoneChanges
+	zoneIDs []string
+}
+
+// zoneChanges the changes of the TXT record sets of a hosted zone.
+type zoneChanges struct {
+	recordSets map[string]*stagedRecordSet
+	fqdns      []string
+
+	// tokens the tokens of the presented challenges, associated to the ID of the change.
+	tokens []string
+	// wait is true if the commit must wait for the change to be INSYNC.
+	wait bool
+}
+
+// stagedRecordSet a TXT record set: the values before the transaction, and the values after the transaction.
+type stagedRecordSet struct {
+	existing []awstypes.ResourceRecord
+	values   []string
+}
+
+// Begin starts a transaction: Present and CleanUp stage the changes until Commit.
+func (d *DNSProvider) Begin() error {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+
+	if d.tx != nil {
+		return errors.New("route53: a transaction is already in progress")
+	}
+
+	d.tx = &transaction{zones: make(map[string]*zoneChanges)}
+
+	return nil
+}
+
+// Commit applies the staged changes, in one change batch by hosted zone.
+// If the change batch of a hosted zone fails, or does not become INSYNC,
+// the change batches already applied are reverted.
+func (d *DNSProvider) Commit() error {
+	d.txMu.Lock()
+	tx := d.tx
+	d.tx = nil
+	d.txMu.Unlock()
+
+	if tx == nil {
+		return errors.New("route53: no transaction in progress")
+	}
+
+	ctx := context.Background()
+
+	var applied []string
+
+	changeIDs := make(map[string]*string)
+
+	for _, zoneID := range tx.zoneIDs {
+		zc := tx.zones[zoneID]
+
+		changes := zc.changes(d.config.TTL)
+		if len(changes) == 0 {
+			continue
+		}
+
+		changeID, err := d.changeRecordSets(ctx, zoneID, changes)
+		if err != nil {
+			d.revert(ctx, tx, applied)
+
+			return fmt.Errorf("route53: %w", err)
+		}
+
+		applied = append(applied, zoneID)
+
+		for _, token := range zc.tokens {
+			changeIDs[token] = changeID
+		}
+
+		if zc.wait {
+			err = d.waitForChange(ctx, changeID)
+			if err != nil {
+				d.revert(ctx, tx, applied)
+
+				return fmt.Errorf("route53: %w", err)
+			}
+		}
+	}
+
+	// the change IDs are only known after the success of all the change batches.
+	d.changeIDsMu.Lock()
+	maps.Copy(d.changeIDs, changeIDs)
+	d.changeIDsMu.Unlock()
+
+	return nil
+}
+
+// Rollback discards the staged changes.
+func (d *DNSProvider) Rollback() {
+	d.txMu.Lock()
+	d.tx = nil
+	d.txMu.Unlock()
+}
+
+// revert restores the record sets of the hosted zones already changed by a transaction.
+func (d *DNSProvider) revert(ctx context.Context, tx *transaction, zoneIDs []string) {
+	for _, zoneID := range zoneIDs {
+		changes := tx.zones[zoneID].revertChanges(d.config.TTL)
+		if len(changes) == 0 {
+			continue
+		}
+
+		_, err := d.changeRecordSets(ctx, zoneID, changes)
+		if err != nil {
+			log.Warnf("route53: failed to revert the changes of the hosted zone %s: %v", zoneID, err)
+		}
+	}
+}
+
+// stage updates the values of a TXT record set in the current transaction.
+// It returns false if there is no transaction in progress.
+func (d *DNSProvider) stage(ctx context.Context, hostedZoneID, fqdn string, update func(zc *zoneChanges, values []string) []string) (bool, error) {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+
+	if d.tx == nil {
+		return false, nil
+	}
+
+	zc, ok := d.tx.zones[hostedZoneID]
+	if !ok {
+		zc = &zoneChanges{recordSets: make(map[string]*stagedRecordSet)}
+		d.tx.zones[hostedZoneID] = zc
+		d.tx.zoneIDs = append(d.tx.zoneIDs, hostedZoneID)
+	}
+
+	rs, ok := zc.recordSets[fqdn]
+	if !ok {
+		existing, err := d.getExistingRecordSets(ctx, hostedZoneID, fqdn)
+		if err != nil {
+			return true, err
+		}
+
+		rs = &stagedRecordSet{existing: existing}
+		for _, record := range existing {
+			rs.values = append(rs.values, deref(record.Value))
+		}
+
+		zc.recordSets[fqdn] = rs
+		zc.fqdns = append(zc.fqdns, fqdn)
+	}
+
+	rs.values = update(zc, rs.values)
+
+	return true, nil
+}
+
+// changes returns the changes to apply the staged values.
+func (z *zoneChanges) changes(ttl int) []awstypes.Change {
+	var changes []awstypes.Change
+
+	for _, fqdn := range z.fqdns {
+		rs := z.recordSets[fqdn]
+
+		switch {
+		case len(rs.values) > 0:
+			changes = append(changes, newChange(awstypes.ChangeActionUpsert, fqdn, ttl, toResourceRecords(rs.values)))
+		case len(rs.existing) > 0:
+			changes = append(changes, newChange(awstypes.ChangeActionDelete, fqdn, ttl, rs.existing))
+		}
+	}
+
+	return changes
+}
+
+// revertChanges returns the changes to restore the values before the transaction.
+func (z *zoneChanges) revertChanges(ttl int) []awstypes.Change {
+	var changes []awstypes.Change
+
+	for _, fqdn := range z.fqdns {
+		rs := z.recordSets[fqdn]
+
+		switch {
+		case len(rs.existing) > 0:
+			changes = append(changes, newChange(awstypes.ChangeActionUpsert, fqdn, ttl, rs.existing))
+		case len(rs.values) > 0:
+			changes = append(changes, newChange(awstypes.ChangeActionDelete, fqdn, ttl, toResourceRecords(rs.values)))
+		}
+	}
+
+	return changes
+}
+
+func newChange(action awstypes.ChangeAction, fqdn string, ttl int, records []awstypes.ResourceRecord) awstypes.Change {
+	return awstypes.Change{
+		Action: action,
+		ResourceRecordSet: &awstypes.ResourceRecordSet{
+			Name:            aws.String(fqdn),
+			Type:            "TXT",
+			TTL:             aws.Int64(int64(ttl)),
+			ResourceRecords: records,
+		},
+	}
+}
+
+func toResourceRecords(values []string) []awstypes.ResourceRecord {
+	var records []awstypes.ResourceRecord
+	for _, value := range values {
+		records = append(records, awstypes.ResourceRecord{Value: aws.String(value)})
+	}
+
+	return records
+}
+
+func addValue(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+
+	return append(values, value)
+}
+
+func removeValue(values []string, value string) []string {
+	return slices.DeleteFunc(slices.Clone(values), func(v string) bool { return v == value })
+}
@@ -0,0 +1,330 @@
+// Package router implements a DNS provider routing the challenges to other DNS providers, by domain suffix.
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/platform/config/env"
+	"gopkg.in/yaml.v2"
+)
+
+// Environment variables names.
+const (
+	envNamespace = "ROUTER_"
+
+	EnvConfigFile = envNamespace + "CONFIG_FILE"
+
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+)
+
+// Name the name of the provider (its own name cannot be used in the routes).
+const Name = "router"
+
+// Factory creates a DNS provider by name, configured with the environment variables read by the getter
+// (ex: dns.NewDNSChallengeProviderByNameFromEnv).
+type Factory func(name string, e env.Getter) (challenge.Provider, error)
+
+// Route routes the challenges of the domains matching a suffix to a DNS provider.
+type Route struct {
+	// Suffix the domain suffix (ex: example.com matches example.com and its subdomains), "." matches all the domains.
+	Suffix string
+	// Provider the DNS provider.
+	Provider challenge.Provider
+}
+
+// Option configures the DNSProvider.
+type Option func(config *Config)
+
+// WithRoute adds a route.
+func WithRoute(suffix string, provider challenge.Provider) Option {
+	return func(config *Config) {
+		config.Routes = append(config.Routes, Route{Suffix: suffix, Provider: provider})
+	}
+}
+
+// WithTimeout defines the propagation timeout and the polling interval.
+func WithTimeout(timeout, interval time.Duration) Option {
+	return func(config *Config) {
+		config.PropagationTimeout = timeout
+		config.PollingInterval = interval
+	}
+}
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	Routes []Route
+
+	// PropagationTimeout and PollingInterval are the minimum values:
+	// the longest timeout and the shortest interval of the routed providers are used if they are beyond.
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
+	return &Config{
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+	}
+}
+
+// FileConfig the configuration file of the routes (YAML).
+//
+//	routes:
+//	  - suffix: example.com
+//	    provider: cloudflare
+//	  - suffix: example.org
+//	    provider: route53
+//	    env-prefix: PROD_
+type FileConfig struct {
+	Routes []FileRoute `yaml:"routes"`
+}
+
+// FileRoute a route of the configuration file.
+type FileRoute struct {
+	Suffix   string `yaml:"suffix"`
+	Provider string `yaml:"provider"`
+	// EnvPrefix the prefix of the environment variables of the provider (ex: PROD_ for PROD_CLOUDFLARE_DNS_API_TOKEN),
+	// the prefixed environment variables have priority over the non-prefixed ones.
+	EnvPrefix string `yaml:"env-prefix"`
+}
+
+// DNSProvider implements the challenge.Provider interface.
+type DNSProvider struct {
+	config *Config
+}
+
+// NewDNSProvider returns a DNSProvider instance configured with the routes of the file ROUTER_CONFIG_FILE,
+// the DNS providers are created by the factory.
+func NewDNSProvider(factory Factory) (*DNSProvider, error) {
+	return NewDNSProviderFromEnv(env.Getter{}, factory)
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix), the DNS providers are created by the factory.
+func NewDNSProviderFromEnv(e env.Getter, factory Factory) (*DNSProvider, error) {
+	values, err := e.Get(EnvConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("router: %w", err)
+	}
+
+	routes, err := LoadRoutes(values[EnvConfigFile], factory)
+	if err != nil {
+		return nil, fmt.Errorf("router: %w", err)
+	}
+
+	config := newDefaultConfig(e)
+	config.Routes = routes
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderWithOptions returns a DNSProvider instance configured with options.
+func NewDNSProviderWithOptions(opts ...Option) (*DNSProvider, error) {
+	config := NewDefaultConfig()
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for routing.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("router: the configuration of the DNS provider is nil")
+	}
+
+	if len(config.Routes) == 0 {
+		return nil, errors.New("router: no route")
+	}
+
+	seen := make(map[string]struct{})
+
+	for i, route := range config.Routes {
+		suffix := normalize(route.Suffix)
+		if suffix == "" {
+			return nil, fmt.Errorf("router: route %d: missing suffix", i)
+		}
+
+		if route.Provider == nil {
+			return nil, fmt.Errorf("router: route %q: missing provider", route.Suffix)
+		}
+
+		if _, ok := seen[suffix]; ok {
+			return nil, fmt.Errorf("router: duplicate route %q", route.Suffix)
+		}
+
+		seen[suffix] = struct{}{}
+	}
+
+	return &DNSProvider{config: config}, nil
+}
+
+// LoadRoutes reads the routes from a configuration file (YAML), the DNS providers are created by the factory.
+func LoadRoutes(filename string, factory Factory) ([]Route, error) {
+	if factory == nil {
+		return nil, errors.New("the provider factory is nil")
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg FileConfig
+
+	err = yaml.UnmarshalStrict(data, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	var routes []Route
+
+	for _, r := range cfg.Routes {
+		if r.Provider == "" {
+			return nil, fmt.Errorf("%s: route %q: missing provider", filename, r.Suffix)
+		}
+
+		if r.Provider == Name {
+			return nil, fmt.Errorf("%s: route %q: the provider %q cannot be routed", filename, r.Suffix, Name)
+		}
+
+		provider, err := factory(r.Provider, env.WithPrefix(r.EnvPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("%s: route %q: %w", filename, r.Suffix, err)
+		}
+
+		routes = append(routes, Route{Suffix: r.Suffix, Provider: provider})
+	}
+
+	return routes, nil
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	return d.PresentContext(context.Background(), domain, token, keyAuth)
+}
+
+// PresentContext creates a TXT record with the provider of the route matching the domain.
+func (d *DNSProvider) PresentContext(ctx context.Context, domain, token, keyAuth string) error {
+	provider, err := d.route(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	return challenge.Present(ctx, provider, domain, token, keyAuth)
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	return d.CleanUpContext(context.Background(), domain, token, keyAuth)
+}
+
+// CleanUpContext removes the TXT record with the provider of the route matching the domain.
+func (d *DNSProvider) CleanUpContext(ctx context.Context, domain, token, keyAuth string) error {
+	provider, err := d.route(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	return challenge.CleanUp(ctx, provider, domain, token, keyAuth)
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation:
+// the longest timeout and the shortest interval of the routed providers, bounded by the configuration.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	timeout, interval = d.config.PropagationTimeout, d.config.PollingInterval
+
+	for _, route := range d.config.Routes {
+		p, ok := route.Provider.(challenge.ProviderTimeout)
+		if !ok {
+			continue
+		}
+
+		t, i := p.Timeout()
+
+		timeout = max(timeout, t)
+
+		if i > 0 {
+			interval = min(interval, i)
+		}
+	}
+
+	return timeout, interval
+}
+
+// VerifyCredentials checks the credentials of the routed providers able to check them (challenge.CredentialsVerifier).
+func (d *DNSProvider) VerifyCredentials(ctx context.Context) error {
+	var errs []error
+
+	for _, route := range d.config.Routes {
+		_, err := challenge.VerifyCredentials(ctx, route.Provider)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("route %s: %w", route.Suffix, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("router: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// route returns the provider of the route with the longest suffix matching the FQDN of the TXT record.
+// The FQDN is the effective FQDN (after the CNAME resolution), the record is created in the zone it belongs to.
+func (d *DNSProvider) route(domain, keyAuth string) (challenge.Provider, error) {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+
+	fqdn := normalize(info.EffectiveFQDN)
+
+	var (
+		provider challenge.Provider
+		longest  = -1
+	)
+
+	for _, route := range d.config.Routes {
+		suffix := normalize(route.Suffix)
+
+		if !matchSuffix(fqdn, suffix) || len(suffix) <= longest {
+			continue
+		}
+
+		provider = route.Provider
+		longest = len(suffix)
+	}
+
+	if provider == nil {
+		return nil, fmt.Errorf("router: no route for %s", dns01.UnFqdn(info.EffectiveFQDN))
+	}
+
+	return provider, nil
+}
+
+func matchSuffix(fqdn, suffix string) bool {
+	return suffix == "." || fqdn == suffix || strings.HasSuffix(fqdn, "."+suffix)
+}
+
+// normalize returns the suffix in lower case with a trailing dot.
+func normalize(suffix string) string {
+	suffix = strings.ToLower(strings.TrimSpace(suffix))
+	if suffix == "" || suffix == "." {
+		return suffix
+	}
+
+	return dns01.ToFqdn(strings.TrimPrefix(suffix, "."))
+}
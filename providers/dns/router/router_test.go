@@ -0,0 +1,220 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pya789/lego/v4/challenge"
+	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var envTest = tester.NewEnvTest(EnvConfigFile)
+
+type mockProvider struct {
+	name     string
+	timeout  time.Duration
+	interval time.Duration
+
+	presented []string
+	cleaned   []string
+}
+
+func (p *mockProvider) Present(domain, _, _ string) error {
+	p.presented = append(p.presented, domain)
+	return nil
+}
+
+func (p *mockProvider) CleanUp(domain, _, _ string) error {
+	p.cleaned = append(p.cleaned, domain)
+	return nil
+}
+
+type mockTimeoutProvider struct {
+	mockProvider
+}
+
+func (p *mockTimeoutProvider) Timeout() (time.Duration, time.Duration) {
+	return p.timeout, p.interval
+}
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvConfigFile: "./fixtures/routes.yml",
+			},
+		},
+		{
+			desc:     "missing configuration file",
+			envVars:  map[string]string{},
+			expected: "router: some credentials information are missing: ROUTER_CONFIG_FILE",
+		},
+		{
+			desc: "routed router",
+			envVars: map[string]string{
+				EnvConfigFile: "./fixtures/routes_router.yml",
+			},
+			expected: `router: ./fixtures/routes_router.yml: route "example.com": the provider "router" cannot be routed`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider(func(name string, e env.Getter) (challenge.Provider, error) {
+				return &mockProvider{name: name}, nil
+			})
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				assert.Len(t, p.config.Routes, 2)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		routes   []Route
+		expected string
+	}{
+		{
+			desc:   "success",
+			routes: []Route{{Suffix: "example.com", Provider: &mockProvider{}}},
+		},
+		{
+			desc:     "no route",
+			expected: "router: no route",
+		},
+		{
+			desc:     "missing suffix",
+			routes:   []Route{{Provider: &mockProvider{}}},
+			expected: "router: route 0: missing suffix",
+		},
+		{
+			desc:     "missing provider",
+			routes:   []Route{{Suffix: "example.com"}},
+			expected: `router: route "example.com": missing provider`,
+		},
+		{
+			desc: "duplicate route",
+			routes: []Route{
+				{Suffix: "example.com", Provider: &mockProvider{}},
+				{Suffix: "EXAMPLE.com.", Provider: &mockProvider{}},
+			},
+			expected: `router: duplicate route "EXAMPLE.com."`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := NewDefaultConfig()
+			config.Routes = test.routes
+
+			p, err := NewDNSProviderConfig(config)
+
+			if test.expected == "" {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestLoadRoutes(t *testing.T) {
+	t.Setenv("PROD_ROUTER_TEST", "prod")
+	t.Setenv("ROUTER_TEST", "default")
+
+	var values []string
+
+	routes, err := LoadRoutes("./fixtures/routes.yml", func(name string, e env.Getter) (challenge.Provider, error) {
+		// the environment variables are resolved with the prefix of the route.
+		values = append(values, name+":"+e.GetOrFile("ROUTER_TEST"))
+
+		return &mockProvider{name: name}, nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, routes, 2)
+	assert.Equal(t, "example.com", routes[0].Suffix)
+	assert.Equal(t, "cloudflare", routes[0].Provider.(*mockProvider).name)
+	assert.Equal(t, "example.org", routes[1].Suffix)
+	assert.Equal(t, "route53", routes[1].Provider.(*mockProvider).name)
+
+	assert.Equal(t, []string{"cloudflare:default", "route53:prod"}, values)
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	main := &mockProvider{name: "main"}
+	sub := &mockProvider{name: "sub"}
+	fallback := &mockProvider{name: "fallback"}
+
+	p, err := NewDNSProviderWithOptions(
+		WithRoute("example.com", main),
+		WithRoute("sub.example.com", sub),
+		WithRoute(".", fallback),
+	)
+	require.NoError(t, err)
+
+	for _, domain := range []string{"example.com", "www.example.com", "sub.example.com", "a.sub.example.com", "example.org", "notexample.com"} {
+		require.NoError(t, p.Present(domain, "token", "keyAuth"))
+		require.NoError(t, p.CleanUp(domain, "token", "keyAuth"))
+	}
+
+	assert.Equal(t, []string{"example.com", "www.example.com"}, main.presented)
+	assert.Equal(t, []string{"sub.example.com", "a.sub.example.com"}, sub.presented)
+	assert.Equal(t, []string{"example.org", "notexample.com"}, fallback.presented)
+
+	assert.Equal(t, main.presented, main.cleaned)
+	assert.Equal(t, sub.presented, sub.cleaned)
+	assert.Equal(t, fallback.presented, fallback.cleaned)
+}
+
+func TestDNSProvider_Present_noRoute(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	p, err := NewDNSProviderWithOptions(WithRoute("example.com", &mockProvider{}))
+	require.NoError(t, err)
+
+	err = p.Present("example.org", "token", "keyAuth")
+	require.EqualError(t, err, "router: no route for _acme-challenge.example.org")
+}
+
+func TestDNSProvider_Timeout(t *testing.T) {
+	p, err := NewDNSProviderWithOptions(
+		WithTimeout(time.Minute, 5*time.Second),
+		WithRoute("example.com", &mockProvider{}),
+		WithRoute("example.org", &mockTimeoutProvider{mockProvider{timeout: 10 * time.Minute, interval: 2 * time.Second}}),
+		WithRoute("example.net", &mockTimeoutProvider{mockProvider{timeout: 30 * time.Second, interval: 10 * time.Second}}),
+	)
+	require.NoError(t, err)
+
+	timeout, interval := p.Timeout()
+
+	assert.Equal(t, 10*time.Minute, timeout)
+	assert.Equal(t, 2*time.Second, interval)
+}
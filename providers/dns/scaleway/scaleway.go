@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/pya789/lego/v4/challenge/dns01"
+	"github.com/pya789/lego/v4/log"
 	"github.com/pya789/lego/v4/platform/config/env"
+	"github.com/pya789/lego/v4/platform/wait"
 	scwdomain "github.com/scaleway/scaleway-sdk-go/api/domain/v2beta1"
 	"github.com/scaleway/scaleway-sdk-go/scw"
 )
@@ -36,28 +38,42 @@ const (
 	EnvAccessKey = altEnvNamespace + "ACCESS_KEY"
 	EnvSecretKey = altEnvNamespace + "SECRET_KEY"
 
+	// EnvDefaultProjectID the default project of the Scaleway tools (ex: the project of a project-scoped API key).
+	EnvDefaultProjectID = altEnvNamespace + "DEFAULT_PROJECT_ID"
+
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+	EnvWaitZoneUpdate     = envNamespace + "WAIT_ZONE_UPDATE"
 )
 
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
+	// ProjectID the project of the DNS zones, required with the project-scoped API keys.
 	ProjectID          string
 	Token              string // TODO(ldez) rename to SecretKey in the next major.
 	AccessKey          string
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	TTL                int
+
+	// WaitZoneUpdate waits for the status of the zone to be active (the changes are applied on the nameservers),
+	// before the DNS propagation check.
+	WaitZoneUpdate bool
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
 		AccessKey:          dumpAccessKey,
-		TTL:                env.GetOneWithFallback(EnvTTL, minTTL, strconv.Atoi, altEnvName(EnvTTL)),
-		PropagationTimeout: env.GetOneWithFallback(EnvPropagationTimeout, defaultPropagationTimeout, env.ParseSecond, altEnvName(EnvPropagationTimeout)),
-		PollingInterval:    env.GetOneWithFallback(EnvPollingInterval, defaultPollingInterval, env.ParseSecond, altEnvName(EnvPollingInterval)),
+		TTL:                env.GetOneWithFallbackFrom(e, EnvTTL, minTTL, strconv.Atoi, altEnvName(EnvTTL)),
+		PropagationTimeout: env.GetOneWithFallbackFrom(e, EnvPropagationTimeout, defaultPropagationTimeout, env.ParseSecond, altEnvName(EnvPropagationTimeout)),
+		PollingInterval:    env.GetOneWithFallbackFrom(e, EnvPollingInterval, defaultPollingInterval, env.ParseSecond, altEnvName(EnvPollingInterval)),
+		WaitZoneUpdate:     env.GetOneWithFallbackFrom(e, EnvWaitZoneUpdate, true, strconv.ParseBool, altEnvName(EnvWaitZoneUpdate)),
 	}
 }
 
@@ -71,15 +87,21 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // SCALEWAY_API_TOKEN, SCALEWAY_PROJECT_ID.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.GetWithFallback([]string{EnvSecretKey, EnvAPIToken})
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.GetWithFallback([]string{EnvSecretKey, EnvAPIToken})
 	if err != nil {
 		return nil, fmt.Errorf("scaleway: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.Token = values[EnvSecretKey]
-	config.AccessKey = env.GetOrDefaultString(EnvAccessKey, dumpAccessKey)
-	config.ProjectID = env.GetOrFile(EnvProjectID)
+	config.AccessKey = e.GetOrDefaultString(EnvAccessKey, dumpAccessKey)
+	config.ProjectID = env.GetOneWithFallbackFrom(e, EnvProjectID, "", env.ParseString, altEnvName(EnvProjectID), EnvDefaultProjectID)
 
 	return NewDNSProviderConfig(config)
 }
@@ -126,16 +148,21 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
+	zone, subDomain, err := d.findZone(info.EffectiveFQDN)
+	if err != nil {
+		return fmt.Errorf("scaleway: %w", err)
+	}
+
 	records := []*scwdomain.Record{{
 		Data:    fmt.Sprintf(`%q`, info.Value),
-		Name:    info.EffectiveFQDN,
+		Name:    subDomain,
 		TTL:     uint32(d.config.TTL),
 		Type:    scwdomain.RecordTypeTXT,
 		Comment: scw.StringPtr("used by lego"),
 	}}
 
 	req := &scwdomain.UpdateDNSZoneRecordsRequest{
-		DNSZone: info.EffectiveFQDN,
+		DNSZone: zone,
 		Changes: []*scwdomain.RecordChange{{
 			Add: &scwdomain.RecordChangeAdd{Records: records},
 		}},
@@ -143,7 +170,16 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		DisallowNewZoneCreation: true,
 	}
 
-	_, err := d.client.UpdateDNSZoneRecords(req)
+	_, err = d.client.UpdateDNSZoneRecords(req)
+	if err != nil {
+		return fmt.Errorf("scaleway: %w", err)
+	}
+
+	if !d.config.WaitZoneUpdate {
+		return nil
+	}
+
+	err = d.waitZoneUpdate(domain, zone)
 	if err != nil {
 		return fmt.Errorf("scaleway: %w", err)
 	}
@@ -155,14 +191,19 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	info := dns01.GetChallengeInfo(domain, keyAuth)
 
+	zone, subDomain, err := d.findZone(info.EffectiveFQDN)
+	if err != nil {
+		return fmt.Errorf("scaleway: %w", err)
+	}
+
 	recordIdentifier := &scwdomain.RecordIdentifier{
-		Name: info.EffectiveFQDN,
+		Name: subDomain,
 		Type: scwdomain.RecordTypeTXT,
 		Data: scw.StringPtr(fmt.Sprintf(`%q`, info.Value)),
 	}
 
 	req := &scwdomain.UpdateDNSZoneRecordsRequest{
-		DNSZone: info.EffectiveFQDN,
+		DNSZone: zone,
 		Changes: []*scwdomain.RecordChange{{
 			Delete: &scwdomain.RecordChangeDelete{IDFields: recordIdentifier},
 		}},
@@ -170,7 +211,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		DisallowNewZoneCreation: true,
 	}
 
-	_, err := d.client.UpdateDNSZoneRecords(req)
+	_, err = d.client.UpdateDNSZoneRecords(req)
 	if err != nil {
 		return fmt.Errorf("scaleway: %w", err)
 	}
@@ -178,6 +219,91 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	return nil
 }
 
+// findZone returns the name of the DNS zone containing the FQDN, and the name of the record relative to the zone.
+// The zone is the authoritative zone of the FQDN:
+// a zone of a domain registered at Scaleway, or of an external domain (registered elsewhere) using the Scaleway nameservers.
+func (d *DNSProvider) findZone(fqdn string) (string, string, error) {
+	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	if err != nil {
+		return "", "", fmt.Errorf("could not find zone: %w", err)
+	}
+
+	zone, err := d.getZone(dns01.UnFqdn(authZone))
+	if err != nil {
+		return "", "", err
+	}
+
+	zoneName := zone.Domain
+	if zone.Subdomain != "" {
+		zoneName = zone.Subdomain + "." + zone.Domain
+	}
+
+	subDomain, err := dns01.ExtractSubDomain(fqdn, zoneName)
+	if err != nil {
+		return "", "", err
+	}
+
+	return zoneName, subDomain, nil
+}
+
+// getZone gets a DNS zone by name, in the project of the configuration.
+// The project is required to list the zones with a project-scoped API key.
+func (d *DNSProvider) getZone(name string) (*scwdomain.DNSZone, error) {
+	req := &scwdomain.ListDNSZonesRequest{
+		DNSZones: []string{name},
+	}
+
+	if d.config.ProjectID != "" {
+		req.ProjectID = scw.StringPtr(d.config.ProjectID)
+	}
+
+	resp, err := d.client.ListDNSZones(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the DNS zone %s: %w", name, err)
+	}
+
+	for _, zone := range resp.DNSZones {
+		if zone.Domain == name || zone.Subdomain+"."+zone.Domain == name {
+			return zone, nil
+		}
+	}
+
+	if d.config.ProjectID != "" {
+		return nil, fmt.Errorf("zone %s not found in the project %s", name, d.config.ProjectID)
+	}
+
+	return nil, fmt.Errorf("zone %s not found (the project must be defined with a project-scoped API key)", name)
+}
+
+// waitZoneUpdate waits for the changes of the zone to be applied on the Scaleway nameservers:
+// the status of the zone is pending during the update.
+func (d *DNSProvider) waitZoneUpdate(domain, name string) error {
+	return wait.For("scaleway zone update on "+name, d.config.PropagationTimeout, d.config.PollingInterval, func() (bool, error) {
+		zone, err := d.getZone(name)
+		if err != nil {
+			return false, err
+		}
+
+		switch zone.Status {
+		case scwdomain.DNSZoneStatusActive:
+			return true, nil
+
+		case scwdomain.DNSZoneStatusError:
+			message := "unknown error"
+			if zone.Message != nil {
+				message = *zone.Message
+			}
+
+			return false, fmt.Errorf("zone %s: %s", name, message)
+
+		default:
+			log.Infof("[%s] scaleway: the status of the zone %s is %s", domain, name, zone.Status)
+
+			return false, nil
+		}
+	})
+}
+
 func altEnvName(v string) string {
 	return strings.ReplaceAll(v, envNamespace, altEnvNamespace)
 }
@@ -12,14 +12,15 @@ import (
 
 const envDomain = envNamespace + "DOMAIN"
 
-var envTest = tester.NewEnvTest(EnvAPIToken, EnvSecretKey, EnvAccessKey, EnvProjectID).
+var envTest = tester.NewEnvTest(EnvAPIToken, EnvSecretKey, EnvAccessKey, EnvProjectID, altEnvName(EnvProjectID), EnvDefaultProjectID).
 	WithDomain(envDomain)
 
 func TestNewDNSProvider(t *testing.T) {
 	testCases := []struct {
-		desc     string
-		envVars  map[string]string
-		expected string
+		desc              string
+		envVars           map[string]string
+		expectedProjectID string
+		expected          string
 	}{
 		{
 			desc: "success",
@@ -28,6 +29,23 @@ func TestNewDNSProvider(t *testing.T) {
 				EnvProjectID: "",
 			},
 		},
+		{
+			desc: "success with project",
+			envVars: map[string]string{
+				EnvSecretKey:             "00000000-0000-0000-0000-000000000000",
+				altEnvName(EnvProjectID): "11111111-1111-1111-1111-111111111111",
+				EnvDefaultProjectID:      "22222222-2222-2222-2222-222222222222",
+			},
+			expectedProjectID: "11111111-1111-1111-1111-111111111111",
+		},
+		{
+			desc: "success with the default project",
+			envVars: map[string]string{
+				EnvSecretKey:        "00000000-0000-0000-0000-000000000000",
+				EnvDefaultProjectID: "22222222-2222-2222-2222-222222222222",
+			},
+			expectedProjectID: "22222222-2222-2222-2222-222222222222",
+		},
 		{
 			desc: "missing api key",
 			envVars: map[string]string{
@@ -52,6 +70,7 @@ func TestNewDNSProvider(t *testing.T) {
 				require.NotNil(t, p)
 				assert.NotNil(t, p.config)
 				assert.NotNil(t, p.client)
+				assert.Equal(t, test.expectedProjectID, p.config.ProjectID)
 			} else {
 				require.EqualError(t, err, test.expected)
 			}
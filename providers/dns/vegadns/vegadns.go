@@ -36,10 +36,14 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 10),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 12*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 1*time.Minute),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 10),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 12*time.Minute),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 1*time.Minute),
 	}
 }
 
@@ -53,15 +57,21 @@ type DNSProvider struct {
 // Credentials must be passed in the environment variables:
 // VEGADNS_URL, SECRET_VEGADNS_KEY, SECRET_VEGADNS_SECRET.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvURL)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvURL)
 	if err != nil {
 		return nil, fmt.Errorf("vegadns: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.BaseURL = values[EnvURL]
-	config.APIKey = env.GetOrFile(EnvKey)
-	config.APISecret = env.GetOrFile(EnvSecret)
+	config.APIKey = e.GetOrFile(EnvKey)
+	config.APISecret = e.GetOrFile(EnvSecret)
 
 	return NewDNSProviderConfig(config)
 }
@@ -39,12 +39,16 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 60),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 60*time.Second),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 5*time.Second),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 60),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, 60*time.Second),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, 5*time.Second),
 		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
+			Timeout: e.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
 		},
 	}
 }
@@ -61,14 +65,20 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for Vercel.
 // Credentials must be passed in the environment variables: VERCEL_API_TOKEN, VERCEL_TEAM_ID.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvAuthToken)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvAuthToken)
 	if err != nil {
 		return nil, fmt.Errorf("vercel: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.AuthToken = values[EnvAuthToken]
-	config.TeamID = env.GetOrDefaultString(EnvTeamID, "")
+	config.TeamID = e.GetOrDefaultString(EnvTeamID, "")
 
 	return NewDNSProviderConfig(config)
 }
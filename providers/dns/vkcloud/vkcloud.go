@@ -55,10 +55,14 @@ type Config struct {
 
 // NewDefaultConfig returns a default configuration for the DNSProvider.
 func NewDefaultConfig() *Config {
+	return newDefaultConfig(env.Getter{})
+}
+
+func newDefaultConfig(e env.Getter) *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt(EnvTTL, 60),
-		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
-		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+		TTL:                e.GetOrDefaultInt(EnvTTL, 60),
+		PropagationTimeout: e.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    e.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
 	}
 }
 
@@ -70,18 +74,24 @@ type DNSProvider struct {
 
 // NewDNSProvider returns a DNSProvider instance configured for VK Cloud.
 func NewDNSProvider() (*DNSProvider, error) {
-	values, err := env.Get(EnvProjectID, EnvUsername, EnvPassword)
+	return NewDNSProviderFromEnv(env.Getter{})
+}
+
+// NewDNSProviderFromEnv returns a DNSProvider instance configured with the environment variables read by the getter
+// (ex: with a prefix, see env.WithPrefix).
+func NewDNSProviderFromEnv(e env.Getter) (*DNSProvider, error) {
+	values, err := e.Get(EnvProjectID, EnvUsername, EnvPassword)
 	if err != nil {
 		return nil, fmt.Errorf("vkcloud: %w", err)
 	}
 
-	config := NewDefaultConfig()
+	config := newDefaultConfig(e)
 	config.ProjectID = values[EnvProjectID]
 	config.Username = values[EnvUsername]
 	config.Password = values[EnvPassword]
-	config.IdentityEndpoint = env.GetOrDefaultString(EnvIdentityEndpoint, defaultIdentityEndpoint)
-	config.DomainName = env.GetOrDefaultString(EnvDomainName, defaultDomainName)
-	config.DNSEndpoint = env.GetOrDefaultString(EnvDNSEndpoint, defaultDNSEndpoint)
+	config.IdentityEndpoint = e.GetOrDefaultString(EnvIdentityEndpoint, defaultIdentityEndpoint)
+	config.DomainName = e.GetOrDefaultString(EnvDomainName, defaultDomainName)
+	config.DNSEndpoint = e.GetOrDefaultString(EnvDNSEndpoint, defaultDNSEndpoint)
 
 	return NewDNSProviderConfig(config)
 }
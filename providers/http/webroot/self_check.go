@@ -0,0 +1,32 @@
+package webroot
+
+import (
+	"net/http"
+	"time"
+)
+
+// The file is written before the check: the polling only covers the delay of a synchronization (ex: network file system).
+var (
+	selfCheckTimeout  = 10 * time.Second
+	selfCheckInterval = time.Second
+)
+
+// hint returns a hint about the configuration of the web server, based on the status code of the response.
+func hint(statusCode int, alias bool) string {
+	switch {
+	case statusCode == http.StatusNotFound && alias:
+		return "check that the webroot is the target directory of the alias of /.well-known/acme-challenge/"
+	case statusCode == http.StatusNotFound:
+		return "check that the webroot is the document root of the domain;" +
+			" if /.well-known/acme-challenge/ is mapped with an alias (nginx 'alias', Apache 'Alias'), use the alias mode with the target directory of the alias as webroot"
+	case statusCode == http.StatusForbidden:
+		return "check that the web server can read the file (permissions 0644 and 0755 for the directories, SELinux context) and doesn't deny the hidden directories (/.well-known)"
+	case statusCode == http.StatusOK:
+		return "the URL is probably rewritten (ex: Apache 'RewriteRule', front controller of a framework, single-page application fallback):" +
+			" exclude /.well-known/acme-challenge/ from the rewrite rules (ex: 'RewriteRule ^\\.well-known/acme-challenge/ - [L]')"
+	case statusCode >= http.StatusInternalServerError:
+		return "the web server or the application failed: serve /.well-known/acme-challenge/ as static files"
+	default:
+		return ""
+	}
+}
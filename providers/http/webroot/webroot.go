@@ -4,15 +4,33 @@ package webroot
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/pya789/lego/v4/challenge/http01"
+	"github.com/pya789/lego/v4/log"
 )
 
+// tempFilePrefix the prefix of the temporary files, renamed to the token files once written.
+const tempFilePrefix = ".lego-"
+
+// tokenPattern the characters of a token (base64url).
+var tokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 // HTTPProvider implements ChallengeProvider for `http-01` challenge.
 type HTTPProvider struct {
-	path string
+	path        string
+	domainPaths map[string]string
+	alias       bool
+	selfCheck   *http01.SelfChecker
+
+	fileMode os.FileMode
+	uid, gid int
 }
 
 // NewHTTPProvider returns a HTTPProvider instance with a configured webroot path.
@@ -21,33 +39,270 @@ func NewHTTPProvider(path string) (*HTTPProvider, error) {
 		return nil, errors.New("webroot path does not exist")
 	}
 
-	return &HTTPProvider{path: path}, nil
+	return &HTTPProvider{
+		path:        path,
+		domainPaths: map[string]string{},
+		fileMode:    0o644,
+		uid:         -1,
+		gid:         -1,
+	}, nil
+}
+
+// AddDomainPath defines the webroot path of a domain (ex: virtual hosts with different document roots),
+// used instead of the default webroot path.
+func (w *HTTPProvider) AddDomainPath(domain, path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("webroot path of %s does not exist", domain)
+	}
+
+	w.domainPaths[domain] = path
+
+	return nil
+}
+
+// SetAlias defines the webroot paths as the directories of an alias of `/.well-known/acme-challenge/`
+// (ex: nginx `alias`, Apache `Alias`): the files are written directly in the directories.
+func (w *HTTPProvider) SetAlias(alias bool) {
+	w.alias = alias
+}
+
+// SetFileMode defines the permissions of the challenge files (default: 0644).
+func (w *HTTPProvider) SetFileMode(mode os.FileMode) {
+	w.fileMode = mode.Perm()
+}
+
+// SetOwner defines the owner of the challenge files and of the directories created for them
+// (ex: the user of the web server on a shared hosting).
+// A negative uid or gid is not changed (see os.Chown), the change of owner is not supported on Windows.
+func (w *HTTPProvider) SetOwner(uid, gid int) {
+	w.uid = uid
+	w.gid = gid
+}
+
+// SetSelfCheck verifies that the file is served by the web server, before notifying the CA.
+// The request is sent to the address (ex: 127.0.0.1:80) with the domain as Host header,
+// or to the domain if the address is empty.
+// When the check fails, the error contains a hint about the configuration of the web server.
+func (w *HTTPProvider) SetSelfCheck(address string) {
+	w.selfCheck = http01.NewSelfChecker(&http.Client{Timeout: 10 * time.Second}, address, selfCheckTimeout, selfCheckInterval)
 }
 
 // Present makes the token available at `HTTP01ChallengePath(token)` by creating a file in the given webroot path.
 func (w *HTTPProvider) Present(domain, token, keyAuth string) error {
 	var err error
 
-	challengeFilePath := filepath.Join(w.path, http01.ChallengePath(token))
-	err = os.MkdirAll(filepath.Dir(challengeFilePath), 0o755)
+	challengeFilePath := w.challengeFilePath(domain, token)
+	err = w.mkdirAll(filepath.Dir(challengeFilePath))
 	if err != nil {
 		return fmt.Errorf("could not create required directories in webroot for HTTP challenge: %w", err)
 	}
 
-	err = os.WriteFile(challengeFilePath, []byte(keyAuth), 0o644)
+	err = w.writeFile(challengeFilePath, []byte(keyAuth))
 	if err != nil {
 		return fmt.Errorf("could not write file in webroot for HTTP challenge: %w", err)
 	}
 
+	if w.selfCheck != nil {
+		err = w.selfCheck.Check(domain, token, keyAuth)
+		if err != nil {
+			var selfCheckErr *http01.SelfCheckError
+			if errors.As(err, &selfCheckErr) {
+				if h := hint(selfCheckErr.StatusCode, w.alias); h != "" {
+					err = fmt.Errorf("%w (hint: %s)", err, h)
+				}
+			}
+
+			return fmt.Errorf("the file %s is not served: %w", challengeFilePath, err)
+		}
+	}
+
 	return nil
 }
 
 // CleanUp removes the file created for the challenge.
 func (w *HTTPProvider) CleanUp(domain, token, keyAuth string) error {
-	err := os.Remove(filepath.Join(w.path, http01.ChallengePath(token)))
+	err := os.Remove(w.challengeFilePath(domain, token))
 	if err != nil {
 		return fmt.Errorf("could not remove file in webroot after HTTP challenge: %w", err)
 	}
 
 	return nil
 }
+
+// RemoveStale removes the challenge files older than maxAge from the challenge directories of the webroot paths
+// (ex: files left by an interrupted run), and returns the number of removed files.
+// Only the files named like a token, and the temporary files of the provider, are removed.
+func (w *HTTPProvider) RemoveStale(maxAge time.Duration) (int, error) {
+	limit := time.Now().Add(-maxAge)
+
+	var count int
+
+	var errs []error
+
+	for _, dir := range w.challengeDirs() {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || !isChallengeFile(entry.Name()) {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil || !info.ModTime().Before(limit) {
+				continue
+			}
+
+			err = os.Remove(filepath.Join(dir, entry.Name()))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+				continue
+			}
+
+			log.Debugf("webroot: stale challenge file removed: %s", filepath.Join(dir, entry.Name()))
+
+			count++
+		}
+	}
+
+	if len(errs) > 0 {
+		return count, fmt.Errorf("could not remove stale files in webroot: %w", errors.Join(errs...))
+	}
+
+	return count, nil
+}
+
+func (w *HTTPProvider) challengeFilePath(domain, token string) string {
+	return filepath.Join(w.challengeDir(w.rootPath(domain)), token)
+}
+
+func (w *HTTPProvider) rootPath(domain string) string {
+	if path, ok := w.domainPaths[domain]; ok {
+		return path
+	}
+
+	return w.path
+}
+
+func (w *HTTPProvider) challengeDir(root string) string {
+	if w.alias {
+		return root
+	}
+
+	return filepath.Join(root, filepath.Dir(http01.ChallengePath("token")))
+}
+
+// challengeDirs returns the challenge directories of all the webroot paths, without duplicates.
+func (w *HTTPProvider) challengeDirs() []string {
+	seen := map[string]struct{}{}
+
+	var dirs []string
+
+	for _, root := range append([]string{w.path}, mapValues(w.domainPaths)...) {
+		dir := filepath.Clean(w.challengeDir(root))
+		if _, ok := seen[dir]; ok {
+			continue
+		}
+
+		seen[dir] = struct{}{}
+		dirs = append(dirs, dir)
+	}
+
+	return dirs
+}
+
+// mkdirAll creates the directory and its missing parents, and changes the owner of the created directories.
+func (w *HTTPProvider) mkdirAll(dir string) error {
+	var created []string
+
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || !errors.Is(err, fs.ErrNotExist) || filepath.Dir(d) == d {
+			break
+		}
+
+		created = append(created, d)
+	}
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range created {
+		err = w.chown(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes the file atomically: the content is written in a temporary file of the same directory,
+// then the temporary file is renamed, the web server never reads a partial file.
+func (w *HTTPProvider) writeFile(filename string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(filename), tempFilePrefix+"*")
+	if err != nil {
+		return err
+	}
+
+	tmpName := file.Name()
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpName)
+		}
+	}()
+
+	_, err = file.Write(data)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	// CreateTemp uses the mode 0600.
+	err = os.Chmod(tmpName, w.fileMode)
+	if err != nil {
+		return err
+	}
+
+	err = w.chown(tmpName)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, filename)
+}
+
+func (w *HTTPProvider) chown(name string) error {
+	if w.uid < 0 && w.gid < 0 {
+		return nil
+	}
+
+	return os.Chown(name, w.uid, w.gid)
+}
+
+func isChallengeFile(name string) bool {
+	return strings.HasPrefix(name, tempFilePrefix) || tokenPattern.MatchString(name)
+}
+
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+
+	return values
+}
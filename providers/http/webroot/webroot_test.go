@@ -1,8 +1,12 @@
 package webroot
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,3 +42,150 @@ func TestHTTPProvider(t *testing.T) {
 	err = provider.CleanUp(domain, token, keyAuth)
 	require.NoError(t, err)
 }
+
+func TestHTTPProvider_domainPaths(t *testing.T) {
+	webroot := t.TempDir()
+	other := t.TempDir()
+
+	provider, err := NewHTTPProvider(webroot)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.AddDomainPath("other.example.com", other))
+	require.Error(t, provider.AddDomainPath("missing.example.com", filepath.Join(other, "missing")))
+
+	require.NoError(t, provider.Present("example.com", "token1", "keyAuth1"))
+	assert.FileExists(t, filepath.Join(webroot, ".well-known", "acme-challenge", "token1"))
+
+	require.NoError(t, provider.Present("other.example.com", "token2", "keyAuth2"))
+	assert.FileExists(t, filepath.Join(other, ".well-known", "acme-challenge", "token2"))
+
+	provider.SetAlias(true)
+
+	require.NoError(t, provider.Present("other.example.com", "token3", "keyAuth3"))
+	assert.FileExists(t, filepath.Join(other, "token3"))
+
+	require.NoError(t, provider.CleanUp("other.example.com", "token3", "keyAuth3"))
+	assert.NoFileExists(t, filepath.Join(other, "token3"))
+}
+
+func TestHTTPProvider_SetSelfCheck(t *testing.T) {
+	webroot := t.TempDir()
+
+	timeout, interval := selfCheckTimeout, selfCheckInterval
+	selfCheckTimeout, selfCheckInterval = 50*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { selfCheckTimeout, selfCheckInterval = timeout, interval })
+
+	testCases := []struct {
+		desc     string
+		handler  http.Handler
+		expected string
+	}{
+		{
+			desc:    "served",
+			handler: http.StripPrefix("/", http.FileServer(http.Dir(webroot))),
+		},
+		{
+			desc:     "not found",
+			handler:  http.NotFoundHandler(),
+			expected: "self-check: unexpected status code: 404 (hint: check that the webroot is the document root of the domain;",
+		},
+		{
+			desc: "rewritten",
+			handler: http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				_, _ = rw.Write([]byte("<html></html>"))
+			}),
+			expected: "self-check: the response doesn't match the key authorization (hint: the URL is probably rewritten",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var host string
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				host = req.Host
+				test.handler.ServeHTTP(rw, req)
+			}))
+			t.Cleanup(server.Close)
+
+			provider, err := NewHTTPProvider(webroot)
+			require.NoError(t, err)
+
+			provider.SetSelfCheck(server.Listener.Addr().String())
+
+			err = provider.Present("example.com", "token", "keyAuth")
+			if test.expected != "" {
+				require.ErrorContains(t, err, test.expected)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, "example.com", host)
+		})
+	}
+}
+
+func TestHTTPProvider_SetFileMode(t *testing.T) {
+	webroot := t.TempDir()
+
+	provider, err := NewHTTPProvider(webroot)
+	require.NoError(t, err)
+
+	provider.SetFileMode(0o640)
+
+	require.NoError(t, provider.Present("example.com", "token", "keyAuth"))
+
+	info, err := os.Stat(filepath.Join(webroot, ".well-known", "acme-challenge", "token"))
+	require.NoError(t, err)
+
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	// no temporary file left.
+	entries, err := os.ReadDir(filepath.Join(webroot, ".well-known", "acme-challenge"))
+	require.NoError(t, err)
+
+	require.Len(t, entries, 1)
+	assert.Equal(t, "token", entries[0].Name())
+}
+
+func TestHTTPProvider_RemoveStale(t *testing.T) {
+	webroot := t.TempDir()
+	other := t.TempDir()
+
+	provider, err := NewHTTPProvider(webroot)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.AddDomainPath("other.example.com", other))
+
+	require.NoError(t, provider.Present("example.com", "stale1", "keyAuth"))
+	require.NoError(t, provider.Present("example.com", "recent", "keyAuth"))
+	require.NoError(t, provider.Present("other.example.com", "stale2", "keyAuth"))
+
+	dir := filepath.Join(webroot, ".well-known", "acme-challenge")
+	otherDir := filepath.Join(other, ".well-known", "acme-challenge")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".lego-123"), []byte("partial"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0o600))
+
+	old := time.Now().Add(-48 * time.Hour)
+
+	for _, name := range []string{
+		filepath.Join(dir, "stale1"),
+		filepath.Join(dir, ".lego-123"),
+		filepath.Join(dir, "index.html"),
+		filepath.Join(otherDir, "stale2"),
+	} {
+		require.NoError(t, os.Chtimes(name, old, old))
+	}
+
+	count, err := provider.RemoveStale(24 * time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, count)
+
+	assert.NoFileExists(t, filepath.Join(dir, "stale1"))
+	assert.NoFileExists(t, filepath.Join(dir, ".lego-123"))
+	assert.NoFileExists(t, filepath.Join(otherDir, "stale2"))
+	assert.FileExists(t, filepath.Join(dir, "recent"))
+	assert.FileExists(t, filepath.Join(dir, "index.html"))
+}
@@ -0,0 +1,132 @@
+package registration
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// telNumber matches a global phone number (RFC 3966), with optional visual separators.
+var telNumber = regexp.MustCompile(`^\+[0-9][0-9().-]*[0-9]$`)
+
+// ContactPolicy the contact URLs accepted by a CA.
+type ContactPolicy struct {
+	// Schemes the accepted schemes (ex: "mailto", "tel").
+	Schemes []string
+	// MaxContacts the maximum number of contacts (0 for unlimited).
+	MaxContacts int
+}
+
+// contactPolicies the CA-specific policies, indexed by the domain of the CA.
+var contactPolicies = map[string]ContactPolicy{
+	// Boulder only supports the mailto scheme.
+	"letsencrypt.org": {Schemes: []string{"mailto"}, MaxContacts: 10},
+	"buypass.com":     {Schemes: []string{"mailto"}},
+	"buypass.no":      {Schemes: []string{"mailto"}},
+	"zerossl.com":     {Schemes: []string{"mailto"}},
+	"pki.goog":        {Schemes: []string{"mailto"}},
+}
+
+// DefaultContactPolicy the policy used when the CA is unknown (RFC 8555 section 7.3).
+var DefaultContactPolicy = ContactPolicy{Schemes: []string{"mailto", "tel"}}
+
+// ContactPolicyFor returns the contact policy of the CA of an ACME URL (ex: the newAccount URL).
+func ContactPolicyFor(acmeURL string) ContactPolicy {
+	u, err := url.Parse(acmeURL)
+	if err != nil {
+		return DefaultContactPolicy
+	}
+
+	host := u.Hostname()
+
+	for domain, policy := range contactPolicies {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return policy
+		}
+	}
+
+	return DefaultContactPolicy
+}
+
+// NormalizeContact returns the contact as a URL: an email address without scheme is prefixed with "mailto:".
+func NormalizeContact(contact string) string {
+	contact = strings.TrimSpace(contact)
+
+	if !strings.Contains(contact, ":") && strings.Contains(contact, "@") {
+		return mailTo + contact
+	}
+
+	return contact
+}
+
+// ValidateContacts validates the contact URLs against the policy of the CA.
+func ValidateContacts(contacts []string, policy ContactPolicy) error {
+	if policy.MaxContacts > 0 && len(contacts) > policy.MaxContacts {
+		return fmt.Errorf("too many contacts: %d (max %d)", len(contacts), policy.MaxContacts)
+	}
+
+	var errAll error
+
+	for _, contact := range contacts {
+		scheme, value, ok := strings.Cut(contact, ":")
+		if !ok {
+			errAll = errors.Join(errAll, fmt.Errorf("invalid contact %q: missing scheme", contact))
+			continue
+		}
+
+		scheme = strings.ToLower(scheme)
+
+		if !slices.Contains(policy.Schemes, scheme) {
+			errAll = errors.Join(errAll, fmt.Errorf("invalid contact %q: unsupported scheme %q by the CA (supported: %s)",
+				contact, scheme, strings.Join(policy.Schemes, ", ")))
+			continue
+		}
+
+		if err := validateContactValue(scheme, value); err != nil {
+			errAll = errors.Join(errAll, fmt.Errorf("invalid contact %q: %w", contact, err))
+		}
+	}
+
+	return errAll
+}
+
+func validateContactValue(scheme, value string) error {
+	switch scheme {
+	case "mailto":
+		// RFC 8555 section 7.3: the mailto URL must not contain hfields nor more than one addr-spec.
+		if strings.ContainsAny(value, "?,") {
+			return errors.New("only one email address without header fields is allowed")
+		}
+
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Name != "" || addr.Address != value {
+			return errors.New("invalid email address")
+		}
+
+	case "tel":
+		if !telNumber.MatchString(value) {
+			return errors.New("a global phone number is expected (ex: tel:+1-201-555-0123)")
+		}
+	}
+
+	return nil
+}
+
+// sameContacts returns true if both lists contain the same contacts (regardless of the order).
+func sameContacts(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, c := range a {
+		if !slices.Contains(b, c) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,91 @@
+package registration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactPolicyFor(t *testing.T) {
+	assert.Equal(t, []string{"mailto"}, ContactPolicyFor("https://acme-v02.api.letsencrypt.org/acme/new-acct").Schemes)
+	assert.Equal(t, []string{"mailto"}, ContactPolicyFor("https://acme-staging-v02.api.letsencrypt.org/acme/new-acct").Schemes)
+	assert.Equal(t, DefaultContactPolicy, ContactPolicyFor("https://ca.example.com/acme/new-account"))
+	assert.Equal(t, DefaultContactPolicy, ContactPolicyFor(""))
+}
+
+func TestNormalizeContact(t *testing.T) {
+	assert.Equal(t, "mailto:admin@example.com", NormalizeContact(" admin@example.com "))
+	assert.Equal(t, "mailto:admin@example.com", NormalizeContact("mailto:admin@example.com"))
+	assert.Equal(t, "tel:+1-201-555-0123", NormalizeContact("tel:+1-201-555-0123"))
+}
+
+func TestValidateContacts(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		contacts []string
+		policy   ContactPolicy
+		expected string
+	}{
+		{
+			desc:     "valid",
+			contacts: []string{"mailto:admin@example.com", "tel:+1-201-555-0123", "tel:+33.1.23.45.67.89"},
+			policy:   DefaultContactPolicy,
+		},
+		{
+			desc:     "missing scheme",
+			contacts: []string{"admin"},
+			policy:   DefaultContactPolicy,
+			expected: `invalid contact "admin": missing scheme`,
+		},
+		{
+			desc:     "unsupported scheme by the CA",
+			contacts: []string{"tel:+1-201-555-0123"},
+			policy:   ContactPolicy{Schemes: []string{"mailto"}},
+			expected: `invalid contact "tel:+1-201-555-0123": unsupported scheme "tel" by the CA (supported: mailto)`,
+		},
+		{
+			desc:     "multiple addresses",
+			contacts: []string{"mailto:a@example.com,b@example.com"},
+			policy:   DefaultContactPolicy,
+			expected: `invalid contact "mailto:a@example.com,b@example.com": only one email address without header fields is allowed`,
+		},
+		{
+			desc:     "hfields",
+			contacts: []string{"mailto:a@example.com?subject=foo"},
+			policy:   DefaultContactPolicy,
+			expected: `invalid contact "mailto:a@example.com?subject=foo": only one email address without header fields is allowed`,
+		},
+		{
+			desc:     "invalid email",
+			contacts: []string{"mailto:John <john@example.com>"},
+			policy:   DefaultContactPolicy,
+			expected: `invalid contact "mailto:John <john@example.com>": invalid email address`,
+		},
+		{
+			desc:     "local phone number",
+			contacts: []string{"tel:555-0123"},
+			policy:   DefaultContactPolicy,
+			expected: `invalid contact "tel:555-0123": a global phone number is expected (ex: tel:+1-201-555-0123)`,
+		},
+		{
+			desc:     "too many contacts",
+			contacts: []string{"mailto:a@example.com", "mailto:b@example.com"},
+			policy:   ContactPolicy{Schemes: []string{"mailto"}, MaxContacts: 1},
+			expected: "too many contacts: 2 (max 1)",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateContacts(test.contacts, test.policy)
+			if test.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
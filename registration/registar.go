@@ -1,8 +1,12 @@
 package registration
 
 import (
+	"crypto"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/pya789/lego/v4/acme"
 	"github.com/pya789/lego/v4/acme/api"
@@ -21,12 +25,23 @@ type Resource struct {
 
 type RegisterOptions struct {
 	TermsOfServiceAgreed bool
+	// Contact additional contact URLs (ex: "mailto:admin@example.com", "tel:+1-201-555-0123").
+	Contact []string
 }
 
 type RegisterEABOptions struct {
 	TermsOfServiceAgreed bool
 	Kid                  string
 	HmacEncoded          string
+	// Contact additional contact URLs (ex: "mailto:admin@example.com", "tel:+1-201-555-0123").
+	Contact []string
+}
+
+type RotateEABOptions struct {
+	// NewKey the new key of the account.
+	NewKey      crypto.PrivateKey
+	Kid         string
+	HmacEncoded string
 }
 
 type Registrar struct {
@@ -47,14 +62,14 @@ func (r *Registrar) Register(options RegisterOptions) (*Resource, error) {
 		return nil, errors.New("acme: cannot register a nil client or user")
 	}
 
-	accMsg := acme.Account{
-		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
-		Contact:              []string{},
+	contact, err := r.validContact(options.Contact)
+	if err != nil {
+		return nil, err
 	}
 
-	if r.user.GetEmail() != "" {
-		log.Infof("acme: Registering account for %s", r.user.GetEmail())
-		accMsg.Contact = []string{mailTo + r.user.GetEmail()}
+	accMsg := acme.Account{
+		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
+		Contact:              contact,
 	}
 
 	account, err := r.core.Accounts.New(accMsg)
@@ -71,14 +86,14 @@ func (r *Registrar) Register(options RegisterOptions) (*Resource, error) {
 
 // RegisterWithExternalAccountBinding Register the current account to the ACME server.
 func (r *Registrar) RegisterWithExternalAccountBinding(options RegisterEABOptions) (*Resource, error) {
-	accMsg := acme.Account{
-		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
-		Contact:              []string{},
+	contact, err := r.validContact(options.Contact)
+	if err != nil {
+		return nil, err
 	}
 
-	if r.user.GetEmail() != "" {
-		log.Infof("acme: Registering account for %s", r.user.GetEmail())
-		accMsg.Contact = []string{mailTo + r.user.GetEmail()}
+	accMsg := acme.Account{
+		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
+		Contact:              contact,
 	}
 
 	account, err := r.core.Accounts.NewEAB(accMsg, options.Kid, options.HmacEncoded)
@@ -93,6 +108,60 @@ func (r *Registrar) RegisterWithExternalAccountBinding(options RegisterEABOption
 	return &Resource{URI: account.Location, Body: account.Account}, nil
 }
 
+// KeyRollover replaces the key of the current account by a new key.
+// The new key must be stored by the caller: the old key is no longer associated with the account.
+func (r *Registrar) KeyRollover(newKey crypto.PrivateKey) error {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return errors.New("acme: cannot change the key of a nil client or an unregistered user")
+	}
+
+	if newKey == nil {
+		return errors.New("acme: the new account key is missing")
+	}
+
+	log.Infof("acme: Changing the key of the account %s", r.user.GetRegistration().URI)
+
+	return r.core.Accounts.KeyChange(r.user.GetRegistration().URI, newKey)
+}
+
+// BindExternalAccount binds the key of the current account to External Account Binding credentials.
+func (r *Registrar) BindExternalAccount(kid, hmacEncoded string) (*Resource, error) {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return nil, errors.New("acme: cannot bind a nil client or an unregistered user")
+	}
+
+	if kid == "" || hmacEncoded == "" {
+		return nil, errors.New("acme: the External Account Binding key identifier and MAC key are required")
+	}
+
+	accountURL := r.user.GetRegistration().URI
+
+	log.Infof("acme: Binding the account %s to the external account %s", accountURL, kid)
+
+	account, err := r.core.Accounts.BindEAB(accountURL, kid, hmacEncoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Location != "" && account.Location != accountURL {
+		return nil, fmt.Errorf("acme: the server returned another account (%s) than the current account (%s)", account.Location, accountURL)
+	}
+
+	return &Resource{URI: accountURL, Body: account.Account}, nil
+}
+
+// RotateExternalAccountBinding re-binds the current account to new External Account Binding credentials:
+// the account key is replaced by the new key (key rollover), then the new key is bound to the new credentials.
+// The new key must be stored by the caller as soon as the key rollover succeeds, even if the binding fails.
+func (r *Registrar) RotateExternalAccountBinding(options RotateEABOptions) (*Resource, error) {
+	err := r.KeyRollover(options.NewKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.BindExternalAccount(options.Kid, options.HmacEncoded)
+}
+
 // QueryRegistration runs a POST request on the client's registration and returns the result.
 //
 // This is similar to the Register function,
@@ -123,14 +192,14 @@ func (r *Registrar) UpdateRegistration(options RegisterOptions) (*Resource, erro
 		return nil, errors.New("acme: cannot update a nil client or user")
 	}
 
-	accMsg := acme.Account{
-		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
-		Contact:              []string{},
+	contact, err := r.validContact(options.Contact)
+	if err != nil {
+		return nil, err
 	}
 
-	if r.user.GetEmail() != "" {
-		log.Infof("acme: Registering account for %s", r.user.GetEmail())
-		accMsg.Contact = []string{mailTo + r.user.GetEmail()}
+	accMsg := acme.Account{
+		TermsOfServiceAgreed: options.TermsOfServiceAgreed,
+		Contact:              contact,
 	}
 
 	accountURL := r.user.GetRegistration().URI
@@ -143,6 +212,35 @@ func (r *Registrar) UpdateRegistration(options RegisterOptions) (*Resource, erro
 	return &Resource{URI: accountURL, Body: account}, nil
 }
 
+// UpdateContacts updates the contacts of the account on the ACME server,
+// only if they differ from the contacts of the current registration.
+// Returns a nil resource if the contacts are unchanged.
+func (r *Registrar) UpdateContacts(additional []string) (*Resource, error) {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return nil, errors.New("acme: cannot update the contacts of a nil client or an unregistered user")
+	}
+
+	contact, err := r.validContact(additional)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := r.user.GetRegistration()
+
+	if sameContacts(contact, reg.Body.Contact) {
+		return nil, nil
+	}
+
+	log.Infof("acme: Updating the contacts of the account %s: %s", reg.URI, strings.Join(contact, ", "))
+
+	account, err := r.core.Accounts.Update(reg.URI, acme.Account{Contact: contact})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Resource{URI: reg.URI, Body: account}, nil
+}
+
 // DeleteRegistration deletes the client's user registration from the ACME server.
 func (r *Registrar) DeleteRegistration() error {
 	if r == nil || r.user == nil {
@@ -154,6 +252,54 @@ func (r *Registrar) DeleteRegistration() error {
 	return r.core.Accounts.Deactivate(r.user.GetRegistration().URI)
 }
 
+// SignPayload signs a payload with the account key,
+// and returns the JWS (flattened JSON serialization) to send to the given URL.
+// It can be used with CA-specific endpoints not covered by RFC 8555 (ex: certificate ownership proofs).
+func (r *Registrar) SignPayload(url string, payload []byte) ([]byte, error) {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return nil, errors.New("acme: cannot sign a payload with a nil client or an unregistered user")
+	}
+
+	return r.core.SignContent(url, payload)
+}
+
+// getContact returns the contact URLs of the user:
+// the email address, the additional contacts from the user defaults, and the additional contacts of the options.
+func (r *Registrar) getContact(additional []string) []string {
+	contact := []string{}
+
+	if r.user.GetEmail() != "" {
+		log.Infof("acme: Registering account for %s", r.user.GetEmail())
+		contact = append(contact, mailTo+r.user.GetEmail())
+	}
+
+	var extra []string
+	if defaults := getDefaults(r.user); defaults != nil {
+		extra = append(extra, defaults.Contact...)
+	}
+
+	for _, c := range append(extra, additional...) {
+		c = NormalizeContact(c)
+		if c != "" && !slices.Contains(contact, c) {
+			contact = append(contact, c)
+		}
+	}
+
+	return contact
+}
+
+// validContact returns the contact URLs of the user, validated against the contact policy of the CA.
+func (r *Registrar) validContact(additional []string) ([]string, error) {
+	contact := r.getContact(additional)
+
+	err := ValidateContacts(contact, ContactPolicyFor(r.core.GetDirectory().NewAccountURL))
+	if err != nil {
+		return nil, fmt.Errorf("acme: %w", err)
+	}
+
+	return contact, nil
+}
+
 // ResolveAccountByKey will attempt to look up an account using the given account key
 // and return its registration resource.
 func (r *Registrar) ResolveAccountByKey() (*Resource, error) {
@@ -1,11 +1,16 @@
 package registration
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/json"
+	"io"
 	"net/http"
 	"testing"
 
+	jose "github.com/go-jose/go-jose/v4"
 	"github.com/pya789/lego/v4/acme"
 	"github.com/pya789/lego/v4/acme/api"
 	"github.com/pya789/lego/v4/platform/tester"
@@ -46,3 +51,282 @@ func TestRegistrar_ResolveAccountByKey(t *testing.T) {
 
 	assert.Equal(t, "valid", res.Body.Status, "Unexpected account status")
 }
+
+func TestRegistrar_SignPayload(t *testing.T) {
+	_, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: apiURL + "/account"},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", user.regres.URI, key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	signed, err := registrar.SignPayload(apiURL+"/proof", []byte(`{"foo":"bar"}`))
+	require.NoError(t, err)
+
+	jws, err := jose.ParseSigned(string(signed), []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(t, err)
+
+	require.Len(t, jws.Signatures, 1)
+
+	header := jws.Signatures[0].Protected
+	assert.Equal(t, apiURL+"/account", header.KeyID)
+	assert.Equal(t, apiURL+"/proof", header.ExtraHeaders["url"])
+	assert.NotEmpty(t, header.Nonce)
+
+	payload, err := jws.Verify(key.Public())
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"foo":"bar"}`, string(payload))
+}
+
+func TestRegistrar_SignPayload_unregistered(t *testing.T) {
+	registrar := NewRegistrar(nil, mockUser{})
+
+	_, err := registrar.SignPayload("https://example.com", nil)
+	require.Error(t, err)
+}
+
+func TestRegistrar_getContact(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		user       User
+		additional []string
+		expected   []string
+	}{
+		{
+			desc:     "no email",
+			user:     mockUser{},
+			expected: []string{},
+		},
+		{
+			desc:     "email",
+			user:     mockUser{email: "test@test.com"},
+			expected: []string{"mailto:test@test.com"},
+		},
+		{
+			desc: "email and defaults",
+			user: mockUserWithDefaults{
+				mockUser: mockUser{email: "test@test.com"},
+				defaults: &Defaults{Contact: []string{"mailto:test@test.com", "mailto:admin@test.com"}},
+			},
+			expected: []string{"mailto:test@test.com", "mailto:admin@test.com"},
+		},
+		{
+			desc: "additional contacts",
+			user: mockUserWithDefaults{
+				mockUser: mockUser{email: "test@test.com"},
+				defaults: &Defaults{Contact: []string{"mailto:admin@test.com"}},
+			},
+			additional: []string{"ops@test.com", "tel:+1-201-555-0123", "mailto:admin@test.com"},
+			expected:   []string{"mailto:test@test.com", "mailto:admin@test.com", "mailto:ops@test.com", "tel:+1-201-555-0123"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			registrar := NewRegistrar(nil, test.user)
+
+			assert.Equal(t, test.expected, registrar.getContact(test.additional))
+		})
+	}
+}
+
+func TestRegistrar_UpdateContacts(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	var updates int
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, _ *http.Request) {
+		updates++
+
+		err := tester.WriteJSONResponse(w, acme.Account{
+			Status:  "valid",
+			Contact: []string{"mailto:test@test.com", "tel:+1-201-555-0123"},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := mockUser{
+		email: "test@test.com",
+		regres: &Resource{
+			URI:  apiURL + "/account",
+			Body: acme.Account{Contact: []string{"mailto:test@test.com"}},
+		},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", user.regres.URI, key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	// Unchanged.
+	res, err := registrar.UpdateContacts(nil)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+	assert.Equal(t, 0, updates)
+
+	res, err = registrar.UpdateContacts([]string{"tel:+1-201-555-0123"})
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, 1, updates)
+	assert.Equal(t, []string{"mailto:test@test.com", "tel:+1-201-555-0123"}, res.Body.Contact)
+
+	_, err = registrar.UpdateContacts([]string{"tel:555"})
+	require.EqualError(t, err, `acme: invalid contact "tel:555": a global phone number is expected (ex: tel:+1-201-555-0123)`)
+	assert.Equal(t, 1, updates)
+}
+
+func TestRegistrar_RotateExternalAccountBinding(t *testing.T) {
+	mux, apiURL := tester.SetupFakeAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Could not generate test key")
+
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "Could not generate test key")
+
+	algorithms := []jose.SignatureAlgorithm{jose.RS256, jose.ES256, jose.HS256}
+
+	var keyChanged, bound bool
+
+	mux.HandleFunc("/keyChange", func(w http.ResponseWriter, r *http.Request) {
+		outer, err := readJWS(r, algorithms)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		content, err := outer.Verify(key.Public())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		inner, err := jose.ParseSigned(string(content), algorithms)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		payload, err := inner.Verify(newKey.Public())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var keyChange struct {
+			Account string          `json:"account"`
+			OldKey  jose.JSONWebKey `json:"oldKey"`
+		}
+
+		err = json.Unmarshal(payload, &keyChange)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if keyChange.Account != apiURL+"/account" || !keyChange.OldKey.Valid() || inner.Signatures[0].Protected.Nonce != "" {
+			http.Error(w, "invalid key change", http.StatusBadRequest)
+			return
+		}
+
+		keyChanged = true
+	})
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		jws, err := readJWS(r, algorithms)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// newAccount requests are signed with the JWK of the new key.
+		jwk := jws.Signatures[0].Protected.JSONWebKey
+		if jwk == nil || jws.Signatures[0].Protected.KeyID != "" {
+			http.Error(w, "missing JWK", http.StatusBadRequest)
+			return
+		}
+
+		payload, err := jws.Verify(newKey.Public())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var account acme.Account
+
+		err = json.Unmarshal(payload, &account)
+		if err != nil || len(account.ExternalAccountBinding) == 0 {
+			http.Error(w, "missing EAB", http.StatusBadRequest)
+			return
+		}
+
+		bound = true
+
+		w.Header().Set("Location", apiURL+"/account")
+
+		err = tester.WriteJSONResponse(w, acme.Account{Status: "valid"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: apiURL + "/account"},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", user.regres.URI, key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	res, err := registrar.RotateExternalAccountBinding(RotateEABOptions{
+		NewKey:      newKey,
+		Kid:         "kid-2",
+		HmacEncoded: "YWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWE",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, keyChanged)
+	assert.True(t, bound)
+
+	assert.Equal(t, apiURL+"/account", res.URI)
+	assert.Equal(t, "valid", res.Body.Status)
+}
+
+func TestRegistrar_KeyRollover_unregistered(t *testing.T) {
+	registrar := NewRegistrar(nil, mockUser{})
+
+	err := registrar.KeyRollover(nil)
+	require.Error(t, err)
+}
+
+func readJWS(r *http.Request, algorithms []jose.SignatureAlgorithm) (*jose.JSONWebSignature, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return jose.ParseSigned(string(body), algorithms)
+}
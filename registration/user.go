@@ -2,6 +2,8 @@ package registration
 
 import (
 	"crypto"
+
+	"github.com/pya789/lego/v4/certcrypto"
 )
 
 // User interface is to be implemented by users of this library.
@@ -11,3 +13,31 @@ type User interface {
 	GetRegistration() *Resource
 	GetPrivateKey() crypto.PrivateKey
 }
+
+// UserWithDefaults is an optional interface for users with default settings.
+// The defaults are applied by the client when the configuration is created.
+type UserWithDefaults interface {
+	User
+	GetDefaults() *Defaults
+}
+
+// Defaults the default settings of an account.
+// They are intended to be persisted with the account data,
+// so that multiple tools sharing an account behave consistently.
+type Defaults struct {
+	// KeyType the type of the private keys of the certificates.
+	KeyType certcrypto.KeyType `json:"keyType,omitempty"`
+	// PreferredChain the preferred certificate chain (issuer Common Name).
+	PreferredChain string `json:"preferredChain,omitempty"`
+	// Contact additional contact URLs (ex: "mailto:admin@example.com").
+	Contact []string `json:"contact,omitempty"`
+}
+
+func getDefaults(user User) *Defaults {
+	u, ok := user.(UserWithDefaults)
+	if !ok {
+		return nil
+	}
+
+	return u.GetDefaults()
+}
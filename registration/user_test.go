@@ -14,3 +14,10 @@ type mockUser struct {
 func (u mockUser) GetEmail() string                 { return u.email }
 func (u mockUser) GetRegistration() *Resource       { return u.regres }
 func (u mockUser) GetPrivateKey() crypto.PrivateKey { return u.privatekey }
+
+type mockUserWithDefaults struct {
+	mockUser
+	defaults *Defaults
+}
+
+func (u mockUserWithDefaults) GetDefaults() *Defaults { return u.defaults }
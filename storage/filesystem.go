@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	dirPerm  os.FileMode = 0o700
+	filePerm os.FileMode = 0o600
+)
+
+// FileSystem stores the data in files, the keys are the paths of the files relative to the root directory.
+type FileSystem struct {
+	root string
+}
+
+// NewFileSystem creates a FileSystem storage in the root directory.
+func NewFileSystem(root string) *FileSystem {
+	return &FileSystem{root: root}
+}
+
+// Root returns the root directory.
+func (s *FileSystem) Root() string {
+	return s.root
+}
+
+// Path returns the path of the file of the key.
+func (s *FileSystem) Path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+func (s *FileSystem) Get(_ context.Context, key string) ([]byte, error) {
+	return os.ReadFile(s.Path(key))
+}
+
+// Put writes the file of the key, the missing directories are created.
+func (s *FileSystem) Put(_ context.Context, key string, data []byte) error {
+	filename := s.Path(key)
+
+	err := os.MkdirAll(filepath.Dir(filename), dirPerm)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, filePerm)
+}
+
+func (s *FileSystem) Delete(_ context.Context, key string) error {
+	err := os.Remove(s.Path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileSystem) List(_ context.Context, prefix string) ([]string, error) {
+	// Only the directory of the prefix is walked.
+	dir := path.Dir(prefix)
+	if strings.HasSuffix(prefix, "/") {
+		dir = strings.TrimSuffix(prefix, "/")
+	}
+
+	var keys []string
+
+	err := filepath.WalkDir(s.Path(dir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
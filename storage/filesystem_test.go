@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSystem(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	store := NewFileSystem(root)
+
+	_, err := store.Get(ctx, "certificates/example.com.crt")
+	require.ErrorIs(t, err, ErrNotExist)
+
+	require.NoError(t, store.Put(ctx, "certificates/example.com.crt", []byte("cert")))
+	require.NoError(t, store.Put(ctx, "certificates/example.com.key", []byte("key")))
+	require.NoError(t, store.Put(ctx, "accounts/acme.example.com/foo/account.json", []byte("{}")))
+
+	fi, err := os.Stat(filepath.Join(root, "certificates", "example.com.key"))
+	require.NoError(t, err)
+	assert.Equal(t, filePerm, fi.Mode().Perm())
+
+	data, err := store.Get(ctx, "certificates/example.com.crt")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert"), data)
+
+	keys, err := store.List(ctx, "certificates/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"certificates/example.com.crt", "certificates/example.com.key"}, keys)
+
+	keys, err = store.List(ctx, "certificates/example.com.c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"certificates/example.com.crt"}, keys)
+
+	keys, err = store.List(ctx, "archives/")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, store.Delete(ctx, "certificates/example.com.crt"))
+	require.NoError(t, store.Delete(ctx, "certificates/example.com.crt"))
+
+	assert.NoFileExists(t, filepath.Join(root, "certificates", "example.com.crt"))
+}
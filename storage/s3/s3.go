@@ -0,0 +1,187 @@
+// Package s3 implements a storage of the data of lego in an AWS S3 bucket (`s3://bucket/prefix`).
+// The credentials must be passed in the environment variables.
+//
+// The server-side encryption of the objects is defined by the query parameters of the URL:
+// `s3://bucket/prefix?sse=aws:kms&kms-key-id=<key ID>` (or `sse=AES256`).
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/pya789/lego/v4/storage"
+)
+
+func init() {
+	storage.Register("s3", func(u *url.URL) (storage.Store, error) {
+		query := u.Query()
+
+		return NewStoreConfig(&Config{
+			Bucket:               u.Host,
+			Prefix:               u.Path,
+			ServerSideEncryption: query.Get("sse"),
+			KMSKeyID:             query.Get("kms-key-id"),
+		})
+	})
+}
+
+// Config the configuration of the Store.
+type Config struct {
+	Bucket string
+	// Prefix the optional prefix of the keys (ex: lego/).
+	Prefix string
+
+	// ServerSideEncryption the server-side encryption of the objects (AES256, aws:kms).
+	// The objects are encrypted with the default encryption of the bucket if empty.
+	ServerSideEncryption string
+	// KMSKeyID the ID (or ARN) of the KMS key, with the aws:kms server-side encryption.
+	// The AWS managed key is used if empty.
+	KMSKeyID string
+}
+
+// Store stores the data as objects of an S3 bucket, the keys are prefixed by the prefix of the store.
+// The objects are encrypted with the server-side encryption of the configuration,
+// or the default encryption of the bucket.
+type Store struct {
+	bucket string
+	prefix string
+	sse    types.ServerSideEncryption
+	kmsKey string
+	client *s3.Client
+}
+
+// NewStore creates a Store in the bucket, with an optional prefix (ex: lego/).
+func NewStore(bucket, prefix string) (*Store, error) {
+	return NewStoreConfig(&Config{Bucket: bucket, Prefix: prefix})
+}
+
+// NewStoreConfig creates a Store from a configuration.
+func NewStoreConfig(cfg *Config) (*Store, error) {
+	if cfg == nil {
+		return nil, errors.New("s3: the configuration is nil")
+	}
+
+	awsConfig, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("s3: unable to create AWS config: %w", err)
+	}
+
+	return newStore(cfg, s3.NewFromConfig(awsConfig))
+}
+
+func newStore(cfg *Config, client *s3.Client) (*Store, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3: bucket name missing")
+	}
+
+	sse := types.ServerSideEncryption(cfg.ServerSideEncryption)
+
+	switch sse {
+	case "", types.ServerSideEncryptionAes256, types.ServerSideEncryptionAwsKms:
+	default:
+		return nil, fmt.Errorf("s3: unsupported server-side encryption %q (expected %q or %q)",
+			cfg.ServerSideEncryption, types.ServerSideEncryptionAes256, types.ServerSideEncryptionAwsKms)
+	}
+
+	if cfg.KMSKeyID != "" && sse != types.ServerSideEncryptionAwsKms {
+		return nil, fmt.Errorf("s3: the KMS key requires the server-side encryption %q", types.ServerSideEncryptionAwsKms)
+	}
+
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &Store{
+		bucket: cfg.Bucket,
+		prefix: prefix,
+		sse:    sse,
+		kmsKey: cfg.KMSKeyID,
+		client: client,
+	}, nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+	})
+	if err != nil {
+		var notFound *types.NoSuchKey
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("s3: %s: %w", key, storage.ErrNotExist)
+		}
+
+		return nil, fmt.Errorf("s3: get %s: %w", key, err)
+	}
+
+	defer func() { _ = out.Body.Close() }()
+
+	return io.ReadAll(out.Body)
+}
+
+func (s *Store) Put(ctx context.Context, key string, data []byte) error {
+	input := &s3.PutObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(s.prefix + key),
+		Body:                 bytes.NewReader(data),
+		ServerSideEncryption: s.sse,
+	}
+
+	if s.kmsKey != "" {
+		input.SSEKMSKeyId = aws.String(s.kmsKey)
+	}
+
+	_, err := s.client.PutObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("s3: put %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+	})
+	if err != nil {
+		return fmt.Errorf("s3: delete %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix + prefix),
+	})
+
+	var keys []string
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("s3: list %s: %w", prefix, err)
+		}
+
+		for _, obj := range page.Contents {
+			keys = append(keys, strings.TrimPrefix(aws.ToString(obj.Key), s.prefix))
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
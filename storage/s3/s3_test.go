@@ -0,0 +1,227 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/pya789/lego/v4/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBucket a stub of the S3 API (path-style requests), storing the objects of a bucket in memory.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	// headers the server-side encryption headers of the PUT requests, by key.
+	headers map[string]http.Header
+}
+
+func setupStore(t *testing.T, cfg *Config) (*Store, *fakeBucket) {
+	t.Helper()
+
+	bucket := &fakeBucket{objects: map[string][]byte{}, headers: map[string]http.Header{}}
+
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+
+	client := s3.NewFromConfig(aws.Config{
+		Credentials:      credentials.NewStaticCredentialsProvider("abc", "123", ""),
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(server.URL),
+		RetryMaxAttempts: 1,
+	}, func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+
+	store, err := newStore(cfg, client)
+	require.NoError(t, err)
+
+	return store, bucket
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/bucket"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		b.list(w, r.URL.Query().Get("prefix"))
+
+	case r.Method == http.MethodGet:
+		data, ok := b.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+
+		_, _ = w.Write(data)
+
+	case r.Method == http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		b.objects[key] = data
+		b.headers[key] = http.Header{
+			"X-Amz-Server-Side-Encryption":                r.Header.Values("X-Amz-Server-Side-Encryption"),
+			"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id": r.Header.Values("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"),
+		}
+
+	case r.Method == http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (b *fakeBucket) list(w http.ResponseWriter, prefix string) {
+	var keys []string
+	for key := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>bucket</Name><Prefix>%s</Prefix><KeyCount>%d</KeyCount><IsTruncated>false</IsTruncated>`, prefix, len(keys))
+
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(w, `<Contents><Key>%s</Key></Contents>`, key)
+	}
+
+	_, _ = fmt.Fprint(w, `</ListBucketResult>`)
+}
+
+func TestStore(t *testing.T) {
+	store, bucket := setupStore(t, &Config{Bucket: "bucket", Prefix: "/lego/"})
+
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, "certificates/example.com.crt")
+	require.ErrorIs(t, err, storage.ErrNotExist)
+
+	require.NoError(t, store.Put(ctx, "certificates/example.com.crt", []byte("cert")))
+	require.NoError(t, store.Put(ctx, "certificates/example.org.crt", []byte("other")))
+	require.NoError(t, store.Put(ctx, "accounts/account.json", []byte("account")))
+
+	assert.Contains(t, bucket.objects, "lego/certificates/example.com.crt")
+
+	data, err := store.Get(ctx, "certificates/example.com.crt")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert"), data)
+
+	keys, err := store.List(ctx, "certificates/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"certificates/example.com.crt", "certificates/example.org.crt"}, keys)
+
+	require.NoError(t, store.Delete(ctx, "certificates/example.com.crt"))
+
+	_, err = store.Get(ctx, "certificates/example.com.crt")
+	require.ErrorIs(t, err, storage.ErrNotExist)
+
+	// the default encryption of the bucket.
+	assert.Empty(t, bucket.headers["lego/accounts/account.json"].Get("X-Amz-Server-Side-Encryption"))
+}
+
+func TestStore_Put_serverSideEncryption(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      *Config
+		expectedSSE string
+		expectedKey string
+	}{
+		{
+			desc:        "AES256",
+			config:      &Config{Bucket: "bucket", ServerSideEncryption: "AES256"},
+			expectedSSE: "AES256",
+		},
+		{
+			desc:        "KMS: AWS managed key",
+			config:      &Config{Bucket: "bucket", ServerSideEncryption: "aws:kms"},
+			expectedSSE: "aws:kms",
+		},
+		{
+			desc:        "KMS: customer managed key",
+			config:      &Config{Bucket: "bucket", ServerSideEncryption: "aws:kms", KMSKeyID: "alias/lego"},
+			expectedSSE: "aws:kms",
+			expectedKey: "alias/lego",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			store, bucket := setupStore(t, test.config)
+
+			require.NoError(t, store.Put(context.Background(), "accounts/account.json", []byte("account")))
+
+			headers := bucket.headers["accounts/account.json"]
+			assert.Equal(t, test.expectedSSE, headers.Get("X-Amz-Server-Side-Encryption"))
+			assert.Equal(t, test.expectedKey, headers.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+		})
+	}
+}
+
+func Test_newStore_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *Config
+		expected string
+	}{
+		{
+			desc:     "missing bucket",
+			config:   &Config{},
+			expected: "s3: bucket name missing",
+		},
+		{
+			desc:     "unsupported encryption",
+			config:   &Config{Bucket: "bucket", ServerSideEncryption: "aws:kms:dsse"},
+			expected: `s3: unsupported server-side encryption "aws:kms:dsse" (expected "AES256" or "aws:kms")`,
+		},
+		{
+			desc:     "KMS key without KMS encryption",
+			config:   &Config{Bucket: "bucket", KMSKeyID: "alias/lego"},
+			expected: `s3: the KMS key requires the server-side encryption "aws:kms"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := newStore(test.config, nil)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	store, err := storage.Open("s3://bucket/lego?sse=aws:kms&kms-key-id=alias/lego")
+	require.NoError(t, err)
+
+	s3Store, ok := store.(*Store)
+	require.True(t, ok)
+
+	assert.Equal(t, "bucket", s3Store.bucket)
+	assert.Equal(t, "lego/", s3Store.prefix)
+	assert.EqualValues(t, "aws:kms", s3Store.sse)
+	assert.Equal(t, "alias/lego", s3Store.kmsKey)
+}
@@ -0,0 +1,97 @@
+// Package storage abstracts the storage of the accounts, the keys, the certificates, and their metadata
+// (the `.lego` directory of the CLI), to store them in other backends than the filesystem (ex: S3, Vault, etcd).
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// ErrNotExist is returned (wrapped) by Store.Get when the key doesn't exist.
+var ErrNotExist = fs.ErrNotExist
+
+// Store stores the data of lego.
+// The keys are slash-separated paths, relative to the root of the storage (ex: `certificates/example.com.crt`).
+type Store interface {
+	// Get returns the data of the key, or an error wrapping ErrNotExist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put creates or replaces the data of the key.
+	Put(ctx context.Context, key string, data []byte) error
+	// Delete removes the key, it doesn't fail if the key doesn't exist.
+	Delete(ctx context.Context, key string) error
+	// List returns the sorted keys starting with the prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Factory creates a Store from a URL (ex: `s3://bucket/prefix`).
+type Factory func(u *url.URL) (Store, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"file": func(u *url.URL) (Store, error) { return NewFileSystem(u.Path), nil },
+	}
+)
+
+// Register registers a Store factory for a URL scheme, to create the Store with Open.
+// It panics if the scheme is empty, if the factory is nil, or if the scheme is already registered.
+func Register(scheme string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if scheme == "" {
+		panic("storage: Register with an empty scheme")
+	}
+
+	if factory == nil {
+		panic("storage: Register factory is nil for " + scheme)
+	}
+
+	if _, dup := registry[scheme]; dup {
+		panic("storage: Register called twice for " + scheme)
+	}
+
+	registry[scheme] = factory
+}
+
+// Schemes returns the sorted schemes of the registered factories.
+func Schemes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	schemes := make([]string, 0, len(registry))
+	for scheme := range registry {
+		schemes = append(schemes, scheme)
+	}
+
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+// Open creates the Store of the URL, with the factory registered for its scheme.
+// A URL without scheme is a path of the filesystem.
+func Open(rawURL string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+
+	if u.Scheme == "" {
+		return NewFileSystem(rawURL), nil
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[u.Scheme]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("storage: unsupported scheme %q", u.Scheme)
+	}
+
+	return factory(u)
+}
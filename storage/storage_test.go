@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	Register("memory-test", func(u *url.URL) (Store, error) {
+		return NewFileSystem(u.Host), nil
+	})
+
+	testCases := []struct {
+		desc     string
+		url      string
+		expected Store
+		err      string
+	}{
+		{
+			desc:     "path",
+			url:      "/var/lib/lego",
+			expected: NewFileSystem("/var/lib/lego"),
+		},
+		{
+			desc:     "file URL",
+			url:      "file:///var/lib/lego",
+			expected: NewFileSystem("/var/lib/lego"),
+		},
+		{
+			desc:     "registered scheme",
+			url:      "memory-test://foo",
+			expected: NewFileSystem("foo"),
+		},
+		{
+			desc: "unsupported scheme",
+			url:  "vault://secret/lego",
+			err:  `storage: unsupported scheme "vault"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			store, err := Open(test.url)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, store)
+		})
+	}
+}
+
+func TestRegister_duplicate(t *testing.T) {
+	assert.Panics(t, func() {
+		Register("file", func(_ *url.URL) (Store, error) { return nil, nil })
+	})
+}